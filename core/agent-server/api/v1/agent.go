@@ -1,6 +1,7 @@
 package v1
 
 import (
+	"encoding/json"
 	"fmt"
 	"time"
 
@@ -160,11 +161,16 @@ func (h *Agent) List(c *gin.Context) {
 			Plugin:               pluginInfo,
 			KnowledgeBaseID:      agent.KnowledgeBaseID,
 			GenerationCount:      agent.GenerationCount,
+			HubAgentID:           agent.HubAgentID,
+			HubVersion:           agent.HubVersion,
 			KnowledgeBase:        kbInfo,
 			LLMConfigID:          agent.LLMConfigID,
 			LLMConfig:            llmInfo,
 			SystemPromptTemplate: agent.SystemPromptTemplate,
 			Metadata:             metadata,
+			Pipeline:             agentPipeline(agent),
+			LLMRouting:           agentLLMRouting(agent),
+			Memory:               agentMemory(agent),
 			Logo:                 agent.Logo,
 			Greeting:             agent.Greeting,
 			GreetingType:         agent.GreetingType,
@@ -279,10 +285,15 @@ func (h *Agent) Get(c *gin.Context) {
 			LLMConfig:            llmInfo,
 			SystemPromptTemplate: agent.SystemPromptTemplate,
 			Metadata:             metadata,
+			Pipeline:             agentPipeline(agent),
+			LLMRouting:           agentLLMRouting(agent),
+			Memory:               agentMemory(agent),
 			Logo:                 agent.Logo,
 			Greeting:             agent.Greeting,
 			GreetingType:         agent.GreetingType,
 			GenerationCount:      agent.GenerationCount,
+			HubAgentID:           agent.HubAgentID,
+			HubVersion:           agent.HubVersion,
 			Visibility:           agent.Visibility,
 			Admin:                agent.Admin,
 			IsAdmin:              utils.IsAdmin(agent.Admin, currentUser),
@@ -333,6 +344,9 @@ func (h *Agent) Create(c *gin.Context) {
 		LLMConfigID:          req.LLMConfigID,
 		SystemPromptTemplate: req.SystemPromptTemplate,
 		Metadata:             metadata,
+		Pipeline:             marshalAgentPipeline(req.Pipeline),
+		LLMRouting:           marshalAgentLLMRouting(req.LLMRouting),
+		Memory:               marshalAgentMemory(req.Memory),
 		Logo:                 req.Logo,
 		Greeting:             req.Greeting,
 		GreetingType:         req.GreetingType,
@@ -404,6 +418,9 @@ func (h *Agent) Update(c *gin.Context) {
 	} else {
 		agent.Metadata = nil
 	}
+	agent.Pipeline = marshalAgentPipeline(req.Pipeline)
+	agent.LLMRouting = marshalAgentLLMRouting(req.LLMRouting)
+	agent.Memory = marshalAgentMemory(req.Memory)
 
 	if err := h.service.UpdateAgent(ctx, agent); err != nil {
 		response.FailWithMessage(c, err.Error())
@@ -448,7 +465,7 @@ func (h *Agent) Delete(c *gin.Context) {
 
 // Enable 启用智能体
 // @Summary 启用智能体
-// @Description 启用智能体
+// @Description 启用智能体（plugin 类型的智能体要求插件在线）
 // @Tags 智能体管理
 // @Accept json
 // @Produce json
@@ -509,3 +526,75 @@ func (h *Agent) Disable(c *gin.Context) {
 
 	response.OkWithMessage(c, "禁用成功")
 }
+
+// agentPipeline 解析智能体的流水线配置
+func agentPipeline(agent *model.Agent) []dto.AgentPipelineStep {
+	steps := []dto.AgentPipelineStep{}
+	if agent.Pipeline != nil && *agent.Pipeline != "" {
+		_ = json.Unmarshal([]byte(*agent.Pipeline), &steps)
+	}
+	return steps
+}
+
+// marshalAgentPipeline 序列化流水线配置（由 service 层校验）
+func marshalAgentPipeline(steps []dto.AgentPipelineStep) *string {
+	if len(steps) == 0 {
+		return nil
+	}
+	data, err := json.Marshal(steps)
+	if err != nil {
+		return nil
+	}
+	pipeline := string(data)
+	return &pipeline
+}
+
+// agentLLMRouting 解析智能体的 LLM 路由策略（未配置时返回 nil）
+func agentLLMRouting(agent *model.Agent) *dto.AgentLLMRouting {
+	if agent.LLMRouting == nil || *agent.LLMRouting == "" {
+		return nil
+	}
+	var routing dto.AgentLLMRouting
+	if err := json.Unmarshal([]byte(*agent.LLMRouting), &routing); err != nil {
+		return nil
+	}
+	return &routing
+}
+
+// marshalAgentLLMRouting 序列化 LLM 路由策略（由 service 层校验）
+func marshalAgentLLMRouting(routing *dto.AgentLLMRouting) *string {
+	if routing == nil {
+		return nil
+	}
+	data, err := json.Marshal(routing)
+	if err != nil {
+		return nil
+	}
+	result := string(data)
+	return &result
+}
+
+// agentMemory 解析智能体的会话记忆策略（未配置时返回 nil）
+func agentMemory(agent *model.Agent) *dto.AgentMemory {
+	if agent.Memory == nil || *agent.Memory == "" {
+		return nil
+	}
+	var memory dto.AgentMemory
+	if err := json.Unmarshal([]byte(*agent.Memory), &memory); err != nil {
+		return nil
+	}
+	return &memory
+}
+
+// marshalAgentMemory 序列化会话记忆策略（由 service 层校验）
+func marshalAgentMemory(memory *dto.AgentMemory) *string {
+	if memory == nil {
+		return nil
+	}
+	data, err := json.Marshal(memory)
+	if err != nil {
+		return nil
+	}
+	result := string(data)
+	return &result
+}
@@ -0,0 +1,144 @@
+package v1
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/ai-agent-os/ai-agent-os/dto"
+	"github.com/ai-agent-os/ai-agent-os/pkg/contextx"
+	"github.com/ai-agent-os/ai-agent-os/pkg/ginx/response"
+	"github.com/ai-agent-os/ai-agent-os/pkg/logger"
+	"github.com/gin-gonic/gin"
+)
+
+// agentBundleMaxUploadSize 导入的导出包最大字节数
+const agentBundleMaxUploadSize = 100 << 20
+
+// Export 导出智能体
+// @Summary 导出智能体
+// @Description 导出智能体为 zip 导出包：manifest.json、prompt.md 和 knowledge/ 目录（知识库文档），插件只导出元数据，不包含任何密钥
+// @Tags 智能体管理
+// @Produce application/zip
+// @Param id query int true "智能体ID"
+// @Success 200 {file} file "导出包"
+// @Failure 400 {string} string "请求参数错误"
+// @Router /agent/api/v1/agents/export [get]
+func (h *Agent) Export(c *gin.Context) {
+	var req dto.AgentExportReq
+	var data []byte
+	var err error
+
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.FailWithMessage(c, "参数错误: "+err.Error())
+		return
+	}
+
+	defer func() {
+		logger.Infof(c, "Agent.Export req:%+v size:%d err:%v", req, len(data), err)
+	}()
+
+	ctx := contextx.ToContext(c)
+	var fileName string
+	data, fileName, err = h.service.ExportAgent(ctx, req.ID)
+	if err != nil {
+		response.FailWithMessage(c, err.Error())
+		return
+	}
+
+	// 使用 RFC 5987 格式支持中文文件名
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"; filename*=UTF-8''%s", fileName, url.QueryEscape(fileName)))
+	c.Data(http.StatusOK, "application/zip", data)
+}
+
+// Import 导入智能体
+// @Summary 导入智能体
+// @Description 导入智能体导出包：插件按 Code、知识库按名称检测冲突，merge 复用已有插件并补充知识库缺少的文档，overwrite 覆盖已有插件元数据和知识库文档；导入的智能体为禁用状态
+// @Tags 智能体管理
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "导出包（zip）"
+// @Param mode formData string false "冲突处理方式" Enums(merge, overwrite)
+// @Param name formData string false "导入后的智能体名称"
+// @Param dry_run formData bool false "只检查冲突，不写入数据"
+// @Success 200 {object} dto.AgentImportResp "导入成功"
+// @Failure 400 {string} string "请求参数错误"
+// @Router /agent/api/v1/agents/import [post]
+func (h *Agent) Import(c *gin.Context) {
+	var req dto.AgentImportReq
+	var resp *dto.AgentImportResp
+	var err error
+
+	if err := c.ShouldBind(&req); err != nil {
+		response.FailWithMessage(c, "参数错误: "+err.Error())
+		return
+	}
+
+	defer func() {
+		logger.Infof(c, "Agent.Import req:%+v resp:%+v err:%v", req, resp, err)
+	}()
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		response.FailWithMessage(c, "请上传导出包: "+err.Error())
+		return
+	}
+	if file.Size > agentBundleMaxUploadSize {
+		err = fmt.Errorf("导出包不能超过 %dMB", agentBundleMaxUploadSize>>20)
+		response.FailWithMessage(c, err.Error())
+		return
+	}
+	f, err := file.Open()
+	if err != nil {
+		response.FailWithMessage(c, "读取导出包失败: "+err.Error())
+		return
+	}
+	defer f.Close()
+	data, err := io.ReadAll(f)
+	if err != nil {
+		response.FailWithMessage(c, "读取导出包失败: "+err.Error())
+		return
+	}
+
+	ctx := contextx.ToContext(c)
+	resp, err = h.service.ImportAgent(ctx, data, req)
+	if err != nil {
+		response.FailWithMessage(c, err.Error())
+		return
+	}
+	response.OkWithData(c, resp)
+}
+
+// PublishToHub 发布智能体到 Hub
+// @Summary 发布智能体到 Hub
+// @Description 将智能体导出包发布到 Hub 市场，首次发布为 v1，之后每次发布新版本
+// @Tags 智能体管理
+// @Accept json
+// @Produce json
+// @Param request body dto.AgentPublishToHubReq true "发布智能体请求"
+// @Success 200 {object} dto.AgentPublishToHubResp "发布成功"
+// @Failure 400 {string} string "请求参数错误"
+// @Router /agent/api/v1/agents/publish_to_hub [post]
+func (h *Agent) PublishToHub(c *gin.Context) {
+	var req dto.AgentPublishToHubReq
+	var resp *dto.AgentPublishToHubResp
+	var err error
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.FailWithMessage(c, "参数错误: "+err.Error())
+		return
+	}
+
+	defer func() {
+		logger.Infof(c, "Agent.PublishToHub req:%+v resp:%+v err:%v", req, resp, err)
+	}()
+
+	ctx := contextx.ToContext(c)
+	resp, err = h.service.PublishAgentToHub(ctx, &req)
+	if err != nil {
+		response.FailWithMessage(c, err.Error())
+		return
+	}
+	response.OkWithData(c, resp)
+}
@@ -1,6 +1,9 @@
 package v1
 
 import (
+	"encoding/json"
+	"fmt"
+	"io"
 	"time"
 
 	"github.com/ai-agent-os/ai-agent-os/core/agent-server/model"
@@ -12,6 +15,9 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// functionGenStreamHeartbeat SSE 心跳间隔（避免网关/代理因空闲断开连接）
+const functionGenStreamHeartbeat = 15 * time.Second
+
 // AgentChat 智能体聊天 API 处理器
 type AgentChat struct {
 	service *service.AgentChatService
@@ -146,7 +152,7 @@ func (h *AgentChat) ListSessions(c *gin.Context) {
 
 // ListMessages 获取消息列表
 // @Summary 获取消息列表
-// @Description 根据SessionID获取消息列表
+// @Description 根据SessionID获取当前分支的消息列表，有多个版本（重新生成、编辑后重发）的消息通过 siblings 返回其他版本
 // @Tags 智能体管理
 // @Accept json
 // @Produce json
@@ -166,33 +172,168 @@ func (h *AgentChat) ListMessages(c *gin.Context) {
 	}
 
 	ctx := contextx.ToContext(c)
-	messages, err := h.service.ListMessages(ctx, req.SessionID)
+	branches, err := h.service.ListMessages(ctx, req.SessionID)
 	if err != nil {
 		response.FailWithMessage(c, err.Error())
 		return
 	}
 
 	// 转换为响应格式
-	messageInfos := make([]dto.ChatMessageInfo, 0, len(messages))
-	for _, msg := range messages {
+	messageInfos := make([]dto.ChatMessageInfo, 0, len(branches.Messages))
+	for _, msg := range branches.Messages {
 		filesStr := ""
 		if msg.Files != nil {
 			filesStr = *msg.Files
 		}
 		messageInfos = append(messageInfos, dto.ChatMessageInfo{
-			ID:        msg.ID,
-			SessionID: msg.SessionID,
-			AgentID:   msg.AgentID, // 处理该消息的智能体ID
-			Role:      msg.Role,
-			Content:   msg.Content,
-			Files:     filesStr,
-			User:      msg.User,
-			CreatedAt: time.Time(msg.CreatedAt).Format(time.DateTime),
+			ID:          msg.ID,
+			SessionID:   msg.SessionID,
+			AgentID:     msg.AgentID, // 处理该消息的智能体ID
+			Role:        msg.Role,
+			Content:     msg.Content,
+			Files:       filesStr,
+			User:        msg.User,
+			LLMProvider: msg.LLMProvider,
+			LLMModel:    msg.LLMModel,
+			ParentID:    msg.ParentID,
+			RecordID:    msg.RecordID,
+			Siblings:    branches.Siblings[msg.ID],
+			Applied:     msg.RecordID > 0 && msg.RecordID == branches.Session.AppliedRecordID,
+			CreatedAt:   time.Time(msg.CreatedAt).Format(time.DateTime),
 		})
 	}
 
 	resp = &dto.ChatMessageListResp{
-		Messages: messageInfos,
+		Messages:         messageInfos,
+		CurrentMessageID: branches.Session.CurrentMessageID,
+		AppliedRecordID:  branches.Session.AppliedRecordID,
+		AppliedMessageID: branches.AppliedMessageID,
+	}
+	response.OkWithData(c, resp)
+}
+
+// RegenerateFunctionGen 重新生成回答
+// @Summary 重新生成回答
+// @Description 基于同一条用户消息重新生成，新的回答与原回答并列（可通过消息列表的 siblings 对比），生成完成后成为当前分支并应用到工作空间
+// @Tags 智能体管理
+// @Accept json
+// @Produce json
+// @Param request body dto.FunctionGenRegenerateReq true "重新生成请求"
+// @Success 200 {object} dto.FunctionGenAgentChatResp "已开始生成"
+// @Failure 400 {string} string "请求参数错误"
+// @Router /agent/api/v1/chat/function_gen/regenerate [post]
+func (h *AgentChat) RegenerateFunctionGen(c *gin.Context) {
+	var req dto.FunctionGenRegenerateReq
+	var resp *dto.FunctionGenAgentChatResp
+	var err error
+	defer func() {
+		logger.Infof(c, "AgentChat.RegenerateFunctionGen req:%+v resp:%+v err:%v", req, resp, err)
+	}()
+
+	if err = c.ShouldBindJSON(&req); err != nil {
+		response.FailWithMessage(c, "参数错误: "+err.Error())
+		return
+	}
+
+	ctx := contextx.ToContext(c)
+	resp, err = h.service.RegenerateFunctionGen(ctx, &req)
+	if err != nil {
+		response.FailWithMessage(c, err.Error())
+		return
+	}
+	response.OkWithData(c, resp)
+}
+
+// EditFunctionGen 编辑用户消息后重新发送
+// @Summary 编辑后重新发送
+// @Description 编辑之前的用户消息并重新生成，新的用户消息与原消息并列，开始一个新分支（原分支保留）
+// @Tags 智能体管理
+// @Accept json
+// @Produce json
+// @Param request body dto.FunctionGenEditReq true "编辑请求"
+// @Success 200 {object} dto.FunctionGenAgentChatResp "已开始生成"
+// @Failure 400 {string} string "请求参数错误"
+// @Router /agent/api/v1/chat/function_gen/edit [post]
+func (h *AgentChat) EditFunctionGen(c *gin.Context) {
+	var req dto.FunctionGenEditReq
+	var resp *dto.FunctionGenAgentChatResp
+	var err error
+	defer func() {
+		logger.Infof(c, "AgentChat.EditFunctionGen req:%+v resp:%+v err:%v", req, resp, err)
+	}()
+
+	if err = c.ShouldBindJSON(&req); err != nil {
+		response.FailWithMessage(c, "参数错误: "+err.Error())
+		return
+	}
+
+	ctx := contextx.ToContext(c)
+	resp, err = h.service.EditFunctionGen(ctx, &req)
+	if err != nil {
+		response.FailWithMessage(c, err.Error())
+		return
+	}
+	response.OkWithData(c, resp)
+}
+
+// SwitchBranch 切换分支
+// @Summary 切换分支
+// @Description 切换会话的当前分支（切换到指定消息下最新的分支），只影响后续对话的上下文，不修改工作空间
+// @Tags 智能体管理
+// @Accept json
+// @Produce json
+// @Param request body dto.ChatBranchSwitchReq true "切换分支请求"
+// @Success 200 {string} string "切换成功"
+// @Failure 400 {string} string "请求参数错误"
+// @Router /agent/api/v1/chat/branch/switch [post]
+func (h *AgentChat) SwitchBranch(c *gin.Context) {
+	var req dto.ChatBranchSwitchReq
+	var err error
+	defer func() {
+		logger.Infof(c, "AgentChat.SwitchBranch req:%+v err:%v", req, err)
+	}()
+
+	if err = c.ShouldBindJSON(&req); err != nil {
+		response.FailWithMessage(c, "参数错误: "+err.Error())
+		return
+	}
+
+	ctx := contextx.ToContext(c)
+	if err = h.service.SwitchBranch(ctx, &req); err != nil {
+		response.FailWithMessage(c, err.Error())
+		return
+	}
+	response.OkWithMessage(c, "切换成功")
+}
+
+// ApplyFunctionGen 应用分支代码
+// @Summary 应用分支代码
+// @Description 把指定回答生成的代码重新写入工作空间（与生成完成时相同的流程，结果通过 SSE 的 result 事件和生成状态返回），并切换到该分支
+// @Tags 智能体管理
+// @Accept json
+// @Produce json
+// @Param request body dto.FunctionGenApplyReq true "应用请求"
+// @Success 200 {object} dto.FunctionGenAgentChatResp "已开始应用"
+// @Failure 400 {string} string "请求参数错误"
+// @Router /agent/api/v1/chat/function_gen/apply [post]
+func (h *AgentChat) ApplyFunctionGen(c *gin.Context) {
+	var req dto.FunctionGenApplyReq
+	var resp *dto.FunctionGenAgentChatResp
+	var err error
+	defer func() {
+		logger.Infof(c, "AgentChat.ApplyFunctionGen req:%+v resp:%+v err:%v", req, resp, err)
+	}()
+
+	if err = c.ShouldBindJSON(&req); err != nil {
+		response.FailWithMessage(c, "参数错误: "+err.Error())
+		return
+	}
+
+	ctx := contextx.ToContext(c)
+	resp, err = h.service.ApplyFunctionGen(ctx, &req)
+	if err != nil {
+		response.FailWithMessage(c, err.Error())
+		return
 	}
 	response.OkWithData(c, resp)
 }
@@ -260,6 +401,9 @@ func (h *AgentChat) GetFunctionGenStatus(c *gin.Context) {
 		CreatedAt:      time.Time(record.CreatedAt).Format(time.DateTime),
 		UpdatedAt:      time.Time(record.UpdatedAt).Format(time.DateTime),
 		FullGroupCodes: fullGroupCodes,
+		LLMConfigID:    record.LLMConfigID,
+		LLMProvider:    record.LLMProvider,
+		LLMModel:       record.LLMModel,
 	}
 
 	// 根据状态返回不同的字段
@@ -272,5 +416,88 @@ func (h *AgentChat) GetFunctionGenStatus(c *gin.Context) {
 	}
 	// generating 状态：不返回代码和错误信息
 
+	// 流水线步骤执行记录（每一步的输入、输出和耗时）
+	if record.PipelineSteps != nil && *record.PipelineSteps != "" {
+		if err := json.Unmarshal([]byte(*record.PipelineSteps), &resp.PipelineSteps); err != nil {
+			logger.Warnf(ctx, "[AgentChat] 解析流水线步骤记录失败 - RecordID: %d, Error: %v", record.ID, err)
+		}
+	}
+
 	response.OkWithData(c, resp)
 }
+
+// StreamFunctionGen 订阅代码生成过程（SSE）
+// @Summary 订阅代码生成过程
+// @Description 通过 Server-Sent Events 实时接收会话的代码生成过程：status（状态）、plugin_step（插件/流水线步骤）、thinking（思考过程片段）、content（回答内容片段）、code（提取的代码）、result（工作空间更新回调结果）、error（生成失败）。
+// @Description 收到 result 或 error 事件后连接关闭；断线重连时通过 Last-Event-ID 请求头或 last_event_id 参数补齐错过的事件，空闲时定期推送 ping 事件
+// @Tags 智能体管理
+// @Produce text/event-stream
+// @Param session_id query string true "会话ID"
+// @Param last_event_id query string false "最后收到的事件ID"
+// @Param Last-Event-ID header string false "最后收到的事件ID（EventSource 自动携带）"
+// @Success 200 {object} dto.FunctionGenStreamEvent "事件"
+// @Failure 400 {string} string "请求参数错误"
+// @Router /agent/api/v1/chat/function_gen/stream [get]
+func (h *AgentChat) StreamFunctionGen(c *gin.Context) {
+	var req dto.FunctionGenStreamReq
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.FailWithMessage(c, "参数错误: "+err.Error())
+		return
+	}
+	if lastEventID := c.GetHeader("Last-Event-ID"); lastEventID != "" {
+		req.LastEventID = lastEventID
+	}
+
+	ctx := contextx.ToContext(c)
+	replay, ch, cancel, err := h.service.SubscribeFunctionGen(ctx, req.SessionID, req.LastEventID)
+	if err != nil {
+		logger.Errorf(ctx, "[AgentChat] 订阅代码生成过程失败 - SessionID: %s, Error: %v", req.SessionID, err)
+		response.FailWithMessage(c, err.Error())
+		return
+	}
+	defer cancel()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no") // 禁止 nginx 等代理缓冲
+
+	logger.Infof(ctx, "[AgentChat] SSE 订阅建立 - SessionID: %s, LastEventID: %s, Replay: %d", req.SessionID, req.LastEventID, len(replay))
+	for _, event := range replay {
+		writeFunctionGenEvent(c.Writer, event)
+		if event.Type == dto.FunctionGenEventResult || event.Type == dto.FunctionGenEventError {
+			c.Writer.Flush()
+			return
+		}
+	}
+	c.Writer.Flush()
+
+	ticker := time.NewTicker(functionGenStreamHeartbeat)
+	defer ticker.Stop()
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case event, ok := <-ch:
+			if !ok {
+				// 推送跟不上时服务端断开，客户端带上最后的事件ID重连即可
+				return false
+			}
+			writeFunctionGenEvent(w, event)
+			return event.Type != dto.FunctionGenEventResult && event.Type != dto.FunctionGenEventError
+		case <-ticker.C:
+			c.SSEvent("ping", time.Now().Unix())
+			return true
+		case <-c.Request.Context().Done():
+			return false
+		}
+	})
+	logger.Infof(ctx, "[AgentChat] SSE 订阅断开 - SessionID: %s", req.SessionID)
+}
+
+// writeFunctionGenEvent 写入一个 SSE 事件（带事件ID，用于断线重连）
+func writeFunctionGenEvent(w io.Writer, event dto.FunctionGenStreamEvent) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+}
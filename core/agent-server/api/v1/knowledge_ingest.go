@@ -0,0 +1,234 @@
+package v1
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/ai-agent-os/ai-agent-os/core/agent-server/model"
+	"github.com/ai-agent-os/ai-agent-os/dto"
+	"github.com/ai-agent-os/ai-agent-os/pkg/contextx"
+	"github.com/ai-agent-os/ai-agent-os/pkg/ginx/response"
+	"github.com/ai-agent-os/ai-agent-os/pkg/logger"
+	"github.com/gin-gonic/gin"
+)
+
+// knowledgeIngestMaxUploadSize 批量导入压缩包最大字节数
+const knowledgeIngestMaxUploadSize = 200 << 20
+
+// IngestArchive 从压缩包批量导入文档
+// @Summary 从压缩包批量导入文档
+// @Description 上传 zip/tar/tar.gz 压缩包，后台把其中的 Markdown、TXT、HTML、Word(docx) 文件导入知识库：目录结构保留为文档树，HTML 和 docx 转换为 Markdown，重复导入时按内容哈希只更新变化的文档
+// @Tags 知识库管理
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "压缩包（zip/tar/tar.gz）"
+// @Param knowledge_base_id formData int true "知识库ID"
+// @Param sub_dir formData string false "只导入压缩包中的子目录"
+// @Param delete_missing formData bool false "删除来源中已不存在的文档"
+// @Success 200 {object} dto.KnowledgeIngestResp "导入任务已创建"
+// @Failure 400 {string} string "请求参数错误"
+// @Router /agent/api/v1/knowledge/ingest/archive [post]
+func (h *Knowledge) IngestArchive(c *gin.Context) {
+	var req dto.KnowledgeIngestArchiveReq
+	var resp *dto.KnowledgeIngestResp
+	var err error
+
+	if err := c.ShouldBind(&req); err != nil {
+		response.FailWithMessage(c, "参数错误: "+err.Error())
+		return
+	}
+
+	defer func() {
+		logger.Infof(c, "Knowledge.IngestArchive req:%+v resp:%+v err:%v", req, resp, err)
+	}()
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		response.FailWithMessage(c, "请上传压缩包: "+err.Error())
+		return
+	}
+	if file.Size > knowledgeIngestMaxUploadSize {
+		err = fmt.Errorf("压缩包不能超过 %dMB", knowledgeIngestMaxUploadSize>>20)
+		response.FailWithMessage(c, err.Error())
+		return
+	}
+	f, err := file.Open()
+	if err != nil {
+		response.FailWithMessage(c, "读取压缩包失败: "+err.Error())
+		return
+	}
+	defer f.Close()
+	data, err := io.ReadAll(f)
+	if err != nil {
+		response.FailWithMessage(c, "读取压缩包失败: "+err.Error())
+		return
+	}
+
+	ctx := contextx.ToContext(c)
+	job, err := h.service.IngestArchive(ctx, &req, file.Filename, data)
+	if err != nil {
+		response.FailWithMessage(c, err.Error())
+		return
+	}
+	resp = &dto.KnowledgeIngestResp{JobID: job.ID}
+	response.OkWithData(c, resp)
+}
+
+// IngestGit 从本地 git 仓库批量导入文档
+// @Summary 从本地 git 仓库批量导入文档
+// @Description 后台读取服务器上 git 仓库指定引用（默认 HEAD）的文件导入知识库，仓库路径必须在 agent.ingest_roots 配置的目录中；导入规则同压缩包导入
+// @Tags 知识库管理
+// @Accept json
+// @Produce json
+// @Param request body dto.KnowledgeIngestGitReq true "git 仓库导入请求"
+// @Success 200 {object} dto.KnowledgeIngestResp "导入任务已创建"
+// @Failure 400 {string} string "请求参数错误"
+// @Router /agent/api/v1/knowledge/ingest/git [post]
+func (h *Knowledge) IngestGit(c *gin.Context) {
+	var req dto.KnowledgeIngestGitReq
+	var resp *dto.KnowledgeIngestResp
+	var err error
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.FailWithMessage(c, "参数错误: "+err.Error())
+		return
+	}
+
+	defer func() {
+		logger.Infof(c, "Knowledge.IngestGit req:%+v resp:%+v err:%v", req, resp, err)
+	}()
+
+	ctx := contextx.ToContext(c)
+	job, err := h.service.IngestGit(ctx, &req)
+	if err != nil {
+		response.FailWithMessage(c, err.Error())
+		return
+	}
+	resp = &dto.KnowledgeIngestResp{JobID: job.ID}
+	response.OkWithData(c, resp)
+}
+
+// ListIngestJobs 获取批量导入任务列表
+// @Summary 获取批量导入任务列表
+// @Description 获取知识库的批量导入任务列表（最新的在前），包含进度和统计
+// @Tags 知识库管理
+// @Accept json
+// @Produce json
+// @Param knowledge_base_id query int true "知识库ID"
+// @Param page query int true "页码" default(1)
+// @Param page_size query int true "每页数量" default(10)
+// @Success 200 {object} dto.KnowledgeListIngestJobsResp "获取成功"
+// @Failure 400 {string} string "请求参数错误"
+// @Router /agent/api/v1/knowledge/ingest/jobs [get]
+func (h *Knowledge) ListIngestJobs(c *gin.Context) {
+	var req dto.KnowledgeListIngestJobsReq
+	var resp *dto.KnowledgeListIngestJobsResp
+	var err error
+
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.FailWithMessage(c, "参数错误: "+err.Error())
+		return
+	}
+
+	defer func() {
+		logger.Infof(c, "Knowledge.ListIngestJobs req:%+v err:%v", req, err)
+	}()
+
+	ctx := contextx.ToContext(c)
+	jobs, total, err := h.service.ListIngestJobs(ctx, req.KnowledgeBaseID, req.Page, req.PageSize)
+	if err != nil {
+		response.FailWithMessage(c, err.Error())
+		return
+	}
+
+	jobInfos := make([]dto.KnowledgeIngestJobInfo, 0, len(jobs))
+	for _, job := range jobs {
+		jobInfos = append(jobInfos, toKnowledgeIngestJobInfo(job))
+	}
+	resp = &dto.KnowledgeListIngestJobsResp{
+		Jobs:  jobInfos,
+		Total: total,
+	}
+	response.OkWithData(c, resp)
+}
+
+// GetIngestJob 获取批量导入任务详情
+// @Summary 获取批量导入任务详情
+// @Description 获取批量导入任务的进度和每个文件的处理结果，可按文件状态过滤
+// @Tags 知识库管理
+// @Accept json
+// @Produce json
+// @Param id query int true "导入任务ID"
+// @Param status query string false "文件状态" Enums(created, updated, unchanged, deleted, failed)
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页数量" default(50)
+// @Success 200 {object} dto.KnowledgeGetIngestJobResp "获取成功"
+// @Failure 400 {string} string "请求参数错误"
+// @Router /agent/api/v1/knowledge/ingest/job [get]
+func (h *Knowledge) GetIngestJob(c *gin.Context) {
+	var req dto.KnowledgeGetIngestJobReq
+	var resp *dto.KnowledgeGetIngestJobResp
+	var err error
+
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.FailWithMessage(c, "参数错误: "+err.Error())
+		return
+	}
+
+	defer func() {
+		logger.Infof(c, "Knowledge.GetIngestJob req:%+v err:%v", req, err)
+	}()
+
+	ctx := contextx.ToContext(c)
+	job, files, total, err := h.service.GetIngestJob(ctx, req.ID, req.Status, req.Page, req.PageSize)
+	if err != nil {
+		response.FailWithMessage(c, err.Error())
+		return
+	}
+
+	fileInfos := make([]dto.KnowledgeIngestFileInfo, 0, len(files))
+	for _, file := range files {
+		fileInfos = append(fileInfos, dto.KnowledgeIngestFileInfo{
+			ID:         file.ID,
+			Path:       file.Path,
+			Status:     file.Status,
+			DocumentID: file.DocumentID,
+			FileType:   file.FileType,
+			ErrorMsg:   file.ErrorMsg,
+		})
+	}
+	resp = &dto.KnowledgeGetIngestJobResp{
+		KnowledgeIngestJobInfo: toKnowledgeIngestJobInfo(job),
+		Files:                  fileInfos,
+		FilesTotal:             total,
+	}
+	response.OkWithData(c, resp)
+}
+
+func toKnowledgeIngestJobInfo(job *model.KnowledgeIngestJob) dto.KnowledgeIngestJobInfo {
+	return dto.KnowledgeIngestJobInfo{
+		ID:              job.ID,
+		KnowledgeBaseID: job.KnowledgeBaseID,
+		SourceType:      job.SourceType,
+		Source:          job.Source,
+		Ref:             job.Ref,
+		Commit:          job.Commit,
+		SubDir:          job.SubDir,
+		DeleteMissing:   job.DeleteMissing,
+		Status:          job.Status,
+		Total:           job.Total,
+		Processed:       job.Processed,
+		Created:         job.Created,
+		Updated:         job.Updated,
+		Unchanged:       job.Unchanged,
+		Deleted:         job.Deleted,
+		Failed:          job.Failed,
+		Skipped:         job.Skipped,
+		ErrorMsg:        job.ErrorMsg,
+		Duration:        job.Duration,
+		User:            job.User,
+		CreatedAt:       time.Time(job.CreatedAt).Format("2006-01-02T15:04:05Z"),
+		UpdatedAt:       time.Time(job.UpdatedAt).Format("2006-01-02T15:04:05Z"),
+	}
+}
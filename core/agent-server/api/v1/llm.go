@@ -64,21 +64,24 @@ func (h *LLM) List(c *gin.Context) {
 			extraConfig = *cfg.ExtraConfig
 		}
 		llmInfos = append(llmInfos, dto.LLMInfo{
-			ID:          cfg.ID,
-			Name:        cfg.Name,
-			Provider:    cfg.Provider,
-			Model:       cfg.Model,
-			APIBase:     cfg.APIBase,
-			Timeout:     cfg.Timeout,
-			MaxTokens:   cfg.MaxTokens,
-			ExtraConfig: extraConfig,
-			UseThinking: cfg.UseThinking,
-			IsDefault:   cfg.IsDefault,
-			Visibility:  cfg.Visibility,
-			Admin:       cfg.Admin,
-			IsAdmin:     utils.IsAdmin(cfg.Admin, currentUser),
-			CreatedAt:   time.Time(cfg.CreatedAt).Format("2006-01-02T15:04:05Z"),
-			UpdatedAt:   time.Time(cfg.UpdatedAt).Format("2006-01-02T15:04:05Z"),
+			ID:              cfg.ID,
+			Name:            cfg.Name,
+			Provider:        cfg.Provider,
+			Model:           cfg.Model,
+			APIBase:         cfg.APIBase,
+			Timeout:         cfg.Timeout,
+			MaxTokens:       cfg.MaxTokens,
+			ExtraConfig:     extraConfig,
+			UseThinking:     cfg.UseThinking,
+			Cost:            cfg.Cost,
+			PromptPrice:     cfg.PromptPrice,
+			CompletionPrice: cfg.CompletionPrice,
+			IsDefault:       cfg.IsDefault,
+			Visibility:      cfg.Visibility,
+			Admin:           cfg.Admin,
+			IsAdmin:         utils.IsAdmin(cfg.Admin, currentUser),
+			CreatedAt:       time.Time(cfg.CreatedAt).Format("2006-01-02T15:04:05Z"),
+			UpdatedAt:       time.Time(cfg.UpdatedAt).Format("2006-01-02T15:04:05Z"),
 		})
 	}
 
@@ -126,18 +129,21 @@ func (h *LLM) Get(c *gin.Context) {
 	}
 	resp = &dto.LLMGetResp{
 		LLMInfo: dto.LLMInfo{
-			ID:          cfg.ID,
-			Name:        cfg.Name,
-			Provider:    cfg.Provider,
-			Model:       cfg.Model,
-			APIBase:     cfg.APIBase,
-			Timeout:     cfg.Timeout,
-			MaxTokens:   cfg.MaxTokens,
-			ExtraConfig: extraConfig,
-			UseThinking: cfg.UseThinking,
-			IsDefault:   cfg.IsDefault,
-			CreatedAt:   time.Time(cfg.CreatedAt).Format("2006-01-02T15:04:05Z"),
-			UpdatedAt:   time.Time(cfg.UpdatedAt).Format("2006-01-02T15:04:05Z"),
+			ID:              cfg.ID,
+			Name:            cfg.Name,
+			Provider:        cfg.Provider,
+			Model:           cfg.Model,
+			APIBase:         cfg.APIBase,
+			Timeout:         cfg.Timeout,
+			MaxTokens:       cfg.MaxTokens,
+			ExtraConfig:     extraConfig,
+			UseThinking:     cfg.UseThinking,
+			Cost:            cfg.Cost,
+			PromptPrice:     cfg.PromptPrice,
+			CompletionPrice: cfg.CompletionPrice,
+			IsDefault:       cfg.IsDefault,
+			CreatedAt:       time.Time(cfg.CreatedAt).Format("2006-01-02T15:04:05Z"),
+			UpdatedAt:       time.Time(cfg.UpdatedAt).Format("2006-01-02T15:04:05Z"),
 		},
 	}
 	response.OkWithData(c, resp)
@@ -173,18 +179,21 @@ func (h *LLM) GetDefault(c *gin.Context) {
 	}
 	resp = &dto.LLMGetDefaultResp{
 		LLMInfo: dto.LLMInfo{
-			ID:          cfg.ID,
-			Name:        cfg.Name,
-			Provider:    cfg.Provider,
-			Model:       cfg.Model,
-			APIBase:     cfg.APIBase,
-			Timeout:     cfg.Timeout,
-			MaxTokens:   cfg.MaxTokens,
-			ExtraConfig: extraConfig,
-			UseThinking: cfg.UseThinking,
-			IsDefault:   cfg.IsDefault,
-			CreatedAt:   time.Time(cfg.CreatedAt).Format("2006-01-02T15:04:05Z"),
-			UpdatedAt:   time.Time(cfg.UpdatedAt).Format("2006-01-02T15:04:05Z"),
+			ID:              cfg.ID,
+			Name:            cfg.Name,
+			Provider:        cfg.Provider,
+			Model:           cfg.Model,
+			APIBase:         cfg.APIBase,
+			Timeout:         cfg.Timeout,
+			MaxTokens:       cfg.MaxTokens,
+			ExtraConfig:     extraConfig,
+			UseThinking:     cfg.UseThinking,
+			Cost:            cfg.Cost,
+			PromptPrice:     cfg.PromptPrice,
+			CompletionPrice: cfg.CompletionPrice,
+			IsDefault:       cfg.IsDefault,
+			CreatedAt:       time.Time(cfg.CreatedAt).Format("2006-01-02T15:04:05Z"),
+			UpdatedAt:       time.Time(cfg.UpdatedAt).Format("2006-01-02T15:04:05Z"),
 		},
 	}
 	response.OkWithData(c, resp)
@@ -216,16 +225,19 @@ func (h *LLM) Create(c *gin.Context) {
 
 	ctx := contextx.ToContext(c)
 	cfg := &model.LLMConfig{
-		Name:        req.Name,
-		Provider:    req.Provider,
-		Model:       req.Model,
-		APIKey:      req.APIKey,
-		APIBase:     req.APIBase,
-		Timeout:     req.Timeout,
-		MaxTokens:   req.MaxTokens,
-		ExtraConfig: req.ExtraConfig,
-		UseThinking: req.UseThinking,
-		IsDefault:   req.IsDefault,
+		Name:            req.Name,
+		Provider:        req.Provider,
+		Model:           req.Model,
+		APIKey:          req.APIKey,
+		APIBase:         req.APIBase,
+		Timeout:         req.Timeout,
+		MaxTokens:       req.MaxTokens,
+		ExtraConfig:     req.ExtraConfig,
+		UseThinking:     req.UseThinking,
+		Cost:            req.Cost,
+		PromptPrice:     req.PromptPrice,
+		CompletionPrice: req.CompletionPrice,
+		IsDefault:       req.IsDefault,
 	}
 
 	if err := h.service.CreateLLMConfig(ctx, cfg); err != nil {
@@ -285,6 +297,9 @@ func (h *LLM) Update(c *gin.Context) {
 		cfg.ExtraConfig = nil
 	}
 	cfg.UseThinking = req.UseThinking
+	cfg.Cost = req.Cost
+	cfg.PromptPrice = req.PromptPrice
+	cfg.CompletionPrice = req.CompletionPrice
 	cfg.IsDefault = req.IsDefault
 
 	if err := h.service.UpdateLLMConfig(ctx, cfg); err != nil {
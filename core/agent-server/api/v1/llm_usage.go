@@ -0,0 +1,236 @@
+package v1
+
+import (
+	"time"
+
+	"github.com/ai-agent-os/ai-agent-os/core/agent-server/model"
+	"github.com/ai-agent-os/ai-agent-os/core/agent-server/service"
+	"github.com/ai-agent-os/ai-agent-os/dto"
+	"github.com/ai-agent-os/ai-agent-os/pkg/contextx"
+	"github.com/ai-agent-os/ai-agent-os/pkg/ginx/response"
+	"github.com/ai-agent-os/ai-agent-os/pkg/logger"
+	"github.com/gin-gonic/gin"
+)
+
+// LLMUsage LLM 用量和配额 API 处理器
+type LLMUsage struct {
+	service *service.LLMUsageService
+}
+
+// NewLLMUsage 创建 LLM 用量 API 处理器
+func NewLLMUsage(service *service.LLMUsageService) *LLMUsage {
+	return &LLMUsage{service: service}
+}
+
+// Report 获取用量报表
+// @Summary 获取LLM用量报表
+// @Description 按用户、部门、智能体或模型聚合 token 用量和费用，可按天或按月分组（非用量管理员只能查看自己的用量）
+// @Tags LLM管理
+// @Accept json
+// @Produce json
+// @Param group_by query string true "分组维度：user/department/agent/model"
+// @Param interval query string false "时间粒度：day/month，为空表示整个时间段汇总"
+// @Param start_date query string false "开始日期（2006-01-02），默认 30 天前"
+// @Param end_date query string false "结束日期（2006-01-02），默认今天"
+// @Param user query string false "按用户过滤"
+// @Param department query string false "按部门过滤（包含子部门）"
+// @Param agent_id query int false "按智能体过滤"
+// @Param llm_config_id query int false "按LLM配置过滤"
+// @Success 200 {object} dto.LLMUsageReportResp "获取成功"
+// @Failure 400 {string} string "请求参数错误"
+// @Router /agent/api/v1/llm/usage/report [get]
+func (h *LLMUsage) Report(c *gin.Context) {
+	var req dto.LLMUsageReportReq
+	var resp *dto.LLMUsageReportResp
+	var err error
+
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.FailWithMessage(c, "参数错误: "+err.Error())
+		return
+	}
+
+	defer func() {
+		logger.Infof(c, "LLMUsage.Report req:%+v resp:%+v err:%v", req, resp, err)
+	}()
+
+	ctx := contextx.ToContext(c)
+	resp, err = h.service.Report(ctx, &req)
+	if err != nil {
+		response.FailWithMessage(c, err.Error())
+		return
+	}
+	response.OkWithData(c, resp)
+}
+
+// QuotaList 获取配额列表
+// @Summary 获取LLM用量配额列表
+// @Description 获取配额列表及当前周期的用量（仅用量管理员）
+// @Tags LLM管理
+// @Accept json
+// @Produce json
+// @Param scope query string false "配额范围：user/department/agent"
+// @Param target query string false "配额对象"
+// @Param page query int true "页码" default(1)
+// @Param page_size query int true "每页数量" default(10)
+// @Success 200 {object} dto.LLMQuotaListResp "获取成功"
+// @Failure 400 {string} string "请求参数错误"
+// @Router /agent/api/v1/llm/quota/list [get]
+func (h *LLMUsage) QuotaList(c *gin.Context) {
+	var req dto.LLMQuotaListReq
+	var resp *dto.LLMQuotaListResp
+	var err error
+
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.FailWithMessage(c, "参数错误: "+err.Error())
+		return
+	}
+
+	defer func() {
+		logger.Infof(c, "LLMUsage.QuotaList req:%+v resp:%+v err:%v", req, resp, err)
+	}()
+
+	ctx := contextx.ToContext(c)
+	quotas, total, err := h.service.ListQuotas(ctx, &req)
+	if err != nil {
+		response.FailWithMessage(c, err.Error())
+		return
+	}
+
+	quotaInfos := make([]dto.LLMQuotaInfo, 0, len(quotas))
+	for _, quota := range quotas {
+		usedTokens, usedCost, periodStart, err := h.service.QuotaUsage(ctx, quota)
+		if err != nil {
+			response.FailWithMessage(c, err.Error())
+			return
+		}
+		quotaInfos = append(quotaInfos, dto.LLMQuotaInfo{
+			ID:          quota.ID,
+			Scope:       quota.Scope,
+			Target:      quota.Target,
+			Period:      quota.Period,
+			TokenLimit:  quota.TokenLimit,
+			CostLimit:   quota.CostLimit,
+			Enabled:     quota.Enabled,
+			Remark:      quota.Remark,
+			UsedTokens:  usedTokens,
+			UsedCost:    usedCost,
+			PeriodStart: periodStart.Format("2006-01-02T15:04:05Z"),
+			CreatedBy:   quota.CreatedBy,
+			CreatedAt:   time.Time(quota.CreatedAt).Format("2006-01-02T15:04:05Z"),
+			UpdatedAt:   time.Time(quota.UpdatedAt).Format("2006-01-02T15:04:05Z"),
+		})
+	}
+
+	resp = &dto.LLMQuotaListResp{
+		Quotas: quotaInfos,
+		Total:  total,
+	}
+	response.OkWithData(c, resp)
+}
+
+// QuotaCreate 创建配额
+// @Summary 创建LLM用量配额
+// @Description 为用户、部门（包含子部门）或智能体设置每日/每月的 token 或费用上限（仅用量管理员）
+// @Tags LLM管理
+// @Accept json
+// @Produce json
+// @Param request body dto.LLMQuotaCreateReq true "创建配额请求"
+// @Success 200 {object} dto.LLMQuotaCreateResp "创建成功"
+// @Failure 400 {string} string "请求参数错误"
+// @Router /agent/api/v1/llm/quota/create [post]
+func (h *LLMUsage) QuotaCreate(c *gin.Context) {
+	var req dto.LLMQuotaCreateReq
+	var resp *dto.LLMQuotaCreateResp
+	var err error
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.FailWithMessage(c, "参数错误: "+err.Error())
+		return
+	}
+
+	defer func() {
+		logger.Infof(c, "LLMUsage.QuotaCreate req:%+v resp:%+v err:%v", req, resp, err)
+	}()
+
+	ctx := contextx.ToContext(c)
+	quota := &model.LLMQuota{
+		Scope:      req.Scope,
+		Target:     req.Target,
+		Period:     req.Period,
+		TokenLimit: req.TokenLimit,
+		CostLimit:  req.CostLimit,
+		Enabled:    req.Enabled,
+		Remark:     req.Remark,
+	}
+	if err = h.service.CreateQuota(ctx, quota); err != nil {
+		response.FailWithMessage(c, err.Error())
+		return
+	}
+
+	resp = &dto.LLMQuotaCreateResp{ID: quota.ID}
+	response.OkWithData(c, resp)
+}
+
+// QuotaUpdate 更新配额
+// @Summary 更新LLM用量配额
+// @Description 更新配额上限、启用状态和备注（范围、对象和周期不可修改，仅用量管理员）
+// @Tags LLM管理
+// @Accept json
+// @Produce json
+// @Param request body dto.LLMQuotaUpdateReq true "更新配额请求"
+// @Success 200 "更新成功"
+// @Failure 400 {string} string "请求参数错误"
+// @Router /agent/api/v1/llm/quota/update [post]
+func (h *LLMUsage) QuotaUpdate(c *gin.Context) {
+	var req dto.LLMQuotaUpdateReq
+	var err error
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.FailWithMessage(c, "参数错误: "+err.Error())
+		return
+	}
+
+	defer func() {
+		logger.Infof(c, "LLMUsage.QuotaUpdate req:%+v err:%v", req, err)
+	}()
+
+	ctx := contextx.ToContext(c)
+	if err = h.service.UpdateQuota(ctx, &req); err != nil {
+		response.FailWithMessage(c, err.Error())
+		return
+	}
+
+	response.OkWithMessage(c, "更新成功")
+}
+
+// QuotaDelete 删除配额
+// @Summary 删除LLM用量配额
+// @Description 删除配额（仅用量管理员）
+// @Tags LLM管理
+// @Accept json
+// @Produce json
+// @Param id query int true "配额ID"
+// @Success 200 "删除成功"
+// @Failure 400 {string} string "请求参数错误"
+// @Router /agent/api/v1/llm/quota/delete [post]
+func (h *LLMUsage) QuotaDelete(c *gin.Context) {
+	var req dto.LLMQuotaDeleteReq
+	var err error
+
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.FailWithMessage(c, "参数错误: "+err.Error())
+		return
+	}
+
+	defer func() {
+		logger.Infof(c, "LLMUsage.QuotaDelete req:%+v err:%v", req, err)
+	}()
+
+	ctx := contextx.ToContext(c)
+	if err = h.service.DeleteQuota(ctx, req.ID); err != nil {
+		response.FailWithMessage(c, err.Error())
+		return
+	}
+
+	response.OkWithMessage(c, "删除成功")
+}
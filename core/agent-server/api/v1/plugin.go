@@ -36,7 +36,7 @@ func NewPlugin(service *service.PluginService, cfg *config.AgentServerConfig) *P
 
 // List 获取插件列表
 // @Summary 获取插件列表
-// @Description 获取所有可用插件列表
+// @Description 获取所有可用插件列表（包含在线状态和调用延迟）
 // @Tags 插件管理
 // @Accept json
 // @Produce json
@@ -88,6 +88,7 @@ func (h *Plugin) List(c *gin.Context) {
 			Visibility:  plugin.Visibility,
 			Admin:       plugin.Admin,
 			IsAdmin:     utils.IsAdmin(plugin.Admin, currentUser),
+			Runtime:     h.service.GetRuntimeStatus(plugin.Subject),
 			CreatedAt:   time.Time(plugin.CreatedAt).Format("2006-01-02T15:04:05Z"),
 			UpdatedAt:   time.Time(plugin.UpdatedAt).Format("2006-01-02T15:04:05Z"),
 		})
@@ -236,7 +237,7 @@ func (h *Plugin) Update(c *gin.Context) {
 
 // Get 获取插件详情
 // @Summary 获取插件详情
-// @Description 根据ID获取插件详情
+// @Description 根据ID获取插件详情（包含在线实例、版本、声明的输入/输出结构和调用延迟）
 // @Tags 插件管理
 // @Accept json
 // @Produce json
@@ -281,6 +282,7 @@ func (h *Plugin) Get(c *gin.Context) {
 		NatsHost:    h.getNatsHost(),
 		Config:      plugin.Config,
 		User:        plugin.User,
+		Runtime:     h.service.GetRuntimeStatus(plugin.Subject),
 		CreatedAt:   time.Time(plugin.CreatedAt).Format("2006-01-02T15:04:05Z"),
 		UpdatedAt:   time.Time(plugin.UpdatedAt).Format("2006-01-02T15:04:05Z"),
 	}
@@ -28,6 +28,10 @@ type Agent struct {
 	// 格式：agent.{chat_type}.{创建用户}.{智能体id}
 	// 注意：新架构中应该使用 Plugin.Subject，此字段保留用于向后兼容
 	MsgSubject string `gorm:"type:varchar(512);index" json:"msg_subject"` // 消息主题（已废弃）
+
+	// 流水线（JSON 数组，允许为 NULL），配置后替代单个插件调用
+	// 例如：[{"name":"解析Excel","type":"plugin","plugin_id":1},{"name":"推断表结构","type":"llm","prompt":"..."}]
+	Pipeline *string `gorm:"type:json;comment:流水线步骤" json:"pipeline"`
 	
 	// 知识库关联（两种类型都需要）
 	KnowledgeBaseID int64        `gorm:"type:bigint;not null;index;comment:知识库ID" json:"knowledge_base_id"`
@@ -37,6 +41,13 @@ type Agent struct {
 	LLMConfigID int64    `gorm:"type:bigint;index;comment:LLM配置ID" json:"llm_config_id"`
 	LLMConfig   LLMConfig `gorm:"foreignKey:LLMConfigID" json:"llm_config,omitempty"` // 预加载关联
 
+	// LLM 路由策略（JSON，允许为 NULL）：备用 LLM、选择策略、重试规则，未配置时只调用 LLMConfigID 对应的 LLM
+	// 例如：{"strategy":"failover","fallback_llm_config_ids":[2,3],"retry_on":["rate_limit"],"max_retries":1}
+	LLMRouting *string `gorm:"type:json;comment:LLM路由策略" json:"llm_routing"`
+
+	// 会话记忆策略（JSON，对应 dto.AgentMemory），为空表示发送全部历史消息
+	Memory *string `gorm:"type:json;comment:会话记忆策略" json:"memory"`
+
 	// System Prompt 模板（支持 {knowledge} 变量，会被替换为知识库内容）
 	// 如果为空，使用默认模板："你是一个专业的代码生成助手。以下是相关的知识库内容，请参考这些内容来生成代码：\n{knowledge}"
 	SystemPromptTemplate string `gorm:"type:text;comment:System Prompt模板" json:"system_prompt_template"`
@@ -57,6 +68,11 @@ type Agent struct {
 
 	// 使用统计
 	GenerationCount int64 `gorm:"type:bigint;default:0;index;comment:生成次数统计" json:"generation_count"` // 生成次数统计
+
+	// Hub 发布信息（发布到 Hub 后记录，用于发布新版本）
+	HubAgentID    int64  `gorm:"index;default:0;comment:关联的Hub智能体ID（如果已发布到Hub）" json:"hub_agent_id"`    // 关联的Hub智能体ID
+	HubVersion    string `gorm:"type:varchar(50);default:'';comment:Hub智能体版本（如 v1）" json:"hub_version"` // 最近发布的版本
+	HubVersionNum int    `gorm:"default:0;comment:Hub智能体版本号（数字部分）" json:"hub_version_num"`              // 最近发布的版本号（数字部分）
 }
 
 // TableName 指定表名
@@ -13,6 +13,16 @@ type AgentChatMessage struct {
 	Content   string  `gorm:"type:longtext;comment:消息内容" json:"content"`
 	Files     *string `gorm:"type:json;comment:文件列表（JSON格式）" json:"files"` // 存储文件URL数组的JSON，可为NULL
 	User      string  `gorm:"type:varchar(128);not null;index;comment:创建用户" json:"user"`
+
+	// 消息树：ParentID 为上一条消息（0 表示会话的第一条消息）
+	// 重新生成的 assistant 消息与原消息同一个父消息，编辑后重发的用户消息与原消息同一个父消息，形成分支
+	ParentID int64 `gorm:"type:bigint;default:0;index;comment:父消息ID" json:"parent_id"`
+	RecordID int64 `gorm:"type:bigint;default:0;comment:函数生成记录ID（仅 assistant 消息）" json:"record_id"`
+
+	// 实际提供服务的 LLM（仅 assistant 消息）
+	LLMConfigID int64  `gorm:"type:bigint;default:0;comment:实际调用的LLM配置ID" json:"llm_config_id"`
+	LLMProvider string `gorm:"type:varchar(32);comment:实际调用的LLM提供商" json:"llm_provider"`
+	LLMModel    string `gorm:"type:varchar(128);comment:实际调用的LLM模型" json:"llm_model"`
 }
 
 // TableName 指定表名
@@ -13,7 +13,16 @@ type AgentChatSession struct {
 	Title     string `gorm:"type:varchar(255);comment:会话标题" json:"title"`              // 自动生成或用户自定义
 	Status    string `gorm:"type:varchar(32);not null;default:'active';index;comment:会话状态(active/generating/done)" json:"status"` // 会话状态
 	User      string `gorm:"type:varchar(128);not null;index;comment:创建用户" json:"user"`
-	
+
+	// 会话记忆：较早对话的滚动摘要（智能体启用会话记忆后生成），SummaryUntilID 之前（含）的消息不再原样发送给 LLM
+	Summary        string `gorm:"type:longtext;comment:较早对话的摘要" json:"summary"`
+	SummaryUntilID int64  `gorm:"type:bigint;default:0;comment:摘要覆盖到的消息ID" json:"summary_until_id"`
+
+	// 消息分支：CurrentMessageID 为当前分支的最后一条消息（0 表示旧会话，消息按时间顺序排列）
+	// AppliedRecordID 为最近一次成功写入工作空间的生成记录（对应某个分支的 assistant 消息）
+	CurrentMessageID int64 `gorm:"type:bigint;default:0;comment:当前分支的最后一条消息ID" json:"current_message_id"`
+	AppliedRecordID  int64 `gorm:"type:bigint;default:0;comment:已应用到工作空间的生成记录ID" json:"applied_record_id"`
+
 	// 关联的智能体（预加载）
 	Agent *Agent `gorm:"foreignKey:AgentID" json:"agent,omitempty"`
 }
@@ -26,7 +26,15 @@ type FunctionGenRecord struct {
 	// 包含：用户消息、上传的文件、插件处理结果等
 	// 例如：{"user_message": "生成一个工单系统", "files": [{"url": "...", "remark": "..."}], "plugin_data": "..."}
 	Metadata *string `gorm:"type:json;comment:生成过程元数据" json:"metadata"`
+
+	// 流水线各步骤的执行记录（JSON 数组），包含每一步的输入、输出和耗时，便于排查
+	PipelineSteps *string `gorm:"type:json;comment:流水线步骤执行记录" json:"pipeline_steps"`
 	
+	// 实际提供服务的 LLM（配置了备用 LLM 时可能与智能体绑定的不同）
+	LLMConfigID int64  `gorm:"type:bigint;default:0;index;comment:实际调用的LLM配置ID" json:"llm_config_id"`
+	LLMProvider string `gorm:"type:varchar(32);comment:实际调用的LLM提供商" json:"llm_provider"`
+	LLMModel    string `gorm:"type:varchar(128);comment:实际调用的LLM模型" json:"llm_model"`
+
 	// 生成耗时（秒，从创建记录到完成/失败的时间）
 	Duration int `gorm:"type:int;default:0;comment:生成耗时(秒)" json:"duration"`
 	
@@ -13,11 +13,15 @@ func InitTables(db *gorm.DB) error {
 		&KnowledgeBase{},
 		&KnowledgeDocument{},
 		&KnowledgeChunk{},
+		&KnowledgeIngestJob{},
+		&KnowledgeIngestFile{},
 		&LLMConfig{},
 		&AgentChatSession{},
 		&AgentChatMessage{},
 		&FunctionGenRecord{},
 		&FunctionGroupAgent{},
+		&LLMUsage{},
+		&LLMQuota{},
 	); err != nil {
 		return err
 	}
@@ -48,6 +48,8 @@ type KnowledgeDocument struct {
 	Status          string `gorm:"column:status;comment:状态(completed/failed)" json:"status"`
 	SortOrder       int    `gorm:"column:sort_order;default:0;index;comment:排序字段（数字越小越靠前）" json:"sort_order"`
 	Path            string `gorm:"column:path;type:varchar(512);comment:路径（用于快速查询，如：/目录1/目录2/文档）" json:"path"`
+	ContentHash     string `gorm:"column:content_hash;type:varchar(64);comment:文档内容哈希值" json:"content_hash"`
+	SourcePath      string `gorm:"column:source_path;type:varchar(512);index;comment:批量导入来源中的相对路径（目录以/结尾）" json:"source_path"`
 	User            string `gorm:"column:user;comment:上传用户" json:"user"`
 }
 
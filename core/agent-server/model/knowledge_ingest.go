@@ -0,0 +1,79 @@
+package model
+
+import (
+	"github.com/ai-agent-os/ai-agent-os/pkg/gormx/models"
+)
+
+// 知识库批量导入来源
+const (
+	KnowledgeIngestSourceArchive = "archive" // 上传的 zip/tar 压缩包
+	KnowledgeIngestSourceGit     = "git"     // 服务器上的本地 git 仓库（读取指定提交的文件）
+)
+
+// 知识库批量导入任务状态
+const (
+	KnowledgeIngestStatusPending   = "pending"
+	KnowledgeIngestStatusRunning   = "running"
+	KnowledgeIngestStatusSucceeded = "succeeded" // 全部文件处理完成（可能有个别文件失败，见 Failed）
+	KnowledgeIngestStatusFailed    = "failed"    // 任务整体失败（如读取来源失败）
+)
+
+// 知识库批量导入文件状态
+const (
+	KnowledgeIngestFileCreated   = "created"   // 新建文档
+	KnowledgeIngestFileUpdated   = "updated"   // 内容变化，已更新文档
+	KnowledgeIngestFileUnchanged = "unchanged" // 内容哈希未变化，跳过
+	KnowledgeIngestFileDeleted   = "deleted"   // 来源中已不存在，已删除文档
+	KnowledgeIngestFileFailed    = "failed"    // 转换或写入失败
+)
+
+// KnowledgeIngestJob 知识库批量导入任务
+// 导入在后台执行，目录结构保留为文档树（目录对应一个空内容的文档），按内容哈希增量更新
+type KnowledgeIngestJob struct {
+	models.Base
+	KnowledgeBaseID int64  `gorm:"type:bigint;not null;index;comment:知识库ID" json:"knowledge_base_id"`
+	SourceType      string `gorm:"type:varchar(16);not null;comment:来源类型(archive/git)" json:"source_type"`
+	Source          string `gorm:"type:varchar(512);comment:来源（压缩包文件名或仓库路径）" json:"source"`
+	Ref             string `gorm:"type:varchar(255);comment:git 引用（分支/标签/提交）" json:"ref"`
+	Commit          string `gorm:"type:varchar(64);comment:实际读取的 git 提交" json:"commit"`
+	SubDir          string `gorm:"type:varchar(512);comment:只导入来源中的子目录" json:"sub_dir"`
+	DeleteMissing   bool   `gorm:"default:false;comment:是否删除来源中已不存在的文档" json:"delete_missing"`
+	Status          string `gorm:"type:varchar(16);not null;index;comment:状态(pending/running/succeeded/failed)" json:"status"`
+	Total           int    `gorm:"type:int;default:0;comment:待处理的文件数" json:"total"`
+	Processed       int    `gorm:"type:int;default:0;comment:已处理的文件数" json:"processed"`
+	Created         int    `gorm:"type:int;default:0;comment:新建文档数" json:"created"`
+	Updated         int    `gorm:"type:int;default:0;comment:更新文档数" json:"updated"`
+	Unchanged       int    `gorm:"type:int;default:0;comment:未变化文档数" json:"unchanged"`
+	Deleted         int    `gorm:"type:int;default:0;comment:删除文档数" json:"deleted"`
+	Failed          int    `gorm:"type:int;default:0;comment:失败文件数" json:"failed"`
+	Skipped         int    `gorm:"type:int;default:0;comment:不支持的文件数" json:"skipped"`
+	ErrorMsg        string `gorm:"type:text;comment:错误信息" json:"error_msg"`
+	Duration        int    `gorm:"type:int;default:0;comment:耗时(秒)" json:"duration"`
+	User            string `gorm:"type:varchar(128);not null;index;comment:创建用户" json:"user"`
+}
+
+// TableName 指定表名
+func (KnowledgeIngestJob) TableName() string {
+	return "knowledge_ingest_jobs"
+}
+
+// Finished 任务是否已结束
+func (j *KnowledgeIngestJob) Finished() bool {
+	return j.Status == KnowledgeIngestStatusSucceeded || j.Status == KnowledgeIngestStatusFailed
+}
+
+// KnowledgeIngestFile 知识库批量导入的单个文件处理结果
+type KnowledgeIngestFile struct {
+	models.Base
+	JobID      int64  `gorm:"type:bigint;not null;index;comment:导入任务ID" json:"job_id"`
+	Path       string `gorm:"type:varchar(512);not null;comment:来源中的相对路径" json:"path"`
+	Status     string `gorm:"type:varchar(16);not null;index;comment:状态(created/updated/unchanged/deleted/failed)" json:"status"`
+	DocumentID int64  `gorm:"type:bigint;default:0;comment:对应的文档ID" json:"document_id"`
+	FileType   string `gorm:"type:varchar(16);comment:原始文件类型" json:"file_type"`
+	ErrorMsg   string `gorm:"type:text;comment:错误信息" json:"error_msg"`
+}
+
+// TableName 指定表名
+func (KnowledgeIngestFile) TableName() string {
+	return "knowledge_ingest_files"
+}
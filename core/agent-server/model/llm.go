@@ -8,16 +8,21 @@ import (
 // LLMConfig LLM 配置模型
 type LLMConfig struct {
 	models.Base
-	Name       string `gorm:"type:varchar(255);not null" json:"name"`
-	Provider   string `gorm:"type:varchar(32);not null;index" json:"provider"` // openai, claude, local, etc.
-	Model      string `gorm:"type:varchar(128);not null" json:"model"`         // gpt-4, claude-3, etc.
-	APIKey     string `gorm:"type:varchar(512)" json:"api_key"`                // 加密存储
-	APIBase    string `gorm:"type:varchar(512)" json:"api_base"`
-	Timeout    int    `gorm:"default:120" json:"timeout"`     // 超时时间（秒）
-	MaxTokens  int    `gorm:"default:4000" json:"max_tokens"` // 最大 token 数
-	ExtraConfig *string `gorm:"type:json" json:"extra_config"`  // JSON 额外配置
-	UseThinking bool   `gorm:"default:false;comment:是否使用思考模式" json:"use_thinking"` // 是否使用思考模式（GLM特有功能）
-	IsDefault  bool   `gorm:"default:false;index" json:"is_default"`
+	Name        string  `gorm:"type:varchar(255);not null" json:"name"`
+	Provider    string  `gorm:"type:varchar(32);not null;index" json:"provider"` // openai, claude, local, etc.
+	Model       string  `gorm:"type:varchar(128);not null" json:"model"`         // gpt-4, claude-3, etc.
+	APIKey      string  `gorm:"type:varchar(512)" json:"api_key"`                // 加密存储
+	APIBase     string  `gorm:"type:varchar(512)" json:"api_base"`
+	Timeout     int     `gorm:"default:120" json:"timeout"`                                 // 超时时间（秒）
+	MaxTokens   int     `gorm:"default:4000" json:"max_tokens"`                             // 最大 token 数
+	ExtraConfig *string `gorm:"type:json" json:"extra_config"`                              // JSON 额外配置
+	UseThinking bool    `gorm:"default:false;comment:是否使用思考模式" json:"use_thinking"`         // 是否使用思考模式（GLM特有功能）
+	Cost        float64 `gorm:"type:decimal(12,6);default:0;comment:每千token成本" json:"cost"` // 每千 token 成本（智能体按成本路由时使用，0 表示按价格计算）
+	IsDefault   bool    `gorm:"default:false;index" json:"is_default"`
+
+	// 价格（每千 token，用于用量统计的费用计算，0 表示不计费）
+	PromptPrice     float64 `gorm:"type:decimal(12,6);default:0;comment:输入每千token价格" json:"prompt_price"`
+	CompletionPrice float64 `gorm:"type:decimal(12,6);default:0;comment:输出每千token价格" json:"completion_price"`
 
 	// 权限控制
 	Visibility int    `gorm:"type:tinyint;default:0;index;comment:可见性(0:公开,1:私有)" json:"visibility"` // 0: 公开, 1: 私有
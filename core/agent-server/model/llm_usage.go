@@ -0,0 +1,67 @@
+package model
+
+import (
+	"github.com/ai-agent-os/ai-agent-os/pkg/gormx/models"
+)
+
+// LLM 调用场景
+const (
+	LLMUsageSceneChat        = "chat"         // 智能体聊天
+	LLMUsageSceneFunctionGen = "function_gen" // 函数生成
+	LLMUsageScenePipeline    = "pipeline"     // 流水线 LLM 步骤
+	LLMUsageSceneSummary     = "summary"      // 会话摘要
+)
+
+// LLMUsage LLM 调用用量记录（每次成功调用一条）
+type LLMUsage struct {
+	models.Base
+	User             string  `gorm:"type:varchar(128);not null;index;comment:调用用户" json:"user"`
+	Department       string  `gorm:"type:varchar(512);index;comment:调用时用户所属部门完整路径" json:"department"`
+	AgentID          int64   `gorm:"type:bigint;index;comment:智能体ID" json:"agent_id"`
+	LLMConfigID      int64   `gorm:"type:bigint;index;comment:LLM配置ID" json:"llm_config_id"`
+	Provider         string  `gorm:"type:varchar(32);comment:LLM提供商" json:"provider"`
+	Model            string  `gorm:"type:varchar(128);index;comment:LLM模型" json:"model"`
+	Scene            string  `gorm:"type:varchar(32);index;comment:调用场景(chat/function_gen/pipeline/summary)" json:"scene"`
+	RecordID         int64   `gorm:"type:bigint;default:0;comment:函数生成记录ID" json:"record_id"`
+	PromptTokens     int     `gorm:"type:int;default:0;comment:输入token数" json:"prompt_tokens"`
+	CompletionTokens int     `gorm:"type:int;default:0;comment:输出token数" json:"completion_tokens"`
+	TotalTokens      int     `gorm:"type:int;default:0;comment:总token数" json:"total_tokens"`
+	Estimated        bool    `gorm:"default:false;comment:是否为估算值(提供商未返回用量)" json:"estimated"`
+	Cost             float64 `gorm:"type:decimal(16,6);default:0;comment:费用" json:"cost"`
+}
+
+// TableName 指定表名
+func (LLMUsage) TableName() string {
+	return "llm_usages"
+}
+
+// 配额范围
+const (
+	LLMQuotaScopeUser       = "user"       // 用户（Target 为用户名）
+	LLMQuotaScopeDepartment = "department" // 部门（Target 为部门完整路径，包含子部门）
+	LLMQuotaScopeAgent      = "agent"      // 智能体（Target 为智能体ID）
+)
+
+// 配额周期
+const (
+	LLMQuotaPeriodDaily   = "daily"
+	LLMQuotaPeriodMonthly = "monthly"
+)
+
+// LLMQuota LLM 用量配额
+// 调用 LLM 之前检查当前周期内的用量，token 数或费用达到上限时拒绝调用
+type LLMQuota struct {
+	models.Base
+	Scope      string  `gorm:"type:varchar(16);not null;uniqueIndex:uk_llm_quota;comment:配额范围(user/department/agent)" json:"scope"`
+	Target     string  `gorm:"type:varchar(512);not null;uniqueIndex:uk_llm_quota;comment:配额对象" json:"target"`
+	Period     string  `gorm:"type:varchar(16);not null;uniqueIndex:uk_llm_quota;comment:配额周期(daily/monthly)" json:"period"`
+	TokenLimit int64   `gorm:"type:bigint;default:0;comment:token上限(0表示不限制)" json:"token_limit"`
+	CostLimit  float64 `gorm:"type:decimal(16,6);default:0;comment:费用上限(0表示不限制)" json:"cost_limit"`
+	Enabled    bool    `gorm:"default:true;index;comment:是否启用" json:"enabled"`
+	Remark     string  `gorm:"type:varchar(512);comment:备注" json:"remark"`
+}
+
+// TableName 指定表名
+func (LLMQuota) TableName() string {
+	return "llm_quotas"
+}
@@ -126,3 +126,11 @@ func (r *AgentRepository) Disable(id int64) error {
 	return r.db.Model(&model.Agent{}).Where("id = ?", id).Update("enabled", false).Error
 }
 
+// UpdateHubInfo 更新智能体的 Hub 发布信息
+func (r *AgentRepository) UpdateHubInfo(id int64, hubAgentID int64, hubVersion string, hubVersionNum int) error {
+	return r.db.Model(&model.Agent{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"hub_agent_id":    hubAgentID,
+		"hub_version":     hubVersion,
+		"hub_version_num": hubVersionNum,
+	}).Error
+}
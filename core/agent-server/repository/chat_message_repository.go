@@ -66,6 +66,13 @@ func (r *ChatMessageRepository) ListBySessionIDWithLimit(sessionID string, limit
 	return messages, nil
 }
 
+// UpdateParentID 更新消息的父消息（旧会话升级为消息树时使用）
+func (r *ChatMessageRepository) UpdateParentID(id, parentID int64) error {
+	return r.db.Model(&model.AgentChatMessage{}).
+		Where("id = ?", id).
+		Update("parent_id", parentID).Error
+}
+
 // DeleteBySessionID 根据 SessionID 删除所有消息
 func (r *ChatMessageRepository) DeleteBySessionID(sessionID string) error {
 	return r.db.Where("session_id = ?", sessionID).Delete(&model.AgentChatMessage{}).Error
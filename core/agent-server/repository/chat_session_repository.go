@@ -60,6 +60,37 @@ func (r *ChatSessionRepository) Update(session *model.AgentChatSession) error {
 	return r.db.Save(session).Error
 }
 
+// UpdateSummary 更新会话摘要（只更新摘要字段，避免覆盖并发修改的状态）
+func (r *ChatSessionRepository) UpdateSummary(sessionID, summary string, untilID int64) error {
+	return r.db.Model(&model.AgentChatSession{}).
+		Where("session_id = ?", sessionID).
+		Updates(map[string]interface{}{
+			"summary":          summary,
+			"summary_until_id": untilID,
+		}).Error
+}
+
+// UpdateStatus 更新会话状态
+func (r *ChatSessionRepository) UpdateStatus(sessionID, status string) error {
+	return r.db.Model(&model.AgentChatSession{}).
+		Where("session_id = ?", sessionID).
+		Update("status", status).Error
+}
+
+// UpdateCurrentMessage 切换会话的当前分支（分支的最后一条消息）
+func (r *ChatSessionRepository) UpdateCurrentMessage(sessionID string, messageID int64) error {
+	return r.db.Model(&model.AgentChatSession{}).
+		Where("session_id = ?", sessionID).
+		Update("current_message_id", messageID).Error
+}
+
+// UpdateAppliedRecord 记录已应用到工作空间的生成记录
+func (r *ChatSessionRepository) UpdateAppliedRecord(sessionID string, recordID int64) error {
+	return r.db.Model(&model.AgentChatSession{}).
+		Where("session_id = ?", sessionID).
+		Update("applied_record_id", recordID).Error
+}
+
 // Delete 删除会话（根据 SessionID）
 func (r *ChatSessionRepository) Delete(sessionID string) error {
 	return r.db.Where("session_id = ?", sessionID).Delete(&model.AgentChatSession{}).Error
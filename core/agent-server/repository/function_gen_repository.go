@@ -43,6 +43,30 @@ func (r *FunctionGenRepository) GetBySessionID(sessionID string) (*model.Functio
 	return &record, nil
 }
 
+// GetByMessageID 根据用户消息ID获取记录（最新的）
+func (r *FunctionGenRepository) GetByMessageID(messageID int64) (*model.FunctionGenRecord, error) {
+	var record model.FunctionGenRecord
+	if err := r.db.
+		Where("message_id = ?", messageID).
+		Order("id DESC").
+		First(&record).Error; err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+// ListCompletedBySessionID 获取会话中已完成的记录（按创建顺序）
+func (r *FunctionGenRepository) ListCompletedBySessionID(sessionID string) ([]*model.FunctionGenRecord, error) {
+	var records []*model.FunctionGenRecord
+	if err := r.db.
+		Where("session_id = ? AND status = ?", sessionID, model.FunctionGenStatusCompleted).
+		Order("id ASC").
+		Find(&records).Error; err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
 // ListByTreeID 根据 TreeID 获取记录列表
 func (r *FunctionGenRepository) ListByTreeID(treeID int64, offset, limit int) ([]*model.FunctionGenRecord, int64, error) {
 	var records []*model.FunctionGenRecord
@@ -150,6 +174,24 @@ func (r *FunctionGenRepository) UpdateCode(id int64, code string) error {
 		Update("code", code).Error
 }
 
+// UpdatePipelineSteps 更新流水线步骤执行记录（生成之后的步骤执行完时追加）
+func (r *FunctionGenRepository) UpdatePipelineSteps(id int64, steps string) error {
+	return r.db.Model(&model.FunctionGenRecord{}).
+		Where("id = ?", id).
+		Update("pipeline_steps", steps).Error
+}
+
+// UpdateLLMServed 记录实际提供服务的 LLM
+func (r *FunctionGenRepository) UpdateLLMServed(id int64, llmConfigID int64, provider, modelName string) error {
+	return r.db.Model(&model.FunctionGenRecord{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"llm_config_id": llmConfigID,
+			"llm_provider":  provider,
+			"llm_model":     modelName,
+		}).Error
+}
+
 // UpdateCodeAndStatus 更新代码和状态（自动计算耗时，用于兼容旧代码）
 func (r *FunctionGenRepository) UpdateCodeAndStatus(id int64, code string, status string) error {
 	// 获取记录以计算耗时
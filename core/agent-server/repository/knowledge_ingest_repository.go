@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"github.com/ai-agent-os/ai-agent-os/core/agent-server/model"
+	"gorm.io/gorm"
+)
+
+// KnowledgeIngestRepository 知识库批量导入任务数据访问层
+type KnowledgeIngestRepository struct {
+	db *gorm.DB
+}
+
+// NewKnowledgeIngestRepository 创建知识库批量导入 Repository
+func NewKnowledgeIngestRepository(db *gorm.DB) *KnowledgeIngestRepository {
+	return &KnowledgeIngestRepository{db: db}
+}
+
+// CreateJob 创建导入任务
+func (r *KnowledgeIngestRepository) CreateJob(job *model.KnowledgeIngestJob) error {
+	return r.db.Create(job).Error
+}
+
+// GetJob 根据 ID 获取导入任务
+func (r *KnowledgeIngestRepository) GetJob(id int64) (*model.KnowledgeIngestJob, error) {
+	var job model.KnowledgeIngestJob
+	if err := r.db.Where("id = ?", id).First(&job).Error; err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// UpdateJob 更新导入任务（状态和统计）
+func (r *KnowledgeIngestRepository) UpdateJob(job *model.KnowledgeIngestJob) error {
+	return r.db.Save(job).Error
+}
+
+// ListJobs 获取知识库的导入任务列表（最新的在前）
+func (r *KnowledgeIngestRepository) ListJobs(kbID int64, offset, limit int) ([]*model.KnowledgeIngestJob, int64, error) {
+	var jobs []*model.KnowledgeIngestJob
+	var total int64
+	query := r.db.Model(&model.KnowledgeIngestJob{}).Where("knowledge_base_id = ?", kbID)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	if err := query.Offset(offset).Limit(limit).Order("id DESC").Find(&jobs).Error; err != nil {
+		return nil, 0, err
+	}
+	return jobs, total, nil
+}
+
+// HasUnfinishedJob 知识库是否有未结束的导入任务
+func (r *KnowledgeIngestRepository) HasUnfinishedJob(kbID int64) (bool, error) {
+	var count int64
+	err := r.db.Model(&model.KnowledgeIngestJob{}).
+		Where("knowledge_base_id = ? AND status IN ?", kbID, []string{model.KnowledgeIngestStatusPending, model.KnowledgeIngestStatusRunning}).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// FailUnfinishedJobs 把未结束的导入任务标记为失败（服务重启后调用，后台任务已经中断）
+func (r *KnowledgeIngestRepository) FailUnfinishedJobs(errorMsg string) (int64, error) {
+	result := r.db.Model(&model.KnowledgeIngestJob{}).
+		Where("status IN ?", []string{model.KnowledgeIngestStatusPending, model.KnowledgeIngestStatusRunning}).
+		Updates(map[string]interface{}{
+			"status":    model.KnowledgeIngestStatusFailed,
+			"error_msg": errorMsg,
+		})
+	return result.RowsAffected, result.Error
+}
+
+// CreateFiles 批量保存文件处理结果
+func (r *KnowledgeIngestRepository) CreateFiles(files []*model.KnowledgeIngestFile) error {
+	if len(files) == 0 {
+		return nil
+	}
+	return r.db.CreateInBatches(files, 100).Error
+}
+
+// ListFiles 获取导入任务的文件处理结果（status 为空表示全部）
+func (r *KnowledgeIngestRepository) ListFiles(jobID int64, status string, offset, limit int) ([]*model.KnowledgeIngestFile, int64, error) {
+	var files []*model.KnowledgeIngestFile
+	var total int64
+	query := r.db.Model(&model.KnowledgeIngestFile{}).Where("job_id = ?", jobID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	if err := query.Offset(offset).Limit(limit).Order("id ASC").Find(&files).Error; err != nil {
+		return nil, 0, err
+	}
+	return files, total, nil
+}
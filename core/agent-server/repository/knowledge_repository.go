@@ -29,6 +29,15 @@ func (r *KnowledgeRepository) GetByID(id int64) (*model.KnowledgeBase, error) {
 	return &kb, nil
 }
 
+// GetByName 根据名称获取知识库（同名时返回最早创建的）
+func (r *KnowledgeRepository) GetByName(name string) (*model.KnowledgeBase, error) {
+	var kb model.KnowledgeBase
+	if err := r.db.Where("name = ?", name).Order("id ASC").First(&kb).Error; err != nil {
+		return nil, err
+	}
+	return &kb, nil
+}
+
 
 // List 获取知识库列表
 func (r *KnowledgeRepository) List(scope string, currentUser string, offset, limit int) ([]*model.KnowledgeBase, int64, error) {
@@ -65,6 +74,14 @@ func (r *KnowledgeRepository) Update(kb *model.KnowledgeBase) error {
 	return r.db.Save(kb).Error
 }
 
+// UpdateStats 更新知识库的文档数量和内容哈希
+func (r *KnowledgeRepository) UpdateStats(id int64, documentCount int, contentHash string) error {
+	return r.db.Model(&model.KnowledgeBase{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"document_count": documentCount,
+		"content_hash":   contentHash,
+	}).Error
+}
+
 // Delete 删除知识库（根据 ID）
 func (r *KnowledgeRepository) Delete(id int64) error {
 	return r.db.Where("id = ?", id).Delete(&model.KnowledgeBase{}).Error
@@ -128,6 +145,16 @@ func (r *KnowledgeRepository) DeleteDocument(id int64) error {
 	return r.db.Where("id = ?", id).Delete(&model.KnowledgeDocument{}).Error
 }
 
+// DeleteDocumentsByKBID 删除知识库的全部文档和分块
+func (r *KnowledgeRepository) DeleteDocumentsByKBID(kbID int64) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("knowledge_base_id = ?", kbID).Delete(&model.KnowledgeChunk{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("knowledge_base_id = ?", kbID).Delete(&model.KnowledgeDocument{}).Error
+	})
+}
+
 // UpdateDocumentSort 批量更新文档排序
 func (r *KnowledgeRepository) UpdateDocumentSort(updates []struct {
 	ID        int64
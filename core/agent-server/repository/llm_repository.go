@@ -38,6 +38,15 @@ func (r *LLMRepository) GetDefault() (*model.LLMConfig, error) {
 	return &cfg, nil
 }
 
+// ListByModel 根据提供商和模型获取 LLM 配置列表
+func (r *LLMRepository) ListByModel(provider, modelName string) ([]*model.LLMConfig, error) {
+	var configs []*model.LLMConfig
+	if err := r.db.Where("provider = ? AND model = ?", provider, modelName).Order("id ASC").Find(&configs).Error; err != nil {
+		return nil, err
+	}
+	return configs, nil
+}
+
 // List 获取 LLM 配置列表
 func (r *LLMRepository) List(scope string, currentUser string, offset, limit int) ([]*model.LLMConfig, int64, error) {
 	var configs []*model.LLMConfig
@@ -0,0 +1,175 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ai-agent-os/ai-agent-os/core/agent-server/model"
+	"github.com/ai-agent-os/ai-agent-os/dto"
+	"gorm.io/gorm"
+)
+
+// LLMUsageRepository LLM 用量和配额数据访问层
+type LLMUsageRepository struct {
+	db *gorm.DB
+}
+
+// NewLLMUsageRepository 创建 LLM 用量 Repository
+func NewLLMUsageRepository(db *gorm.DB) *LLMUsageRepository {
+	return &LLMUsageRepository{db: db}
+}
+
+// Create 创建用量记录
+func (r *LLMUsageRepository) Create(usage *model.LLMUsage) error {
+	return r.db.Create(usage).Error
+}
+
+// SumSince 统计配额范围内从 since 开始的用量（token 数和费用）
+func (r *LLMUsageRepository) SumSince(scope, target string, since time.Time) (int64, float64, error) {
+	query := r.db.Model(&model.LLMUsage{}).Where("created_at >= ?", since)
+	switch scope {
+	case model.LLMQuotaScopeUser:
+		query = query.Where("user = ?", target)
+	case model.LLMQuotaScopeDepartment:
+		query = query.Where("(department = ? OR department LIKE ?)", target, target+"/%")
+	case model.LLMQuotaScopeAgent:
+		query = query.Where("agent_id = ?", target)
+	default:
+		return 0, 0, fmt.Errorf("配额范围不支持: %s", scope)
+	}
+
+	var result struct {
+		Tokens int64
+		Cost   float64
+	}
+	if err := query.Select("COALESCE(SUM(total_tokens), 0) AS tokens, COALESCE(SUM(cost), 0) AS cost").Scan(&result).Error; err != nil {
+		return 0, 0, err
+	}
+	return result.Tokens, result.Cost, nil
+}
+
+// LLMUsageReportFilter 用量报表查询条件
+type LLMUsageReportFilter struct {
+	GroupBy     string // user/department/agent/model
+	Interval    string // day/month，为空表示不按时间分组
+	Start       time.Time
+	End         time.Time // 不包含
+	User        string
+	Department  string // 包含子部门
+	AgentID     int64
+	LLMConfigID int64
+}
+
+// Report 按维度和时间段聚合用量
+func (r *LLMUsageRepository) Report(filter LLMUsageReportFilter) ([]dto.LLMUsageReportRow, error) {
+	var keyExpr string
+	switch filter.GroupBy {
+	case "user":
+		keyExpr = "user"
+	case "department":
+		keyExpr = "department"
+	case "agent":
+		keyExpr = "CAST(agent_id AS CHAR)"
+	case "model":
+		keyExpr = "CONCAT(provider, '/', model)"
+	default:
+		return nil, fmt.Errorf("分组维度不支持: %s", filter.GroupBy)
+	}
+	periodExpr, groupExpr := "''", keyExpr
+	switch filter.Interval {
+	case "":
+	case "day":
+		periodExpr = "DATE_FORMAT(created_at, '%Y-%m-%d')"
+	case "month":
+		periodExpr = "DATE_FORMAT(created_at, '%Y-%m')"
+	default:
+		return nil, fmt.Errorf("时间粒度不支持: %s", filter.Interval)
+	}
+	if filter.Interval != "" {
+		groupExpr = keyExpr + ", " + periodExpr
+	}
+
+	query := r.db.Model(&model.LLMUsage{}).
+		Where("created_at >= ? AND created_at < ?", filter.Start, filter.End)
+	if filter.User != "" {
+		query = query.Where("user = ?", filter.User)
+	}
+	if filter.Department != "" {
+		query = query.Where("(department = ? OR department LIKE ?)", filter.Department, filter.Department+"/%")
+	}
+	if filter.AgentID > 0 {
+		query = query.Where("agent_id = ?", filter.AgentID)
+	}
+	if filter.LLMConfigID > 0 {
+		query = query.Where("llm_config_id = ?", filter.LLMConfigID)
+	}
+
+	var rows []dto.LLMUsageReportRow
+	err := query.Select(fmt.Sprintf(`%s AS `+"`key`"+`, %s AS period, COUNT(*) AS calls,
+		COALESCE(SUM(prompt_tokens), 0) AS prompt_tokens, COALESCE(SUM(completion_tokens), 0) AS completion_tokens,
+		COALESCE(SUM(total_tokens), 0) AS total_tokens, COALESCE(SUM(cost), 0) AS cost`, keyExpr, periodExpr)).
+		Group(groupExpr).
+		Order("period ASC, total_tokens DESC").
+		Scan(&rows).Error
+	return rows, err
+}
+
+// CreateQuota 创建配额
+func (r *LLMUsageRepository) CreateQuota(quota *model.LLMQuota) error {
+	return r.db.Create(quota).Error
+}
+
+// GetQuotaByID 根据 ID 获取配额
+func (r *LLMUsageRepository) GetQuotaByID(id int64) (*model.LLMQuota, error) {
+	var quota model.LLMQuota
+	if err := r.db.Where("id = ?", id).First(&quota).Error; err != nil {
+		return nil, err
+	}
+	return &quota, nil
+}
+
+// UpdateQuota 更新配额
+func (r *LLMUsageRepository) UpdateQuota(quota *model.LLMQuota) error {
+	return r.db.Save(quota).Error
+}
+
+// DeleteQuota 删除配额
+func (r *LLMUsageRepository) DeleteQuota(id int64) error {
+	return r.db.Delete(&model.LLMQuota{}, id).Error
+}
+
+// ListQuotas 获取配额列表
+func (r *LLMUsageRepository) ListQuotas(scope, target string, offset, limit int) ([]*model.LLMQuota, int64, error) {
+	var quotas []*model.LLMQuota
+	var total int64
+
+	query := r.db.Model(&model.LLMQuota{})
+	if scope != "" {
+		query = query.Where("scope = ?", scope)
+	}
+	if target != "" {
+		query = query.Where("target = ?", target)
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	if err := query.Order("id DESC").Offset(offset).Limit(limit).Find(&quotas).Error; err != nil {
+		return nil, 0, err
+	}
+	return quotas, total, nil
+}
+
+// ListMatchingQuotas 获取对本次调用生效的配额（用户、用户所在部门及上级部门、智能体）
+func (r *LLMUsageRepository) ListMatchingQuotas(user, department string, agentID int64) ([]*model.LLMQuota, error) {
+	var quotas []*model.LLMQuota
+	cond := r.db.Where("scope = ? AND target = ?", model.LLMQuotaScopeUser, user).
+		Or("scope = ? AND target = ?", model.LLMQuotaScopeAgent, fmt.Sprintf("%d", agentID))
+	if department != "" {
+		cond = cond.Or("scope = ? AND (target = ? OR ? LIKE CONCAT(target, '/%'))", model.LLMQuotaScopeDepartment, department, department)
+	}
+	query := r.db.Where("enabled = ?", true).Where(cond)
+	if err := query.Find(&quotas).Error; err != nil {
+		return nil, err
+	}
+	return quotas, nil
+}
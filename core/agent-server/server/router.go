@@ -31,13 +31,16 @@ func (s *Server) setupRoutes() {
 	// 智能体管理路由
 	agents := apiV1.Group("/agents")
 	agentHandler := v1.NewAgent(s.agentService, s.cfg)
-	agents.GET("/list", agentHandler.List)        // 获取智能体列表（前端调用）
-	agents.GET("/get", agentHandler.Get)          // 获取智能体详情
-	agents.POST("/create", agentHandler.Create)   // 创建智能体
-	agents.POST("/update", agentHandler.Update)   // 更新智能体
-	agents.POST("/delete", agentHandler.Delete)   // 删除智能体
-	agents.POST("/enable", agentHandler.Enable)   // 启用智能体
-	agents.POST("/disable", agentHandler.Disable) // 禁用智能体
+	agents.GET("/list", agentHandler.List)                    // 获取智能体列表（前端调用）
+	agents.GET("/get", agentHandler.Get)                      // 获取智能体详情
+	agents.POST("/create", agentHandler.Create)               // 创建智能体
+	agents.POST("/update", agentHandler.Update)               // 更新智能体
+	agents.POST("/delete", agentHandler.Delete)               // 删除智能体
+	agents.POST("/enable", agentHandler.Enable)               // 启用智能体
+	agents.POST("/disable", agentHandler.Disable)             // 禁用智能体
+	agents.GET("/export", agentHandler.Export)                // 导出智能体（zip 导出包）
+	agents.POST("/import", agentHandler.Import)               // 导入智能体
+	agents.POST("/publish_to_hub", agentHandler.PublishToHub) // 发布智能体到 Hub
 
 	// 知识库管理路由
 	knowledge := apiV1.Group("/knowledge")
@@ -54,6 +57,10 @@ func (s *Server) setupRoutes() {
 	knowledge.POST("/update_document", knowledgeHandler.UpdateDocument)    // 更新文档
 	knowledge.POST("/update_documents_sort", knowledgeHandler.UpdateDocumentsSort) // 批量更新文档排序
 	knowledge.POST("/delete_document", knowledgeHandler.DeleteDocument)     // 删除文档
+	knowledge.POST("/ingest/archive", knowledgeHandler.IngestArchive)       // 从压缩包批量导入文档（后台任务）
+	knowledge.POST("/ingest/git", knowledgeHandler.IngestGit)               // 从本地 git 仓库批量导入文档（后台任务）
+	knowledge.GET("/ingest/jobs", knowledgeHandler.ListIngestJobs)          // 获取批量导入任务列表
+	knowledge.GET("/ingest/job", knowledgeHandler.GetIngestJob)             // 获取批量导入任务详情（含文件处理结果）
 
 	// LLM 配置管理路由
 	llm := apiV1.Group("/llm")
@@ -66,6 +73,14 @@ func (s *Server) setupRoutes() {
 	llm.POST("/delete", llmHandler.Delete)         // 删除LLM配置
 	llm.POST("/set_default", llmHandler.SetDefault) // 设置默认LLM配置
 
+	// LLM 用量和配额路由
+	llmUsageHandler := v1.NewLLMUsage(s.llmUsageService)
+	llm.GET("/usage/report", llmUsageHandler.Report)       // 获取用量报表
+	llm.GET("/quota/list", llmUsageHandler.QuotaList)      // 获取配额列表
+	llm.POST("/quota/create", llmUsageHandler.QuotaCreate) // 创建配额
+	llm.POST("/quota/update", llmUsageHandler.QuotaUpdate) // 更新配额
+	llm.POST("/quota/delete", llmUsageHandler.QuotaDelete) // 删除配额
+
 	// 插件管理路由
 	plugins := apiV1.Group("/plugins")
 	pluginHandler := v1.NewPlugin(s.pluginService, s.cfg)
@@ -80,10 +95,15 @@ func (s *Server) setupRoutes() {
 	// 智能体聊天路由（按 chat_type 区分）
 	agentChatHandler := v1.NewAgentChat(s.agentChatService)
 	chat := apiV1.Group("/chat")
-	chat.POST("/function_gen", agentChatHandler.FunctionGenChat)              // 智能体聊天 - 函数生成类型
-	chat.GET("/function_gen/status", agentChatHandler.GetFunctionGenStatus)    // 查询代码生成状态
-	chat.GET("/sessions", agentChatHandler.ListSessions)                      // 获取会话列表
-	chat.GET("/messages", agentChatHandler.ListMessages)                      // 获取消息列表
+	chat.POST("/function_gen", agentChatHandler.FunctionGenChat)                  // 智能体聊天 - 函数生成类型
+	chat.GET("/function_gen/status", agentChatHandler.GetFunctionGenStatus)       // 查询代码生成状态
+	chat.GET("/function_gen/stream", agentChatHandler.StreamFunctionGen)          // 订阅代码生成过程（SSE）
+	chat.POST("/function_gen/regenerate", agentChatHandler.RegenerateFunctionGen) // 重新生成回答
+	chat.POST("/function_gen/edit", agentChatHandler.EditFunctionGen)             // 编辑用户消息后重新发送
+	chat.POST("/function_gen/apply", agentChatHandler.ApplyFunctionGen)           // 把某个分支的代码应用到工作空间
+	chat.POST("/branch/switch", agentChatHandler.SwitchBranch)                    // 切换分支
+	chat.GET("/sessions", agentChatHandler.ListSessions)                          // 获取会话列表
+	chat.GET("/messages", agentChatHandler.ListMessages)                          // 获取消息列表
 
 	// 工作空间相关路由（服务间调用，不需要JWT验证，但需要用户信息中间件）
 	workspace := apiV1.Group("/workspace")
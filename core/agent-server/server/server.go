@@ -40,14 +40,18 @@ type Server struct {
 	functionGroupAgentRepo *repository.FunctionGroupAgentRepository
 	sessionRepo            *repository.ChatSessionRepository
 	messageRepo            *repository.ChatMessageRepository
+	llmUsageRepo           *repository.LLMUsageRepository
 
 	// 服务
 	agentService       *service.AgentService
 	pluginService      *service.PluginService
 	knowledgeService   *service.KnowledgeService
 	llmService         *service.LLMService
+	llmUsageService    *service.LLMUsageService
 	agentChatService   *service.AgentChatService
 	functionGenService *service.FunctionGenService
+	pluginRegistry     *service.PluginRegistry
+	functionGenStream  *service.FunctionGenStream
 
 	// 上下文
 	ctx context.Context
@@ -137,6 +141,18 @@ func (s *Server) Stop(ctx context.Context) error {
 		}
 	}
 
+	// 关闭插件注册表（在 NATS 连接关闭前取消订阅）
+	if s.pluginRegistry != nil {
+		s.pluginRegistry.Close()
+		logger.Infof(ctx, "[Server] Plugin registry closed")
+	}
+
+	// 关闭函数生成流式事件中心
+	if s.functionGenStream != nil {
+		s.functionGenStream.Close()
+		logger.Infof(ctx, "[Server] Function gen stream closed")
+	}
+
 	// 关闭 NATS 连接
 	if s.natsConn != nil {
 		s.natsConn.Close()
@@ -304,18 +320,37 @@ func (s *Server) initServices(ctx context.Context) error {
 	s.messageRepo = messageRepo
 	s.functionGenRepo = repository.NewFunctionGenRepository(s.db)
 	s.functionGroupAgentRepo = repository.NewFunctionGroupAgentRepository(s.db)
+	s.llmUsageRepo = repository.NewLLMUsageRepository(s.db)
+	knowledgeIngestRepo := repository.NewKnowledgeIngestRepository(s.db)
+
+	// 初始化插件在线注册表（接收插件上线/心跳/下线）
+	pluginRegistry, err := service.NewPluginRegistry(s.natsConn)
+	if err != nil {
+		return fmt.Errorf("failed to init plugin registry: %w", err)
+	}
+	s.pluginRegistry = pluginRegistry
+
+	// 初始化函数生成流式事件中心（SSE 推送生成过程）
+	functionGenStream, err := service.NewFunctionGenStream(s.natsConn)
+	if err != nil {
+		return fmt.Errorf("failed to init function gen stream: %w", err)
+	}
+	s.functionGenStream = functionGenStream
 
 	// 初始化 Service
-	s.agentService = service.NewAgentService(s.agentRepo, s.pluginRepo, s.knowledgeRepo)
-	s.pluginService = service.NewPluginService(s.pluginRepo)
-	s.knowledgeService = service.NewKnowledgeService(s.knowledgeRepo)
+	s.agentService = service.NewAgentService(s.agentRepo, s.pluginRepo, s.knowledgeRepo, s.llmRepo, s.pluginRegistry)
+	s.pluginService = service.NewPluginService(s.pluginRepo, s.pluginRegistry)
+	s.knowledgeService = service.NewKnowledgeService(s.knowledgeRepo, knowledgeIngestRepo, s.cfg)
+	// 上次进程退出时未完成的批量导入任务已中断
+	s.knowledgeService.FailInterruptedIngestJobs(ctx)
 	s.llmService = service.NewLLMService(s.llmRepo)
+	s.llmUsageService = service.NewLLMUsageService(s.llmUsageRepo, s.agentRepo, s.cfg)
 
 	// 先初始化函数生成服务（因为 agentChatService 依赖它）
-	s.functionGenService = service.NewFunctionGenService(s.natsConn, s.cfg, s.functionGenRepo)
+	s.functionGenService = service.NewFunctionGenService(s.natsConn, s.cfg, s.functionGenRepo, sessionRepo, s.pluginRepo, s.pluginRegistry, s.functionGenStream)
 
 	// 初始化智能体聊天服务（传入 functionGenService）
-	s.agentChatService = service.NewAgentChatService(s.agentRepo, s.llmRepo, s.knowledgeRepo, s.functionGenService, sessionRepo, messageRepo, s.functionGenRepo)
+	s.agentChatService = service.NewAgentChatService(s.agentRepo, s.llmRepo, s.knowledgeRepo, s.functionGenService, sessionRepo, messageRepo, s.functionGenRepo, service.NewLLMRouter(s.llmRepo, s.llmUsageService))
 
 	logger.Infof(ctx, "[Server] Services initialized successfully")
 	return nil
@@ -0,0 +1,955 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ai-agent-os/ai-agent-os/core/agent-server/model"
+	"github.com/ai-agent-os/ai-agent-os/core/agent-server/utils"
+	"github.com/ai-agent-os/ai-agent-os/dto"
+	"github.com/ai-agent-os/ai-agent-os/pkg/apicall"
+	"github.com/ai-agent-os/ai-agent-os/pkg/contextx"
+	"github.com/ai-agent-os/ai-agent-os/pkg/logger"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	agentBundleMaxFiles    = 5000
+	agentBundleMaxFileSize = 16 << 20 // 导出包中单个文件的最大字节数
+	agentBundleMaxNameLen  = 100      // 导出包中文件名的最大字符数
+)
+
+// agentBundleSecretKeys 插件配置中视为密钥的字段名（小写，包含即匹配），导出时移除，覆盖导入时保留已有值
+var agentBundleSecretKeys = []string{"secret", "token", "password", "passwd", "api_key", "apikey", "access_key", "private_key", "credential", "authorization"}
+
+// agentBundleFile 导出包中的文件（清单除外）
+type agentBundleFile struct {
+	Path    string
+	Content string
+}
+
+// agentImportPlugin 导入计划中的插件（existing 为空表示新建）
+type agentImportPlugin struct {
+	bundle   dto.AgentBundlePlugin
+	existing *model.Plugin
+	action   string
+}
+
+// agentImportKnowledgeBase 导入计划中的知识库（existing 为空表示新建）
+type agentImportKnowledgeBase struct {
+	bundle   dto.AgentBundleKnowledgeBase
+	existing *model.KnowledgeBase
+	action   string
+}
+
+// ExportAgent 导出智能体，返回导出包（zip）内容和文件名
+// 导出包包含智能体定义、System Prompt 模板、引用的知识库文档、插件元数据和 LLM 配置引用，不包含任何密钥
+func (s *AgentService) ExportAgent(ctx context.Context, id int64) ([]byte, string, error) {
+	manifest, files, err := s.buildAgentBundle(ctx, id)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	write := func(name string, content []byte) error {
+		w, err := zw.Create(name)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(content)
+		return err
+	}
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, "", fmt.Errorf("序列化导出包清单失败: %w", err)
+	}
+	if err := write(dto.AgentBundleManifestFile, manifestData); err != nil {
+		return nil, "", fmt.Errorf("写入导出包失败: %w", err)
+	}
+	for _, file := range files {
+		if err := write(file.Path, []byte(file.Content)); err != nil {
+			return nil, "", fmt.Errorf("写入导出包失败: %w", err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, "", fmt.Errorf("写入导出包失败: %w", err)
+	}
+
+	fileName := fmt.Sprintf("%s.agent.zip", bundleName(manifest.Agent.Name, fmt.Sprintf("agent-%d", id)))
+	return buf.Bytes(), fileName, nil
+}
+
+// buildAgentBundle 构建智能体的导出包清单和文件
+func (s *AgentService) buildAgentBundle(ctx context.Context, id int64) (*dto.AgentBundleManifest, []agentBundleFile, error) {
+	user := contextx.GetRequestUser(ctx)
+	agent, err := s.GetAgent(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if agent.Visibility == 1 && !utils.IsAdmin(agent.Admin, user) {
+		return nil, nil, fmt.Errorf("无权限：私有智能体只有管理员可以导出")
+	}
+
+	steps, err := parseAgentPipeline(agent)
+	if err != nil {
+		return nil, nil, err
+	}
+	routing, err := parseAgentLLMRouting(agent)
+	if err != nil {
+		return nil, nil, err
+	}
+	memory, err := parseAgentMemory(agent)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	manifest := &dto.AgentBundleManifest{
+		Format:     dto.AgentBundleFormat,
+		Version:    dto.AgentBundleVersion,
+		ExportedAt: time.Now().Format("2006-01-02 15:04:05"),
+		ExportedBy: user,
+		Agent: dto.AgentBundleAgent{
+			ID:              agent.ID,
+			Name:            agent.Name,
+			AgentType:       agent.AgentType,
+			ChatType:        agent.ChatType,
+			Description:     agent.Description,
+			Author:          agent.Author,
+			Timeout:         agent.Timeout,
+			KnowledgeBaseID: agent.KnowledgeBaseID,
+			LLMConfigID:     agent.LLMConfigID,
+			Pipeline:        steps,
+			LLMRouting:      routing,
+			Memory:          memory,
+			Logo:            agent.Logo,
+			Greeting:        agent.Greeting,
+			GreetingType:    agent.GreetingType,
+			Visibility:      agent.Visibility,
+		},
+		KnowledgeBases: []dto.AgentBundleKnowledgeBase{},
+		Plugins:        []dto.AgentBundlePlugin{},
+		LLMConfigs:     []dto.AgentBundleLLMConfig{},
+	}
+	if agent.PluginID != nil {
+		manifest.Agent.PluginID = *agent.PluginID
+	}
+	if agent.Metadata != nil {
+		manifest.Agent.Metadata = *agent.Metadata
+	}
+
+	var files []agentBundleFile
+	if agent.SystemPromptTemplate != "" {
+		manifest.Agent.PromptFile = dto.AgentBundlePromptFile
+		files = append(files, agentBundleFile{Path: dto.AgentBundlePromptFile, Content: agent.SystemPromptTemplate})
+	}
+
+	// 知识库：智能体的知识库和流水线知识库步骤引用的知识库
+	kbIDs := appendBundleID(nil, agent.KnowledgeBaseID)
+	pluginIDs := []int64{}
+	if agent.PluginID != nil {
+		pluginIDs = appendBundleID(pluginIDs, *agent.PluginID)
+	}
+	llmIDs := appendBundleID(nil, agent.LLMConfigID)
+	for _, step := range steps {
+		kbIDs = appendBundleID(kbIDs, step.KnowledgeBaseID)
+		pluginIDs = appendBundleID(pluginIDs, step.PluginID)
+		llmIDs = appendBundleID(llmIDs, step.LLMConfigID)
+	}
+	if routing != nil {
+		for _, llmID := range routing.FallbackLLMConfigIDs {
+			llmIDs = appendBundleID(llmIDs, llmID)
+		}
+	}
+	if memory != nil {
+		llmIDs = appendBundleID(llmIDs, memory.SummaryLLMConfigID)
+	}
+
+	kbDirs := make(map[string]bool)
+	for _, kbID := range kbIDs {
+		kb, err := s.knowledgeRepo.GetByID(kbID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("获取知识库失败: KBID=%d, %w", kbID, err)
+		}
+		docs, err := s.knowledgeRepo.GetDocumentsTreeByKBID(kbID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("获取知识库文档失败: KBID=%d, %w", kbID, err)
+		}
+		dir := path.Join(dto.AgentBundleKnowledgeDir, uniqueBundleName(kbDirs, kb.Name, fmt.Sprintf("kb-%d", kb.ID)))
+		bundleKB, kbFiles := buildBundleKnowledgeBase(kb, docs, dir)
+		manifest.KnowledgeBases = append(manifest.KnowledgeBases, bundleKB)
+		files = append(files, kbFiles...)
+	}
+
+	for _, pluginID := range pluginIDs {
+		plugin, err := s.pluginRepo.GetByID(pluginID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("获取插件失败: PluginID=%d, %w", pluginID, err)
+		}
+		manifest.Plugins = append(manifest.Plugins, dto.AgentBundlePlugin{
+			ID:          plugin.ID,
+			Code:        plugin.Code,
+			Name:        plugin.Name,
+			Description: plugin.Description,
+			Author:      plugin.Author,
+			Config:      stripPluginSecrets(plugin.Config),
+		})
+	}
+
+	for _, llmID := range llmIDs {
+		cfg, err := s.llmRepo.GetByID(llmID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("获取LLM配置失败: LLMConfigID=%d, %w", llmID, err)
+		}
+		manifest.LLMConfigs = append(manifest.LLMConfigs, dto.AgentBundleLLMConfig{
+			ID:       cfg.ID,
+			Name:     cfg.Name,
+			Provider: cfg.Provider,
+			Model:    cfg.Model,
+		})
+	}
+	return manifest, files, nil
+}
+
+// buildBundleKnowledgeBase 把知识库的文档树转换为导出包中的目录结构
+// 每个文档对应一个 markdown 文件，子文档放在与父文档同名的目录下
+func buildBundleKnowledgeBase(kb *model.KnowledgeBase, docs []*model.KnowledgeDocument, dir string) (dto.AgentBundleKnowledgeBase, []agentBundleFile) {
+	bundleKB := dto.AgentBundleKnowledgeBase{
+		ID:          kb.ID,
+		Name:        kb.Name,
+		Description: kb.Description,
+		Dir:         dir,
+		Documents:   []dto.AgentBundleDocument{},
+	}
+
+	exists := make(map[int64]bool, len(docs))
+	for _, doc := range docs {
+		exists[doc.ID] = true
+	}
+	// 文档已按 parent_id、sort_order、id 排序，父文档不存在的文档放到根目录
+	children := make(map[int64][]*model.KnowledgeDocument)
+	for _, doc := range docs {
+		parentID := doc.ParentID
+		if !exists[parentID] || parentID == doc.ID {
+			parentID = 0
+		}
+		children[parentID] = append(children[parentID], doc)
+	}
+
+	var files []agentBundleFile
+	var walk func(parentID int64, dir string)
+	walk = func(parentID int64, dir string) {
+		used := make(map[string]bool)
+		for _, doc := range children[parentID] {
+			name := uniqueBundleName(used, doc.Title, fmt.Sprintf("doc-%d", doc.ID))
+			file := path.Join(dir, name+".md")
+			bundleKB.Documents = append(bundleKB.Documents, dto.AgentBundleDocument{
+				ID:        doc.ID,
+				ParentID:  parentID,
+				Title:     doc.Title,
+				File:      file,
+				FileType:  doc.FileType,
+				SortOrder: doc.SortOrder,
+			})
+			files = append(files, agentBundleFile{Path: file, Content: doc.Content})
+			walk(doc.ID, path.Join(dir, name))
+		}
+	}
+	walk(0, dir)
+	return bundleKB, files
+}
+
+// ImportAgent 导入智能体导出包
+// 插件按 Code、知识库按名称检测冲突，按 mode 复用、合并或覆盖；LLM 配置按提供商和模型匹配已有配置（不会新建）。
+// 智能体总是新建，导入后为禁用状态，确认插件上线后再手动启用。dry_run 只返回冲突和映射关系，建议导入前先检查
+func (s *AgentService) ImportAgent(ctx context.Context, data []byte, req dto.AgentImportReq) (*dto.AgentImportResp, error) {
+	user := contextx.GetRequestUser(ctx)
+	mode := req.Mode
+	if mode == "" {
+		mode = dto.AgentImportModeMerge
+	}
+	if mode != dto.AgentImportModeMerge && mode != dto.AgentImportModeOverwrite {
+		return nil, fmt.Errorf("导入方式不支持: %s", mode)
+	}
+
+	manifest, files, err := readAgentBundle(data)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &dto.AgentImportResp{
+		Mode:             mode,
+		DryRun:           req.DryRun,
+		Conflicts:        []dto.AgentImportConflict{},
+		PluginIDs:        make(map[int64]int64),
+		KnowledgeBaseIDs: make(map[int64]int64),
+		LLMConfigIDs:     make(map[int64]int64),
+		Warnings:         []string{},
+	}
+
+	// 1. 检查冲突和映射关系（覆盖已有资源需要是其管理员）
+	plugins, err := s.planImportPlugins(manifest.Plugins, mode, user, resp)
+	if err != nil {
+		return nil, err
+	}
+	kbs, err := s.planImportKnowledgeBases(manifest.KnowledgeBases, mode, user, resp)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.mapImportLLMConfigs(manifest.LLMConfigs, resp); err != nil {
+		return nil, err
+	}
+	if req.DryRun {
+		return resp, nil
+	}
+
+	// 2. 写入插件和知识库
+	for _, item := range plugins {
+		pluginID, err := s.importPlugin(item, user)
+		if err != nil {
+			return nil, err
+		}
+		resp.PluginIDs[item.bundle.ID] = pluginID
+	}
+	for _, item := range kbs {
+		kbID, count, err := s.importKnowledgeBase(item, files, user)
+		if err != nil {
+			return nil, err
+		}
+		resp.KnowledgeBaseIDs[item.bundle.ID] = kbID
+		resp.DocumentCount += count
+	}
+
+	// 3. 创建智能体（重新映射引用的ID）
+	agent, err := buildImportAgent(manifest.Agent, files, resp)
+	if err != nil {
+		return nil, err
+	}
+	if req.Name != "" {
+		agent.Name = req.Name
+	}
+	if err := s.CreateAgent(ctx, agent); err != nil {
+		return nil, fmt.Errorf("创建智能体失败: %w", err)
+	}
+	if err := s.repo.Disable(agent.ID); err != nil {
+		return nil, fmt.Errorf("禁用导入的智能体失败: %w", err)
+	}
+	resp.AgentID = agent.ID
+	resp.Warnings = append(resp.Warnings, "智能体导入后为禁用状态，请确认插件已上线后手动启用")
+
+	logger.Infof(ctx, "[AgentService] 导入智能体完成 - AgentID: %d, Mode: %s, Plugins: %v, KnowledgeBases: %v, LLMConfigs: %v, Documents: %d",
+		agent.ID, mode, resp.PluginIDs, resp.KnowledgeBaseIDs, resp.LLMConfigIDs, resp.DocumentCount)
+	return resp, nil
+}
+
+// planImportPlugins 按插件 Code 检测冲突
+func (s *AgentService) planImportPlugins(bundlePlugins []dto.AgentBundlePlugin, mode, user string, resp *dto.AgentImportResp) ([]agentImportPlugin, error) {
+	items := make([]agentImportPlugin, 0, len(bundlePlugins))
+	for _, bundlePlugin := range bundlePlugins {
+		if bundlePlugin.Code == "" {
+			return nil, fmt.Errorf("导出包中的插件缺少 Code: %s", bundlePlugin.Name)
+		}
+		item := agentImportPlugin{bundle: bundlePlugin}
+		existing, err := s.pluginRepo.GetByCode(bundlePlugin.Code)
+		if err != nil && err != gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("查询插件失败: %w", err)
+		}
+		if err == gorm.ErrRecordNotFound {
+			resp.PluginIDs[bundlePlugin.ID] = 0
+			items = append(items, item)
+			continue
+		}
+
+		item.existing = existing
+		item.action = dto.AgentImportActionReuse
+		if mode == dto.AgentImportModeOverwrite {
+			if !utils.IsAdmin(existing.Admin, user) {
+				return nil, fmt.Errorf("无权限：插件 %s 已存在，只有其管理员可以覆盖", bundlePlugin.Code)
+			}
+			item.action = dto.AgentImportActionOverwrite
+		}
+		resp.Conflicts = append(resp.Conflicts, dto.AgentImportConflict{
+			Type:       dto.AgentImportConflictPlugin,
+			Key:        bundlePlugin.Code,
+			ExistingID: existing.ID,
+			Action:     item.action,
+		})
+		resp.PluginIDs[bundlePlugin.ID] = existing.ID
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// planImportKnowledgeBases 按知识库名称检测冲突
+func (s *AgentService) planImportKnowledgeBases(bundleKBs []dto.AgentBundleKnowledgeBase, mode, user string, resp *dto.AgentImportResp) ([]agentImportKnowledgeBase, error) {
+	items := make([]agentImportKnowledgeBase, 0, len(bundleKBs))
+	for _, bundleKB := range bundleKBs {
+		if bundleKB.Name == "" {
+			return nil, fmt.Errorf("导出包中的知识库缺少名称: KBID=%d", bundleKB.ID)
+		}
+		item := agentImportKnowledgeBase{bundle: bundleKB}
+		existing, err := s.knowledgeRepo.GetByName(bundleKB.Name)
+		if err != nil && err != gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("查询知识库失败: %w", err)
+		}
+		if err == gorm.ErrRecordNotFound {
+			resp.KnowledgeBaseIDs[bundleKB.ID] = 0
+			items = append(items, item)
+			continue
+		}
+
+		item.existing = existing
+		conflict := dto.AgentImportConflict{
+			Type:       dto.AgentImportConflictKnowledgeBase,
+			Key:        bundleKB.Name,
+			ExistingID: existing.ID,
+		}
+		isAdmin := utils.IsAdmin(existing.Admin, user)
+		switch {
+		case mode == dto.AgentImportModeOverwrite && !isAdmin:
+			return nil, fmt.Errorf("无权限：知识库 %s 已存在，只有其管理员可以覆盖", bundleKB.Name)
+		case mode == dto.AgentImportModeOverwrite:
+			item.action = dto.AgentImportActionOverwrite
+		case isAdmin:
+			item.action = dto.AgentImportActionMerge
+		default:
+			// 不是管理员时不修改已有知识库，直接复用
+			item.action = dto.AgentImportActionReuse
+			conflict.Message = "当前用户不是该知识库的管理员，直接复用，不补充文档"
+		}
+		conflict.Action = item.action
+		resp.Conflicts = append(resp.Conflicts, conflict)
+		resp.KnowledgeBaseIDs[bundleKB.ID] = existing.ID
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// mapImportLLMConfigs 按提供商和模型匹配已有的 LLM 配置（同名优先），匹配不到时使用默认 LLM
+func (s *AgentService) mapImportLLMConfigs(bundleConfigs []dto.AgentBundleLLMConfig, resp *dto.AgentImportResp) error {
+	for _, bundleConfig := range bundleConfigs {
+		candidates, err := s.llmRepo.ListByModel(bundleConfig.Provider, bundleConfig.Model)
+		if err != nil {
+			return fmt.Errorf("查询LLM配置失败: %w", err)
+		}
+		if len(candidates) == 0 {
+			resp.LLMConfigIDs[bundleConfig.ID] = 0
+			resp.Warnings = append(resp.Warnings, fmt.Sprintf("未找到 LLM 配置 %s（%s/%s），将使用默认 LLM", bundleConfig.Name, bundleConfig.Provider, bundleConfig.Model))
+			continue
+		}
+		matched := candidates[0]
+		for _, candidate := range candidates {
+			if candidate.Name == bundleConfig.Name {
+				matched = candidate
+				break
+			}
+		}
+		resp.LLMConfigIDs[bundleConfig.ID] = matched.ID
+	}
+	return nil
+}
+
+// importPlugin 写入插件，返回导入后的插件ID
+func (s *AgentService) importPlugin(item agentImportPlugin, user string) (int64, error) {
+	config, err := normalizeMetadata(item.bundle.Config)
+	if err != nil {
+		return 0, fmt.Errorf("插件 %s 的配置无效: %w", item.bundle.Code, err)
+	}
+
+	switch item.action {
+	case dto.AgentImportActionReuse:
+		return item.existing.ID, nil
+	case dto.AgentImportActionOverwrite:
+		plugin := item.existing
+		if item.bundle.Name != "" {
+			plugin.Name = item.bundle.Name
+		}
+		plugin.Description = item.bundle.Description
+		plugin.Author = item.bundle.Author
+		plugin.Config = restorePluginSecrets(plugin.Config, config)
+		plugin.UpdatedBy = user
+		if err := s.pluginRepo.Update(plugin); err != nil {
+			return 0, fmt.Errorf("覆盖插件 %s 失败: %w", plugin.Code, err)
+		}
+		return plugin.ID, nil
+	}
+
+	name := item.bundle.Name
+	if name == "" {
+		name = item.bundle.Code
+	}
+	plugin := &model.Plugin{
+		Name:        name,
+		Code:        item.bundle.Code,
+		Description: item.bundle.Description,
+		Author:      item.bundle.Author,
+		Config:      config,
+		User:        user,
+		Admin:       user,
+	}
+	plugin.CreatedBy = user
+	plugin.UpdatedBy = user
+	if err := s.pluginRepo.Create(plugin); err != nil {
+		return 0, fmt.Errorf("创建插件 %s 失败: %w", plugin.Code, err)
+	}
+	return plugin.ID, nil
+}
+
+// importKnowledgeBase 写入知识库和文档，返回导入后的知识库ID和写入的文档数
+// 合并时按文档路径（/父文档标题/文档标题）跳过已有文档
+func (s *AgentService) importKnowledgeBase(item agentImportKnowledgeBase, files map[string]string, user string) (int64, int, error) {
+	kb := item.existing
+	existingPaths := make(map[string]int64)
+	switch item.action {
+	case dto.AgentImportActionReuse:
+		return kb.ID, 0, nil
+	case dto.AgentImportActionOverwrite:
+		kb.Description = item.bundle.Description
+		kb.UpdatedBy = user
+		if err := s.knowledgeRepo.Update(kb); err != nil {
+			return 0, 0, fmt.Errorf("覆盖知识库 %s 失败: %w", kb.Name, err)
+		}
+		if err := s.knowledgeRepo.DeleteDocumentsByKBID(kb.ID); err != nil {
+			return 0, 0, fmt.Errorf("清空知识库 %s 的文档失败: %w", kb.Name, err)
+		}
+	case dto.AgentImportActionMerge:
+		docs, err := s.knowledgeRepo.GetDocumentsTreeByKBID(kb.ID)
+		if err != nil {
+			return 0, 0, fmt.Errorf("获取知识库 %s 的文档失败: %w", kb.Name, err)
+		}
+		for id, docPath := range knowledgeDocumentPaths(docs) {
+			existingPaths[docPath] = id
+		}
+	default:
+		kb = &model.KnowledgeBase{
+			Name:        item.bundle.Name,
+			Description: item.bundle.Description,
+			Status:      "active",
+			User:        user,
+			Admin:       user,
+		}
+		kb.CreatedBy = user
+		kb.UpdatedBy = user
+		if err := s.knowledgeRepo.Create(kb); err != nil {
+			return 0, 0, fmt.Errorf("创建知识库 %s 失败: %w", kb.Name, err)
+		}
+	}
+
+	// 清单中父文档总是排在子文档之前
+	docIDs := make(map[int64]int64)
+	docPaths := make(map[int64]string)
+	count := 0
+	for _, bundleDoc := range item.bundle.Documents {
+		parentID := docIDs[bundleDoc.ParentID]
+		docPath := docPaths[bundleDoc.ParentID] + "/" + bundleDoc.Title
+		docPaths[bundleDoc.ID] = docPath
+		if id, ok := existingPaths[docPath]; ok {
+			docIDs[bundleDoc.ID] = id
+			continue
+		}
+
+		content, ok := files[bundleDoc.File]
+		if !ok {
+			return 0, 0, fmt.Errorf("导出包缺少文档文件: %s", bundleDoc.File)
+		}
+		doc := &model.KnowledgeDocument{
+			KnowledgeBaseID: kb.ID,
+			ParentID:        parentID,
+			DocID:           uuid.New().String(),
+			Title:           bundleDoc.Title,
+			Content:         content,
+			FileType:        bundleDoc.FileType,
+			FileSize:        int64(len(content)),
+			Status:          "completed",
+			SortOrder:       bundleDoc.SortOrder,
+			Path:            docPath,
+			User:            user,
+		}
+		doc.CreatedBy = user
+		doc.UpdatedBy = user
+		if err := s.knowledgeRepo.AddDocument(doc); err != nil {
+			return 0, 0, fmt.Errorf("写入文档 %s 失败: %w", docPath, err)
+		}
+		docIDs[bundleDoc.ID] = doc.ID
+		count++
+	}
+	return kb.ID, count, nil
+}
+
+// buildImportAgent 根据导出包中的智能体定义构建新智能体，引用的ID替换为导入后的ID
+func buildImportAgent(bundleAgent dto.AgentBundleAgent, files map[string]string, resp *dto.AgentImportResp) (*model.Agent, error) {
+	agent := &model.Agent{
+		Name:            bundleAgent.Name,
+		AgentType:       bundleAgent.AgentType,
+		ChatType:        bundleAgent.ChatType,
+		Description:     bundleAgent.Description,
+		Author:          bundleAgent.Author,
+		Timeout:         bundleAgent.Timeout,
+		KnowledgeBaseID: resp.KnowledgeBaseIDs[bundleAgent.KnowledgeBaseID],
+		LLMConfigID:     resp.LLMConfigIDs[bundleAgent.LLMConfigID],
+		Logo:            bundleAgent.Logo,
+		Greeting:        bundleAgent.Greeting,
+		GreetingType:    bundleAgent.GreetingType,
+		Visibility:      bundleAgent.Visibility,
+	}
+	if bundleAgent.PluginID > 0 {
+		pluginID := resp.PluginIDs[bundleAgent.PluginID]
+		agent.PluginID = &pluginID
+	}
+	if bundleAgent.PromptFile != "" {
+		prompt, ok := files[bundleAgent.PromptFile]
+		if !ok {
+			return nil, fmt.Errorf("导出包缺少 System Prompt 模板文件: %s", bundleAgent.PromptFile)
+		}
+		agent.SystemPromptTemplate = prompt
+	}
+	if bundleAgent.Metadata != "" {
+		metadata := bundleAgent.Metadata
+		agent.Metadata = &metadata
+	}
+
+	if len(bundleAgent.Pipeline) > 0 {
+		steps := make([]dto.AgentPipelineStep, 0, len(bundleAgent.Pipeline))
+		for _, step := range bundleAgent.Pipeline {
+			step.PluginID = resp.PluginIDs[step.PluginID]
+			step.KnowledgeBaseID = resp.KnowledgeBaseIDs[step.KnowledgeBaseID]
+			step.LLMConfigID = resp.LLMConfigIDs[step.LLMConfigID]
+			steps = append(steps, step)
+		}
+		pipeline, err := marshalBundleJSON(steps)
+		if err != nil {
+			return nil, err
+		}
+		agent.Pipeline = pipeline
+	}
+	if bundleAgent.LLMRouting != nil {
+		routing := *bundleAgent.LLMRouting
+		fallbacks := make([]int64, 0, len(routing.FallbackLLMConfigIDs))
+		for _, llmID := range routing.FallbackLLMConfigIDs {
+			fallbacks = appendBundleID(fallbacks, resp.LLMConfigIDs[llmID])
+		}
+		routing.FallbackLLMConfigIDs = fallbacks
+		llmRouting, err := marshalBundleJSON(routing)
+		if err != nil {
+			return nil, err
+		}
+		agent.LLMRouting = llmRouting
+	}
+	if bundleAgent.Memory != nil {
+		memory := *bundleAgent.Memory
+		memory.SummaryLLMConfigID = resp.LLMConfigIDs[memory.SummaryLLMConfigID]
+		agentMemory, err := marshalBundleJSON(memory)
+		if err != nil {
+			return nil, err
+		}
+		agent.Memory = agentMemory
+	}
+	return agent, nil
+}
+
+// PublishAgentToHub 发布智能体到 Hub（与目录发布一致：首次发布 v1，之后每次发布新版本）
+func (s *AgentService) PublishAgentToHub(ctx context.Context, req *dto.AgentPublishToHubReq) (*dto.AgentPublishToHubResp, error) {
+	user := contextx.GetRequestUser(ctx)
+	agent, err := s.GetAgent(ctx, req.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !utils.IsAdmin(agent.Admin, user) {
+		return nil, fmt.Errorf("无权限：只有管理员可以发布此智能体")
+	}
+
+	manifest, files, err := s.buildAgentBundle(ctx, req.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	versionNum := agent.HubVersionNum + 1
+	version := fmt.Sprintf("v%d", versionNum)
+	hubFiles := make([]*dto.FileSnapshotInfo, 0, len(files))
+	for _, file := range files {
+		hubFiles = append(hubFiles, &dto.FileSnapshotInfo{
+			FileName:     path.Base(file.Path),
+			RelativePath: file.Path,
+			Content:      file.Content,
+			FileType:     strings.TrimPrefix(path.Ext(file.Path), "."),
+			FileVersion:  version,
+		})
+	}
+
+	hubReq := &dto.PublishHubAgentReq{
+		HubAgentID:    agent.HubAgentID,
+		SourceAgentID: agent.ID,
+		Name:          req.Name,
+		Description:   req.Description,
+		Category:      req.Category,
+		Tags:          req.Tags,
+		Version:       version,
+		Manifest:      manifest,
+		Files:         hubFiles,
+	}
+	if hubReq.Name == "" {
+		hubReq.Name = agent.Name
+	}
+	if hubReq.Description == "" {
+		hubReq.Description = agent.Description
+	}
+
+	header := &apicall.Header{
+		TraceID:     contextx.GetTraceId(ctx),
+		RequestUser: user,
+		Token:       contextx.GetToken(ctx),
+	}
+	var hubResp *dto.PublishHubAgentResp
+	if agent.HubAgentID > 0 {
+		hubResp, err = apicall.UpdateAgentToHub(header, hubReq)
+	} else {
+		hubResp, err = apicall.PublishAgentToHub(header, hubReq)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("调用 Hub API 失败: %w", err)
+	}
+
+	// 以 Hub 返回的版本为准
+	if hubResp.Version != "" && hubResp.Version != version {
+		var num int
+		if _, err := fmt.Sscanf(hubResp.Version, "v%d", &num); err == nil {
+			version, versionNum = hubResp.Version, num
+		}
+	}
+	if err := s.repo.UpdateHubInfo(agent.ID, hubResp.HubAgentID, version, versionNum); err != nil {
+		// 发布已经成功，只是绑定失败
+		logger.Warnf(ctx, "[PublishAgentToHub] 更新智能体的Hub信息失败: agentID=%d, hubAgentID=%d, hubVersion=%s, error=%v",
+			agent.ID, hubResp.HubAgentID, version, err)
+	}
+
+	return &dto.AgentPublishToHubResp{
+		HubAgentID:  hubResp.HubAgentID,
+		HubAgentURL: hubResp.HubAgentURL,
+		Version:     version,
+		OldVersion:  agent.HubVersion,
+		FileCount:   hubResp.FileCount,
+	}, nil
+}
+
+// readAgentBundle 读取导出包，返回清单和其余文件内容（路径 -> 内容）
+func readAgentBundle(data []byte) (*dto.AgentBundleManifest, map[string]string, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, nil, fmt.Errorf("导出包不是有效的 zip 文件: %w", err)
+	}
+	if len(zr.File) > agentBundleMaxFiles {
+		return nil, nil, fmt.Errorf("导出包中的文件过多: %d", len(zr.File))
+	}
+
+	files := make(map[string]string, len(zr.File))
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		if f.UncompressedSize64 > agentBundleMaxFileSize {
+			return nil, nil, fmt.Errorf("导出包中的文件过大: %s", f.Name)
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, nil, fmt.Errorf("读取导出包文件 %s 失败: %w", f.Name, err)
+		}
+		content, err := io.ReadAll(io.LimitReader(rc, agentBundleMaxFileSize+1))
+		rc.Close()
+		if err != nil {
+			return nil, nil, fmt.Errorf("读取导出包文件 %s 失败: %w", f.Name, err)
+		}
+		if len(content) > agentBundleMaxFileSize {
+			return nil, nil, fmt.Errorf("导出包中的文件过大: %s", f.Name)
+		}
+		files[f.Name] = string(content)
+	}
+
+	manifestData, ok := files[dto.AgentBundleManifestFile]
+	if !ok {
+		return nil, nil, fmt.Errorf("导出包缺少 %s", dto.AgentBundleManifestFile)
+	}
+	delete(files, dto.AgentBundleManifestFile)
+
+	var manifest dto.AgentBundleManifest
+	if err := json.Unmarshal([]byte(manifestData), &manifest); err != nil {
+		return nil, nil, fmt.Errorf("解析导出包清单失败: %w", err)
+	}
+	if manifest.Format != dto.AgentBundleFormat {
+		return nil, nil, fmt.Errorf("不是智能体导出包: format=%s", manifest.Format)
+	}
+	if manifest.Version <= 0 || manifest.Version > dto.AgentBundleVersion {
+		return nil, nil, fmt.Errorf("导出包版本 %d 不受支持，当前支持的最高版本为 %d", manifest.Version, dto.AgentBundleVersion)
+	}
+	return &manifest, files, nil
+}
+
+// knowledgeDocumentPaths 计算文档在文档树中的路径（/父文档标题/文档标题）
+func knowledgeDocumentPaths(docs []*model.KnowledgeDocument) map[int64]string {
+	byID := make(map[int64]*model.KnowledgeDocument, len(docs))
+	for _, doc := range docs {
+		byID[doc.ID] = doc
+	}
+	paths := make(map[int64]string, len(docs))
+	for _, doc := range docs {
+		docPath := ""
+		seen := make(map[int64]bool)
+		for current := doc; current != nil && !seen[current.ID]; current = byID[current.ParentID] {
+			seen[current.ID] = true
+			docPath = "/" + current.Title + docPath
+		}
+		paths[doc.ID] = docPath
+	}
+	return paths
+}
+
+// stripPluginSecrets 移除插件配置中的密钥字段，配置无效时返回空
+func stripPluginSecrets(config *string) string {
+	if config == nil || *config == "" || *config == "null" {
+		return ""
+	}
+	var value interface{}
+	if err := json.Unmarshal([]byte(*config), &value); err != nil {
+		return ""
+	}
+	data, err := json.Marshal(removeSecretFields(value))
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// restorePluginSecrets 把已有配置中的密钥字段合并到导入的配置中（导入的配置不包含密钥）
+func restorePluginSecrets(existing, imported *string) *string {
+	if existing == nil || *existing == "" {
+		return imported
+	}
+	var existingValue interface{}
+	if err := json.Unmarshal([]byte(*existing), &existingValue); err != nil {
+		return imported
+	}
+	var importedValue interface{} = map[string]interface{}{}
+	if imported != nil {
+		if err := json.Unmarshal([]byte(*imported), &importedValue); err != nil {
+			return imported
+		}
+	}
+	merged, err := marshalBundleJSON(copySecretFields(importedValue, existingValue))
+	if err != nil {
+		return imported
+	}
+	return merged
+}
+
+// removeSecretFields 递归移除 JSON 对象中的密钥字段
+func removeSecretFields(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			if isSecretKey(key) {
+				delete(v, key)
+				continue
+			}
+			v[key] = removeSecretFields(item)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = removeSecretFields(item)
+		}
+	}
+	return value
+}
+
+// copySecretFields 递归把 src 中的密钥字段复制到 dst
+func copySecretFields(dst, src interface{}) interface{} {
+	dstMap, ok := dst.(map[string]interface{})
+	if !ok {
+		return dst
+	}
+	srcMap, ok := src.(map[string]interface{})
+	if !ok {
+		return dst
+	}
+	for key, item := range srcMap {
+		if isSecretKey(key) {
+			dstMap[key] = item
+		} else if existing, ok := dstMap[key]; ok {
+			dstMap[key] = copySecretFields(existing, item)
+		}
+	}
+	return dstMap
+}
+
+// isSecretKey 判断配置字段是否为密钥
+func isSecretKey(key string) bool {
+	key = strings.ReplaceAll(strings.ToLower(key), "-", "_")
+	if key == "key" {
+		return true
+	}
+	for _, secret := range agentBundleSecretKeys {
+		if strings.Contains(key, secret) {
+			return true
+		}
+	}
+	return false
+}
+
+// appendBundleID 追加引用的ID（忽略 0 和重复的ID）
+func appendBundleID(ids []int64, id int64) []int64 {
+	if id <= 0 {
+		return ids
+	}
+	for _, existing := range ids {
+		if existing == id {
+			return ids
+		}
+	}
+	return append(ids, id)
+}
+
+// uniqueBundleName 生成同一目录下不重复的文件名
+func uniqueBundleName(used map[string]bool, name, fallback string) string {
+	base := bundleName(name, fallback)
+	candidate := base
+	for i := 2; used[strings.ToLower(candidate)]; i++ {
+		candidate = fmt.Sprintf("%s-%d", base, i)
+	}
+	used[strings.ToLower(candidate)] = true
+	return candidate
+}
+
+// bundleName 把标题转换为可用的文件名（去掉路径分隔符等特殊字符）
+func bundleName(name, fallback string) string {
+	name = strings.Map(func(r rune) rune {
+		if r < 0x20 || strings.ContainsRune(`/\:*?"<>|`, r) {
+			return '_'
+		}
+		return r
+	}, name)
+	name = strings.Trim(name, " .")
+	if utf8.RuneCountInString(name) > agentBundleMaxNameLen {
+		name = string([]rune(name)[:agentBundleMaxNameLen])
+	}
+	if name == "" {
+		return fallback
+	}
+	return name
+}
+
+// marshalBundleJSON 序列化 JSON 字段
+func marshalBundleJSON(value interface{}) (*string, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("序列化失败: %w", err)
+	}
+	result := string(data)
+	return &result, nil
+}
@@ -0,0 +1,374 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/ai-agent-os/ai-agent-os/core/agent-server/model"
+	"github.com/ai-agent-os/ai-agent-os/dto"
+	"github.com/ai-agent-os/ai-agent-os/pkg/contextx"
+	"github.com/ai-agent-os/ai-agent-os/pkg/logger"
+	"gorm.io/gorm"
+)
+
+// ChatMessageBranches 会话当前分支的消息（以及每条消息的其他版本）
+type ChatMessageBranches struct {
+	Session          *model.AgentChatSession
+	Messages         []*model.AgentChatMessage // 当前分支，从第一条消息到分支的最后一条消息
+	Siblings         map[int64][]int64         // 消息ID -> 同一位置的所有版本（只包含有多个版本的消息）
+	AppliedMessageID int64                     // 最近一次应用到工作空间的 assistant 消息ID
+}
+
+// ListMessages 获取消息列表（当前分支）
+func (s *AgentChatService) ListMessages(ctx context.Context, sessionID string) (*ChatMessageBranches, error) {
+	if s.messageRepo == nil {
+		return nil, fmt.Errorf("消息Repository未初始化")
+	}
+
+	session, err := s.sessionRepo.GetBySessionID(sessionID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("会话不存在")
+		}
+		return nil, fmt.Errorf("获取会话失败: %w", err)
+	}
+	messages, err := s.messageRepo.ListBySessionID(sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("获取消息列表失败: %w", err)
+	}
+
+	branches := &ChatMessageBranches{
+		Session:  session,
+		Messages: messages,
+		Siblings: make(map[int64][]int64),
+	}
+	// 旧会话没有分支，按时间顺序返回全部消息
+	if session.CurrentMessageID == 0 {
+		return branches, nil
+	}
+	branches.Messages = messagePath(messages, session.CurrentMessageID)
+
+	children := make(map[int64][]int64)
+	for _, msg := range messages {
+		children[msg.ParentID] = append(children[msg.ParentID], msg.ID)
+		if session.AppliedRecordID > 0 && msg.RecordID == session.AppliedRecordID {
+			branches.AppliedMessageID = msg.ID
+		}
+	}
+	for _, msg := range branches.Messages {
+		if ids := children[msg.ParentID]; len(ids) > 1 {
+			branches.Siblings[msg.ID] = ids
+		}
+	}
+	return branches, nil
+}
+
+// RegenerateFunctionGen 重新生成回答：基于同一条用户消息再生成一次，新的回答与原回答并列
+func (s *AgentChatService) RegenerateFunctionGen(ctx context.Context, req *dto.FunctionGenRegenerateReq) (*dto.FunctionGenAgentChatResp, error) {
+	user := contextx.GetRequestUser(ctx)
+	traceId := contextx.GetTraceId(ctx)
+	logger.Infof(ctx, "[FunctionGenChat] 重新生成 - SessionID: %s, MessageID: %d, User: %s, TraceID: %s", req.SessionID, req.MessageID, user, traceId)
+
+	session, messages, err := s.getOwnSessionMessages(ctx, req.SessionID, user)
+	if err != nil {
+		return nil, err
+	}
+	assistantMessage := findMessage(messages, req.MessageID)
+	if assistantMessage == nil || assistantMessage.Role != "assistant" {
+		return nil, fmt.Errorf("只能重新生成智能体的回答")
+	}
+	userMessage := findMessage(messages, assistantMessage.ParentID)
+	if userMessage == nil || userMessage.Role != "user" {
+		return nil, fmt.Errorf("回答对应的用户消息不存在")
+	}
+
+	chatReq, err := s.branchChatReq(session, userMessage, req.Package, req.ExistingFiles)
+	if err != nil {
+		return nil, err
+	}
+	agent, err := s.validateAndGetAgent(ctx, chatReq.AgentID, traceId)
+	if err != nil {
+		return nil, err
+	}
+	ctx = s.llmRouter.usage.WithScope(ctx, agent.ID, model.LLMUsageSceneFunctionGen)
+	if err := s.llmRouter.usage.CheckQuota(ctx); err != nil {
+		return nil, err
+	}
+	if _, err := s.getAndValidateSession(ctx, req.SessionID, user, traceId); err != nil {
+		return nil, err
+	}
+
+	return s.startFunctionGen(ctx, chatReq, agent, req.SessionID, userMessage, user, traceId)
+}
+
+// EditFunctionGen 编辑用户消息后重新发送：新的用户消息与原消息并列，开始一个新分支
+func (s *AgentChatService) EditFunctionGen(ctx context.Context, req *dto.FunctionGenEditReq) (*dto.FunctionGenAgentChatResp, error) {
+	user := contextx.GetRequestUser(ctx)
+	traceId := contextx.GetTraceId(ctx)
+	logger.Infof(ctx, "[FunctionGenChat] 编辑后重新发送 - SessionID: %s, MessageID: %d, User: %s, TraceID: %s", req.SessionID, req.MessageID, user, traceId)
+
+	session, messages, err := s.getOwnSessionMessages(ctx, req.SessionID, user)
+	if err != nil {
+		return nil, err
+	}
+	original := findMessage(messages, req.MessageID)
+	if original == nil || original.Role != "user" {
+		return nil, fmt.Errorf("只能编辑用户消息")
+	}
+
+	chatReq := &dto.FunctionGenAgentChatReq{
+		AgentID:       original.AgentID,
+		TreeID:        session.TreeID,
+		Package:       req.Package,
+		SessionID:     req.SessionID,
+		ExistingFiles: req.ExistingFiles,
+		Message:       req.Message,
+	}
+	agent, err := s.validateAndGetAgent(ctx, chatReq.AgentID, traceId)
+	if err != nil {
+		return nil, err
+	}
+	ctx = s.llmRouter.usage.WithScope(ctx, agent.ID, model.LLMUsageSceneFunctionGen)
+	if err := s.llmRouter.usage.CheckQuota(ctx); err != nil {
+		return nil, err
+	}
+	if _, err := s.getAndValidateSession(ctx, req.SessionID, user, traceId); err != nil {
+		return nil, err
+	}
+
+	userMessage, err := s.saveUserMessage(ctx, chatReq, req.SessionID, original.ParentID, user, traceId)
+	if err != nil {
+		s.releaseSession(ctx, req.SessionID, traceId)
+		return nil, err
+	}
+	return s.startFunctionGen(ctx, chatReq, agent, req.SessionID, userMessage, user, traceId)
+}
+
+// SwitchBranch 切换当前分支（切换到指定消息下最新的分支，不修改工作空间）
+func (s *AgentChatService) SwitchBranch(ctx context.Context, req *dto.ChatBranchSwitchReq) error {
+	user := contextx.GetRequestUser(ctx)
+	session, messages, err := s.getOwnSessionMessages(ctx, req.SessionID, user)
+	if err != nil {
+		return err
+	}
+	if session.Status == model.ChatSessionStatusGenerating {
+		return fmt.Errorf("会话正在生成中，请等待完成后再试")
+	}
+	if findMessage(messages, req.MessageID) == nil {
+		return fmt.Errorf("消息不存在")
+	}
+
+	leafID := latestLeaf(messages, req.MessageID)
+	if err := s.sessionRepo.UpdateCurrentMessage(req.SessionID, leafID); err != nil {
+		return fmt.Errorf("切换分支失败: %w", err)
+	}
+	logger.Infof(ctx, "[FunctionGenChat] 切换分支 - SessionID: %s, MessageID: %d, CurrentMessageID: %d, User: %s",
+		req.SessionID, req.MessageID, leafID, user)
+	return nil
+}
+
+// ApplyFunctionGen 把指定回答生成的代码应用到工作空间（走与生成完成时相同的发布/回调流程），并切换到该分支
+func (s *AgentChatService) ApplyFunctionGen(ctx context.Context, req *dto.FunctionGenApplyReq) (*dto.FunctionGenAgentChatResp, error) {
+	user := contextx.GetRequestUser(ctx)
+	traceId := contextx.GetTraceId(ctx)
+
+	session, messages, err := s.getOwnSessionMessages(ctx, req.SessionID, user)
+	if err != nil {
+		return nil, err
+	}
+	if session.Status == model.ChatSessionStatusGenerating {
+		return nil, fmt.Errorf("会话正在生成中，请等待完成后再试")
+	}
+	message := findMessage(messages, req.MessageID)
+	if message == nil || message.Role != "assistant" {
+		return nil, fmt.Errorf("只能应用智能体回答中的代码")
+	}
+
+	// 旧消息没有关联生成记录，按对应的用户消息查找
+	var record *model.FunctionGenRecord
+	if message.RecordID > 0 {
+		record, err = s.functionGenRepo.GetByID(message.RecordID)
+	} else {
+		record, err = s.functionGenRepo.GetByMessageID(message.ParentID)
+	}
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("回答对应的生成记录不存在")
+		}
+		return nil, fmt.Errorf("获取生成记录失败: %w", err)
+	}
+	if record.Status == model.FunctionGenStatusGenerating {
+		return nil, fmt.Errorf("代码正在应用中，请等待完成后再试")
+	}
+	if record.Code == "" {
+		return nil, fmt.Errorf("该回答没有生成代码")
+	}
+
+	leafID := latestLeaf(messages, message.ID)
+	if err := s.sessionRepo.UpdateCurrentMessage(req.SessionID, leafID); err != nil {
+		return nil, fmt.Errorf("切换分支失败: %w", err)
+	}
+	if err := s.functionGenRepo.UpdateStatus(record.ID, model.FunctionGenStatusGenerating, ""); err != nil {
+		return nil, fmt.Errorf("更新生成记录状态失败: %w", err)
+	}
+	s.functionGenService.stream.Publish(dto.FunctionGenStreamEvent{
+		Type:      dto.FunctionGenEventStatus,
+		SessionID: req.SessionID,
+		RecordID:  record.ID,
+		Status:    model.FunctionGenStatusGenerating,
+	})
+
+	logger.Infof(ctx, "[FunctionGenChat] 应用分支代码 - SessionID: %s, MessageID: %d, RecordID: %d, CodeLength: %d, User: %s, TraceID: %s",
+		req.SessionID, message.ID, record.ID, len(record.Code), user, traceId)
+	resultData := &dto.AddFunctionsReq{
+		RecordID:  record.ID,
+		MessageID: record.MessageID,
+		AgentID:   record.AgentID,
+		TreeID:    record.TreeID,
+		User:      user,
+		Code:      record.Code,
+	}
+	if err := s.functionGenService.PublishResult(ctx, resultData, traceId, user); err != nil {
+		s.failRecord(ctx, req.SessionID, record.ID, err, traceId)
+		return nil, err
+	}
+
+	return &dto.FunctionGenAgentChatResp{
+		SessionID:   req.SessionID,
+		Content:     "正在应用代码，请稍候...",
+		RecordID:    record.ID,
+		Status:      model.FunctionGenStatusGenerating,
+		CanContinue: true,
+	}, nil
+}
+
+// getOwnSessionMessages 获取当前用户自己的会话和会话的全部消息
+func (s *AgentChatService) getOwnSessionMessages(ctx context.Context, sessionID, user string) (*model.AgentChatSession, []*model.AgentChatMessage, error) {
+	session, err := s.sessionRepo.GetBySessionID(sessionID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil, fmt.Errorf("会话不存在")
+		}
+		return nil, nil, fmt.Errorf("获取会话失败: %w", err)
+	}
+	if session.User != user {
+		return nil, nil, fmt.Errorf("无权限：只能操作自己的会话")
+	}
+	if _, err := s.currentMessageID(ctx, session, contextx.GetTraceId(ctx)); err != nil {
+		return nil, nil, err
+	}
+	messages, err := s.messageRepo.ListBySessionID(sessionID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("获取消息列表失败: %w", err)
+	}
+	return session, messages, nil
+}
+
+// branchChatReq 根据分支上的用户消息构建生成请求（重新生成时使用）
+func (s *AgentChatService) branchChatReq(session *model.AgentChatSession, userMessage *model.AgentChatMessage, pkg string, existingFiles []string) (*dto.FunctionGenAgentChatReq, error) {
+	req := &dto.FunctionGenAgentChatReq{
+		AgentID:       userMessage.AgentID,
+		TreeID:        session.TreeID,
+		Package:       pkg,
+		SessionID:     session.SessionID,
+		ExistingFiles: existingFiles,
+	}
+	req.Message.Content = userMessage.Content
+	if userMessage.Files != nil && *userMessage.Files != "" {
+		if err := json.Unmarshal([]byte(*userMessage.Files), &req.Message.Files); err != nil {
+			return nil, fmt.Errorf("解析用户消息的文件列表失败: %w", err)
+		}
+	}
+	return req, nil
+}
+
+// currentMessageID 返回会话当前分支的最后一条消息ID
+// 旧会话（没有记录当前分支）按时间顺序把消息串成一条分支后再返回
+func (s *AgentChatService) currentMessageID(ctx context.Context, session *model.AgentChatSession, traceId string) (int64, error) {
+	if session.CurrentMessageID > 0 {
+		return session.CurrentMessageID, nil
+	}
+	messages, err := s.messageRepo.ListBySessionID(session.SessionID)
+	if err != nil {
+		return 0, fmt.Errorf("加载历史消息失败: %w", err)
+	}
+	if len(messages) == 0 {
+		return 0, nil
+	}
+
+	for i := 1; i < len(messages); i++ {
+		if messages[i].ParentID != 0 {
+			continue
+		}
+		if err := s.messageRepo.UpdateParentID(messages[i].ID, messages[i-1].ID); err != nil {
+			return 0, fmt.Errorf("升级会话消息失败: %w", err)
+		}
+	}
+	last := messages[len(messages)-1].ID
+	if err := s.sessionRepo.UpdateCurrentMessage(session.SessionID, last); err != nil {
+		return 0, fmt.Errorf("升级会话消息失败: %w", err)
+	}
+	session.CurrentMessageID = last
+	logger.Infof(ctx, "[FunctionGenChat] 旧会话已升级为消息树 - SessionID: %s, Messages: %d, CurrentMessageID: %d, TraceID: %s",
+		session.SessionID, len(messages), last, traceId)
+	return last, nil
+}
+
+// releaseSession 生成结束后把会话状态恢复为 active（可以继续输入）
+func (s *AgentChatService) releaseSession(ctx context.Context, sessionID, traceId string) {
+	if err := s.sessionRepo.UpdateStatus(sessionID, model.ChatSessionStatusActive); err != nil {
+		logger.Errorf(ctx, "[FunctionGenChat] 恢复会话状态失败 - SessionID: %s, TraceID: %s, Error: %v", sessionID, traceId, err)
+	}
+}
+
+// messagePath 返回从第一条消息到 leafID 的分支
+func messagePath(messages []*model.AgentChatMessage, leafID int64) []*model.AgentChatMessage {
+	byID := make(map[int64]*model.AgentChatMessage, len(messages))
+	for _, msg := range messages {
+		byID[msg.ID] = msg
+	}
+
+	path := make([]*model.AgentChatMessage, 0)
+	for id := leafID; id != 0 && len(path) < len(messages); {
+		msg, ok := byID[id]
+		if !ok {
+			break
+		}
+		path = append(path, msg)
+		id = msg.ParentID
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path
+}
+
+// latestLeaf 返回 messageID 下最新的分支的最后一条消息（每一层都选择最新的子消息）
+func latestLeaf(messages []*model.AgentChatMessage, messageID int64) int64 {
+	latestChild := make(map[int64]int64)
+	for _, msg := range messages {
+		if msg.ID > latestChild[msg.ParentID] {
+			latestChild[msg.ParentID] = msg.ID
+		}
+	}
+	leaf := messageID
+	for i := 0; i < len(messages); i++ {
+		child, ok := latestChild[leaf]
+		if !ok {
+			break
+		}
+		leaf = child
+	}
+	return leaf
+}
+
+// findMessage 按 ID 查找消息
+func findMessage(messages []*model.AgentChatMessage, id int64) *model.AgentChatMessage {
+	for _, msg := range messages {
+		if msg.ID == id {
+			return msg
+		}
+	}
+	return nil
+}
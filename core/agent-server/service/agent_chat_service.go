@@ -2,12 +2,12 @@ package service
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"time"
 
 	"github.com/ai-agent-os/ai-agent-os/core/agent-server/model"
 	"github.com/ai-agent-os/ai-agent-os/core/agent-server/repository"
+	"github.com/ai-agent-os/ai-agent-os/dto"
+	"github.com/ai-agent-os/ai-agent-os/pkg/contextx"
 	"github.com/ai-agent-os/ai-agent-os/pkg/llms"
 	"gorm.io/gorm"
 )
@@ -23,6 +23,8 @@ type AgentChatService struct {
 	sessionRepo     *repository.ChatSessionRepository
 	messageRepo     *repository.ChatMessageRepository
 	functionGenRepo *repository.FunctionGenRepository
+
+	llmRouter *LLMRouter
 }
 
 // NewAgentChatService 创建智能体聊天服务
@@ -34,6 +36,7 @@ func NewAgentChatService(
 	sessionRepo *repository.ChatSessionRepository,
 	messageRepo *repository.ChatMessageRepository,
 	functionGenRepo *repository.FunctionGenRepository,
+	llmRouter *LLMRouter,
 ) *AgentChatService {
 	return &AgentChatService{
 		agentRepo:          agentRepo,
@@ -43,6 +46,7 @@ func NewAgentChatService(
 		sessionRepo:        sessionRepo,
 		messageRepo:        messageRepo,
 		functionGenRepo:    functionGenRepo,
+		llmRouter:          llmRouter,
 	}
 }
 
@@ -86,69 +90,20 @@ func (s *AgentChatService) Chat(ctx context.Context, agentID int64, messages []l
 		}
 	}
 
-	// 4. 创建 LLM 客户端（使用自定义配置）
-	provider := llms.Provider(llmConfig.Provider)
-	options := llms.DefaultClientOptions()
-	if llmConfig.Model != "" {
-		options = options.WithModel(llmConfig.Model)
-	}
-
-	// 设置超时时间
-	if llmConfig.Timeout > 0 {
-		options = options.WithTimeout(time.Duration(llmConfig.Timeout) * time.Second)
-	}
-
-	// 设置自定义 BaseURL（如果提供了）
-	if llmConfig.APIBase != "" {
-		options = options.WithBaseURL(llmConfig.APIBase)
-	}
-
-	// 设置模型名称（如果提供了）
-	if llmConfig.Model != "" {
-		options = options.WithModel(llmConfig.Model)
-	}
-
-	// 创建客户端
-	client, err := llms.NewLLMClientWithOptions(provider, llmConfig.APIKey, options)
+	// 4. 按智能体的路由策略调用 LLM（主 LLM 不可用时切换到备用 LLM）
+	routing, err := parseAgentLLMRouting(agent)
 	if err != nil {
-		return nil, fmt.Errorf("创建LLM客户端失败: %w", err)
-	}
-
-	// 6. 解析额外配置（如果有）
-	var extraConfig map[string]interface{}
-	if llmConfig.ExtraConfig != nil && *llmConfig.ExtraConfig != "" {
-		if err := json.Unmarshal([]byte(*llmConfig.ExtraConfig), &extraConfig); err != nil {
-			// 忽略解析错误，使用默认配置
-		}
-	}
-
-	// 7. 构建聊天请求
-	chatReq := &llms.ChatRequest{
-		Messages: messages,
-		Model:    llmConfig.Model,
+		return nil, err
 	}
-
-	// 8. 应用额外配置
-	if maxTokens, ok := extraConfig["max_tokens"].(float64); ok && maxTokens > 0 {
-		chatReq.MaxTokens = int(maxTokens)
-	} else if llmConfig.MaxTokens > 0 {
-		chatReq.MaxTokens = llmConfig.MaxTokens
-	}
-
-	if temperature, ok := extraConfig["temperature"].(float64); ok {
-		chatReq.Temperature = temperature
-	}
-
-	// 如果配置了 UseThinking，设置到请求中
-	if llmConfig.UseThinking {
-		useThinking := true
-		chatReq.UseThinking = &useThinking
+	traceId := contextx.GetTraceId(ctx)
+	ctx = s.llmRouter.usage.WithScope(ctx, agent.ID, model.LLMUsageSceneChat)
+	route, err := s.llmRouter.Route(ctx, llmConfig, routing, messages, traceId)
+	if err != nil {
+		return nil, err
 	}
-
-	// 9. 调用 LLM
-	resp, err := client.Chat(ctx, chatReq)
+	resp, _, err := s.llmRouter.Chat(ctx, route, traceId)
 	if err != nil {
-		return nil, fmt.Errorf("调用LLM失败: %w", err)
+		return nil, err
 	}
 
 	return resp, nil
@@ -169,20 +124,6 @@ func (s *AgentChatService) ListSessions(ctx context.Context, treeID int64, page,
 	return sessions, total, nil
 }
 
-// ListMessages 获取消息列表
-func (s *AgentChatService) ListMessages(ctx context.Context, sessionID string) ([]*model.AgentChatMessage, error) {
-	if s.messageRepo == nil {
-		return nil, fmt.Errorf("消息Repository未初始化")
-	}
-
-	messages, err := s.messageRepo.ListBySessionID(sessionID)
-	if err != nil {
-		return nil, fmt.Errorf("获取消息列表失败: %w", err)
-	}
-
-	return messages, nil
-}
-
 // GetFunctionGenStatus 查询代码生成状态
 func (s *AgentChatService) GetFunctionGenStatus(ctx context.Context, recordID int64) (*model.FunctionGenRecord, error) {
 	if s.functionGenRepo == nil {
@@ -199,3 +140,54 @@ func (s *AgentChatService) GetFunctionGenStatus(ctx context.Context, recordID in
 
 	return record, nil
 }
+
+// SubscribeFunctionGen 订阅会话的代码生成过程（SSE）
+// 返回需要先推送的事件（断线期间错过的事件）、后续事件的通道和取消订阅函数，取消函数必须在连接断开时调用
+func (s *AgentChatService) SubscribeFunctionGen(ctx context.Context, sessionID, lastEventID string) ([]dto.FunctionGenStreamEvent, <-chan dto.FunctionGenStreamEvent, func(), error) {
+	session, err := s.sessionRepo.GetBySessionID(sessionID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil, nil, fmt.Errorf("会话不存在")
+		}
+		return nil, nil, nil, fmt.Errorf("获取会话失败: %w", err)
+	}
+	if session.User != contextx.GetRequestUser(ctx) {
+		return nil, nil, nil, fmt.Errorf("无权限：只能订阅自己的会话")
+	}
+
+	replay, ch, cancel := s.functionGenService.stream.Subscribe(sessionID, lastEventID)
+
+	// 事件缓存已过期（如 agent-server 重启）时，用最近一条生成记录补一个当前状态
+	if len(replay) == 0 && lastEventID == "" {
+		if record, err := s.functionGenRepo.GetBySessionID(sessionID); err == nil {
+			replay = append(replay, functionGenSnapshotEvent(record))
+		}
+	}
+	return replay, ch, cancel, nil
+}
+
+// functionGenSnapshotEvent 根据生成记录构建当前状态事件
+func functionGenSnapshotEvent(record *model.FunctionGenRecord) dto.FunctionGenStreamEvent {
+	event := dto.FunctionGenStreamEvent{
+		ID:        "0",
+		Type:      dto.FunctionGenEventStatus,
+		SessionID: record.SessionID,
+		RecordID:  record.ID,
+		Status:    record.Status,
+	}
+	switch record.Status {
+	case model.FunctionGenStatusCompleted:
+		event.Type = dto.FunctionGenEventResult
+		event.Code = record.Code
+		event.Result = &dto.FunctionGenCallback{
+			RecordID:       record.ID,
+			MessageID:      record.MessageID,
+			Success:        true,
+			FullGroupCodes: record.GetFullGroupCodes(),
+		}
+	case model.FunctionGenStatusFailed:
+		event.Type = dto.FunctionGenEventError
+		event.Error = record.ErrorMsg
+	}
+	return event
+}
@@ -30,38 +30,68 @@ func (s *AgentChatService) FunctionGenChat(ctx context.Context, req *dto.Functio
 		return nil, err
 	}
 
+	// 设置用量归属并检查配额（配额用完时不创建会话和生成记录）
+	ctx = s.llmRouter.usage.WithScope(ctx, agent.ID, model.LLMUsageSceneFunctionGen)
+	if err := s.llmRouter.usage.CheckQuota(ctx); err != nil {
+		return nil, err
+	}
+
 	// 2. 会话管理：创建或获取会话
 	sessionID, _, userMessage, err := s.manageSession(ctx, req, user, traceId)
 	if err != nil {
 		return nil, err
 	}
 
-	// 3. 加载历史消息
-	historyMessages, err := s.messageRepo.ListBySessionID(sessionID)
+	return s.startFunctionGen(ctx, req, agent, sessionID, userMessage, user, traceId)
+}
+
+// startFunctionGen 基于用户消息所在的分支开始生成（发送、重新生成、编辑后重发共用）
+// 调用前会话已锁定为 generating，生成结束（或启动失败）时解锁
+func (s *AgentChatService) startFunctionGen(ctx context.Context, req *dto.FunctionGenAgentChatReq, agent *model.Agent, sessionID string, userMessage *model.AgentChatMessage, user, traceId string) (resp *dto.FunctionGenAgentChatResp, err error) {
+	defer func() {
+		if err != nil {
+			s.releaseSession(ctx, sessionID, traceId)
+		}
+	}()
+
+	// 3. 加载历史消息（用户消息所在分支，从第一条消息到该用户消息）
+	messages, err := s.messageRepo.ListBySessionID(sessionID)
 	if err != nil {
 		logger.Errorf(ctx, "[FunctionGenChat] 加载历史消息失败 - SessionID: %s, TraceID: %s, Error: %v", sessionID, traceId, err)
 		return nil, fmt.Errorf("加载历史消息失败: %w", err)
 	}
-	logger.Infof(ctx, "[FunctionGenChat] 历史消息数量 - SessionID: %s, Count: %d, TraceID: %s", sessionID, len(historyMessages), traceId)
+	historyMessages := messagePath(messages, userMessage.ID)
+	logger.Infof(ctx, "[FunctionGenChat] 历史消息数量 - SessionID: %s, Count: %d, Total: %d, TraceID: %s", sessionID, len(historyMessages), len(messages), traceId)
 
 	// 4. 构建 LLM 消息列表
-	llmMessages, pluginResp, err := s.buildLLMMessages(ctx, req, agent, historyMessages, traceId)
+	llmMessages, pluginResp, pipelineLogs, err := s.buildLLMMessages(ctx, req, agent, sessionID, historyMessages, traceId)
 	if err != nil {
+		// 流水线执行失败时也保留已执行步骤的记录，便于排查
+		if len(pipelineLogs) > 0 {
+			s.saveFailedPipelineRecord(ctx, req, sessionID, userMessage.ID, user, pipelineLogs, err, traceId)
+		}
 		return nil, err
 	}
 
-	// 5. 获取 LLM 配置和客户端
-	llmConfig, client, chatReq, err := s.prepareLLMRequest(ctx, agent, llmMessages, traceId)
+	// 5. 获取 LLM 配置和客户端（主 LLM 和备用 LLM）
+	route, err := s.prepareLLMRequest(ctx, agent, llmMessages, traceId)
 	if err != nil {
 		return nil, err
 	}
 
 	// 6. 创建函数生成记录
-	record, err := s.createFunctionGenRecord(ctx, req, sessionID, userMessage.ID, user, pluginResp, traceId)
+	record, err := s.createFunctionGenRecord(ctx, req, sessionID, userMessage.ID, user, pluginResp, pipelineLogs, traceId)
 	if err != nil {
 		return nil, err
 	}
 
+	s.functionGenService.stream.Publish(dto.FunctionGenStreamEvent{
+		Type:      dto.FunctionGenEventStatus,
+		SessionID: sessionID,
+		RecordID:  record.ID,
+		Status:    model.FunctionGenStatusGenerating,
+	})
+
 	// 7. 异步更新智能体生成次数
 	go func() {
 		if err := s.agentRepo.IncrementGenerationCount(req.AgentID); err != nil {
@@ -72,7 +102,7 @@ func (s *AgentChatService) FunctionGenChat(ctx context.Context, req *dto.Functio
 	}()
 
 	// 8. 异步调用 LLM
-	s.asyncCallLLM(ctx, req, sessionID, record, user, traceId, llmConfig, client, chatReq)
+	s.asyncCallLLM(ctx, req, agent, sessionID, record, user, traceId, route)
 
 	// 9. 立即返回响应
 	return &dto.FunctionGenAgentChatResp{
@@ -131,9 +161,15 @@ func (s *AgentChatService) manageSession(ctx context.Context, req *dto.FunctionG
 		currentSession = session
 	}
 
-	// 保存用户消息
-	userMessage, err := s.saveUserMessage(ctx, req, sessionID, user, traceId)
+	// 保存用户消息（接在当前分支的最后一条消息后面）
+	parentID, err := s.currentMessageID(ctx, currentSession, traceId)
+	if err != nil {
+		s.releaseSession(ctx, sessionID, traceId)
+		return "", nil, nil, err
+	}
+	userMessage, err := s.saveUserMessage(ctx, req, sessionID, parentID, user, traceId)
 	if err != nil {
+		s.releaseSession(ctx, sessionID, traceId)
 		return "", nil, nil, err
 	}
 
@@ -207,7 +243,7 @@ func (s *AgentChatService) getAndValidateSession(ctx context.Context, sessionID,
 }
 
 // saveUserMessage 保存用户消息
-func (s *AgentChatService) saveUserMessage(ctx context.Context, req *dto.FunctionGenAgentChatReq, sessionID, user, traceId string) (*model.AgentChatMessage, error) {
+func (s *AgentChatService) saveUserMessage(ctx context.Context, req *dto.FunctionGenAgentChatReq, sessionID string, parentID int64, user, traceId string) (*model.AgentChatMessage, error) {
 	logger.Debugf(ctx, "[FunctionGenChat] 保存用户消息 - SessionID: %s, AgentID: %d, ContentLength: %d, FilesCount: %d, TraceID: %s",
 		sessionID, req.AgentID, len(req.Message.Content), len(req.Message.Files), traceId)
 
@@ -217,6 +253,7 @@ func (s *AgentChatService) saveUserMessage(ctx context.Context, req *dto.Functio
 		Role:      "user",
 		Content:   req.Message.Content,
 		User:      user,
+		ParentID:  parentID,
 	}
 
 	// 处理文件列表
@@ -239,6 +276,11 @@ func (s *AgentChatService) saveUserMessage(ctx context.Context, req *dto.Functio
 		return nil, fmt.Errorf("保存用户消息失败: %w", err)
 	}
 
+	// 新的用户消息成为当前分支的最后一条消息
+	if err := s.sessionRepo.UpdateCurrentMessage(sessionID, userMessage.ID); err != nil {
+		logger.Warnf(ctx, "[FunctionGenChat] 更新当前分支失败 - SessionID: %s, MessageID: %d, TraceID: %s, Error: %v", sessionID, userMessage.ID, traceId, err)
+	}
+
 	return userMessage, nil
 }
 
@@ -265,32 +307,30 @@ func (s *AgentChatService) generateSessionTitle(ctx context.Context, sessionID,
 }
 
 // buildLLMMessages 构建 LLM 消息列表
-func (s *AgentChatService) buildLLMMessages(ctx context.Context, req *dto.FunctionGenAgentChatReq, agent *model.Agent, historyMessages []*model.AgentChatMessage, traceId string) ([]llms.Message, *dto.PluginRunResp, error) {
+// 返回的流水线执行记录在出错时也会返回（只包含已执行的步骤）
+func (s *AgentChatService) buildLLMMessages(ctx context.Context, req *dto.FunctionGenAgentChatReq, agent *model.Agent, sessionID string, historyMessages []*model.AgentChatMessage, traceId string) ([]llms.Message, *dto.PluginRunResp, []dto.AgentPipelineStepLog, error) {
 	llmMessages := make([]llms.Message, 0)
 
 	// 1. 构建系统消息
 	systemMessage, err := s.buildSystemMessage(ctx, req, agent, traceId)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 	llmMessages = append(llmMessages, systemMessage)
 
-	// 2. 处理插件（如果是 plugin 类型智能体）
-	userContent, pluginResp, err := s.processPlugin(ctx, req, agent, traceId)
+	// 2. 处理插件（plugin 类型智能体或配置了流水线）
+	userContent, pluginResp, pipelineLogs, err := s.processPlugin(ctx, req, agent, sessionID, traceId)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, pipelineLogs, err
 	}
 
-	// 3. 添加历史消息（排除最后一条用户消息）
-	for i, msg := range historyMessages {
-		if i == len(historyMessages)-1 && msg.Role == "user" {
-			continue
-		}
-		llmMessages = append(llmMessages, llms.Message{
-			Role:    msg.Role,
-			Content: msg.Content,
-		})
+	// 3. 添加历史消息（排除最后一条用户消息，启用会话记忆时按 token 预算裁剪，摘要和最新代码追加到系统消息）
+	if n := len(historyMessages); n > 0 && historyMessages[n-1].Role == "user" {
+		historyMessages = historyMessages[:n-1]
 	}
+	memoryContext, history := s.buildMemory(ctx, agent, sessionID, historyMessages, traceId)
+	llmMessages[0].Content += memoryContext
+	llmMessages = append(llmMessages, history...)
 
 	// 4. 添加当前用户消息（包含插件处理后的内容）
 	llmMessages = append(llmMessages, llms.Message{
@@ -299,7 +339,7 @@ func (s *AgentChatService) buildLLMMessages(ctx context.Context, req *dto.Functi
 	})
 	logger.Infof(ctx, "[FunctionGenChat] 用户消息已添加 - ContentLength: %d, TraceID: %s", len(userContent), traceId)
 
-	return llmMessages, pluginResp, nil
+	return llmMessages, pluginResp, pipelineLogs, nil
 }
 
 // buildSystemMessage 构建系统消息
@@ -364,15 +404,9 @@ func (s *AgentChatService) buildSystemMessage(ctx context.Context, req *dto.Func
 }
 
 // processPlugin 处理插件
-func (s *AgentChatService) processPlugin(ctx context.Context, req *dto.FunctionGenAgentChatReq, agent *model.Agent, traceId string) (string, *dto.PluginRunResp, error) {
-	if agent.AgentType != "plugin" {
-		return req.Message.Content, nil, nil
-	}
-
-	logger.Infof(ctx, "[FunctionGenChat] 调用 Plugin - AgentID: %d, MessageLength: %d, FilesCount: %d, TraceID: %s",
-		agent.ID, len(req.Message.Content), len(req.Message.Files), traceId)
-
-	// 构建 plugin 请求
+// 配置了流水线时按顺序执行 before 阶段的步骤，最终输出作为用户消息；否则 plugin 类型智能体调用单个插件
+func (s *AgentChatService) processPlugin(ctx context.Context, req *dto.FunctionGenAgentChatReq, agent *model.Agent, sessionID, traceId string) (string, *dto.PluginRunResp, []dto.AgentPipelineStepLog, error) {
+	// 构建 plugin 请求文件
 	pluginFiles := make([]dto.PluginFile, 0, len(req.Message.Files))
 	for _, f := range req.Message.Files {
 		pluginFiles = append(pluginFiles, dto.PluginFile{
@@ -380,22 +414,59 @@ func (s *AgentChatService) processPlugin(ctx context.Context, req *dto.FunctionG
 			Remark: f.Remark,
 		})
 	}
+
+	steps, err := parseAgentPipeline(agent)
+	if err != nil {
+		logger.Errorf(ctx, "[FunctionGenChat] 流水线配置错误 - AgentID: %d, TraceID: %s, Error: %v", agent.ID, traceId, err)
+		return "", nil, nil, err
+	}
+	if beforeSteps := pipelineStageSteps(steps, dto.AgentPipelineStageBefore); len(beforeSteps) > 0 {
+		logger.Infof(ctx, "[FunctionGenChat] 执行流水线 - AgentID: %d, Steps: %d, MessageLength: %d, FilesCount: %d, TraceID: %s",
+			agent.ID, len(beforeSteps), len(req.Message.Content), len(pluginFiles), traceId)
+		userContent, logs, err := s.runPipeline(ctx, agent, beforeSteps, req.Message.Content, pluginFiles, traceId, s.publishStepEvent(sessionID, 0))
+		if err != nil {
+			return "", nil, logs, err
+		}
+		return userContent, nil, logs, nil
+	}
+
+	if agent.AgentType != "plugin" {
+		return req.Message.Content, nil, nil, nil
+	}
+
+	logger.Infof(ctx, "[FunctionGenChat] 调用 Plugin - AgentID: %d, MessageLength: %d, FilesCount: %d, TraceID: %s",
+		agent.ID, len(req.Message.Content), len(req.Message.Files), traceId)
+
 	pluginReq := &dto.PluginRunReq{
 		Message: req.Message.Content,
 		Files:   pluginFiles,
 	}
 
-	// 调用 plugin
+	// 调用 plugin（执行结果同时推送给订阅生成过程的前端）
+	stepLog := dto.AgentPipelineStepLog{
+		Name:      "插件",
+		Type:      dto.AgentPipelineStepPlugin,
+		Stage:     dto.AgentPipelineStageBefore,
+		Input:     truncatePipelineLog(req.Message.Content),
+		Attempts:  1,
+		StartedAt: time.Now().Format(time.DateTime),
+	}
+	start := time.Now()
 	pluginResp, err := s.functionGenService.RunPlugin(ctx, agent, pluginReq)
+	stepLog.DurationMs = time.Since(start).Milliseconds()
+	if err == nil && pluginResp.Error != "" {
+		err = fmt.Errorf("插件处理失败: %s", pluginResp.Error)
+	}
 	if err != nil {
+		stepLog.Status = dto.AgentPipelineStatusFailed
+		stepLog.Error = err.Error()
+		s.publishStepEvent(sessionID, 0)(stepLog)
 		logger.Errorf(ctx, "[FunctionGenChat] Plugin 调用失败 - AgentID: %d, TraceID: %s, Error: %v", agent.ID, traceId, err)
-		return "", nil, err
-	}
-
-	if pluginResp.Error != "" {
-		logger.Errorf(ctx, "[FunctionGenChat] Plugin 处理失败 - AgentID: %d, Error: %s, TraceID: %s", agent.ID, pluginResp.Error, traceId)
-		return "", nil, fmt.Errorf("插件处理失败: %s", pluginResp.Error)
+		return "", nil, nil, err
 	}
+	stepLog.Status = dto.AgentPipelineStatusSucceeded
+	stepLog.Output = truncatePipelineLog(pluginResp.Data)
+	s.publishStepEvent(sessionID, 0)(stepLog)
 
 	logger.Infof(ctx, "[FunctionGenChat] Plugin 调用成功 - AgentID: %d, DataLength: %d, TraceID: %s", agent.ID, len(pluginResp.Data), traceId)
 
@@ -408,35 +479,51 @@ func (s *AgentChatService) processPlugin(ctx context.Context, req *dto.FunctionG
 	logger.Debugf(ctx, "[FunctionGenChat] 用户消息构建完成（包含插件处理结果） - OriginalLength: %d, PluginDataLength: %d, FinalLength: %d, TraceID: %s",
 		len(req.Message.Content), len(pluginResp.Data), len(userContent), traceId)
 
-	return userContent, pluginResp, nil
+	return userContent, pluginResp, nil, nil
 }
 
-// prepareLLMRequest 准备 LLM 请求
-func (s *AgentChatService) prepareLLMRequest(ctx context.Context, agent *model.Agent, llmMessages []llms.Message, traceId string) (*model.LLMConfig, llms.LLMClient, *llms.ChatRequest, error) {
-	// 1. 获取 LLM 配置
-	logger.Debugf(ctx, "[FunctionGenChat] 获取 LLM 配置 - LLMConfigID: %d, TraceID: %s", agent.LLMConfigID, traceId)
-	var llmConfig *model.LLMConfig
-	var err error
+// prepareLLMRequest 准备 LLM 请求（按智能体的路由策略构建候选 LLM）
+func (s *AgentChatService) prepareLLMRequest(ctx context.Context, agent *model.Agent, llmMessages []llms.Message, traceId string) (*llmRoute, error) {
+	// 1. 获取主 LLM 配置
+	llmConfig, err := s.resolveLLMConfig(ctx, agent.LLMConfigID, traceId)
+	if err != nil {
+		return nil, err
+	}
 
-	if agent.LLMConfigID > 0 {
-		llmConfig, err = s.llmRepo.GetByID(agent.LLMConfigID)
+	// 2. 解析路由策略，创建候选 LLM 的客户端和请求
+	routing, err := parseAgentLLMRouting(agent)
+	if err != nil {
+		return nil, err
+	}
+	return s.llmRouter.Route(ctx, llmConfig, routing, llmMessages, traceId)
+}
+
+// resolveLLMConfig 获取 LLM 配置（llmConfigID 为 0 时使用默认配置）
+func (s *AgentChatService) resolveLLMConfig(ctx context.Context, llmConfigID int64, traceId string) (*model.LLMConfig, error) {
+	logger.Debugf(ctx, "[FunctionGenChat] 获取 LLM 配置 - LLMConfigID: %d, TraceID: %s", llmConfigID, traceId)
+	if llmConfigID > 0 {
+		llmConfig, err := s.llmRepo.GetByID(llmConfigID)
 		if err != nil {
-			logger.Errorf(ctx, "[FunctionGenChat] 获取LLM配置失败 - LLMConfigID: %d, TraceID: %s, Error: %v", agent.LLMConfigID, traceId, err)
-			return nil, nil, nil, fmt.Errorf("获取LLM配置失败: %w", err)
+			logger.Errorf(ctx, "[FunctionGenChat] 获取LLM配置失败 - LLMConfigID: %d, TraceID: %s, Error: %v", llmConfigID, traceId, err)
+			return nil, fmt.Errorf("获取LLM配置失败: %w", err)
 		}
 		logger.Infof(ctx, "[FunctionGenChat] 使用智能体绑定的LLM - LLMConfigID: %d, Provider: %s, Model: %s, TraceID: %s",
 			llmConfig.ID, llmConfig.Provider, llmConfig.Model, traceId)
-	} else {
-		llmConfig, err = s.llmRepo.GetDefault()
-		if err != nil {
-			logger.Errorf(ctx, "[FunctionGenChat] 获取默认LLM配置失败 - TraceID: %s, Error: %v", traceId, err)
-			return nil, nil, nil, fmt.Errorf("获取默认LLM配置失败: %w", err)
-		}
-		logger.Infof(ctx, "[FunctionGenChat] 使用默认LLM - LLMConfigID: %d, Provider: %s, Model: %s, TraceID: %s",
-			llmConfig.ID, llmConfig.Provider, llmConfig.Model, traceId)
+		return llmConfig, nil
+	}
+
+	llmConfig, err := s.llmRepo.GetDefault()
+	if err != nil {
+		logger.Errorf(ctx, "[FunctionGenChat] 获取默认LLM配置失败 - TraceID: %s, Error: %v", traceId, err)
+		return nil, fmt.Errorf("获取默认LLM配置失败: %w", err)
 	}
+	logger.Infof(ctx, "[FunctionGenChat] 使用默认LLM - LLMConfigID: %d, Provider: %s, Model: %s, TraceID: %s",
+		llmConfig.ID, llmConfig.Provider, llmConfig.Model, traceId)
+	return llmConfig, nil
+}
 
-	// 2. 创建 LLM 客户端
+// newLLMClient 根据 LLM 配置创建客户端
+func newLLMClient(llmConfig *model.LLMConfig) (llms.LLMClient, error) {
 	provider := llms.Provider(llmConfig.Provider)
 	options := llms.DefaultClientOptions()
 	if llmConfig.Model != "" {
@@ -449,40 +536,33 @@ func (s *AgentChatService) prepareLLMRequest(ctx context.Context, agent *model.A
 		options = options.WithBaseURL(llmConfig.APIBase)
 	}
 
-	client, err := llms.NewLLMClientWithOptions(provider, llmConfig.APIKey, options)
-	if err != nil {
-		return nil, nil, nil, fmt.Errorf("创建LLM客户端失败: %w", err)
-	}
-
-	// 3. 解析额外配置
-	var extraConfig map[string]interface{}
+	// extra_config 中的 headers（OpenAI 兼容接口的额外请求头）、mock（mock 提供商的回放脚本）
+	// 回放脚本只能内联配置，不支持从服务器本地文件读取，避免通过 LLM 配置读取任意文件
 	if llmConfig.ExtraConfig != nil && *llmConfig.ExtraConfig != "" {
-		json.Unmarshal([]byte(*llmConfig.ExtraConfig), &extraConfig)
+		var extra struct {
+			Headers map[string]string `json:"headers"`
+			Mock    *llms.MockScript  `json:"mock"`
+		}
+		if err := json.Unmarshal([]byte(*llmConfig.ExtraConfig), &extra); err != nil {
+			return nil, fmt.Errorf("解析LLM额外配置失败: %w", err)
+		}
+		if len(extra.Headers) > 0 {
+			options = options.WithHeaders(extra.Headers)
+		}
+		if extra.Mock != nil {
+			options = options.WithMock(extra.Mock)
+		}
 	}
 
-	// 4. 构建聊天请求
-	chatReq := &llms.ChatRequest{
-		Messages: llmMessages,
-		Model:    llmConfig.Model,
-	}
-	if maxTokens, ok := extraConfig["max_tokens"].(float64); ok && maxTokens > 0 {
-		chatReq.MaxTokens = int(maxTokens)
-	} else if llmConfig.MaxTokens > 0 {
-		chatReq.MaxTokens = llmConfig.MaxTokens
-	}
-	if temperature, ok := extraConfig["temperature"].(float64); ok {
-		chatReq.Temperature = temperature
-	}
-	if llmConfig.UseThinking {
-		useThinking := true
-		chatReq.UseThinking = &useThinking
+	client, err := llms.NewLLMClientWithOptions(provider, llmConfig.APIKey, options)
+	if err != nil {
+		return nil, fmt.Errorf("创建LLM客户端失败: %w", err)
 	}
-
-	return llmConfig, client, chatReq, nil
+	return client, nil
 }
 
 // createFunctionGenRecord 创建函数生成记录
-func (s *AgentChatService) createFunctionGenRecord(ctx context.Context, req *dto.FunctionGenAgentChatReq, sessionID string, messageID int64, user string, pluginResp *dto.PluginRunResp, traceId string) (*model.FunctionGenRecord, error) {
+func (s *AgentChatService) createFunctionGenRecord(ctx context.Context, req *dto.FunctionGenAgentChatReq, sessionID string, messageID int64, user string, pluginResp *dto.PluginRunResp, pipelineLogs []dto.AgentPipelineStepLog, traceId string) (*model.FunctionGenRecord, error) {
 	logger.Infof(ctx, "[FunctionGenChat] 创建生成记录 - SessionID: %s, MessageID: %d, AgentID: %d, TreeID: %d, TraceID: %s",
 		sessionID, messageID, req.AgentID, req.TreeID, traceId)
 
@@ -494,6 +574,7 @@ func (s *AgentChatService) createFunctionGenRecord(ctx context.Context, req *dto
 		Status:    model.FunctionGenStatusGenerating,
 		User:      user,
 	}
+	record.PipelineSteps = encodePipelineLogs(pipelineLogs)
 	record.CreatedBy = user
 	record.UpdatedBy = user
 
@@ -519,39 +600,94 @@ func (s *AgentChatService) createFunctionGenRecord(ctx context.Context, req *dto
 	return record, nil
 }
 
+// saveFailedPipelineRecord 流水线执行失败时保存一条失败的生成记录（尽力而为，失败只记录日志）
+func (s *AgentChatService) saveFailedPipelineRecord(ctx context.Context, req *dto.FunctionGenAgentChatReq, sessionID string, messageID int64, user string, pipelineLogs []dto.AgentPipelineStepLog, cause error, traceId string) {
+	record, err := s.createFunctionGenRecord(ctx, req, sessionID, messageID, user, nil, pipelineLogs, traceId)
+	if err != nil {
+		return
+	}
+	s.failRecord(ctx, sessionID, record.ID, cause, traceId)
+}
+
+// failRecord 把生成记录标记为失败，并推送失败事件
+func (s *AgentChatService) failRecord(ctx context.Context, sessionID string, recordID int64, cause error, traceId string) {
+	if err := s.functionGenRepo.UpdateStatus(recordID, model.FunctionGenStatusFailed, cause.Error()); err != nil {
+		logger.Errorf(ctx, "[FunctionGenChat] 更新生成记录状态失败 - RecordID: %d, TraceID: %s, Error: %v", recordID, traceId, err)
+	}
+	s.functionGenService.stream.Publish(dto.FunctionGenStreamEvent{
+		Type:      dto.FunctionGenEventError,
+		SessionID: sessionID,
+		RecordID:  recordID,
+		Status:    model.FunctionGenStatusFailed,
+		Error:     cause.Error(),
+	})
+}
+
+// publishStepEvent 返回推送插件/流水线步骤事件的回调
+func (s *AgentChatService) publishStepEvent(sessionID string, recordID int64) func(dto.AgentPipelineStepLog) {
+	return func(stepLog dto.AgentPipelineStepLog) {
+		s.functionGenService.stream.Publish(dto.FunctionGenStreamEvent{
+			Type:      dto.FunctionGenEventPluginStep,
+			SessionID: sessionID,
+			RecordID:  recordID,
+			Step:      &stepLog,
+		})
+	}
+}
+
 // asyncCallLLM 异步调用 LLM
-func (s *AgentChatService) asyncCallLLM(ctx context.Context, req *dto.FunctionGenAgentChatReq, sessionID string, record *model.FunctionGenRecord, user, traceId string, llmConfig *model.LLMConfig, client llms.LLMClient, chatReq *llms.ChatRequest) {
-	logger.Infof(ctx, "[FunctionGenChat] 启动异步 LLM 调用 - RecordID: %d, MessagesCount: %d, TraceID: %s",
-		record.ID, len(chatReq.Messages), traceId)
+func (s *AgentChatService) asyncCallLLM(ctx context.Context, req *dto.FunctionGenAgentChatReq, agent *model.Agent, sessionID string, record *model.FunctionGenRecord, user, traceId string, route *llmRoute) {
+	logger.Infof(ctx, "[FunctionGenChat] 启动异步 LLM 调用 - RecordID: %d, Candidates: %d, TraceID: %s",
+		record.ID, len(route.targets), traceId)
 
 	go func() {
-		// 创建带超时的子 context
-		llmTimeout := time.Duration(llmConfig.Timeout) * time.Second
-		if llmTimeout <= 0 {
-			llmTimeout = 600 * time.Second
-		}
+		// 创建带超时的子 context（包含切换到备用 LLM 和重试的时间）
+		llmTimeout := route.Timeout()
 		asyncCtx, cancel := context.WithTimeout(context.Background(), llmTimeout)
 		defer cancel()
+		// 生成结束（成功或失败）后解锁会话，可以继续输入或重新生成
+		defer s.releaseSession(asyncCtx, sessionID, traceId)
+		// 本次调用的用量记到生成记录上
+		if scope, ok := llmUsageScopeFrom(ctx); ok {
+			scope.RecordID = record.ID
+			asyncCtx = withLLMUsageScope(asyncCtx, scope)
+		}
 
-		logger.Infof(asyncCtx, "[FunctionGenChat] 开始调用 LLM - RecordID: %d, Provider: %s, Model: %s, Timeout: %v, MessagesCount: %d, TraceID: %s",
-			record.ID, llmConfig.Provider, llmConfig.Model, llmTimeout, len(chatReq.Messages), traceId)
+		logger.Infof(asyncCtx, "[FunctionGenChat] 开始调用 LLM - RecordID: %d, Provider: %s, Model: %s, Timeout: %v, TraceID: %s",
+			record.ID, route.primary.Provider, route.primary.Model, llmTimeout, traceId)
 
-		// 调用 LLM
-		resp, err := client.Chat(asyncCtx, chatReq)
+		// 流式调用 LLM（思考过程和回答内容实时推送给订阅生成过程的前端）
+		content, served, err := s.streamLLM(asyncCtx, sessionID, record.ID, route, traceId)
 		if err != nil {
 			logger.Errorf(asyncCtx, "[FunctionGen] LLM调用失败: %v, RecordID: %d, AgentID: %d, TraceID: %s",
 				err, record.ID, req.AgentID, traceId)
-			s.functionGenRepo.UpdateStatus(record.ID, model.FunctionGenStatusFailed, err.Error())
+			s.failRecord(asyncCtx, sessionID, record.ID, err, traceId)
 			return
 		}
+		if err := s.functionGenRepo.UpdateLLMServed(record.ID, served.ID, served.Provider, served.Model); err != nil {
+			logger.Errorf(asyncCtx, "[FunctionGen] 记录实际调用的LLM失败: %v, RecordID: %d, TraceID: %s", err, record.ID, traceId)
+		}
 
 		// 保存 assistant 消息
-		s.saveAssistantMessage(asyncCtx, sessionID, req.AgentID, resp.Content, user, record.ID, traceId)
+		s.saveAssistantMessage(asyncCtx, sessionID, req.AgentID, content, user, served, record, traceId)
 
 		// 提取代码
-		extractedCode := s.extractCodeFromLLMResponse(resp.Content)
+		extractedCode := s.extractCodeFromLLMResponse(content)
 		logger.Infof(asyncCtx, "[FunctionGen] 代码提取完成 - 原始长度: %d, 提取后长度: %d, RecordID: %d, TraceID: %s",
-			len(resp.Content), len(extractedCode), record.ID, traceId)
+			len(content), len(extractedCode), record.ID, traceId)
+
+		// 执行 after 阶段的流水线步骤（如编译检查），输出替换提取的代码
+		extractedCode, err = s.runAfterPipeline(asyncCtx, agent, sessionID, record, extractedCode, traceId)
+		if err != nil {
+			s.failRecord(asyncCtx, sessionID, record.ID, err, traceId)
+			return
+		}
+		s.functionGenService.stream.Publish(dto.FunctionGenStreamEvent{
+			Type:      dto.FunctionGenEventCode,
+			SessionID: sessionID,
+			RecordID:  record.ID,
+			Code:      extractedCode,
+		})
 
 		// 更新记录
 		if err := s.functionGenRepo.UpdateCode(record.ID, extractedCode); err != nil {
@@ -573,27 +709,106 @@ func (s *AgentChatService) asyncCallLLM(ctx context.Context, req *dto.FunctionGe
 			record.ID, len(resultData.Code), traceId)
 		if err := s.functionGenService.PublishResult(asyncCtx, resultData, traceId, user); err != nil {
 			logger.Errorf(asyncCtx, "[FunctionGenChat] 发布结果失败 - RecordID: %d, TraceID: %s, Error: %v", record.ID, traceId, err)
-			s.functionGenRepo.UpdateStatus(record.ID, model.FunctionGenStatusFailed, err.Error())
+			s.failRecord(asyncCtx, sessionID, record.ID, err, traceId)
 			return
 		}
 		logger.Infof(asyncCtx, "[FunctionGenChat] 结果已发布 - RecordID: %d, TraceID: %s", record.ID, traceId)
+
+		// 历史消息超出预算时更新会话摘要（失败不影响本次生成）
+		s.compactMemory(asyncCtx, agent, sessionID, traceId)
 	}()
 }
 
-// saveAssistantMessage 保存 assistant 消息
-func (s *AgentChatService) saveAssistantMessage(ctx context.Context, sessionID string, agentID int64, content, user string, recordID int64, traceId string) {
+// streamLLM 流式调用 LLM，返回完整的回答内容和实际提供服务的 LLM 配置
+func (s *AgentChatService) streamLLM(ctx context.Context, sessionID string, recordID int64, route *llmRoute, traceId string) (string, *model.LLMConfig, error) {
+	// SSE 单独推送思考过程，不计入回答内容
+	for _, target := range route.targets {
+		target.Request.SplitThinking = true
+	}
+	stream, served, err := s.llmRouter.ChatStream(ctx, route, traceId)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var content strings.Builder
+	var streamErr error
+	// 读完整个通道，避免提供商的 goroutine 阻塞在发送上
+	for chunk := range stream {
+		if streamErr != nil {
+			continue
+		}
+		if chunk.Error != "" {
+			streamErr = fmt.Errorf("%s", chunk.Error)
+			continue
+		}
+		if chunk.Thinking != "" {
+			s.functionGenService.stream.PublishDelta(sessionID, recordID, dto.FunctionGenEventThinking, chunk.Thinking)
+		}
+		if chunk.Content != "" {
+			content.WriteString(chunk.Content)
+			s.functionGenService.stream.PublishDelta(sessionID, recordID, dto.FunctionGenEventContent, chunk.Content)
+		}
+	}
+	if streamErr != nil {
+		return "", nil, streamErr
+	}
+	if err := ctx.Err(); err != nil {
+		return "", nil, fmt.Errorf("LLM调用超时: %w", err)
+	}
+	if content.Len() == 0 {
+		return "", nil, fmt.Errorf("LLM 返回内容为空")
+	}
+	return content.String(), served, nil
+}
+
+// runAfterPipeline 对生成的代码执行 after 阶段的流水线步骤，并把步骤记录追加到生成记录
+func (s *AgentChatService) runAfterPipeline(ctx context.Context, agent *model.Agent, sessionID string, record *model.FunctionGenRecord, code, traceId string) (string, error) {
+	steps, err := parseAgentPipeline(agent)
+	if err != nil {
+		return "", err
+	}
+	afterSteps := pipelineStageSteps(steps, dto.AgentPipelineStageAfter)
+	if len(afterSteps) == 0 {
+		return code, nil
+	}
+
+	output, logs, runErr := s.runPipeline(ctx, agent, afterSteps, code, nil, traceId, s.publishStepEvent(sessionID, record.ID))
+	allLogs := append(decodePipelineLogs(record.PipelineSteps), logs...)
+	if data := encodePipelineLogs(allLogs); data != nil {
+		if err := s.functionGenRepo.UpdatePipelineSteps(record.ID, *data); err != nil {
+			logger.Errorf(ctx, "[FunctionGen] 保存流水线步骤记录失败: %v, RecordID: %d, TraceID: %s", err, record.ID, traceId)
+		}
+	}
+	if runErr != nil {
+		return "", runErr
+	}
+	return output, nil
+}
+
+// saveAssistantMessage 保存 assistant 消息（记录实际提供服务的 LLM）
+func (s *AgentChatService) saveAssistantMessage(ctx context.Context, sessionID string, agentID int64, content, user string, served *model.LLMConfig, record *model.FunctionGenRecord, traceId string) {
 	assistantMsg := &model.AgentChatMessage{
-		SessionID: sessionID,
-		AgentID:   agentID,
-		Role:      "assistant",
-		Content:   content,
-		User:      user,
+		SessionID:   sessionID,
+		AgentID:     agentID,
+		Role:        "assistant",
+		Content:     content,
+		User:        user,
+		LLMConfigID: served.ID,
+		LLMProvider: served.Provider,
+		LLMModel:    served.Model,
+		ParentID:    record.MessageID,
+		RecordID:    record.ID,
 	}
 	assistantMsg.CreatedBy = user
 	assistantMsg.UpdatedBy = user
 
 	if err := s.messageRepo.Create(assistantMsg); err != nil {
-		logger.Errorf(ctx, "[FunctionGen] 保存assistant消息失败: %v, RecordID: %d, TraceID: %s", err, recordID, traceId)
+		logger.Errorf(ctx, "[FunctionGen] 保存assistant消息失败: %v, RecordID: %d, TraceID: %s", err, record.ID, traceId)
+		return
+	}
+	// 重新生成时新的回答替换原回答成为当前分支
+	if err := s.sessionRepo.UpdateCurrentMessage(sessionID, assistantMsg.ID); err != nil {
+		logger.Warnf(ctx, "[FunctionGen] 更新当前分支失败: %v, MessageID: %d, TraceID: %s", err, assistantMsg.ID, traceId)
 	}
 }
 
@@ -0,0 +1,330 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/ai-agent-os/ai-agent-os/core/agent-server/model"
+	"github.com/ai-agent-os/ai-agent-os/dto"
+	"github.com/ai-agent-os/ai-agent-os/pkg/llms"
+	"github.com/ai-agent-os/ai-agent-os/pkg/logger"
+)
+
+const (
+	agentMemoryDefaultMaxTokens = 16000
+	agentMemoryMinMaxTokens     = 1000
+	agentMemoryMaxMaxTokens     = 200000
+	agentMemoryDefaultTurns     = 4
+	agentMemoryMaxTurns         = 50
+	agentMemorySummaryMaxChars  = 800
+)
+
+// codeBlockPattern Markdown 代码块（总结时省略代码，代码另外保留）
+var codeBlockPattern = regexp.MustCompile("(?s)```.*?```")
+
+// parseAgentMemory 解析智能体的会话记忆策略（未配置时返回 nil）
+func parseAgentMemory(agent *model.Agent) (*dto.AgentMemory, error) {
+	if agent.Memory == nil || *agent.Memory == "" || *agent.Memory == "null" {
+		return nil, nil
+	}
+	var memory dto.AgentMemory
+	if err := json.Unmarshal([]byte(*agent.Memory), &memory); err != nil {
+		return nil, fmt.Errorf("解析会话记忆策略失败: %w", err)
+	}
+	if memory.MaxHistoryTokens <= 0 {
+		memory.MaxHistoryTokens = agentMemoryDefaultMaxTokens
+	}
+	if memory.KeepRecentTurns <= 0 {
+		memory.KeepRecentTurns = agentMemoryDefaultTurns
+	}
+	return &memory, nil
+}
+
+// applyMemory 校验并规范化智能体的会话记忆策略
+func (s *AgentService) applyMemory(agent *model.Agent) error {
+	memory, err := parseAgentMemory(agent)
+	if err != nil {
+		return err
+	}
+	if memory == nil {
+		agent.Memory = nil
+		return nil
+	}
+
+	if memory.MaxHistoryTokens < agentMemoryMinMaxTokens || memory.MaxHistoryTokens > agentMemoryMaxMaxTokens {
+		return fmt.Errorf("历史消息 token 预算需要在 %d-%d 之间", agentMemoryMinMaxTokens, agentMemoryMaxMaxTokens)
+	}
+	if memory.KeepRecentTurns > agentMemoryMaxTurns {
+		return fmt.Errorf("保留的最近轮数不能超过 %d", agentMemoryMaxTurns)
+	}
+	if memory.SummaryLLMConfigID < 0 {
+		memory.SummaryLLMConfigID = 0
+	}
+	if memory.SummaryLLMConfigID > 0 {
+		if _, err := s.llmRepo.GetByID(memory.SummaryLLMConfigID); err != nil {
+			return fmt.Errorf("生成摘要的LLM配置不存在: LLMConfigID=%d", memory.SummaryLLMConfigID)
+		}
+	}
+
+	data, err := json.Marshal(memory)
+	if err != nil {
+		return fmt.Errorf("序列化会话记忆策略失败: %w", err)
+	}
+	result := string(data)
+	agent.Memory = &result
+	return nil
+}
+
+// buildMemory 构建发送给 LLM 的历史消息
+// 未启用会话记忆时返回全部历史消息；启用时返回会话摘要和每个文件最新代码（追加到系统消息），
+// 以及摘要之后的消息（超出 token 预算时只保留能放下的最近几轮）
+func (s *AgentChatService) buildMemory(ctx context.Context, agent *model.Agent, sessionID string, history []*model.AgentChatMessage, traceId string) (string, []llms.Message) {
+	memory, err := parseAgentMemory(agent)
+	if err != nil {
+		logger.Warnf(ctx, "[AgentMemory] 会话记忆策略无效，发送全部历史消息 - AgentID: %d, TraceID: %s, Error: %v", agent.ID, traceId, err)
+	}
+	if memory == nil || !memory.Enabled {
+		return "", toLLMMessages(history)
+	}
+
+	session, err := s.sessionRepo.GetBySessionID(sessionID)
+	if err != nil {
+		logger.Warnf(ctx, "[AgentMemory] 获取会话失败，发送全部历史消息 - SessionID: %s, TraceID: %s, Error: %v", sessionID, traceId, err)
+		return "", toLLMMessages(history)
+	}
+	provider := s.memoryProvider(ctx, agent, traceId)
+
+	// 已经总结过的消息只通过摘要发送
+	summary, pending := branchSummary(session, history)
+
+	// 本轮生成完成后才会更新摘要，这里超出预算时只能丢弃较早的轮次（至少保留最近一轮）
+	budget := memory.MaxHistoryTokens - llms.CountTokens(provider, summary)
+	turns := splitTurns(pending)
+	kept := len(turns)
+	for kept > 1 && countTurnsTokens(provider, turns[len(turns)-kept:]) > budget {
+		kept--
+	}
+	if kept < len(turns) {
+		logger.Warnf(ctx, "[AgentMemory] 历史消息超出预算，丢弃较早的 %d 轮 - SessionID: %s, TraceID: %s", len(turns)-kept, sessionID, traceId)
+	}
+	recent := flattenTurns(turns[len(turns)-kept:])
+
+	// 最近几轮之前生成的代码不在原文里，单独附上每个文件的最新版本
+	cutoff := int64(-1)
+	if len(recent) > 0 {
+		cutoff = recent[0].ID
+	}
+	var context strings.Builder
+	if summary != "" {
+		context.WriteString("\n\n## 之前对话的摘要\n")
+		context.WriteString(summary)
+	}
+	if code := s.latestCode(ctx, sessionID, history, cutoff, traceId); code != "" {
+		context.WriteString("\n\n## 本会话中每个文件最新生成的代码（修改时以此为准）\n")
+		context.WriteString(code)
+	}
+
+	logger.Infof(ctx, "[AgentMemory] 历史消息 - SessionID: %s, Total: %d, Summarized: %d, Sent: %d, SummaryLength: %d, TraceID: %s",
+		sessionID, len(history), len(history)-len(pending), len(recent), len(summary), traceId)
+	return context.String(), toLLMMessages(recent)
+}
+
+// latestCode 返回当前分支上 cutoff 消息之前生成的每个文件的最新代码（cutoff 小于 0 表示全部）
+func (s *AgentChatService) latestCode(ctx context.Context, sessionID string, history []*model.AgentChatMessage, cutoff int64, traceId string) string {
+	records, err := s.functionGenRepo.ListCompletedBySessionID(sessionID)
+	if err != nil {
+		logger.Warnf(ctx, "[AgentMemory] 获取生成记录失败 - SessionID: %s, TraceID: %s, Error: %v", sessionID, traceId, err)
+		return ""
+	}
+	onBranch := make(map[int64]bool, len(history))
+	for _, msg := range history {
+		onBranch[msg.ID] = true
+	}
+
+	// 同一个文件（函数组）多次生成时只保留最新的（其他分支生成的代码不算）
+	latest := make(map[string]*model.FunctionGenRecord)
+	files := make([]string, 0)
+	for _, record := range records {
+		if record.Code == "" || !onBranch[record.MessageID] {
+			continue
+		}
+		if _, ok := latest[record.FullGroupCodes]; !ok {
+			files = append(files, record.FullGroupCodes)
+		}
+		latest[record.FullGroupCodes] = record
+	}
+
+	var builder strings.Builder
+	for _, file := range files {
+		record := latest[file]
+		if cutoff >= 0 && record.MessageID >= cutoff {
+			continue
+		}
+		name := file
+		if name == "" {
+			name = fmt.Sprintf("第 %d 次生成", record.ID)
+		}
+		fmt.Fprintf(&builder, "\n### %s\n```go\n%s\n```\n", name, strings.TrimSpace(record.Code))
+	}
+	return builder.String()
+}
+
+// compactMemory 本轮生成完成后检查历史消息，超出预算时把较早的轮次合并到会话摘要
+func (s *AgentChatService) compactMemory(ctx context.Context, agent *model.Agent, sessionID, traceId string) {
+	memory, err := parseAgentMemory(agent)
+	if err != nil || memory == nil || !memory.Enabled {
+		return
+	}
+	session, err := s.sessionRepo.GetBySessionID(sessionID)
+	if err != nil {
+		logger.Warnf(ctx, "[AgentMemory] 获取会话失败 - SessionID: %s, TraceID: %s, Error: %v", sessionID, traceId, err)
+		return
+	}
+	messages, err := s.messageRepo.ListBySessionID(sessionID)
+	if err != nil {
+		logger.Warnf(ctx, "[AgentMemory] 加载历史消息失败 - SessionID: %s, TraceID: %s, Error: %v", sessionID, traceId, err)
+		return
+	}
+	history := messagePath(messages, session.CurrentMessageID)
+	provider := s.memoryProvider(ctx, agent, traceId)
+
+	previous, pending := branchSummary(session, history)
+	if llms.CountTokens(provider, previous)+llms.CountMessagesTokens(provider, toLLMMessages(pending)) <= memory.MaxHistoryTokens {
+		return
+	}
+
+	turns := splitTurns(pending)
+	if len(turns) <= memory.KeepRecentTurns {
+		return
+	}
+	older := flattenTurns(turns[:len(turns)-memory.KeepRecentTurns])
+	summary, err := s.summarize(ctx, agent, memory, previous, older, traceId)
+	if err != nil {
+		logger.Warnf(ctx, "[AgentMemory] 生成会话摘要失败 - SessionID: %s, TraceID: %s, Error: %v", sessionID, traceId, err)
+		return
+	}
+
+	untilID := older[len(older)-1].ID
+	if err := s.sessionRepo.UpdateSummary(sessionID, summary, untilID); err != nil {
+		logger.Warnf(ctx, "[AgentMemory] 保存会话摘要失败 - SessionID: %s, TraceID: %s, Error: %v", sessionID, traceId, err)
+		return
+	}
+	logger.Infof(ctx, "[AgentMemory] 会话摘要已更新 - SessionID: %s, SummarizedMessages: %d, UntilID: %d, SummaryLength: %d, TraceID: %s",
+		sessionID, len(older), untilID, len(summary), traceId)
+}
+
+// summarize 把已有摘要和较早的对话合并成新的摘要（对话中的代码会被省略）
+func (s *AgentChatService) summarize(ctx context.Context, agent *model.Agent, memory *dto.AgentMemory, previous string, messages []*model.AgentChatMessage, traceId string) (string, error) {
+	llmConfigID := memory.SummaryLLMConfigID
+	if llmConfigID == 0 {
+		llmConfigID = agent.LLMConfigID
+	}
+	llmConfig, err := s.resolveLLMConfig(ctx, llmConfigID, traceId)
+	if err != nil {
+		return "", err
+	}
+	// 使用智能体的 LLM 时沿用它的路由策略
+	var routing *dto.AgentLLMRouting
+	if memory.SummaryLLMConfigID == 0 {
+		if routing, err = parseAgentLLMRouting(agent); err != nil {
+			return "", err
+		}
+	}
+
+	var conversation strings.Builder
+	if previous != "" {
+		conversation.WriteString("已有摘要：\n")
+		conversation.WriteString(previous)
+		conversation.WriteString("\n\n")
+	}
+	conversation.WriteString("新的对话：\n")
+	for _, msg := range messages {
+		role := "用户"
+		if msg.Role == "assistant" {
+			role = "助手"
+		}
+		content := codeBlockPattern.ReplaceAllString(msg.Content, "[代码已省略]")
+		fmt.Fprintf(&conversation, "%s：%s\n", role, strings.TrimSpace(content))
+	}
+
+	route, err := s.llmRouter.Route(ctx, llmConfig, routing, []llms.Message{
+		{Role: "system", Content: fmt.Sprintf("你负责压缩代码生成对话的历史。请把已有摘要和新的对话合并成一份新的摘要："+
+			"保留用户提出的全部需求、约束和已确认的设计（表、字段、页面、交互），以及尚未完成或被否定的事项；不要包含代码。"+
+			"摘要不超过 %d 字，直接输出摘要内容。", agentMemorySummaryMaxChars)},
+		{Role: "user", Content: conversation.String()},
+	}, traceId)
+	if err != nil {
+		return "", err
+	}
+	resp, _, err := s.llmRouter.Chat(withLLMUsageScene(ctx, model.LLMUsageSceneSummary), route, traceId)
+	if err != nil {
+		return "", err
+	}
+	summary := strings.TrimSpace(resp.Content)
+	if summary == "" {
+		return "", fmt.Errorf("LLM返回的摘要为空")
+	}
+	return summary, nil
+}
+
+// memoryProvider 计算 token 使用的提供商（智能体的 LLM）
+func (s *AgentChatService) memoryProvider(ctx context.Context, agent *model.Agent, traceId string) llms.Provider {
+	llmConfig, err := s.resolveLLMConfig(ctx, agent.LLMConfigID, traceId)
+	if err != nil {
+		return ""
+	}
+	return llms.Provider(llmConfig.Provider)
+}
+
+// branchSummary 返回适用于当前分支的会话摘要和摘要之后的消息
+// 摘要覆盖到的消息不在当前分支上（切换了分支或编辑了更早的消息）时摘要不适用，返回全部消息
+func branchSummary(session *model.AgentChatSession, history []*model.AgentChatMessage) (string, []*model.AgentChatMessage) {
+	if session.SummaryUntilID > 0 {
+		for i, msg := range history {
+			if msg.ID == session.SummaryUntilID {
+				return session.Summary, history[i+1:]
+			}
+		}
+	}
+	return "", history
+}
+
+// splitTurns 把消息按轮次分组（每轮从用户消息开始）
+func splitTurns(messages []*model.AgentChatMessage) [][]*model.AgentChatMessage {
+	turns := make([][]*model.AgentChatMessage, 0)
+	for _, msg := range messages {
+		if msg.Role == "user" || len(turns) == 0 {
+			turns = append(turns, []*model.AgentChatMessage{msg})
+			continue
+		}
+		turns[len(turns)-1] = append(turns[len(turns)-1], msg)
+	}
+	return turns
+}
+
+func flattenTurns(turns [][]*model.AgentChatMessage) []*model.AgentChatMessage {
+	messages := make([]*model.AgentChatMessage, 0)
+	for _, turn := range turns {
+		messages = append(messages, turn...)
+	}
+	return messages
+}
+
+func countTurnsTokens(provider llms.Provider, turns [][]*model.AgentChatMessage) int {
+	return llms.CountMessagesTokens(provider, toLLMMessages(flattenTurns(turns)))
+}
+
+// toLLMMessages 把聊天消息转换为 LLM 消息
+func toLLMMessages(messages []*model.AgentChatMessage) []llms.Message {
+	result := make([]llms.Message, 0, len(messages))
+	for _, msg := range messages {
+		result = append(result, llms.Message{
+			Role:    msg.Role,
+			Content: msg.Content,
+		})
+	}
+	return result
+}
@@ -0,0 +1,466 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/ai-agent-os/ai-agent-os/core/agent-server/model"
+	"github.com/ai-agent-os/ai-agent-os/dto"
+	"github.com/ai-agent-os/ai-agent-os/pkg/llms"
+	"github.com/ai-agent-os/ai-agent-os/pkg/logger"
+)
+
+const (
+	pipelineMaxSteps       = 20
+	pipelineMaxRetries     = 5
+	pipelineMaxTimeout     = 3600 // 单步超时上限（秒）
+	pipelineDefaultTopK    = 3
+	pipelineMaxTopK        = 20
+	pipelineLogLimit       = 64 * 1024 // 每一步保存的输入/输出最大字节数
+	pipelineRetryBaseDelay = 2 * time.Second
+	pipelineMaxCodeErrors  = 10
+)
+
+// parseAgentPipeline 解析智能体的流水线配置（未配置时返回 nil）
+func parseAgentPipeline(agent *model.Agent) ([]dto.AgentPipelineStep, error) {
+	if agent.Pipeline == nil || *agent.Pipeline == "" || *agent.Pipeline == "null" {
+		return nil, nil
+	}
+	var steps []dto.AgentPipelineStep
+	if err := json.Unmarshal([]byte(*agent.Pipeline), &steps); err != nil {
+		return nil, fmt.Errorf("解析流水线配置失败: %w", err)
+	}
+	return steps, nil
+}
+
+// pipelineStageSteps 获取某个阶段的步骤
+func pipelineStageSteps(steps []dto.AgentPipelineStep, stage string) []dto.AgentPipelineStep {
+	result := make([]dto.AgentPipelineStep, 0, len(steps))
+	for _, step := range steps {
+		stepStage := step.Stage
+		if stepStage == "" {
+			stepStage = dto.AgentPipelineStageBefore
+		}
+		if stepStage == stage {
+			result = append(result, step)
+		}
+	}
+	return result
+}
+
+// normalizePipeline 校验流水线配置并序列化（空流水线返回 nil）
+func (s *AgentService) normalizePipeline(steps []dto.AgentPipelineStep) (*string, error) {
+	if len(steps) == 0 {
+		return nil, nil
+	}
+	if len(steps) > pipelineMaxSteps {
+		return nil, fmt.Errorf("流水线最多 %d 个步骤", pipelineMaxSteps)
+	}
+	for i := range steps {
+		step := &steps[i]
+		name := step.Name
+		if name == "" {
+			name = fmt.Sprintf("第 %d 步", i+1)
+		}
+		if step.Stage == "" {
+			step.Stage = dto.AgentPipelineStageBefore
+		}
+		if step.Stage != dto.AgentPipelineStageBefore && step.Stage != dto.AgentPipelineStageAfter {
+			return nil, fmt.Errorf("%s 的阶段不支持: %s", name, step.Stage)
+		}
+		switch step.Type {
+		case dto.AgentPipelineStepPlugin:
+			if step.PluginID == 0 {
+				return nil, fmt.Errorf("%s 需要选择插件", name)
+			}
+			if _, err := s.pluginRepo.GetByID(step.PluginID); err != nil {
+				return nil, fmt.Errorf("%s 的插件不存在: PluginID=%d", name, step.PluginID)
+			}
+		case dto.AgentPipelineStepKnowledge:
+			if step.KnowledgeBaseID > 0 {
+				if _, err := s.knowledgeRepo.GetByID(step.KnowledgeBaseID); err != nil {
+					return nil, fmt.Errorf("%s 的知识库不存在: KBID=%d", name, step.KnowledgeBaseID)
+				}
+			}
+			if step.TopK < 0 || step.TopK > pipelineMaxTopK {
+				return nil, fmt.Errorf("%s 的文档数需要在 0-%d 之间", name, pipelineMaxTopK)
+			}
+		case dto.AgentPipelineStepLLM:
+			if step.Prompt == "" {
+				return nil, fmt.Errorf("%s 需要填写提示词", name)
+			}
+		case dto.AgentPipelineStepCompileCheck:
+		default:
+			return nil, fmt.Errorf("%s 的类型不支持: %s", name, step.Type)
+		}
+		if step.TimeoutSeconds < 0 || step.TimeoutSeconds > pipelineMaxTimeout {
+			return nil, fmt.Errorf("%s 的超时时间需要在 0-%d 秒之间", name, pipelineMaxTimeout)
+		}
+		if step.MaxRetries < 0 || step.MaxRetries > pipelineMaxRetries {
+			return nil, fmt.Errorf("%s 的重试次数需要在 0-%d 之间", name, pipelineMaxRetries)
+		}
+		switch step.When {
+		case "", dto.AgentPipelineWhenHasFiles, dto.AgentPipelineWhenNoFiles, dto.AgentPipelineWhenNotEmpty:
+		case dto.AgentPipelineWhenContains, dto.AgentPipelineWhenNotContains:
+			if step.Value == "" {
+				return nil, fmt.Errorf("%s 的执行条件缺少参数", name)
+			}
+		default:
+			return nil, fmt.Errorf("%s 的执行条件不支持: %s", name, step.When)
+		}
+	}
+
+	data, err := json.Marshal(steps)
+	if err != nil {
+		return nil, fmt.Errorf("序列化流水线失败: %w", err)
+	}
+	pipeline := string(data)
+	return &pipeline, nil
+}
+
+// pipelinePlugins 流水线中引用的插件ID
+func pipelinePlugins(steps []dto.AgentPipelineStep) []int64 {
+	var ids []int64
+	for _, step := range steps {
+		if step.Type == dto.AgentPipelineStepPlugin && step.PluginID > 0 {
+			ids = append(ids, step.PluginID)
+		}
+	}
+	return ids
+}
+
+// runPipeline 按顺序执行流水线步骤，上一步的输出作为下一步的输入
+// 返回最终输出和每一步的执行记录（失败时也会返回已执行步骤的记录）；onStep 在每一步结束时调用（可以为 nil）
+func (s *AgentChatService) runPipeline(ctx context.Context, agent *model.Agent, steps []dto.AgentPipelineStep, input string, files []dto.PluginFile, traceId string, onStep func(dto.AgentPipelineStepLog)) (string, []dto.AgentPipelineStepLog, error) {
+	logs := make([]dto.AgentPipelineStepLog, 0, len(steps))
+	addLog := func(stepLog dto.AgentPipelineStepLog) {
+		logs = append(logs, stepLog)
+		if onStep != nil {
+			onStep(stepLog)
+		}
+	}
+	current := input
+
+	for i, step := range steps {
+		name := step.Name
+		if name == "" {
+			name = fmt.Sprintf("第 %d 步", i+1)
+		}
+		stage := step.Stage
+		if stage == "" {
+			stage = dto.AgentPipelineStageBefore
+		}
+		stepLog := dto.AgentPipelineStepLog{
+			Name:      name,
+			Type:      step.Type,
+			Stage:     stage,
+			Input:     truncatePipelineLog(current),
+			StartedAt: time.Now().Format(time.DateTime),
+		}
+
+		if !pipelineConditionMet(step, current, files) {
+			stepLog.Status = dto.AgentPipelineStatusSkipped
+			stepLog.Output = stepLog.Input
+			addLog(stepLog)
+			logger.Infof(ctx, "[Pipeline] 条件不满足，跳过步骤 - AgentID: %d, Step: %s, When: %s, TraceID: %s",
+				agent.ID, name, step.When, traceId)
+			continue
+		}
+
+		start := time.Now()
+		output, attempts, err := s.runPipelineStepWithRetry(ctx, agent, step, current, files)
+		stepLog.Attempts = attempts
+		stepLog.DurationMs = time.Since(start).Milliseconds()
+
+		if err != nil {
+			stepLog.Status = dto.AgentPipelineStatusFailed
+			stepLog.Error = err.Error()
+			addLog(stepLog)
+			logger.Errorf(ctx, "[Pipeline] 步骤执行失败 - AgentID: %d, Step: %s, Attempts: %d, TraceID: %s, Error: %v",
+				agent.ID, name, attempts, traceId, err)
+			if step.ContinueOnError {
+				continue
+			}
+			return "", logs, fmt.Errorf("流水线步骤「%s」执行失败: %w", name, err)
+		}
+
+		stepLog.Status = dto.AgentPipelineStatusSucceeded
+		stepLog.Output = truncatePipelineLog(output)
+		addLog(stepLog)
+		logger.Infof(ctx, "[Pipeline] 步骤执行成功 - AgentID: %d, Step: %s, Type: %s, OutputLength: %d, Duration: %dms, TraceID: %s",
+			agent.ID, name, step.Type, len(output), stepLog.DurationMs, traceId)
+		current = output
+	}
+
+	return current, logs, nil
+}
+
+// runPipelineStepWithRetry 执行单个步骤（失败按配置重试，每次执行都有独立的超时）
+func (s *AgentChatService) runPipelineStepWithRetry(ctx context.Context, agent *model.Agent, step dto.AgentPipelineStep, input string, files []dto.PluginFile) (string, int, error) {
+	timeout := defaultPluginTimeout
+	if step.TimeoutSeconds > 0 {
+		timeout = time.Duration(step.TimeoutSeconds) * time.Second
+	}
+
+	var lastErr error
+	attempts := 0
+	for attempts <= step.MaxRetries {
+		attempts++
+		stepCtx, cancel := context.WithTimeout(ctx, timeout)
+		output, err := s.runPipelineStep(stepCtx, agent, step, input, files, timeout)
+		cancel()
+		if err == nil {
+			return output, attempts, nil
+		}
+		lastErr = err
+		if attempts > step.MaxRetries {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return "", attempts, ctx.Err()
+		case <-time.After(pipelineRetryBaseDelay * time.Duration(attempts)):
+		}
+	}
+	return "", attempts, lastErr
+}
+
+// runPipelineStep 执行单个步骤
+func (s *AgentChatService) runPipelineStep(ctx context.Context, agent *model.Agent, step dto.AgentPipelineStep, input string, files []dto.PluginFile, timeout time.Duration) (string, error) {
+	switch step.Type {
+	case dto.AgentPipelineStepPlugin:
+		resp, err := s.functionGenService.RunPipelinePlugin(ctx, step.PluginID, agent.ID, &dto.PluginRunReq{
+			Message: input,
+			Files:   files,
+		}, timeout)
+		if err != nil {
+			return "", err
+		}
+		if resp.Error != "" {
+			return "", fmt.Errorf("插件处理失败: %s", resp.Error)
+		}
+		return resp.Data, nil
+
+	case dto.AgentPipelineStepKnowledge:
+		kbID := step.KnowledgeBaseID
+		if kbID == 0 {
+			kbID = agent.KnowledgeBaseID
+		}
+		docs, err := s.knowledgeRepo.GetAllDocumentsByKBID(kbID)
+		if err != nil {
+			return "", fmt.Errorf("加载知识库文档失败: %w", err)
+		}
+		topK := step.TopK
+		if topK == 0 {
+			topK = pipelineDefaultTopK
+		}
+		matched := rankKnowledgeDocuments(docs, input, topK)
+		if len(matched) == 0 {
+			return input, nil
+		}
+		var b strings.Builder
+		b.WriteString(input)
+		b.WriteString("\n\n## 相关知识\n")
+		for _, doc := range matched {
+			b.WriteString(fmt.Sprintf("\n### %s\n%s\n", doc.Title, doc.Content))
+		}
+		return b.String(), nil
+
+	case dto.AgentPipelineStepLLM:
+		llmConfigID := step.LLMConfigID
+		if llmConfigID == 0 {
+			llmConfigID = agent.LLMConfigID
+		}
+		llmConfig, err := s.resolveLLMConfig(ctx, llmConfigID, "")
+		if err != nil {
+			return "", err
+		}
+		// 步骤同样使用智能体的路由策略（备用 LLM、重试）
+		routing, err := parseAgentLLMRouting(agent)
+		if err != nil {
+			return "", err
+		}
+		route, err := s.llmRouter.Route(ctx, llmConfig, routing, []llms.Message{
+			{Role: "system", Content: step.Prompt},
+			{Role: "user", Content: input},
+		}, "")
+		if err != nil {
+			return "", err
+		}
+		resp, _, err := s.llmRouter.Chat(withLLMUsageScene(ctx, model.LLMUsageScenePipeline), route, "")
+		if err != nil {
+			return "", err
+		}
+		return resp.Content, nil
+
+	case dto.AgentPipelineStepCompileCheck:
+		if err := checkGoCode(s.extractCodeFromLLMResponse(input)); err != nil {
+			return "", err
+		}
+		return input, nil
+	}
+	return "", fmt.Errorf("步骤类型不支持: %s", step.Type)
+}
+
+// pipelineConditionMet 判断步骤的执行条件是否满足
+func pipelineConditionMet(step dto.AgentPipelineStep, input string, files []dto.PluginFile) bool {
+	switch step.When {
+	case dto.AgentPipelineWhenHasFiles:
+		return len(files) > 0
+	case dto.AgentPipelineWhenNoFiles:
+		return len(files) == 0
+	case dto.AgentPipelineWhenContains:
+		return strings.Contains(input, step.Value)
+	case dto.AgentPipelineWhenNotContains:
+		return !strings.Contains(input, step.Value)
+	case dto.AgentPipelineWhenNotEmpty:
+		return strings.TrimSpace(input) != ""
+	}
+	return true
+}
+
+// checkGoCode 检查 Go 代码语法（只做语法检查，不做类型检查）
+func checkGoCode(code string) error {
+	if strings.TrimSpace(code) == "" {
+		return fmt.Errorf("没有可检查的代码")
+	}
+	_, err := parser.ParseFile(token.NewFileSet(), "generated.go", code, parser.AllErrors)
+	if err == nil {
+		return nil
+	}
+	errs, ok := err.(scanner.ErrorList)
+	if !ok {
+		return fmt.Errorf("代码语法错误: %w", err)
+	}
+	lines := make([]string, 0, pipelineMaxCodeErrors)
+	for i, e := range errs {
+		if i == pipelineMaxCodeErrors {
+			lines = append(lines, fmt.Sprintf("... 共 %d 处错误", len(errs)))
+			break
+		}
+		lines = append(lines, e.Error())
+	}
+	return fmt.Errorf("代码语法错误:\n%s", strings.Join(lines, "\n"))
+}
+
+// rankKnowledgeDocuments 按与输入的关键词重合度挑选文档（英文按单词、中文按相邻两字切分）
+func rankKnowledgeDocuments(docs []*model.KnowledgeDocument, query string, topK int) []*model.KnowledgeDocument {
+	terms := knowledgeTerms(query)
+	if len(terms) == 0 {
+		return nil
+	}
+
+	type scored struct {
+		doc   *model.KnowledgeDocument
+		score int
+	}
+	candidates := make([]scored, 0, len(docs))
+	for _, doc := range docs {
+		if doc.Status != "completed" {
+			continue
+		}
+		title := strings.ToLower(doc.Title)
+		content := strings.ToLower(doc.Content)
+		score := 0
+		for term := range terms {
+			// 标题命中权重更高
+			score += strings.Count(title, term) * 3
+			score += strings.Count(content, term)
+		}
+		if score > 0 {
+			candidates = append(candidates, scored{doc: doc, score: score})
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].score > candidates[j].score
+	})
+
+	result := make([]*model.KnowledgeDocument, 0, topK)
+	for i := 0; i < len(candidates) && i < topK; i++ {
+		result = append(result, candidates[i].doc)
+	}
+	return result
+}
+
+// knowledgeTerms 切分检索词
+func knowledgeTerms(text string) map[string]struct{} {
+	terms := make(map[string]struct{})
+	var word []rune
+	var han []rune
+	flushWord := func() {
+		if len(word) >= 2 {
+			terms[strings.ToLower(string(word))] = struct{}{}
+		}
+		word = word[:0]
+	}
+	flushHan := func() {
+		for i := 0; i+1 < len(han); i++ {
+			terms[string(han[i:i+2])] = struct{}{}
+		}
+		han = han[:0]
+	}
+	for _, r := range text {
+		switch {
+		case unicode.Is(unicode.Han, r):
+			flushWord()
+			han = append(han, r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_':
+			flushHan()
+			word = append(word, r)
+		default:
+			flushWord()
+			flushHan()
+		}
+	}
+	flushWord()
+	flushHan()
+	return terms
+}
+
+// truncatePipelineLog 截断过长的输入/输出（只影响保存的执行记录）
+func truncatePipelineLog(text string) string {
+	if len(text) <= pipelineLogLimit {
+		return text
+	}
+	cut := pipelineLogLimit
+	for cut > 0 && !isRuneStart(text[cut]) {
+		cut--
+	}
+	return text[:cut] + fmt.Sprintf("\n...（已截断，共 %d 字节）", len(text))
+}
+
+func isRuneStart(b byte) bool {
+	return b&0xC0 != 0x80
+}
+
+// encodePipelineLogs 序列化步骤执行记录
+func encodePipelineLogs(logs []dto.AgentPipelineStepLog) *string {
+	if len(logs) == 0 {
+		return nil
+	}
+	data, err := json.Marshal(logs)
+	if err != nil {
+		return nil
+	}
+	result := string(data)
+	return &result
+}
+
+// decodePipelineLogs 反序列化步骤执行记录
+func decodePipelineLogs(data *string) []dto.AgentPipelineStepLog {
+	if data == nil || *data == "" {
+		return nil
+	}
+	var logs []dto.AgentPipelineStepLog
+	_ = json.Unmarshal([]byte(*data), &logs)
+	return logs
+}
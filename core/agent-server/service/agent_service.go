@@ -41,14 +41,18 @@ type AgentService struct {
 	repo          *repository.AgentRepository
 	pluginRepo    *repository.PluginRepository
 	knowledgeRepo *repository.KnowledgeRepository
+	llmRepo       *repository.LLMRepository
+	registry      *PluginRegistry
 }
 
 // NewAgentService 创建智能体服务
-func NewAgentService(repo *repository.AgentRepository, pluginRepo *repository.PluginRepository, knowledgeRepo *repository.KnowledgeRepository) *AgentService {
+func NewAgentService(repo *repository.AgentRepository, pluginRepo *repository.PluginRepository, knowledgeRepo *repository.KnowledgeRepository, llmRepo *repository.LLMRepository, registry *PluginRegistry) *AgentService {
 	return &AgentService{
 		repo:          repo,
 		pluginRepo:    pluginRepo,
 		knowledgeRepo: knowledgeRepo,
+		llmRepo:       llmRepo,
+		registry:      registry,
 	}
 }
 
@@ -111,6 +115,21 @@ func (s *AgentService) CreateAgent(ctx context.Context, agent *model.Agent) erro
 	}
 	agent.Metadata = normalizedMetadata
 
+	// 校验流水线配置
+	if err := s.applyPipeline(agent); err != nil {
+		return err
+	}
+
+	// 校验 LLM 路由策略
+	if err := s.applyLLMRouting(agent); err != nil {
+		return err
+	}
+
+	// 校验会话记忆策略
+	if err := s.applyMemory(agent); err != nil {
+		return err
+	}
+
 	// 设置默认管理员（如果为空，设置为创建用户）
 	if agent.Admin == "" {
 		agent.Admin = user
@@ -171,8 +190,26 @@ func (s *AgentService) UpdateAgent(ctx context.Context, agent *model.Agent) erro
 		return fmt.Errorf("验证知识库失败: %w", err)
 	}
 
-	// 如果是 plugin 类型，验证插件是否存在
-	if agent.AgentType == "plugin" {
+	// 校验流水线配置
+	if err := s.applyPipeline(agent); err != nil {
+		return err
+	}
+
+	// 校验 LLM 路由策略
+	if err := s.applyLLMRouting(agent); err != nil {
+		return err
+	}
+
+	// 校验会话记忆策略
+	if err := s.applyMemory(agent); err != nil {
+		return err
+	}
+
+	// 如果是 plugin 类型，验证插件是否存在（配置了流水线时可以不关联单个插件）
+	if agent.AgentType == "plugin" && agent.Pipeline != nil && (agent.PluginID == nil || *agent.PluginID == 0) {
+		agent.PluginID = nil
+		agent.MsgSubject = ""
+	} else if agent.AgentType == "plugin" {
 		if agent.PluginID == nil || *agent.PluginID == 0 {
 			return fmt.Errorf("插件类型智能体必须关联插件")
 		}
@@ -238,9 +275,61 @@ func (s *AgentService) EnableAgent(ctx context.Context, id int64) error {
 	if !utils.IsAdmin(existing.Admin, user) {
 		return fmt.Errorf("无权限：只有管理员可以启用此资源")
 	}
+
+	// plugin 类型的智能体，插件必须在线才能启用
+	if existing.AgentType == "plugin" && existing.PluginID != nil && *existing.PluginID != 0 {
+		plugin, err := s.pluginRepo.GetByID(*existing.PluginID)
+		if err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return fmt.Errorf("插件不存在")
+			}
+			return fmt.Errorf("获取插件失败: %w", err)
+		}
+		if !plugin.Enabled {
+			return fmt.Errorf("插件已禁用，无法启用智能体")
+		}
+		if !s.registry.IsOnline(plugin.Subject) {
+			return fmt.Errorf("插件不在线，无法启用智能体，请先启动插件: %s", plugin.Subject)
+		}
+	}
+
+	// 流水线中的插件也必须在线
+	steps, err := parseAgentPipeline(existing)
+	if err != nil {
+		return err
+	}
+	for _, pluginID := range pipelinePlugins(steps) {
+		plugin, err := s.pluginRepo.GetByID(pluginID)
+		if err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return fmt.Errorf("流水线中的插件不存在: PluginID=%d", pluginID)
+			}
+			return fmt.Errorf("获取插件失败: %w", err)
+		}
+		if !plugin.Enabled {
+			return fmt.Errorf("流水线中的插件已禁用，无法启用智能体: %s", plugin.Name)
+		}
+		if !s.registry.IsOnline(plugin.Subject) {
+			return fmt.Errorf("流水线中的插件不在线，无法启用智能体，请先启动插件: %s", plugin.Subject)
+		}
+	}
 	return s.repo.Enable(id)
 }
 
+// applyPipeline 校验并规范化智能体的流水线配置
+func (s *AgentService) applyPipeline(agent *model.Agent) error {
+	steps, err := parseAgentPipeline(agent)
+	if err != nil {
+		return err
+	}
+	pipeline, err := s.normalizePipeline(steps)
+	if err != nil {
+		return err
+	}
+	agent.Pipeline = pipeline
+	return nil
+}
+
 // DisableAgent 禁用智能体
 func (s *AgentService) DisableAgent(ctx context.Context, id int64) error {
 	// 检查权限：只有管理员可以禁用资源
@@ -0,0 +1,400 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ai-agent-os/ai-agent-os/core/agent-server/model"
+	"github.com/ai-agent-os/ai-agent-os/core/agent-server/repository"
+	"github.com/ai-agent-os/ai-agent-os/dto"
+	"github.com/ai-agent-os/ai-agent-os/pkg/config"
+	"github.com/ai-agent-os/ai-agent-os/pkg/llms"
+	"github.com/ai-agent-os/ai-agent-os/pkg/subjects"
+	"github.com/glebarez/sqlite"
+	"github.com/nats-io/nats.go"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// fakeNATSServer 进程内的最小 NATS 服务端，只实现 PUB/HPUB/SUB/UNSUB/PING，
+// 按主题精确匹配转发消息，让测试不依赖外部 NATS
+type fakeNATSServer struct {
+	ln net.Listener
+
+	mu   sync.Mutex
+	subs map[*fakeNATSClient]map[string]string // 客户端 -> sid -> 主题
+}
+
+type fakeNATSClient struct {
+	conn net.Conn
+	wmu  sync.Mutex
+}
+
+func newFakeNATSServer(t *testing.T) *fakeNATSServer {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	s := &fakeNATSServer{ln: ln, subs: make(map[*fakeNATSClient]map[string]string)}
+	go s.accept()
+	t.Cleanup(func() { ln.Close() })
+	return s
+}
+
+func (s *fakeNATSServer) URL() string {
+	return "nats://" + s.ln.Addr().String()
+}
+
+func (s *fakeNATSServer) accept() {
+	for {
+		conn, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+		go s.serve(&fakeNATSClient{conn: conn})
+	}
+}
+
+func (s *fakeNATSServer) serve(c *fakeNATSClient) {
+	defer func() {
+		s.mu.Lock()
+		delete(s.subs, c)
+		s.mu.Unlock()
+		c.conn.Close()
+	}()
+
+	c.write(`INFO {"server_id":"fake","version":"2.10.0","proto":1,"headers":true,"max_payload":1048576}` + "\r\n")
+	r := bufio.NewReader(c.conn)
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		switch strings.ToUpper(fields[0]) {
+		case "PING":
+			c.write("PONG\r\n")
+		case "SUB":
+			// SUB <subject> [queue] <sid>
+			s.mu.Lock()
+			if s.subs[c] == nil {
+				s.subs[c] = make(map[string]string)
+			}
+			s.subs[c][fields[len(fields)-1]] = fields[1]
+			s.mu.Unlock()
+		case "UNSUB":
+			s.mu.Lock()
+			delete(s.subs[c], fields[1])
+			s.mu.Unlock()
+		case "PUB", "HPUB":
+			// PUB <subject> [reply] <size> / HPUB <subject> [reply] <hdr size> <total size>
+			headers := strings.ToUpper(fields[0]) == "HPUB"
+			sizes := 1
+			if headers {
+				sizes = 2
+			}
+			total, _ := strconv.Atoi(fields[len(fields)-1])
+			payload := make([]byte, total+2)
+			if _, err := io.ReadFull(r, payload); err != nil {
+				return
+			}
+			args := fields[2 : len(fields)-sizes]
+			s.route(fields[1], args, fields[len(fields)-sizes:], headers, payload)
+		}
+	}
+}
+
+// route 把消息转发给订阅了该主题的所有客户端
+func (s *fakeNATSServer) route(subject string, reply, sizes []string, headers bool, payload []byte) {
+	op := "MSG"
+	if headers {
+		op = "HMSG"
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for c, sids := range s.subs {
+		for sid, sub := range sids {
+			if sub != subject {
+				continue
+			}
+			parts := append([]string{op, subject, sid}, reply...)
+			parts = append(parts, sizes...)
+			c.write(strings.Join(parts, " ") + "\r\n" + string(payload))
+		}
+	}
+}
+
+func (c *fakeNATSClient) write(data string) {
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+	c.conn.Write([]byte(data))
+}
+
+// functionGenTestEnv 函数生成链路的测试环境（内存 sqlite + 进程内 NATS + mock LLM）
+type functionGenTestEnv struct {
+	db      *gorm.DB
+	nc      *nats.Conn
+	service *AgentChatService
+	stream  *FunctionGenStream
+}
+
+func newFunctionGenTestEnv(t *testing.T) *functionGenTestEnv {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: gormlogger.Default.LogMode(gormlogger.Silent)})
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("get sql.DB: %v", err)
+	}
+	// 内存库每个连接是独立的数据库，异步生成的 goroutine 必须和测试使用同一个连接
+	sqlDB.SetMaxOpenConns(1)
+	if err := model.InitTables(db); err != nil {
+		t.Fatalf("init tables: %v", err)
+	}
+
+	nc, err := nats.Connect(newFakeNATSServer(t).URL())
+	if err != nil {
+		t.Fatalf("connect nats: %v", err)
+	}
+	t.Cleanup(nc.Close)
+
+	stream, err := NewFunctionGenStream(nc)
+	if err != nil {
+		t.Fatalf("new stream: %v", err)
+	}
+	t.Cleanup(stream.Close)
+	registry, err := NewPluginRegistry(nc)
+	if err != nil {
+		t.Fatalf("new plugin registry: %v", err)
+	}
+	t.Cleanup(registry.Close)
+
+	cfg := &config.AgentServerConfig{}
+	agentRepo := repository.NewAgentRepository(db)
+	llmRepo := repository.NewLLMRepository(db)
+	sessionRepo := repository.NewChatSessionRepository(db)
+	functionGenRepo := repository.NewFunctionGenRepository(db)
+	functionGenService := NewFunctionGenService(nc, cfg, functionGenRepo, sessionRepo, repository.NewPluginRepository(db), registry, stream)
+	usage := NewLLMUsageService(repository.NewLLMUsageRepository(db), agentRepo, cfg)
+	service := NewAgentChatService(
+		agentRepo,
+		llmRepo,
+		repository.NewKnowledgeRepository(db),
+		functionGenService,
+		sessionRepo,
+		repository.NewChatMessageRepository(db),
+		functionGenRepo,
+		NewLLMRouter(llmRepo, usage),
+	)
+
+	return &functionGenTestEnv{db: db, nc: nc, service: service, stream: stream}
+}
+
+// createMockAgent 创建绑定 mock LLM 的函数生成智能体，LLM 按脚本回放
+func (e *functionGenTestEnv) createMockAgent(t *testing.T, script *llms.MockScript) *model.Agent {
+	t.Helper()
+
+	extra, err := json.Marshal(map[string]interface{}{"mock": script})
+	if err != nil {
+		t.Fatalf("marshal extra config: %v", err)
+	}
+	extraConfig := string(extra)
+	llmConfig := &model.LLMConfig{
+		Name:        "mock",
+		Provider:    string(llms.ProviderMock),
+		Model:       "mock-coder",
+		Timeout:     10,
+		ExtraConfig: &extraConfig,
+		IsDefault:   true,
+		Admin:       "tester",
+	}
+	if err := e.db.Create(llmConfig).Error; err != nil {
+		t.Fatalf("create llm config: %v", err)
+	}
+
+	agent := &model.Agent{
+		Name:                 "函数生成",
+		AgentType:            "knowledge_only",
+		ChatType:             "function_gen",
+		Enabled:              true,
+		LLMConfigID:          llmConfig.ID,
+		SystemPromptTemplate: "你是一个 Go 代码生成助手。",
+	}
+	if err := e.db.Create(agent).Error; err != nil {
+		t.Fatalf("create agent: %v", err)
+	}
+	return agent
+}
+
+// waitRecord 等待异步生成把记录更新到期望的状态
+func (e *functionGenTestEnv) waitRecord(t *testing.T, recordID int64, done func(*model.FunctionGenRecord) bool) *model.FunctionGenRecord {
+	t.Helper()
+	repo := repository.NewFunctionGenRepository(e.db)
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if record, err := repo.GetByID(recordID); err == nil && done(record) {
+			return record
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("record %d not updated in time", recordID)
+	return nil
+}
+
+// waitStreamEvent 等待会话收到指定类型的事件（事件经 NATS 异步投递），返回已缓存的事件
+func (e *functionGenTestEnv) waitStreamEvent(t *testing.T, sessionID, eventType string) []dto.FunctionGenStreamEvent {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		events, _, cancel := e.stream.Subscribe(sessionID, "")
+		cancel()
+		for _, event := range events {
+			if event.Type == eventType {
+				return events
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("session %s has no %s event", sessionID, eventType)
+	return nil
+}
+
+func TestFunctionGenChatOffline(t *testing.T) {
+	env := newFunctionGenTestEnv(t)
+
+	code := "package crm\n\nfunc CrmTicket() string {\n\treturn \"ok\"\n}"
+	agent := env.createMockAgent(t, &llms.MockScript{
+		Responses: []llms.MockResponse{{
+			Thinking: "先定义工单函数",
+			Content:  "下面是生成的代码：\n```go\n" + code + "\n```\n",
+		}},
+		ChunkSize: 8,
+	})
+
+	results, err := env.nc.SubscribeSync(subjects.GetAgentServerFunctionGenSubject())
+	if err != nil {
+		t.Fatalf("subscribe result: %v", err)
+	}
+	if err := env.nc.Flush(); err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+
+	ctx := context.WithValue(context.Background(), "request_user", "tester")
+	resp, err := env.service.FunctionGenChat(ctx, &dto.FunctionGenAgentChatReq{
+		AgentID: agent.ID,
+		TreeID:  629,
+		Package: "crm",
+		Message: dto.Message{Content: "生成一个工单函数"},
+	})
+	if err != nil {
+		t.Fatalf("FunctionGenChat: %v", err)
+	}
+	if resp.SessionID == "" || resp.RecordID == 0 || resp.Status != model.FunctionGenStatusGenerating {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+
+	// 发布给 app-server 的结果
+	msg, err := results.NextMsg(5 * time.Second)
+	if err != nil {
+		t.Fatalf("wait result: %v", err)
+	}
+	var result dto.FunctionGenResult
+	if err := json.Unmarshal(msg.Data, &result); err != nil {
+		t.Fatalf("decode result: %v", err)
+	}
+	if result.RecordID != resp.RecordID || result.AgentID != agent.ID || result.TreeID != 629 || result.User != "tester" {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	if result.Code != code {
+		t.Fatalf("result code = %q, want %q", result.Code, code)
+	}
+	if got := msg.Header.Get("X-Request-User"); got != "tester" {
+		t.Fatalf("X-Request-User = %q, want tester", got)
+	}
+
+	// 生成记录
+	record := env.waitRecord(t, resp.RecordID, func(r *model.FunctionGenRecord) bool { return r.Code != "" })
+	if record.Code != code {
+		t.Fatalf("record code = %q, want %q", record.Code, code)
+	}
+	if record.Status == model.FunctionGenStatusFailed {
+		t.Fatalf("record failed: %s", record.ErrorMsg)
+	}
+
+	// 会话消息：用户消息 + assistant 消息（只包含回答内容，不包含思考过程）
+	messages, err := repository.NewChatMessageRepository(env.db).ListBySessionID(resp.SessionID)
+	if err != nil {
+		t.Fatalf("list messages: %v", err)
+	}
+	if len(messages) != 2 || messages[0].Role != "user" || messages[1].Role != "assistant" {
+		t.Fatalf("unexpected messages: %d", len(messages))
+	}
+	if !strings.Contains(messages[1].Content, code) || strings.Contains(messages[1].Content, "先定义工单函数") {
+		t.Fatalf("unexpected assistant content: %q", messages[1].Content)
+	}
+
+	// SSE 事件：思考过程和回答内容分开推送，最后推送提取的代码
+	events := env.waitStreamEvent(t, resp.SessionID, dto.FunctionGenEventCode)
+	var thinking, content strings.Builder
+	var codeEvent string
+	for _, event := range events {
+		switch event.Type {
+		case dto.FunctionGenEventThinking:
+			thinking.WriteString(event.Delta)
+		case dto.FunctionGenEventContent:
+			content.WriteString(event.Delta)
+		case dto.FunctionGenEventCode:
+			codeEvent = event.Code
+		}
+	}
+	if thinking.String() != "先定义工单函数" {
+		t.Fatalf("thinking events = %q", thinking.String())
+	}
+	if content.String() != messages[1].Content {
+		t.Fatalf("content events = %q, want %q", content.String(), messages[1].Content)
+	}
+	if codeEvent != code {
+		t.Fatalf("code event = %q, want %q", codeEvent, code)
+	}
+}
+
+func TestFunctionGenChatOfflineLLMError(t *testing.T) {
+	env := newFunctionGenTestEnv(t)
+	agent := env.createMockAgent(t, &llms.MockScript{
+		Responses: []llms.MockResponse{{Error: "HTTP请求失败，状态码: 400"}},
+	})
+
+	ctx := context.WithValue(context.Background(), "request_user", "tester")
+	resp, err := env.service.FunctionGenChat(ctx, &dto.FunctionGenAgentChatReq{
+		AgentID: agent.ID,
+		TreeID:  629,
+		Message: dto.Message{Content: "生成一个工单函数"},
+	})
+	if err != nil {
+		t.Fatalf("FunctionGenChat: %v", err)
+	}
+	record := env.waitRecord(t, resp.RecordID, func(r *model.FunctionGenRecord) bool {
+		return r.Status == model.FunctionGenStatusFailed
+	})
+	if record.Status != model.FunctionGenStatusFailed || record.ErrorMsg == "" {
+		t.Fatalf("record status = %s, error = %q, want failed", record.Status, record.ErrorMsg)
+	}
+	if record.Code != "" {
+		t.Fatalf("record code = %q, want empty", record.Code)
+	}
+}
@@ -17,20 +17,31 @@ import (
 	"github.com/nats-io/nats.go"
 )
 
+// defaultPluginTimeout 默认插件调用超时时间
+const defaultPluginTimeout = 600 * time.Second
+
 // FunctionGenService 函数生成服务
 // 负责调用 plugin 处理输入，以及发布函数生成结果到 app-server（通过 HTTP）
 type FunctionGenService struct {
 	natsConn        *nats.Conn
 	cfg             *config.AgentServerConfig
 	functionGenRepo *repository.FunctionGenRepository
+	sessionRepo     *repository.ChatSessionRepository
+	pluginRepo      *repository.PluginRepository
+	pluginRegistry  *PluginRegistry
+	stream          *FunctionGenStream
 }
 
 // NewFunctionGenService 创建函数生成服务
-func NewFunctionGenService(natsConn *nats.Conn, cfg *config.AgentServerConfig, functionGenRepo *repository.FunctionGenRepository) *FunctionGenService {
+func NewFunctionGenService(natsConn *nats.Conn, cfg *config.AgentServerConfig, functionGenRepo *repository.FunctionGenRepository, sessionRepo *repository.ChatSessionRepository, pluginRepo *repository.PluginRepository, pluginRegistry *PluginRegistry, stream *FunctionGenStream) *FunctionGenService {
 	return &FunctionGenService{
 		natsConn:        natsConn,
 		cfg:             cfg,
 		functionGenRepo: functionGenRepo,
+		sessionRepo:     sessionRepo,
+		pluginRepo:      pluginRepo,
+		pluginRegistry:  pluginRegistry,
+		stream:          stream,
 	}
 }
 
@@ -51,7 +62,7 @@ func (s *FunctionGenService) RunPlugin(ctx context.Context, agent *model.Agent,
 		pluginSubject := subjects.BuildAgentPluginRunSubject(agent.ChatType, agent.CreatedBy, agent.ID)
 		logger.Warnf(ctx, "[FunctionGenService] 智能体未关联插件，使用旧的插件主题 - Subject: %s, AgentID: %d, TraceID: %s",
 			pluginSubject, agent.ID, traceId)
-		return s.callPlugin(ctx, pluginSubject, agent.ID, req, traceId)
+		return s.callPlugin(ctx, pluginSubject, agent.ID, req, traceId, defaultPluginTimeout)
 	}
 
 	// 3. 验证插件是否已预加载
@@ -70,25 +81,48 @@ func (s *FunctionGenService) RunPlugin(ctx context.Context, agent *model.Agent,
 		return nil, fmt.Errorf("插件主题为空: PluginID=%d", plugin.ID)
 	}
 
+	// 5. 插件没有在线实例时直接返回，避免等待请求超时
+	if !s.pluginRegistry.IsOnline(pluginSubject) {
+		return nil, fmt.Errorf("插件不在线，请检查插件进程是否已启动: PluginID=%d, Subject=%s", plugin.ID, pluginSubject)
+	}
+
 	logger.Infof(ctx, "[FunctionGenService] 开始调用 Plugin - Subject: %s, PluginID: %d, AgentID: %d, MessageLength: %d, FilesCount: %d, TraceID: %s",
 		pluginSubject, plugin.ID, agent.ID, len(req.Message), len(req.Files), traceId)
 
-	return s.callPlugin(ctx, pluginSubject, agent.ID, req, traceId)
+	return s.callPlugin(ctx, pluginSubject, agent.ID, req, traceId, defaultPluginTimeout)
+}
+
+// RunPipelinePlugin 执行流水线中的插件步骤
+// agentID 仅用于日志，timeout 为单次调用超时
+func (s *FunctionGenService) RunPipelinePlugin(ctx context.Context, pluginID, agentID int64, req *dto.PluginRunReq, timeout time.Duration) (*dto.PluginRunResp, error) {
+	plugin, err := s.pluginRepo.GetByID(pluginID)
+	if err != nil {
+		return nil, fmt.Errorf("获取插件失败: PluginID=%d, %w", pluginID, err)
+	}
+	if !plugin.Enabled {
+		return nil, fmt.Errorf("插件已禁用: PluginID=%d", plugin.ID)
+	}
+	if plugin.Subject == "" {
+		return nil, fmt.Errorf("插件主题为空: PluginID=%d", plugin.ID)
+	}
+	if !s.pluginRegistry.IsOnline(plugin.Subject) {
+		return nil, fmt.Errorf("插件不在线，请检查插件进程是否已启动: PluginID=%d, Subject=%s", plugin.ID, plugin.Subject)
+	}
+	return s.callPlugin(ctx, plugin.Subject, agentID, req, contextx.GetTraceId(ctx), timeout)
 }
 
 // callPlugin 调用插件的通用方法
-func (s *FunctionGenService) callPlugin(ctx context.Context, pluginSubject string, agentID int64, req *dto.PluginRunReq, traceId string) (*dto.PluginRunResp, error) {
+func (s *FunctionGenService) callPlugin(ctx context.Context, pluginSubject string, agentID int64, req *dto.PluginRunReq, traceId string, timeout time.Duration) (*dto.PluginRunResp, error) {
 
 	// 调用插件（使用 NATS Request/Reply 模式）
 	var pluginResp dto.PluginRunResp
-	// 默认 NATS 超时时间：600 秒
-	timeout := 600 * time.Second
 	logger.Debugf(ctx, "[FunctionGenService] 发送 NATS 请求 - Subject: %s, Timeout: %v, TraceID: %s",
 		pluginSubject, timeout, traceId)
 
 	startTime := time.Now()
 	_, err := msgx.RequestMsgWithTimeout(ctx, s.natsConn, pluginSubject, req, &pluginResp, timeout)
 	duration := time.Since(startTime)
+	s.pluginRegistry.RecordCall(pluginSubject, duration, err)
 
 	if err != nil {
 		logger.Errorf(ctx, "[FunctionGenService] 调用插件失败 - Subject: %s, AgentID: %d, Duration: %v, TraceID: %s, Error: %v",
@@ -181,5 +215,28 @@ func (s *FunctionGenService) ProcessFunctionGenCallback(ctx context.Context, cal
 			callback.RecordID, errorMsg, traceId)
 	}
 
+	// 推送回调结果给订阅生成过程的前端（SSE）
+	if record, err := s.functionGenRepo.GetByID(callback.RecordID); err == nil {
+		status := model.FunctionGenStatusCompleted
+		if !callback.Success {
+			status = model.FunctionGenStatusFailed
+		}
+		// 记录会话中最近一次应用到工作空间的分支
+		if callback.Success {
+			if err := s.sessionRepo.UpdateAppliedRecord(record.SessionID, record.ID); err != nil {
+				logger.Warnf(ctx, "[FunctionGenService] 记录已应用的生成记录失败 - RecordID: %d, TraceID: %s, Error: %v", record.ID, traceId, err)
+			}
+		}
+		s.stream.Publish(dto.FunctionGenStreamEvent{
+			Type:      dto.FunctionGenEventResult,
+			SessionID: record.SessionID,
+			RecordID:  record.ID,
+			Status:    status,
+			Result:    callback,
+		})
+	} else {
+		logger.Warnf(ctx, "[FunctionGenService] 获取生成记录失败，未推送回调结果 - RecordID: %d, TraceID: %s, Error: %v", callback.RecordID, traceId, err)
+	}
+
 	return nil
 }
@@ -0,0 +1,259 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ai-agent-os/ai-agent-os/dto"
+	"github.com/ai-agent-os/ai-agent-os/pkg/logger"
+	"github.com/ai-agent-os/ai-agent-os/pkg/subjects"
+	"github.com/nats-io/nats.go"
+)
+
+const (
+	functionGenStreamMaxEvents      = 20000            // 每个会话最多缓存的事件数（超出后丢弃最早的事件）
+	functionGenStreamSubscriberBuf  = 512              // SSE 订阅者缓冲区大小
+	functionGenStreamFinishedTTL    = 10 * time.Minute // 生成结束后事件保留时间（用于断线重连）
+	functionGenStreamIdleTTL        = 30 * time.Minute // 没有新事件的会话保留时间
+	functionGenStreamSweepInterval  = time.Minute
+	functionGenStreamMaxDeltaLength = 4096 // 单个 thinking/content 事件的最大长度（过长的片段会被拆分）
+)
+
+// FunctionGenStream 函数生成流式事件中心
+// 生成过程中的事件（插件步骤、思考过程、回答内容、提取的代码、回调结果）通过 NATS 在所有 agent-server 实例之间广播，
+// 每个实例都按会话缓存最近的事件，所以 SSE 连接到任意实例都可以订阅，断线后带上最后的事件ID即可补齐错过的事件。
+// 事件ID 是发布时的纳秒时间戳（同一实例内严格递增）
+type FunctionGenStream struct {
+	natsConn *nats.Conn
+	sub      *nats.Subscription
+	lastID   int64
+
+	mu       sync.Mutex
+	sessions map[string]*functionGenStreamSession
+
+	stopCh    chan struct{}
+	closeOnce sync.Once
+}
+
+// functionGenStreamSession 单个会话的事件缓存和订阅者
+type functionGenStreamSession struct {
+	events      []dto.FunctionGenStreamEvent
+	subscribers map[chan dto.FunctionGenStreamEvent]struct{}
+	updatedAt   time.Time
+	finishedAt  time.Time
+}
+
+// NewFunctionGenStream 创建函数生成流式事件中心
+func NewFunctionGenStream(natsConn *nats.Conn) (*FunctionGenStream, error) {
+	s := &FunctionGenStream{
+		natsConn: natsConn,
+		sessions: make(map[string]*functionGenStreamSession),
+		stopCh:   make(chan struct{}),
+	}
+
+	// 每个实例都需要完整的事件，所以不使用队列组
+	sub, err := natsConn.Subscribe(subjects.GetAgentServerFunctionGenStreamSubject(), s.handleEvent)
+	if err != nil {
+		return nil, fmt.Errorf("订阅函数生成事件主题失败: %w", err)
+	}
+	s.sub = sub
+
+	go s.sweep()
+	return s, nil
+}
+
+// Publish 发布事件（ID 由这里生成）
+func (s *FunctionGenStream) Publish(event dto.FunctionGenStreamEvent) {
+	if event.SessionID == "" {
+		return
+	}
+	event.ID = strconv.FormatInt(s.nextID(), 10)
+
+	data, err := json.Marshal(event)
+	if err == nil {
+		if err = s.natsConn.Publish(subjects.GetAgentServerFunctionGenStreamSubject(), data); err == nil {
+			return
+		}
+	}
+	// 广播失败时至少保证本实例上的订阅者能收到
+	logger.Warnf(context.Background(), "[FunctionGenStream] 广播事件失败，仅推送本实例 - SessionID: %s, Type: %s, Error: %v",
+		event.SessionID, event.Type, err)
+	s.deliver(event)
+}
+
+// PublishDelta 发布 thinking/content 增量事件（过长的片段拆分为多个事件）
+func (s *FunctionGenStream) PublishDelta(sessionID string, recordID int64, eventType, delta string) {
+	for len(delta) > 0 {
+		n := len(delta)
+		if n > functionGenStreamMaxDeltaLength {
+			n = functionGenStreamMaxDeltaLength
+			for n > 0 && !isRuneStart(delta[n]) {
+				n--
+			}
+		}
+		s.Publish(dto.FunctionGenStreamEvent{
+			Type:      eventType,
+			SessionID: sessionID,
+			RecordID:  recordID,
+			Delta:     delta[:n],
+		})
+		delta = delta[n:]
+	}
+}
+
+// Subscribe 订阅会话的事件
+// 返回 lastEventID 之后已缓存的事件和后续事件的通道；订阅者处理不过来时通道会被关闭，客户端重连后可以补齐。
+// 不带 lastEventID 时只返回最近一轮生成的事件（同一会话可以有多轮生成）。
+// 返回的 cancel 必须在连接断开时调用
+func (s *FunctionGenStream) Subscribe(sessionID, lastEventID string) ([]dto.FunctionGenStreamEvent, <-chan dto.FunctionGenStreamEvent, func()) {
+	lastID, _ := strconv.ParseInt(lastEventID, 10, 64)
+	ch := make(chan dto.FunctionGenStreamEvent, functionGenStreamSubscriberBuf)
+
+	s.mu.Lock()
+	session := s.session(sessionID)
+	events := session.events
+	if lastEventID == "" {
+		// 从上一轮生成的结束事件之后开始（最后一个事件本身是结束事件时，返回完整的最后一轮）
+		for i := len(events) - 2; i >= 0; i-- {
+			if isFunctionGenFinalEvent(events[i]) {
+				events = events[i+1:]
+				break
+			}
+		}
+	}
+	replay := make([]dto.FunctionGenStreamEvent, 0, len(events))
+	for _, event := range events {
+		if id, _ := strconv.ParseInt(event.ID, 10, 64); id > lastID {
+			replay = append(replay, event)
+		}
+	}
+	session.subscribers[ch] = struct{}{}
+	s.mu.Unlock()
+
+	var once sync.Once
+	cancel := func() {
+		once.Do(func() {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			if session, ok := s.sessions[sessionID]; ok {
+				if _, ok := session.subscribers[ch]; ok {
+					delete(session.subscribers, ch)
+					close(ch)
+				}
+			}
+		})
+	}
+	return replay, ch, cancel
+}
+
+// handleEvent 处理广播的事件
+func (s *FunctionGenStream) handleEvent(msg *nats.Msg) {
+	var event dto.FunctionGenStreamEvent
+	if err := json.Unmarshal(msg.Data, &event); err != nil {
+		logger.Warnf(context.Background(), "[FunctionGenStream] 解析事件失败: %v", err)
+		return
+	}
+	s.deliver(event)
+}
+
+// deliver 缓存事件并推送给本实例上的订阅者
+func (s *FunctionGenStream) deliver(event dto.FunctionGenStreamEvent) {
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session := s.session(event.SessionID)
+	session.events = append(session.events, event)
+	if len(session.events) > functionGenStreamMaxEvents {
+		session.events = session.events[len(session.events)-functionGenStreamMaxEvents:]
+	}
+	session.updatedAt = now
+	if isFunctionGenFinalEvent(event) {
+		session.finishedAt = now
+	} else {
+		// 同一会话可以继续生成（多轮对话），有新事件时重新计时
+		session.finishedAt = time.Time{}
+	}
+
+	for ch := range session.subscribers {
+		select {
+		case ch <- event:
+		default:
+			// 订阅者处理不过来，断开连接让客户端带上最后的事件ID重连
+			delete(session.subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+// session 获取会话（不存在时创建，调用方需持有锁）
+func (s *FunctionGenStream) session(sessionID string) *functionGenStreamSession {
+	session, ok := s.sessions[sessionID]
+	if !ok {
+		session = &functionGenStreamSession{
+			subscribers: make(map[chan dto.FunctionGenStreamEvent]struct{}),
+			updatedAt:   time.Now(),
+		}
+		s.sessions[sessionID] = session
+	}
+	return session
+}
+
+// nextID 生成事件ID（纳秒时间戳，同一实例内严格递增）
+func (s *FunctionGenStream) nextID() int64 {
+	for {
+		last := atomic.LoadInt64(&s.lastID)
+		id := time.Now().UnixNano()
+		if id <= last {
+			id = last + 1
+		}
+		if atomic.CompareAndSwapInt64(&s.lastID, last, id) {
+			return id
+		}
+	}
+}
+
+// sweep 定期清理过期会话的事件缓存（还有订阅者的会话保留）
+func (s *FunctionGenStream) sweep() {
+	ticker := time.NewTicker(functionGenStreamSweepInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.stopCh:
+			return
+		case now := <-ticker.C:
+			s.mu.Lock()
+			for sessionID, session := range s.sessions {
+				if len(session.subscribers) > 0 {
+					continue
+				}
+				finished := !session.finishedAt.IsZero() && now.Sub(session.finishedAt) > functionGenStreamFinishedTTL
+				idle := now.Sub(session.updatedAt) > functionGenStreamIdleTTL
+				if finished || idle {
+					delete(s.sessions, sessionID)
+				}
+			}
+			s.mu.Unlock()
+		}
+	}
+}
+
+// isFunctionGenFinalEvent 是否是一轮生成的结束事件
+func isFunctionGenFinalEvent(event dto.FunctionGenStreamEvent) bool {
+	return event.Type == dto.FunctionGenEventResult || event.Type == dto.FunctionGenEventError
+}
+
+// Close 取消订阅并停止清理
+func (s *FunctionGenStream) Close() {
+	s.closeOnce.Do(func() {
+		close(s.stopCh)
+		if s.sub != nil {
+			_ = s.sub.Unsubscribe()
+		}
+	})
+}
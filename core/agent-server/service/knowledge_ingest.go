@@ -0,0 +1,823 @@
+package service
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ai-agent-os/ai-agent-os/core/agent-server/model"
+	"github.com/ai-agent-os/ai-agent-os/core/agent-server/utils"
+	"github.com/ai-agent-os/ai-agent-os/dto"
+	"github.com/ai-agent-os/ai-agent-os/pkg/contextx"
+	"github.com/ai-agent-os/ai-agent-os/pkg/logger"
+	"github.com/ai-agent-os/ai-agent-os/pkg/mdconv"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	// ingestMaxFileSize 单个文件的最大字节数，超过的文件记为失败
+	ingestMaxFileSize = 20 << 20
+	// ingestMaxTotalSize 来源解压后的最大总字节数（防止压缩炸弹）
+	ingestMaxTotalSize = 500 << 20
+	// ingestFlushSize 每处理多少个文件保存一次进度
+	ingestFlushSize = 50
+	// ingestDirFileType 目录节点的文件类型
+	ingestDirFileType = "dir"
+)
+
+// ingestFile 批量导入来源中的一个文件
+type ingestFile struct {
+	path string // 相对路径（以 / 分隔）
+	data []byte
+	err  error // 读取失败的原因（如文件过大）
+}
+
+// IngestArchive 从压缩包（zip/tar/tar.gz）批量导入文档，返回后台导入任务
+func (s *KnowledgeService) IngestArchive(ctx context.Context, req *dto.KnowledgeIngestArchiveReq, fileName string, data []byte) (*model.KnowledgeIngestJob, error) {
+	if archiveFormat(fileName) == "" {
+		return nil, fmt.Errorf("不支持的压缩包格式，仅支持 zip、tar、tar.gz")
+	}
+	subDir, err := cleanIngestSubDir(req.SubDir)
+	if err != nil {
+		return nil, err
+	}
+	job := &model.KnowledgeIngestJob{
+		KnowledgeBaseID: req.KnowledgeBaseID,
+		SourceType:      model.KnowledgeIngestSourceArchive,
+		Source:          fileName,
+		SubDir:          subDir,
+		DeleteMissing:   req.DeleteMissing,
+	}
+	if err := s.createIngestJob(ctx, job); err != nil {
+		return nil, err
+	}
+
+	go s.runIngest(job, func() ([]ingestFile, error) {
+		files, err := readIngestArchive(fileName, data)
+		if err != nil {
+			return nil, err
+		}
+		return filterIngestSubDir(stripCommonRoot(files), subDir), nil
+	})
+	return job, nil
+}
+
+// IngestGit 从服务器上的本地 git 仓库批量导入文档（读取指定提交中的文件，不依赖工作区），返回后台导入任务
+func (s *KnowledgeService) IngestGit(ctx context.Context, req *dto.KnowledgeIngestGitReq) (*model.KnowledgeIngestJob, error) {
+	repoPath, err := s.checkIngestRepoPath(req.RepoPath)
+	if err != nil {
+		return nil, err
+	}
+	subDir, err := cleanIngestSubDir(req.SubDir)
+	if err != nil {
+		return nil, err
+	}
+
+	repo, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return nil, fmt.Errorf("打开 git 仓库失败: %w", err)
+	}
+	ref := req.Ref
+	if ref == "" {
+		ref = "HEAD"
+	}
+	hash, err := repo.ResolveRevision(plumbing.Revision(ref))
+	if err != nil {
+		return nil, fmt.Errorf("解析 git 引用 %s 失败: %w", ref, err)
+	}
+	commit, err := repo.CommitObject(*hash)
+	if err != nil {
+		return nil, fmt.Errorf("读取 git 提交 %s 失败: %w", hash.String(), err)
+	}
+
+	job := &model.KnowledgeIngestJob{
+		KnowledgeBaseID: req.KnowledgeBaseID,
+		SourceType:      model.KnowledgeIngestSourceGit,
+		Source:          repoPath,
+		Ref:             ref,
+		Commit:          commit.Hash.String(),
+		SubDir:          subDir,
+		DeleteMissing:   req.DeleteMissing,
+	}
+	if err := s.createIngestJob(ctx, job); err != nil {
+		return nil, err
+	}
+
+	go s.runIngest(job, func() ([]ingestFile, error) {
+		files, err := readIngestCommit(commit)
+		if err != nil {
+			return nil, err
+		}
+		return filterIngestSubDir(files, subDir), nil
+	})
+	return job, nil
+}
+
+// ListIngestJobs 获取知识库的批量导入任务列表
+func (s *KnowledgeService) ListIngestJobs(ctx context.Context, kbID int64, page, pageSize int) ([]*model.KnowledgeIngestJob, int64, error) {
+	if pageSize <= 0 {
+		pageSize = 10
+	}
+	if page <= 0 {
+		page = 1
+	}
+	return s.ingestRepo.ListJobs(kbID, (page-1)*pageSize, pageSize)
+}
+
+// GetIngestJob 获取批量导入任务和文件处理结果（status 为空表示全部文件）
+func (s *KnowledgeService) GetIngestJob(ctx context.Context, id int64, status string, page, pageSize int) (*model.KnowledgeIngestJob, []*model.KnowledgeIngestFile, int64, error) {
+	job, err := s.ingestRepo.GetJob(id)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil, 0, fmt.Errorf("导入任务不存在")
+		}
+		return nil, nil, 0, fmt.Errorf("获取导入任务失败: %w", err)
+	}
+	if pageSize <= 0 {
+		pageSize = 50
+	}
+	if page <= 0 {
+		page = 1
+	}
+	files, total, err := s.ingestRepo.ListFiles(id, status, (page-1)*pageSize, pageSize)
+	if err != nil {
+		return nil, nil, 0, fmt.Errorf("获取导入文件列表失败: %w", err)
+	}
+	return job, files, total, nil
+}
+
+// FailInterruptedIngestJobs 服务启动时把未结束的导入任务标记为失败（后台任务随进程退出已中断）
+func (s *KnowledgeService) FailInterruptedIngestJobs(ctx context.Context) {
+	count, err := s.ingestRepo.FailUnfinishedJobs("服务重启，导入任务已中断，请重新导入")
+	if err != nil {
+		logger.Errorf(ctx, "[KnowledgeIngest] 标记中断的导入任务失败 - Error: %v", err)
+		return
+	}
+	if count > 0 {
+		logger.Warnf(ctx, "[KnowledgeIngest] 已将 %d 个中断的导入任务标记为失败", count)
+	}
+}
+
+// createIngestJob 检查知识库权限和是否有进行中的导入任务后创建任务
+func (s *KnowledgeService) createIngestJob(ctx context.Context, job *model.KnowledgeIngestJob) error {
+	user := contextx.GetRequestUser(ctx)
+	kb, err := s.repo.GetByID(job.KnowledgeBaseID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return fmt.Errorf("知识库不存在")
+		}
+		return fmt.Errorf("获取知识库失败: %w", err)
+	}
+	if !utils.IsAdmin(kb.Admin, user) {
+		return fmt.Errorf("无权限：只有管理员可以导入文档")
+	}
+
+	running, err := s.ingestRepo.HasUnfinishedJob(kb.ID)
+	if err != nil {
+		return fmt.Errorf("检查导入任务失败: %w", err)
+	}
+	if running {
+		return fmt.Errorf("知识库有正在进行的导入任务，请等待完成后再导入")
+	}
+
+	job.Status = model.KnowledgeIngestStatusPending
+	job.User = user
+	job.CreatedBy = user
+	job.UpdatedBy = user
+	if err := s.ingestRepo.CreateJob(job); err != nil {
+		return fmt.Errorf("创建导入任务失败: %w", err)
+	}
+	return nil
+}
+
+// checkIngestRepoPath 检查仓库路径是否在允许的根目录下（解析符号链接后比较）
+func (s *KnowledgeService) checkIngestRepoPath(repoPath string) (string, error) {
+	var roots []string
+	if s.cfg != nil {
+		roots = s.cfg.GetIngestRoots()
+	}
+	if len(roots) == 0 {
+		return "", fmt.Errorf("未配置 agent.ingest_roots，不允许从服务器本地路径导入")
+	}
+
+	resolved, err := filepath.Abs(repoPath)
+	if err != nil {
+		return "", fmt.Errorf("仓库路径无效: %w", err)
+	}
+	if resolved, err = filepath.EvalSymlinks(resolved); err != nil {
+		return "", fmt.Errorf("仓库路径不存在: %s", repoPath)
+	}
+	for _, root := range roots {
+		root, err := filepath.Abs(root)
+		if err != nil {
+			continue
+		}
+		if evaluated, err := filepath.EvalSymlinks(root); err == nil {
+			root = evaluated
+		}
+		rel, err := filepath.Rel(root, resolved)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return resolved, nil
+		}
+	}
+	return "", fmt.Errorf("仓库路径 %s 不在允许导入的目录中", repoPath)
+}
+
+// runIngest 后台执行导入任务
+func (s *KnowledgeService) runIngest(job *model.KnowledgeIngestJob, load func() ([]ingestFile, error)) {
+	ctx := context.Background()
+	start := time.Now()
+	defer func() {
+		if r := recover(); r != nil {
+			job.Status = model.KnowledgeIngestStatusFailed
+			job.ErrorMsg = fmt.Sprintf("导入任务异常: %v", r)
+			job.Duration = int(time.Since(start).Seconds())
+			if err := s.ingestRepo.UpdateJob(job); err != nil {
+				logger.Errorf(ctx, "[KnowledgeIngest] 保存导入任务失败 - JobID: %d, Error: %v", job.ID, err)
+			}
+			logger.Errorf(ctx, "[KnowledgeIngest] 导入任务异常 - JobID: %d, Panic: %v", job.ID, r)
+		}
+	}()
+
+	job.Status = model.KnowledgeIngestStatusRunning
+	if err := s.ingestRepo.UpdateJob(job); err != nil {
+		logger.Errorf(ctx, "[KnowledgeIngest] 保存导入任务失败 - JobID: %d, Error: %v", job.ID, err)
+	}
+	logger.Infof(ctx, "[KnowledgeIngest] 开始导入 - JobID: %d, KnowledgeBaseID: %d, Source: %s, Ref: %s, Commit: %s",
+		job.ID, job.KnowledgeBaseID, job.Source, job.Ref, job.Commit)
+
+	err := s.ingest(ctx, job, load)
+	job.Duration = int(time.Since(start).Seconds())
+	if err != nil {
+		job.Status = model.KnowledgeIngestStatusFailed
+		job.ErrorMsg = err.Error()
+		logger.Errorf(ctx, "[KnowledgeIngest] 导入失败 - JobID: %d, Error: %v", job.ID, err)
+	} else {
+		job.Status = model.KnowledgeIngestStatusSucceeded
+		logger.Infof(ctx, "[KnowledgeIngest] 导入完成 - JobID: %d, Total: %d, Created: %d, Updated: %d, Unchanged: %d, Deleted: %d, Failed: %d, Skipped: %d, Duration: %ds",
+			job.ID, job.Total, job.Created, job.Updated, job.Unchanged, job.Deleted, job.Failed, job.Skipped, job.Duration)
+	}
+	if err := s.ingestRepo.UpdateJob(job); err != nil {
+		logger.Errorf(ctx, "[KnowledgeIngest] 保存导入任务失败 - JobID: %d, Error: %v", job.ID, err)
+	}
+}
+
+// ingest 把来源中的文件同步到知识库：目录对应空内容的目录文档，按 SourcePath 匹配已导入的文档，内容哈希相同则跳过
+func (s *KnowledgeService) ingest(ctx context.Context, job *model.KnowledgeIngestJob, load func() ([]ingestFile, error)) error {
+	files, err := load()
+	if err != nil {
+		return err
+	}
+
+	var supported []ingestFile
+	for _, f := range files {
+		if ingestFileType(f.path) == "" {
+			job.Skipped++
+			continue
+		}
+		supported = append(supported, f)
+	}
+	sort.Slice(supported, func(i, j int) bool { return supported[i].path < supported[j].path })
+	job.Total = len(supported)
+	if err := s.ingestRepo.UpdateJob(job); err != nil {
+		return fmt.Errorf("保存导入任务失败: %w", err)
+	}
+
+	existing, err := s.repo.GetDocumentsTreeByKBID(job.KnowledgeBaseID)
+	if err != nil {
+		return fmt.Errorf("获取知识库文档失败: %w", err)
+	}
+	syncer := newIngestSyncer(s, job, existing)
+
+	var results []*model.KnowledgeIngestFile
+	flush := func() error {
+		if err := s.ingestRepo.CreateFiles(results); err != nil {
+			return fmt.Errorf("保存文件处理结果失败: %w", err)
+		}
+		results = nil
+		if err := s.ingestRepo.UpdateJob(job); err != nil {
+			return fmt.Errorf("保存导入任务失败: %w", err)
+		}
+		return nil
+	}
+
+	for _, f := range supported {
+		result := syncer.syncFile(f)
+		switch result.Status {
+		case model.KnowledgeIngestFileCreated:
+			job.Created++
+		case model.KnowledgeIngestFileUpdated:
+			job.Updated++
+		case model.KnowledgeIngestFileUnchanged:
+			job.Unchanged++
+		case model.KnowledgeIngestFileFailed:
+			job.Failed++
+		}
+		job.Processed++
+		results = append(results, result)
+		if len(results) >= ingestFlushSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+
+	if job.DeleteMissing {
+		for _, result := range syncer.deleteMissing() {
+			if result.Status == model.KnowledgeIngestFileDeleted {
+				job.Deleted++
+			} else {
+				job.Failed++
+			}
+			results = append(results, result)
+		}
+	}
+	if err := flush(); err != nil {
+		return err
+	}
+	return s.refreshKnowledgeStats(job.KnowledgeBaseID)
+}
+
+// refreshKnowledgeStats 重新计算知识库的文档数量（不含目录）和内容哈希
+func (s *KnowledgeService) refreshKnowledgeStats(kbID int64) error {
+	docs, err := s.repo.GetDocumentsTreeByKBID(kbID)
+	if err != nil {
+		return fmt.Errorf("获取知识库文档失败: %w", err)
+	}
+	sort.Slice(docs, func(i, j int) bool {
+		if docs[i].Path != docs[j].Path {
+			return docs[i].Path < docs[j].Path
+		}
+		return docs[i].ID < docs[j].ID
+	})
+
+	count := 0
+	h := sha256.New()
+	for _, doc := range docs {
+		if doc.FileType == ingestDirFileType {
+			continue
+		}
+		count++
+		hash := doc.ContentHash
+		if hash == "" {
+			hash = contentHash(doc.Content)
+		}
+		fmt.Fprintf(h, "%s\x00%s\n", doc.Path, hash)
+	}
+	if err := s.repo.UpdateStats(kbID, count, hex.EncodeToString(h.Sum(nil))); err != nil {
+		return fmt.Errorf("更新知识库统计失败: %w", err)
+	}
+	return nil
+}
+
+// ingestSyncer 把来源文件同步为知识库文档
+type ingestSyncer struct {
+	s        *KnowledgeService
+	job      *model.KnowledgeIngestJob
+	bySource map[string]*model.KnowledgeDocument // SourcePath -> 已导入的文档
+	children map[int64]int                       // 父文档ID -> 子文档数量（用于新文档排序）
+	seen     map[string]bool                     // 本次来源中存在的 SourcePath
+}
+
+func newIngestSyncer(s *KnowledgeService, job *model.KnowledgeIngestJob, docs []*model.KnowledgeDocument) *ingestSyncer {
+	syncer := &ingestSyncer{
+		s:        s,
+		job:      job,
+		bySource: map[string]*model.KnowledgeDocument{},
+		children: map[int64]int{},
+		seen:     map[string]bool{},
+	}
+	for _, doc := range docs {
+		syncer.children[doc.ParentID]++
+		if doc.SourcePath != "" {
+			syncer.bySource[doc.SourcePath] = doc
+		}
+	}
+	return syncer
+}
+
+// syncFile 同步单个文件，返回处理结果
+func (x *ingestSyncer) syncFile(f ingestFile) *model.KnowledgeIngestFile {
+	fileType := ingestFileType(f.path)
+	result := &model.KnowledgeIngestFile{
+		JobID:    x.job.ID,
+		Path:     f.path,
+		FileType: fileType,
+	}
+	result.CreatedBy = x.job.User
+	result.UpdatedBy = x.job.User
+	x.seen[f.path] = true
+
+	fail := func(err error) *model.KnowledgeIngestFile {
+		result.Status = model.KnowledgeIngestFileFailed
+		result.ErrorMsg = err.Error()
+		return result
+	}
+	if f.err != nil {
+		return fail(f.err)
+	}
+
+	content, err := convertIngestFile(fileType, f.data)
+	if err != nil {
+		return fail(err)
+	}
+	parent, err := x.ensureDir(path.Dir(f.path))
+	if err != nil {
+		return fail(err)
+	}
+	title := ingestTitle(f.path)
+	docPath := parent.Path + "/" + title
+	hash := contentHash(content)
+
+	if doc := x.bySource[f.path]; doc != nil {
+		result.DocumentID = doc.ID
+		if doc.ContentHash == hash && doc.ParentID == parent.ID && doc.Path == docPath {
+			result.Status = model.KnowledgeIngestFileUnchanged
+			return result
+		}
+		doc.ParentID = parent.ID
+		doc.Path = docPath
+		doc.Content = content
+		doc.ContentHash = hash
+		doc.FileType = fileType
+		doc.FileSize = int64(len(content))
+		doc.Status = "completed"
+		doc.UpdatedBy = x.job.User
+		if err := x.s.repo.UpdateDocument(doc); err != nil {
+			return fail(fmt.Errorf("更新文档失败: %w", err))
+		}
+		result.Status = model.KnowledgeIngestFileUpdated
+		return result
+	}
+
+	doc := x.newDocument(parent.ID, title, docPath, f.path)
+	doc.Content = content
+	doc.ContentHash = hash
+	doc.FileType = fileType
+	doc.FileSize = int64(len(content))
+	if err := x.s.repo.AddDocument(doc); err != nil {
+		return fail(fmt.Errorf("创建文档失败: %w", err))
+	}
+	x.bySource[f.path] = doc
+	result.DocumentID = doc.ID
+	result.Status = model.KnowledgeIngestFileCreated
+	return result
+}
+
+// ensureDir 确保目录（及其上级目录）对应的目录文档存在，根目录返回 ID 为 0 的空文档
+func (x *ingestSyncer) ensureDir(dir string) (*model.KnowledgeDocument, error) {
+	if dir == "." || dir == "" {
+		return &model.KnowledgeDocument{}, nil
+	}
+	sourcePath := dir + "/"
+	x.seen[sourcePath] = true
+	if doc := x.bySource[sourcePath]; doc != nil {
+		return doc, nil
+	}
+
+	parent, err := x.ensureDir(path.Dir(dir))
+	if err != nil {
+		return nil, err
+	}
+	title := path.Base(dir)
+	doc := x.newDocument(parent.ID, title, parent.Path+"/"+title, sourcePath)
+	doc.FileType = ingestDirFileType
+	if err := x.s.repo.AddDocument(doc); err != nil {
+		return nil, fmt.Errorf("创建目录 %s 失败: %w", dir, err)
+	}
+	x.bySource[sourcePath] = doc
+	return doc, nil
+}
+
+func (x *ingestSyncer) newDocument(parentID int64, title, docPath, sourcePath string) *model.KnowledgeDocument {
+	doc := &model.KnowledgeDocument{
+		KnowledgeBaseID: x.job.KnowledgeBaseID,
+		ParentID:        parentID,
+		DocID:           uuid.New().String(),
+		Title:           title,
+		Status:          "completed",
+		SortOrder:       x.children[parentID],
+		Path:            docPath,
+		SourcePath:      sourcePath,
+		User:            x.job.User,
+	}
+	doc.CreatedBy = x.job.User
+	doc.UpdatedBy = x.job.User
+	x.children[parentID]++
+	return doc
+}
+
+// deleteMissing 删除来源中已不存在的导入文档（手动添加的文档不受影响），仍有子文档的目录保留
+func (x *ingestSyncer) deleteMissing() []*model.KnowledgeIngestFile {
+	var missing []*model.KnowledgeDocument
+	for sourcePath, doc := range x.bySource {
+		if !x.seen[sourcePath] {
+			missing = append(missing, doc)
+		}
+	}
+	// 先删文件再删目录，深层目录先删
+	sort.Slice(missing, func(i, j int) bool {
+		di, dj := missing[i].FileType == ingestDirFileType, missing[j].FileType == ingestDirFileType
+		if di != dj {
+			return !di
+		}
+		return strings.Count(missing[i].SourcePath, "/") > strings.Count(missing[j].SourcePath, "/")
+	})
+
+	var results []*model.KnowledgeIngestFile
+	for _, doc := range missing {
+		if doc.FileType == ingestDirFileType && x.children[doc.ID] > 0 {
+			continue
+		}
+		result := &model.KnowledgeIngestFile{
+			JobID:      x.job.ID,
+			Path:       doc.SourcePath,
+			DocumentID: doc.ID,
+			FileType:   doc.FileType,
+			Status:     model.KnowledgeIngestFileDeleted,
+		}
+		result.CreatedBy = x.job.User
+		result.UpdatedBy = x.job.User
+		if err := x.s.repo.DeleteDocument(doc.ID); err != nil {
+			result.Status = model.KnowledgeIngestFileFailed
+			result.ErrorMsg = fmt.Sprintf("删除文档失败: %v", err)
+		} else {
+			x.children[doc.ParentID]--
+			delete(x.bySource, doc.SourcePath)
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// ingestFileType 根据扩展名返回支持的文件类型，不支持时返回空
+func ingestFileType(name string) string {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".md", ".markdown":
+		return "md"
+	case ".txt":
+		return "txt"
+	case ".html", ".htm":
+		return "html"
+	case ".docx":
+		return "docx"
+	}
+	return ""
+}
+
+// convertIngestFile 把文件内容转换为 Markdown
+func convertIngestFile(fileType string, data []byte) (string, error) {
+	switch fileType {
+	case "html":
+		return mdconv.HTMLToMarkdown(string(data))
+	case "docx":
+		return mdconv.DocxToMarkdown(data)
+	}
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	if !utf8.Valid(data) {
+		return "", fmt.Errorf("文件不是 UTF-8 编码")
+	}
+	return strings.ReplaceAll(string(data), "\r\n", "\n"), nil
+}
+
+// ingestTitle 文件名去掉扩展名作为文档标题
+func ingestTitle(name string) string {
+	base := path.Base(name)
+	if title := strings.TrimSuffix(base, path.Ext(base)); title != "" {
+		return title
+	}
+	return base
+}
+
+func contentHash(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+// cleanIngestSubDir 规范化子目录参数
+func cleanIngestSubDir(subDir string) (string, error) {
+	subDir = strings.Trim(strings.ReplaceAll(subDir, "\\", "/"), "/")
+	if subDir == "" {
+		return "", nil
+	}
+	cleaned, ok := cleanIngestPath(subDir)
+	if !ok {
+		return "", fmt.Errorf("子目录无效: %s", subDir)
+	}
+	return cleaned, nil
+}
+
+// cleanIngestPath 规范化来源中的相对路径，绝对路径、包含 .. 或隐藏文件（如 .git、__MACOSX）的路径返回 false
+func cleanIngestPath(name string) (string, bool) {
+	name = strings.ReplaceAll(name, "\\", "/")
+	if strings.HasPrefix(name, "/") {
+		return "", false
+	}
+	for _, part := range strings.Split(name, "/") {
+		if part == ".." || (strings.HasPrefix(part, ".") && part != ".") || part == "__MACOSX" {
+			return "", false
+		}
+	}
+	cleaned := path.Clean(name)
+	if cleaned == "." {
+		return "", false
+	}
+	return cleaned, true
+}
+
+// stripCommonRoot 所有文件都在同一个顶层目录下时去掉该目录（压缩包通常会多包一层）
+func stripCommonRoot(files []ingestFile) []ingestFile {
+	if len(files) == 0 {
+		return files
+	}
+	root := ""
+	for _, f := range files {
+		i := strings.Index(f.path, "/")
+		if i < 0 {
+			return files
+		}
+		if root == "" {
+			root = f.path[:i]
+		} else if f.path[:i] != root {
+			return files
+		}
+	}
+	for i := range files {
+		files[i].path = strings.TrimPrefix(files[i].path, root+"/")
+	}
+	return files
+}
+
+// filterIngestSubDir 只保留子目录中的文件，路径改为相对子目录
+func filterIngestSubDir(files []ingestFile, subDir string) []ingestFile {
+	if subDir == "" {
+		return files
+	}
+	var result []ingestFile
+	for _, f := range files {
+		if strings.HasPrefix(f.path, subDir+"/") {
+			f.path = strings.TrimPrefix(f.path, subDir+"/")
+			result = append(result, f)
+		}
+	}
+	return result
+}
+
+// archiveFormat 根据文件名判断压缩包格式
+func archiveFormat(fileName string) string {
+	name := strings.ToLower(fileName)
+	switch {
+	case strings.HasSuffix(name, ".zip"):
+		return "zip"
+	case strings.HasSuffix(name, ".tar.gz"), strings.HasSuffix(name, ".tgz"):
+		return "tar.gz"
+	case strings.HasSuffix(name, ".tar"):
+		return "tar"
+	}
+	return ""
+}
+
+// readIngestArchive 读取压缩包中的普通文件（跳过目录、符号链接和隐藏文件）
+func readIngestArchive(fileName string, data []byte) ([]ingestFile, error) {
+	var files []ingestFile
+	var total int64
+	add := func(name string, size int64, open func() (io.ReadCloser, error)) error {
+		cleaned, ok := cleanIngestPath(name)
+		if !ok {
+			return nil
+		}
+		if ingestFileType(cleaned) == "" {
+			files = append(files, ingestFile{path: cleaned})
+			return nil
+		}
+		if size > ingestMaxFileSize {
+			files = append(files, ingestFile{path: cleaned, err: fmt.Errorf("文件超过 %dMB", ingestMaxFileSize>>20)})
+			return nil
+		}
+		if total += size; total > ingestMaxTotalSize {
+			return fmt.Errorf("压缩包解压后超过 %dMB", ingestMaxTotalSize>>20)
+		}
+		rc, err := open()
+		if err != nil {
+			files = append(files, ingestFile{path: cleaned, err: fmt.Errorf("读取文件失败: %w", err)})
+			return nil
+		}
+		defer rc.Close()
+		content, err := io.ReadAll(io.LimitReader(rc, ingestMaxFileSize+1))
+		if err != nil {
+			files = append(files, ingestFile{path: cleaned, err: fmt.Errorf("读取文件失败: %w", err)})
+			return nil
+		}
+		if len(content) > ingestMaxFileSize {
+			files = append(files, ingestFile{path: cleaned, err: fmt.Errorf("文件超过 %dMB", ingestMaxFileSize>>20)})
+			return nil
+		}
+		files = append(files, ingestFile{path: cleaned, data: content})
+		return nil
+	}
+
+	switch archiveFormat(fileName) {
+	case "zip":
+		zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			return nil, fmt.Errorf("读取 zip 失败: %w", err)
+		}
+		for _, f := range zr.File {
+			if !f.Mode().IsRegular() {
+				continue
+			}
+			if err := add(f.Name, int64(f.UncompressedSize64), f.Open); err != nil {
+				return nil, err
+			}
+		}
+	case "tar", "tar.gz":
+		var r io.Reader = bytes.NewReader(data)
+		if archiveFormat(fileName) == "tar.gz" {
+			gz, err := gzip.NewReader(r)
+			if err != nil {
+				return nil, fmt.Errorf("读取 tar.gz 失败: %w", err)
+			}
+			defer gz.Close()
+			r = gz
+		}
+		tr := tar.NewReader(r)
+		for {
+			header, err := tr.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return nil, fmt.Errorf("读取 tar 失败: %w", err)
+			}
+			if header.Typeflag != tar.TypeReg {
+				continue
+			}
+			if err := add(header.Name, header.Size, func() (io.ReadCloser, error) { return io.NopCloser(tr), nil }); err != nil {
+				return nil, err
+			}
+		}
+	default:
+		return nil, fmt.Errorf("不支持的压缩包格式，仅支持 zip、tar、tar.gz")
+	}
+	return files, nil
+}
+
+// readIngestCommit 读取 git 提交中的全部文件（跳过隐藏文件和子模块）
+func readIngestCommit(commit *object.Commit) ([]ingestFile, error) {
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("读取 git 目录树失败: %w", err)
+	}
+	var files []ingestFile
+	var total int64
+	err = tree.Files().ForEach(func(f *object.File) error {
+		cleaned, ok := cleanIngestPath(f.Name)
+		if !ok || !f.Mode.IsFile() {
+			return nil
+		}
+		if ingestFileType(cleaned) == "" {
+			files = append(files, ingestFile{path: cleaned})
+			return nil
+		}
+		if f.Size > ingestMaxFileSize {
+			files = append(files, ingestFile{path: cleaned, err: fmt.Errorf("文件超过 %dMB", ingestMaxFileSize>>20)})
+			return nil
+		}
+		if total += f.Size; total > ingestMaxTotalSize {
+			return fmt.Errorf("仓库文件超过 %dMB", ingestMaxTotalSize>>20)
+		}
+		rc, err := f.Reader()
+		if err != nil {
+			files = append(files, ingestFile{path: cleaned, err: fmt.Errorf("读取文件失败: %w", err)})
+			return nil
+		}
+		defer rc.Close()
+		content, err := io.ReadAll(rc)
+		if err != nil {
+			files = append(files, ingestFile{path: cleaned, err: fmt.Errorf("读取文件失败: %w", err)})
+			return nil
+		}
+		files = append(files, ingestFile{path: cleaned, data: content})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return files, nil
+}
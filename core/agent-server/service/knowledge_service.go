@@ -7,6 +7,7 @@ import (
 	"github.com/ai-agent-os/ai-agent-os/core/agent-server/model"
 	"github.com/ai-agent-os/ai-agent-os/core/agent-server/repository"
 	"github.com/ai-agent-os/ai-agent-os/core/agent-server/utils"
+	"github.com/ai-agent-os/ai-agent-os/pkg/config"
 	"github.com/ai-agent-os/ai-agent-os/pkg/contextx"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
@@ -14,12 +15,14 @@ import (
 
 // KnowledgeService 知识库服务
 type KnowledgeService struct {
-	repo *repository.KnowledgeRepository
+	repo       *repository.KnowledgeRepository
+	ingestRepo *repository.KnowledgeIngestRepository
+	cfg        *config.AgentServerConfig
 }
 
 // NewKnowledgeService 创建知识库服务
-func NewKnowledgeService(repo *repository.KnowledgeRepository) *KnowledgeService {
-	return &KnowledgeService{repo: repo}
+func NewKnowledgeService(repo *repository.KnowledgeRepository, ingestRepo *repository.KnowledgeIngestRepository, cfg *config.AgentServerConfig) *KnowledgeService {
+	return &KnowledgeService{repo: repo, ingestRepo: ingestRepo, cfg: cfg}
 }
 
 // GetKnowledgeBase 获取知识库（根据 ID，保留兼容）
@@ -0,0 +1,305 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ai-agent-os/ai-agent-os/core/agent-server/model"
+	"github.com/ai-agent-os/ai-agent-os/core/agent-server/repository"
+	"github.com/ai-agent-os/ai-agent-os/dto"
+	"github.com/ai-agent-os/ai-agent-os/pkg/llms"
+	"github.com/ai-agent-os/ai-agent-os/pkg/logger"
+)
+
+const (
+	llmRoutingMaxFallbacks = 5
+	llmRoutingMaxRetries   = 3
+	llmRoutingMaxBackoffMs = 60 * 1000
+	llmDefaultTimeout      = 600 * time.Second
+)
+
+// LLMRouter LLM 路由服务
+// 所有调用共享同一个 llms.Router，每个 LLM 配置的熔断状态和延迟统计在所有智能体之间共享
+// 调用前检查 context 中调用归属的配额，调用成功后记录用量
+type LLMRouter struct {
+	llmRepo *repository.LLMRepository
+	usage   *LLMUsageService
+	router  *llms.Router
+}
+
+// NewLLMRouter 创建 LLM 路由服务
+func NewLLMRouter(llmRepo *repository.LLMRepository, usage *LLMUsageService) *LLMRouter {
+	return &LLMRouter{
+		llmRepo: llmRepo,
+		usage:   usage,
+		router:  llms.NewRouter(),
+	}
+}
+
+// llmRoute 一次 LLM 调用的候选配置和路由策略
+type llmRoute struct {
+	primary *model.LLMConfig
+	policy  *llms.RoutePolicy
+	targets []*llms.RouteTarget
+	configs map[string]*model.LLMConfig // RouteTarget.Key -> LLM 配置
+}
+
+// usageEstimate 按主 LLM 预估本次调用的用量（用于预占配额）
+func (r *llmRoute) usageEstimate() llmUsageEstimate {
+	if len(r.targets) == 0 {
+		return llmUsageEstimate{}
+	}
+	return estimateLLMUsage(r.configs[r.targets[0].Key], r.targets[0].Request)
+}
+
+// Timeout 整体超时：每个候选都可能用满自己的超时并重试
+func (r *llmRoute) Timeout() time.Duration {
+	var total time.Duration
+	for _, target := range r.targets {
+		timeout := time.Duration(r.configs[target.Key].Timeout) * time.Second
+		if timeout <= 0 {
+			timeout = llmDefaultTimeout
+		}
+		total += timeout * time.Duration(r.policy.MaxRetries+1)
+	}
+	return total
+}
+
+// Route 构建候选列表：主 LLM 在前，备用 LLM 按配置顺序在后（重复和不存在的配置会被忽略）
+func (r *LLMRouter) Route(ctx context.Context, primary *model.LLMConfig, routing *dto.AgentLLMRouting, messages []llms.Message, traceId string) (*llmRoute, error) {
+	route := &llmRoute{
+		primary: primary,
+		policy:  llmRoutePolicy(routing),
+		configs: make(map[string]*model.LLMConfig),
+	}
+
+	candidates := []*model.LLMConfig{primary}
+	if routing != nil {
+		seen := map[int64]bool{primary.ID: true}
+		for _, id := range routing.FallbackLLMConfigIDs {
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+			cfg, err := r.llmRepo.GetByID(id)
+			if err != nil {
+				logger.Warnf(ctx, "[LLMRouter] 备用LLM配置不可用，已忽略 - LLMConfigID: %d, TraceID: %s, Error: %v", id, traceId, err)
+				continue
+			}
+			candidates = append(candidates, cfg)
+		}
+	}
+
+	var lastErr error
+	for _, cfg := range candidates {
+		client, err := newLLMClient(cfg)
+		if err != nil {
+			logger.Warnf(ctx, "[LLMRouter] 创建LLM客户端失败，已忽略 - LLMConfigID: %d, Provider: %s, TraceID: %s, Error: %v",
+				cfg.ID, cfg.Provider, traceId, err)
+			lastErr = err
+			continue
+		}
+		key := llmRouteKey(cfg)
+		route.configs[key] = cfg
+		route.targets = append(route.targets, &llms.RouteTarget{
+			Key:     key,
+			Client:  client,
+			Request: buildLLMChatRequest(cfg, messages),
+			Cost:    llmRouteCost(cfg),
+		})
+	}
+	if len(route.targets) == 0 {
+		return nil, lastErr
+	}
+	return route, nil
+}
+
+// Chat 按路由策略调用，返回响应和实际提供服务的 LLM 配置
+func (r *LLMRouter) Chat(ctx context.Context, route *llmRoute, traceId string) (*llms.ChatResponse, *model.LLMConfig, error) {
+	release, err := r.usage.ReserveQuota(ctx, route.usageEstimate())
+	if err != nil {
+		return nil, nil, err
+	}
+	defer release()
+	resp, target, err := r.router.Chat(ctx, route.policy, route.targets)
+	if err != nil {
+		return nil, nil, fmt.Errorf("调用LLM失败: %w", err)
+	}
+	served := route.configs[target.Key]
+	r.logServed(ctx, route, served, traceId)
+	r.usage.Record(ctx, served, target.Request, resp.Usage, resp.Content)
+	return resp, served, nil
+}
+
+// ChatStream 按路由策略流式调用，返回流式响应通道和实际提供服务的 LLM 配置（调用方必须读完通道）
+func (r *LLMRouter) ChatStream(ctx context.Context, route *llmRoute, traceId string) (<-chan *llms.StreamChunk, *model.LLMConfig, error) {
+	release, err := r.usage.ReserveQuota(ctx, route.usageEstimate())
+	if err != nil {
+		return nil, nil, err
+	}
+	stream, target, err := r.router.ChatStream(ctx, route.policy, route.targets)
+	if err != nil {
+		release()
+		return nil, nil, fmt.Errorf("调用LLM失败: %w", err)
+	}
+	served := route.configs[target.Key]
+	r.logServed(ctx, route, served, traceId)
+
+	// 转发数据块，流结束后记录用量（中途失败时已输出的部分同样计入）
+	out := make(chan *llms.StreamChunk, cap(stream))
+	go func() {
+		defer close(out)
+		defer release()
+		var usage *llms.Usage
+		var content strings.Builder
+		for chunk := range stream {
+			if chunk.Usage != nil {
+				usage = chunk.Usage
+			}
+			content.WriteString(chunk.Thinking)
+			content.WriteString(chunk.Content)
+			out <- chunk
+		}
+		if usage != nil || content.Len() > 0 {
+			r.usage.Record(ctx, served, target.Request, usage, content.String())
+		}
+	}()
+	return out, served, nil
+}
+
+// logServed 实际调用的不是主 LLM 时记录日志，便于发现提供商故障
+func (r *LLMRouter) logServed(ctx context.Context, route *llmRoute, served *model.LLMConfig, traceId string) {
+	if served.ID == route.primary.ID {
+		return
+	}
+	logger.Warnf(ctx, "[LLMRouter] 未使用主LLM - PrimaryID: %d (%s), ServedID: %d, Provider: %s, Model: %s, PrimaryCircuit: %s, TraceID: %s",
+		route.primary.ID, route.primary.Provider, served.ID, served.Provider, served.Model,
+		r.router.CircuitState(llmRouteKey(route.primary)), traceId)
+}
+
+// llmRouteKey 路由目标标识（配置ID 加上提供商和模型，修改配置后重新统计）
+func llmRouteKey(cfg *model.LLMConfig) string {
+	return fmt.Sprintf("%d:%s/%s", cfg.ID, cfg.Provider, cfg.Model)
+}
+
+// llmRouteCost 路由成本（未设置成本时按输入、输出价格之和估算）
+func llmRouteCost(cfg *model.LLMConfig) float64 {
+	if cfg.Cost > 0 {
+		return cfg.Cost
+	}
+	return cfg.PromptPrice + cfg.CompletionPrice
+}
+
+// llmRoutePolicy 把智能体的路由配置转换为路由策略（未配置时不重试）
+func llmRoutePolicy(routing *dto.AgentLLMRouting) *llms.RoutePolicy {
+	if routing == nil {
+		return &llms.RoutePolicy{}
+	}
+	return &llms.RoutePolicy{
+		Strategy:   routing.Strategy,
+		RetryOn:    routing.RetryOn,
+		MaxRetries: routing.MaxRetries,
+		Backoff:    time.Duration(routing.BackoffMs) * time.Millisecond,
+	}
+}
+
+// buildLLMChatRequest 根据 LLM 配置构建聊天请求（extra_config 中的 max_tokens、temperature 优先）
+func buildLLMChatRequest(llmConfig *model.LLMConfig, messages []llms.Message) *llms.ChatRequest {
+	var extraConfig map[string]interface{}
+	if llmConfig.ExtraConfig != nil && *llmConfig.ExtraConfig != "" {
+		json.Unmarshal([]byte(*llmConfig.ExtraConfig), &extraConfig)
+	}
+
+	chatReq := &llms.ChatRequest{
+		Messages: messages,
+		Model:    llmConfig.Model,
+	}
+	if maxTokens, ok := extraConfig["max_tokens"].(float64); ok && maxTokens > 0 {
+		chatReq.MaxTokens = int(maxTokens)
+	} else if llmConfig.MaxTokens > 0 {
+		chatReq.MaxTokens = llmConfig.MaxTokens
+	}
+	if temperature, ok := extraConfig["temperature"].(float64); ok {
+		chatReq.Temperature = temperature
+	}
+	if llmConfig.UseThinking {
+		useThinking := true
+		chatReq.UseThinking = &useThinking
+	}
+	return chatReq
+}
+
+// parseAgentLLMRouting 解析智能体的 LLM 路由策略（未配置时返回 nil）
+func parseAgentLLMRouting(agent *model.Agent) (*dto.AgentLLMRouting, error) {
+	if agent.LLMRouting == nil || *agent.LLMRouting == "" || *agent.LLMRouting == "null" {
+		return nil, nil
+	}
+	var routing dto.AgentLLMRouting
+	if err := json.Unmarshal([]byte(*agent.LLMRouting), &routing); err != nil {
+		return nil, fmt.Errorf("解析LLM路由策略失败: %w", err)
+	}
+	return &routing, nil
+}
+
+// applyLLMRouting 校验并规范化智能体的 LLM 路由策略
+func (s *AgentService) applyLLMRouting(agent *model.Agent) error {
+	routing, err := parseAgentLLMRouting(agent)
+	if err != nil {
+		return err
+	}
+	if routing == nil {
+		agent.LLMRouting = nil
+		return nil
+	}
+
+	switch routing.Strategy {
+	case "":
+		routing.Strategy = dto.AgentLLMRoutingFailover
+	case dto.AgentLLMRoutingFailover, dto.AgentLLMRoutingLatency, dto.AgentLLMRoutingCost:
+	default:
+		return fmt.Errorf("LLM路由策略不支持: %s", routing.Strategy)
+	}
+
+	fallbacks := make([]int64, 0, len(routing.FallbackLLMConfigIDs))
+	seen := map[int64]bool{agent.LLMConfigID: true}
+	for _, id := range routing.FallbackLLMConfigIDs {
+		if id <= 0 || seen[id] {
+			continue
+		}
+		seen[id] = true
+		if _, err := s.llmRepo.GetByID(id); err != nil {
+			return fmt.Errorf("备用LLM配置不存在: LLMConfigID=%d", id)
+		}
+		fallbacks = append(fallbacks, id)
+	}
+	if len(fallbacks) > llmRoutingMaxFallbacks {
+		return fmt.Errorf("最多配置 %d 个备用LLM", llmRoutingMaxFallbacks)
+	}
+	routing.FallbackLLMConfigIDs = fallbacks
+
+	for _, class := range routing.RetryOn {
+		switch class {
+		case llms.ErrorClassTimeout, llms.ErrorClassRateLimit, llms.ErrorClassServer, llms.ErrorClassNetwork,
+			llms.ErrorClassAuth, llms.ErrorClassInvalid, llms.ErrorClassOther:
+		default:
+			return fmt.Errorf("重试的错误类型不支持: %s", class)
+		}
+	}
+	if routing.MaxRetries < 0 || routing.MaxRetries > llmRoutingMaxRetries {
+		return fmt.Errorf("LLM重试次数需要在 0-%d 之间", llmRoutingMaxRetries)
+	}
+	if routing.BackoffMs < 0 || routing.BackoffMs > llmRoutingMaxBackoffMs {
+		return fmt.Errorf("LLM重试等待时间需要在 0-%d 毫秒之间", llmRoutingMaxBackoffMs)
+	}
+
+	data, err := json.Marshal(routing)
+	if err != nil {
+		return fmt.Errorf("序列化LLM路由策略失败: %w", err)
+	}
+	result := string(data)
+	agent.LLMRouting = &result
+	return nil
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/ai-agent-os/ai-agent-os/core/agent-server/repository"
 	"github.com/ai-agent-os/ai-agent-os/core/agent-server/utils"
 	"github.com/ai-agent-os/ai-agent-os/pkg/contextx"
+	"github.com/ai-agent-os/ai-agent-os/pkg/llms"
 	"gorm.io/gorm"
 )
 
@@ -35,6 +36,16 @@ func normalizeExtraConfig(extraConfig string) (*string, error) {
 	return &result, nil
 }
 
+// validateLLMClientConfig OpenAI 兼容接口和 mock 的连接参数全部来自配置，保存前先确认能创建客户端
+func validateLLMClientConfig(cfg *model.LLMConfig) error {
+	switch llms.Provider(cfg.Provider) {
+	case llms.ProviderOpenAICompatible, llms.ProviderMock:
+		_, err := newLLMClient(cfg)
+		return err
+	}
+	return nil
+}
+
 // LLMService LLM 服务
 type LLMService struct {
 	repo *repository.LLMRepository
@@ -96,6 +107,9 @@ func (s *LLMService) CreateLLMConfig(ctx context.Context, cfg *model.LLMConfig)
 	if cfg.Model == "" {
 		return fmt.Errorf("模型名称不能为空")
 	}
+	if cfg.Cost < 0 || cfg.PromptPrice < 0 || cfg.CompletionPrice < 0 {
+		return fmt.Errorf("成本和价格不能为负数")
+	}
 
 	// 规范化 extra_config 字段
 	normalizedExtraConfig, err := normalizeExtraConfig(func() string {
@@ -108,6 +122,9 @@ func (s *LLMService) CreateLLMConfig(ctx context.Context, cfg *model.LLMConfig)
 		return err
 	}
 	cfg.ExtraConfig = normalizedExtraConfig
+	if err := validateLLMClientConfig(cfg); err != nil {
+		return err
+	}
 
 	// 设置默认管理员（如果为空，设置为创建用户）
 	if cfg.Admin == "" {
@@ -158,6 +175,9 @@ func (s *LLMService) UpdateLLMConfig(ctx context.Context, cfg *model.LLMConfig)
 	if cfg.Model == "" {
 		return fmt.Errorf("模型名称不能为空")
 	}
+	if cfg.Cost < 0 || cfg.PromptPrice < 0 || cfg.CompletionPrice < 0 {
+		return fmt.Errorf("成本和价格不能为负数")
+	}
 
 	// 规范化 extra_config 字段
 	normalizedExtraConfig, err := normalizeExtraConfig(func() string {
@@ -170,6 +190,9 @@ func (s *LLMService) UpdateLLMConfig(ctx context.Context, cfg *model.LLMConfig)
 		return err
 	}
 	cfg.ExtraConfig = normalizedExtraConfig
+	if err := validateLLMClientConfig(cfg); err != nil {
+		return err
+	}
 
 	// 如果设置为默认，先取消其他默认配置
 	if cfg.IsDefault {
@@ -0,0 +1,502 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ai-agent-os/ai-agent-os/core/agent-server/model"
+	"github.com/ai-agent-os/ai-agent-os/core/agent-server/repository"
+	"github.com/ai-agent-os/ai-agent-os/core/agent-server/utils"
+	"github.com/ai-agent-os/ai-agent-os/dto"
+	"github.com/ai-agent-os/ai-agent-os/pkg/apicall"
+	"github.com/ai-agent-os/ai-agent-os/pkg/config"
+	"github.com/ai-agent-os/ai-agent-os/pkg/contextx"
+	"github.com/ai-agent-os/ai-agent-os/pkg/llms"
+	"github.com/ai-agent-os/ai-agent-os/pkg/logger"
+	"gorm.io/gorm"
+)
+
+const (
+	llmUsageDepartmentTTL = 10 * time.Minute
+	llmUsageReportDays    = 30
+	llmUsageReportMaxDays = 366
+)
+
+// llmUsageScope 一次 LLM 调用的归属，通过 context 传给 LLMRouter 用于配额检查和用量记录
+type llmUsageScope struct {
+	User       string
+	Department string
+	AgentID    int64
+	Scene      string
+	RecordID   int64
+}
+
+type llmUsageScopeKey struct{}
+
+// withLLMUsageScope 把调用归属放入 context
+func withLLMUsageScope(ctx context.Context, scope llmUsageScope) context.Context {
+	return context.WithValue(ctx, llmUsageScopeKey{}, scope)
+}
+
+// llmUsageScopeFrom 从 context 中取出调用归属
+func llmUsageScopeFrom(ctx context.Context) (llmUsageScope, bool) {
+	scope, ok := ctx.Value(llmUsageScopeKey{}).(llmUsageScope)
+	return scope, ok
+}
+
+// withLLMUsageScene 修改 context 中调用归属的场景（没有调用归属时原样返回）
+func withLLMUsageScene(ctx context.Context, scene string) context.Context {
+	scope, ok := llmUsageScopeFrom(ctx)
+	if !ok {
+		return ctx
+	}
+	scope.Scene = scene
+	return withLLMUsageScope(ctx, scope)
+}
+
+// llmQuotaReserved 某个配额下进行中调用预占的用量
+type llmQuotaReserved struct {
+	tokens int64
+	cost   float64
+}
+
+// llmUsageEstimate 一次调用预估的用量（用于预占配额）
+type llmUsageEstimate struct {
+	tokens int64
+	cost   float64
+}
+
+type llmUsageDepartment struct {
+	path      string
+	expiresAt time.Time
+}
+
+// LLMUsageService LLM 用量、配额服务
+type LLMUsageService struct {
+	repo      *repository.LLMUsageRepository
+	agentRepo *repository.AgentRepository
+	cfg       *config.AgentServerConfig
+
+	mu          sync.Mutex
+	departments map[string]llmUsageDepartment // 用户名 -> 部门完整路径（缓存）
+
+	quotaMu  sync.Mutex                  // 串行化配额检查和预占，同一实例内的并发调用不会同时通过检查
+	reserved map[int64]*llmQuotaReserved // 配额 ID -> 进行中调用预占的用量
+}
+
+// NewLLMUsageService 创建 LLM 用量服务
+func NewLLMUsageService(repo *repository.LLMUsageRepository, agentRepo *repository.AgentRepository, cfg *config.AgentServerConfig) *LLMUsageService {
+	return &LLMUsageService{
+		repo:        repo,
+		agentRepo:   agentRepo,
+		cfg:         cfg,
+		departments: make(map[string]llmUsageDepartment),
+		reserved:    make(map[int64]*llmQuotaReserved),
+	}
+}
+
+// WithScope 为当前用户的调用设置归属（部门在调用时确定，之后调整部门不影响历史用量）
+func (s *LLMUsageService) WithScope(ctx context.Context, agentID int64, scene string) context.Context {
+	user := contextx.GetRequestUser(ctx)
+	return withLLMUsageScope(ctx, llmUsageScope{
+		User:       user,
+		Department: s.department(ctx, user),
+		AgentID:    agentID,
+		Scene:      scene,
+	})
+}
+
+// department 查询用户所在部门（查询失败时不计入部门，不影响调用）
+func (s *LLMUsageService) department(ctx context.Context, user string) string {
+	if user == "" {
+		return ""
+	}
+	s.mu.Lock()
+	cached, ok := s.departments[user]
+	s.mu.Unlock()
+	if ok && time.Now().Before(cached.expiresAt) {
+		return cached.path
+	}
+
+	header := &apicall.Header{
+		TraceID:     contextx.GetTraceId(ctx),
+		RequestUser: user,
+		Token:       contextx.GetToken(ctx),
+	}
+	if header.Token == "" {
+		return cached.path
+	}
+	info, err := apicall.GetUserByUsername(header, user)
+	if err != nil {
+		logger.Warnf(ctx, "[LLMUsageService] 查询用户部门失败 - User: %s, Error: %v", user, err)
+		return cached.path
+	}
+
+	s.mu.Lock()
+	s.departments[user] = llmUsageDepartment{path: info.DepartmentFullPath, expiresAt: time.Now().Add(llmUsageDepartmentTTL)}
+	s.mu.Unlock()
+	return info.DepartmentFullPath
+}
+
+// CheckQuota 检查调用归属的配额（用户、部门及上级部门、智能体），任一配额用完时拒绝调用
+// 只检查不预占，用于创建生成记录之前快速失败；实际调用 LLM 时由 ReserveQuota 预占
+func (s *LLMUsageService) CheckQuota(ctx context.Context) error {
+	_, err := s.ReserveQuota(ctx, llmUsageEstimate{})
+	return err
+}
+
+// ReserveQuota 检查配额并为本次调用预占预估用量，调用结束（记录实际用量之后）必须调用返回的 release 释放
+// 同一实例内检查和预占是串行的，已用量加上进行中调用的预占量达到上限时拒绝；
+// 多个 agent-server 实例之间不共享预占，超出量最多为每个实例一次调用的用量
+// 配额查询失败时按 agent.quota_fail_open 配置处理，默认拒绝调用
+func (s *LLMUsageService) ReserveQuota(ctx context.Context, estimate llmUsageEstimate) (release func(), err error) {
+	release = func() {}
+	scope, ok := llmUsageScopeFrom(ctx)
+	if !ok {
+		return release, nil
+	}
+
+	s.quotaMu.Lock()
+	defer s.quotaMu.Unlock()
+
+	quotas, err := s.repo.ListMatchingQuotas(scope.User, scope.Department, scope.AgentID)
+	if err != nil {
+		return release, s.quotaCheckFailed(ctx, scope, err)
+	}
+
+	now := time.Now()
+	var quotaIDs []int64
+	for _, quota := range quotas {
+		if quota.TokenLimit <= 0 && quota.CostLimit <= 0 {
+			continue
+		}
+		tokens, cost, err := s.repo.SumSince(quota.Scope, quota.Target, llmQuotaPeriodStart(quota.Period, now))
+		if err != nil {
+			return release, s.quotaCheckFailed(ctx, scope, err)
+		}
+		if reserved := s.reserved[quota.ID]; reserved != nil {
+			tokens += reserved.tokens
+			cost += reserved.cost
+		}
+		if quota.TokenLimit > 0 && tokens >= quota.TokenLimit {
+			return release, fmt.Errorf("%s的%s token 配额已用完（%d/%d）", llmQuotaTargetName(quota), llmQuotaPeriodName(quota.Period), tokens, quota.TokenLimit)
+		}
+		if quota.CostLimit > 0 && cost >= quota.CostLimit {
+			return release, fmt.Errorf("%s的%s费用配额已用完（%.2f/%.2f）", llmQuotaTargetName(quota), llmQuotaPeriodName(quota.Period), cost, quota.CostLimit)
+		}
+		quotaIDs = append(quotaIDs, quota.ID)
+	}
+	if len(quotaIDs) == 0 || (estimate.tokens <= 0 && estimate.cost <= 0) {
+		return release, nil
+	}
+
+	for _, id := range quotaIDs {
+		reserved := s.reserved[id]
+		if reserved == nil {
+			reserved = &llmQuotaReserved{}
+			s.reserved[id] = reserved
+		}
+		reserved.tokens += estimate.tokens
+		reserved.cost += estimate.cost
+	}
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			s.quotaMu.Lock()
+			defer s.quotaMu.Unlock()
+			for _, id := range quotaIDs {
+				reserved := s.reserved[id]
+				if reserved == nil {
+					continue
+				}
+				reserved.tokens -= estimate.tokens
+				reserved.cost -= estimate.cost
+				if reserved.tokens <= 0 && reserved.cost <= 0 {
+					delete(s.reserved, id)
+				}
+			}
+		})
+	}, nil
+}
+
+// quotaCheckFailed 配额查询失败：默认拒绝调用，配置了 quota_fail_open 时放行
+func (s *LLMUsageService) quotaCheckFailed(ctx context.Context, scope llmUsageScope, err error) error {
+	logger.Errorf(ctx, "[LLMUsageService] 查询配额失败 - User: %s, AgentID: %d, FailOpen: %v, Error: %v",
+		scope.User, scope.AgentID, s.cfg.IsQuotaFailOpen(), err)
+	if s.cfg.IsQuotaFailOpen() {
+		return nil
+	}
+	return fmt.Errorf("配额检查失败，请稍后重试")
+}
+
+// estimateLLMUsage 预估一次调用的用量：输入 token 加上最大输出 token，按 LLM 配置的价格计算费用
+func estimateLLMUsage(cfg *model.LLMConfig, req *llms.ChatRequest) llmUsageEstimate {
+	if cfg == nil || req == nil {
+		return llmUsageEstimate{}
+	}
+	provider := llms.Provider(cfg.Provider)
+	prompt := llms.CountMessagesTokens(provider, req.Messages)
+	completion := req.MaxTokens
+	return llmUsageEstimate{
+		tokens: int64(prompt + completion),
+		cost:   float64(prompt)/1000*cfg.PromptPrice + float64(completion)/1000*cfg.CompletionPrice,
+	}
+}
+
+// Record 记录一次调用的用量（提供商没有返回用量时按文本长度估算）
+func (s *LLMUsageService) Record(ctx context.Context, served *model.LLMConfig, req *llms.ChatRequest, usage *llms.Usage, completion string) {
+	scope, ok := llmUsageScopeFrom(ctx)
+	if !ok {
+		scope = llmUsageScope{User: contextx.GetRequestUser(ctx)}
+	}
+
+	record := &model.LLMUsage{
+		User:        scope.User,
+		Department:  scope.Department,
+		AgentID:     scope.AgentID,
+		LLMConfigID: served.ID,
+		Provider:    served.Provider,
+		Model:       served.Model,
+		Scene:       scope.Scene,
+		RecordID:    scope.RecordID,
+	}
+	if usage != nil && usage.PromptTokens+usage.CompletionTokens > 0 {
+		record.PromptTokens = usage.PromptTokens
+		record.CompletionTokens = usage.CompletionTokens
+	} else {
+		provider := llms.Provider(served.Provider)
+		record.PromptTokens = llms.CountMessagesTokens(provider, req.Messages)
+		record.CompletionTokens = llms.CountTokens(provider, completion)
+		record.Estimated = true
+	}
+	record.TotalTokens = record.PromptTokens + record.CompletionTokens
+	record.Cost = float64(record.PromptTokens)/1000*served.PromptPrice + float64(record.CompletionTokens)/1000*served.CompletionPrice
+	record.CreatedBy = scope.User
+
+	if err := s.repo.Create(record); err != nil {
+		logger.Errorf(ctx, "[LLMUsageService] 记录用量失败 - User: %s, LLMConfigID: %d, Tokens: %d, Error: %v",
+			scope.User, served.ID, record.TotalTokens, err)
+	}
+}
+
+// Report 用量报表（非用量管理员只能查看自己的用量）
+func (s *LLMUsageService) Report(ctx context.Context, req *dto.LLMUsageReportReq) (*dto.LLMUsageReportResp, error) {
+	filter := repository.LLMUsageReportFilter{
+		GroupBy:     req.GroupBy,
+		Interval:    req.Interval,
+		User:        req.User,
+		Department:  req.Department,
+		AgentID:     req.AgentID,
+		LLMConfigID: req.LLMConfigID,
+	}
+	if !s.isUsageAdmin(ctx) {
+		filter.User = contextx.GetRequestUser(ctx)
+	}
+
+	now := time.Now()
+	end := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	if req.EndDate != "" {
+		t, err := time.ParseInLocation("2006-01-02", req.EndDate, time.Local)
+		if err != nil {
+			return nil, fmt.Errorf("结束日期格式错误: %s", req.EndDate)
+		}
+		end = t
+	}
+	start := end.AddDate(0, 0, -(llmUsageReportDays - 1))
+	if req.StartDate != "" {
+		t, err := time.ParseInLocation("2006-01-02", req.StartDate, time.Local)
+		if err != nil {
+			return nil, fmt.Errorf("开始日期格式错误: %s", req.StartDate)
+		}
+		start = t
+	}
+	filter.Start, filter.End = start, end.AddDate(0, 0, 1)
+	if !filter.Start.Before(filter.End) {
+		return nil, fmt.Errorf("开始日期不能晚于结束日期")
+	}
+	if filter.End.Sub(filter.Start) > llmUsageReportMaxDays*24*time.Hour {
+		return nil, fmt.Errorf("查询时间范围不能超过 %d 天", llmUsageReportMaxDays)
+	}
+
+	rows, err := s.repo.Report(filter)
+	if err != nil {
+		return nil, fmt.Errorf("查询用量失败: %w", err)
+	}
+
+	resp := &dto.LLMUsageReportResp{Rows: rows, Total: dto.LLMUsageReportRow{Key: "total"}}
+	agentNames := make(map[string]string)
+	for i := range resp.Rows {
+		row := &resp.Rows[i]
+		resp.Total.Calls += row.Calls
+		resp.Total.PromptTokens += row.PromptTokens
+		resp.Total.CompletionTokens += row.CompletionTokens
+		resp.Total.TotalTokens += row.TotalTokens
+		resp.Total.Cost += row.Cost
+
+		if req.GroupBy != "agent" {
+			continue
+		}
+		name, ok := agentNames[row.Key]
+		if !ok {
+			if id, err := strconv.ParseInt(row.Key, 10, 64); err == nil && id > 0 {
+				if agent, err := s.agentRepo.GetByID(id); err == nil {
+					name = agent.Name
+				}
+			}
+			agentNames[row.Key] = name
+		}
+		row.Name = name
+	}
+	if resp.Rows == nil {
+		resp.Rows = []dto.LLMUsageReportRow{}
+	}
+	return resp, nil
+}
+
+// ListQuotas 获取配额列表
+func (s *LLMUsageService) ListQuotas(ctx context.Context, req *dto.LLMQuotaListReq) ([]*model.LLMQuota, int64, error) {
+	if err := s.checkUsageAdmin(ctx); err != nil {
+		return nil, 0, err
+	}
+	offset := (req.Page - 1) * req.PageSize
+	return s.repo.ListQuotas(req.Scope, req.Target, offset, req.PageSize)
+}
+
+// QuotaUsage 统计配额当前周期的用量，返回 token 数、费用和周期开始时间
+func (s *LLMUsageService) QuotaUsage(ctx context.Context, quota *model.LLMQuota) (int64, float64, time.Time, error) {
+	start := llmQuotaPeriodStart(quota.Period, time.Now())
+	tokens, cost, err := s.repo.SumSince(quota.Scope, quota.Target, start)
+	if err != nil {
+		return 0, 0, start, fmt.Errorf("统计用量失败: %w", err)
+	}
+	return tokens, cost, start, nil
+}
+
+// CreateQuota 创建配额
+func (s *LLMUsageService) CreateQuota(ctx context.Context, quota *model.LLMQuota) error {
+	if err := s.checkUsageAdmin(ctx); err != nil {
+		return err
+	}
+	switch quota.Scope {
+	case model.LLMQuotaScopeUser, model.LLMQuotaScopeDepartment:
+	case model.LLMQuotaScopeAgent:
+		id, err := strconv.ParseInt(quota.Target, 10, 64)
+		if err != nil {
+			return fmt.Errorf("智能体配额的对象必须是智能体ID")
+		}
+		if _, err := s.agentRepo.GetByID(id); err != nil {
+			return fmt.Errorf("智能体不存在: %s", quota.Target)
+		}
+	default:
+		return fmt.Errorf("配额范围不支持: %s", quota.Scope)
+	}
+	switch quota.Period {
+	case model.LLMQuotaPeriodDaily, model.LLMQuotaPeriodMonthly:
+	default:
+		return fmt.Errorf("配额周期不支持: %s", quota.Period)
+	}
+	if err := validateLLMQuotaLimit(quota); err != nil {
+		return err
+	}
+
+	if quotas, _, err := s.repo.ListQuotas(quota.Scope, quota.Target, 0, 10); err == nil {
+		for _, existing := range quotas {
+			if existing.Period == quota.Period {
+				return fmt.Errorf("该对象已存在%s配额", llmQuotaPeriodName(quota.Period))
+			}
+		}
+	}
+
+	quota.CreatedBy = contextx.GetRequestUser(ctx)
+	return s.repo.CreateQuota(quota)
+}
+
+// UpdateQuota 更新配额上限和启用状态
+func (s *LLMUsageService) UpdateQuota(ctx context.Context, req *dto.LLMQuotaUpdateReq) error {
+	if err := s.checkUsageAdmin(ctx); err != nil {
+		return err
+	}
+	quota, err := s.repo.GetQuotaByID(req.ID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("配额不存在")
+		}
+		return err
+	}
+	quota.TokenLimit = req.TokenLimit
+	quota.CostLimit = req.CostLimit
+	quota.Enabled = req.Enabled
+	quota.Remark = req.Remark
+	if err := validateLLMQuotaLimit(quota); err != nil {
+		return err
+	}
+	return s.repo.UpdateQuota(quota)
+}
+
+// DeleteQuota 删除配额
+func (s *LLMUsageService) DeleteQuota(ctx context.Context, id int64) error {
+	if err := s.checkUsageAdmin(ctx); err != nil {
+		return err
+	}
+	if _, err := s.repo.GetQuotaByID(id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("配额不存在")
+		}
+		return err
+	}
+	return s.repo.DeleteQuota(id)
+}
+
+// isUsageAdmin 当前用户是否为用量管理员
+func (s *LLMUsageService) isUsageAdmin(ctx context.Context) bool {
+	return utils.IsAdmin(s.cfg.GetUsageAdmins(), contextx.GetRequestUser(ctx))
+}
+
+// checkUsageAdmin 配额只能由用量管理员维护
+func (s *LLMUsageService) checkUsageAdmin(ctx context.Context) error {
+	if !s.isUsageAdmin(ctx) {
+		return fmt.Errorf("只有用量管理员可以管理配额")
+	}
+	return nil
+}
+
+// validateLLMQuotaLimit 校验配额上限
+func validateLLMQuotaLimit(quota *model.LLMQuota) error {
+	if quota.TokenLimit < 0 || quota.CostLimit < 0 {
+		return fmt.Errorf("配额上限不能为负数")
+	}
+	if quota.TokenLimit == 0 && quota.CostLimit == 0 {
+		return fmt.Errorf("token 上限和费用上限至少设置一个")
+	}
+	return nil
+}
+
+// llmQuotaPeriodStart 配额周期的开始时间（按天为当天 0 点，按月为当月 1 日 0 点）
+func llmQuotaPeriodStart(period string, now time.Time) time.Time {
+	if period == model.LLMQuotaPeriodMonthly {
+		return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	}
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+}
+
+func llmQuotaPeriodName(period string) string {
+	if period == model.LLMQuotaPeriodMonthly {
+		return "本月"
+	}
+	return "今日"
+}
+
+func llmQuotaTargetName(quota *model.LLMQuota) string {
+	switch quota.Scope {
+	case model.LLMQuotaScopeUser:
+		return "用户 " + quota.Target
+	case model.LLMQuotaScopeDepartment:
+		return "部门 " + quota.Target
+	default:
+		return "智能体 " + quota.Target
+	}
+}
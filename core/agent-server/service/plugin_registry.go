@@ -0,0 +1,244 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ai-agent-os/ai-agent-os/dto"
+	"github.com/ai-agent-os/ai-agent-os/pkg/logger"
+	"github.com/ai-agent-os/ai-agent-os/pkg/subjects"
+	"github.com/nats-io/nats.go"
+)
+
+const (
+	pluginDefaultHeartbeat = 10 * time.Second // 插件未上报心跳间隔时使用
+	pluginMissedHeartbeats = 3                // 连续丢失的心跳数达到该值即认为实例离线
+	pluginSweepInterval    = 5 * time.Second
+	pluginLatencyWeight    = 0.2 // 延迟指数滑动平均的权重
+)
+
+// pluginInstance 插件在线实例
+type pluginInstance struct {
+	presence dto.PluginPresence
+	onlineAt time.Time
+	lastSeen time.Time
+}
+
+// expired 超过 3 个心跳间隔未收到心跳
+func (i *pluginInstance) expired(now time.Time) bool {
+	interval := time.Duration(i.presence.Interval) * time.Second
+	if interval <= 0 {
+		interval = pluginDefaultHeartbeat
+	}
+	return now.Sub(i.lastSeen) > interval*pluginMissedHeartbeats
+}
+
+// pluginCallStats 插件调用统计
+type pluginCallStats struct {
+	avgLatency  time.Duration
+	lastLatency time.Duration
+	lastCallAt  time.Time
+	lastError   string
+}
+
+// PluginRegistry 插件在线注册表
+// 插件实例启动时上线、定期心跳、关闭时下线（见 sdk/agent-plugin），这里按 Plugin.Subject 维护在线实例，
+// 同时记录调用延迟。状态只保存在内存中，agent-server 启动时会广播发现请求，让在线插件立即上报
+type PluginRegistry struct {
+	natsConn *nats.Conn
+	subs     []*nats.Subscription
+
+	mu        sync.RWMutex
+	instances map[string]map[string]*pluginInstance // subject -> instanceID -> 实例
+	stats     map[string]*pluginCallStats           // subject -> 调用统计
+
+	stopCh    chan struct{}
+	closeOnce sync.Once
+}
+
+// NewPluginRegistry 创建插件在线注册表
+func NewPluginRegistry(natsConn *nats.Conn) (*PluginRegistry, error) {
+	r := &PluginRegistry{
+		natsConn:  natsConn,
+		instances: make(map[string]map[string]*pluginInstance),
+		stats:     make(map[string]*pluginCallStats),
+		stopCh:    make(chan struct{}),
+	}
+
+	// 每个 agent-server 实例都需要完整的在线信息，所以不使用队列组
+	sub, err := natsConn.Subscribe(subjects.GetAgentServerPluginPresenceSubject(), r.handlePresence)
+	if err != nil {
+		return nil, fmt.Errorf("订阅插件状态主题失败: %w", err)
+	}
+	r.subs = append(r.subs, sub)
+
+	if err := natsConn.Publish(subjects.GetAgentServerPluginDiscoverSubject(), nil); err != nil {
+		logger.Warnf(context.Background(), "[PluginRegistry] 广播插件发现请求失败: %v", err)
+	}
+
+	go r.sweep()
+	return r, nil
+}
+
+// handlePresence 处理插件上线/心跳/下线
+func (r *PluginRegistry) handlePresence(msg *nats.Msg) {
+	var presence dto.PluginPresence
+	if err := json.Unmarshal(msg.Data, &presence); err != nil {
+		logger.Warnf(context.Background(), "[PluginRegistry] 解析插件状态失败: %v", err)
+		return
+	}
+	if presence.Subject == "" || presence.InstanceID == "" {
+		return
+	}
+
+	now := time.Now()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	instances := r.instances[presence.Subject]
+	if presence.Type == dto.PluginPresenceOffline {
+		if instances != nil {
+			delete(instances, presence.InstanceID)
+			if len(instances) == 0 {
+				delete(r.instances, presence.Subject)
+			}
+		}
+		logger.Infof(context.Background(), "[PluginRegistry] 插件实例下线 - Subject: %s, InstanceID: %s", presence.Subject, presence.InstanceID)
+		return
+	}
+
+	if instances == nil {
+		instances = make(map[string]*pluginInstance)
+		r.instances[presence.Subject] = instances
+	}
+	instance, ok := instances[presence.InstanceID]
+	if !ok {
+		instance = &pluginInstance{onlineAt: now}
+		instances[presence.InstanceID] = instance
+		logger.Infof(context.Background(), "[PluginRegistry] 插件实例上线 - Subject: %s, InstanceID: %s, Version: %s, Host: %s",
+			presence.Subject, presence.InstanceID, presence.Version, presence.Hostname)
+	}
+	instance.presence = presence
+	instance.lastSeen = now
+}
+
+// sweep 定期清理心跳超时的实例
+func (r *PluginRegistry) sweep() {
+	ticker := time.NewTicker(pluginSweepInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.stopCh:
+			return
+		case now := <-ticker.C:
+			r.mu.Lock()
+			for subject, instances := range r.instances {
+				for id, instance := range instances {
+					if instance.expired(now) {
+						delete(instances, id)
+						logger.Warnf(context.Background(), "[PluginRegistry] 插件实例心跳超时，已离线 - Subject: %s, InstanceID: %s, LastSeen: %s",
+							subject, id, instance.lastSeen.Format(time.DateTime))
+					}
+				}
+				if len(instances) == 0 {
+					delete(r.instances, subject)
+				}
+			}
+			r.mu.Unlock()
+		}
+	}
+}
+
+// IsOnline 插件是否至少有一个在线实例
+func (r *PluginRegistry) IsOnline(subject string) bool {
+	now := time.Now()
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, instance := range r.instances[subject] {
+		if !instance.expired(now) {
+			return true
+		}
+	}
+	return false
+}
+
+// RecordCall 记录一次插件调用的延迟和结果
+func (r *PluginRegistry) RecordCall(subject string, latency time.Duration, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stats := r.stats[subject]
+	if stats == nil {
+		stats = &pluginCallStats{avgLatency: latency}
+		r.stats[subject] = stats
+	}
+	stats.avgLatency = time.Duration(float64(stats.avgLatency)*(1-pluginLatencyWeight) + float64(latency)*pluginLatencyWeight)
+	stats.lastLatency = latency
+	stats.lastCallAt = time.Now()
+	stats.lastError = ""
+	if err != nil {
+		stats.lastError = err.Error()
+	}
+}
+
+// Status 获取插件运行状态
+func (r *PluginRegistry) Status(subject string) *dto.PluginRuntimeStatus {
+	now := time.Now()
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	status := &dto.PluginRuntimeStatus{Instances: []dto.PluginInstanceInfo{}}
+	var latest *pluginInstance
+	for _, instance := range r.instances[subject] {
+		if instance.expired(now) {
+			continue
+		}
+		p := instance.presence
+		status.Instances = append(status.Instances, dto.PluginInstanceInfo{
+			InstanceID:      p.InstanceID,
+			Hostname:        p.Hostname,
+			Version:         p.Version,
+			Capabilities:    p.Capabilities,
+			Concurrency:     p.Concurrency,
+			Inflight:        p.Inflight,
+			OnlineAt:        instance.onlineAt.Format(time.DateTime),
+			LastHeartbeatAt: instance.lastSeen.Format(time.DateTime),
+		})
+		status.Concurrency += p.Concurrency
+		if latest == nil || instance.onlineAt.After(latest.onlineAt) {
+			latest = instance
+		}
+	}
+	sort.Slice(status.Instances, func(i, j int) bool {
+		return status.Instances[i].OnlineAt < status.Instances[j].OnlineAt
+	})
+	status.InstanceCount = len(status.Instances)
+	status.Online = status.InstanceCount > 0
+	if latest != nil {
+		status.Version = latest.presence.Version
+		status.Capabilities = latest.presence.Capabilities
+		status.InputSchema = latest.presence.InputSchema
+		status.OutputSchema = latest.presence.OutputSchema
+	}
+
+	if stats := r.stats[subject]; stats != nil {
+		status.AvgLatencyMs = stats.avgLatency.Milliseconds()
+		status.LastLatencyMs = stats.lastLatency.Milliseconds()
+		status.LastCallAt = stats.lastCallAt.Format(time.DateTime)
+		status.LastError = stats.lastError
+	}
+	return status
+}
+
+// Close 取消订阅并停止清理
+func (r *PluginRegistry) Close() {
+	r.closeOnce.Do(func() {
+		close(r.stopCh)
+		for _, sub := range r.subs {
+			_ = sub.Unsubscribe()
+		}
+	})
+}
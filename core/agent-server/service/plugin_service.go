@@ -7,6 +7,7 @@ import (
 	"github.com/ai-agent-os/ai-agent-os/core/agent-server/model"
 	"github.com/ai-agent-os/ai-agent-os/core/agent-server/repository"
 	"github.com/ai-agent-os/ai-agent-os/core/agent-server/utils"
+	"github.com/ai-agent-os/ai-agent-os/dto"
 	"github.com/ai-agent-os/ai-agent-os/pkg/contextx"
 	"github.com/ai-agent-os/ai-agent-os/pkg/subjects"
 	"gorm.io/gorm"
@@ -14,13 +15,15 @@ import (
 
 // PluginService 插件服务
 type PluginService struct {
-	repo *repository.PluginRepository
+	repo     *repository.PluginRepository
+	registry *PluginRegistry
 }
 
 // NewPluginService 创建插件服务
-func NewPluginService(repo *repository.PluginRepository) *PluginService {
+func NewPluginService(repo *repository.PluginRepository, registry *PluginRegistry) *PluginService {
 	return &PluginService{
-		repo: repo,
+		repo:     repo,
+		registry: registry,
 	}
 }
 
@@ -203,3 +206,8 @@ func (s *PluginService) DisablePlugin(ctx context.Context, id int64) error {
 
 	return s.repo.Disable(id)
 }
+
+// GetRuntimeStatus 获取插件运行状态（在线实例、调用延迟）
+func (s *PluginService) GetRuntimeStatus(subject string) *dto.PluginRuntimeStatus {
+	return s.registry.Status(subject)
+}
@@ -258,6 +258,10 @@ func (s *Server) createProxy(targetURL string, timeout int, route *config.RouteC
 		}
 	}
 
+	// SSE 响应（Content-Type: text/event-stream）需要逐条转发：
+	// httputil.ReverseProxy 对 text/event-stream 会在每次写入后立即刷新（FlushInterval 不生效），
+	// 所以这里只需要去掉超时（见下方 handler）并告诉下游代理不要缓冲（见 ModifyResponse）
+
 	// 移除后端服务设置的 CORS 头，避免与网关的 CORS 中间件重复
 	// 网关的 CORS 中间件会统一处理所有响应
 	proxy.ModifyResponse = func(resp *http.Response) error {
@@ -277,6 +281,12 @@ func (s *Server) createProxy(targetURL string, timeout int, route *config.RouteC
 			return nil
 		}
 
+		// SSE 响应禁止下游代理缓冲
+		if strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") {
+			resp.Header.Set("X-Accel-Buffering", "no")
+			resp.Header.Set("Cache-Control", "no-cache")
+		}
+
 		// 移除后端服务设置的 CORS 头，避免重复
 		resp.Header.Del("Access-Control-Allow-Origin")
 		resp.Header.Del("Access-Control-Allow-Methods")
@@ -304,6 +314,12 @@ func (s *Server) createProxy(targetURL string, timeout int, route *config.RouteC
 			c.Request.Header.Set(contextx.TraceIdHeader, traceId)
 		}
 
+		// SSE 长连接不设置超时（由客户端或后端结束），其余请求按路由超时
+		if isEventStreamRequest(c.Request) {
+			proxy.ServeHTTP(c.Writer, c.Request)
+			return
+		}
+
 		// ✅ 创建带超时的 Context，避免高并发时请求堆积
 		ctx, cancel := context.WithTimeout(c.Request.Context(), time.Duration(timeout)*time.Second)
 		defer cancel()
@@ -314,6 +330,11 @@ func (s *Server) createProxy(targetURL string, timeout int, route *config.RouteC
 	}
 }
 
+// isEventStreamRequest 是否是 SSE 请求（EventSource 会带上 Accept: text/event-stream）
+func isEventStreamRequest(req *http.Request) bool {
+	return strings.Contains(req.Header.Get("Accept"), "text/event-stream")
+}
+
 // createLoadBalanceProxy 创建负载均衡代理
 // 当前实现：使用第一个目标（负载均衡功能待实现）
 // 未来实现：
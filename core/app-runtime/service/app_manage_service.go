@@ -20,6 +20,7 @@ import (
 	"github.com/ai-agent-os/ai-agent-os/pkg/contextx"
 	"github.com/ai-agent-os/ai-agent-os/pkg/gitx"
 	"github.com/ai-agent-os/ai-agent-os/pkg/logger"
+	"github.com/ai-agent-os/ai-agent-os/pkg/msgx"
 	"github.com/ai-agent-os/ai-agent-os/pkg/subjects"
 	"github.com/nats-io/nats.go"
 )
@@ -1102,6 +1103,15 @@ func (s *AppManageService) startAppContainer(ctx context.Context, containerName,
 		logger.Infof(ctx, "[startAppContainer] Injecting %s=%s into container (SDK config)", key, value)
 	}
 
+	// 注入应用密钥（从 app-server 获取明文，作为 SECRET_{NAME} 环境变量注入，日志中不输出密钥值）
+	// 获取失败不阻塞启动，SDK 运行时会通过 ctx.Secret 再次向 app-server 获取
+	// 同时注入应用令牌，SDK 凭令牌只能读取本应用的密钥
+	if user, app, _, err := parseContainerName(containerName); err == nil {
+		secretEnvVars := s.loadAppSecretEnvVars(ctx, user, app)
+		envVars = append(envVars, secretEnvVars...)
+		envVars = append(envVars, fmt.Sprintf("%s=%s", appPkg.SecretTokenEnv, buildAppSecretToken(user, app, appSecretContainerTokenTTL)))
+	}
+
 	// 注入版本信息到环境变量（新架构：每个容器对应特定版本）
 	// 这样启动脚本可以通过环境变量读取版本，而不依赖可能被更新的文件
 	envVars = append(envVars, fmt.Sprintf("APP_VERSION=%s", version))
@@ -1119,6 +1129,41 @@ func (s *AppManageService) startAppContainer(ctx context.Context, containerName,
 	return nil
 }
 
+// loadAppSecretEnvVars 从 app-server 获取应用密钥，转换为容器环境变量（SECRET_{NAME}=value）
+func (s *AppManageService) loadAppSecretEnvVars(ctx context.Context, user, app string) []string {
+	if s.natsConn == nil {
+		return nil
+	}
+
+	var resp sharedDto.GetAppSecretsRuntimeResp
+	req := &sharedDto.GetAppSecretsRuntimeReq{User: user, App: app, Token: buildAppSecretToken(user, app, appSecretRequestTokenTTL)}
+	_, err := msgx.RequestMsgWithTimeout(ctx, s.natsConn, subjects.GetAppServerSecretRequestSubject(), req, &resp, 5*time.Second)
+	if err != nil {
+		logger.Warnf(ctx, "[startAppContainer] Failed to load app secrets for %s/%s: %v", user, app, err)
+		return nil
+	}
+
+	envVars := make([]string, 0, len(resp.Secrets))
+	for name, value := range resp.Secrets {
+		envVars = append(envVars, fmt.Sprintf("%s%s=%s", appPkg.SecretEnvPrefix, name, value))
+		logger.Infof(ctx, "[startAppContainer] Injecting %s%s=%s into container (app secret)", appPkg.SecretEnvPrefix, name, logger.RedactedPlaceholder)
+	}
+	return envVars
+}
+
+const (
+	// appSecretContainerTokenTTL 注入容器的令牌有效期，每次启动容器重新签发；
+	// 过期后 SDK 读取密钥失败会回退到启动时注入的 SECRET_* 环境变量
+	appSecretContainerTokenTTL = 30 * 24 * time.Hour
+	// appSecretRequestTokenTTL app-runtime 自己请求密钥时使用的令牌有效期
+	appSecretRequestTokenTTL = time.Minute
+)
+
+// buildAppSecretToken 签发应用密钥访问令牌（使用与 app-server 共享的全局 JWT Secret）
+func buildAppSecretToken(user, app string, ttl time.Duration) string {
+	return appPkg.BuildSecretToken([]byte(appconfig.GetGlobalSharedConfig().JWT.Secret), user, app, time.Now().Add(ttl))
+}
+
 // stopOldVersionContainer 优雅关闭旧版本容器（三次握手流程）
 // 这是新架构的核心方法：优雅关闭旧版本容器
 func (s *AppManageService) stopOldVersionContainer(ctx context.Context, user, app, oldVersion string) error {
@@ -0,0 +1,128 @@
+package v1
+
+import (
+	"github.com/ai-agent-os/ai-agent-os/core/app-server/service"
+	"github.com/ai-agent-os/ai-agent-os/dto"
+	"github.com/ai-agent-os/ai-agent-os/pkg/contextx"
+	"github.com/ai-agent-os/ai-agent-os/pkg/ginx/response"
+	"github.com/ai-agent-os/ai-agent-os/pkg/logger"
+	"github.com/gin-gonic/gin"
+)
+
+// AppSecret 应用密钥相关API
+type AppSecret struct {
+	appSecretService *service.AppSecretService
+}
+
+// NewAppSecret 创建应用密钥API（依赖注入）
+func NewAppSecret(appSecretService *service.AppSecretService) *AppSecret {
+	return &AppSecret{
+		appSecretService: appSecretService,
+	}
+}
+
+// ListSecrets 获取应用密钥列表
+// @Summary 获取应用密钥列表
+// @Description 获取应用的密钥列表，只返回元数据，密钥值固定返回 ******
+// @Tags 应用密钥
+// @Accept json
+// @Produce json
+// @Param X-Token header string true "JWT Token"
+// @Param app path string true "应用名"
+// @Success 200 {object} dto.AppSecretListResp "获取成功"
+// @Failure 400 {string} string "请求参数错误"
+// @Failure 401 {string} string "未授权"
+// @Failure 403 {string} string "无权限"
+// @Router /api/v1/app/secret/list/{app} [get]
+func (a *AppSecret) ListSecrets(c *gin.Context) {
+	var resp *dto.AppSecretListResp
+	var err error
+	app := c.Param("app")
+	defer func() {
+		if err != nil {
+			logger.Errorf(c, "ListSecrets app:%s err:%v", app, err)
+		}
+	}()
+
+	user := contextx.GetRequestUser(c)
+	ctx := contextx.ToContext(c)
+	resp, err = a.appSecretService.ListSecrets(ctx, user, app)
+	if err != nil {
+		response.FailWithMessage(c, err.Error())
+		return
+	}
+	response.OkWithData(c, resp)
+}
+
+// SetSecret 设置应用密钥
+// @Summary 设置应用密钥
+// @Description 创建或轮换应用密钥，密钥加密存储；轮换后运行中的应用无需重新编译即可读取新值
+// @Tags 应用密钥
+// @Accept json
+// @Produce json
+// @Param X-Token header string true "JWT Token"
+// @Param app path string true "应用名"
+// @Param request body dto.SetAppSecretReq true "设置密钥请求"
+// @Success 200 {string} string "设置成功"
+// @Failure 400 {string} string "请求参数错误"
+// @Failure 401 {string} string "未授权"
+// @Failure 403 {string} string "无权限"
+// @Router /api/v1/app/secret/set/{app} [post]
+func (a *AppSecret) SetSecret(c *gin.Context) {
+	var req dto.SetAppSecretReq
+	var err error
+	app := c.Param("app")
+	defer func() {
+		// ⭐ 只记录密钥名称，不记录密钥值
+		logger.Infof(c, "SetSecret app:%s name:%s err:%v", app, req.Name, err)
+	}()
+
+	if err = c.ShouldBindJSON(&req); err != nil {
+		response.FailWithMessage(c, "参数绑定失败: "+err.Error())
+		return
+	}
+
+	user := contextx.GetRequestUser(c)
+	ctx := contextx.ToContext(c)
+	if err = a.appSecretService.SetSecret(ctx, user, app, &req); err != nil {
+		response.FailWithMessage(c, err.Error())
+		return
+	}
+	response.OkWithMessage(c, "设置成功")
+}
+
+// DeleteSecret 删除应用密钥
+// @Summary 删除应用密钥
+// @Description 删除应用密钥，运行中的应用会收到变更通知
+// @Tags 应用密钥
+// @Accept json
+// @Produce json
+// @Param X-Token header string true "JWT Token"
+// @Param app path string true "应用名"
+// @Param name query string true "密钥名称"
+// @Success 200 {string} string "删除成功"
+// @Failure 400 {string} string "请求参数错误"
+// @Failure 401 {string} string "未授权"
+// @Failure 403 {string} string "无权限"
+// @Router /api/v1/app/secret/delete/{app} [delete]
+func (a *AppSecret) DeleteSecret(c *gin.Context) {
+	var req dto.DeleteAppSecretReq
+	var err error
+	app := c.Param("app")
+	defer func() {
+		logger.Infof(c, "DeleteSecret app:%s name:%s err:%v", app, req.Name, err)
+	}()
+
+	if err = c.ShouldBindQuery(&req); err != nil {
+		response.FailWithMessage(c, "参数绑定失败: "+err.Error())
+		return
+	}
+
+	user := contextx.GetRequestUser(c)
+	ctx := contextx.ToContext(c)
+	if err = a.appSecretService.DeleteSecret(ctx, user, app, &req); err != nil {
+		response.FailWithMessage(c, err.Error())
+		return
+	}
+	response.OkWithMessage(c, "删除成功")
+}
@@ -0,0 +1,243 @@
+package v1
+
+import (
+	"strconv"
+
+	"github.com/ai-agent-os/ai-agent-os/core/app-server/service"
+	"github.com/ai-agent-os/ai-agent-os/dto"
+	"github.com/ai-agent-os/ai-agent-os/pkg/contextx"
+	"github.com/ai-agent-os/ai-agent-os/pkg/ginx/response"
+	"github.com/ai-agent-os/ai-agent-os/pkg/logger"
+	"github.com/gin-gonic/gin"
+)
+
+// Approval 审批流程相关API
+type Approval struct {
+	approvalService *service.ApprovalService
+}
+
+// NewApproval 创建审批流程API（依赖注入）
+func NewApproval(approvalService *service.ApprovalService) *Approval {
+	return &Approval{
+		approvalService: approvalService,
+	}
+}
+
+// GetTasks 获取我的审批任务
+// @Summary 获取我的审批任务
+// @Description 获取当前用户的待办（done=false）或已办（done=true）审批任务
+// @Tags 审批流程
+// @Accept json
+// @Produce json
+// @Param X-Token header string true "JWT Token"
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页数量" default(20)
+// @Param done query bool false "是否已办"
+// @Success 200 {object} dto.GetApprovalTasksResp "获取成功"
+// @Failure 400 {string} string "请求参数错误"
+// @Failure 401 {string} string "未授权"
+// @Router /api/v1/approval/tasks [get]
+func (a *Approval) GetTasks(c *gin.Context) {
+	var req dto.GetApprovalTasksReq
+	var resp *dto.GetApprovalTasksResp
+	var err error
+	defer func() {
+		if err != nil {
+			logger.Errorf(c, "GetApprovalTasks req:%+v err:%v", req, err)
+		}
+	}()
+
+	if err = c.ShouldBindQuery(&req); err != nil {
+		response.FailWithMessage(c, "参数绑定失败: "+err.Error())
+		return
+	}
+
+	ctx := contextx.ToContext(c)
+	resp, err = a.approvalService.GetTasks(ctx, &req)
+	if err != nil {
+		response.FailWithMessage(c, err.Error())
+		return
+	}
+	response.OkWithData(c, resp)
+}
+
+// GetInitiated 获取我发起的审批
+// @Summary 获取我发起的审批
+// @Description 获取当前用户发起的审批（包含各节点的审批记录）
+// @Tags 审批流程
+// @Accept json
+// @Produce json
+// @Param X-Token header string true "JWT Token"
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页数量" default(20)
+// @Param status query string false "审批状态（pending/approved/rejected/canceled）"
+// @Success 200 {object} dto.ApprovalInstanceListResp "获取成功"
+// @Failure 400 {string} string "请求参数错误"
+// @Failure 401 {string} string "未授权"
+// @Router /api/v1/approval/initiated [get]
+func (a *Approval) GetInitiated(c *gin.Context) {
+	var req dto.GetInitiatedApprovalsReq
+	var resp *dto.ApprovalInstanceListResp
+	var err error
+	defer func() {
+		if err != nil {
+			logger.Errorf(c, "GetInitiatedApprovals req:%+v err:%v", req, err)
+		}
+	}()
+
+	if err = c.ShouldBindQuery(&req); err != nil {
+		response.FailWithMessage(c, "参数绑定失败: "+err.Error())
+		return
+	}
+
+	ctx := contextx.ToContext(c)
+	resp, err = a.approvalService.GetInitiated(ctx, &req)
+	if err != nil {
+		response.FailWithMessage(c, err.Error())
+		return
+	}
+	response.OkWithData(c, resp)
+}
+
+// GetInstance 获取审批详情
+// @Summary 获取审批详情
+// @Description 获取审批详情和审批记录（仅发起人和审批人可以查看）
+// @Tags 审批流程
+// @Accept json
+// @Produce json
+// @Param X-Token header string true "JWT Token"
+// @Param id path int true "审批实例ID"
+// @Success 200 {object} dto.ApprovalInstanceInfo "获取成功"
+// @Failure 400 {string} string "请求参数错误"
+// @Failure 401 {string} string "未授权"
+// @Router /api/v1/approval/instance/{id} [get]
+func (a *Approval) GetInstance(c *gin.Context) {
+	var resp *dto.ApprovalInstanceInfo
+	var err error
+	defer func() {
+		if err != nil {
+			logger.Errorf(c, "GetApprovalInstance id:%s err:%v", c.Param("id"), err)
+		}
+	}()
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.FailWithMessage(c, "无效的审批ID")
+		return
+	}
+
+	ctx := contextx.ToContext(c)
+	resp, err = a.approvalService.GetInstance(ctx, id)
+	if err != nil {
+		response.FailWithMessage(c, err.Error())
+		return
+	}
+	response.OkWithData(c, resp)
+}
+
+// GetRowApprovals 获取记录的审批历史
+// @Summary 获取记录的审批历史
+// @Description 获取表格某条记录的所有审批及审批记录（需要函数查看权限）
+// @Tags 审批流程
+// @Accept json
+// @Produce json
+// @Param X-Token header string true "JWT Token"
+// @Param full-code-path path string true "函数完整路径"
+// @Param row_id query int true "记录ID"
+// @Success 200 {array} dto.ApprovalInstanceInfo "获取成功"
+// @Failure 400 {string} string "请求参数错误"
+// @Failure 401 {string} string "未授权"
+// @Failure 403 {string} string "无权限"
+// @Router /api/v1/approval/row/{full-code-path} [get]
+func (a *Approval) GetRowApprovals(c *gin.Context) {
+	var req dto.GetRowApprovalsReq
+	var resp []dto.ApprovalInstanceInfo
+	var err error
+	fullCodePath := c.Param("full-code-path")
+	defer func() {
+		if err != nil {
+			logger.Errorf(c, "GetRowApprovals path:%s req:%+v err:%v", fullCodePath, req, err)
+		}
+	}()
+
+	if err = c.ShouldBindQuery(&req); err != nil {
+		response.FailWithMessage(c, "参数绑定失败: "+err.Error())
+		return
+	}
+
+	ctx := contextx.ToContext(c)
+	resp, err = a.approvalService.GetRowApprovals(ctx, fullCodePath, req.RowID)
+	if err != nil {
+		response.FailWithMessage(c, err.Error())
+		return
+	}
+	response.OkWithData(c, resp)
+}
+
+// Act 处理审批任务
+// @Summary 处理审批任务
+// @Description 通过（approve）、拒绝（reject）或转交（transfer）审批任务，只有任务的审批人可以处理
+// @Tags 审批流程
+// @Accept json
+// @Produce json
+// @Param X-Token header string true "JWT Token"
+// @Param request body dto.ApprovalActionReq true "审批操作"
+// @Success 200 {string} string "处理成功"
+// @Failure 400 {string} string "请求参数错误"
+// @Failure 401 {string} string "未授权"
+// @Router /api/v1/approval/act [post]
+func (a *Approval) Act(c *gin.Context) {
+	var req dto.ApprovalActionReq
+	var err error
+	defer func() {
+		if err != nil {
+			logger.Errorf(c, "ApprovalAct req:%+v err:%v", req, err)
+		}
+	}()
+
+	if err = c.ShouldBindJSON(&req); err != nil {
+		response.FailWithMessage(c, "参数绑定失败: "+err.Error())
+		return
+	}
+
+	ctx := contextx.ToContext(c)
+	if err = a.approvalService.Act(ctx, &req); err != nil {
+		response.FailWithMessage(c, err.Error())
+		return
+	}
+	response.OkWithMessage(c, "处理成功")
+}
+
+// Cancel 撤回审批
+// @Summary 撤回审批
+// @Description 发起人撤回审批中的审批，撤回后会触发应用的 OnApprovalFinished 回调（status=canceled）
+// @Tags 审批流程
+// @Accept json
+// @Produce json
+// @Param X-Token header string true "JWT Token"
+// @Param request body dto.CancelApprovalReq true "撤回请求"
+// @Success 200 {string} string "撤回成功"
+// @Failure 400 {string} string "请求参数错误"
+// @Failure 401 {string} string "未授权"
+// @Router /api/v1/approval/cancel [post]
+func (a *Approval) Cancel(c *gin.Context) {
+	var req dto.CancelApprovalReq
+	var err error
+	defer func() {
+		if err != nil {
+			logger.Errorf(c, "CancelApproval req:%+v err:%v", req, err)
+		}
+	}()
+
+	if err = c.ShouldBindJSON(&req); err != nil {
+		response.FailWithMessage(c, "参数绑定失败: "+err.Error())
+		return
+	}
+
+	ctx := contextx.ToContext(c)
+	if err = a.approvalService.Cancel(ctx, &req); err != nil {
+		response.FailWithMessage(c, err.Error())
+		return
+	}
+	response.OkWithMessage(c, "撤回成功")
+}
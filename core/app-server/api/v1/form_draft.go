@@ -0,0 +1,119 @@
+package v1
+
+import (
+	"github.com/ai-agent-os/ai-agent-os/core/app-server/service"
+	"github.com/ai-agent-os/ai-agent-os/dto"
+	"github.com/ai-agent-os/ai-agent-os/pkg/contextx"
+	"github.com/ai-agent-os/ai-agent-os/pkg/ginx/response"
+	"github.com/ai-agent-os/ai-agent-os/pkg/logger"
+	"github.com/gin-gonic/gin"
+)
+
+// FormDraft 向导表单草稿相关API
+type FormDraft struct {
+	formDraftService *service.FormDraftService
+}
+
+// NewFormDraft 创建向导表单草稿API（依赖注入）
+func NewFormDraft(formDraftService *service.FormDraftService) *FormDraft {
+	return &FormDraft{
+		formDraftService: formDraftService,
+	}
+}
+
+// Get 获取向导表单草稿
+// @Summary 获取向导表单草稿
+// @Description 获取当前用户在表单上保存的草稿，用于从保存的步骤继续填写
+// @Tags 向导表单
+// @Accept json
+// @Produce json
+// @Param X-Token header string true "JWT Token"
+// @Param full-code-path path string true "表单函数完整路径，如：/luobei/demo/crm/crm_apply"
+// @Success 200 {object} dto.GetFormDraftResp "获取成功"
+// @Failure 400 {string} string "请求参数错误"
+// @Failure 401 {string} string "未授权"
+// @Failure 403 {string} string "无权限"
+// @Router /api/v1/form/draft/get/{full-code-path} [get]
+func (f *FormDraft) Get(c *gin.Context) {
+	var resp *dto.GetFormDraftResp
+	var err error
+	fullCodePath := c.Param("full-code-path")
+	defer func() {
+		if err != nil {
+			logger.Errorf(c, "GetFormDraft path:%s err:%v", fullCodePath, err)
+		}
+	}()
+
+	ctx := contextx.ToContext(c)
+	resp, err = f.formDraftService.GetDraft(ctx, fullCodePath)
+	if err != nil {
+		response.FailWithMessage(c, err.Error())
+		return
+	}
+	response.OkWithData(c, resp)
+}
+
+// Save 保存向导表单草稿
+// @Summary 保存向导表单草稿
+// @Description 每个用户每个表单只有一份草稿，重复保存时覆盖；表单提交成功后草稿自动删除
+// @Tags 向导表单
+// @Accept json
+// @Produce json
+// @Param X-Token header string true "JWT Token"
+// @Param full-code-path path string true "表单函数完整路径"
+// @Param request body dto.SaveFormDraftReq true "保存草稿请求"
+// @Success 200 {object} dto.SaveFormDraftResp "保存成功"
+// @Failure 400 {string} string "请求参数错误"
+// @Failure 401 {string} string "未授权"
+// @Failure 403 {string} string "无权限"
+// @Router /api/v1/form/draft/save/{full-code-path} [post]
+func (f *FormDraft) Save(c *gin.Context) {
+	var req dto.SaveFormDraftReq
+	var resp *dto.SaveFormDraftResp
+	var err error
+	fullCodePath := c.Param("full-code-path")
+	defer func() {
+		logger.Infof(c, "SaveFormDraft path:%s step:%s err:%v", fullCodePath, req.Step, err)
+	}()
+
+	if err = c.ShouldBindJSON(&req); err != nil {
+		response.FailWithMessage(c, "参数绑定失败: "+err.Error())
+		return
+	}
+
+	ctx := contextx.ToContext(c)
+	resp, err = f.formDraftService.SaveDraft(ctx, fullCodePath, &req)
+	if err != nil {
+		response.FailWithMessage(c, err.Error())
+		return
+	}
+	response.OkWithData(c, resp)
+}
+
+// Delete 删除向导表单草稿
+// @Summary 删除向导表单草稿
+// @Description 删除当前用户在表单上保存的草稿（放弃填写）
+// @Tags 向导表单
+// @Accept json
+// @Produce json
+// @Param X-Token header string true "JWT Token"
+// @Param full-code-path path string true "表单函数完整路径"
+// @Success 200 {string} string "删除成功"
+// @Failure 400 {string} string "请求参数错误"
+// @Failure 401 {string} string "未授权"
+// @Failure 403 {string} string "无权限"
+// @Router /api/v1/form/draft/delete/{full-code-path} [post]
+func (f *FormDraft) Delete(c *gin.Context) {
+	var err error
+	fullCodePath := c.Param("full-code-path")
+	defer func() {
+		logger.Infof(c, "DeleteFormDraft path:%s err:%v", fullCodePath, err)
+	}()
+
+	ctx := contextx.ToContext(c)
+	if err = f.formDraftService.DeleteDraft(ctx, fullCodePath); err != nil {
+		response.FailWithMessage(c, err.Error())
+		return
+	}
+	response.OkWithMessage(c, "删除成功")
+}
@@ -0,0 +1,208 @@
+package v1
+
+import (
+	"github.com/ai-agent-os/ai-agent-os/core/app-server/service"
+	"github.com/ai-agent-os/ai-agent-os/dto"
+	"github.com/ai-agent-os/ai-agent-os/pkg/contextx"
+	"github.com/ai-agent-os/ai-agent-os/pkg/ginx/response"
+	"github.com/ai-agent-os/ai-agent-os/pkg/logger"
+	"github.com/gin-gonic/gin"
+)
+
+// FunctionConfig 函数运行时配置相关API
+type FunctionConfig struct {
+	functionConfigService *service.FunctionConfigService
+}
+
+// NewFunctionConfig 创建函数运行时配置API（依赖注入）
+func NewFunctionConfig(functionConfigService *service.FunctionConfigService) *FunctionConfig {
+	return &FunctionConfig{
+		functionConfigService: functionConfigService,
+	}
+}
+
+// GetConfig 获取函数运行时配置
+// @Summary 获取函数运行时配置
+// @Description 获取函数声明的配置结构、代码默认值和当前生效的配置值
+// @Tags 函数配置
+// @Accept json
+// @Produce json
+// @Param X-Token header string true "JWT Token"
+// @Param full-code-path path string true "函数完整路径，如：/luobei/demo/crm/crm_ticket"
+// @Success 200 {object} dto.GetFunctionConfigResp "获取成功"
+// @Failure 400 {string} string "请求参数错误"
+// @Failure 401 {string} string "未授权"
+// @Failure 403 {string} string "无权限"
+// @Router /api/v1/config/get/{full-code-path} [get]
+func (f *FunctionConfig) GetConfig(c *gin.Context) {
+	var resp *dto.GetFunctionConfigResp
+	var err error
+	fullCodePath := c.Param("full-code-path")
+	defer func() {
+		if err != nil {
+			logger.Errorf(c, "GetConfig path:%s err:%v", fullCodePath, err)
+		}
+	}()
+
+	ctx := contextx.ToContext(c)
+	resp, err = f.functionConfigService.GetConfig(ctx, fullCodePath)
+	if err != nil {
+		response.FailWithMessage(c, err.Error())
+		return
+	}
+	response.OkWithData(c, resp)
+}
+
+// UpdateConfig 更新函数运行时配置
+// @Summary 更新函数运行时配置
+// @Description 按配置结构校验后生成新版本，并推送到运行中的应用（无需重新编译）
+// @Tags 函数配置
+// @Accept json
+// @Produce json
+// @Param X-Token header string true "JWT Token"
+// @Param full-code-path path string true "函数完整路径"
+// @Param request body dto.UpdateFunctionConfigReq true "更新配置请求"
+// @Success 200 {object} dto.UpdateFunctionConfigResp "更新成功"
+// @Failure 400 {string} string "请求参数错误"
+// @Failure 401 {string} string "未授权"
+// @Failure 403 {string} string "无权限"
+// @Router /api/v1/config/update/{full-code-path} [post]
+func (f *FunctionConfig) UpdateConfig(c *gin.Context) {
+	var req dto.UpdateFunctionConfigReq
+	var resp *dto.UpdateFunctionConfigResp
+	var err error
+	fullCodePath := c.Param("full-code-path")
+	defer func() {
+		logger.Infof(c, "UpdateConfig path:%s req:%+v resp:%+v err:%v", fullCodePath, req, resp, err)
+	}()
+
+	if err = c.ShouldBindJSON(&req); err != nil {
+		response.FailWithMessage(c, "参数绑定失败: "+err.Error())
+		return
+	}
+
+	ctx := contextx.ToContext(c)
+	resp, err = f.functionConfigService.UpdateConfig(ctx, fullCodePath, &req)
+	if err != nil {
+		response.FailWithMessage(c, err.Error())
+		return
+	}
+	response.OkWithData(c, resp)
+}
+
+// GetHistory 获取函数配置历史
+// @Summary 获取函数配置历史
+// @Description 分页获取配置版本历史（按版本号倒序）
+// @Tags 函数配置
+// @Accept json
+// @Produce json
+// @Param X-Token header string true "JWT Token"
+// @Param full-code-path path string true "函数完整路径"
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页数量" default(20)
+// @Success 200 {object} dto.FunctionConfigHistoryResp "获取成功"
+// @Failure 400 {string} string "请求参数错误"
+// @Failure 401 {string} string "未授权"
+// @Failure 403 {string} string "无权限"
+// @Router /api/v1/config/history/{full-code-path} [get]
+func (f *FunctionConfig) GetHistory(c *gin.Context) {
+	var req dto.FunctionConfigHistoryReq
+	var resp *dto.FunctionConfigHistoryResp
+	var err error
+	fullCodePath := c.Param("full-code-path")
+	defer func() {
+		if err != nil {
+			logger.Errorf(c, "GetConfigHistory path:%s err:%v", fullCodePath, err)
+		}
+	}()
+
+	if err = c.ShouldBindQuery(&req); err != nil {
+		response.FailWithMessage(c, "参数绑定失败: "+err.Error())
+		return
+	}
+
+	ctx := contextx.ToContext(c)
+	resp, err = f.functionConfigService.ListHistory(ctx, fullCodePath, &req)
+	if err != nil {
+		response.FailWithMessage(c, err.Error())
+		return
+	}
+	response.OkWithData(c, resp)
+}
+
+// Diff 对比函数配置版本
+// @Summary 对比函数配置版本
+// @Description 对比两个配置版本的差异，from=0 表示代码默认值，to=0 表示当前版本
+// @Tags 函数配置
+// @Accept json
+// @Produce json
+// @Param X-Token header string true "JWT Token"
+// @Param full-code-path path string true "函数完整路径"
+// @Param from query int false "源版本"
+// @Param to query int false "目标版本"
+// @Success 200 {object} dto.FunctionConfigDiffResp "对比成功"
+// @Failure 400 {string} string "请求参数错误"
+// @Failure 401 {string} string "未授权"
+// @Failure 403 {string} string "无权限"
+// @Router /api/v1/config/diff/{full-code-path} [get]
+func (f *FunctionConfig) Diff(c *gin.Context) {
+	var req dto.FunctionConfigDiffReq
+	var resp *dto.FunctionConfigDiffResp
+	var err error
+	fullCodePath := c.Param("full-code-path")
+	defer func() {
+		if err != nil {
+			logger.Errorf(c, "DiffConfig path:%s req:%+v err:%v", fullCodePath, req, err)
+		}
+	}()
+
+	if err = c.ShouldBindQuery(&req); err != nil {
+		response.FailWithMessage(c, "参数绑定失败: "+err.Error())
+		return
+	}
+
+	ctx := contextx.ToContext(c)
+	resp, err = f.functionConfigService.Diff(ctx, fullCodePath, &req)
+	if err != nil {
+		response.FailWithMessage(c, err.Error())
+		return
+	}
+	response.OkWithData(c, resp)
+}
+
+// Rollback 回滚函数配置
+// @Summary 回滚函数配置
+// @Description 以指定版本的值生成一个新版本并推送到运行中的应用，历史版本不会被改写
+// @Tags 函数配置
+// @Accept json
+// @Produce json
+// @Param X-Token header string true "JWT Token"
+// @Param full-code-path path string true "函数完整路径"
+// @Param request body dto.FunctionConfigRollbackReq true "回滚请求"
+// @Success 200 {object} dto.UpdateFunctionConfigResp "回滚成功"
+// @Failure 400 {string} string "请求参数错误"
+// @Failure 401 {string} string "未授权"
+// @Failure 403 {string} string "无权限"
+// @Router /api/v1/config/rollback/{full-code-path} [post]
+func (f *FunctionConfig) Rollback(c *gin.Context) {
+	var req dto.FunctionConfigRollbackReq
+	var resp *dto.UpdateFunctionConfigResp
+	var err error
+	fullCodePath := c.Param("full-code-path")
+	defer func() {
+		logger.Infof(c, "RollbackConfig path:%s req:%+v resp:%+v err:%v", fullCodePath, req, resp, err)
+	}()
+
+	if err = c.ShouldBindJSON(&req); err != nil {
+		response.FailWithMessage(c, "参数绑定失败: "+err.Error())
+		return
+	}
+
+	ctx := contextx.ToContext(c)
+	resp, err = f.functionConfigService.Rollback(ctx, fullCodePath, &req)
+	if err != nil {
+		response.FailWithMessage(c, err.Error())
+		return
+	}
+	response.OkWithData(c, resp)
+}
@@ -0,0 +1,221 @@
+package v1
+
+import (
+	"io"
+	"time"
+
+	"github.com/ai-agent-os/ai-agent-os/core/app-server/service"
+	"github.com/ai-agent-os/ai-agent-os/dto"
+	"github.com/ai-agent-os/ai-agent-os/pkg/contextx"
+	"github.com/ai-agent-os/ai-agent-os/pkg/ginx/response"
+	"github.com/ai-agent-os/ai-agent-os/pkg/logger"
+	"github.com/gin-gonic/gin"
+)
+
+// notificationHeartbeatInterval SSE 心跳间隔（避免网关/代理因空闲断开连接）
+const notificationHeartbeatInterval = 30 * time.Second
+
+// Notification 通知中心相关API
+type Notification struct {
+	notificationService *service.NotificationService
+}
+
+// NewNotification 创建通知中心API（依赖注入）
+func NewNotification(notificationService *service.NotificationService) *Notification {
+	return &Notification{
+		notificationService: notificationService,
+	}
+}
+
+// GetNotifications 获取通知列表
+// @Summary 获取通知列表
+// @Description 获取当前用户的站内通知（按时间倒序），同时返回未读数量
+// @Tags 通知中心
+// @Accept json
+// @Produce json
+// @Param X-Token header string true "JWT Token"
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页数量" default(20)
+// @Param unread_only query bool false "只看未读"
+// @Success 200 {object} dto.GetNotificationsResp "获取成功"
+// @Failure 400 {string} string "请求参数错误"
+// @Failure 401 {string} string "未授权"
+// @Router /api/v1/notification/list [get]
+func (n *Notification) GetNotifications(c *gin.Context) {
+	var req dto.GetNotificationsReq
+	var resp *dto.GetNotificationsResp
+	var err error
+	defer func() {
+		if err != nil {
+			logger.Errorf(c, "GetNotifications req:%+v err:%v", req, err)
+		}
+	}()
+
+	if err = c.ShouldBindQuery(&req); err != nil {
+		response.FailWithMessage(c, "参数绑定失败: "+err.Error())
+		return
+	}
+
+	ctx := contextx.ToContext(c)
+	resp, err = n.notificationService.GetNotifications(ctx, &req)
+	if err != nil {
+		response.FailWithMessage(c, err.Error())
+		return
+	}
+	response.OkWithData(c, resp)
+}
+
+// GetUnreadCount 获取未读通知数量
+// @Summary 获取未读通知数量
+// @Description 获取当前用户的未读通知数量
+// @Tags 通知中心
+// @Accept json
+// @Produce json
+// @Param X-Token header string true "JWT Token"
+// @Success 200 {object} dto.GetUnreadCountResp "获取成功"
+// @Failure 401 {string} string "未授权"
+// @Router /api/v1/notification/unread_count [get]
+func (n *Notification) GetUnreadCount(c *gin.Context) {
+	ctx := contextx.ToContext(c)
+	resp, err := n.notificationService.GetUnreadCount(ctx)
+	if err != nil {
+		logger.Errorf(c, "GetUnreadCount err:%v", err)
+		response.FailWithMessage(c, err.Error())
+		return
+	}
+	response.OkWithData(c, resp)
+}
+
+// MarkRead 标记通知为已读
+// @Summary 标记通知为已读
+// @Description 标记指定通知或全部通知为已读（只能标记自己的通知）
+// @Tags 通知中心
+// @Accept json
+// @Produce json
+// @Param X-Token header string true "JWT Token"
+// @Param request body dto.MarkNotificationsReadReq true "标记已读请求"
+// @Success 200 {string} string "标记成功"
+// @Failure 400 {string} string "请求参数错误"
+// @Failure 401 {string} string "未授权"
+// @Router /api/v1/notification/read [post]
+func (n *Notification) MarkRead(c *gin.Context) {
+	var req dto.MarkNotificationsReadReq
+	var err error
+	defer func() {
+		logger.Infof(c, "MarkNotificationsRead req:%+v err:%v", req, err)
+	}()
+
+	if err = c.ShouldBindJSON(&req); err != nil {
+		response.FailWithMessage(c, "参数绑定失败: "+err.Error())
+		return
+	}
+
+	ctx := contextx.ToContext(c)
+	if err = n.notificationService.MarkRead(ctx, &req); err != nil {
+		response.FailWithMessage(c, err.Error())
+		return
+	}
+	response.OkWithMessage(c, "标记成功")
+}
+
+// GetPreference 获取通知偏好
+// @Summary 获取通知偏好
+// @Description 获取当前用户的通知渠道偏好（站内、邮件、Webhook），Webhook 密钥不回显
+// @Tags 通知中心
+// @Accept json
+// @Produce json
+// @Param X-Token header string true "JWT Token"
+// @Success 200 {object} dto.NotificationPreferenceInfo "获取成功"
+// @Failure 401 {string} string "未授权"
+// @Router /api/v1/notification/preference [get]
+func (n *Notification) GetPreference(c *gin.Context) {
+	ctx := contextx.ToContext(c)
+	resp, err := n.notificationService.GetPreference(ctx)
+	if err != nil {
+		logger.Errorf(c, "GetNotificationPreference err:%v", err)
+		response.FailWithMessage(c, err.Error())
+		return
+	}
+	response.OkWithData(c, resp)
+}
+
+// UpdatePreference 更新通知偏好
+// @Summary 更新通知偏好
+// @Description 更新当前用户的通知渠道偏好，webhook_secret 为空表示不修改
+// @Tags 通知中心
+// @Accept json
+// @Produce json
+// @Param X-Token header string true "JWT Token"
+// @Param request body dto.NotificationPreferenceInfo true "通知偏好"
+// @Success 200 {string} string "保存成功"
+// @Failure 400 {string} string "请求参数错误"
+// @Failure 401 {string} string "未授权"
+// @Router /api/v1/notification/preference [post]
+func (n *Notification) UpdatePreference(c *gin.Context) {
+	var req dto.NotificationPreferenceInfo
+	var err error
+	defer func() {
+		// ⭐ 不记录 Webhook 密钥
+		logger.Infof(c, "UpdateNotificationPreference in_app:%v email:%v webhook:%v url:%s err:%v",
+			req.InAppEnabled, req.EmailEnabled, req.WebhookEnabled, req.WebhookURL, err)
+	}()
+
+	if err = c.ShouldBindJSON(&req); err != nil {
+		response.FailWithMessage(c, "参数绑定失败: "+err.Error())
+		return
+	}
+
+	ctx := contextx.ToContext(c)
+	if err = n.notificationService.UpdatePreference(ctx, &req); err != nil {
+		response.FailWithMessage(c, err.Error())
+		return
+	}
+	response.OkWithMessage(c, "保存成功")
+}
+
+// Subscribe 订阅站内通知（SSE）
+// @Summary 订阅站内通知
+// @Description 通过 Server-Sent Events 实时接收站内通知。连接建立后先推送 unread 事件（未读数量），之后每条新通知推送一个 notification 事件，空闲时定期推送 ping 事件
+// @Tags 通知中心
+// @Produce text/event-stream
+// @Param X-Token header string true "JWT Token"
+// @Success 200 {object} dto.NotificationInfo "notification 事件"
+// @Failure 401 {string} string "未授权"
+// @Router /api/v1/notification/subscribe [get]
+func (n *Notification) Subscribe(c *gin.Context) {
+	username := contextx.GetRequestUser(c)
+	ch, cancel := n.notificationService.Subscribe(username)
+	defer cancel()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no") // 禁止 nginx 等代理缓冲
+
+	ctx := contextx.ToContext(c)
+	if unread, err := n.notificationService.GetUnreadCount(ctx); err == nil {
+		c.SSEvent("unread", unread)
+		c.Writer.Flush()
+	}
+
+	ticker := time.NewTicker(notificationHeartbeatInterval)
+	defer ticker.Stop()
+
+	logger.Infof(c, "[Notification] SSE 订阅建立: user=%s", username)
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case info, ok := <-ch:
+			if !ok {
+				return false
+			}
+			c.SSEvent("notification", info)
+			return true
+		case <-ticker.C:
+			c.SSEvent("ping", time.Now().Unix())
+			return true
+		case <-c.Request.Context().Done():
+			return false
+		}
+	})
+	logger.Infof(c, "[Notification] SSE 订阅断开: user=%s", username)
+}
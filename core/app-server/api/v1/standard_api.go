@@ -127,6 +127,15 @@ func (s *StandardAPI) buildCallbackAppReq(c *gin.Context, fullCodePath string, c
 	return req, nil
 }
 
+// callbackReqBody 取出回调请求中封装的原始请求体（buildCallbackAppReq 已读取请求体并放在 body 字段中）
+func callbackReqBody(req *dto.RequestAppReq) []byte {
+	var callback struct {
+		Body []byte `json:"body"`
+	}
+	_ = json.Unmarshal(req.Body, &callback)
+	return callback.Body
+}
+
 // ============================================
 // Table 函数接口
 // ============================================
@@ -246,6 +255,16 @@ func (s *StandardAPI) TableCreate(c *gin.Context) {
 		return
 	}
 
+	// 发布表格数据变更事件（工作流等订阅者据此触发）
+	s.appService.EmitTableEvent(ctx, &service.TableEvent{
+		FullCodePath: fullCodePath,
+		Action:       "OnTableAddRow",
+		RequestUser:  req.RequestUser,
+		TraceID:      req.TraceId,
+		Body:         callbackReqBody(req),
+		Result:       resp.Result,
+	})
+
 	response.OkWithData(c, resp.Result, metadata)
 }
 
@@ -642,6 +661,12 @@ func generateExampleValueForRow(field *widget.Field, rowIndex int, maxRows int,
 // @Failure 500 {string} string "服务器内部错误"
 // @Router /api/v1/table/update/{full-code-path} [put]
 func (s *StandardAPI) TableUpdate(c *gin.Context) {
+	s.updateRow(c, "OnTableUpdateRow")
+}
+
+// updateRow 更新单条记录（表格编辑、看板拖动卡片），记录操作日志并发布表格更新事件
+// callbackType 为 SDK 回调类型，同时作为操作日志的 Action
+func (s *StandardAPI) updateRow(c *gin.Context, callbackType string) {
 	fullCodePath := c.Param("full-code-path")
 	if fullCodePath == "" {
 		response.FailWithMessage(c, "full-code-path 参数不能为空")
@@ -656,8 +681,8 @@ func (s *StandardAPI) TableUpdate(c *gin.Context) {
 	}
 	c.Request.Body = io.NopCloser(strings.NewReader(string(bodyBytes))) // 重新设置请求体，供后续使用
 
-	// 构建回调请求对象（调用 OnTableUpdateRow）
-	req, err := s.buildCallbackAppReq(c, fullCodePath, "OnTableUpdateRow")
+	// 构建回调请求对象（调用 OnTableUpdateRow 或 OnKanbanMove）
+	req, err := s.buildCallbackAppReq(c, fullCodePath, callbackType)
 	if err != nil {
 		response.FailWithMessage(c, "构建请求失败: "+err.Error())
 		return
@@ -672,7 +697,7 @@ func (s *StandardAPI) TableUpdate(c *gin.Context) {
 			RequestUser: req.RequestUser,
 			App:         app,
 			Router:      router,
-			Action:      "OnTableUpdateRow",
+			Action:      callbackType,
 			IPAddress:   c.ClientIP(),
 			UserAgent:   c.GetHeader("User-Agent"),
 			TraceID:     req.TraceId,
@@ -699,7 +724,7 @@ func (s *StandardAPI) TableUpdate(c *gin.Context) {
 		ctx := contextx.ToContext(c)
 		go func() {
 			if err := s.appService.RecordTableOperateLog(ctx, logReq); err != nil {
-				logger.Warnf(ctx, "[TableUpdate] 记录 Table 更新操作日志失败: %v", err)
+				logger.Warnf(ctx, "[%s] 记录 Table 更新操作日志失败: %v", callbackType, err)
 			}
 		}()
 	}
@@ -729,6 +754,16 @@ func (s *StandardAPI) TableUpdate(c *gin.Context) {
 		return
 	}
 
+	// 发布表格数据变更事件（工作流等订阅者据此触发，看板拖动卡片也是一次记录更新）
+	s.appService.EmitTableEvent(ctx, &service.TableEvent{
+		FullCodePath: fullCodePath,
+		Action:       "OnTableUpdateRow",
+		RequestUser:  req.RequestUser,
+		TraceID:      req.TraceId,
+		Body:         bodyBytes,
+		Result:       resp.Result,
+	})
+
 	response.OkWithData(c, resp.Result, metadata)
 }
 
@@ -831,6 +866,156 @@ func (s *StandardAPI) TableDelete(c *gin.Context) {
 		return
 	}
 
+	// 发布表格数据变更事件（工作流等订阅者据此触发）
+	s.appService.EmitTableEvent(ctx, &service.TableEvent{
+		FullCodePath: fullCodePath,
+		Action:       "OnTableDeleteRows",
+		RequestUser:  req.RequestUser,
+		TraceID:      req.TraceId,
+		Body:         bodyBytes,
+		Result:       resp.Result,
+	})
+
+	response.OkWithData(c, resp.Result, metadata)
+}
+
+// TableChildren Table 子表加载接口
+// @Summary Table 子表加载
+// @Description 主从表展开主表行时加载子表记录，支持与表格查询相同的搜索、排序、分页和聚合统计
+// @Tags 标准接口
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param X-Token header string true "JWT Token"
+// @Param full-code-path path string true "函数完整路径，如：/luobei/operations/tools/pdftools/to_images"
+// @Param body body object true "加载条件，格式：{\"code\": \"items\", \"parent_id\": 1, \"page\": 1, \"page_size\": 20, \"like\": [\"name:笔\"]}"
+// @Success 200 {object} dto.RequestAppResp "查询成功"
+// @Failure 400 {string} string "请求参数错误"
+// @Failure 401 {string} string "未授权"
+// @Failure 403 {string} string "权限不足"
+// @Failure 500 {string} string "服务器内部错误"
+// @Router /api/v1/table/children/{full-code-path} [post]
+func (s *StandardAPI) TableChildren(c *gin.Context) {
+	fullCodePath := c.Param("full-code-path")
+	if fullCodePath == "" {
+		response.FailWithMessage(c, "full-code-path 参数不能为空")
+		return
+	}
+
+	// 构建回调请求对象（调用系统内置的 OnTableLoadChildren）
+	req, err := s.buildCallbackAppReq(c, fullCodePath, "OnTableLoadChildren")
+	if err != nil {
+		response.FailWithMessage(c, "构建请求失败: "+err.Error())
+		return
+	}
+
+	// 调用服务层
+	ctx := contextx.ToContext(c)
+	now := time.Now()
+	resp, err := s.appService.RequestApp(ctx, req)
+	mill := time.Since(now).Milliseconds()
+
+	// 构建响应元数据
+	metadata := make(map[string]interface{})
+	metadata["trace_id"] = req.TraceId
+	metadata["app"] = req.App
+	if resp != nil {
+		metadata["version"] = resp.Version
+	}
+	metadata["total_cost_mill"] = mill
+
+	if err != nil {
+		response.FailWithMessage(c, err.Error(), metadata)
+		return
+	}
+
+	if resp.Error != "" {
+		response.Result(resp.ErrCode, nil, resp.Error, c, metadata)
+		return
+	}
+
+	response.OkWithData(c, resp.Result, metadata)
+}
+
+// ============================================
+// Kanban / Calendar 函数接口
+// ============================================
+
+// KanbanMove 看板拖动卡片接口
+// @Summary 看板拖动卡片
+// @Description 把卡片拖到其他列（修改分列字段）或调整列内顺序，权限与表格更新相同
+// @Tags 标准接口
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param X-Token header string true "JWT Token"
+// @Param full-code-path path string true "函数完整路径，如：/luobei/operations/tools/pdftools/to_images"
+// @Param body body object true "格式：{\"id\": 1, \"updates\": {\"status\": \"进行中\"}, \"old_values\": {\"status\": \"待处理\"}, \"index\": 0}"
+// @Success 200 {object} dto.RequestAppResp "移动成功"
+// @Failure 400 {string} string "请求参数错误"
+// @Failure 401 {string} string "未授权"
+// @Failure 403 {string} string "权限不足"
+// @Failure 500 {string} string "服务器内部错误"
+// @Router /api/v1/kanban/move/{full-code-path} [put]
+func (s *StandardAPI) KanbanMove(c *gin.Context) {
+	s.updateRow(c, "OnKanbanMove")
+}
+
+// CalendarRange 日历按时间范围加载日程接口
+// @Summary 日历时间范围查询
+// @Description 按时间范围（毫秒时间戳，[start, end)）加载日历中的日程，支持与表格查询相同的搜索条件
+// @Tags 标准接口
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param X-Token header string true "JWT Token"
+// @Param full-code-path path string true "函数完整路径，如：/luobei/operations/tools/pdftools/to_images"
+// @Param body body object true "查询条件，格式：{\"start\": 1730390400000, \"end\": 1733068800000, \"eq\": [\"room:A101\"]}"
+// @Success 200 {object} dto.RequestAppResp "查询成功"
+// @Failure 400 {string} string "请求参数错误"
+// @Failure 401 {string} string "未授权"
+// @Failure 403 {string} string "权限不足"
+// @Failure 500 {string} string "服务器内部错误"
+// @Router /api/v1/calendar/range/{full-code-path} [post]
+func (s *StandardAPI) CalendarRange(c *gin.Context) {
+	fullCodePath := c.Param("full-code-path")
+	if fullCodePath == "" {
+		response.FailWithMessage(c, "full-code-path 参数不能为空")
+		return
+	}
+
+	// 构建回调请求对象（调用 OnCalendarRange）
+	req, err := s.buildCallbackAppReq(c, fullCodePath, "OnCalendarRange")
+	if err != nil {
+		response.FailWithMessage(c, "构建请求失败: "+err.Error())
+		return
+	}
+
+	// 调用服务层
+	ctx := contextx.ToContext(c)
+	now := time.Now()
+	resp, err := s.appService.RequestApp(ctx, req)
+	mill := time.Since(now).Milliseconds()
+
+	// 构建响应元数据
+	metadata := make(map[string]interface{})
+	metadata["trace_id"] = req.TraceId
+	metadata["app"] = req.App
+	if resp != nil {
+		metadata["version"] = resp.Version
+	}
+	metadata["total_cost_mill"] = mill
+
+	if err != nil {
+		response.FailWithMessage(c, err.Error(), metadata)
+		return
+	}
+
+	if resp.Error != "" {
+		response.Result(resp.ErrCode, nil, resp.Error, c, metadata)
+		return
+	}
+
 	response.OkWithData(c, resp.Result, metadata)
 }
 
@@ -895,9 +1080,75 @@ func (s *StandardAPI) FormSubmit(c *gin.Context) {
 		return
 	}
 
+	// 发布表单提交事件（删除提交人的向导表单草稿）
+	s.appService.EmitFormEvent(ctx, &service.FormEvent{
+		FullCodePath: fullCodePath,
+		RequestUser:  req.RequestUser,
+		TraceID:      req.TraceId,
+	})
+
+	response.OkWithData(c, resp.Result, metadata)
+}
+
+// FormStepValidate 向导表单步骤校验接口
+// @Summary 向导表单步骤校验
+// @Description 进入下一步前校验当前步骤：按字段的 validate 标签校验后执行该步骤的 OnFormStepValidate 回调，返回字段错误、后续步骤的预填值和下一步
+// @Tags 标准接口
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param X-Token header string true "JWT Token"
+// @Param full-code-path path string true "函数完整路径，如：/luobei/demo/crm/crm_apply"
+// @Param body body object true "步骤校验请求（step: 步骤名称，body: 已填写的表单数据）"
+// @Success 200 {object} dto.RequestAppResp "校验完成（errors 为空表示通过）"
+// @Failure 400 {string} string "请求参数错误"
+// @Failure 401 {string} string "未授权"
+// @Failure 403 {string} string "权限不足"
+// @Failure 500 {string} string "服务器内部错误"
+// @Router /api/v1/form/step/{full-code-path} [post]
+func (s *StandardAPI) FormStepValidate(c *gin.Context) {
+	fullCodePath := c.Param("full-code-path")
+	if fullCodePath == "" {
+		response.FailWithMessage(c, "full-code-path 参数不能为空")
+		return
+	}
+
+	// 构建回调请求对象（调用 OnFormStepValidate）
+	req, err := s.buildCallbackAppReq(c, fullCodePath, "OnFormStepValidate")
+	if err != nil {
+		response.FailWithMessage(c, "构建请求失败: "+err.Error())
+		return
+	}
+
+	// 调用服务层
+	ctx := contextx.ToContext(c)
+	now := time.Now()
+	resp, err := s.appService.RequestApp(ctx, req)
+	mill := time.Since(now).Milliseconds()
+
+	// 构建响应元数据
+	metadata := make(map[string]interface{})
+	metadata["trace_id"] = req.TraceId
+	metadata["app"] = req.App
+	if resp != nil {
+		metadata["version"] = resp.Version
+	}
+	metadata["total_cost_mill"] = mill
+
+	if err != nil {
+		response.FailWithMessage(c, err.Error(), metadata)
+		return
+	}
+
+	if resp.Error != "" {
+		response.Result(resp.ErrCode, nil, resp.Error, c, metadata)
+		return
+	}
+
 	response.OkWithData(c, resp.Result, metadata)
 }
 
+
 // ============================================
 // Chart 函数接口
 // ============================================
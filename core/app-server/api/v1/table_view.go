@@ -0,0 +1,136 @@
+package v1
+
+import (
+	"github.com/ai-agent-os/ai-agent-os/core/app-server/model"
+	"github.com/ai-agent-os/ai-agent-os/core/app-server/service"
+	"github.com/ai-agent-os/ai-agent-os/dto"
+	"github.com/ai-agent-os/ai-agent-os/pkg/contextx"
+	"github.com/ai-agent-os/ai-agent-os/pkg/ginx/response"
+	"github.com/ai-agent-os/ai-agent-os/pkg/logger"
+	"github.com/ai-agent-os/ai-agent-os/pkg/middleware"
+	"github.com/ai-agent-os/ai-agent-os/pkg/permission"
+	"github.com/gin-gonic/gin"
+)
+
+// TableView 表格视图相关API
+type TableView struct {
+	tableViewService *service.TableViewService
+}
+
+// NewTableView 创建表格视图API（依赖注入）
+func NewTableView(tableViewService *service.TableViewService) *TableView {
+	return &TableView{
+		tableViewService: tableViewService,
+	}
+}
+
+// List 获取表格视图列表
+// @Summary 获取表格视图列表
+// @Description 获取当前用户可见的视图：自己创建的、共享给所在部门的、所有人可见的，默认视图排在最前面
+// @Tags 表格视图
+// @Accept json
+// @Produce json
+// @Param X-Token header string true "JWT Token"
+// @Param full-code-path path string true "表格函数完整路径，如：/luobei/demo/crm/crm_ticket"
+// @Success 200 {object} dto.ListTableViewsResp "获取成功"
+// @Failure 400 {string} string "请求参数错误"
+// @Failure 401 {string} string "未授权"
+// @Failure 403 {string} string "无权限"
+// @Router /api/v1/view/list/{full-code-path} [get]
+func (t *TableView) List(c *gin.Context) {
+	var resp *dto.ListTableViewsResp
+	var err error
+	fullCodePath := c.Param("full-code-path")
+	defer func() {
+		if err != nil {
+			logger.Errorf(c, "ListTableViews path:%s err:%v", fullCodePath, err)
+		}
+	}()
+
+	ctx := contextx.ToContext(c)
+	resp, err = t.tableViewService.ListViews(ctx, fullCodePath)
+	if err != nil {
+		response.FailWithMessage(c, err.Error())
+		return
+	}
+	response.OkWithData(c, resp)
+}
+
+// Save 创建或更新表格视图
+// @Summary 创建或更新表格视图
+// @Description id 为 0 时创建，否则更新（只能修改自己创建的视图）。共享给所有人或设置为默认视图需要函数的所有权
+// @Tags 表格视图
+// @Accept json
+// @Produce json
+// @Param X-Token header string true "JWT Token"
+// @Param full-code-path path string true "表格函数完整路径"
+// @Param request body dto.SaveTableViewReq true "保存视图请求"
+// @Success 200 {object} dto.SaveTableViewResp "保存成功"
+// @Failure 400 {string} string "请求参数错误"
+// @Failure 401 {string} string "未授权"
+// @Failure 403 {string} string "无权限"
+// @Router /api/v1/view/save/{full-code-path} [post]
+func (t *TableView) Save(c *gin.Context) {
+	var req dto.SaveTableViewReq
+	var resp *dto.SaveTableViewResp
+	var err error
+	fullCodePath := c.Param("full-code-path")
+	defer func() {
+		logger.Infof(c, "SaveTableView path:%s req:%+v resp:%+v err:%v", fullCodePath, req, resp, err)
+	}()
+
+	if err = c.ShouldBindJSON(&req); err != nil {
+		response.FailWithMessage(c, "参数绑定失败: "+err.Error())
+		return
+	}
+
+	// 所有人可见的视图会影响其他用户，需要函数的所有权（社区版不做权限控制）
+	if req.Scope == model.TableViewScopeAll || req.IsDefault {
+		if !middleware.CheckPermissionWithPath(c, fullCodePath, permission.FunctionManage, "无权限共享视图给所有人") {
+			return
+		}
+	}
+
+	ctx := contextx.ToContext(c)
+	resp, err = t.tableViewService.SaveView(ctx, fullCodePath, &req)
+	if err != nil {
+		response.FailWithMessage(c, err.Error())
+		return
+	}
+	response.OkWithData(c, resp)
+}
+
+// Delete 删除表格视图
+// @Summary 删除表格视图
+// @Description 只能删除自己创建的视图
+// @Tags 表格视图
+// @Accept json
+// @Produce json
+// @Param X-Token header string true "JWT Token"
+// @Param full-code-path path string true "表格函数完整路径"
+// @Param request body dto.DeleteTableViewReq true "删除视图请求"
+// @Success 200 {string} string "删除成功"
+// @Failure 400 {string} string "请求参数错误"
+// @Failure 401 {string} string "未授权"
+// @Failure 403 {string} string "无权限"
+// @Router /api/v1/view/delete/{full-code-path} [post]
+func (t *TableView) Delete(c *gin.Context) {
+	var req dto.DeleteTableViewReq
+	var err error
+	fullCodePath := c.Param("full-code-path")
+	defer func() {
+		logger.Infof(c, "DeleteTableView path:%s req:%+v err:%v", fullCodePath, req, err)
+	}()
+
+	if err = c.ShouldBindJSON(&req); err != nil {
+		response.FailWithMessage(c, "参数绑定失败: "+err.Error())
+		return
+	}
+
+	ctx := contextx.ToContext(c)
+	if err = t.tableViewService.DeleteView(ctx, fullCodePath, req.ID); err != nil {
+		response.FailWithMessage(c, err.Error())
+		return
+	}
+	response.OkWithMessage(c, "删除成功")
+}
@@ -0,0 +1,348 @@
+package v1
+
+import (
+	"strconv"
+
+	"github.com/ai-agent-os/ai-agent-os/core/app-server/service"
+	"github.com/ai-agent-os/ai-agent-os/dto"
+	"github.com/ai-agent-os/ai-agent-os/pkg/contextx"
+	"github.com/ai-agent-os/ai-agent-os/pkg/ginx/response"
+	"github.com/ai-agent-os/ai-agent-os/pkg/logger"
+	"github.com/gin-gonic/gin"
+)
+
+// Workflow 工作流相关API
+type Workflow struct {
+	workflowService *service.WorkflowService
+}
+
+// NewWorkflow 创建工作流API（依赖注入）
+func NewWorkflow(workflowService *service.WorkflowService) *Workflow {
+	return &Workflow{
+		workflowService: workflowService,
+	}
+}
+
+// Save 创建/更新工作流
+// @Summary 创建/更新工作流
+// @Description 保存工作流定义和触发器（ID 为 0 时创建）。保存前会校验步骤依赖（必须是有向无环图）、函数是否存在、输入映射的字段和类型是否匹配
+// @Tags 工作流
+// @Accept json
+// @Produce json
+// @Param X-Token header string true "JWT Token"
+// @Param request body dto.SaveWorkflowReq true "工作流"
+// @Success 200 {object} dto.SaveWorkflowResp "保存成功"
+// @Failure 400 {string} string "请求参数错误或校验不通过"
+// @Failure 401 {string} string "未授权"
+// @Router /api/v1/workflow/save [post]
+func (w *Workflow) Save(c *gin.Context) {
+	var req dto.SaveWorkflowReq
+	var resp *dto.SaveWorkflowResp
+	var err error
+	defer func() {
+		if err != nil {
+			logger.Errorf(c, "SaveWorkflow req:%+v err:%v", req, err)
+		}
+	}()
+
+	if err = c.ShouldBindJSON(&req); err != nil {
+		response.FailWithMessage(c, "参数绑定失败: "+err.Error())
+		return
+	}
+
+	ctx := contextx.ToContext(c)
+	resp, err = w.workflowService.SaveWorkflow(ctx, &req)
+	if err != nil {
+		response.FailWithMessage(c, err.Error())
+		return
+	}
+	response.OkWithData(c, resp)
+}
+
+// List 获取我的工作流列表
+// @Summary 获取我的工作流列表
+// @Description 获取当前用户创建的工作流
+// @Tags 工作流
+// @Accept json
+// @Produce json
+// @Param X-Token header string true "JWT Token"
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页数量" default(20)
+// @Param keyword query string false "名称关键字"
+// @Success 200 {object} dto.GetWorkflowsResp "获取成功"
+// @Failure 400 {string} string "请求参数错误"
+// @Failure 401 {string} string "未授权"
+// @Router /api/v1/workflow/list [get]
+func (w *Workflow) List(c *gin.Context) {
+	var req dto.GetWorkflowsReq
+	var resp *dto.GetWorkflowsResp
+	var err error
+	defer func() {
+		if err != nil {
+			logger.Errorf(c, "GetWorkflows req:%+v err:%v", req, err)
+		}
+	}()
+
+	if err = c.ShouldBindQuery(&req); err != nil {
+		response.FailWithMessage(c, "参数绑定失败: "+err.Error())
+		return
+	}
+
+	ctx := contextx.ToContext(c)
+	resp, err = w.workflowService.GetWorkflows(ctx, &req)
+	if err != nil {
+		response.FailWithMessage(c, err.Error())
+		return
+	}
+	response.OkWithData(c, resp)
+}
+
+// Get 获取工作流详情
+// @Summary 获取工作流详情
+// @Description 获取工作流定义和触发器（仅创建人可以查看）
+// @Tags 工作流
+// @Accept json
+// @Produce json
+// @Param X-Token header string true "JWT Token"
+// @Param id path int true "工作流ID"
+// @Success 200 {object} dto.WorkflowInfo "获取成功"
+// @Failure 400 {string} string "请求参数错误"
+// @Failure 401 {string} string "未授权"
+// @Router /api/v1/workflow/get/{id} [get]
+func (w *Workflow) Get(c *gin.Context) {
+	var resp *dto.WorkflowInfo
+	var err error
+	defer func() {
+		if err != nil {
+			logger.Errorf(c, "GetWorkflow id:%s err:%v", c.Param("id"), err)
+		}
+	}()
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.FailWithMessage(c, "无效的工作流ID")
+		return
+	}
+
+	ctx := contextx.ToContext(c)
+	resp, err = w.workflowService.GetWorkflow(ctx, id)
+	if err != nil {
+		response.FailWithMessage(c, err.Error())
+		return
+	}
+	response.OkWithData(c, resp)
+}
+
+// Delete 删除工作流
+// @Summary 删除工作流
+// @Description 删除工作流（运行记录保留）
+// @Tags 工作流
+// @Accept json
+// @Produce json
+// @Param X-Token header string true "JWT Token"
+// @Param request body dto.DeleteWorkflowReq true "删除请求"
+// @Success 200 {string} string "删除成功"
+// @Failure 400 {string} string "请求参数错误"
+// @Failure 401 {string} string "未授权"
+// @Router /api/v1/workflow/delete [post]
+func (w *Workflow) Delete(c *gin.Context) {
+	var req dto.DeleteWorkflowReq
+	var err error
+	defer func() {
+		if err != nil {
+			logger.Errorf(c, "DeleteWorkflow req:%+v err:%v", req, err)
+		}
+	}()
+
+	if err = c.ShouldBindJSON(&req); err != nil {
+		response.FailWithMessage(c, "参数绑定失败: "+err.Error())
+		return
+	}
+
+	ctx := contextx.ToContext(c)
+	if err = w.workflowService.DeleteWorkflow(ctx, &req); err != nil {
+		response.FailWithMessage(c, err.Error())
+		return
+	}
+	response.OkWithMessage(c, "删除成功")
+}
+
+// Suggest 推荐步骤输入映射
+// @Summary 推荐步骤输入映射
+// @Description 按字段 code 和类型从上游步骤的输出中为尚未映射的请求字段推荐数据来源，并返回当前定义的校验问题
+// @Tags 工作流
+// @Accept json
+// @Produce json
+// @Param X-Token header string true "JWT Token"
+// @Param request body dto.SuggestWorkflowInputsReq true "工作流定义"
+// @Success 200 {object} dto.SuggestWorkflowInputsResp "推荐成功"
+// @Failure 400 {string} string "请求参数错误"
+// @Failure 401 {string} string "未授权"
+// @Router /api/v1/workflow/suggest [post]
+func (w *Workflow) Suggest(c *gin.Context) {
+	var req dto.SuggestWorkflowInputsReq
+	var resp *dto.SuggestWorkflowInputsResp
+	var err error
+	defer func() {
+		if err != nil {
+			logger.Errorf(c, "SuggestWorkflowInputs req:%+v err:%v", req, err)
+		}
+	}()
+
+	if err = c.ShouldBindJSON(&req); err != nil {
+		response.FailWithMessage(c, "参数绑定失败: "+err.Error())
+		return
+	}
+
+	ctx := contextx.ToContext(c)
+	resp, err = w.workflowService.SuggestInputs(ctx, &req)
+	if err != nil {
+		response.FailWithMessage(c, err.Error())
+		return
+	}
+	response.OkWithData(c, resp)
+}
+
+// Run 手动运行工作流
+// @Summary 手动运行工作流
+// @Description 以当前用户身份运行工作流，运行在后台执行，通过运行记录查看进度
+// @Tags 工作流
+// @Accept json
+// @Produce json
+// @Param X-Token header string true "JWT Token"
+// @Param request body dto.RunWorkflowReq true "运行请求"
+// @Success 200 {object} dto.RunWorkflowResp "已提交"
+// @Failure 400 {string} string "请求参数错误"
+// @Failure 401 {string} string "未授权"
+// @Router /api/v1/workflow/run [post]
+func (w *Workflow) Run(c *gin.Context) {
+	var req dto.RunWorkflowReq
+	var resp *dto.RunWorkflowResp
+	var err error
+	defer func() {
+		if err != nil {
+			logger.Errorf(c, "RunWorkflow req:%+v err:%v", req, err)
+		}
+	}()
+
+	if err = c.ShouldBindJSON(&req); err != nil {
+		response.FailWithMessage(c, "参数绑定失败: "+err.Error())
+		return
+	}
+
+	ctx := contextx.ToContext(c)
+	resp, err = w.workflowService.RunWorkflow(ctx, &req)
+	if err != nil {
+		response.FailWithMessage(c, err.Error())
+		return
+	}
+	response.OkWithData(c, resp)
+}
+
+// CancelRun 取消工作流运行
+// @Summary 取消工作流运行
+// @Description 取消等待中或执行中的运行（正在执行的步骤会执行完，后续步骤不再执行）
+// @Tags 工作流
+// @Accept json
+// @Produce json
+// @Param X-Token header string true "JWT Token"
+// @Param request body dto.CancelWorkflowRunReq true "取消请求"
+// @Success 200 {string} string "取消成功"
+// @Failure 400 {string} string "请求参数错误"
+// @Failure 401 {string} string "未授权"
+// @Router /api/v1/workflow/run/cancel [post]
+func (w *Workflow) CancelRun(c *gin.Context) {
+	var req dto.CancelWorkflowRunReq
+	var err error
+	defer func() {
+		if err != nil {
+			logger.Errorf(c, "CancelWorkflowRun req:%+v err:%v", req, err)
+		}
+	}()
+
+	if err = c.ShouldBindJSON(&req); err != nil {
+		response.FailWithMessage(c, "参数绑定失败: "+err.Error())
+		return
+	}
+
+	ctx := contextx.ToContext(c)
+	if err = w.workflowService.CancelRun(ctx, &req); err != nil {
+		response.FailWithMessage(c, err.Error())
+		return
+	}
+	response.OkWithMessage(c, "取消成功")
+}
+
+// GetRuns 获取工作流运行记录
+// @Summary 获取工作流运行记录
+// @Description 获取工作流的运行记录（按创建时间倒序）
+// @Tags 工作流
+// @Accept json
+// @Produce json
+// @Param X-Token header string true "JWT Token"
+// @Param workflow_id query int true "工作流ID"
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页数量" default(20)
+// @Success 200 {object} dto.GetWorkflowRunsResp "获取成功"
+// @Failure 400 {string} string "请求参数错误"
+// @Failure 401 {string} string "未授权"
+// @Router /api/v1/workflow/runs [get]
+func (w *Workflow) GetRuns(c *gin.Context) {
+	var req dto.GetWorkflowRunsReq
+	var resp *dto.GetWorkflowRunsResp
+	var err error
+	defer func() {
+		if err != nil {
+			logger.Errorf(c, "GetWorkflowRuns req:%+v err:%v", req, err)
+		}
+	}()
+
+	if err = c.ShouldBindQuery(&req); err != nil {
+		response.FailWithMessage(c, "参数绑定失败: "+err.Error())
+		return
+	}
+
+	ctx := contextx.ToContext(c)
+	resp, err = w.workflowService.GetRuns(ctx, &req)
+	if err != nil {
+		response.FailWithMessage(c, err.Error())
+		return
+	}
+	response.OkWithData(c, resp)
+}
+
+// GetRun 获取工作流运行详情
+// @Summary 获取工作流运行详情
+// @Description 获取运行详情，包含每个步骤的请求、响应、调用次数和日志
+// @Tags 工作流
+// @Accept json
+// @Produce json
+// @Param X-Token header string true "JWT Token"
+// @Param id path int true "运行ID"
+// @Success 200 {object} dto.WorkflowRunInfo "获取成功"
+// @Failure 400 {string} string "请求参数错误"
+// @Failure 401 {string} string "未授权"
+// @Router /api/v1/workflow/run/{id} [get]
+func (w *Workflow) GetRun(c *gin.Context) {
+	var resp *dto.WorkflowRunInfo
+	var err error
+	defer func() {
+		if err != nil {
+			logger.Errorf(c, "GetWorkflowRun id:%s err:%v", c.Param("id"), err)
+		}
+	}()
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.FailWithMessage(c, "无效的运行ID")
+		return
+	}
+
+	ctx := contextx.ToContext(c)
+	resp, err = w.workflowService.GetRun(ctx, id)
+	if err != nil {
+		response.FailWithMessage(c, err.Error())
+		return
+	}
+	response.OkWithData(c, resp)
+}
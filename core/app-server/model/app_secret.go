@@ -0,0 +1,22 @@
+package model
+
+import (
+	"github.com/ai-agent-os/ai-agent-os/pkg/gormx/models"
+)
+
+// AppSecret 应用密钥表
+// 密钥值使用 AES-256-GCM 加密存储，任何接口都不回显明文
+// 应用容器启动时由 app-runtime 注入为环境变量，运行时 SDK 通过 ctx.Secret(name) 读取
+type AppSecret struct {
+	models.Base
+	AppID          int64  `json:"app_id" gorm:"not null;uniqueIndex:idx_app_secret_name;comment:应用ID"`
+	Name           string `json:"name" gorm:"type:varchar(128);not null;uniqueIndex:idx_app_secret_name;comment:密钥名称"`
+	EncryptedValue string `json:"-" gorm:"type:text;not null;comment:加密后的密钥值（base64）"` // ⭐ 不参与 JSON 序列化，避免意外泄露
+	Description    string `json:"description" gorm:"type:varchar(500);comment:描述"`
+	Version        int    `json:"version" gorm:"default:1;comment:版本号（每次轮换+1）"`
+}
+
+// TableName 指定表名
+func (AppSecret) TableName() string {
+	return "app_secret"
+}
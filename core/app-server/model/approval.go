@@ -0,0 +1,73 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/ai-agent-os/ai-agent-os/pkg/gormx/models"
+)
+
+// 审批任务状态
+const (
+	ApprovalTaskWaiting     = "waiting"     // 等待中（依次审批时排在后面的审批人）
+	ApprovalTaskPending     = "pending"     // 待审批
+	ApprovalTaskApproved    = "approved"    // 已通过
+	ApprovalTaskRejected    = "rejected"    // 已拒绝
+	ApprovalTaskTransferred = "transferred" // 已转交
+	ApprovalTaskEscalated   = "escalated"   // 超时已升级
+	ApprovalTaskCanceled    = "canceled"    // 已取消（或签其他人已通过、审批被拒绝或撤回）
+)
+
+// 审批结束回调状态
+const (
+	ApprovalCallbackNone    = ""        // 审批未结束
+	ApprovalCallbackSuccess = "success" // 回调成功
+	ApprovalCallbackFailed  = "failed"  // 回调失败
+)
+
+// ApprovalInstance 审批实例表（每次发起审批一条）
+type ApprovalInstance struct {
+	models.Base
+	TenantUser     string          `json:"tenant_user" gorm:"type:varchar(255);not null;comment:租户用户（app的所有者）"`
+	App            string          `json:"app" gorm:"type:varchar(100);not null;comment:应用名"`
+	FullCodePath   string          `json:"full_code_path" gorm:"type:varchar(500);not null;index:idx_approval_row;comment:发起审批的函数"`
+	RowID          int64           `json:"row_id" gorm:"type:bigint;index:idx_approval_row;comment:关联的记录ID（Form 函数为 0）"`
+	ProcessCode    string          `json:"process_code" gorm:"type:varchar(255);index;comment:流程编码"`
+	ProcessName    string          `json:"process_name" gorm:"type:varchar(255);comment:流程名称"`
+	Title          string          `json:"title" gorm:"type:varchar(500);comment:审批标题"`
+	Initiator      string          `json:"initiator" gorm:"type:varchar(255);not null;index;comment:发起人"`
+	Data           json.RawMessage `json:"data" gorm:"type:json;comment:业务数据"`
+	Definition     json.RawMessage `json:"definition" gorm:"type:json;comment:流程定义快照（包含发起时解析出的审批人）"`
+	CurrentNode    int             `json:"current_node" gorm:"comment:当前节点序号"`
+	Status         string          `json:"status" gorm:"type:varchar(20);not null;index;comment:审批状态"`
+	FinishedAt     *time.Time      `json:"finished_at" gorm:"comment:结束时间"`
+	CallbackStatus string          `json:"callback_status" gorm:"type:varchar(20);comment:结束回调状态"`
+	TraceID        string          `json:"trace_id" gorm:"type:varchar(100);comment:发起审批的链路ID"`
+	Version        string          `json:"version" gorm:"type:varchar(50);comment:发起时的应用版本"`
+}
+
+// TableName 指定表名
+func (ApprovalInstance) TableName() string {
+	return "approval_instance"
+}
+
+// ApprovalTask 审批任务表（每个节点的每个审批人一条，转交和升级会生成新任务）
+type ApprovalTask struct {
+	models.Base
+	InstanceID    int64      `json:"instance_id" gorm:"not null;index;comment:审批实例ID"`
+	NodeIndex     int        `json:"node_index" gorm:"comment:节点序号"`
+	NodeName      string     `json:"node_name" gorm:"type:varchar(255);comment:节点名称"`
+	Seq           int        `json:"seq" gorm:"comment:节点内顺序（依次审批时使用）"`
+	Assignee      string     `json:"assignee" gorm:"type:varchar(255);not null;index:idx_approval_task_assignee;comment:审批人"`
+	Status        string     `json:"status" gorm:"type:varchar(20);not null;index:idx_approval_task_assignee;comment:任务状态"`
+	Comment       string     `json:"comment" gorm:"type:text;comment:审批意见"`
+	TransferredBy string     `json:"transferred_by" gorm:"type:varchar(255);comment:由谁转交或升级而来"`
+	EscalateTo    string     `json:"escalate_to" gorm:"type:varchar(255);comment:超时升级给谁（创建任务时确定）"`
+	DueAt         *time.Time `json:"due_at" gorm:"index;comment:超时时间"`
+	ActedAt       *time.Time `json:"acted_at" gorm:"comment:处理时间"`
+}
+
+// TableName 指定表名
+func (ApprovalTask) TableName() string {
+	return "approval_task"
+}
@@ -0,0 +1,22 @@
+package model
+
+import (
+	"encoding/json"
+
+	"github.com/ai-agent-os/ai-agent-os/pkg/gormx/models"
+)
+
+// FormDraft 向导表单的草稿（每个用户每个表单函数一份），创建人即 CreatedBy
+// 用户填写到一半离开后可以从保存的步骤继续填写，提交成功后删除
+type FormDraft struct {
+	models.Base
+	AppID        int64           `json:"app_id" gorm:"not null;index;comment:应用ID"`
+	FullCodePath string          `json:"full_code_path" gorm:"type:varchar(500);not null;index;comment:表单函数完整路径"`
+	Step         string          `json:"step" gorm:"type:varchar(100);comment:当前步骤名称"`
+	Data         json.RawMessage `json:"data" gorm:"type:json;comment:已填写的数据"`
+}
+
+// TableName 指定表名
+func (FormDraft) TableName() string {
+	return "form_draft"
+}
@@ -8,17 +8,20 @@ import (
 
 type Function struct {
 	models.Base
-	Request      json.RawMessage `json:"request" gorm:"type:json"`
-	Response     json.RawMessage `json:"response" gorm:"type:json"`
-	AppID        int64           `json:"app_id"`
-	TreeID       int64           `json:"tree_id"`
-	Method       string          `json:"method" gorm:"type:varchar(255);column:method"`
-	Router       string          `json:"router" gorm:"type:varchar(255);column:router"`
-	HasConfig    bool            `json:"has_config" gorm:"column:has_config;comment:是否存在配置"` // 是否存在配置
-	CreateTables string          `json:"create_tables"`                                      //创建该api时候会自动帮忙创建这个数据库表gorm的model列表
-	Callbacks    string          `json:"callbacks"`
-	TemplateType string          `json:"widget"`                                  // 渲染类型
-	App          *App            `json:"-" gorm:"foreignKey:AppID;references:ID"` // 预加载的完整应用对象
+	Request        json.RawMessage `json:"request" gorm:"type:json"`
+	Response       json.RawMessage `json:"response" gorm:"type:json"`
+	AppID          int64           `json:"app_id"`
+	TreeID         int64           `json:"tree_id"`
+	Method         string          `json:"method" gorm:"type:varchar(255);column:method"`
+	Router         string          `json:"router" gorm:"type:varchar(255);column:router"`
+	HasConfig      bool            `json:"has_config" gorm:"column:has_config;comment:是否存在配置"` // 是否存在配置
+	Config         json.RawMessage `json:"config" gorm:"type:json;comment:运行时配置结构（widget字段）"`  // 运行时配置结构（BaseConfig.Config 解析出的字段）
+	ConfigDefault  json.RawMessage `json:"config_default" gorm:"type:json;comment:运行时配置默认值"`   // 运行时配置默认值（代码中声明的值）
+	TemplateConfig json.RawMessage `json:"template_config" gorm:"type:json;comment:模板配置"`      // 模板配置（看板的分列字段、日历的时间字段等）
+	CreateTables   string          `json:"create_tables"`                                      //创建该api时候会自动帮忙创建这个数据库表gorm的model列表
+	Callbacks      string          `json:"callbacks"`
+	TemplateType   string          `json:"widget"`                                  // 渲染类型
+	App            *App            `json:"-" gorm:"foreignKey:AppID;references:ID"` // 预加载的完整应用对象
 }
 
 func (Function) TableName() string {
@@ -0,0 +1,26 @@
+package model
+
+import (
+	"encoding/json"
+
+	"github.com/ai-agent-os/ai-agent-os/pkg/gormx/models"
+)
+
+// FunctionConfigVersion 函数运行时配置版本表
+// 每次修改/回滚都会新增一个版本，IsCurrent 标记当前生效的版本
+// 配置结构由 SDK 中的 BaseConfig.Config 声明，存储在 Function.Config 中
+type FunctionConfigVersion struct {
+	models.Base
+	AppID        int64           `json:"app_id" gorm:"not null;index;comment:应用ID"`
+	FullCodePath string          `json:"full_code_path" gorm:"type:varchar(500);not null;uniqueIndex:idx_config_path_version;comment:函数完整路径"`
+	Version      int             `json:"version" gorm:"not null;uniqueIndex:idx_config_path_version;comment:配置版本号（从1开始递增）"`
+	Values       json.RawMessage `json:"values" gorm:"type:json;comment:配置值"`
+	Remark       string          `json:"remark" gorm:"type:varchar(500);comment:变更说明"`
+	RollbackFrom int             `json:"rollback_from" gorm:"default:0;comment:回滚来源版本（0表示非回滚）"`
+	IsCurrent    bool            `json:"is_current" gorm:"default:false;index;comment:是否为当前生效版本"`
+}
+
+// TableName 指定表名
+func (FunctionConfigVersion) TableName() string {
+	return "function_config_version"
+}
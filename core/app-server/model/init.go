@@ -25,6 +25,24 @@ func InitTables(db *gorm.DB) error {
 		&FormOperateLog{},
 		// 目录更新历史表（用于记录API变更历史）
 		&DirectoryUpdateHistory{},
+		// 应用密钥表（加密存储）
+		&AppSecret{},
+		// 函数运行时配置版本表
+		&FunctionConfigVersion{},
+		// 表格保存的视图
+		&TableView{},
+		// 向导表单的草稿
+		&FormDraft{},
+		// 通知中心
+		&Notification{},
+		&NotificationPreference{},
+		// 审批流程
+		&ApprovalInstance{},
+		&ApprovalTask{},
+		// 工作流
+		&Workflow{},
+		&WorkflowRun{},
+		&WorkflowStepRun{},
 	)
 	if err != nil {
 		return err
@@ -0,0 +1,61 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/ai-agent-os/ai-agent-os/pkg/gormx/models"
+)
+
+// 通知投递状态（邮件、Webhook）
+const (
+	NotificationDeliverySkipped = "skipped" // 未开启该渠道
+	NotificationDeliveryPending = "pending" // 投递中
+	NotificationDeliverySent    = "sent"    // 投递成功
+	NotificationDeliveryFailed  = "failed"  // 投递失败（已重试）
+)
+
+// Notification 站内通知表（每个接收人一条）
+type Notification struct {
+	models.Base
+	Username      string          `json:"username" gorm:"type:varchar(255);not null;index:idx_notification_user_read;comment:接收人"`
+	App           string          `json:"app" gorm:"type:varchar(255);index;comment:发送通知的应用（user/app）"`
+	FullCodePath  string          `json:"full_code_path" gorm:"type:varchar(500);comment:发送通知的函数"`
+	TemplateCode  string          `json:"template_code" gorm:"type:varchar(255);index;comment:通知模板编码"`
+	Title         string          `json:"title" gorm:"type:varchar(500);comment:标题"`
+	Content       string          `json:"content" gorm:"type:text;comment:内容"`
+	Level         string          `json:"level" gorm:"type:varchar(20);default:'info';comment:级别"`
+	Link          string          `json:"link" gorm:"type:varchar(1000);comment:跳转链接"`
+	Data          json.RawMessage `json:"data" gorm:"type:json;comment:模板数据"`
+	IsRead        bool            `json:"is_read" gorm:"default:false;index:idx_notification_user_read;comment:是否已读"`
+	ReadAt        *time.Time      `json:"read_at" gorm:"comment:阅读时间"`
+	EmailStatus   string          `json:"email_status" gorm:"type:varchar(20);comment:邮件投递状态"`
+	WebhookStatus string          `json:"webhook_status" gorm:"type:varchar(20);comment:Webhook投递状态"`
+	TraceID       string          `json:"trace_id" gorm:"type:varchar(100);comment:链路ID"`
+}
+
+// TableName 指定表名
+func (Notification) TableName() string {
+	return "notification"
+}
+
+// NotificationPreference 用户通知偏好表（每个用户一条，没有记录时使用默认偏好：只开启站内通知）
+type NotificationPreference struct {
+	models.Base
+	Username       string `json:"username" gorm:"type:varchar(255);not null;uniqueIndex;comment:用户名"`
+	InAppEnabled   bool   `json:"in_app_enabled" gorm:"comment:站内通知"`
+	EmailEnabled   bool   `json:"email_enabled" gorm:"comment:邮件通知"`
+	WebhookEnabled bool   `json:"webhook_enabled" gorm:"comment:Webhook通知"`
+	WebhookURL     string `json:"webhook_url" gorm:"type:varchar(1000);comment:Webhook地址"`
+	WebhookSecret  string `json:"-" gorm:"type:varchar(255);comment:Webhook签名密钥（AES-GCM加密）"`
+}
+
+// TableName 指定表名
+func (NotificationPreference) TableName() string {
+	return "notification_preference"
+}
+
+// DefaultNotificationPreference 默认通知偏好
+func DefaultNotificationPreference(username string) *NotificationPreference {
+	return &NotificationPreference{Username: username, InAppEnabled: true}
+}
@@ -0,0 +1,38 @@
+package model
+
+import (
+	"encoding/json"
+
+	"github.com/ai-agent-os/ai-agent-os/pkg/gormx/models"
+)
+
+// 视图的可见范围
+const (
+	TableViewScopePrivate    = "private"    // 仅自己可见
+	TableViewScopeDepartment = "department" // 共享给创建人所在部门（含子部门）
+	TableViewScopeAll        = "all"        // 所有有表格查看权限的人可见
+)
+
+// TableView 表格函数保存的视图（个人筛选）
+// 保存过滤表达式、排序、显示的列（按顺序）和每页数量，创建人即 CreatedBy
+type TableView struct {
+	models.Base
+	AppID        int64           `json:"app_id" gorm:"not null;index;comment:应用ID"`
+	FullCodePath string          `json:"full_code_path" gorm:"type:varchar(500);not null;index;comment:表格函数完整路径"`
+	Name         string          `json:"name" gorm:"type:varchar(100);not null;comment:视图名称"`
+	Scope        string          `json:"scope" gorm:"type:varchar(20);not null;default:private;comment:可见范围 private/department/all"`
+	Department   string          `json:"department" gorm:"type:varchar(500);index;comment:共享的部门完整路径（scope=department）"`
+	Filter       string          `json:"filter" gorm:"type:text;comment:过滤表达式（紧凑格式）"`
+	Sorts        string          `json:"sorts" gorm:"type:varchar(500);comment:排序，如 id:desc"`
+	Columns      json.RawMessage `json:"columns" gorm:"type:json;comment:显示的列（按顺序）"`
+	PageSize     int             `json:"page_size" gorm:"default:0;comment:每页数量（0 表示默认）"`
+	IsDefault    bool            `json:"is_default" gorm:"default:false;comment:是否为所有人的默认视图"`
+
+	// InvalidFields 函数结构变更后视图引用的已删除字段（逗号分隔），为空表示视图有效，重新保存视图时清除
+	InvalidFields string `json:"invalid_fields" gorm:"type:varchar(1000);comment:引用的已删除字段"`
+}
+
+// TableName 指定表名
+func (TableView) TableName() string {
+	return "table_view"
+}
@@ -0,0 +1,69 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/ai-agent-os/ai-agent-os/pkg/gormx/models"
+)
+
+// Workflow 工作流表（跨应用的函数编排）
+type Workflow struct {
+	models.Base
+	Name            string          `json:"name" gorm:"type:varchar(255);not null;comment:名称"`
+	Description     string          `json:"description" gorm:"type:text;comment:描述"`
+	Definition      json.RawMessage `json:"definition" gorm:"type:json;comment:工作流定义（步骤DAG）"`
+	Triggers        json.RawMessage `json:"triggers" gorm:"type:json;comment:触发器"`
+	Enabled         bool            `json:"enabled" gorm:"index;comment:是否启用（只影响定时和表格事件触发）"`
+	LastScheduledAt *time.Time      `json:"last_scheduled_at" gorm:"comment:最近一次定时触发时间（用于多实例去重）"`
+}
+
+// TableName 指定表名
+func (Workflow) TableName() string {
+	return "workflow"
+}
+
+// WorkflowRun 工作流运行记录表
+// 运行由 app-server 后台按租约领取执行，实例重启或宕机后租约过期，会被其他实例接管并从未完成的步骤继续
+type WorkflowRun struct {
+	models.Base
+	WorkflowID  int64           `json:"workflow_id" gorm:"not null;index;comment:工作流ID"`
+	Definition  json.RawMessage `json:"definition" gorm:"type:json;comment:运行时的工作流定义快照"`
+	Status      string          `json:"status" gorm:"type:varchar(20);not null;index;comment:运行状态"`
+	TriggerType string          `json:"trigger_type" gorm:"type:varchar(20);comment:触发方式"`
+	RunAs       string          `json:"run_as" gorm:"type:varchar(255);comment:以谁的身份调用函数"`
+	Input       json.RawMessage `json:"input" gorm:"type:json;comment:触发数据"`
+	Error       string          `json:"error" gorm:"type:text;comment:失败原因"`
+	TraceID     string          `json:"trace_id" gorm:"type:varchar(100);comment:链路ID"`
+	LeaseOwner  string          `json:"lease_owner" gorm:"type:varchar(100);comment:执行实例"`
+	LeaseUntil  *time.Time      `json:"lease_until" gorm:"index;comment:租约到期时间"`
+	StartedAt   *time.Time      `json:"started_at" gorm:"comment:开始时间"`
+	FinishedAt  *time.Time      `json:"finished_at" gorm:"comment:结束时间"`
+}
+
+// TableName 指定表名
+func (WorkflowRun) TableName() string {
+	return "workflow_run"
+}
+
+// WorkflowStepRun 工作流步骤运行记录表（每次运行的每个步骤一条）
+type WorkflowStepRun struct {
+	models.Base
+	RunID        int64           `json:"run_id" gorm:"not null;uniqueIndex:idx_workflow_step_run;comment:运行ID"`
+	StepKey      string          `json:"step_key" gorm:"type:varchar(100);not null;uniqueIndex:idx_workflow_step_run;comment:步骤标识"`
+	StepName     string          `json:"step_name" gorm:"type:varchar(255);comment:步骤名称"`
+	FullCodePath string          `json:"full_code_path" gorm:"type:varchar(500);comment:调用的函数"`
+	Status       string          `json:"status" gorm:"type:varchar(20);not null;comment:步骤状态"`
+	Attempts     int             `json:"attempts" gorm:"comment:已调用次数"`
+	Request      json.RawMessage `json:"request" gorm:"type:json;comment:请求参数"`
+	Response     json.RawMessage `json:"response" gorm:"type:json;comment:响应结果"`
+	Error        string          `json:"error" gorm:"type:text;comment:失败原因"`
+	Logs         json.RawMessage `json:"logs" gorm:"type:json;comment:执行日志"`
+	StartedAt    *time.Time      `json:"started_at" gorm:"comment:开始时间"`
+	FinishedAt   *time.Time      `json:"finished_at" gorm:"comment:结束时间"`
+}
+
+// TableName 指定表名
+func (WorkflowStepRun) TableName() string {
+	return "workflow_step_run"
+}
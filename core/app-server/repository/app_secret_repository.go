@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"github.com/ai-agent-os/ai-agent-os/core/app-server/model"
+	"gorm.io/gorm"
+)
+
+// AppSecretRepository 应用密钥仓库
+type AppSecretRepository struct {
+	db *gorm.DB
+}
+
+// NewAppSecretRepository 创建应用密钥仓库
+func NewAppSecretRepository(db *gorm.DB) *AppSecretRepository {
+	return &AppSecretRepository{db: db}
+}
+
+// ListByAppID 获取应用的所有密钥（按名称排序）
+func (r *AppSecretRepository) ListByAppID(appID int64) ([]*model.AppSecret, error) {
+	var secrets []*model.AppSecret
+	err := r.db.Where("app_id = ?", appID).Order("name ASC").Find(&secrets).Error
+	return secrets, err
+}
+
+// GetByAppIDAndName 根据应用ID和名称获取密钥
+func (r *AppSecretRepository) GetByAppIDAndName(appID int64, name string) (*model.AppSecret, error) {
+	var secret model.AppSecret
+	err := r.db.Where("app_id = ? AND name = ?", appID, name).First(&secret).Error
+	if err != nil {
+		return nil, err
+	}
+	return &secret, nil
+}
+
+// Create 创建密钥
+func (r *AppSecretRepository) Create(secret *model.AppSecret) error {
+	return r.db.Create(secret).Error
+}
+
+// Update 更新密钥
+func (r *AppSecretRepository) Update(secret *model.AppSecret) error {
+	return r.db.Save(secret).Error
+}
+
+// Delete 删除密钥（物理删除，避免唯一索引冲突，也不在库中残留密文）
+func (r *AppSecretRepository) Delete(appID int64, name string) error {
+	return r.db.Unscoped().Where("app_id = ? AND name = ?", appID, name).Delete(&model.AppSecret{}).Error
+}
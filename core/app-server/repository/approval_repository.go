@@ -0,0 +1,192 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/ai-agent-os/ai-agent-os/core/app-server/model"
+	"gorm.io/gorm"
+)
+
+// ApprovalRepository 审批仓库
+type ApprovalRepository struct {
+	db *gorm.DB
+}
+
+// NewApprovalRepository 创建审批仓库
+func NewApprovalRepository(db *gorm.DB) *ApprovalRepository {
+	return &ApprovalRepository{db: db}
+}
+
+// CreateInstance 创建审批实例及第一个节点的审批任务
+func (r *ApprovalRepository) CreateInstance(instance *model.ApprovalInstance, tasks []*model.ApprovalTask) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(instance).Error; err != nil {
+			return err
+		}
+		for _, task := range tasks {
+			task.InstanceID = instance.ID
+		}
+		if len(tasks) == 0 {
+			return nil
+		}
+		return tx.Create(&tasks).Error
+	})
+}
+
+// GetInstance 获取审批实例
+func (r *ApprovalRepository) GetInstance(id int64) (*model.ApprovalInstance, error) {
+	var instance model.ApprovalInstance
+	if err := r.db.Where("id = ?", id).First(&instance).Error; err != nil {
+		return nil, err
+	}
+	return &instance, nil
+}
+
+// GetInstancesByIDs 批量获取审批实例
+func (r *ApprovalRepository) GetInstancesByIDs(ids []int64) ([]*model.ApprovalInstance, error) {
+	var instances []*model.ApprovalInstance
+	if len(ids) == 0 {
+		return instances, nil
+	}
+	err := r.db.Where("id IN ?", ids).Find(&instances).Error
+	return instances, err
+}
+
+// UpdateInstance 更新审批实例
+func (r *ApprovalRepository) UpdateInstance(id int64, updates map[string]interface{}) error {
+	return r.db.Model(&model.ApprovalInstance{}).Where("id = ?", id).Updates(updates).Error
+}
+
+// FinishInstance 结束审批实例（只有审批中的实例才能结束），返回是否结束成功
+// 多个 app-server 实例并发处理同一个审批时，只有一个能结束成功
+func (r *ApprovalRepository) FinishInstance(id int64, status string, finishedAt time.Time) (bool, error) {
+	result := r.db.Model(&model.ApprovalInstance{}).
+		Where("id = ? AND status = ?", id, "pending").
+		Updates(map[string]interface{}{"status": status, "finished_at": &finishedAt})
+	return result.RowsAffected > 0, result.Error
+}
+
+// ListInitiated 分页获取用户发起的审批（按创建时间倒序）
+func (r *ApprovalRepository) ListInitiated(username, status string, page, pageSize int) ([]*model.ApprovalInstance, int64, error) {
+	var instances []*model.ApprovalInstance
+	var total int64
+
+	query := r.db.Model(&model.ApprovalInstance{}).Where("initiator = ?", username)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	err := query.Order("id DESC").Offset(offset).Limit(pageSize).Find(&instances).Error
+	return instances, total, err
+}
+
+// ListByRow 获取某条记录的所有审批（按创建时间倒序）
+func (r *ApprovalRepository) ListByRow(fullCodePath string, rowID int64) ([]*model.ApprovalInstance, error) {
+	var instances []*model.ApprovalInstance
+	err := r.db.Where("full_code_path = ? AND row_id = ?", fullCodePath, rowID).
+		Order("id DESC").
+		Find(&instances).Error
+	return instances, err
+}
+
+// GetTask 获取审批任务
+func (r *ApprovalRepository) GetTask(id int64) (*model.ApprovalTask, error) {
+	var task model.ApprovalTask
+	if err := r.db.Where("id = ?", id).First(&task).Error; err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// CreateTasks 批量创建审批任务
+func (r *ApprovalRepository) CreateTasks(tasks []*model.ApprovalTask) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+	return r.db.Create(&tasks).Error
+}
+
+// ListTasksByInstances 获取审批实例的所有任务（按节点、顺序、创建时间排序）
+func (r *ApprovalRepository) ListTasksByInstances(instanceIDs []int64) ([]*model.ApprovalTask, error) {
+	var tasks []*model.ApprovalTask
+	if len(instanceIDs) == 0 {
+		return tasks, nil
+	}
+	err := r.db.Where("instance_id IN ?", instanceIDs).
+		Order("instance_id ASC, node_index ASC, seq ASC, id ASC").
+		Find(&tasks).Error
+	return tasks, err
+}
+
+// ListNodeTasks 获取审批实例某个节点的所有任务
+func (r *ApprovalRepository) ListNodeTasks(instanceID int64, nodeIndex int) ([]*model.ApprovalTask, error) {
+	var tasks []*model.ApprovalTask
+	err := r.db.Where("instance_id = ? AND node_index = ?", instanceID, nodeIndex).
+		Order("seq ASC, id ASC").
+		Find(&tasks).Error
+	return tasks, err
+}
+
+// ListAssigneeTasks 分页获取用户的审批任务，done 为 false 时获取待办，为 true 时获取已办
+func (r *ApprovalRepository) ListAssigneeTasks(username string, done bool, page, pageSize int) ([]*model.ApprovalTask, int64, error) {
+	var tasks []*model.ApprovalTask
+	var total int64
+
+	query := r.db.Model(&model.ApprovalTask{}).Where("assignee = ?", username)
+	if done {
+		query = query.Where("status IN ?", []string{
+			model.ApprovalTaskApproved, model.ApprovalTaskRejected,
+			model.ApprovalTaskTransferred, model.ApprovalTaskEscalated,
+		})
+	} else {
+		query = query.Where("status = ?", model.ApprovalTaskPending)
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	err := query.Order("id DESC").Offset(offset).Limit(pageSize).Find(&tasks).Error
+	return tasks, total, err
+}
+
+// ListOverdueTasks 获取已超时的待审批任务
+func (r *ApprovalRepository) ListOverdueTasks(now time.Time, limit int) ([]*model.ApprovalTask, error) {
+	var tasks []*model.ApprovalTask
+	err := r.db.Where("status = ? AND due_at IS NOT NULL AND due_at <= ?", model.ApprovalTaskPending, now).
+		Order("due_at ASC").
+		Limit(limit).
+		Find(&tasks).Error
+	return tasks, err
+}
+
+// TransitTask 将任务从 from 状态更新为 updates 中的状态（条件更新），返回是否更新成功
+// 用于防止同一个任务被重复处理（并发审批、多个 app-server 实例同时处理超时）
+func (r *ApprovalRepository) TransitTask(id int64, from string, updates map[string]interface{}) (bool, error) {
+	result := r.db.Model(&model.ApprovalTask{}).
+		Where("id = ? AND status = ?", id, from).
+		Updates(updates)
+	return result.RowsAffected > 0, result.Error
+}
+
+// CancelOpenTasks 取消审批实例中所有未处理的任务（nodeIndex < 0 表示所有节点）
+func (r *ApprovalRepository) CancelOpenTasks(instanceID int64, nodeIndex int) error {
+	query := r.db.Model(&model.ApprovalTask{}).
+		Where("instance_id = ? AND status IN ?", instanceID, []string{model.ApprovalTaskWaiting, model.ApprovalTaskPending})
+	if nodeIndex >= 0 {
+		query = query.Where("node_index = ?", nodeIndex)
+	}
+	return query.Update("status", model.ApprovalTaskCanceled).Error
+}
+
+// AdvanceNode 将审批实例从 from 节点推进到 to 节点（条件更新），返回是否推进成功
+func (r *ApprovalRepository) AdvanceNode(id int64, from, to int) (bool, error) {
+	result := r.db.Model(&model.ApprovalInstance{}).
+		Where("id = ? AND current_node = ? AND status = ?", id, from, "pending").
+		Update("current_node", to)
+	return result.RowsAffected > 0, result.Error
+}
@@ -72,3 +72,15 @@ func (r *CasbinRuleRepository) GetPermissionsByAppIDAndUser(appID int64, usernam
 	return rules, nil
 }
 
+// ListUsersByAppID 查询在指定工作空间下拥有任意权限的用户（去重）
+func (r *CasbinRuleRepository) ListUsersByAppID(appID int64) ([]string, error) {
+	var users []string
+	err := r.db.Table("casbin_rule").
+		Where("app_id = ? AND ptype = 'p'", appID).
+		Distinct().
+		Pluck("v0", &users).Error
+	if err != nil {
+		return nil, err
+	}
+	return users, nil
+}
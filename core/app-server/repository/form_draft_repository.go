@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"github.com/ai-agent-os/ai-agent-os/core/app-server/model"
+	"gorm.io/gorm"
+)
+
+// FormDraftRepository 向导表单草稿仓库
+type FormDraftRepository struct {
+	db *gorm.DB
+}
+
+// NewFormDraftRepository 创建向导表单草稿仓库
+func NewFormDraftRepository(db *gorm.DB) *FormDraftRepository {
+	return &FormDraftRepository{db: db}
+}
+
+// Get 获取用户在表单函数上的草稿
+func (r *FormDraftRepository) Get(fullCodePath, username string) (*model.FormDraft, error) {
+	var draft model.FormDraft
+	err := r.db.Where("full_code_path = ? AND created_by = ?", fullCodePath, username).
+		Order("id DESC").
+		First(&draft).Error
+	if err != nil {
+		return nil, err
+	}
+	return &draft, nil
+}
+
+// Save 创建或更新草稿
+func (r *FormDraftRepository) Save(draft *model.FormDraft) error {
+	return r.db.Save(draft).Error
+}
+
+// Delete 删除用户在表单函数上的草稿（草稿不需要保留，直接物理删除）
+func (r *FormDraftRepository) Delete(fullCodePath, username string) error {
+	return r.db.Unscoped().
+		Where("full_code_path = ? AND created_by = ?", fullCodePath, username).
+		Delete(&model.FormDraft{}).Error
+}
+
+// DeleteByFullCodePaths 删除多个函数的所有草稿（函数删除时使用）
+func (r *FormDraftRepository) DeleteByFullCodePaths(fullCodePaths []string) error {
+	return r.db.Unscoped().Where("full_code_path IN ?", fullCodePaths).Delete(&model.FormDraft{}).Error
+}
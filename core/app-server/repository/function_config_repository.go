@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ai-agent-os/ai-agent-os/core/app-server/model"
+	"gorm.io/gorm"
+)
+
+// FunctionConfigRepository 函数运行时配置版本仓库
+type FunctionConfigRepository struct {
+	db *gorm.DB
+}
+
+// NewFunctionConfigRepository 创建函数运行时配置版本仓库
+func NewFunctionConfigRepository(db *gorm.DB) *FunctionConfigRepository {
+	return &FunctionConfigRepository{db: db}
+}
+
+// GetCurrent 获取函数当前生效的配置版本
+func (r *FunctionConfigRepository) GetCurrent(fullCodePath string) (*model.FunctionConfigVersion, error) {
+	var version model.FunctionConfigVersion
+	err := r.db.Where("full_code_path = ? AND is_current = ?", fullCodePath, true).First(&version).Error
+	if err != nil {
+		return nil, err
+	}
+	return &version, nil
+}
+
+// GetByVersion 获取指定版本
+func (r *FunctionConfigRepository) GetByVersion(fullCodePath string, version int) (*model.FunctionConfigVersion, error) {
+	var v model.FunctionConfigVersion
+	err := r.db.Where("full_code_path = ? AND version = ?", fullCodePath, version).First(&v).Error
+	if err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+// ListVersions 分页获取配置版本历史（按版本号倒序）
+func (r *FunctionConfigRepository) ListVersions(fullCodePath string, page, pageSize int) ([]*model.FunctionConfigVersion, int64, error) {
+	var versions []*model.FunctionConfigVersion
+	var total int64
+
+	query := r.db.Model(&model.FunctionConfigVersion{}).Where("full_code_path = ?", fullCodePath)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	err := query.Order("version DESC").Offset(offset).Limit(pageSize).Find(&versions).Error
+	return versions, total, err
+}
+
+// createVersionMaxAttempts 并发创建版本时版本号冲突的最大尝试次数
+const createVersionMaxAttempts = 3
+
+// CreateVersion 创建新版本并设置为当前版本（版本号自动递增）
+// (full_code_path, version) 唯一，并发更新导致版本号冲突时整个事务回滚并重试
+func (r *FunctionConfigRepository) CreateVersion(version *model.FunctionConfigVersion) error {
+	var err error
+	for attempt := 1; attempt <= createVersionMaxAttempts; attempt++ {
+		version.ID = 0
+		err = r.db.Transaction(func(tx *gorm.DB) error {
+			var maxVersion int
+			err := tx.Model(&model.FunctionConfigVersion{}).
+				Where("full_code_path = ?", version.FullCodePath).
+				Select("COALESCE(MAX(version), 0)").
+				Scan(&maxVersion).Error
+			if err != nil {
+				return err
+			}
+
+			err = tx.Model(&model.FunctionConfigVersion{}).
+				Where("full_code_path = ? AND is_current = ?", version.FullCodePath, true).
+				Update("is_current", false).Error
+			if err != nil {
+				return err
+			}
+
+			version.Version = maxVersion + 1
+			version.IsCurrent = true
+			return tx.Create(version).Error
+		})
+		if err == nil || !isDuplicateKeyError(err) {
+			return err
+		}
+	}
+	return fmt.Errorf("配置版本号冲突，请稍后重试: %w", err)
+}
+
+// isDuplicateKeyError 判断是否为唯一索引冲突（MySQL / SQLite）
+func isDuplicateKeyError(err error) bool {
+	msg := err.Error()
+	return strings.Contains(msg, "Duplicate entry") || strings.Contains(msg, "UNIQUE constraint failed")
+}
@@ -32,6 +32,9 @@ func (r *FunctionRepository) UpdateFunctions(functions []*model.Function) error
 				"request":       function.Request,
 				"response":      function.Response,
 				"has_config":    function.HasConfig,
+				"config":        function.Config,
+				"config_default": function.ConfigDefault,
+				"template_config": function.TemplateConfig,
 				"create_tables": function.CreateTables,
 				"callbacks":     function.Callbacks,
 				"template_type": function.TemplateType,
@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/ai-agent-os/ai-agent-os/core/app-server/model"
+	"gorm.io/gorm"
+)
+
+// NotificationRepository 通知仓库
+type NotificationRepository struct {
+	db *gorm.DB
+}
+
+// NewNotificationRepository 创建通知仓库
+func NewNotificationRepository(db *gorm.DB) *NotificationRepository {
+	return &NotificationRepository{db: db}
+}
+
+// CreateNotifications 批量创建通知
+func (r *NotificationRepository) CreateNotifications(notifications []*model.Notification) error {
+	if len(notifications) == 0 {
+		return nil
+	}
+	return r.db.Create(&notifications).Error
+}
+
+// ListByUsername 分页获取用户的通知（按创建时间倒序）
+func (r *NotificationRepository) ListByUsername(username string, unreadOnly bool, page, pageSize int) ([]*model.Notification, int64, error) {
+	var notifications []*model.Notification
+	var total int64
+
+	query := r.db.Model(&model.Notification{}).Where("username = ?", username)
+	if unreadOnly {
+		query = query.Where("is_read = ?", false)
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	err := query.Order("id DESC").Offset(offset).Limit(pageSize).Find(&notifications).Error
+	return notifications, total, err
+}
+
+// CountUnread 获取用户未读通知数量
+func (r *NotificationRepository) CountUnread(username string) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.Notification{}).
+		Where("username = ? AND is_read = ?", username, false).
+		Count(&count).Error
+	return count, err
+}
+
+// MarkRead 标记通知为已读（只能标记自己的通知），ids 为空时标记全部
+func (r *NotificationRepository) MarkRead(username string, ids []int64) error {
+	query := r.db.Model(&model.Notification{}).Where("username = ? AND is_read = ?", username, false)
+	if len(ids) > 0 {
+		query = query.Where("id IN ?", ids)
+	}
+	now := time.Now()
+	return query.Updates(map[string]interface{}{"is_read": true, "read_at": &now}).Error
+}
+
+// UpdateDeliveryStatus 更新投递状态（column: email_status / webhook_status）
+func (r *NotificationRepository) UpdateDeliveryStatus(id int64, column, status string) error {
+	return r.db.Model(&model.Notification{}).Where("id = ?", id).Update(column, status).Error
+}
+
+// GetPreference 获取用户通知偏好，不存在时返回 gorm.ErrRecordNotFound
+func (r *NotificationRepository) GetPreference(username string) (*model.NotificationPreference, error) {
+	var pref model.NotificationPreference
+	err := r.db.Where("username = ?", username).First(&pref).Error
+	if err != nil {
+		return nil, err
+	}
+	return &pref, nil
+}
+
+// GetPreferences 批量获取用户通知偏好
+func (r *NotificationRepository) GetPreferences(usernames []string) ([]*model.NotificationPreference, error) {
+	var prefs []*model.NotificationPreference
+	if len(usernames) == 0 {
+		return prefs, nil
+	}
+	err := r.db.Where("username IN ?", usernames).Find(&prefs).Error
+	return prefs, err
+}
+
+// SavePreference 保存用户通知偏好（不存在则创建）
+func (r *NotificationRepository) SavePreference(pref *model.NotificationPreference) error {
+	return r.db.Save(pref).Error
+}
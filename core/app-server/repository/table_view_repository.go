@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"github.com/ai-agent-os/ai-agent-os/core/app-server/model"
+	"gorm.io/gorm"
+)
+
+// TableViewRepository 表格视图仓库
+type TableViewRepository struct {
+	db *gorm.DB
+}
+
+// NewTableViewRepository 创建表格视图仓库
+func NewTableViewRepository(db *gorm.DB) *TableViewRepository {
+	return &TableViewRepository{db: db}
+}
+
+// GetByID 根据ID获取视图
+func (r *TableViewRepository) GetByID(id int64) (*model.TableView, error) {
+	var view model.TableView
+	if err := r.db.First(&view, id).Error; err != nil {
+		return nil, err
+	}
+	return &view, nil
+}
+
+// ListVisible 获取用户可见的视图：自己创建的、共享给所在部门（departments 为用户部门及其所有上级部门）的、共享给所有人的
+// 默认视图排在最前面，其余按创建顺序
+func (r *TableViewRepository) ListVisible(fullCodePath, username string, departments []string) ([]*model.TableView, error) {
+	visible := r.db.Where("created_by = ?", username).Or("scope = ?", model.TableViewScopeAll)
+	if len(departments) > 0 {
+		visible = visible.Or("scope = ? AND department IN ?", model.TableViewScopeDepartment, departments)
+	}
+
+	var views []*model.TableView
+	err := r.db.Where("full_code_path = ?", fullCodePath).
+		Where(visible).
+		Order("is_default DESC, id ASC").
+		Find(&views).Error
+	return views, err
+}
+
+// ListByFullCodePaths 获取多个函数的所有视图
+func (r *TableViewRepository) ListByFullCodePaths(fullCodePaths []string) ([]*model.TableView, error) {
+	var views []*model.TableView
+	err := r.db.Where("full_code_path IN ?", fullCodePaths).Find(&views).Error
+	return views, err
+}
+
+// Save 创建或更新视图，设置为默认视图时取消该函数其他视图的默认标记
+func (r *TableViewRepository) Save(view *model.TableView) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if view.IsDefault {
+			err := tx.Model(&model.TableView{}).
+				Where("full_code_path = ? AND is_default = ? AND id <> ?", view.FullCodePath, true, view.ID).
+				Update("is_default", false).Error
+			if err != nil {
+				return err
+			}
+		}
+		return tx.Save(view).Error
+	})
+}
+
+// UpdateInvalidFields 更新视图引用的已删除字段
+func (r *TableViewRepository) UpdateInvalidFields(id int64, invalidFields string) error {
+	return r.db.Model(&model.TableView{}).Where("id = ?", id).Update("invalid_fields", invalidFields).Error
+}
+
+// Delete 删除视图
+func (r *TableViewRepository) Delete(id int64) error {
+	return r.db.Delete(&model.TableView{}, id).Error
+}
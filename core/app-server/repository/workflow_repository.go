@@ -0,0 +1,175 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/ai-agent-os/ai-agent-os/core/app-server/model"
+	"gorm.io/gorm"
+)
+
+// WorkflowRepository 工作流仓库
+type WorkflowRepository struct {
+	db *gorm.DB
+}
+
+// NewWorkflowRepository 创建工作流仓库
+func NewWorkflowRepository(db *gorm.DB) *WorkflowRepository {
+	return &WorkflowRepository{db: db}
+}
+
+// CreateWorkflow 创建工作流
+func (r *WorkflowRepository) CreateWorkflow(workflow *model.Workflow) error {
+	return r.db.Create(workflow).Error
+}
+
+// UpdateWorkflow 更新工作流
+func (r *WorkflowRepository) UpdateWorkflow(id int64, updates map[string]interface{}) error {
+	return r.db.Model(&model.Workflow{}).Where("id = ?", id).Updates(updates).Error
+}
+
+// DeleteWorkflow 删除工作流
+func (r *WorkflowRepository) DeleteWorkflow(id int64) error {
+	return r.db.Where("id = ?", id).Delete(&model.Workflow{}).Error
+}
+
+// GetWorkflow 获取工作流
+func (r *WorkflowRepository) GetWorkflow(id int64) (*model.Workflow, error) {
+	var workflow model.Workflow
+	if err := r.db.Where("id = ?", id).First(&workflow).Error; err != nil {
+		return nil, err
+	}
+	return &workflow, nil
+}
+
+// ListWorkflows 分页获取用户创建的工作流
+func (r *WorkflowRepository) ListWorkflows(createdBy, keyword string, page, pageSize int) ([]*model.Workflow, int64, error) {
+	var workflows []*model.Workflow
+	var total int64
+
+	query := r.db.Model(&model.Workflow{}).Where("created_by = ?", createdBy)
+	if keyword != "" {
+		query = query.Where("name LIKE ?", "%"+keyword+"%")
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	err := query.Order("id DESC").Offset(offset).Limit(pageSize).Find(&workflows).Error
+	return workflows, total, err
+}
+
+// ListEnabledWorkflows 获取已启用的工作流，triggerKeyword 不为空时按触发器内容预筛选
+func (r *WorkflowRepository) ListEnabledWorkflows(triggerKeyword string) ([]*model.Workflow, error) {
+	var workflows []*model.Workflow
+	query := r.db.Where("enabled = ?", true)
+	if triggerKeyword != "" {
+		query = query.Where("triggers LIKE ?", "%"+triggerKeyword+"%")
+	}
+	err := query.Find(&workflows).Error
+	return workflows, err
+}
+
+// ClaimSchedule 领取某一分钟的定时触发（条件更新），返回是否领取成功
+// 多个 app-server 实例同时检查定时触发时，只有一个能领取成功
+func (r *WorkflowRepository) ClaimSchedule(id int64, scheduledAt time.Time) (bool, error) {
+	result := r.db.Model(&model.Workflow{}).
+		Where("id = ? AND (last_scheduled_at IS NULL OR last_scheduled_at < ?)", id, scheduledAt).
+		Update("last_scheduled_at", &scheduledAt)
+	return result.RowsAffected > 0, result.Error
+}
+
+// CreateRun 创建运行记录
+func (r *WorkflowRepository) CreateRun(run *model.WorkflowRun) error {
+	return r.db.Create(run).Error
+}
+
+// GetRun 获取运行记录
+func (r *WorkflowRepository) GetRun(id int64) (*model.WorkflowRun, error) {
+	var run model.WorkflowRun
+	if err := r.db.Where("id = ?", id).First(&run).Error; err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
+
+// ListRuns 分页获取工作流的运行记录
+func (r *WorkflowRepository) ListRuns(workflowID int64, page, pageSize int) ([]*model.WorkflowRun, int64, error) {
+	var runs []*model.WorkflowRun
+	var total int64
+
+	query := r.db.Model(&model.WorkflowRun{}).Where("workflow_id = ?", workflowID)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	err := query.Order("id DESC").Offset(offset).Limit(pageSize).Find(&runs).Error
+	return runs, total, err
+}
+
+// ListClaimableRuns 获取可领取的运行（等待执行的，或执行中但租约已过期的）
+func (r *WorkflowRepository) ListClaimableRuns(now time.Time, limit int) ([]*model.WorkflowRun, error) {
+	var runs []*model.WorkflowRun
+	err := r.db.Where("status = ? OR (status = ? AND lease_until < ?)", "pending", "running", now).
+		Order("id ASC").
+		Limit(limit).
+		Find(&runs).Error
+	return runs, err
+}
+
+// ClaimRun 领取运行（条件更新），返回是否领取成功
+func (r *WorkflowRepository) ClaimRun(id int64, owner string, now, leaseUntil time.Time) (bool, error) {
+	result := r.db.Model(&model.WorkflowRun{}).
+		Where("id = ? AND (status = ? OR (status = ? AND lease_until < ?))", id, "pending", "running", now).
+		Updates(map[string]interface{}{
+			"status":      "running",
+			"lease_owner": owner,
+			"lease_until": &leaseUntil,
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// RenewLease 续约（只有租约持有者且仍在执行中才能续约），返回是否续约成功
+func (r *WorkflowRepository) RenewLease(id int64, owner string, leaseUntil time.Time) (bool, error) {
+	result := r.db.Model(&model.WorkflowRun{}).
+		Where("id = ? AND lease_owner = ? AND status = ?", id, owner, "running").
+		Update("lease_until", &leaseUntil)
+	return result.RowsAffected > 0, result.Error
+}
+
+// UpdateRun 更新运行记录
+func (r *WorkflowRepository) UpdateRun(id int64, updates map[string]interface{}) error {
+	return r.db.Model(&model.WorkflowRun{}).Where("id = ?", id).Updates(updates).Error
+}
+
+// CancelRun 取消运行（只能取消未结束的运行），返回是否取消成功
+func (r *WorkflowRepository) CancelRun(id int64, finishedAt time.Time) (bool, error) {
+	result := r.db.Model(&model.WorkflowRun{}).
+		Where("id = ? AND status IN ?", id, []string{"pending", "running"}).
+		Updates(map[string]interface{}{"status": "canceled", "finished_at": &finishedAt})
+	return result.RowsAffected > 0, result.Error
+}
+
+// ListStepRuns 获取运行的所有步骤记录
+func (r *WorkflowRepository) ListStepRuns(runIDs []int64) ([]*model.WorkflowStepRun, error) {
+	var stepRuns []*model.WorkflowStepRun
+	if len(runIDs) == 0 {
+		return stepRuns, nil
+	}
+	err := r.db.Where("run_id IN ?", runIDs).Order("id ASC").Find(&stepRuns).Error
+	return stepRuns, err
+}
+
+// SaveStepRun 保存步骤记录（不存在时创建）
+func (r *WorkflowRepository) SaveStepRun(stepRun *model.WorkflowStepRun) error {
+	return r.db.Save(stepRun).Error
+}
+
+// FinishRun 结束运行（只有租约持有者且仍在执行中才能结束），返回是否结束成功
+func (r *WorkflowRepository) FinishRun(id int64, owner, status, errMsg string, finishedAt time.Time) (bool, error) {
+	result := r.db.Model(&model.WorkflowRun{}).
+		Where("id = ? AND lease_owner = ? AND status = ?", id, owner, "running").
+		Updates(map[string]interface{}{"status": status, "error": errMsg, "finished_at": &finishedAt})
+	return result.RowsAffected > 0, result.Error
+}
@@ -49,6 +49,11 @@ func (s *Server) setupRoutes() {
 	app.POST("/update/:app", middleware2.CheckAppUpdate(), appHandler.UpdateApp)
 	// ⭐ 添加应用删除权限检查
 	app.DELETE("/delete/:app", middleware2.CheckAppDelete(), appHandler.DeleteApp)
+	// 应用密钥管理（需要应用更新权限，密钥值不回显）
+	appSecretHandler := v1.NewAppSecret(s.appSecretService)
+	app.GET("/secret/list/:app", middleware2.CheckAppUpdate(), appSecretHandler.ListSecrets)
+	app.POST("/secret/set/:app", middleware2.CheckAppUpdate(), appSecretHandler.SetSecret)
+	app.DELETE("/secret/delete/:app", middleware2.CheckAppUpdate(), appSecretHandler.DeleteSecret)
 	// 支持所有 HTTP 方法的请求应用接口
 	request := apiV1.Group("/run")
 	request.Use(middleware2.JWTAuth())
@@ -142,11 +147,30 @@ func (s *Server) setupRoutes() {
 	table.POST("/batch-create/*full-code-path", middleware2.CheckTableWrite(), standardAPI.TableBatchCreate) // Table 批量导入
 	table.PUT("/update/*full-code-path", middleware2.CheckTableUpdate(), standardAPI.TableUpdate)          // Table 更新
 	table.DELETE("/delete/*full-code-path", middleware2.CheckTableDelete(), standardAPI.TableDelete)        // Table 删除
+	table.POST("/children/*full-code-path", middleware2.CheckTableSearch(), standardAPI.TableChildren)     // Table 子表加载（主从表）
 
 	// Form 函数接口
 	form := apiV1.Group("/form")
 	form.Use(middleware2.JWTAuth())
-	form.POST("/submit/*full-code-path", middleware2.CheckFormWrite(), standardAPI.FormSubmit) // Form 提交
+	form.POST("/submit/*full-code-path", middleware2.CheckFormWrite(), standardAPI.FormSubmit)     // Form 提交
+	form.POST("/step/*full-code-path", middleware2.CheckFormWrite(), standardAPI.FormStepValidate) // 向导表单步骤校验
+
+	// 向导表单草稿
+	formDraftHandler := v1.NewFormDraft(s.formDraftService)
+	form.GET("/draft/get/*full-code-path", middleware2.CheckFormWrite(), formDraftHandler.Get)        // 获取草稿
+	form.POST("/draft/save/*full-code-path", middleware2.CheckFormWrite(), formDraftHandler.Save)     // 保存草稿
+	form.POST("/draft/delete/*full-code-path", middleware2.CheckFormWrite(), formDraftHandler.Delete) // 删除草稿
+
+	// Kanban / Calendar 函数接口（卡片、日程的增删改复用 Table 接口）
+	kanban := apiV1.Group("/kanban")
+	kanban.Use(middleware2.JWTAuth())
+	kanban.GET("/search/*full-code-path", middleware2.CheckTableSearch(), standardAPI.TableSearch) // Kanban 查询
+	kanban.PUT("/move/*full-code-path", middleware2.CheckTableUpdate(), standardAPI.KanbanMove)    // Kanban 拖动卡片
+
+	calendar := apiV1.Group("/calendar")
+	calendar.Use(middleware2.JWTAuth())
+	calendar.GET("/search/*full-code-path", middleware2.CheckTableSearch(), standardAPI.TableSearch)     // Calendar 列表查询
+	calendar.POST("/range/*full-code-path", middleware2.CheckTableSearch(), standardAPI.CalendarRange) // Calendar 时间范围查询
 
 	// Chart 函数接口
 	chart := apiV1.Group("/chart")
@@ -158,6 +182,64 @@ func (s *Server) setupRoutes() {
 	callbackStandard.Use(middleware2.JWTAuth())
 	callbackStandard.POST("/on_select_fuzzy/*full-code-path", standardAPI.CallbackOnSelectFuzzy) // 模糊搜索回调
 
+	// 函数运行时配置路由（需要JWT验证 + 配置管理功能鉴权）
+	functionConfig := apiV1.Group("/config")
+	functionConfig.Use(middleware2.JWTAuth())                                          // JWT 认证
+	functionConfig.Use(middleware2.RequireFeature(enterprise.FeatureConfigManagement)) // 配置管理功能鉴权（企业版）
+	functionConfigHandler := v1.NewFunctionConfig(s.functionConfigService)
+	functionConfig.GET("/get/*full-code-path", middleware2.CheckFunctionConfigRead(), functionConfigHandler.GetConfig)            // 获取配置
+	functionConfig.POST("/update/*full-code-path", middleware2.CheckFunctionConfigManage(), functionConfigHandler.UpdateConfig)   // 更新配置
+	functionConfig.GET("/history/*full-code-path", middleware2.CheckFunctionConfigRead(), functionConfigHandler.GetHistory)       // 配置历史
+	functionConfig.GET("/diff/*full-code-path", middleware2.CheckFunctionConfigRead(), functionConfigHandler.Diff)                // 版本对比
+	functionConfig.POST("/rollback/*full-code-path", middleware2.CheckFunctionConfigManage(), functionConfigHandler.Rollback)     // 回滚
+
+	// 表格视图路由（需要JWT验证，查看表格的权限即可保存个人视图）
+	tableView := apiV1.Group("/view")
+	tableView.Use(middleware2.JWTAuth()) // JWT 认证
+	tableViewHandler := v1.NewTableView(s.tableViewService)
+	tableView.GET("/list/*full-code-path", middleware2.CheckTableRead(), tableViewHandler.List)      // 视图列表
+	tableView.POST("/save/*full-code-path", middleware2.CheckTableRead(), tableViewHandler.Save)     // 创建/更新视图
+	tableView.POST("/delete/*full-code-path", middleware2.CheckTableRead(), tableViewHandler.Delete) // 删除视图
+
+	// 通知中心路由（需要JWT验证 + 通知中心功能鉴权）
+	notification := apiV1.Group("/notification")
+	notification.Use(middleware2.JWTAuth())                                      // JWT 认证
+	notification.Use(middleware2.RequireFeature(enterprise.FeatureNotification)) // 通知中心功能鉴权（企业版）
+	notificationHandler := v1.NewNotification(s.notificationService)
+	notification.GET("/list", notificationHandler.GetNotifications)          // 通知列表
+	notification.GET("/unread_count", notificationHandler.GetUnreadCount)    // 未读数量
+	notification.POST("/read", notificationHandler.MarkRead)                 // 标记已读
+	notification.GET("/preference", notificationHandler.GetPreference)       // 获取通知偏好
+	notification.POST("/preference", notificationHandler.UpdatePreference)   // 更新通知偏好
+	notification.GET("/subscribe", notificationHandler.Subscribe)            // 订阅站内通知（SSE）
+
+	// 审批流程路由（需要JWT验证 + 审批流程功能鉴权）
+	approval := apiV1.Group("/approval")
+	approval.Use(middleware2.JWTAuth())                                  // JWT 认证
+	approval.Use(middleware2.RequireFeature(enterprise.FeatureApproval)) // 审批流程功能鉴权（企业版）
+	approvalHandler := v1.NewApproval(s.approvalService)
+	approval.GET("/tasks", approvalHandler.GetTasks)                                                          // 我的待办/已办
+	approval.GET("/initiated", approvalHandler.GetInitiated)                                                  // 我发起的审批
+	approval.GET("/instance/:id", approvalHandler.GetInstance)                                                // 审批详情
+	approval.GET("/row/*full-code-path", middleware2.CheckApprovalRowRead(), approvalHandler.GetRowApprovals) // 记录的审批历史
+	approval.POST("/act", approvalHandler.Act)                                                                // 通过/拒绝/转交
+	approval.POST("/cancel", approvalHandler.Cancel)                                                          // 撤回
+
+	// 工作流路由（需要JWT验证 + 工作流功能鉴权）
+	workflow := apiV1.Group("/workflow")
+	workflow.Use(middleware2.JWTAuth())                                  // JWT 认证
+	workflow.Use(middleware2.RequireFeature(enterprise.FeatureWorkflow)) // 工作流功能鉴权（企业版）
+	workflowHandler := v1.NewWorkflow(s.workflowService)
+	workflow.POST("/save", workflowHandler.Save)            // 创建/更新工作流
+	workflow.GET("/list", workflowHandler.List)             // 我的工作流列表
+	workflow.GET("/get/:id", workflowHandler.Get)           // 工作流详情
+	workflow.POST("/delete", workflowHandler.Delete)        // 删除工作流
+	workflow.POST("/suggest", workflowHandler.Suggest)      // 推荐步骤输入映射
+	workflow.POST("/run", workflowHandler.Run)              // 手动运行
+	workflow.POST("/run/cancel", workflowHandler.CancelRun) // 取消运行
+	workflow.GET("/runs", workflowHandler.GetRuns)          // 运行记录
+	workflow.GET("/run/:id", workflowHandler.GetRun)        // 运行详情（含步骤日志）
+
 	// ⭐ 权限管理路由（需要JWT验证 + 权限管理功能鉴权）
 	permission := apiV1.Group("/permission")
 	permission.Use(middleware2.JWTAuth())                                    // JWT 认证
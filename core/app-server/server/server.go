@@ -47,8 +47,15 @@ type Server struct {
 	userService                   *service.UserService
 	operateLogService             *service.OperateLogService
 	directoryUpdateHistoryService *service.DirectoryUpdateHistoryService
-	permissionService             *service.PermissionService // ⭐ 权限管理服务
-	appRepo                       *repository.AppRepository  // ⭐ 应用仓储（用于权限服务查询 app.id）
+	permissionService             *service.PermissionService     // ⭐ 权限管理服务
+	appSecretService              *service.AppSecretService      // 应用密钥服务
+	functionConfigService         *service.FunctionConfigService // 函数运行时配置服务
+	tableViewService              *service.TableViewService      // 表格视图服务
+	formDraftService              *service.FormDraftService      // 向导表单草稿服务
+	notificationService           *service.NotificationService   // 通知中心服务
+	approvalService               *service.ApprovalService       // 审批流程服务
+	workflowService               *service.WorkflowService       // 工作流服务
+	appRepo                       *repository.AppRepository      // ⭐ 应用仓储（用于权限服务查询 app.id）
 
 	// 上游服务
 	natsService *service.NatsService
@@ -156,6 +163,32 @@ func (s *Server) Stop(ctx context.Context) error {
 		logger.Infof(ctx, "[Server] AppRuntime service closed")
 	}
 
+	// 关闭应用密钥服务（NATS 订阅）
+	if s.appSecretService != nil {
+		s.appSecretService.Close()
+		logger.Infof(ctx, "[Server] AppSecret service closed")
+	}
+
+	// 关闭函数运行时配置服务（NATS 订阅）
+	if s.functionConfigService != nil {
+		s.functionConfigService.Close()
+		logger.Infof(ctx, "[Server] FunctionConfig service closed")
+	}
+
+	// 关闭通知中心服务（NATS 订阅）
+	if s.notificationService != nil {
+		s.notificationService.Close()
+		logger.Infof(ctx, "[Server] Notification service closed")
+	}
+	if s.approvalService != nil {
+		s.approvalService.Close()
+		logger.Infof(ctx, "[Server] Approval service closed")
+	}
+	if s.workflowService != nil {
+		s.workflowService.Close()
+		logger.Infof(ctx, "[Server] Workflow service closed")
+	}
+
 	// 关闭 NATS 服务
 	if s.natsService != nil {
 		s.natsService.Close()
@@ -403,12 +436,40 @@ func (s *Server) initServices(ctx context.Context) error {
 	casbinRuleRepo := repository.NewCasbinRuleRepository(s.db)
 	s.permissionService = service.NewPermissionService(enterprise.GetPermissionService(), casbinRuleRepo, s.appRepo)
 
+	// 初始化应用密钥服务（加密存储，向 app-runtime/SDK App 下发明文）
+	appSecretRepo := repository.NewAppSecretRepository(s.db)
+	s.appSecretService = service.NewAppSecretService(s.cfg, appSecretRepo, appRepo, s.natsService)
+
 	// 初始化服务目录服务（包含目录管理功能：copy、create、remove）
-	s.serviceTreeService = service.NewServiceTreeService(serviceTreeRepo, appRepo, s.appRuntime, fileSnapshotRepo, s.appService, s.functionGenService, s.permissionService)
+	s.serviceTreeService = service.NewServiceTreeService(serviceTreeRepo, appRepo, s.appRuntime, fileSnapshotRepo, s.appService, s.functionGenService, s.permissionService, s.appSecretService)
 
 	// 初始化函数服务
 	s.functionService = service.NewFunctionService(functionRepo, appRepo)
 
+	// 初始化函数运行时配置服务（版本化存储，变更推送到运行中的应用）
+	functionConfigRepo := repository.NewFunctionConfigRepository(s.db)
+	s.functionConfigService = service.NewFunctionConfigService(s.cfg, functionConfigRepo, functionRepo, appRepo, s.natsService)
+
+	// 初始化表格视图服务
+	tableViewRepo := repository.NewTableViewRepository(s.db)
+	s.tableViewService = service.NewTableViewService(tableViewRepo, functionRepo, s.appService)
+
+	// 初始化向导表单草稿服务
+	formDraftRepo := repository.NewFormDraftRepository(s.db)
+	s.formDraftService = service.NewFormDraftService(formDraftRepo, functionRepo, s.appService)
+
+	// 初始化通知中心服务（站内信、邮件、Webhook）
+	notificationRepo := repository.NewNotificationRepository(s.db)
+	s.notificationService = service.NewNotificationService(s.cfg, notificationRepo, appRepo, casbinRuleRepo, s.emailService, s.natsService)
+
+	// 初始化审批流程服务（依赖通知中心发送待办和结果通知）
+	approvalRepo := repository.NewApprovalRepository(s.db)
+	s.approvalService = service.NewApprovalService(approvalRepo, appRepo, operateLogRepo, s.appService, s.notificationService, s.natsService)
+
+	// 初始化工作流服务（跨应用编排函数，定时触发和表格事件触发）
+	workflowRepo := repository.NewWorkflowRepository(s.db)
+	s.workflowService = service.NewWorkflowService(workflowRepo, functionRepo, s.appService)
+
 	// 初始化用户服务
 	s.userService = service.NewUserService(userRepo)
 
@@ -0,0 +1,311 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/ai-agent-os/ai-agent-os/core/app-server/model"
+	"github.com/ai-agent-os/ai-agent-os/core/app-server/repository"
+	"github.com/ai-agent-os/ai-agent-os/dto"
+	appPkg "github.com/ai-agent-os/ai-agent-os/pkg/app"
+	"github.com/ai-agent-os/ai-agent-os/pkg/config"
+	"github.com/ai-agent-os/ai-agent-os/pkg/contextx"
+	"github.com/ai-agent-os/ai-agent-os/pkg/logger"
+	"github.com/ai-agent-os/ai-agent-os/pkg/msgx"
+	"github.com/ai-agent-os/ai-agent-os/pkg/subjects"
+	"github.com/nats-io/nats.go"
+	"gorm.io/gorm"
+)
+
+// secretNamePattern 密钥名称规则：与环境变量命名保持一致
+var secretNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]{0,127}$`)
+
+// AppSecretService 应用密钥服务
+// 负责密钥的加密存储、轮换、删除，以及向 app-runtime/SDK App 下发明文
+type AppSecretService struct {
+	secretRepo  *repository.AppSecretRepository
+	appRepo     *repository.AppRepository
+	natsService *NatsService
+	cipher      *SecretCipher
+	tokenKey    []byte // 校验 app-runtime 签发的应用令牌（与 app-runtime 共享全局 JWT Secret）
+	subs        []*nats.Subscription
+}
+
+// NewAppSecretService 创建应用密钥服务
+func NewAppSecretService(cfg *config.AppServerConfig, secretRepo *repository.AppSecretRepository, appRepo *repository.AppRepository, natsService *NatsService) *AppSecretService {
+	if !cfg.HasSecretEncryptionKey() {
+		logger.Errorf(context.Background(), "[AppSecretService] ⚠️ 未配置 secret.encryption_key，应用密钥将使用 JWT Secret 派生的密钥加密！"+
+			"JWT Secret 轮换后已保存的密钥将无法解密，生产环境请务必单独配置 secret.encryption_key")
+	}
+
+	s := &AppSecretService{
+		secretRepo:  secretRepo,
+		appRepo:     appRepo,
+		natsService: natsService,
+		cipher:      NewSecretCipher(cfg.GetSecretEncryptionKey()),
+		tokenKey:    []byte(cfg.GetJWT().Secret),
+	}
+
+	// 初始化订阅（app-runtime 启动容器、SDK 读取密钥都通过该主题获取明文）
+	s.initSubscriptions()
+
+	return s
+}
+
+// ListSecrets 获取应用密钥列表（不返回明文）
+func (s *AppSecretService) ListSecrets(ctx context.Context, user, app string) (*dto.AppSecretListResp, error) {
+	appModel, err := s.getApp(user, app)
+	if err != nil {
+		return nil, err
+	}
+
+	secrets, err := s.secretRepo.ListByAppID(appModel.ID)
+	if err != nil {
+		return nil, fmt.Errorf("查询应用密钥失败: %w", err)
+	}
+
+	resp := &dto.AppSecretListResp{Secrets: make([]dto.AppSecretInfo, 0, len(secrets))}
+	for _, secret := range secrets {
+		resp.Secrets = append(resp.Secrets, dto.AppSecretInfo{
+			ID:          secret.ID,
+			Name:        secret.Name,
+			Description: secret.Description,
+			Value:       logger.RedactedPlaceholder,
+			Version:     secret.Version,
+			UpdatedBy:   secret.UpdatedBy,
+			UpdatedAt:   time.Time(secret.UpdatedAt).Format(time.DateTime),
+			CreatedAt:   time.Time(secret.CreatedAt).Format(time.DateTime),
+		})
+	}
+	return resp, nil
+}
+
+// SetSecret 设置应用密钥（不存在则创建，存在则轮换）
+// 轮换后通知运行中的应用清空密钥缓存，无需重新编译
+func (s *AppSecretService) SetSecret(ctx context.Context, user, app string, req *dto.SetAppSecretReq) error {
+	if !secretNamePattern.MatchString(req.Name) {
+		return fmt.Errorf("密钥名称不合法: %s（只能包含字母、数字、下划线，且不能以数字开头）", req.Name)
+	}
+
+	appModel, err := s.getApp(user, app)
+	if err != nil {
+		return err
+	}
+
+	encrypted, err := s.cipher.Encrypt(req.Value)
+	if err != nil {
+		return fmt.Errorf("加密密钥失败: %w", err)
+	}
+
+	requestUser := contextx.GetRequestUser(ctx)
+	secret, err := s.secretRepo.GetByAppIDAndName(appModel.ID, req.Name)
+	if err != nil {
+		if err != gorm.ErrRecordNotFound {
+			return fmt.Errorf("查询应用密钥失败: %w", err)
+		}
+		secret = &model.AppSecret{
+			AppID:          appModel.ID,
+			Name:           req.Name,
+			EncryptedValue: encrypted,
+			Description:    req.Description,
+			Version:        1,
+		}
+		secret.CreatedBy = requestUser
+		secret.UpdatedBy = requestUser
+		if err := s.secretRepo.Create(secret); err != nil {
+			return fmt.Errorf("创建应用密钥失败: %w", err)
+		}
+	} else {
+		secret.EncryptedValue = encrypted
+		if req.Description != "" {
+			secret.Description = req.Description
+		}
+		secret.Version++
+		secret.UpdatedBy = requestUser
+		if err := s.secretRepo.Update(secret); err != nil {
+			return fmt.Errorf("更新应用密钥失败: %w", err)
+		}
+	}
+
+	logger.Infof(ctx, "[AppSecretService] 设置应用密钥成功: app=%s/%s, name=%s, version=%d, operator=%s",
+		user, app, req.Name, secret.Version, requestUser)
+
+	s.notifySecretRotate(ctx, appModel)
+	return nil
+}
+
+// DeleteSecret 删除应用密钥
+func (s *AppSecretService) DeleteSecret(ctx context.Context, user, app string, req *dto.DeleteAppSecretReq) error {
+	appModel, err := s.getApp(user, app)
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.secretRepo.GetByAppIDAndName(appModel.ID, req.Name); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return fmt.Errorf("密钥不存在: %s", req.Name)
+		}
+		return fmt.Errorf("查询应用密钥失败: %w", err)
+	}
+
+	if err := s.secretRepo.Delete(appModel.ID, req.Name); err != nil {
+		return fmt.Errorf("删除应用密钥失败: %w", err)
+	}
+
+	logger.Infof(ctx, "[AppSecretService] 删除应用密钥成功: app=%s/%s, name=%s, operator=%s",
+		user, app, req.Name, contextx.GetRequestUser(ctx))
+
+	s.notifySecretRotate(ctx, appModel)
+	return nil
+}
+
+// GetSecretValues 获取应用所有密钥的明文（仅供内部调用，不能暴露给 HTTP 接口）
+func (s *AppSecretService) GetSecretValues(ctx context.Context, appID int64) (map[string]string, error) {
+	secrets, err := s.secretRepo.ListByAppID(appID)
+	if err != nil {
+		return nil, fmt.Errorf("查询应用密钥失败: %w", err)
+	}
+
+	values := make(map[string]string, len(secrets))
+	for _, secret := range secrets {
+		value, err := s.cipher.Decrypt(secret.EncryptedValue)
+		if err != nil {
+			// 单个密钥解密失败不影响其他密钥（通常是加密密钥被修改）
+			logger.Errorf(ctx, "[AppSecretService] 解密密钥失败: app_id=%d, name=%s, err=%v", appID, secret.Name, err)
+			continue
+		}
+		values[secret.Name] = value
+	}
+	return values, nil
+}
+
+// RedactContent 将内容中出现的应用密钥明文替换为占位符
+// 用于发布到 Hub 等对外场景，避免开发者把密钥硬编码在代码里被带出去
+func (s *AppSecretService) RedactContent(ctx context.Context, appID int64, content string) string {
+	values, err := s.GetSecretValues(ctx, appID)
+	if err != nil {
+		logger.Warnf(ctx, "[AppSecretService] 获取应用密钥失败，跳过脱敏: app_id=%d, err=%v", appID, err)
+		return content
+	}
+	return redactSecretValues(content, values)
+}
+
+// redactSecretValues 将内容中出现的密钥明文替换为 ******
+func redactSecretValues(content string, values map[string]string) string {
+	for _, value := range values {
+		if value == "" {
+			continue
+		}
+		content = strings.ReplaceAll(content, value, logger.RedactedPlaceholder)
+	}
+	return content
+}
+
+// getApp 获取应用
+func (s *AppSecretService) getApp(user, app string) (*model.App, error) {
+	appModel, err := s.appRepo.GetAppByUserName(user, app)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("应用不存在: %s/%s", user, app)
+		}
+		return nil, fmt.Errorf("获取应用信息失败: %w", err)
+	}
+	return appModel, nil
+}
+
+// notifySecretRotate 通知运行中的应用密钥已变更（SDK 收到后清空密钥缓存）
+func (s *AppSecretService) notifySecretRotate(ctx context.Context, appModel *model.App) {
+	if appModel.Version == "" {
+		return
+	}
+	conn, err := s.natsService.GetNatsByHost(appModel.HostID)
+	if err != nil {
+		logger.Warnf(ctx, "[AppSecretService] 获取 NATS 连接失败，跳过密钥变更通知: %v", err)
+		return
+	}
+
+	message := subjects.Message{
+		Type:      subjects.MessageTypeStatusSecretRotate,
+		User:      appModel.User,
+		App:       appModel.Code,
+		Version:   appModel.Version,
+		Timestamp: time.Now(),
+	}
+	data, err := json.Marshal(message)
+	if err != nil {
+		logger.Warnf(ctx, "[AppSecretService] 序列化密钥变更通知失败: %v", err)
+		return
+	}
+
+	subject := subjects.BuildAppStatusSubject(appModel.User, appModel.Code, appModel.Version)
+	if err := conn.Publish(subject, data); err != nil {
+		logger.Warnf(ctx, "[AppSecretService] 发送密钥变更通知失败: subject=%s, err=%v", subject, err)
+	}
+}
+
+// initSubscriptions 初始化 NATS 订阅（所有主机）
+func (s *AppSecretService) initSubscriptions() {
+	for hostId := range s.natsService.hostIdMap {
+		conn, err := s.natsService.GetNatsByHost(hostId)
+		if err != nil {
+			continue
+		}
+
+		// 使用队列组，多个 app-server 实例只有一个会响应
+		sub, err := conn.QueueSubscribe(subjects.GetAppServerSecretRequestSubject(), "app-server", s.handleSecretRequest)
+		if err != nil {
+			logger.Errorf(context.Background(), "[AppSecretService] Failed to subscribe secret subject on host %d: %v", hostId, err)
+			continue
+		}
+		s.subs = append(s.subs, sub)
+	}
+}
+
+// handleSecretRequest 处理密钥明文请求（app-runtime 启动容器 / SDK App 读取密钥）
+func (s *AppSecretService) handleSecretRequest(msg *nats.Msg) {
+	ctx := context.Background()
+
+	info, err := msgx.DecodeNatsMsg[dto.GetAppSecretsRuntimeReq](msg)
+	if err != nil {
+		msgx.RespFailMsg(msg, fmt.Errorf("解析请求失败: %w", err))
+		return
+	}
+
+	// 请求必须携带 app-runtime 为该应用签发的令牌，防止任意 NATS 客户端冒充其他应用读取密钥
+	if !appPkg.VerifySecretToken(s.tokenKey, info.Data.User, info.Data.App, info.Data.Token) {
+		logger.Warnf(ctx, "[AppSecretService] 拒绝密钥请求，令牌与应用不匹配: app=%s/%s", info.Data.User, info.Data.App)
+		msgx.RespFailMsg(msg, fmt.Errorf("无权获取应用 %s/%s 的密钥", info.Data.User, info.Data.App))
+		return
+	}
+
+	appModel, err := s.getApp(info.Data.User, info.Data.App)
+	if err != nil {
+		msgx.RespFailMsg(msg, err)
+		return
+	}
+
+	values, err := s.GetSecretValues(ctx, appModel.ID)
+	if err != nil {
+		msgx.RespFailMsg(msg, err)
+		return
+	}
+
+	if err := msgx.RespSuccessMsg(msg, &dto.GetAppSecretsRuntimeResp{Secrets: values}); err != nil {
+		logger.Errorf(ctx, "[AppSecretService] 响应密钥请求失败: app=%s/%s, err=%v", info.Data.User, info.Data.App, err)
+	}
+}
+
+// Close 关闭订阅
+func (s *AppSecretService) Close() error {
+	for _, sub := range s.subs {
+		if sub != nil {
+			_ = sub.Unsubscribe()
+		}
+	}
+	s.subs = nil
+	return nil
+}
@@ -31,6 +31,9 @@ type AppService struct {
 	operateLogRepo             *repository.OperateLogRepository
 	fileSnapshotRepo           *repository.FileSnapshotRepository
 	directoryUpdateHistoryRepo *repository.DirectoryUpdateHistoryRepository
+	tableEventHandlers         []TableEventHandler     // 表格数据变更事件订阅者（服务初始化时注册）
+	functionChangeHandlers     []FunctionChangeHandler // 函数结构变更事件订阅者（服务初始化时注册）
+	formEventHandlers          []FormEventHandler      // 表单提交事件订阅者（服务初始化时注册）
 }
 
 // NewAppService 创建 AppService（依赖注入）
@@ -331,8 +334,8 @@ func (a *AppService) RecordTableOperateLog(ctx context.Context, req *dto.RecordT
 	// 		}
 	// 	}()
 
-	case "OnTableUpdateRow":
-		// 更新操作：记录 updates 和 old_values
+	case "OnTableUpdateRow", "OnKanbanMove":
+		// 更新操作（包括看板拖动卡片）：记录 updates 和 old_values
 		log := &model.TableOperateLog{
 			TenantUser:   req.TenantUser,
 			RequestUser:  req.RequestUser,
@@ -442,6 +445,11 @@ func (a *AppService) processAPIDiff(ctx context.Context, appID int64, diffData *
 		}
 	}
 
+	// 通知订阅者函数结构变更（如标记引用了已删除字段的表格视图）
+	if len(diffData.Update) > 0 || len(diffData.Delete) > 0 {
+		a.emitFunctionChange(ctx, &FunctionChangeEvent{AppID: appID, Updated: diffData.Update, Deleted: diffData.Delete})
+	}
+
 	// 5. 创建目录快照（检测目录变更并创建快照）
 	err = a.createDirectorySnapshots(ctx, appID, app, diffData, req, duration, gitCommitHash)
 	if err != nil {
@@ -477,17 +485,30 @@ func (a *AppService) convertApiInfoToFunctions(ctx context.Context, appID int64,
 			responseJSON = responseData
 		}
 
+		// 序列化运行时配置结构
+		var configJSON json.RawMessage
+		if len(api.Config) > 0 {
+			configData, err := json.Marshal(api.Config)
+			if err != nil {
+				return nil, fmt.Errorf("序列化config字段失败: %w", err)
+			}
+			configJSON = configData
+		}
+
 		// 序列化create_tables字段
 
 		function := &model.Function{
-			AppID:        appID,
-			Method:       api.Method,
-			Router:       api.BuildFullCodePath(),
-			Request:      requestJSON,
-			Response:     responseJSON,
-			HasConfig:    false, // 预留字段，默认为false
-			TemplateType: api.TemplateType,
-			Callbacks:    strings.Join(api.Callback, ","),
+			AppID:          appID,
+			Method:         api.Method,
+			Router:         api.BuildFullCodePath(),
+			Request:        requestJSON,
+			Response:       responseJSON,
+			HasConfig:      len(api.Config) > 0,
+			Config:         configJSON,
+			ConfigDefault:  api.ConfigDefault,
+			TemplateConfig: api.TemplateConfig,
+			TemplateType:   api.TemplateType,
+			Callbacks:      strings.Join(api.Callback, ","),
 		}
 		// 设置创建者用户名（通过嵌入的 Base 结构体）
 		function.CreatedBy = username
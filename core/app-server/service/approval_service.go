@@ -0,0 +1,1101 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ai-agent-os/ai-agent-os/core/app-server/model"
+	"github.com/ai-agent-os/ai-agent-os/core/app-server/repository"
+	"github.com/ai-agent-os/ai-agent-os/dto"
+	"github.com/ai-agent-os/ai-agent-os/enterprise"
+	"github.com/ai-agent-os/ai-agent-os/pkg/apicall"
+	"github.com/ai-agent-os/ai-agent-os/pkg/contextx"
+	"github.com/ai-agent-os/ai-agent-os/pkg/license"
+	"github.com/ai-agent-os/ai-agent-os/pkg/logger"
+	"github.com/ai-agent-os/ai-agent-os/pkg/msgx"
+	"github.com/ai-agent-os/ai-agent-os/pkg/subjects"
+	"github.com/nats-io/nats.go"
+	"gorm.io/gorm"
+)
+
+const (
+	approvalTimeoutCheckInterval = time.Minute // 超时检查间隔
+	approvalTimeoutBatchSize     = 100         // 每次最多处理的超时任务数
+	approvalCallbackTimeout      = 30 * time.Second
+	approvalOperateLogPrefix     = "Approval:" // 操作日志 Action 前缀，如 Approval:approve
+)
+
+// ApprovalService 审批流程服务
+// SDK 通过 ctx.StartApproval 发起审批（NATS），审批人在工作空间中通过、拒绝或转交，
+// 审批结束后回调应用的 OnApprovalFinished；所有审批操作都会记录到操作日志
+type ApprovalService struct {
+	approvalRepo        *repository.ApprovalRepository
+	appRepo             *repository.AppRepository
+	operateLogRepo      *repository.OperateLogRepository
+	appService          *AppService
+	notificationService *NotificationService
+	natsService         *NatsService
+	subs                []*nats.Subscription
+	stopCh              chan struct{}
+}
+
+// approvalNodeSnapshot 流程节点快照（审批人在发起时解析，之后 hr 中的组织架构变化不影响进行中的审批）
+type approvalNodeSnapshot struct {
+	dto.ApprovalNode
+	Approvers  []string          `json:"approvers"`            // 审批人
+	Escalation map[string]string `json:"escalation,omitempty"` // 审批人 -> 超时升级给谁
+}
+
+// NewApprovalService 创建审批流程服务
+func NewApprovalService(
+	approvalRepo *repository.ApprovalRepository,
+	appRepo *repository.AppRepository,
+	operateLogRepo *repository.OperateLogRepository,
+	appService *AppService,
+	notificationService *NotificationService,
+	natsService *NatsService,
+) *ApprovalService {
+	s := &ApprovalService{
+		approvalRepo:        approvalRepo,
+		appRepo:             appRepo,
+		operateLogRepo:      operateLogRepo,
+		appService:          appService,
+		notificationService: notificationService,
+		natsService:         natsService,
+		stopCh:              make(chan struct{}),
+	}
+
+	// 初始化订阅（SDK 发起审批）
+	s.initSubscriptions()
+
+	// 启动超时检查
+	go s.runTimeoutChecker()
+
+	return s
+}
+
+// Start 发起审批
+func (s *ApprovalService) Start(ctx context.Context, req *dto.StartApprovalReq) (*dto.StartApprovalResp, error) {
+	if req.RequestUser == "" {
+		return nil, fmt.Errorf("发起人不能为空")
+	}
+	if req.Process.Code == "" {
+		return nil, fmt.Errorf("审批流程编码不能为空")
+	}
+	if len(req.Process.Nodes) == 0 {
+		return nil, fmt.Errorf("审批流程至少需要一个节点")
+	}
+
+	app, err := s.appRepo.GetAppByUserName(req.User, req.App)
+	if err != nil {
+		return nil, fmt.Errorf("获取应用信息失败: %w", err)
+	}
+
+	header := &apicall.Header{
+		TraceID:     req.TraceID,
+		RequestUser: req.RequestUser,
+		Token:       req.Token,
+	}
+
+	// 1. 解析每个节点的审批人和超时升级人
+	nodes := make([]*approvalNodeSnapshot, 0, len(req.Process.Nodes))
+	for i, node := range req.Process.Nodes {
+		if node == nil {
+			return nil, fmt.Errorf("审批节点 %d 不能为空", i+1)
+		}
+		snapshot, err := s.resolveNode(header, req.RequestUser, node)
+		if err != nil {
+			return nil, fmt.Errorf("解析审批节点「%s」失败: %w", nodeDisplayName(node, i), err)
+		}
+		nodes = append(nodes, snapshot)
+	}
+
+	definition, err := json.Marshal(nodes)
+	if err != nil {
+		return nil, fmt.Errorf("序列化审批流程失败: %w", err)
+	}
+	data, err := json.Marshal(req.Data)
+	if err != nil {
+		return nil, fmt.Errorf("序列化审批数据失败: %w", err)
+	}
+
+	// 2. 创建审批实例和第一个节点的任务
+	instance := &model.ApprovalInstance{
+		TenantUser:   req.User,
+		App:          req.App,
+		FullCodePath: req.FullCodePath,
+		RowID:        req.RowID,
+		ProcessCode:  req.Process.Code,
+		ProcessName:  req.Process.Name,
+		Title:        req.Title,
+		Initiator:    req.RequestUser,
+		Data:         data,
+		Definition:   definition,
+		CurrentNode:  0,
+		Status:       dto.ApprovalStatusPending,
+		TraceID:      req.TraceID,
+		Version:      app.Version,
+	}
+	instance.CreatedBy = req.RequestUser
+	tasks := buildApprovalNodeTasks(0, nodes[0], time.Now())
+	if err := s.approvalRepo.CreateInstance(instance, tasks); err != nil {
+		return nil, fmt.Errorf("创建审批失败: %w", err)
+	}
+
+	s.recordOperateLog(ctx, instance, req.RequestUser, dto.ApprovalActionStart, map[string]interface{}{
+		"process_code": req.Process.Code,
+		"title":        req.Title,
+		"approvers":    nodes[0].Approvers,
+	})
+	s.notifyTasks(ctx, instance, tasks)
+
+	logger.Infof(ctx, "[ApprovalService] 发起审批: instance=%d, process=%s, initiator=%s, path=%s, row=%d",
+		instance.ID, req.Process.Code, req.RequestUser, req.FullCodePath, req.RowID)
+
+	return &dto.StartApprovalResp{InstanceID: instance.ID, Approvers: nodes[0].Approvers}, nil
+}
+
+// Act 处理审批任务（通过、拒绝、转交），只有任务的审批人可以处理
+func (s *ApprovalService) Act(ctx context.Context, req *dto.ApprovalActionReq) error {
+	username := contextx.GetRequestUser(ctx)
+
+	task, err := s.approvalRepo.GetTask(req.TaskID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("审批任务不存在")
+		}
+		return fmt.Errorf("获取审批任务失败: %w", err)
+	}
+	if task.Assignee != username {
+		return fmt.Errorf("无权处理该审批任务")
+	}
+	if task.Status != model.ApprovalTaskPending {
+		return fmt.Errorf("该审批任务已处理")
+	}
+	instance, nodes, err := s.getInstanceWithNodes(task.InstanceID)
+	if err != nil {
+		return err
+	}
+	if instance.Status != dto.ApprovalStatusPending {
+		return fmt.Errorf("审批已结束")
+	}
+
+	now := time.Now()
+	switch req.Action {
+	case dto.ApprovalActionApprove:
+		if err := s.transitTask(task.ID, model.ApprovalTaskPending, model.ApprovalTaskApproved, req.Comment, now); err != nil {
+			return err
+		}
+		s.recordOperateLog(ctx, instance, username, dto.ApprovalActionApprove, map[string]interface{}{
+			"task_id": task.ID, "node": task.NodeName, "comment": req.Comment,
+		})
+		return s.advance(ctx, instance, nodes, task.NodeIndex, username, req.Comment)
+
+	case dto.ApprovalActionReject:
+		if err := s.transitTask(task.ID, model.ApprovalTaskPending, model.ApprovalTaskRejected, req.Comment, now); err != nil {
+			return err
+		}
+		s.recordOperateLog(ctx, instance, username, dto.ApprovalActionReject, map[string]interface{}{
+			"task_id": task.ID, "node": task.NodeName, "comment": req.Comment,
+		})
+		return s.finish(ctx, instance, dto.ApprovalStatusRejected, username, req.Comment)
+
+	case dto.ApprovalActionTransfer:
+		if req.TransferTo == "" {
+			return fmt.Errorf("转交人不能为空")
+		}
+		if req.TransferTo == username {
+			return fmt.Errorf("不能转交给自己")
+		}
+		if err := s.transitTask(task.ID, model.ApprovalTaskPending, model.ApprovalTaskTransferred, req.Comment, now); err != nil {
+			return err
+		}
+		node := nodes[task.NodeIndex]
+		escalateTo := ""
+		if node.TimeoutMinutes > 0 && node.TimeoutAction == dto.ApprovalTimeoutEscalate {
+			header := &apicall.Header{
+				TraceID:     contextx.GetTraceId(ctx),
+				RequestUser: username,
+				Token:       contextx.GetToken(ctx),
+			}
+			escalateTo = s.resolveEscalateTarget(header, &node.ApprovalNode, req.TransferTo)
+		}
+		newTask := &model.ApprovalTask{
+			InstanceID:    instance.ID,
+			NodeIndex:     task.NodeIndex,
+			NodeName:      task.NodeName,
+			Seq:           task.Seq,
+			Assignee:      req.TransferTo,
+			Status:        model.ApprovalTaskPending,
+			TransferredBy: username,
+			EscalateTo:    escalateTo,
+			DueAt:         approvalDueAt(&node.ApprovalNode, now),
+		}
+		if err := s.approvalRepo.CreateTasks([]*model.ApprovalTask{newTask}); err != nil {
+			return fmt.Errorf("转交审批失败: %w", err)
+		}
+		s.recordOperateLog(ctx, instance, username, dto.ApprovalActionTransfer, map[string]interface{}{
+			"task_id": task.ID, "node": task.NodeName, "comment": req.Comment, "transfer_to": req.TransferTo,
+		})
+		s.notifyTasks(ctx, instance, []*model.ApprovalTask{newTask})
+		return nil
+
+	default:
+		return fmt.Errorf("不支持的审批操作: %s", req.Action)
+	}
+}
+
+// Cancel 撤回审批（仅发起人，且审批未结束）
+func (s *ApprovalService) Cancel(ctx context.Context, req *dto.CancelApprovalReq) error {
+	username := contextx.GetRequestUser(ctx)
+
+	instance, err := s.approvalRepo.GetInstance(req.InstanceID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("审批不存在")
+		}
+		return fmt.Errorf("获取审批失败: %w", err)
+	}
+	if instance.Initiator != username {
+		return fmt.Errorf("只有发起人可以撤回审批")
+	}
+	if instance.Status != dto.ApprovalStatusPending {
+		return fmt.Errorf("审批已结束")
+	}
+
+	s.recordOperateLog(ctx, instance, username, dto.ApprovalActionCancel, map[string]interface{}{"comment": req.Comment})
+	return s.finish(ctx, instance, dto.ApprovalStatusCanceled, username, req.Comment)
+}
+
+// GetTasks 获取当前用户的待办/已办审批
+func (s *ApprovalService) GetTasks(ctx context.Context, req *dto.GetApprovalTasksReq) (*dto.GetApprovalTasksResp, error) {
+	username := contextx.GetRequestUser(ctx)
+	page, pageSize := normalizePage(req.Page, req.PageSize)
+
+	tasks, total, err := s.approvalRepo.ListAssigneeTasks(username, req.Done, page, pageSize)
+	if err != nil {
+		return nil, fmt.Errorf("获取审批任务失败: %w", err)
+	}
+
+	instanceIDs := make([]int64, 0, len(tasks))
+	for _, task := range tasks {
+		instanceIDs = append(instanceIDs, task.InstanceID)
+	}
+	instances, err := s.approvalRepo.GetInstancesByIDs(instanceIDs)
+	if err != nil {
+		return nil, fmt.Errorf("获取审批失败: %w", err)
+	}
+	instanceMap := make(map[int64]*model.ApprovalInstance, len(instances))
+	for _, instance := range instances {
+		instanceMap[instance.ID] = instance
+	}
+
+	items := make([]dto.ApprovalTodoInfo, 0, len(tasks))
+	for _, task := range tasks {
+		instance, ok := instanceMap[task.InstanceID]
+		if !ok {
+			continue
+		}
+		items = append(items, dto.ApprovalTodoInfo{
+			Task:     convertApprovalTask(task),
+			Instance: convertApprovalInstance(instance, nil),
+		})
+	}
+
+	return &dto.GetApprovalTasksResp{
+		Tasks:    items,
+		Total:    total,
+		Page:     page,
+		PageSize: pageSize,
+	}, nil
+}
+
+// GetInitiated 获取当前用户发起的审批
+func (s *ApprovalService) GetInitiated(ctx context.Context, req *dto.GetInitiatedApprovalsReq) (*dto.ApprovalInstanceListResp, error) {
+	username := contextx.GetRequestUser(ctx)
+	page, pageSize := normalizePage(req.Page, req.PageSize)
+
+	instances, total, err := s.approvalRepo.ListInitiated(username, req.Status, page, pageSize)
+	if err != nil {
+		return nil, fmt.Errorf("获取审批失败: %w", err)
+	}
+	infos, err := s.convertInstancesWithTasks(instances)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.ApprovalInstanceListResp{
+		Instances: infos,
+		Total:     total,
+		Page:      page,
+		PageSize:  pageSize,
+	}, nil
+}
+
+// GetInstance 获取审批详情（发起人和审批人可以查看）
+func (s *ApprovalService) GetInstance(ctx context.Context, id int64) (*dto.ApprovalInstanceInfo, error) {
+	username := contextx.GetRequestUser(ctx)
+
+	instance, err := s.approvalRepo.GetInstance(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("审批不存在")
+		}
+		return nil, fmt.Errorf("获取审批失败: %w", err)
+	}
+	infos, err := s.convertInstancesWithTasks([]*model.ApprovalInstance{instance})
+	if err != nil {
+		return nil, err
+	}
+	info := infos[0]
+
+	allowed := instance.Initiator == username
+	for _, task := range info.Tasks {
+		if task.Assignee == username {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return nil, fmt.Errorf("无权查看该审批")
+	}
+	return &info, nil
+}
+
+// GetRowApprovals 获取某条记录的审批历史（权限由路由中间件按函数读权限校验）
+func (s *ApprovalService) GetRowApprovals(ctx context.Context, fullCodePath string, rowID int64) ([]dto.ApprovalInstanceInfo, error) {
+	instances, err := s.approvalRepo.ListByRow(fullCodePath, rowID)
+	if err != nil {
+		return nil, fmt.Errorf("获取审批历史失败: %w", err)
+	}
+	return s.convertInstancesWithTasks(instances)
+}
+
+// ============================================
+// 流程推进
+// ============================================
+
+// advance 节点内某个任务通过后，判断节点是否完成；完成后进入下一个节点或结束审批
+func (s *ApprovalService) advance(ctx context.Context, instance *model.ApprovalInstance, nodes []*approvalNodeSnapshot, nodeIndex int, operator, comment string) error {
+	node := nodes[nodeIndex]
+	tasks, err := s.approvalRepo.ListNodeTasks(instance.ID, nodeIndex)
+	if err != nil {
+		return fmt.Errorf("获取审批任务失败: %w", err)
+	}
+
+	switch node.Mode {
+	case dto.ApprovalModeAnyOf:
+		// 或签：任意一人通过即节点通过，取消其他人的任务
+		if err := s.approvalRepo.CancelOpenTasks(instance.ID, nodeIndex); err != nil {
+			return fmt.Errorf("取消审批任务失败: %w", err)
+		}
+	case dto.ApprovalModeParallel:
+		// 会签：还有人未审批时继续等待
+		for _, task := range tasks {
+			if task.Status == model.ApprovalTaskPending {
+				return nil
+			}
+		}
+	default:
+		// 依次审批：激活下一个等待中的审批人
+		for _, task := range tasks {
+			if task.Status == model.ApprovalTaskPending {
+				return nil
+			}
+		}
+		for _, task := range tasks {
+			if task.Status != model.ApprovalTaskWaiting {
+				continue
+			}
+			ok, err := s.approvalRepo.TransitTask(task.ID, model.ApprovalTaskWaiting, map[string]interface{}{
+				"status": model.ApprovalTaskPending,
+				"due_at": approvalDueAt(&node.ApprovalNode, time.Now()),
+			})
+			if err != nil {
+				return fmt.Errorf("激活审批任务失败: %w", err)
+			}
+			if ok {
+				task.Status = model.ApprovalTaskPending
+				s.notifyTasks(ctx, instance, []*model.ApprovalTask{task})
+			}
+			return nil
+		}
+	}
+
+	next := nodeIndex + 1
+	if next >= len(nodes) {
+		return s.finish(ctx, instance, dto.ApprovalStatusApproved, operator, comment)
+	}
+
+	// 条件更新当前节点，防止并发审批时重复进入下一个节点
+	ok, err := s.approvalRepo.AdvanceNode(instance.ID, nodeIndex, next)
+	if err != nil {
+		return fmt.Errorf("更新审批节点失败: %w", err)
+	}
+	if !ok {
+		return nil
+	}
+	instance.CurrentNode = next
+	nextTasks := buildApprovalNodeTasks(next, nodes[next], time.Now())
+	for _, task := range nextTasks {
+		task.InstanceID = instance.ID
+	}
+	if err := s.approvalRepo.CreateTasks(nextTasks); err != nil {
+		return fmt.Errorf("创建审批任务失败: %w", err)
+	}
+	s.notifyTasks(ctx, instance, nextTasks)
+	return nil
+}
+
+// finish 结束审批：取消未处理的任务、通知发起人、回调应用
+func (s *ApprovalService) finish(ctx context.Context, instance *model.ApprovalInstance, status, operator, comment string) error {
+	now := time.Now()
+	ok, err := s.approvalRepo.FinishInstance(instance.ID, status, now)
+	if err != nil {
+		return fmt.Errorf("结束审批失败: %w", err)
+	}
+	if !ok {
+		// 已被其他请求结束
+		return nil
+	}
+	instance.Status = status
+	instance.FinishedAt = &now
+
+	if err := s.approvalRepo.CancelOpenTasks(instance.ID, -1); err != nil {
+		logger.Warnf(ctx, "[ApprovalService] 取消未处理的审批任务失败: instance=%d, err=%v", instance.ID, err)
+	}
+
+	s.recordOperateLog(ctx, instance, operator, status, map[string]interface{}{"status": status, "comment": comment})
+	s.notifyFinished(ctx, instance, operator, comment)
+
+	go s.callbackApp(instance, operator, comment)
+
+	logger.Infof(ctx, "[ApprovalService] 审批结束: instance=%d, status=%s, operator=%s", instance.ID, status, operator)
+	return nil
+}
+
+// callbackApp 回调应用的 OnApprovalFinished（应用未实现时会返回错误，记录为回调失败）
+func (s *ApprovalService) callbackApp(instance *model.ApprovalInstance, operator, comment string) {
+	ctx, cancel := context.WithTimeout(context.Background(), approvalCallbackTimeout)
+	defer cancel()
+
+	var data map[string]interface{}
+	if len(instance.Data) > 0 {
+		_ = json.Unmarshal(instance.Data, &data)
+	}
+	body, err := json.Marshal(map[string]interface{}{
+		"instance_id":  instance.ID,
+		"process_code": instance.ProcessCode,
+		"title":        instance.Title,
+		"row_id":       instance.RowID,
+		"status":       instance.Status,
+		"initiator":    instance.Initiator,
+		"operator":     operator,
+		"comment":      comment,
+		"data":         data,
+	})
+	if err != nil {
+		s.updateCallbackStatus(ctx, instance.ID, model.ApprovalCallbackFailed)
+		return
+	}
+
+	prefix := fmt.Sprintf("/%s/%s/", instance.TenantUser, instance.App)
+	router := strings.TrimPrefix(instance.FullCodePath, prefix)
+	callbackBody, err := json.Marshal(map[string]interface{}{
+		"method": "POST",
+		"router": router,
+		"body":   body,
+		"type":   "OnApprovalFinished",
+	})
+	if err != nil {
+		s.updateCallbackStatus(ctx, instance.ID, model.ApprovalCallbackFailed)
+		return
+	}
+
+	resp, err := s.appService.RequestApp(ctx, &dto.RequestAppReq{
+		User:        instance.TenantUser,
+		App:         instance.App,
+		Router:      "/_callback",
+		Method:      "POST",
+		TraceId:     instance.TraceID,
+		RequestUser: operator,
+		Body:        callbackBody,
+		IsCallback:  true,
+	})
+	if err == nil && resp.IsError() {
+		err = errors.New(resp.Error)
+	}
+	if err != nil {
+		logger.Warnf(ctx, "[ApprovalService] 回调 OnApprovalFinished 失败: instance=%d, path=%s, err=%v", instance.ID, instance.FullCodePath, err)
+		s.updateCallbackStatus(ctx, instance.ID, model.ApprovalCallbackFailed)
+		return
+	}
+	s.updateCallbackStatus(ctx, instance.ID, model.ApprovalCallbackSuccess)
+}
+
+func (s *ApprovalService) updateCallbackStatus(ctx context.Context, instanceID int64, status string) {
+	if err := s.approvalRepo.UpdateInstance(instanceID, map[string]interface{}{"callback_status": status}); err != nil {
+		logger.Warnf(ctx, "[ApprovalService] 更新回调状态失败: instance=%d, err=%v", instanceID, err)
+	}
+}
+
+// transitTask 更新任务状态，任务已被处理时返回错误
+func (s *ApprovalService) transitTask(taskID int64, from, to, comment string, now time.Time) error {
+	ok, err := s.approvalRepo.TransitTask(taskID, from, map[string]interface{}{
+		"status":   to,
+		"comment":  comment,
+		"acted_at": &now,
+	})
+	if err != nil {
+		return fmt.Errorf("更新审批任务失败: %w", err)
+	}
+	if !ok {
+		return fmt.Errorf("该审批任务已处理")
+	}
+	return nil
+}
+
+// ============================================
+// 超时处理
+// ============================================
+
+// runTimeoutChecker 定期处理超时的审批任务
+func (s *ApprovalService) runTimeoutChecker() {
+	ticker := time.NewTicker(approvalTimeoutCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.stopCh:
+			return
+		case <-ticker.C:
+			s.checkTimeouts()
+		}
+	}
+}
+
+// checkTimeouts 处理超时任务（多个 app-server 实例同时检查时，通过条件更新保证每个任务只处理一次）
+func (s *ApprovalService) checkTimeouts() {
+	if !license.GetManager().HasFeature(enterprise.FeatureApproval) {
+		return
+	}
+	ctx := context.Background()
+	tasks, err := s.approvalRepo.ListOverdueTasks(time.Now(), approvalTimeoutBatchSize)
+	if err != nil {
+		logger.Warnf(ctx, "[ApprovalService] 获取超时审批任务失败: %v", err)
+		return
+	}
+	for _, task := range tasks {
+		if err := s.handleTimeout(ctx, task); err != nil {
+			logger.Warnf(ctx, "[ApprovalService] 处理超时审批任务失败: task=%d, err=%v", task.ID, err)
+		}
+	}
+}
+
+// handleTimeout 按节点配置处理单个超时任务：升级、自动通过或自动拒绝
+func (s *ApprovalService) handleTimeout(ctx context.Context, task *model.ApprovalTask) error {
+	instance, nodes, err := s.getInstanceWithNodes(task.InstanceID)
+	if err != nil {
+		return err
+	}
+	if instance.Status != dto.ApprovalStatusPending {
+		return nil
+	}
+	node := nodes[task.NodeIndex]
+	now := time.Now()
+
+	switch node.TimeoutAction {
+	case dto.ApprovalTimeoutApprove:
+		ok, err := s.approvalRepo.TransitTask(task.ID, model.ApprovalTaskPending, map[string]interface{}{
+			"status": model.ApprovalTaskApproved, "comment": "超时自动通过", "acted_at": &now,
+		})
+		if err != nil || !ok {
+			return err
+		}
+		s.recordOperateLog(ctx, instance, task.Assignee, dto.ApprovalActionApprove, map[string]interface{}{
+			"task_id": task.ID, "node": task.NodeName, "timeout": true,
+		})
+		return s.advance(ctx, instance, nodes, task.NodeIndex, task.Assignee, "超时自动通过")
+
+	case dto.ApprovalTimeoutReject:
+		ok, err := s.approvalRepo.TransitTask(task.ID, model.ApprovalTaskPending, map[string]interface{}{
+			"status": model.ApprovalTaskRejected, "comment": "超时自动拒绝", "acted_at": &now,
+		})
+		if err != nil || !ok {
+			return err
+		}
+		s.recordOperateLog(ctx, instance, task.Assignee, dto.ApprovalActionReject, map[string]interface{}{
+			"task_id": task.ID, "node": task.NodeName, "timeout": true,
+		})
+		return s.finish(ctx, instance, dto.ApprovalStatusRejected, task.Assignee, "超时自动拒绝")
+
+	default:
+		if task.EscalateTo == "" || task.EscalateTo == task.Assignee {
+			// 没有可升级的人，不再重复检查，继续等待原审批人处理
+			_, err := s.approvalRepo.TransitTask(task.ID, model.ApprovalTaskPending, map[string]interface{}{"due_at": nil})
+			return err
+		}
+		ok, err := s.approvalRepo.TransitTask(task.ID, model.ApprovalTaskPending, map[string]interface{}{
+			"status": model.ApprovalTaskEscalated, "comment": "超时升级", "acted_at": &now,
+		})
+		if err != nil || !ok {
+			return err
+		}
+		// 升级后的任务如果仍然超时，继续升级给节点配置的升级人（不再查询 Leader）
+		nextEscalate := ""
+		if len(node.EscalateTo) > 0 && node.EscalateTo[0] != task.EscalateTo {
+			nextEscalate = node.EscalateTo[0]
+		}
+		newTask := &model.ApprovalTask{
+			InstanceID:    instance.ID,
+			NodeIndex:     task.NodeIndex,
+			NodeName:      task.NodeName,
+			Seq:           task.Seq,
+			Assignee:      task.EscalateTo,
+			Status:        model.ApprovalTaskPending,
+			TransferredBy: task.Assignee,
+			EscalateTo:    nextEscalate,
+			DueAt:         approvalDueAt(&node.ApprovalNode, now),
+		}
+		if err := s.approvalRepo.CreateTasks([]*model.ApprovalTask{newTask}); err != nil {
+			return fmt.Errorf("创建升级审批任务失败: %w", err)
+		}
+		s.recordOperateLog(ctx, instance, task.Assignee, dto.ApprovalActionEscalate, map[string]interface{}{
+			"task_id": task.ID, "node": task.NodeName, "escalate_to": task.EscalateTo,
+		})
+		s.notifyTasks(ctx, instance, []*model.ApprovalTask{newTask})
+		return nil
+	}
+}
+
+// ============================================
+// 审批人解析
+// ============================================
+
+// resolveNode 校验节点配置并解析审批人
+func (s *ApprovalService) resolveNode(header *apicall.Header, initiator string, node *dto.ApprovalNode) (*approvalNodeSnapshot, error) {
+	snapshot := &approvalNodeSnapshot{ApprovalNode: *node}
+	switch snapshot.Mode {
+	case "":
+		snapshot.Mode = dto.ApprovalModeSequential
+	case dto.ApprovalModeSequential, dto.ApprovalModeParallel, dto.ApprovalModeAnyOf:
+	default:
+		return nil, fmt.Errorf("不支持的审批模式: %s", snapshot.Mode)
+	}
+	switch snapshot.TimeoutAction {
+	case "":
+		snapshot.TimeoutAction = dto.ApprovalTimeoutEscalate
+	case dto.ApprovalTimeoutEscalate, dto.ApprovalTimeoutApprove, dto.ApprovalTimeoutReject:
+	default:
+		return nil, fmt.Errorf("不支持的超时处理方式: %s", snapshot.TimeoutAction)
+	}
+
+	seen := make(map[string]bool)
+	add := func(username string) {
+		if username != "" && !seen[username] {
+			seen[username] = true
+			snapshot.Approvers = append(snapshot.Approvers, username)
+		}
+	}
+	for _, username := range node.Users {
+		add(username)
+	}
+	if node.LeaderLevel > 0 {
+		leader, err := s.findLeader(header, initiator, node.LeaderLevel)
+		if err != nil {
+			return nil, err
+		}
+		add(leader)
+	}
+	for _, department := range node.Departments {
+		users, err := apicall.GetUsersByDepartment(header, department)
+		if err != nil {
+			return nil, fmt.Errorf("获取部门 %s 成员失败: %w", department, err)
+		}
+		for _, user := range users {
+			add(user.Username)
+		}
+	}
+	if len(snapshot.Approvers) == 0 {
+		return nil, fmt.Errorf("没有审批人")
+	}
+
+	if snapshot.TimeoutMinutes > 0 && snapshot.TimeoutAction == dto.ApprovalTimeoutEscalate {
+		snapshot.Escalation = make(map[string]string, len(snapshot.Approvers))
+		for _, approver := range snapshot.Approvers {
+			if target := s.resolveEscalateTarget(header, node, approver); target != "" {
+				snapshot.Escalation[approver] = target
+			}
+		}
+	}
+	return snapshot, nil
+}
+
+// findLeader 查找用户的第 level 级 Leader
+func (s *ApprovalService) findLeader(header *apicall.Header, username string, level int) (string, error) {
+	current := username
+	for i := 0; i < level; i++ {
+		user, err := apicall.GetUserByUsername(header, current)
+		if err != nil {
+			return "", fmt.Errorf("获取用户 %s 信息失败: %w", current, err)
+		}
+		if user.LeaderUsername == "" {
+			return "", fmt.Errorf("用户 %s 没有第 %d 级 Leader", username, i+1)
+		}
+		current = user.LeaderUsername
+	}
+	return current, nil
+}
+
+// resolveEscalateTarget 解析审批人超时后升级给谁：优先使用节点配置的升级人，否则为审批人的直属 Leader
+func (s *ApprovalService) resolveEscalateTarget(header *apicall.Header, node *dto.ApprovalNode, approver string) string {
+	for _, target := range node.EscalateTo {
+		if target != "" && target != approver {
+			return target
+		}
+	}
+	leader, err := s.findLeader(header, approver, 1)
+	if err != nil {
+		return ""
+	}
+	return leader
+}
+
+// ============================================
+// 通知和操作日志
+// ============================================
+
+// notifyTasks 通知审批人有新的待办
+func (s *ApprovalService) notifyTasks(ctx context.Context, instance *model.ApprovalInstance, tasks []*model.ApprovalTask) {
+	var users []string
+	for _, task := range tasks {
+		if task.Status == model.ApprovalTaskPending {
+			users = append(users, task.Assignee)
+		}
+	}
+	if len(users) == 0 {
+		return
+	}
+	s.sendNotification(ctx, instance, users, &dto.SendNotificationTemplate{
+		Code:    "approval_todo",
+		Title:   "待审批：{{.title}}",
+		Content: "{{.initiator}} 发起的「{{.process}}」等待你审批",
+		Level:   "info",
+		Link:    "/approval/instance/{{.instance_id}}",
+	})
+}
+
+// notifyFinished 通知发起人审批结果
+func (s *ApprovalService) notifyFinished(ctx context.Context, instance *model.ApprovalInstance, operator, comment string) {
+	if instance.Initiator == operator && instance.Status == dto.ApprovalStatusCanceled {
+		return
+	}
+	level := "success"
+	text := "已通过"
+	switch instance.Status {
+	case dto.ApprovalStatusRejected:
+		level, text = "error", "已被 "+operator+" 拒绝"
+	case dto.ApprovalStatusCanceled:
+		level, text = "warning", "已撤回"
+	}
+	s.sendNotification(ctx, instance, []string{instance.Initiator}, &dto.SendNotificationTemplate{
+		Code:    "approval_finished",
+		Title:   "审批" + text + "：{{.title}}",
+		Content: "你发起的「{{.process}}」" + text + "{{if .comment}}，意见：{{.comment}}{{end}}",
+		Level:   level,
+		Link:    "/approval/instance/{{.instance_id}}",
+	}, "comment", comment)
+}
+
+func (s *ApprovalService) sendNotification(ctx context.Context, instance *model.ApprovalInstance, users []string, tpl *dto.SendNotificationTemplate, extra ...string) {
+	if s.notificationService == nil {
+		return
+	}
+	data := map[string]interface{}{
+		"instance_id": instance.ID,
+		"title":       instance.Title,
+		"process":     instance.ProcessName,
+		"initiator":   instance.Initiator,
+	}
+	for i := 0; i+1 < len(extra); i += 2 {
+		data[extra[i]] = extra[i+1]
+	}
+	_, err := s.notificationService.Send(ctx, s.pushConn(), &dto.SendNotificationReq{
+		User:         instance.TenantUser,
+		App:          instance.App,
+		FullCodePath: instance.FullCodePath,
+		RequestUser:  instance.Initiator,
+		TraceID:      instance.TraceID,
+		Users:        users,
+		Template:     *tpl,
+		Data:         data,
+	})
+	if err != nil {
+		logger.Warnf(ctx, "[ApprovalService] 发送审批通知失败: instance=%d, err=%v", instance.ID, err)
+	}
+}
+
+// pushConn 获取一个 NATS 连接用于广播站内信（没有可用连接时只推送给本实例的订阅者）
+func (s *ApprovalService) pushConn() *nats.Conn {
+	for hostId := range s.natsService.hostIdMap {
+		if conn, err := s.natsService.GetNatsByHost(hostId); err == nil {
+			return conn
+		}
+	}
+	return nil
+}
+
+// recordOperateLog 记录审批操作日志：关联了记录的写入 Table 操作日志（展示在记录的变更历史中），否则写入 Form 操作日志
+func (s *ApprovalService) recordOperateLog(ctx context.Context, instance *model.ApprovalInstance, operator, action string, detail map[string]interface{}) {
+	detail["instance_id"] = instance.ID
+	body, err := json.Marshal(detail)
+	if err != nil {
+		logger.Warnf(ctx, "[ApprovalService] 序列化审批操作日志失败: %v", err)
+		return
+	}
+
+	if instance.RowID > 0 {
+		log := &model.TableOperateLog{
+			TenantUser:   instance.TenantUser,
+			RequestUser:  operator,
+			Action:       approvalOperateLogPrefix + action,
+			App:          instance.App,
+			FullCodePath: instance.FullCodePath,
+			RowID:        instance.RowID,
+			Updates:      body,
+			TraceID:      instance.TraceID,
+			Version:      instance.Version,
+		}
+		if err := s.operateLogRepo.CreateTableOperateLog(log); err != nil {
+			logger.Warnf(ctx, "[ApprovalService] 记录审批操作日志失败: %v", err)
+		}
+		return
+	}
+
+	log := &model.FormOperateLog{
+		TenantUser:     instance.TenantUser,
+		RequestUser:    operator,
+		Action:         approvalOperateLogPrefix + action,
+		App:            instance.App,
+		FullCodePath:   instance.FullCodePath,
+		FunctionMethod: "POST",
+		RequestBody:    body,
+		TraceID:        instance.TraceID,
+		Version:        instance.Version,
+	}
+	if err := s.operateLogRepo.CreateFormOperateLog(log); err != nil {
+		logger.Warnf(ctx, "[ApprovalService] 记录审批操作日志失败: %v", err)
+	}
+}
+
+// ============================================
+// NATS
+// ============================================
+
+// initSubscriptions 初始化 NATS 订阅（所有主机）
+func (s *ApprovalService) initSubscriptions() {
+	for hostId := range s.natsService.hostIdMap {
+		conn, err := s.natsService.GetNatsByHost(hostId)
+		if err != nil {
+			continue
+		}
+		// 使用队列组，多个 app-server 实例只有一个会处理
+		sub, err := conn.QueueSubscribe(subjects.GetAppServerApprovalStartSubject(), "app-server", s.handleStartRequest)
+		if err != nil {
+			logger.Errorf(context.Background(), "[ApprovalService] Failed to subscribe approval subject on host %d: %v", hostId, err)
+			continue
+		}
+		s.subs = append(s.subs, sub)
+	}
+}
+
+// handleStartRequest 处理 SDK App 的发起审批请求
+func (s *ApprovalService) handleStartRequest(msg *nats.Msg) {
+	ctx := context.Background()
+
+	if !license.GetManager().HasFeature(enterprise.FeatureApproval) {
+		msgx.RespFailMsg(msg, fmt.Errorf("此功能需要企业版 License：审批流程，请升级到企业版"))
+		return
+	}
+
+	info, err := msgx.DecodeNatsMsg[dto.StartApprovalReq](msg)
+	if err != nil {
+		msgx.RespFailMsg(msg, fmt.Errorf("解析请求失败: %w", err))
+		return
+	}
+
+	resp, err := s.Start(ctx, &info.Data)
+	if err != nil {
+		logger.Errorf(ctx, "[ApprovalService] 发起审批失败: path=%s, err=%v", info.Data.FullCodePath, err)
+		msgx.RespFailMsg(msg, err)
+		return
+	}
+	if err := msgx.RespSuccessMsg(msg, resp); err != nil {
+		logger.Errorf(ctx, "[ApprovalService] 响应发起审批请求失败: %v", err)
+	}
+}
+
+// Close 关闭服务（取消订阅、停止超时检查）
+func (s *ApprovalService) Close() error {
+	close(s.stopCh)
+	for _, sub := range s.subs {
+		if err := sub.Unsubscribe(); err != nil {
+			logger.Warnf(context.Background(), "[ApprovalService] Failed to unsubscribe: %v", err)
+		}
+	}
+	s.subs = nil
+	return nil
+}
+
+// ============================================
+// 辅助函数
+// ============================================
+
+// getInstanceWithNodes 获取审批实例及其流程节点快照
+func (s *ApprovalService) getInstanceWithNodes(id int64) (*model.ApprovalInstance, []*approvalNodeSnapshot, error) {
+	instance, err := s.approvalRepo.GetInstance(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, fmt.Errorf("审批不存在")
+		}
+		return nil, nil, fmt.Errorf("获取审批失败: %w", err)
+	}
+	nodes, err := decodeApprovalNodes(instance.Definition)
+	if err != nil {
+		return nil, nil, err
+	}
+	return instance, nodes, nil
+}
+
+// convertInstancesWithTasks 转换审批实例（包含所有任务）
+func (s *ApprovalService) convertInstancesWithTasks(instances []*model.ApprovalInstance) ([]dto.ApprovalInstanceInfo, error) {
+	ids := make([]int64, 0, len(instances))
+	for _, instance := range instances {
+		ids = append(ids, instance.ID)
+	}
+	tasks, err := s.approvalRepo.ListTasksByInstances(ids)
+	if err != nil {
+		return nil, fmt.Errorf("获取审批任务失败: %w", err)
+	}
+	taskMap := make(map[int64][]*model.ApprovalTask, len(instances))
+	for _, task := range tasks {
+		taskMap[task.InstanceID] = append(taskMap[task.InstanceID], task)
+	}
+
+	infos := make([]dto.ApprovalInstanceInfo, 0, len(instances))
+	for _, instance := range instances {
+		infos = append(infos, convertApprovalInstance(instance, taskMap[instance.ID]))
+	}
+	return infos, nil
+}
+
+// buildApprovalNodeTasks 创建节点的审批任务（依次审批时只有第一个人是待审批，其他人等待）
+func buildApprovalNodeTasks(nodeIndex int, node *approvalNodeSnapshot, now time.Time) []*model.ApprovalTask {
+	tasks := make([]*model.ApprovalTask, 0, len(node.Approvers))
+	for i, approver := range node.Approvers {
+		task := &model.ApprovalTask{
+			NodeIndex:  nodeIndex,
+			NodeName:   nodeDisplayName(&node.ApprovalNode, nodeIndex),
+			Seq:        i,
+			Assignee:   approver,
+			Status:     model.ApprovalTaskPending,
+			EscalateTo: node.Escalation[approver],
+		}
+		if node.Mode == dto.ApprovalModeSequential && i > 0 {
+			task.Status = model.ApprovalTaskWaiting
+		} else {
+			task.DueAt = approvalDueAt(&node.ApprovalNode, now)
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks
+}
+
+// approvalDueAt 计算任务超时时间，节点未配置超时时返回 nil
+func approvalDueAt(node *dto.ApprovalNode, now time.Time) *time.Time {
+	if node.TimeoutMinutes <= 0 {
+		return nil
+	}
+	due := now.Add(time.Duration(node.TimeoutMinutes) * time.Minute)
+	return &due
+}
+
+func nodeDisplayName(node *dto.ApprovalNode, index int) string {
+	if node.Name != "" {
+		return node.Name
+	}
+	return fmt.Sprintf("节点%d", index+1)
+}
+
+func decodeApprovalNodes(definition json.RawMessage) ([]*approvalNodeSnapshot, error) {
+	var nodes []*approvalNodeSnapshot
+	if err := json.Unmarshal(definition, &nodes); err != nil {
+		return nil, fmt.Errorf("解析审批流程失败: %w", err)
+	}
+	if len(nodes) == 0 {
+		return nil, fmt.Errorf("审批流程没有节点")
+	}
+	return nodes, nil
+}
+
+func normalizePage(page, pageSize int) (int, int) {
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 || pageSize > 100 {
+		pageSize = 20
+	}
+	return page, pageSize
+}
+
+func formatOptionalTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.DateTime)
+}
+
+func convertApprovalTask(task *model.ApprovalTask) dto.ApprovalTaskInfo {
+	return dto.ApprovalTaskInfo{
+		ID:            task.ID,
+		InstanceID:    task.InstanceID,
+		NodeIndex:     task.NodeIndex,
+		NodeName:      task.NodeName,
+		Assignee:      task.Assignee,
+		Status:        task.Status,
+		Comment:       task.Comment,
+		TransferredBy: task.TransferredBy,
+		DueAt:         formatOptionalTime(task.DueAt),
+		ActedAt:       formatOptionalTime(task.ActedAt),
+		CreatedAt:     time.Time(task.CreatedAt).Format(time.DateTime),
+	}
+}
+
+func convertApprovalInstance(instance *model.ApprovalInstance, tasks []*model.ApprovalTask) dto.ApprovalInstanceInfo {
+	info := dto.ApprovalInstanceInfo{
+		ID:           instance.ID,
+		App:          instance.TenantUser + "/" + instance.App,
+		FullCodePath: instance.FullCodePath,
+		ProcessCode:  instance.ProcessCode,
+		ProcessName:  instance.ProcessName,
+		Title:        instance.Title,
+		RowID:        instance.RowID,
+		Initiator:    instance.Initiator,
+		Status:       instance.Status,
+		CurrentNode:  instance.CurrentNode,
+		Data:         instance.Data,
+		CreatedAt:    time.Time(instance.CreatedAt).Format(time.DateTime),
+		FinishedAt:   formatOptionalTime(instance.FinishedAt),
+	}
+	if nodes, err := decodeApprovalNodes(instance.Definition); err == nil {
+		for i, node := range nodes {
+			info.Nodes = append(info.Nodes, nodeDisplayName(&node.ApprovalNode, i))
+		}
+	}
+	for _, task := range tasks {
+		info.Tasks = append(info.Tasks, convertApprovalTask(task))
+	}
+	return info
+}
@@ -133,6 +133,14 @@ func (s *EmailService) getBody(code, codeType string) string {
 	}
 }
 
+// SendEmail 发送通用邮件（HTML 正文），供通知中心等业务使用
+func (s *EmailService) SendEmail(to, subject, body string) error {
+	if s.config.SMTP.Host == "" {
+		return fmt.Errorf("未配置SMTP服务器")
+	}
+	return s.sendEmail(to, subject, body)
+}
+
 // sendEmail 发送邮件
 func (s *EmailService) sendEmail(to, subject, body string) error {
 	// 构建MIME消息
@@ -0,0 +1,169 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/ai-agent-os/ai-agent-os/core/app-server/model"
+	"github.com/ai-agent-os/ai-agent-os/core/app-server/repository"
+	"github.com/ai-agent-os/ai-agent-os/dto"
+	"github.com/ai-agent-os/ai-agent-os/pkg/contextx"
+	"github.com/ai-agent-os/ai-agent-os/pkg/logger"
+	"gorm.io/gorm"
+)
+
+const (
+	formTemplateForm = "form"
+	formDraftMaxSize = 1 << 20 // 草稿数据最大 1MB
+)
+
+// FormDraftService 向导表单草稿服务
+// 用户填写向导表单时按步骤保存草稿，重新打开表单时从保存的步骤继续填写；
+// 表单提交成功或函数被删除时清理草稿
+type FormDraftService struct {
+	draftRepo    *repository.FormDraftRepository
+	functionRepo *repository.FunctionRepository
+}
+
+// NewFormDraftService 创建向导表单草稿服务
+func NewFormDraftService(draftRepo *repository.FormDraftRepository, functionRepo *repository.FunctionRepository, appService *AppService) *FormDraftService {
+	s := &FormDraftService{
+		draftRepo:    draftRepo,
+		functionRepo: functionRepo,
+	}
+
+	// 表单提交成功后删除提交人的草稿
+	appService.OnFormEvent(s.handleFormEvent)
+	// 函数删除后删除所有草稿
+	appService.OnFunctionChange(s.handleFunctionChange)
+
+	return s
+}
+
+// formWizardConfig 向导表单的模板配置（SDK 注册时生成）
+type formWizardConfig struct {
+	Steps []struct {
+		Name string `json:"name"`
+	} `json:"steps"`
+}
+
+// GetDraft 获取当前用户的草稿，没有草稿时返回 Draft 为空
+func (s *FormDraftService) GetDraft(ctx context.Context, fullCodePath string) (*dto.GetFormDraftResp, error) {
+	username := contextx.GetRequestUser(ctx)
+
+	draft, err := s.draftRepo.Get(fullCodePath, username)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return &dto.GetFormDraftResp{}, nil
+		}
+		return nil, fmt.Errorf("获取草稿失败: %w", err)
+	}
+	return &dto.GetFormDraftResp{Draft: toFormDraftInfo(draft)}, nil
+}
+
+// SaveDraft 保存当前用户的草稿（每个用户每个表单只有一份草稿，重复保存时覆盖）
+func (s *FormDraftService) SaveDraft(ctx context.Context, fullCodePath string, req *dto.SaveFormDraftReq) (*dto.SaveFormDraftResp, error) {
+	username := contextx.GetRequestUser(ctx)
+
+	function, err := s.functionRepo.GetFunctionByFullCodePath(fullCodePath)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("函数不存在: %s", fullCodePath)
+		}
+		return nil, fmt.Errorf("获取函数失败: %w", err)
+	}
+	if function.GetTemplateType() != formTemplateForm {
+		return nil, fmt.Errorf("只有表单函数可以保存草稿: %s", fullCodePath)
+	}
+
+	if len(req.Data) > formDraftMaxSize {
+		return nil, fmt.Errorf("草稿数据不能超过 %d 字节", formDraftMaxSize)
+	}
+	var data map[string]interface{}
+	if err := json.Unmarshal(req.Data, &data); err != nil || data == nil {
+		return nil, fmt.Errorf("草稿数据必须是 JSON 对象")
+	}
+
+	// 向导表单的步骤必须存在，普通表单不记录步骤
+	var config formWizardConfig
+	if len(function.TemplateConfig) > 0 {
+		_ = json.Unmarshal(function.TemplateConfig, &config)
+	}
+	if len(config.Steps) == 0 {
+		req.Step = ""
+	} else if req.Step != "" {
+		found := false
+		for _, step := range config.Steps {
+			if step.Name == req.Step {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("步骤不存在: %s", req.Step)
+		}
+	}
+
+	draft, err := s.draftRepo.Get(fullCodePath, username)
+	if err != nil {
+		if err != gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("获取草稿失败: %w", err)
+		}
+		draft = &model.FormDraft{}
+		draft.CreatedBy = username
+	}
+	draft.AppID = function.AppID
+	draft.FullCodePath = fullCodePath
+	draft.Step = req.Step
+	draft.Data = req.Data
+	draft.UpdatedBy = username
+
+	if err := s.draftRepo.Save(draft); err != nil {
+		return nil, fmt.Errorf("保存草稿失败: %w", err)
+	}
+	return &dto.SaveFormDraftResp{Draft: toFormDraftInfo(draft)}, nil
+}
+
+// DeleteDraft 删除当前用户的草稿
+func (s *FormDraftService) DeleteDraft(ctx context.Context, fullCodePath string) error {
+	username := contextx.GetRequestUser(ctx)
+	if err := s.draftRepo.Delete(fullCodePath, username); err != nil {
+		return fmt.Errorf("删除草稿失败: %w", err)
+	}
+	return nil
+}
+
+// handleFormEvent 表单提交成功后删除提交人的草稿
+func (s *FormDraftService) handleFormEvent(ctx context.Context, event *FormEvent) {
+	if event.RequestUser == "" {
+		return
+	}
+	if err := s.draftRepo.Delete(event.FullCodePath, event.RequestUser); err != nil {
+		logger.Errorf(ctx, "[FormDraftService] 删除草稿失败: path=%s, user=%s, err=%v", event.FullCodePath, event.RequestUser, err)
+	}
+}
+
+// handleFunctionChange 函数删除后删除其所有草稿
+func (s *FormDraftService) handleFunctionChange(ctx context.Context, event *FunctionChangeEvent) {
+	if len(event.Deleted) == 0 {
+		return
+	}
+	paths := make([]string, 0, len(event.Deleted))
+	for _, api := range event.Deleted {
+		paths = append(paths, api.BuildFullCodePath())
+	}
+	if err := s.draftRepo.DeleteByFullCodePaths(paths); err != nil {
+		logger.Errorf(ctx, "[FormDraftService] 删除已删除函数的草稿失败: app_id=%d, err=%v", event.AppID, err)
+	}
+}
+
+// toFormDraftInfo 草稿转换为接口返回的信息
+func toFormDraftInfo(draft *model.FormDraft) *dto.FormDraftInfo {
+	return &dto.FormDraftInfo{
+		Step:      draft.Step,
+		Data:      draft.Data,
+		UpdatedAt: time.Time(draft.UpdatedAt).Format(time.DateTime),
+	}
+}
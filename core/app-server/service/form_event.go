@@ -0,0 +1,36 @@
+package service
+
+import (
+	"context"
+
+	"github.com/ai-agent-os/ai-agent-os/pkg/logger"
+)
+
+// FormEvent 表单提交成功事件（通过标准接口提交表单成功后触发）
+type FormEvent struct {
+	FullCodePath string // Form 函数完整路径
+	RequestUser  string // 提交人
+	TraceID      string // 链路ID
+}
+
+// FormEventHandler 表单提交事件处理函数（同步调用，耗时操作需要自行异步处理）
+type FormEventHandler func(ctx context.Context, event *FormEvent)
+
+// OnFormEvent 订阅表单提交事件，只能在服务初始化阶段调用
+func (a *AppService) OnFormEvent(handler FormEventHandler) {
+	a.formEventHandlers = append(a.formEventHandlers, handler)
+}
+
+// EmitFormEvent 发布表单提交事件，订阅者的 panic 不会影响调用方
+func (a *AppService) EmitFormEvent(ctx context.Context, event *FormEvent) {
+	for _, handler := range a.formEventHandlers {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					logger.Errorf(ctx, "[AppService] 处理表单事件 panic: path=%s, err=%v", event.FullCodePath, r)
+				}
+			}()
+			handler(ctx, event)
+		}()
+	}
+}
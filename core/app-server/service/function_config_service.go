@@ -0,0 +1,520 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"time"
+
+	"github.com/ai-agent-os/ai-agent-os/core/app-server/model"
+	"github.com/ai-agent-os/ai-agent-os/core/app-server/repository"
+	"github.com/ai-agent-os/ai-agent-os/dto"
+	appPkg "github.com/ai-agent-os/ai-agent-os/pkg/app"
+	"github.com/ai-agent-os/ai-agent-os/pkg/config"
+	"github.com/ai-agent-os/ai-agent-os/pkg/contextx"
+	"github.com/ai-agent-os/ai-agent-os/pkg/logger"
+	"github.com/ai-agent-os/ai-agent-os/pkg/msgx"
+	"github.com/ai-agent-os/ai-agent-os/pkg/subjects"
+	"github.com/ai-agent-os/ai-agent-os/sdk/agent-app/widget"
+	"github.com/nats-io/nats.go"
+	"gorm.io/gorm"
+)
+
+// FunctionConfigService 函数运行时配置服务
+// 配置结构由 SDK 中的 BaseConfig.Config 声明（随 API 更新写入 Function.Config），
+// 这里负责配置值的版本化存储、校验、对比、回滚，以及推送到运行中的应用
+type FunctionConfigService struct {
+	configRepo   *repository.FunctionConfigRepository
+	functionRepo *repository.FunctionRepository
+	appRepo      *repository.AppRepository
+	natsService  *NatsService
+	tokenKey     []byte // 校验 app-runtime 签发的应用令牌
+	subs         []*nats.Subscription
+}
+
+// NewFunctionConfigService 创建函数运行时配置服务
+func NewFunctionConfigService(cfg *config.AppServerConfig, configRepo *repository.FunctionConfigRepository, functionRepo *repository.FunctionRepository, appRepo *repository.AppRepository, natsService *NatsService) *FunctionConfigService {
+	s := &FunctionConfigService{
+		configRepo:   configRepo,
+		functionRepo: functionRepo,
+		appRepo:      appRepo,
+		natsService:  natsService,
+		tokenKey:     []byte(cfg.GetJWT().Secret),
+	}
+
+	// 初始化订阅（SDK App 首次读取配置时通过该主题获取）
+	s.initSubscriptions()
+
+	return s
+}
+
+// GetConfig 获取函数配置（结构 + 默认值 + 当前值）
+func (s *FunctionConfigService) GetConfig(ctx context.Context, fullCodePath string) (*dto.GetFunctionConfigResp, error) {
+	function, fields, err := s.getConfigFunction(fullCodePath)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &dto.GetFunctionConfigResp{
+		FullCodePath: fullCodePath,
+		Schema:       function.Config,
+		Defaults:     function.ConfigDefault,
+	}
+
+	current, err := s.configRepo.GetCurrent(fullCodePath)
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, fmt.Errorf("获取当前配置失败: %w", err)
+	}
+
+	var currentValues json.RawMessage
+	if current != nil {
+		currentValues = current.Values
+		resp.Version = current.Version
+		resp.Remark = current.Remark
+		resp.UpdatedBy = current.CreatedBy
+		resp.UpdatedAt = time.Time(current.CreatedAt).Format(time.DateTime)
+	}
+
+	values, err := mergeConfigValues(fields, function.ConfigDefault, currentValues)
+	if err != nil {
+		return nil, err
+	}
+	resp.Values, err = json.Marshal(values)
+	if err != nil {
+		return nil, fmt.Errorf("序列化配置失败: %w", err)
+	}
+	return resp, nil
+}
+
+// UpdateConfig 更新函数配置，生成新版本并推送到运行中的应用
+// 提交的值会按配置结构校验（不允许未声明的字段，类型必须匹配），未提交的字段沿用当前值
+func (s *FunctionConfigService) UpdateConfig(ctx context.Context, fullCodePath string, req *dto.UpdateFunctionConfigReq) (*dto.UpdateFunctionConfigResp, error) {
+	function, fields, err := s.getConfigFunction(fullCodePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var submitted map[string]interface{}
+	if err := json.Unmarshal(req.Values, &submitted); err != nil {
+		return nil, fmt.Errorf("配置格式错误，必须是 JSON 对象: %w", err)
+	}
+	if err := validateConfigValues(fields, submitted); err != nil {
+		return nil, err
+	}
+
+	current, err := s.configRepo.GetCurrent(fullCodePath)
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, fmt.Errorf("获取当前配置失败: %w", err)
+	}
+	var currentValues json.RawMessage
+	if current != nil {
+		currentValues = current.Values
+	}
+	values, err := mergeConfigValues(fields, function.ConfigDefault, currentValues)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range submitted {
+		values[k] = v
+	}
+
+	version, err := s.createVersion(ctx, function, fullCodePath, values, req.Remark, 0)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.UpdateFunctionConfigResp{Version: version.Version}, nil
+}
+
+// ListHistory 获取配置版本历史
+func (s *FunctionConfigService) ListHistory(ctx context.Context, fullCodePath string, req *dto.FunctionConfigHistoryReq) (*dto.FunctionConfigHistoryResp, error) {
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.PageSize <= 0 {
+		req.PageSize = 20
+	}
+
+	versions, total, err := s.configRepo.ListVersions(fullCodePath, req.Page, req.PageSize)
+	if err != nil {
+		return nil, fmt.Errorf("获取配置历史失败: %w", err)
+	}
+
+	resp := &dto.FunctionConfigHistoryResp{
+		Versions: make([]dto.FunctionConfigVersionInfo, 0, len(versions)),
+		Total:    total,
+		Page:     req.Page,
+		PageSize: req.PageSize,
+	}
+	for _, v := range versions {
+		resp.Versions = append(resp.Versions, dto.FunctionConfigVersionInfo{
+			Version:      v.Version,
+			Values:       v.Values,
+			Remark:       v.Remark,
+			RollbackFrom: v.RollbackFrom,
+			IsCurrent:    v.IsCurrent,
+			CreatedBy:    v.CreatedBy,
+			CreatedAt:    time.Time(v.CreatedAt).Format(time.DateTime),
+		})
+	}
+	return resp, nil
+}
+
+// Diff 对比两个配置版本（版本 0：from 表示代码默认值，to 表示当前版本）
+func (s *FunctionConfigService) Diff(ctx context.Context, fullCodePath string, req *dto.FunctionConfigDiffReq) (*dto.FunctionConfigDiffResp, error) {
+	function, fields, err := s.getConfigFunction(fullCodePath)
+	if err != nil {
+		return nil, err
+	}
+
+	to := req.To
+	if to == 0 {
+		current, err := s.configRepo.GetCurrent(fullCodePath)
+		if err != nil && err != gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("获取当前配置失败: %w", err)
+		}
+		if current != nil {
+			to = current.Version
+		}
+	}
+
+	fromValues, err := s.getVersionValues(function, fields, fullCodePath, req.From)
+	if err != nil {
+		return nil, err
+	}
+	toValues, err := s.getVersionValues(function, fields, fullCodePath, to)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.FunctionConfigDiffResp{
+		From:    req.From,
+		To:      to,
+		Changes: diffConfigValues(fields, fromValues, toValues),
+	}, nil
+}
+
+// Rollback 回滚到指定版本（以该版本的值生成一个新版本，历史不会被改写）
+func (s *FunctionConfigService) Rollback(ctx context.Context, fullCodePath string, req *dto.FunctionConfigRollbackReq) (*dto.UpdateFunctionConfigResp, error) {
+	function, fields, err := s.getConfigFunction(fullCodePath)
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := s.configRepo.GetByVersion(fullCodePath, req.Version)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("配置版本不存在: v%d", req.Version)
+		}
+		return nil, fmt.Errorf("获取配置版本失败: %w", err)
+	}
+
+	// 以当前代码中的配置结构重新合并，已删除的字段会被丢弃，新增的字段使用默认值
+	values, err := mergeConfigValues(fields, function.ConfigDefault, target.Values)
+	if err != nil {
+		return nil, err
+	}
+
+	remark := req.Remark
+	if remark == "" {
+		remark = fmt.Sprintf("回滚到 v%d", req.Version)
+	}
+	version, err := s.createVersion(ctx, function, fullCodePath, values, remark, req.Version)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.UpdateFunctionConfigResp{Version: version.Version}, nil
+}
+
+// createVersion 保存新版本并推送到运行中的应用
+func (s *FunctionConfigService) createVersion(ctx context.Context, function *model.Function, fullCodePath string, values map[string]interface{}, remark string, rollbackFrom int) (*model.FunctionConfigVersion, error) {
+	data, err := json.Marshal(values)
+	if err != nil {
+		return nil, fmt.Errorf("序列化配置失败: %w", err)
+	}
+
+	version := &model.FunctionConfigVersion{
+		AppID:        function.AppID,
+		FullCodePath: fullCodePath,
+		Values:       data,
+		Remark:       remark,
+		RollbackFrom: rollbackFrom,
+	}
+	requestUser := contextx.GetRequestUser(ctx)
+	version.CreatedBy = requestUser
+	version.UpdatedBy = requestUser
+	if err := s.configRepo.CreateVersion(version); err != nil {
+		return nil, fmt.Errorf("保存配置失败: %w", err)
+	}
+
+	logger.Infof(ctx, "[FunctionConfigService] 配置已更新: path=%s, version=%d, rollback_from=%d, operator=%s",
+		fullCodePath, version.Version, rollbackFrom, requestUser)
+
+	s.notifyConfigUpdate(ctx, function.AppID, &dto.FunctionConfigRuntime{
+		FullCodePath: fullCodePath,
+		Version:      version.Version,
+		Values:       version.Values,
+	})
+	return version, nil
+}
+
+// getConfigFunction 获取声明了运行时配置的函数，并解析配置结构
+func (s *FunctionConfigService) getConfigFunction(fullCodePath string) (*model.Function, []*widget.Field, error) {
+	function, err := s.functionRepo.GetFunctionByFullCodePath(fullCodePath)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil, fmt.Errorf("函数不存在: %s", fullCodePath)
+		}
+		return nil, nil, fmt.Errorf("获取函数失败: %w", err)
+	}
+	if !function.HasConfig || len(function.Config) == 0 {
+		return nil, nil, fmt.Errorf("函数未声明运行时配置: %s", fullCodePath)
+	}
+
+	var fields []*widget.Field
+	if err := json.Unmarshal(function.Config, &fields); err != nil {
+		return nil, nil, fmt.Errorf("解析配置结构失败: %w", err)
+	}
+	return function, fields, nil
+}
+
+// getVersionValues 获取指定版本合并默认值后的配置（版本 0 表示代码默认值）
+func (s *FunctionConfigService) getVersionValues(function *model.Function, fields []*widget.Field, fullCodePath string, version int) (map[string]interface{}, error) {
+	if version == 0 {
+		return mergeConfigValues(fields, function.ConfigDefault, nil)
+	}
+	v, err := s.configRepo.GetByVersion(fullCodePath, version)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("配置版本不存在: v%d", version)
+		}
+		return nil, fmt.Errorf("获取配置版本失败: %w", err)
+	}
+	return mergeConfigValues(fields, function.ConfigDefault, v.Values)
+}
+
+// mergeConfigValues 以默认值为底，用已保存的值覆盖，只保留配置结构中声明的字段
+func mergeConfigValues(fields []*widget.Field, defaults, values json.RawMessage) (map[string]interface{}, error) {
+	merged := make(map[string]interface{})
+	if len(defaults) > 0 {
+		if err := json.Unmarshal(defaults, &merged); err != nil {
+			return nil, fmt.Errorf("解析配置默认值失败: %w", err)
+		}
+	}
+	if len(values) > 0 {
+		var saved map[string]interface{}
+		if err := json.Unmarshal(values, &saved); err != nil {
+			return nil, fmt.Errorf("解析配置值失败: %w", err)
+		}
+		for k, v := range saved {
+			merged[k] = v
+		}
+	}
+
+	declared := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		declared[field.Code] = true
+	}
+	for k := range merged {
+		if !declared[k] {
+			delete(merged, k)
+		}
+	}
+	return merged, nil
+}
+
+// validateConfigValues 按配置结构校验提交的值
+func validateConfigValues(fields []*widget.Field, values map[string]interface{}) error {
+	fieldMap := make(map[string]*widget.Field, len(fields))
+	for _, field := range fields {
+		fieldMap[field.Code] = field
+	}
+
+	for code, value := range values {
+		field, ok := fieldMap[code]
+		if !ok {
+			return fmt.Errorf("配置项不存在: %s", code)
+		}
+		if field.Data == nil || value == nil {
+			continue
+		}
+		if !matchConfigDataType(field.Data.Type, value) {
+			return fmt.Errorf("配置项 %s(%s) 类型错误，期望 %s", field.Name, code, field.Data.Type)
+		}
+	}
+	return nil
+}
+
+// matchConfigDataType 判断 JSON 解码后的值是否符合 widget 数据类型
+func matchConfigDataType(dataType string, value interface{}) bool {
+	switch dataType {
+	case widget.DataTypeString:
+		_, ok := value.(string)
+		return ok
+	case widget.DataTypeInt:
+		n, ok := value.(float64)
+		return ok && n == math.Trunc(n)
+	case widget.DataTypeFloat:
+		_, ok := value.(float64)
+		return ok
+	case widget.DataTypeBool:
+		_, ok := value.(bool)
+		return ok
+	case widget.DataTypeStruct:
+		_, ok := value.(map[string]interface{})
+		return ok
+	case widget.DataTypeStrings, widget.DataTypeInts, widget.DataTypeFloats, widget.DataTypeStructs:
+		items, ok := value.([]interface{})
+		if !ok {
+			return false
+		}
+		itemType := map[string]string{
+			widget.DataTypeStrings: widget.DataTypeString,
+			widget.DataTypeInts:    widget.DataTypeInt,
+			widget.DataTypeFloats:  widget.DataTypeFloat,
+			widget.DataTypeStructs: widget.DataTypeStruct,
+		}[dataType]
+		for _, item := range items {
+			if !matchConfigDataType(itemType, item) {
+				return false
+			}
+		}
+		return true
+	default:
+		// 其他类型（如文件、时间戳等）不做强校验
+		return true
+	}
+}
+
+// diffConfigValues 对比两份配置，按配置结构的字段顺序输出差异
+func diffConfigValues(fields []*widget.Field, from, to map[string]interface{}) []dto.FunctionConfigDiffItem {
+	changes := make([]dto.FunctionConfigDiffItem, 0)
+	for _, field := range fields {
+		oldValue, inOld := from[field.Code]
+		newValue, inNew := to[field.Code]
+		item := dto.FunctionConfigDiffItem{Code: field.Code, Name: field.Name, Old: oldValue, New: newValue}
+		switch {
+		case !inOld && inNew:
+			item.ChangeType = "added"
+		case inOld && !inNew:
+			item.ChangeType = "removed"
+		case inOld && inNew && !reflect.DeepEqual(oldValue, newValue):
+			item.ChangeType = "modified"
+		default:
+			continue
+		}
+		changes = append(changes, item)
+	}
+	return changes
+}
+
+// notifyConfigUpdate 推送配置变更到应用当前运行的版本（SDK 收到后直接更新缓存）
+func (s *FunctionConfigService) notifyConfigUpdate(ctx context.Context, appID int64, cfg *dto.FunctionConfigRuntime) {
+	appModel, err := s.appRepo.GetAppByID(appID)
+	if err != nil {
+		logger.Warnf(ctx, "[FunctionConfigService] 获取应用失败，跳过配置推送: app_id=%d, err=%v", appID, err)
+		return
+	}
+	if appModel.Version == "" {
+		return
+	}
+	conn, err := s.natsService.GetNatsByHost(appModel.HostID)
+	if err != nil {
+		logger.Warnf(ctx, "[FunctionConfigService] 获取 NATS 连接失败，跳过配置推送: %v", err)
+		return
+	}
+
+	message := subjects.Message{
+		Type:      subjects.MessageTypeStatusConfigUpdate,
+		User:      appModel.User,
+		App:       appModel.Code,
+		Version:   appModel.Version,
+		Data:      cfg,
+		Timestamp: time.Now(),
+	}
+	data, err := json.Marshal(message)
+	if err != nil {
+		logger.Warnf(ctx, "[FunctionConfigService] 序列化配置变更通知失败: %v", err)
+		return
+	}
+
+	subject := subjects.BuildAppStatusSubject(appModel.User, appModel.Code, appModel.Version)
+	if err := conn.Publish(subject, data); err != nil {
+		logger.Warnf(ctx, "[FunctionConfigService] 推送配置变更失败: subject=%s, err=%v", subject, err)
+	}
+}
+
+// initSubscriptions 初始化 NATS 订阅（所有主机）
+func (s *FunctionConfigService) initSubscriptions() {
+	for hostId := range s.natsService.hostIdMap {
+		conn, err := s.natsService.GetNatsByHost(hostId)
+		if err != nil {
+			continue
+		}
+
+		// 使用队列组，多个 app-server 实例只有一个会响应
+		sub, err := conn.QueueSubscribe(subjects.GetAppServerConfigRequestSubject(), "app-server", s.handleConfigRequest)
+		if err != nil {
+			logger.Errorf(context.Background(), "[FunctionConfigService] Failed to subscribe config subject on host %d: %v", hostId, err)
+			continue
+		}
+		s.subs = append(s.subs, sub)
+	}
+}
+
+// handleConfigRequest 处理 SDK App 的配置读取请求
+// 只返回已保存的值，默认值由 SDK 根据代码中的声明填充
+func (s *FunctionConfigService) handleConfigRequest(msg *nats.Msg) {
+	ctx := context.Background()
+
+	info, err := msgx.DecodeNatsMsg[dto.GetFunctionConfigRuntimeReq](msg)
+	if err != nil {
+		msgx.RespFailMsg(msg, fmt.Errorf("解析请求失败: %w", err))
+		return
+	}
+
+	// 请求必须携带 app-runtime 为该应用签发的令牌，防止任意 NATS 客户端冒充其他应用读取配置
+	if !appPkg.VerifySecretToken(s.tokenKey, info.Data.User, info.Data.App, info.Data.Token) {
+		logger.Warnf(ctx, "[FunctionConfigService] 拒绝配置请求，令牌与应用不匹配: app=%s/%s", info.Data.User, info.Data.App)
+		msgx.RespFailMsg(msg, fmt.Errorf("无权读取应用 %s/%s 的配置", info.Data.User, info.Data.App))
+		return
+	}
+
+	// 只允许读取本应用的函数配置
+	appModel, err := s.appRepo.GetAppByUserName(info.Data.User, info.Data.App)
+	if err != nil {
+		msgx.RespFailMsg(msg, fmt.Errorf("应用不存在: %s/%s", info.Data.User, info.Data.App))
+		return
+	}
+
+	resp := &dto.FunctionConfigRuntime{FullCodePath: info.Data.FullCodePath}
+	current, err := s.configRepo.GetCurrent(info.Data.FullCodePath)
+	if err != nil && err != gorm.ErrRecordNotFound {
+		msgx.RespFailMsg(msg, fmt.Errorf("获取当前配置失败: %w", err))
+		return
+	}
+	if current != nil {
+		if current.AppID != appModel.ID {
+			msgx.RespFailMsg(msg, fmt.Errorf("无权读取配置: %s", info.Data.FullCodePath))
+			return
+		}
+		resp.Version = current.Version
+		resp.Values = current.Values
+	}
+
+	if err := msgx.RespSuccessMsg(msg, resp); err != nil {
+		logger.Errorf(ctx, "[FunctionConfigService] 响应配置请求失败: path=%s, err=%v", info.Data.FullCodePath, err)
+	}
+}
+
+// Close 关闭订阅
+func (s *FunctionConfigService) Close() error {
+	for _, sub := range s.subs {
+		if sub != nil {
+			_ = sub.Unsubscribe()
+		}
+	}
+	s.subs = nil
+	return nil
+}
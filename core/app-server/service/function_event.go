@@ -0,0 +1,37 @@
+package service
+
+import (
+	"context"
+
+	"github.com/ai-agent-os/ai-agent-os/dto"
+	"github.com/ai-agent-os/ai-agent-os/pkg/logger"
+)
+
+// FunctionChangeEvent 函数结构变更事件（应用更新时 processAPIDiff 更新、删除函数记录后触发）
+type FunctionChangeEvent struct {
+	AppID   int64
+	Updated []*dto.ApiInfo // 结构发生变化的函数（变化后的结构）
+	Deleted []*dto.ApiInfo // 删除的函数
+}
+
+// FunctionChangeHandler 函数结构变更事件处理函数（同步调用，处理失败只记录日志，不影响应用更新）
+type FunctionChangeHandler func(ctx context.Context, event *FunctionChangeEvent)
+
+// OnFunctionChange 订阅函数结构变更事件，只能在服务初始化阶段调用
+func (a *AppService) OnFunctionChange(handler FunctionChangeHandler) {
+	a.functionChangeHandlers = append(a.functionChangeHandlers, handler)
+}
+
+// emitFunctionChange 发布函数结构变更事件，订阅者的 panic 不会影响调用方
+func (a *AppService) emitFunctionChange(ctx context.Context, event *FunctionChangeEvent) {
+	for _, handler := range a.functionChangeHandlers {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					logger.Errorf(ctx, "[AppService] 处理函数变更事件 panic: app_id=%d, err=%v", event.AppID, r)
+				}
+			}()
+			handler(ctx, event)
+		}()
+	}
+}
@@ -55,19 +55,20 @@ func (f *FunctionService) GetFunction(ctx context.Context, functionID int64) (*d
 
 	// 转换为响应格式
 	resp := &dto.GetFunctionResp{
-		ID:           function.ID,
-		AppID:        function.AppID,
-		TreeID:       function.TreeID,
-		Method:       function.Method,
-		Router:       function.Router,
-		HasConfig:    function.HasConfig,
-		CreateTables: function.CreateTables,
-		Callbacks:    function.Callbacks,
-		TemplateType: function.TemplateType,
-		CreatedAt:    time.Time(function.CreatedAt).Format("2006-01-02T15:04:05Z"),
-		UpdatedAt:    time.Time(function.UpdatedAt).Format("2006-01-02T15:04:05Z"),
-		CreatedBy:    function.CreatedBy, // 创建者用户名
-		FullCodePath: function.Router,    // Router 存储的就是 full-code-path
+		ID:             function.ID,
+		AppID:          function.AppID,
+		TreeID:         function.TreeID,
+		Method:         function.Method,
+		Router:         function.Router,
+		HasConfig:      function.HasConfig,
+		CreateTables:   function.CreateTables,
+		Callbacks:      function.Callbacks,
+		TemplateType:   function.TemplateType,
+		TemplateConfig: function.TemplateConfig,
+		CreatedAt:      time.Time(function.CreatedAt).Format("2006-01-02T15:04:05Z"),
+		UpdatedAt:      time.Time(function.UpdatedAt).Format("2006-01-02T15:04:05Z"),
+		CreatedBy:      function.CreatedBy, // 创建者用户名
+		FullCodePath:   function.Router,    // Router 存储的就是 full-code-path
 	}
 
 	// 将json.RawMessage转换为interface{}以便返回JSON对象
@@ -0,0 +1,801 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"html"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"text/template"
+	"time"
+
+	"github.com/ai-agent-os/ai-agent-os/core/app-server/model"
+	"github.com/ai-agent-os/ai-agent-os/core/app-server/repository"
+	"github.com/ai-agent-os/ai-agent-os/dto"
+	"github.com/ai-agent-os/ai-agent-os/enterprise"
+	"github.com/ai-agent-os/ai-agent-os/pkg/apicall"
+	appPkg "github.com/ai-agent-os/ai-agent-os/pkg/app"
+	"github.com/ai-agent-os/ai-agent-os/pkg/config"
+	"github.com/ai-agent-os/ai-agent-os/pkg/contextx"
+	"github.com/ai-agent-os/ai-agent-os/pkg/license"
+	"github.com/ai-agent-os/ai-agent-os/pkg/logger"
+	"github.com/ai-agent-os/ai-agent-os/pkg/msgx"
+	"github.com/ai-agent-os/ai-agent-os/pkg/subjects"
+	"github.com/nats-io/nats.go"
+	"gorm.io/gorm"
+)
+
+const (
+	notificationWebhookMaxAttempts = 3                // Webhook 最大投递次数
+	notificationWebhookBackoff     = time.Second      // Webhook 重试退避基数（1s、2s、4s...）
+	notificationWebhookTimeout     = 10 * time.Second // Webhook 单次请求超时
+	notificationSubscriberBuffer   = 16               // SSE 订阅者缓冲区大小
+)
+
+// notificationLevels 支持的通知级别
+var notificationLevels = map[string]bool{"info": true, "success": true, "warning": true, "error": true}
+
+// NotificationService 通知中心服务
+// SDK 通过 ctx.Notify 发送通知（NATS），这里负责展开接收人、渲染模板、写入站内信，
+// 并按用户偏好投递邮件和 Webhook；站内信通过 SSE 实时推送到前端
+type NotificationService struct {
+	notificationRepo *repository.NotificationRepository
+	appRepo          *repository.AppRepository
+	casbinRuleRepo   *repository.CasbinRuleRepository // 查询应用成员，限制通知接收人范围
+	emailService     *EmailService
+	natsService      *NatsService
+	cipher           *SecretCipher // Webhook 签名密钥与应用密钥共用加密密钥
+	tokenKey         []byte        // 校验 app-runtime 签发的应用令牌
+	hub              *notificationHub
+	httpClient       *http.Client
+	subs             []*nats.Subscription
+}
+
+// notificationPushMessage 站内信推送消息（在所有 app-server 实例之间广播，由持有 SSE 连接的实例推送给前端）
+type notificationPushMessage struct {
+	Username     string               `json:"username"`
+	Notification dto.NotificationInfo `json:"notification"`
+}
+
+// NewNotificationService 创建通知中心服务
+func NewNotificationService(cfg *config.AppServerConfig, notificationRepo *repository.NotificationRepository, appRepo *repository.AppRepository, casbinRuleRepo *repository.CasbinRuleRepository, emailService *EmailService, natsService *NatsService) *NotificationService {
+	s := &NotificationService{
+		notificationRepo: notificationRepo,
+		appRepo:          appRepo,
+		casbinRuleRepo:   casbinRuleRepo,
+		emailService:     emailService,
+		natsService:      natsService,
+		cipher:           NewSecretCipher(cfg.GetSecretEncryptionKey()),
+		tokenKey:         []byte(cfg.GetJWT().Secret),
+		hub:              newNotificationHub(),
+		httpClient:       newWebhookHTTPClient(),
+	}
+
+	// 初始化订阅（SDK 发送通知、实例间站内信广播）
+	s.initSubscriptions()
+
+	return s
+}
+
+// Send 发送通知
+// conn 为收到发送请求的 NATS 连接，站内信通过它广播给所有 app-server 实例
+// 接收人只能是应用成员（应用所有者、触发通知的用户、在该应用下有权限的用户），其余接收人会被忽略
+func (s *NotificationService) Send(ctx context.Context, conn *nats.Conn, req *dto.SendNotificationReq) (*dto.SendNotificationResp, error) {
+	header := &apicall.Header{
+		TraceID:     req.TraceID,
+		RequestUser: req.RequestUser,
+		Token:       req.Token,
+	}
+
+	recipients, err := s.resolveRecipients(header, req.Users, req.Departments)
+	if err != nil {
+		return nil, err
+	}
+	recipients, err = s.filterAppMembers(ctx, req, recipients)
+	if err != nil {
+		return nil, err
+	}
+	if len(recipients) == 0 {
+		return nil, fmt.Errorf("通知接收人不能为空")
+	}
+
+	level := req.Template.Level
+	if level == "" {
+		level = "info"
+	}
+	if !notificationLevels[level] {
+		return nil, fmt.Errorf("不支持的通知级别: %s", level)
+	}
+	title, err := renderNotificationTemplate(req.Template.Title, req.Data)
+	if err != nil {
+		return nil, fmt.Errorf("渲染通知标题失败: %w", err)
+	}
+	if title == "" {
+		return nil, fmt.Errorf("通知标题不能为空")
+	}
+	content, err := renderNotificationTemplate(req.Template.Content, req.Data)
+	if err != nil {
+		return nil, fmt.Errorf("渲染通知内容失败: %w", err)
+	}
+	link, err := renderNotificationTemplate(req.Template.Link, req.Data)
+	if err != nil {
+		return nil, fmt.Errorf("渲染通知链接失败: %w", err)
+	}
+	data, err := json.Marshal(req.Data)
+	if err != nil {
+		return nil, fmt.Errorf("序列化通知数据失败: %w", err)
+	}
+
+	prefs, err := s.getPreferences(recipients)
+	if err != nil {
+		return nil, err
+	}
+
+	// 1. 写入站内信（只为开启了站内通知的用户写入）
+	notifications := make([]*model.Notification, 0, len(recipients))
+	for _, username := range recipients {
+		pref := prefs[username]
+		if !pref.InAppEnabled {
+			continue
+		}
+		notification := &model.Notification{
+			Username:      username,
+			App:           req.User + "/" + req.App,
+			FullCodePath:  req.FullCodePath,
+			TemplateCode:  req.Template.Code,
+			Title:         title,
+			Content:       content,
+			Level:         level,
+			Link:          link,
+			Data:          data,
+			EmailStatus:   deliveryStatus(pref.EmailEnabled),
+			WebhookStatus: deliveryStatus(pref.WebhookEnabled && pref.WebhookURL != ""),
+			TraceID:       req.TraceID,
+		}
+		notification.CreatedBy = req.RequestUser
+		notifications = append(notifications, notification)
+	}
+	if err := s.notificationRepo.CreateNotifications(notifications); err != nil {
+		return nil, fmt.Errorf("保存通知失败: %w", err)
+	}
+
+	// 2. 广播站内信（持有 SSE 连接的实例负责推送）
+	notificationIDs := make(map[string]int64, len(notifications))
+	infos := make(map[string]dto.NotificationInfo, len(recipients))
+	for _, notification := range notifications {
+		notificationIDs[notification.Username] = notification.ID
+		info := convertNotification(notification)
+		infos[notification.Username] = info
+		s.broadcast(ctx, conn, &notificationPushMessage{Username: notification.Username, Notification: info})
+	}
+
+	// 3. 异步投递邮件和 Webhook（失败不影响站内信）
+	var emailUsers []string
+	for _, username := range recipients {
+		pref := prefs[username]
+		if pref.EmailEnabled {
+			emailUsers = append(emailUsers, username)
+		}
+		if pref.WebhookEnabled && pref.WebhookURL != "" {
+			info, ok := infos[username]
+			if !ok {
+				// 未开启站内通知的用户没有站内信记录，临时构造一份用于推送
+				info = dto.NotificationInfo{
+					App:          req.User + "/" + req.App,
+					FullCodePath: req.FullCodePath,
+					TemplateCode: req.Template.Code,
+					Title:        title,
+					Content:      content,
+					Level:        level,
+					Link:         link,
+					CreatedBy:    req.RequestUser,
+					CreatedAt:    time.Now().Format(time.DateTime),
+				}
+			}
+			go s.deliverWebhook(context.Background(), notificationIDs[username], pref, info)
+		}
+	}
+	if len(emailUsers) > 0 {
+		go s.deliverEmails(context.Background(), header, emailUsers, notificationIDs, title, content, link)
+	}
+
+	logger.Infof(ctx, "[NotificationService] 发送通知: app=%s/%s, template=%s, recipients=%d, in_app=%d, email=%d",
+		req.User, req.App, req.Template.Code, len(recipients), len(notifications), len(emailUsers))
+
+	return &dto.SendNotificationResp{Recipients: recipients}, nil
+}
+
+// GetNotifications 获取当前用户的通知列表
+func (s *NotificationService) GetNotifications(ctx context.Context, req *dto.GetNotificationsReq) (*dto.GetNotificationsResp, error) {
+	username := contextx.GetRequestUser(ctx)
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.PageSize <= 0 {
+		req.PageSize = 20
+	}
+
+	notifications, total, err := s.notificationRepo.ListByUsername(username, req.UnreadOnly, req.Page, req.PageSize)
+	if err != nil {
+		return nil, fmt.Errorf("获取通知列表失败: %w", err)
+	}
+	unread, err := s.notificationRepo.CountUnread(username)
+	if err != nil {
+		return nil, fmt.Errorf("获取未读数量失败: %w", err)
+	}
+
+	resp := &dto.GetNotificationsResp{
+		Notifications: make([]dto.NotificationInfo, 0, len(notifications)),
+		Total:         total,
+		Unread:        unread,
+		Page:          req.Page,
+		PageSize:      req.PageSize,
+	}
+	for _, notification := range notifications {
+		resp.Notifications = append(resp.Notifications, convertNotification(notification))
+	}
+	return resp, nil
+}
+
+// GetUnreadCount 获取当前用户的未读通知数量
+func (s *NotificationService) GetUnreadCount(ctx context.Context) (*dto.GetUnreadCountResp, error) {
+	unread, err := s.notificationRepo.CountUnread(contextx.GetRequestUser(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("获取未读数量失败: %w", err)
+	}
+	return &dto.GetUnreadCountResp{Unread: unread}, nil
+}
+
+// MarkRead 标记通知为已读
+func (s *NotificationService) MarkRead(ctx context.Context, req *dto.MarkNotificationsReadReq) error {
+	if !req.All && len(req.IDs) == 0 {
+		return fmt.Errorf("请指定要标记的通知")
+	}
+	ids := req.IDs
+	if req.All {
+		ids = nil
+	}
+	if err := s.notificationRepo.MarkRead(contextx.GetRequestUser(ctx), ids); err != nil {
+		return fmt.Errorf("标记已读失败: %w", err)
+	}
+	return nil
+}
+
+// GetPreference 获取当前用户的通知偏好
+func (s *NotificationService) GetPreference(ctx context.Context) (*dto.NotificationPreferenceInfo, error) {
+	username := contextx.GetRequestUser(ctx)
+	pref, err := s.notificationRepo.GetPreference(username)
+	if err != nil {
+		if err != gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("获取通知偏好失败: %w", err)
+		}
+		pref = model.DefaultNotificationPreference(username)
+	}
+
+	info := &dto.NotificationPreferenceInfo{
+		InAppEnabled:   pref.InAppEnabled,
+		EmailEnabled:   pref.EmailEnabled,
+		WebhookEnabled: pref.WebhookEnabled,
+		WebhookURL:     pref.WebhookURL,
+	}
+	if pref.WebhookSecret != "" {
+		info.WebhookSecret = logger.RedactedPlaceholder
+	}
+	return info, nil
+}
+
+// UpdatePreference 更新当前用户的通知偏好
+func (s *NotificationService) UpdatePreference(ctx context.Context, req *dto.NotificationPreferenceInfo) error {
+	username := contextx.GetRequestUser(ctx)
+	if req.WebhookEnabled {
+		if err := validateWebhookURL(req.WebhookURL); err != nil {
+			return err
+		}
+	}
+
+	pref, err := s.notificationRepo.GetPreference(username)
+	if err != nil {
+		if err != gorm.ErrRecordNotFound {
+			return fmt.Errorf("获取通知偏好失败: %w", err)
+		}
+		pref = model.DefaultNotificationPreference(username)
+		pref.CreatedBy = username
+	}
+
+	pref.InAppEnabled = req.InAppEnabled
+	pref.EmailEnabled = req.EmailEnabled
+	pref.WebhookEnabled = req.WebhookEnabled
+	pref.WebhookURL = req.WebhookURL
+	// 为空或占位符表示不修改密钥
+	if req.WebhookSecret != "" && req.WebhookSecret != logger.RedactedPlaceholder {
+		// 加密后需存入 varchar(255)，明文限制 128 字符
+		if len(req.WebhookSecret) > 128 {
+			return fmt.Errorf("Webhook 签名密钥长度不能超过 128 个字符")
+		}
+		encrypted, err := s.cipher.Encrypt(req.WebhookSecret)
+		if err != nil {
+			return fmt.Errorf("加密 Webhook 签名密钥失败: %w", err)
+		}
+		pref.WebhookSecret = encrypted
+	}
+	pref.UpdatedBy = username
+
+	if err := s.notificationRepo.SavePreference(pref); err != nil {
+		return fmt.Errorf("保存通知偏好失败: %w", err)
+	}
+	return nil
+}
+
+// Subscribe 订阅当前用户的站内信（SSE），返回的 cancel 必须在连接断开时调用
+func (s *NotificationService) Subscribe(username string) (<-chan dto.NotificationInfo, func()) {
+	return s.hub.subscribe(username)
+}
+
+// resolveRecipients 展开接收人（用户 + 部门成员），去重并保持顺序
+func (s *NotificationService) resolveRecipients(header *apicall.Header, users, departments []string) ([]string, error) {
+	seen := make(map[string]bool)
+	recipients := make([]string, 0, len(users))
+	add := func(username string) {
+		username = strings.TrimSpace(username)
+		if username == "" || seen[username] {
+			return
+		}
+		seen[username] = true
+		recipients = append(recipients, username)
+	}
+
+	for _, username := range users {
+		add(username)
+	}
+	for _, department := range departments {
+		members, err := apicall.GetUsersByDepartment(header, department)
+		if err != nil {
+			return nil, fmt.Errorf("获取部门成员失败: %s: %w", department, err)
+		}
+		for _, member := range members {
+			add(member.Username)
+		}
+	}
+	return recipients, nil
+}
+
+// filterAppMembers 过滤掉不属于发送应用的接收人，防止应用向任意用户或部门发送通知
+func (s *NotificationService) filterAppMembers(ctx context.Context, req *dto.SendNotificationReq, recipients []string) ([]string, error) {
+	appModel, err := s.appRepo.GetAppByUserName(req.User, req.App)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("应用不存在: %s/%s", req.User, req.App)
+		}
+		return nil, fmt.Errorf("获取应用信息失败: %w", err)
+	}
+	members, err := s.casbinRuleRepo.ListUsersByAppID(appModel.ID)
+	if err != nil {
+		return nil, fmt.Errorf("获取应用成员失败: %w", err)
+	}
+	allowed := make(map[string]bool, len(members)+2)
+	allowed[appModel.User] = true
+	if req.RequestUser != "" {
+		allowed[req.RequestUser] = true
+	}
+	for _, member := range members {
+		allowed[member] = true
+	}
+
+	filtered := make([]string, 0, len(recipients))
+	var dropped []string
+	for _, username := range recipients {
+		if allowed[username] {
+			filtered = append(filtered, username)
+		} else {
+			dropped = append(dropped, username)
+		}
+	}
+	if len(dropped) > 0 {
+		logger.Warnf(ctx, "[NotificationService] 忽略非应用成员的接收人: app=%s/%s, users=%v", req.User, req.App, dropped)
+	}
+	return filtered, nil
+}
+
+// getPreferences 批量获取通知偏好，没有记录的用户使用默认偏好
+func (s *NotificationService) getPreferences(usernames []string) (map[string]*model.NotificationPreference, error) {
+	list, err := s.notificationRepo.GetPreferences(usernames)
+	if err != nil {
+		return nil, fmt.Errorf("获取通知偏好失败: %w", err)
+	}
+	prefs := make(map[string]*model.NotificationPreference, len(usernames))
+	for _, pref := range list {
+		prefs[pref.Username] = pref
+	}
+	for _, username := range usernames {
+		if _, ok := prefs[username]; !ok {
+			prefs[username] = model.DefaultNotificationPreference(username)
+		}
+	}
+	return prefs, nil
+}
+
+// deliverEmails 投递邮件
+func (s *NotificationService) deliverEmails(ctx context.Context, header *apicall.Header, usernames []string, notificationIDs map[string]int64, title, content, link string) {
+	users, err := apicall.GetUsersByUsernames(header, usernames)
+	if err != nil {
+		logger.Errorf(ctx, "[NotificationService] 获取用户邮箱失败: users=%v, err=%v", usernames, err)
+		for _, username := range usernames {
+			s.updateDeliveryStatus(ctx, notificationIDs[username], "email_status", model.NotificationDeliveryFailed)
+		}
+		return
+	}
+
+	emails := make(map[string]string, len(users))
+	for _, user := range users {
+		emails[user.Username] = user.Email
+	}
+
+	body := buildNotificationEmailBody(title, content, link)
+	for _, username := range usernames {
+		status := model.NotificationDeliverySent
+		email := emails[username]
+		if email == "" {
+			status = model.NotificationDeliverySkipped
+		} else if err := s.emailService.SendEmail(email, title, body); err != nil {
+			logger.Errorf(ctx, "[NotificationService] 发送通知邮件失败: user=%s, err=%v", username, err)
+			status = model.NotificationDeliveryFailed
+		}
+		s.updateDeliveryStatus(ctx, notificationIDs[username], "email_status", status)
+	}
+}
+
+// deliverWebhook 投递 Webhook（带签名，失败按指数退避重试）
+func (s *NotificationService) deliverWebhook(ctx context.Context, notificationID int64, pref *model.NotificationPreference, info dto.NotificationInfo) {
+	body, err := json.Marshal(&dto.NotificationWebhookPayload{
+		Event:        "notification",
+		Username:     pref.Username,
+		Notification: info,
+	})
+	if err != nil {
+		logger.Errorf(ctx, "[NotificationService] 序列化 Webhook 内容失败: %v", err)
+		s.updateDeliveryStatus(ctx, notificationID, "webhook_status", model.NotificationDeliveryFailed)
+		return
+	}
+
+	secret := ""
+	if pref.WebhookSecret != "" {
+		secret, err = s.cipher.Decrypt(pref.WebhookSecret)
+		if err != nil {
+			logger.Errorf(ctx, "[NotificationService] 解密 Webhook 签名密钥失败: user=%s, err=%v", pref.Username, err)
+			s.updateDeliveryStatus(ctx, notificationID, "webhook_status", model.NotificationDeliveryFailed)
+			return
+		}
+	}
+
+	for attempt := 1; attempt <= notificationWebhookMaxAttempts; attempt++ {
+		err = s.postWebhook(pref.WebhookURL, secret, body)
+		if err == nil {
+			s.updateDeliveryStatus(ctx, notificationID, "webhook_status", model.NotificationDeliverySent)
+			return
+		}
+		logger.Warnf(ctx, "[NotificationService] Webhook 投递失败: user=%s, attempt=%d/%d, err=%v",
+			pref.Username, attempt, notificationWebhookMaxAttempts, err)
+		if attempt < notificationWebhookMaxAttempts {
+			time.Sleep(notificationWebhookBackoff << (attempt - 1))
+		}
+	}
+	s.updateDeliveryStatus(ctx, notificationID, "webhook_status", model.NotificationDeliveryFailed)
+}
+
+// postWebhook 发送一次 Webhook 请求
+func (s *NotificationService) postWebhook(url, secret string, body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Event", "notification")
+	req.Header.Set("X-Timestamp", timestamp)
+	if secret != "" {
+		req.Header.Set("X-Signature", signNotificationWebhook(secret, timestamp, body))
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("HTTP错误: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// newWebhookHTTPClient 创建 Webhook 专用 HTTP 客户端
+// 在建立连接时校验实际连接的 IP（而不是只在保存时校验域名），防止 DNS 重绑定访问内网；不走环境变量代理
+func newWebhookHTTPClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout: notificationWebhookTimeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if isBlockedWebhookIP(net.ParseIP(host)) {
+				return fmt.Errorf("Webhook 地址不允许访问内网地址: %s", host)
+			}
+			return nil
+		},
+	}
+	return &http.Client{
+		Timeout: notificationWebhookTimeout,
+		Transport: &http.Transport{
+			Proxy:               nil,
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: notificationWebhookTimeout,
+		},
+	}
+}
+
+// validateWebhookURL 保存偏好时校验 Webhook 地址：仅允许 http/https，且域名解析结果不能是内网地址
+func validateWebhookURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return fmt.Errorf("Webhook 地址必须以 http:// 或 https:// 开头")
+	}
+	host := u.Hostname()
+	if ip := net.ParseIP(host); ip != nil {
+		if isBlockedWebhookIP(ip) {
+			return fmt.Errorf("Webhook 地址不允许使用内网地址: %s", host)
+		}
+		return nil
+	}
+	ips, err := net.LookupIP(host)
+	if err != nil {
+		return fmt.Errorf("Webhook 域名解析失败: %s", host)
+	}
+	for _, ip := range ips {
+		if isBlockedWebhookIP(ip) {
+			return fmt.Errorf("Webhook 地址不允许使用内网地址: %s -> %s", host, ip)
+		}
+	}
+	return nil
+}
+
+// isBlockedWebhookIP 是否为禁止 Webhook 访问的地址（私有、回环、链路本地、组播、未指定地址）
+func isBlockedWebhookIP(ip net.IP) bool {
+	return ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified()
+}
+
+// signNotificationWebhook 计算 Webhook 签名：sha256=hex(HMAC-SHA256(secret, timestamp + "." + body))
+func signNotificationWebhook(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// updateDeliveryStatus 更新投递状态（没有站内信记录时跳过）
+func (s *NotificationService) updateDeliveryStatus(ctx context.Context, notificationID int64, column, status string) {
+	if notificationID == 0 {
+		return
+	}
+	if err := s.notificationRepo.UpdateDeliveryStatus(notificationID, column, status); err != nil {
+		logger.Warnf(ctx, "[NotificationService] 更新投递状态失败: id=%d, %s=%s, err=%v", notificationID, column, status, err)
+	}
+}
+
+// broadcast 广播站内信到所有 app-server 实例
+func (s *NotificationService) broadcast(ctx context.Context, conn *nats.Conn, message *notificationPushMessage) {
+	if conn == nil {
+		s.hub.publish(message.Username, message.Notification)
+		return
+	}
+	data, err := json.Marshal(message)
+	if err != nil {
+		logger.Warnf(ctx, "[NotificationService] 序列化站内信推送失败: %v", err)
+		return
+	}
+	if err := conn.Publish(subjects.GetAppServerNotificationPushSubject(), data); err != nil {
+		logger.Warnf(ctx, "[NotificationService] 广播站内信失败: %v", err)
+	}
+}
+
+// initSubscriptions 初始化 NATS 订阅（所有主机）
+func (s *NotificationService) initSubscriptions() {
+	for hostId := range s.natsService.hostIdMap {
+		conn, err := s.natsService.GetNatsByHost(hostId)
+		if err != nil {
+			continue
+		}
+
+		// 发送通知：使用队列组，多个 app-server 实例只有一个会处理
+		sub, err := conn.QueueSubscribe(subjects.GetAppServerNotificationSendSubject(), "app-server", func(msg *nats.Msg) {
+			s.handleSendRequest(conn, msg)
+		})
+		if err != nil {
+			logger.Errorf(context.Background(), "[NotificationService] Failed to subscribe notification subject on host %d: %v", hostId, err)
+			continue
+		}
+		s.subs = append(s.subs, sub)
+
+		// 站内信广播：不使用队列组，每个实例都要收到
+		pushSub, err := conn.Subscribe(subjects.GetAppServerNotificationPushSubject(), s.handlePushMessage)
+		if err != nil {
+			logger.Errorf(context.Background(), "[NotificationService] Failed to subscribe notification push subject on host %d: %v", hostId, err)
+			continue
+		}
+		s.subs = append(s.subs, pushSub)
+	}
+}
+
+// handleSendRequest 处理 SDK App 的发送通知请求
+func (s *NotificationService) handleSendRequest(conn *nats.Conn, msg *nats.Msg) {
+	ctx := context.Background()
+
+	if !license.GetManager().HasFeature(enterprise.FeatureNotification) {
+		msgx.RespFailMsg(msg, fmt.Errorf("此功能需要企业版 License：通知中心，请升级到企业版"))
+		return
+	}
+
+	info, err := msgx.DecodeNatsMsg[dto.SendNotificationReq](msg)
+	if err != nil {
+		msgx.RespFailMsg(msg, fmt.Errorf("解析请求失败: %w", err))
+		return
+	}
+
+	// 请求必须携带 app-runtime 为该应用签发的令牌，防止任意 NATS 客户端冒充其他应用发送通知
+	if !appPkg.VerifySecretToken(s.tokenKey, info.Data.User, info.Data.App, info.Data.AppToken) {
+		logger.Warnf(ctx, "[NotificationService] 拒绝发送通知，令牌与应用不匹配: app=%s/%s", info.Data.User, info.Data.App)
+		msgx.RespFailMsg(msg, fmt.Errorf("无权以应用 %s/%s 的身份发送通知", info.Data.User, info.Data.App))
+		return
+	}
+
+	resp, err := s.Send(ctx, conn, &info.Data)
+	if err != nil {
+		logger.Errorf(ctx, "[NotificationService] 发送通知失败: app=%s/%s, err=%v", info.Data.User, info.Data.App, err)
+		msgx.RespFailMsg(msg, err)
+		return
+	}
+	if err := msgx.RespSuccessMsg(msg, resp); err != nil {
+		logger.Errorf(ctx, "[NotificationService] 响应发送通知请求失败: %v", err)
+	}
+}
+
+// handlePushMessage 处理站内信广播，推送给本实例上的 SSE 订阅者
+func (s *NotificationService) handlePushMessage(msg *nats.Msg) {
+	var message notificationPushMessage
+	if err := json.Unmarshal(msg.Data, &message); err != nil {
+		logger.Warnf(context.Background(), "[NotificationService] 解析站内信广播失败: %v", err)
+		return
+	}
+	s.hub.publish(message.Username, message.Notification)
+}
+
+// Close 关闭订阅
+func (s *NotificationService) Close() error {
+	for _, sub := range s.subs {
+		if sub != nil {
+			_ = sub.Unsubscribe()
+		}
+	}
+	s.subs = nil
+	return nil
+}
+
+// renderNotificationTemplate 渲染通知模板（text/template）
+func renderNotificationTemplate(text string, data map[string]interface{}) (string, error) {
+	if !strings.Contains(text, "{{") {
+		return text, nil
+	}
+	tpl, err := template.New("notification").Parse(text)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := tpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// buildNotificationEmailBody 构建通知邮件正文
+func buildNotificationEmailBody(title, content, link string) string {
+	var linkHTML string
+	if link != "" {
+		linkHTML = fmt.Sprintf(`<p><a href="%s">查看详情</a></p>`, html.EscapeString(link))
+	}
+	return fmt.Sprintf(`
+			<div style="font-family: Arial, sans-serif; max-width: 600px; margin: 0 auto;">
+				<h2 style="color: #333;">%s</h2>
+				<p style="white-space: pre-wrap;">%s</p>
+				%s
+				<hr style="border: none; border-top: 1px solid #eee; margin: 20px 0;">
+				<p style="color: #666; font-size: 12px;">此邮件由系统自动发送，请勿回复。可在通知设置中关闭邮件通知。</p>
+			</div>
+		`, html.EscapeString(title), html.EscapeString(content), linkHTML)
+}
+
+// deliveryStatus 根据渠道是否开启返回初始投递状态
+func deliveryStatus(enabled bool) string {
+	if enabled {
+		return model.NotificationDeliveryPending
+	}
+	return model.NotificationDeliverySkipped
+}
+
+// convertNotification 转换为 DTO
+func convertNotification(notification *model.Notification) dto.NotificationInfo {
+	info := dto.NotificationInfo{
+		ID:           notification.ID,
+		App:          notification.App,
+		FullCodePath: notification.FullCodePath,
+		TemplateCode: notification.TemplateCode,
+		Title:        notification.Title,
+		Content:      notification.Content,
+		Level:        notification.Level,
+		Link:         notification.Link,
+		IsRead:       notification.IsRead,
+		CreatedBy:    notification.CreatedBy,
+		CreatedAt:    time.Time(notification.CreatedAt).Format(time.DateTime),
+	}
+	if notification.ReadAt != nil {
+		info.ReadAt = notification.ReadAt.Format(time.DateTime)
+	}
+	return info
+}
+
+// notificationHub 本实例上的 SSE 订阅者
+type notificationHub struct {
+	mu          sync.RWMutex
+	subscribers map[string]map[chan dto.NotificationInfo]struct{}
+}
+
+func newNotificationHub() *notificationHub {
+	return &notificationHub{subscribers: make(map[string]map[chan dto.NotificationInfo]struct{})}
+}
+
+// subscribe 订阅用户的站内信（同一用户可以有多个连接，如多个浏览器标签页）
+func (h *notificationHub) subscribe(username string) (<-chan dto.NotificationInfo, func()) {
+	ch := make(chan dto.NotificationInfo, notificationSubscriberBuffer)
+	h.mu.Lock()
+	if h.subscribers[username] == nil {
+		h.subscribers[username] = make(map[chan dto.NotificationInfo]struct{})
+	}
+	h.subscribers[username][ch] = struct{}{}
+	h.mu.Unlock()
+
+	var once sync.Once
+	cancel := func() {
+		once.Do(func() {
+			h.mu.Lock()
+			delete(h.subscribers[username], ch)
+			if len(h.subscribers[username]) == 0 {
+				delete(h.subscribers, username)
+			}
+			h.mu.Unlock()
+			close(ch)
+		})
+	}
+	return ch, cancel
+}
+
+// publish 推送给用户的所有订阅者（订阅者处理不过来时丢弃，前端可以通过列表接口补齐）
+func (h *notificationHub) publish(username string, info dto.NotificationInfo) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for ch := range h.subscribers[username] {
+		select {
+		case ch <- info:
+		default:
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/ai-agent-os/ai-agent-os/core/app-server/model"
+	"github.com/ai-agent-os/ai-agent-os/core/app-server/repository"
+	"github.com/ai-agent-os/ai-agent-os/dto"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestNotificationFilterAppMembers(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("get sql.DB: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	if err := db.AutoMigrate(&model.App{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	if err := db.Exec("CREATE TABLE casbin_rule (id INTEGER PRIMARY KEY, ptype TEXT, v0 TEXT, v1 TEXT, v2 TEXT, app_id INTEGER)").Error; err != nil {
+		t.Fatalf("create casbin_rule: %v", err)
+	}
+
+	demo := &model.App{User: "luobei", Code: "demo", Name: "demo"}
+	other := &model.App{User: "luobei", Code: "other", Name: "other"}
+	for _, app := range []*model.App{demo, other} {
+		if err := db.Create(app).Error; err != nil {
+			t.Fatalf("create app: %v", err)
+		}
+	}
+	rules := [][]interface{}{
+		{"zhangsan", "/luobei/demo/crm", "directory:read", demo.ID},
+		{"zhangsan", "/luobei/demo/crm/*", "directory:read", demo.ID},
+		{"lisi", "/luobei/other/crm", "directory:read", other.ID},
+	}
+	for _, rule := range rules {
+		if err := db.Exec("INSERT INTO casbin_rule (ptype, v0, v1, v2, app_id) VALUES ('p', ?, ?, ?, ?)", rule...).Error; err != nil {
+			t.Fatalf("insert casbin_rule: %v", err)
+		}
+	}
+
+	s := &NotificationService{
+		appRepo:        repository.NewAppRepository(db),
+		casbinRuleRepo: repository.NewCasbinRuleRepository(db),
+	}
+	req := &dto.SendNotificationReq{User: "luobei", App: "demo", RequestUser: "wangwu"}
+
+	// lisi 只是其他应用的成员，outsider 与应用无关
+	got, err := s.filterAppMembers(context.Background(), req, []string{"zhangsan", "lisi", "luobei", "wangwu", "outsider"})
+	if err != nil {
+		t.Fatalf("filterAppMembers: %v", err)
+	}
+	if want := []string{"zhangsan", "luobei", "wangwu"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("recipients = %v, want %v", got, want)
+	}
+
+	if _, err := s.filterAppMembers(context.Background(), &dto.SendNotificationReq{User: "luobei", App: "missing"}, []string{"zhangsan"}); err == nil {
+		t.Fatal("expected error for unknown app")
+	}
+}
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"io"
+)
+
+// SecretCipher 敏感数据加解密（AES-256-GCM）
+// 应用密钥、通知 Webhook 签名密钥等需要落库的敏感值共用同一个加密密钥（secret.encryption_key）
+type SecretCipher struct {
+	key []byte
+}
+
+// NewSecretCipher 创建加解密器，key 必须是 32 字节
+func NewSecretCipher(key []byte) *SecretCipher {
+	return &SecretCipher{key: key}
+}
+
+// Encrypt 加密，返回 base64(nonce + ciphertext)
+func (c *SecretCipher) Encrypt(plaintext string) (string, error) {
+	gcm, err := c.gcm()
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+	ciphertext := gcm.Seal(nonce, nonce, []byte(plaintext), nil)
+	return base64.StdEncoding.EncodeToString(ciphertext), nil
+}
+
+// Decrypt 解密 Encrypt 生成的密文
+func (c *SecretCipher) Decrypt(encrypted string) (string, error) {
+	data, err := base64.StdEncoding.DecodeString(encrypted)
+	if err != nil {
+		return "", err
+	}
+	gcm, err := c.gcm()
+	if err != nil {
+		return "", err
+	}
+	nonceSize := gcm.NonceSize()
+	if len(data) < nonceSize {
+		return "", fmt.Errorf("ciphertext too short")
+	}
+	nonce, ciphertext := data[:nonceSize], data[nonceSize:]
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+func (c *SecretCipher) gcm() (cipher.AEAD, error) {
+	block, err := aes.NewCipher(c.key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,72 @@
+package service
+
+import (
+	"bytes"
+	"encoding/base64"
+	"testing"
+)
+
+func TestSecretCipherRoundTrip(t *testing.T) {
+	c := NewSecretCipher(bytes.Repeat([]byte{1}, 32))
+	for _, plaintext := range []string{"", "sk-test-123", "含中文的密钥值", string(bytes.Repeat([]byte("x"), 4096))} {
+		encrypted, err := c.Encrypt(plaintext)
+		if err != nil {
+			t.Fatalf("Encrypt(%q): %v", plaintext, err)
+		}
+		if plaintext != "" && encrypted == plaintext {
+			t.Fatalf("Encrypt(%q) returned plaintext", plaintext)
+		}
+		decrypted, err := c.Decrypt(encrypted)
+		if err != nil {
+			t.Fatalf("Decrypt: %v", err)
+		}
+		if decrypted != plaintext {
+			t.Fatalf("Decrypt() = %q, want %q", decrypted, plaintext)
+		}
+	}
+}
+
+func TestSecretCipherRandomNonce(t *testing.T) {
+	c := NewSecretCipher(bytes.Repeat([]byte{1}, 32))
+	a, err := c.Encrypt("same value")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := c.Encrypt("same value")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a == b {
+		t.Fatal("encrypting the same value twice should produce different ciphertexts")
+	}
+}
+
+func TestSecretCipherRejects(t *testing.T) {
+	c := NewSecretCipher(bytes.Repeat([]byte{1}, 32))
+	encrypted, err := c.Encrypt("sk-test-123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := base64.StdEncoding.DecodeString(encrypted)
+	data[len(data)-1] ^= 0xff
+	tampered := base64.StdEncoding.EncodeToString(data)
+
+	cases := []struct {
+		name      string
+		cipher    *SecretCipher
+		encrypted string
+	}{
+		{"wrong key", NewSecretCipher(bytes.Repeat([]byte{2}, 32)), encrypted},
+		{"tampered", c, tampered},
+		{"not base64", c, "not base64!"},
+		{"too short", c, base64.StdEncoding.EncodeToString([]byte("short"))},
+		{"invalid key size", NewSecretCipher([]byte("short key")), encrypted},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := tc.cipher.Decrypt(tc.encrypted); err == nil {
+				t.Fatal("Decrypt() expected error")
+			}
+		})
+	}
+}
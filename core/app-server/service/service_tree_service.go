@@ -78,6 +78,7 @@ type ServiceTreeService struct {
 	appService         *AppService
 	functionGenService *FunctionGenService // 用于异步处理和回调
 	permissionService  *PermissionService  // ⭐ 添加 PermissionService 依赖，用于查询权限
+	appSecretService   *AppSecretService   // 应用密钥服务，发布到 Hub 前脱敏
 }
 
 // NewServiceTreeService 创建服务目录服务
@@ -89,6 +90,7 @@ func NewServiceTreeService(
 	appService *AppService,
 	functionGenService *FunctionGenService,
 	permissionService *PermissionService, // ⭐ 新增 PermissionService 依赖
+	appSecretService *AppSecretService,
 ) *ServiceTreeService {
 	return &ServiceTreeService{
 		serviceTreeRepo:    serviceTreeRepo,
@@ -98,6 +100,7 @@ func NewServiceTreeService(
 		appService:         appService,
 		functionGenService: functionGenService,
 		permissionService:  permissionService,
+		appSecretService:   appSecretService,
 	}
 }
 
@@ -970,6 +973,35 @@ func (s *ServiceTreeService) sendCreateServiceTreeMessage(ctx context.Context, u
 	return nil
 }
 
+// redactDirectorySecrets 对目录快照中出现的应用密钥明文脱敏
+// 返回新的快照副本，不修改数据库中加载的原始对象
+func (s *ServiceTreeService) redactDirectorySecrets(ctx context.Context, appID int64, directoryFiles map[string][]*model.FileSnapshot) map[string][]*model.FileSnapshot {
+	if s.appSecretService == nil {
+		return directoryFiles
+	}
+	values, err := s.appSecretService.GetSecretValues(ctx, appID)
+	if err != nil || len(values) == 0 {
+		return directoryFiles
+	}
+
+	result := make(map[string][]*model.FileSnapshot, len(directoryFiles))
+	for path, snapshots := range directoryFiles {
+		redacted := make([]*model.FileSnapshot, 0, len(snapshots))
+		for _, snapshot := range snapshots {
+			content := redactSecretValues(snapshot.Content, values)
+			if content != snapshot.Content {
+				logger.Warnf(ctx, "[ServiceTreeService] 文件中包含应用密钥明文，已脱敏: path=%s, file=%s", path, snapshot.FileName)
+				copied := *snapshot
+				copied.Content = content
+				snapshot = &copied
+			}
+			redacted = append(redacted, snapshot)
+		}
+		result[path] = redacted
+	}
+	return result
+}
+
 // GetDirectorySnapshotsRecursively 递归获取目录及其所有子目录的文件快照
 // GetDirectorySnapshotsRecursively 递归获取目录及其所有子目录的当前版本文件快照
 // 优化：使用 ServiceTreeID 和 IsCurrent 字段，性能更好
@@ -1491,6 +1523,9 @@ func (s *ServiceTreeService) PublishDirectoryToHub(ctx context.Context, req *dto
 		return nil, fmt.Errorf("未找到任何目录快照，请确保源目录已创建快照")
 	}
 
+	// ⭐ 发布到 Hub 前对应用密钥明文脱敏（避免硬编码在代码中的密钥被带出去）
+	directoryFiles = s.redactDirectorySecrets(ctx, sourceApp.ID, directoryFiles)
+
 	// 5. 获取所有函数节点（function 类型，属于当前目录树下的）
 	// 使用路径前缀匹配，只查询属于当前目录的函数
 	normalizedPath := strings.TrimSuffix(req.SourceDirectoryPath, "/") + "/"
@@ -1626,6 +1661,9 @@ func (s *ServiceTreeService) PushDirectoryToHub(ctx context.Context, req *dto.Pu
 		return nil, fmt.Errorf("未找到任何目录快照，请确保源目录已创建快照")
 	}
 
+	// ⭐ 发布到 Hub 前对应用密钥明文脱敏（避免硬编码在代码中的密钥被带出去）
+	directoryFiles = s.redactDirectorySecrets(ctx, sourceApp.ID, directoryFiles)
+
 	// 6. 获取所有函数节点（function 类型，属于当前目录树下的）
 	// 使用路径前缀匹配，只查询属于当前目录的函数
 	normalizedPath := strings.TrimSuffix(req.SourceDirectoryPath, "/") + "/"
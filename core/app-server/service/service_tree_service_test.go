@@ -0,0 +1,86 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/ai-agent-os/ai-agent-os/core/app-server/model"
+	"github.com/ai-agent-os/ai-agent-os/core/app-server/repository"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+)
+
+func newTestAppSecretService(t *testing.T, appID int64, secrets map[string]string) *AppSecretService {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("get sql.DB: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	if err := db.AutoMigrate(&model.AppSecret{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	s := &AppSecretService{
+		secretRepo: repository.NewAppSecretRepository(db),
+		cipher:     NewSecretCipher(bytes.Repeat([]byte{1}, 32)),
+	}
+	for name, value := range secrets {
+		encrypted, err := s.cipher.Encrypt(value)
+		if err != nil {
+			t.Fatalf("encrypt: %v", err)
+		}
+		if err := s.secretRepo.Create(&model.AppSecret{AppID: appID, Name: name, EncryptedValue: encrypted}); err != nil {
+			t.Fatalf("create secret: %v", err)
+		}
+	}
+	return s
+}
+
+func TestRedactDirectorySecrets(t *testing.T) {
+	secretService := newTestAppSecretService(t, 1, map[string]string{
+		"OPENAI_API_KEY": "sk-live-abcdef",
+		"DB_PASSWORD":    "p@ssw0rd!",
+	})
+	s := &ServiceTreeService{appSecretService: secretService}
+
+	leaked := &model.FileSnapshot{FileName: "client", Content: `var key = "sk-live-abcdef"; var pwd = "p@ssw0rd!"`}
+	clean := &model.FileSnapshot{FileName: "user", Content: "package crm"}
+	files := map[string][]*model.FileSnapshot{"/beiluo/crm/ticket": {leaked, clean}}
+
+	result := s.redactDirectorySecrets(context.Background(), 1, files)
+
+	got := result["/beiluo/crm/ticket"]
+	if len(got) != 2 {
+		t.Fatalf("snapshots = %d, want 2", len(got))
+	}
+	if want := `var key = "******"; var pwd = "******"`; got[0].Content != want {
+		t.Fatalf("redacted content = %q, want %q", got[0].Content, want)
+	}
+	// 不修改原始快照，未包含密钥的快照原样返回
+	if leaked.Content != `var key = "sk-live-abcdef"; var pwd = "p@ssw0rd!"` {
+		t.Fatalf("original snapshot modified: %q", leaked.Content)
+	}
+	if got[1] != clean {
+		t.Fatal("snapshot without secrets should be returned as is")
+	}
+
+	// 其他应用的密钥不参与脱敏
+	other := s.redactDirectorySecrets(context.Background(), 2, files)
+	if other["/beiluo/crm/ticket"][0].Content != leaked.Content {
+		t.Fatalf("secrets of another app should not be redacted: %q", other["/beiluo/crm/ticket"][0].Content)
+	}
+}
+
+func TestRedactDirectorySecretsWithoutSecretService(t *testing.T) {
+	s := &ServiceTreeService{}
+	files := map[string][]*model.FileSnapshot{"/beiluo/crm": {{Content: "sk-live-abcdef"}}}
+	if got := s.redactDirectorySecrets(context.Background(), 1, files); got["/beiluo/crm"][0].Content != "sk-live-abcdef" {
+		t.Fatalf("content = %q", got["/beiluo/crm"][0].Content)
+	}
+}
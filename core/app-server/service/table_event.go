@@ -0,0 +1,40 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/ai-agent-os/ai-agent-os/pkg/logger"
+)
+
+// TableEvent 表格数据变更事件（通过标准接口新增、更新、删除记录成功后触发）
+type TableEvent struct {
+	FullCodePath string          // Table 函数完整路径
+	Action       string          // OnTableAddRow / OnTableUpdateRow / OnTableDeleteRows
+	RequestUser  string          // 操作人
+	TraceID      string          // 链路ID
+	Body         json.RawMessage // 请求体（新增的数据、更新的字段、删除的 ids）
+	Result       interface{}     // 应用返回的结果
+}
+
+// TableEventHandler 表格数据变更事件处理函数（同步调用，耗时操作需要自行异步处理）
+type TableEventHandler func(ctx context.Context, event *TableEvent)
+
+// OnTableEvent 订阅表格数据变更事件，只能在服务初始化阶段调用
+func (a *AppService) OnTableEvent(handler TableEventHandler) {
+	a.tableEventHandlers = append(a.tableEventHandlers, handler)
+}
+
+// EmitTableEvent 发布表格数据变更事件，订阅者的 panic 不会影响调用方
+func (a *AppService) EmitTableEvent(ctx context.Context, event *TableEvent) {
+	for _, handler := range a.tableEventHandlers {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					logger.Errorf(ctx, "[AppService] 处理表格事件 panic: action=%s, path=%s, err=%v", event.Action, event.FullCodePath, r)
+				}
+			}()
+			handler(ctx, event)
+		}()
+	}
+}
@@ -0,0 +1,426 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ai-agent-os/ai-agent-os/core/app-server/model"
+	"github.com/ai-agent-os/ai-agent-os/core/app-server/repository"
+	"github.com/ai-agent-os/ai-agent-os/dto"
+	"github.com/ai-agent-os/ai-agent-os/enterprise"
+	"github.com/ai-agent-os/ai-agent-os/pkg/apicall"
+	"github.com/ai-agent-os/ai-agent-os/pkg/contextx"
+	"github.com/ai-agent-os/ai-agent-os/pkg/gormx/query"
+	"github.com/ai-agent-os/ai-agent-os/pkg/license"
+	"github.com/ai-agent-os/ai-agent-os/pkg/logger"
+	"github.com/ai-agent-os/ai-agent-os/pkg/permission"
+	"github.com/ai-agent-os/ai-agent-os/sdk/agent-app/widget"
+	"gorm.io/gorm"
+)
+
+const (
+	tableViewMaxPageSize = 1000
+	tableViewMaxColumns  = 200
+)
+
+// TableViewService 表格视图服务
+// 用户为表格函数保存常用的过滤、排序、显示列和每页数量，可以共享给部门或所有人；
+// 应用更新导致函数结构变化时，标记引用了已删除字段的视图
+type TableViewService struct {
+	viewRepo     *repository.TableViewRepository
+	functionRepo *repository.FunctionRepository
+	canManage    func(ctx context.Context, username, fullCodePath string) (bool, error) // 所有人可见的视图和默认视图需要函数管理权限
+}
+
+// NewTableViewService 创建表格视图服务
+func NewTableViewService(viewRepo *repository.TableViewRepository, functionRepo *repository.FunctionRepository, appService *AppService) *TableViewService {
+	s := &TableViewService{
+		viewRepo:     viewRepo,
+		functionRepo: functionRepo,
+		canManage:    checkFunctionManage,
+	}
+
+	// 函数结构变更时检查视图引用的字段
+	appService.OnFunctionChange(s.handleFunctionChange)
+
+	return s
+}
+
+// tableViewParams 视图转换为表格查询参数（StructToTableParams 的输入）
+type tableViewParams struct {
+	Filter   query.FilterParam
+	Sorts    string
+	PageSize int
+}
+
+// ListViews 获取当前用户可见的视图（自己的、部门共享的、所有人可见的），默认视图排在最前面
+func (s *TableViewService) ListViews(ctx context.Context, fullCodePath string) (*dto.ListTableViewsResp, error) {
+	username := contextx.GetRequestUser(ctx)
+
+	// 获取部门失败时只返回自己的和所有人可见的视图
+	department, err := s.getUserDepartment(ctx, username)
+	if err != nil {
+		logger.Warnf(ctx, "[TableViewService] 获取用户 %s 的部门失败: %v", username, err)
+	}
+
+	views, err := s.viewRepo.ListVisible(fullCodePath, username, departmentAncestors(department))
+	if err != nil {
+		return nil, fmt.Errorf("获取视图列表失败: %w", err)
+	}
+
+	resp := &dto.ListTableViewsResp{Views: make([]dto.TableViewInfo, 0, len(views))}
+	for _, view := range views {
+		resp.Views = append(resp.Views, s.toViewInfo(view, username))
+	}
+	return resp, nil
+}
+
+// SaveView 创建或更新视图（只能修改自己创建的视图），保存时按函数当前结构校验引用的字段
+func (s *TableViewService) SaveView(ctx context.Context, fullCodePath string, req *dto.SaveTableViewReq) (*dto.SaveTableViewResp, error) {
+	username := contextx.GetRequestUser(ctx)
+
+	function, err := s.functionRepo.GetFunctionByFullCodePath(fullCodePath)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("函数不存在: %s", fullCodePath)
+		}
+		return nil, fmt.Errorf("获取函数失败: %w", err)
+	}
+	if function.GetTemplateType() != workflowTemplateTable {
+		return nil, fmt.Errorf("只有表格函数可以保存视图: %s", fullCodePath)
+	}
+
+	scope := req.Scope
+	if scope == "" {
+		scope = model.TableViewScopePrivate
+	}
+	switch scope {
+	case model.TableViewScopePrivate, model.TableViewScopeDepartment, model.TableViewScopeAll:
+	default:
+		return nil, fmt.Errorf("不支持的可见范围: %s", scope)
+	}
+	if req.IsDefault && scope != model.TableViewScopeAll {
+		return nil, fmt.Errorf("只有所有人可见的视图才能设置为默认视图")
+	}
+	if req.PageSize < 0 || req.PageSize > tableViewMaxPageSize {
+		return nil, fmt.Errorf("每页数量必须在 0 到 %d 之间", tableViewMaxPageSize)
+	}
+	if len(req.Columns) > tableViewMaxColumns {
+		return nil, fmt.Errorf("显示的列不能超过 %d 个", tableViewMaxColumns)
+	}
+
+	// 过滤表达式统一保存为紧凑格式
+	var filter query.FilterParam
+	if len(req.Filter) > 0 {
+		if err := json.Unmarshal(req.Filter, &filter); err != nil {
+			return nil, fmt.Errorf("过滤表达式格式错误: %w", err)
+		}
+	}
+	node, err := filter.Parse()
+	if err != nil {
+		return nil, err
+	}
+	sorts, err := normalizeViewSorts(req.Sorts)
+	if err != nil {
+		return nil, err
+	}
+	columns, err := json.Marshal(req.Columns)
+	if err != nil {
+		return nil, fmt.Errorf("序列化显示列失败: %w", err)
+	}
+
+	view := &model.TableView{}
+	if req.ID > 0 {
+		view, err = s.viewRepo.GetByID(req.ID)
+		if err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return nil, fmt.Errorf("视图不存在: %d", req.ID)
+			}
+			return nil, fmt.Errorf("获取视图失败: %w", err)
+		}
+		if view.FullCodePath != fullCodePath {
+			return nil, fmt.Errorf("视图不属于该函数")
+		}
+		if view.CreatedBy != username {
+			return nil, fmt.Errorf("只能修改自己创建的视图")
+		}
+	} else {
+		view.CreatedBy = username
+	}
+
+	// 所有人可见的视图以及默认视图（保存时会取消其他用户视图的默认标记）影响所有用户，需要函数管理权限
+	if scope == model.TableViewScopeAll || req.IsDefault || view.Scope == model.TableViewScopeAll || view.IsDefault {
+		ok, err := s.canManage(ctx, username, fullCodePath)
+		if err != nil {
+			return nil, fmt.Errorf("校验函数管理权限失败: %w", err)
+		}
+		if !ok {
+			return nil, fmt.Errorf("需要函数管理权限才能设置所有人可见的视图或默认视图")
+		}
+	}
+	view.AppID = function.AppID
+	view.FullCodePath = fullCodePath
+	view.Name = strings.TrimSpace(req.Name)
+	view.Scope = scope
+	view.Filter = string(query.NewFilterParam(node))
+	view.Sorts = sorts
+	view.Columns = columns
+	view.PageSize = req.PageSize
+	view.IsDefault = req.IsDefault
+	view.UpdatedBy = username
+
+	// 共享给部门时使用创建人当前所在的部门
+	view.Department = ""
+	if scope == model.TableViewScopeDepartment {
+		view.Department, err = s.getUserDepartment(ctx, username)
+		if err != nil {
+			return nil, fmt.Errorf("获取用户部门失败: %w", err)
+		}
+		if view.Department == "" {
+			return nil, fmt.Errorf("当前用户没有所属部门，不能共享给部门")
+		}
+	}
+
+	missing := viewMissingFields(view, functionFieldCodes(function))
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("视图引用了不存在的字段: %s", strings.Join(missing, ", "))
+	}
+	view.InvalidFields = ""
+
+	if err := s.viewRepo.Save(view); err != nil {
+		return nil, fmt.Errorf("保存视图失败: %w", err)
+	}
+	return &dto.SaveTableViewResp{View: s.toViewInfo(view, username)}, nil
+}
+
+// getView 获取视图
+func (s *TableViewService) getView(id int64) (*model.TableView, error) {
+	view, err := s.viewRepo.GetByID(id)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("视图不存在: %d", id)
+		}
+		return nil, fmt.Errorf("获取视图失败: %w", err)
+	}
+	return view, nil
+}
+
+// DeleteView 删除视图（只能删除自己创建的视图）
+func (s *TableViewService) DeleteView(ctx context.Context, fullCodePath string, id int64) error {
+	username := contextx.GetRequestUser(ctx)
+
+	view, err := s.getView(id)
+	if err != nil {
+		return err
+	}
+	if view.FullCodePath != fullCodePath {
+		return fmt.Errorf("视图不属于该函数")
+	}
+	if view.CreatedBy != username {
+		return fmt.Errorf("只能删除自己创建的视图")
+	}
+	if err := s.viewRepo.Delete(id); err != nil {
+		return fmt.Errorf("删除视图失败: %w", err)
+	}
+	return nil
+}
+
+// handleFunctionChange 函数结构变更后重新检查视图引用的字段
+// 删除的函数没有可用字段，其视图引用的字段全部标记为已删除；字段恢复后标记自动清除
+func (s *TableViewService) handleFunctionChange(ctx context.Context, event *FunctionChangeEvent) {
+	fieldsByPath := make(map[string]map[string]bool)
+	for _, api := range event.Updated {
+		fieldsByPath[api.BuildFullCodePath()] = fieldCodes(api.Request, api.Response)
+	}
+	for _, api := range event.Deleted {
+		fieldsByPath[api.BuildFullCodePath()] = map[string]bool{}
+	}
+
+	paths := make([]string, 0, len(fieldsByPath))
+	for path := range fieldsByPath {
+		paths = append(paths, path)
+	}
+	views, err := s.viewRepo.ListByFullCodePaths(paths)
+	if err != nil {
+		logger.Errorf(ctx, "[TableViewService] 获取视图失败: app_id=%d, err=%v", event.AppID, err)
+		return
+	}
+
+	flagged := 0
+	for _, view := range views {
+		invalidFields := strings.Join(viewMissingFields(view, fieldsByPath[view.FullCodePath]), ",")
+		if invalidFields == view.InvalidFields {
+			continue
+		}
+		if err := s.viewRepo.UpdateInvalidFields(view.ID, invalidFields); err != nil {
+			logger.Errorf(ctx, "[TableViewService] 更新视图 %d 失效字段失败: %v", view.ID, err)
+			continue
+		}
+		if invalidFields != "" {
+			flagged++
+		}
+	}
+	if flagged > 0 {
+		logger.Infof(ctx, "[TableViewService] 函数结构变更，%d 个视图引用了已删除的字段: app_id=%d", flagged, event.AppID)
+	}
+}
+
+// checkFunctionManage 检查用户是否有函数管理权限（社区版不做权限控制）
+func checkFunctionManage(ctx context.Context, username, fullCodePath string) (bool, error) {
+	if !license.GetManager().HasFeature(enterprise.FeaturePermission) {
+		return true, nil
+	}
+	return permission.CheckPermissionWithInheritance(ctx, enterprise.GetPermissionService(), username, fullCodePath, permission.FunctionManage)
+}
+
+// getUserDepartment 获取用户所在部门的完整路径
+func (s *TableViewService) getUserDepartment(ctx context.Context, username string) (string, error) {
+	header := &apicall.Header{
+		TraceID:     contextx.GetTraceId(ctx),
+		RequestUser: username,
+		Token:       contextx.GetToken(ctx),
+	}
+	user, err := apicall.GetUserByUsername(header, username)
+	if err != nil {
+		return "", err
+	}
+	return user.DepartmentFullPath, nil
+}
+
+// toViewInfo 转换为视图信息，并生成打开视图的 URL 查询参数
+func (s *TableViewService) toViewInfo(view *model.TableView, username string) dto.TableViewInfo {
+	info := dto.TableViewInfo{
+		ID:         view.ID,
+		Name:       view.Name,
+		Scope:      view.Scope,
+		Department: view.Department,
+		Filter:     view.Filter,
+		Sorts:      view.Sorts,
+		Columns:    viewColumns(view),
+		PageSize:   view.PageSize,
+		IsDefault:  view.IsDefault,
+		Editable:   view.CreatedBy == username,
+		CreatedBy:  view.CreatedBy,
+		UpdatedAt:  time.Time(view.UpdatedAt).Format(time.DateTime),
+	}
+	if view.InvalidFields != "" {
+		info.InvalidFields = strings.Split(view.InvalidFields, ",")
+	}
+
+	qs, err := query.StructToTableParams(&tableViewParams{
+		Filter:   query.FilterParam(view.Filter),
+		Sorts:    view.Sorts,
+		PageSize: view.PageSize,
+	})
+	if err == nil {
+		values, _ := url.ParseQuery(qs)
+		values.Set("view", strconv.FormatInt(view.ID, 10))
+		info.QueryString = values.Encode()
+	}
+	return info
+}
+
+// normalizeViewSorts 校验排序格式（field:asc/desc，多个用逗号分隔）
+func normalizeViewSorts(sorts string) (string, error) {
+	if strings.TrimSpace(sorts) == "" {
+		return "", nil
+	}
+	parts := strings.Split(sorts, ",")
+	result := make([]string, 0, len(parts))
+	for _, part := range parts {
+		field, order, _ := strings.Cut(strings.TrimSpace(part), ":")
+		field = strings.TrimSpace(field)
+		order = strings.ToLower(strings.TrimSpace(order))
+		if order == "" {
+			order = "asc"
+		}
+		if field == "" || (order != "asc" && order != "desc") {
+			return "", fmt.Errorf("排序格式错误: %s", part)
+		}
+		result = append(result, field+":"+order)
+	}
+	return strings.Join(result, ","), nil
+}
+
+// viewColumns 视图显示的列
+func viewColumns(view *model.TableView) []string {
+	var columns []string
+	if len(view.Columns) > 0 {
+		_ = json.Unmarshal(view.Columns, &columns)
+	}
+	return columns
+}
+
+// viewFields 视图引用的字段：过滤表达式、排序、显示的列
+func viewFields(view *model.TableView) []string {
+	var fields []string
+	if node, err := query.FilterParam(view.Filter).Parse(); err == nil {
+		fields = append(fields, node.Fields()...)
+	}
+	if view.Sorts != "" {
+		for _, part := range strings.Split(view.Sorts, ",") {
+			field, _, _ := strings.Cut(part, ":")
+			fields = append(fields, field)
+		}
+	}
+	return append(fields, viewColumns(view)...)
+}
+
+// viewMissingFields 视图引用但函数结构中不存在的字段（去重，按出现顺序）
+func viewMissingFields(view *model.TableView, codes map[string]bool) []string {
+	var missing []string
+	seen := make(map[string]bool)
+	for _, field := range viewFields(view) {
+		if codes[field] || seen[field] {
+			continue
+		}
+		seen[field] = true
+		missing = append(missing, field)
+	}
+	return missing
+}
+
+// functionFieldCodes 函数请求和响应结构中的字段 code
+func functionFieldCodes(function *model.Function) map[string]bool {
+	var request, response []*widget.Field
+	if len(function.Request) > 0 {
+		_ = json.Unmarshal(function.Request, &request)
+	}
+	if len(function.Response) > 0 {
+		_ = json.Unmarshal(function.Response, &response)
+	}
+	return fieldCodes(request, response)
+}
+
+// fieldCodes 字段列表中的字段 code（表格的列是顶层字段）
+func fieldCodes(fieldLists ...[]*widget.Field) map[string]bool {
+	codes := make(map[string]bool)
+	for _, fields := range fieldLists {
+		for _, field := range fields {
+			if field != nil && field.Code != "" {
+				codes[field.Code] = true
+			}
+		}
+	}
+	return codes
+}
+
+// departmentAncestors 部门及其所有上级部门的完整路径，如 /tech/backend -> [/tech/backend /tech]
+func departmentAncestors(department string) []string {
+	department = strings.TrimRight(department, "/")
+	var paths []string
+	for department != "" {
+		paths = append(paths, department)
+		i := strings.LastIndex(department, "/")
+		if i <= 0 {
+			break
+		}
+		department = department[:i]
+	}
+	return paths
+}
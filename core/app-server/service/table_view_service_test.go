@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ai-agent-os/ai-agent-os/core/app-server/model"
+	"github.com/ai-agent-os/ai-agent-os/core/app-server/repository"
+	"github.com/ai-agent-os/ai-agent-os/dto"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+)
+
+const tableViewTestPath = "/luobei/demo/crm/crm_ticket"
+
+func newTableViewTestService(t *testing.T) *TableViewService {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("get sql.DB: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	if err := db.AutoMigrate(&model.Function{}, &model.TableView{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	function := &model.Function{
+		AppID:        1,
+		Router:       tableViewTestPath,
+		TemplateType: workflowTemplateTable,
+		Response:     []byte(`[{"code":"id"},{"code":"status"}]`),
+	}
+	if err := db.Create(function).Error; err != nil {
+		t.Fatalf("create function: %v", err)
+	}
+
+	return &TableViewService{
+		viewRepo:     repository.NewTableViewRepository(db),
+		functionRepo: repository.NewFunctionRepository(db),
+		// 只有 admin 拥有函数管理权限
+		canManage: func(ctx context.Context, username, fullCodePath string) (bool, error) {
+			return username == "admin", nil
+		},
+	}
+}
+
+func userCtx(username string) context.Context {
+	return context.WithValue(context.Background(), "request_user", username)
+}
+
+func TestSaveViewSharedRequiresManage(t *testing.T) {
+	s := newTableViewTestService(t)
+
+	shared, err := s.SaveView(userCtx("admin"), tableViewTestPath, &dto.SaveTableViewReq{Name: "全部工单", Scope: model.TableViewScopeAll, IsDefault: true})
+	if err != nil {
+		t.Fatalf("admin save default view: %v", err)
+	}
+
+	// 普通读者不能共享给所有人，也不能抢占默认视图
+	if _, err := s.SaveView(userCtx("bob"), tableViewTestPath, &dto.SaveTableViewReq{Name: "bob", Scope: model.TableViewScopeAll}); err == nil {
+		t.Fatal("reader should not save a view visible to all")
+	}
+	if _, err := s.SaveView(userCtx("bob"), tableViewTestPath, &dto.SaveTableViewReq{Name: "bob", Scope: model.TableViewScopeAll, IsDefault: true}); err == nil {
+		t.Fatal("reader should not set the default view")
+	}
+	private, err := s.SaveView(userCtx("bob"), tableViewTestPath, &dto.SaveTableViewReq{Name: "bob", Sorts: "status:asc"})
+	if err != nil {
+		t.Fatalf("reader save private view: %v", err)
+	}
+	if _, err := s.SaveView(userCtx("bob"), tableViewTestPath, &dto.SaveTableViewReq{ID: private.View.ID, Name: "bob", Scope: model.TableViewScopeAll, IsDefault: true}); err == nil {
+		t.Fatal("reader should not promote a private view to the default view")
+	}
+
+	view, err := s.viewRepo.GetByID(shared.View.ID)
+	if err != nil {
+		t.Fatalf("get view: %v", err)
+	}
+	if !view.IsDefault {
+		t.Fatal("default view should not be replaced by a reader")
+	}
+
+	// 有管理权限时可以取消默认视图
+	if _, err := s.SaveView(userCtx("admin"), tableViewTestPath, &dto.SaveTableViewReq{ID: shared.View.ID, Name: "全部工单", Scope: model.TableViewScopeAll}); err != nil {
+		t.Fatalf("admin clear default view: %v", err)
+	}
+}
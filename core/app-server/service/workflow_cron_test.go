@@ -0,0 +1,175 @@
+package service
+
+import (
+	"testing"
+	"time"
+)
+
+func cronTime(t *testing.T, value string) time.Time {
+	t.Helper()
+	tm, err := time.ParseInLocation("2006-01-02 15:04", value, time.Local)
+	if err != nil {
+		t.Fatalf("parse time %q: %v", value, err)
+	}
+	return tm
+}
+
+func TestParseCronInvalid(t *testing.T) {
+	cases := []struct {
+		name string
+		expr string
+	}{
+		{"too few fields", "* * * *"},
+		{"too many fields", "* * * * * *"},
+		{"minute out of range", "60 * * * *"},
+		{"hour out of range", "0 24 * * *"},
+		{"day of month zero", "0 0 0 * *"},
+		{"month out of range", "0 0 1 13 *"},
+		{"day of week out of range", "0 0 * * 8"},
+		{"reversed range", "0 10-5 * * *"},
+		{"zero step", "*/0 * * * *"},
+		{"negative step", "*/-5 * * * *"},
+		{"non numeric", "a * * * *"},
+		{"broken range", "1- * * * *"},
+		{"empty list item", "1,,2 * * * *"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := parseCron(tc.expr); err == nil {
+				t.Fatalf("parseCron(%q) expected error", tc.expr)
+			}
+		})
+	}
+}
+
+func TestCronMatch(t *testing.T) {
+	// 2024-01-01 是周一，2024-02-29 是周四（闰年）
+	cases := []struct {
+		name string
+		expr string
+		time string
+		want bool
+	}{
+		// 通配
+		{"every minute", "* * * * *", "2024-01-01 13:37", true},
+
+		// 单值
+		{"exact minute hour", "30 9 * * *", "2024-01-01 09:30", true},
+		{"exact minute mismatch", "30 9 * * *", "2024-01-01 09:31", false},
+		{"exact hour mismatch", "30 9 * * *", "2024-01-01 10:30", false},
+
+		// 范围
+		{"hour range lower bound", "0 9-17 * * *", "2024-01-01 09:00", true},
+		{"hour range upper bound", "0 9-17 * * *", "2024-01-01 17:00", true},
+		{"hour range outside", "0 9-17 * * *", "2024-01-01 18:00", false},
+		{"weekday range monday", "0 9 * * 1-5", "2024-01-01 09:00", true},
+		{"weekday range saturday", "0 9 * * 1-5", "2024-01-06 09:00", false},
+
+		// 步长
+		{"minute step hit", "*/15 * * * *", "2024-01-01 10:45", true},
+		{"minute step miss", "*/15 * * * *", "2024-01-01 10:50", false},
+		{"range with step hit", "0-30/10 * * * *", "2024-01-01 10:20", true},
+		{"range with step past range", "0-30/10 * * * *", "2024-01-01 10:40", false},
+		{"value with step runs to max", "5/20 * * * *", "2024-01-01 10:45", true},
+		{"value with step before start", "5/20 * * * *", "2024-01-01 10:00", false},
+
+		// 列表
+		{"list hit", "0 8,12,18 * * *", "2024-01-01 12:00", true},
+		{"list miss", "0 8,12,18 * * *", "2024-01-01 13:00", false},
+		{"list of ranges", "0 1-2,22-23 * * *", "2024-01-01 23:00", true},
+		{"list of ranges miss", "0 1-2,22-23 * * *", "2024-01-01 12:00", false},
+
+		// 周日可以写 0 或 7
+		{"sunday as 0", "0 0 * * 0", "2024-01-07 00:00", true},
+		{"sunday as 7", "0 0 * * 7", "2024-01-07 00:00", true},
+		{"sunday as 7 not monday", "0 0 * * 7", "2024-01-08 00:00", false},
+
+		// 日和周：都限定时满足其一即可，只限定其一时必须满足该项
+		{"dom and dow both set, dom hit", "0 0 15 * 5", "2024-01-15 00:00", true},
+		{"dom and dow both set, dow hit", "0 0 15 * 5", "2024-01-05 00:00", true},
+		{"dom and dow both set, neither", "0 0 15 * 5", "2024-01-16 00:00", false},
+		{"only dom set", "0 0 15 * *", "2024-01-05 00:00", false},
+		{"only dow set", "0 0 * * 5", "2024-01-15 00:00", false},
+		{"only dow set hit", "0 0 * * 5", "2024-01-05 00:00", true},
+
+		// 月份
+		{"month hit", "0 0 1 3 *", "2024-03-01 00:00", true},
+		{"month miss", "0 0 1 3 *", "2024-04-01 00:00", false},
+		{"quarterly step", "0 0 1 */3 *", "2024-07-01 00:00", true},
+		{"quarterly step miss", "0 0 1 */3 *", "2024-08-01 00:00", false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			schedule, err := parseCron(tc.expr)
+			if err != nil {
+				t.Fatalf("parseCron(%q): %v", tc.expr, err)
+			}
+			if got := schedule.Match(cronTime(t, tc.time)); got != tc.want {
+				t.Fatalf("parseCron(%q).Match(%s) = %v, want %v", tc.expr, tc.time, got, tc.want)
+			}
+		})
+	}
+}
+
+// TestCronMonthRollover 逐分钟扫描跨月、跨年区间，校验触发时刻
+func TestCronMonthRollover(t *testing.T) {
+	cases := []struct {
+		name  string
+		expr  string
+		start string
+		end   string
+		want  []string
+	}{
+		{
+			name:  "31st skips short months",
+			expr:  "0 0 31 * *",
+			start: "2024-01-30 00:00",
+			end:   "2024-05-31 00:00",
+			want:  []string{"2024-01-31 00:00", "2024-03-31 00:00", "2024-05-31 00:00"},
+		},
+		{
+			name:  "first of month across year end",
+			expr:  "0 0 1 * *",
+			start: "2023-11-15 00:00",
+			end:   "2024-02-15 00:00",
+			want:  []string{"2023-12-01 00:00", "2024-01-01 00:00", "2024-02-01 00:00"},
+		},
+		{
+			name:  "leap day",
+			expr:  "30 12 29 2 *",
+			start: "2024-02-28 00:00",
+			end:   "2024-03-01 23:59",
+			want:  []string{"2024-02-29 12:30"},
+		},
+		{
+			name:  "last minute of month",
+			expr:  "59 23 30,31 * *",
+			start: "2024-04-29 00:00",
+			end:   "2024-05-01 00:00",
+			want:  []string{"2024-04-30 23:59"},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			schedule, err := parseCron(tc.expr)
+			if err != nil {
+				t.Fatalf("parseCron(%q): %v", tc.expr, err)
+			}
+			var got []string
+			end := cronTime(t, tc.end)
+			for tm := cronTime(t, tc.start); !tm.After(end); tm = tm.Add(time.Minute) {
+				if schedule.Match(tm) {
+					got = append(got, tm.Format("2006-01-02 15:04"))
+				}
+			}
+			if len(got) != len(tc.want) {
+				t.Fatalf("parseCron(%q) fired at %v, want %v", tc.expr, got, tc.want)
+			}
+			for i := range got {
+				if got[i] != tc.want[i] {
+					t.Fatalf("parseCron(%q) fired at %v, want %v", tc.expr, got, tc.want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,568 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ai-agent-os/ai-agent-os/core/app-server/model"
+	"github.com/ai-agent-os/ai-agent-os/dto"
+	"github.com/ai-agent-os/ai-agent-os/enterprise"
+	"github.com/ai-agent-os/ai-agent-os/pkg/license"
+	"github.com/ai-agent-os/ai-agent-os/pkg/logger"
+	"github.com/ai-agent-os/ai-agent-os/sdk/agent-app/widget"
+)
+
+// errWorkflowRunStopped 运行已被取消或租约已被其他实例接管
+var errWorkflowRunStopped = errors.New("运行已取消或已被其他实例接管")
+
+// runWorker 领取并执行运行
+func (s *WorkflowService) runWorker() {
+	ticker := time.NewTicker(workflowPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.stopCh:
+			return
+		case <-ticker.C:
+		case <-s.wake:
+		}
+		s.claimRuns()
+	}
+}
+
+// claimRuns 领取可执行的运行（等待中的，或租约已过期的），受并发槽位限制
+func (s *WorkflowService) claimRuns() {
+	if !license.GetManager().HasFeature(enterprise.FeatureWorkflow) {
+		return
+	}
+	ctx := context.Background()
+	now := time.Now()
+	runs, err := s.workflowRepo.ListClaimableRuns(now, workflowMaxRunning)
+	if err != nil {
+		logger.Warnf(ctx, "[WorkflowService] 获取待执行运行失败: %v", err)
+		return
+	}
+	for _, run := range runs {
+		select {
+		case s.slots <- struct{}{}:
+		default:
+			return
+		}
+		ok, err := s.workflowRepo.ClaimRun(run.ID, s.workerID, now, now.Add(workflowLeaseDuration))
+		if err != nil || !ok {
+			<-s.slots
+			continue
+		}
+		go func(run *model.WorkflowRun) {
+			defer func() { <-s.slots }()
+			s.executeRun(run)
+		}(run)
+	}
+}
+
+// executeRun 执行运行：按依赖顺序执行步骤，已成功或已跳过的步骤（接管其他实例的运行时）直接复用结果
+func (s *WorkflowService) executeRun(run *model.WorkflowRun) {
+	ctx := context.Background()
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Errorf(ctx, "[WorkflowService] 执行运行 panic: run=%d, err=%v", run.ID, r)
+			s.finishRun(ctx, run, dto.WorkflowRunFailed, fmt.Sprintf("执行异常: %v", r))
+		}
+	}()
+
+	leaseLost := make(chan struct{})
+	done := make(chan struct{})
+	defer close(done)
+	go s.keepLease(run.ID, done, leaseLost)
+
+	if run.StartedAt == nil {
+		now := time.Now()
+		_ = s.workflowRepo.UpdateRun(run.ID, map[string]interface{}{"started_at": &now})
+	}
+
+	var def dto.WorkflowDefinition
+	if err := json.Unmarshal(run.Definition, &def); err != nil {
+		s.finishRun(ctx, run, dto.WorkflowRunFailed, "解析工作流定义失败: "+err.Error())
+		return
+	}
+	order, err := workflowTopoOrder(def.Steps)
+	if err != nil {
+		s.finishRun(ctx, run, dto.WorkflowRunFailed, err.Error())
+		return
+	}
+
+	var input map[string]interface{}
+	_ = json.Unmarshal(run.Input, &input)
+	data := map[string]interface{}{workflowTriggerRef: input}
+
+	existing, err := s.workflowRepo.ListStepRuns([]int64{run.ID})
+	if err != nil {
+		logger.Warnf(ctx, "[WorkflowService] 获取步骤记录失败，稍后重试: run=%d, err=%v", run.ID, err)
+		return
+	}
+	stepRuns := make(map[string]*model.WorkflowStepRun, len(existing))
+	for _, stepRun := range existing {
+		stepRuns[stepRun.StepKey] = stepRun
+	}
+	schemas := s.loadStepSchemas(def.Steps)
+
+	// 执行前校验运行身份对每个步骤函数的权限（保存后权限可能已被回收）
+	for _, step := range order {
+		schema, ok := schemas[step.Key]
+		if !ok {
+			continue
+		}
+		if err := checkWorkflowStepPermission(ctx, run.RunAs, step, schema.function); err != nil {
+			s.finishRun(ctx, run, dto.WorkflowRunFailed, fmt.Sprintf("步骤 %s: %s", step.Key, err.Error()))
+			return
+		}
+	}
+
+	statuses := make(map[string]string, len(order))
+	for _, step := range order {
+		select {
+		case <-leaseLost:
+			logger.Infof(ctx, "[WorkflowService] 运行已停止: run=%d", run.ID)
+			return
+		default:
+		}
+
+		stepRun := stepRuns[step.Key]
+		if stepRun == nil {
+			stepRun = &model.WorkflowStepRun{
+				RunID:        run.ID,
+				StepKey:      step.Key,
+				StepName:     step.Name,
+				FullCodePath: step.FullCodePath,
+				Status:       dto.WorkflowStepPending,
+			}
+		}
+
+		// 接管的运行：已完成的步骤直接复用结果
+		switch stepRun.Status {
+		case dto.WorkflowStepSucceeded:
+			var result interface{}
+			_ = json.Unmarshal(stepRun.Response, &result)
+			data[step.Key] = result
+			statuses[step.Key] = stepRun.Status
+			continue
+		case dto.WorkflowStepSkipped:
+			statuses[step.Key] = stepRun.Status
+			continue
+		case dto.WorkflowStepFailed:
+			statuses[step.Key] = stepRun.Status
+			if !step.ContinueOnError {
+				s.finishRun(ctx, run, dto.WorkflowRunFailed, fmt.Sprintf("步骤 %s 执行失败: %s", step.Key, stepRun.Error))
+				return
+			}
+			continue
+		}
+
+		// 上游被跳过时跳过；条件不满足时跳过
+		skipReason := ""
+		for _, dep := range step.DependsOn {
+			if statuses[dep] == dto.WorkflowStepSkipped {
+				skipReason = fmt.Sprintf("上游步骤 %s 已跳过", dep)
+				break
+			}
+		}
+		if skipReason == "" {
+			if ok, reason := evaluateWorkflowConditions(step.Conditions, data); !ok {
+				skipReason = reason
+			}
+		}
+		if skipReason != "" {
+			now := time.Now()
+			stepRun.Status = dto.WorkflowStepSkipped
+			stepRun.FinishedAt = &now
+			appendWorkflowLog(stepRun, "跳过: "+skipReason)
+			s.saveStepRun(ctx, stepRun)
+			statuses[step.Key] = dto.WorkflowStepSkipped
+			continue
+		}
+
+		result, err := s.executeStep(ctx, run, step, stepRun, schemas[step.Key], data, leaseLost)
+		if errors.Is(err, errWorkflowRunStopped) {
+			logger.Infof(ctx, "[WorkflowService] 运行已停止: run=%d", run.ID)
+			return
+		}
+		if err != nil {
+			statuses[step.Key] = dto.WorkflowStepFailed
+			if !step.ContinueOnError {
+				s.finishRun(ctx, run, dto.WorkflowRunFailed, fmt.Sprintf("步骤 %s 执行失败: %s", step.Key, err.Error()))
+				return
+			}
+			continue
+		}
+		data[step.Key] = result
+		statuses[step.Key] = dto.WorkflowStepSucceeded
+	}
+
+	s.finishRun(ctx, run, dto.WorkflowRunSucceeded, "")
+}
+
+// executeStep 执行单个步骤（失败时按配置重试，每次调用都会持久化调用次数和日志）
+func (s *WorkflowService) executeStep(ctx context.Context, run *model.WorkflowRun, step *dto.WorkflowStep, stepRun *model.WorkflowStepRun,
+	schema *workflowStepSchema, data map[string]interface{}, leaseLost <-chan struct{}) (interface{}, error) {
+	if schema == nil {
+		return nil, s.failStep(ctx, stepRun, fmt.Errorf("函数 %s 不存在", step.FullCodePath))
+	}
+
+	request, err := buildWorkflowRequest(step, schema, data)
+	if err != nil {
+		return nil, s.failStep(ctx, stepRun, err)
+	}
+	stepRun.Request, _ = json.Marshal(request)
+	if stepRun.StartedAt == nil {
+		now := time.Now()
+		stepRun.StartedAt = &now
+	}
+
+	maxAttempts := step.MaxRetries + 1
+	retryWait := time.Duration(step.RetryIntervalSeconds) * time.Second
+	if retryWait <= 0 {
+		retryWait = workflowDefaultRetryWait
+	}
+
+	var lastErr error
+	for stepRun.Attempts < maxAttempts {
+		stepRun.Attempts++
+		stepRun.Status = dto.WorkflowStepRunning
+		s.saveStepRun(ctx, stepRun)
+
+		start := time.Now()
+		result, err := s.callWorkflowFunction(ctx, run, step, schema.function, request)
+		cost := time.Since(start).Milliseconds()
+		if err == nil {
+			now := time.Now()
+			stepRun.Status = dto.WorkflowStepSucceeded
+			stepRun.Response, _ = json.Marshal(result)
+			stepRun.Error = ""
+			stepRun.FinishedAt = &now
+			appendWorkflowLog(stepRun, fmt.Sprintf("第 %d 次调用成功，耗时 %dms", stepRun.Attempts, cost))
+			s.saveStepRun(ctx, stepRun)
+			return result, nil
+		}
+
+		lastErr = err
+		stepRun.Error = err.Error()
+		appendWorkflowLog(stepRun, fmt.Sprintf("第 %d 次调用失败，耗时 %dms: %s", stepRun.Attempts, cost, err.Error()))
+		s.saveStepRun(ctx, stepRun)
+		if stepRun.Attempts >= maxAttempts {
+			break
+		}
+
+		timer := time.NewTimer(retryWait)
+		select {
+		case <-timer.C:
+		case <-leaseLost:
+			timer.Stop()
+			return nil, errWorkflowRunStopped
+		case <-s.stopCh:
+			timer.Stop()
+			return nil, errWorkflowRunStopped
+		}
+	}
+
+	if lastErr == nil {
+		lastErr = fmt.Errorf("已达到最大调用次数 %d", maxAttempts)
+	}
+	return nil, s.failStep(ctx, stepRun, lastErr)
+}
+
+// failStep 标记步骤失败
+func (s *WorkflowService) failStep(ctx context.Context, stepRun *model.WorkflowStepRun, err error) error {
+	now := time.Now()
+	stepRun.Status = dto.WorkflowStepFailed
+	stepRun.Error = err.Error()
+	stepRun.FinishedAt = &now
+	appendWorkflowLog(stepRun, "执行失败: "+err.Error())
+	s.saveStepRun(ctx, stepRun)
+	return err
+}
+
+// callWorkflowFunction 以运行身份调用函数（直接调用或 Table 回调）
+func (s *WorkflowService) callWorkflowFunction(ctx context.Context, run *model.WorkflowRun, step *dto.WorkflowStep, fn *model.Function, request map[string]interface{}) (interface{}, error) {
+	parts := strings.SplitN(strings.TrimPrefix(step.FullCodePath, "/"), "/", 3)
+	if len(parts) < 3 {
+		return nil, fmt.Errorf("full-code-path 格式错误: %s", step.FullCodePath)
+	}
+	user, app, router := parts[0], parts[1], parts[2]
+
+	body, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("序列化请求失败: %w", err)
+	}
+	req := &dto.RequestAppReq{
+		User:        user,
+		App:         app,
+		Router:      router,
+		Method:      fn.Method,
+		TraceId:     run.TraceID,
+		RequestUser: run.RunAs,
+	}
+	switch {
+	case step.Callback != "":
+		req.Router = "/_callback"
+		req.IsCallback = true
+		req.Body, err = json.Marshal(map[string]interface{}{
+			"method": fn.Method,
+			"router": router,
+			"body":   body,
+			"type":   step.Callback,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("序列化回调请求失败: %w", err)
+		}
+	case strings.EqualFold(fn.Method, "GET"):
+		req.UrlQuery = encodeWorkflowQuery(request)
+	default:
+		req.Body = body
+	}
+
+	callCtx, cancel := context.WithTimeout(ctx, workflowStepTimeout)
+	defer cancel()
+	resp, err := s.appService.RequestApp(callCtx, req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.IsError() {
+		return nil, fmt.Errorf("%s", resp.Error)
+	}
+	return resp.Result, nil
+}
+
+// finishRun 结束运行（只有仍由当前实例执行的运行才能结束，已取消的运行不会被覆盖）
+func (s *WorkflowService) finishRun(ctx context.Context, run *model.WorkflowRun, status, errMsg string) {
+	now := time.Now()
+	ok, err := s.workflowRepo.FinishRun(run.ID, s.workerID, status, errMsg, now)
+	if err != nil {
+		logger.Warnf(ctx, "[WorkflowService] 结束运行失败: run=%d, err=%v", run.ID, err)
+		return
+	}
+	if ok {
+		logger.Infof(ctx, "[WorkflowService] 运行结束: workflow=%d, run=%d, status=%s, err=%s", run.WorkflowID, run.ID, status, errMsg)
+	}
+}
+
+// keepLease 定期续约，续约失败（被取消或被接管）时通知执行方停止
+func (s *WorkflowService) keepLease(runID int64, done <-chan struct{}, leaseLost chan<- struct{}) {
+	ticker := time.NewTicker(workflowLeaseRenewal)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			ok, err := s.workflowRepo.RenewLease(runID, s.workerID, time.Now().Add(workflowLeaseDuration))
+			if err != nil {
+				// 数据库暂时不可用时不中断执行，租约未过期前还有机会续约
+				logger.Warnf(context.Background(), "[WorkflowService] 续约失败: run=%d, err=%v", runID, err)
+				continue
+			}
+			if !ok {
+				close(leaseLost)
+				return
+			}
+		}
+	}
+}
+
+func (s *WorkflowService) saveStepRun(ctx context.Context, stepRun *model.WorkflowStepRun) {
+	if err := s.workflowRepo.SaveStepRun(stepRun); err != nil {
+		logger.Warnf(ctx, "[WorkflowService] 保存步骤记录失败: run=%d, step=%s, err=%v", stepRun.RunID, stepRun.StepKey, err)
+	}
+}
+
+// ============================================
+// 数据映射和条件
+// ============================================
+
+// buildWorkflowRequest 按输入映射构建函数请求参数，并按目标字段类型做必要的转换
+func buildWorkflowRequest(step *dto.WorkflowStep, schema *workflowStepSchema, data map[string]interface{}) (map[string]interface{}, error) {
+	request := make(map[string]interface{}, len(step.Inputs))
+	for _, input := range step.Inputs {
+		if input == nil {
+			continue
+		}
+		value := input.Value
+		if input.From != "" {
+			v, ok := resolveWorkflowPath(data, input.From)
+			if !ok {
+				return nil, fmt.Errorf("字段 %s 的数据来源 %s 没有值", input.Field, input.From)
+			}
+			value = v
+		}
+		request[input.Field] = coerceWorkflowValue(fieldDataType(findWorkflowField(schema.request, input.Field)), value)
+	}
+	return request, nil
+}
+
+// resolveWorkflowPath 读取引用的值，如 create_customer.items.0.id
+func resolveWorkflowPath(data map[string]interface{}, path string) (interface{}, bool) {
+	var current interface{} = data
+	for _, segment := range strings.Split(path, ".") {
+		switch v := current.(type) {
+		case map[string]interface{}:
+			next, ok := v[segment]
+			if !ok {
+				return nil, false
+			}
+			current = next
+		case []interface{}:
+			idx, err := strconv.Atoi(segment)
+			if err != nil || idx < 0 || idx >= len(v) {
+				return nil, false
+			}
+			current = v[idx]
+		default:
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// coerceWorkflowValue 数字和布尔值赋给字符串字段时转成字符串（保存时已校验类型兼容）
+func coerceWorkflowValue(targetType string, value interface{}) interface{} {
+	if targetType != widget.DataTypeString {
+		return value
+	}
+	switch v := value.(type) {
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	}
+	return value
+}
+
+// evaluateWorkflowConditions 判断步骤的条件是否全部满足，不满足时返回原因
+func evaluateWorkflowConditions(conditions []*dto.WorkflowCondition, data map[string]interface{}) (bool, string) {
+	for _, condition := range conditions {
+		if condition == nil {
+			continue
+		}
+		value, _ := resolveWorkflowPath(data, condition.From)
+		if !matchWorkflowCondition(condition.Op, value, condition.Value) {
+			return false, fmt.Sprintf("条件不满足: %s %s %v（实际值: %v）", condition.From, condition.Op, condition.Value, value)
+		}
+	}
+	return true, ""
+}
+
+func matchWorkflowCondition(op string, actual, expected interface{}) bool {
+	switch op {
+	case dto.WorkflowOpEmpty:
+		return isWorkflowEmpty(actual)
+	case dto.WorkflowOpNotEmpty:
+		return !isWorkflowEmpty(actual)
+	case dto.WorkflowOpEq:
+		return workflowEqual(actual, expected)
+	case dto.WorkflowOpNe:
+		return !workflowEqual(actual, expected)
+	case dto.WorkflowOpContains:
+		if items, ok := actual.([]interface{}); ok {
+			for _, item := range items {
+				if workflowEqual(item, expected) {
+					return true
+				}
+			}
+			return false
+		}
+		return strings.Contains(fmt.Sprint(actual), fmt.Sprint(expected))
+	case dto.WorkflowOpGt, dto.WorkflowOpGte, dto.WorkflowOpLt, dto.WorkflowOpLte:
+		a, ok1 := toWorkflowNumber(actual)
+		b, ok2 := toWorkflowNumber(expected)
+		if !ok1 || !ok2 {
+			return false
+		}
+		switch op {
+		case dto.WorkflowOpGt:
+			return a > b
+		case dto.WorkflowOpGte:
+			return a >= b
+		case dto.WorkflowOpLt:
+			return a < b
+		default:
+			return a <= b
+		}
+	}
+	return false
+}
+
+func workflowEqual(a, b interface{}) bool {
+	if x, ok := toWorkflowNumber(a); ok {
+		if y, ok := toWorkflowNumber(b); ok {
+			return x == y
+		}
+	}
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return fmt.Sprint(a) == fmt.Sprint(b)
+}
+
+func toWorkflowNumber(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case string:
+		f, err := strconv.ParseFloat(n, 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+func isWorkflowEmpty(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map:
+		return rv.Len() == 0
+	}
+	return false
+}
+
+// encodeWorkflowQuery GET 函数的参数编码为查询字符串（数组用逗号拼接）
+func encodeWorkflowQuery(request map[string]interface{}) string {
+	values := url.Values{}
+	for key, value := range request {
+		switch v := value.(type) {
+		case nil:
+		case []interface{}:
+			items := make([]string, 0, len(v))
+			for _, item := range v {
+				items = append(items, fmt.Sprint(item))
+			}
+			values.Set(key, strings.Join(items, ","))
+		case float64:
+			values.Set(key, strconv.FormatFloat(v, 'f', -1, 64))
+		default:
+			values.Set(key, fmt.Sprint(v))
+		}
+	}
+	return values.Encode()
+}
+
+// appendWorkflowLog 追加步骤日志（带时间戳）
+func appendWorkflowLog(stepRun *model.WorkflowStepRun, line string) {
+	var logs []string
+	if len(stepRun.Logs) > 0 {
+		_ = json.Unmarshal(stepRun.Logs, &logs)
+	}
+	logs = append(logs, time.Now().Format(time.DateTime)+" "+line)
+	stepRun.Logs, _ = json.Marshal(logs)
+}
@@ -0,0 +1,527 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ai-agent-os/ai-agent-os/core/app-server/model"
+	"github.com/ai-agent-os/ai-agent-os/core/app-server/repository"
+	"github.com/ai-agent-os/ai-agent-os/dto"
+	"github.com/ai-agent-os/ai-agent-os/enterprise"
+	"github.com/ai-agent-os/ai-agent-os/pkg/contextx"
+	"github.com/ai-agent-os/ai-agent-os/pkg/license"
+	"github.com/ai-agent-os/ai-agent-os/pkg/logger"
+	"github.com/ai-agent-os/ai-agent-os/pkg/permission"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	workflowPollInterval     = 3 * time.Second  // 领取待执行运行的间隔
+	workflowScheduleInterval = 20 * time.Second // 检查定时触发的间隔（按分钟去重）
+	workflowLeaseDuration    = 2 * time.Minute  // 运行租约时长
+	workflowLeaseRenewal     = 30 * time.Second // 续约间隔
+	workflowMaxRunning       = 8                // 单个实例最多同时执行的运行数
+	workflowStepTimeout      = 2 * time.Minute  // 单次函数调用超时
+	workflowDefaultRetryWait = 5 * time.Second  // 未配置重试间隔时的默认值
+)
+
+// workflowTableEvents 表格事件触发支持的事件
+var workflowTableEvents = map[string]bool{"OnTableAddRow": true, "OnTableUpdateRow": true, "OnTableDeleteRows": true}
+
+// WorkflowService 工作流编排服务
+// 工作流是以 full-code-path 标识的函数组成的 DAG，保存时按函数的请求/响应字段校验输入映射的类型兼容性；
+// 运行记录持久化在数据库中，由各个 app-server 实例按租约领取执行，以运行身份（手动运行为操作人，其余为工作流创建者）
+// 通过 AppService.RequestApp 调用函数；该调用不经过权限中间件，保存和运行时都会校验运行身份对每个步骤函数的权限
+type WorkflowService struct {
+	workflowRepo *repository.WorkflowRepository
+	functionRepo *repository.FunctionRepository
+	appService   *AppService
+	workerID     string        // 当前实例标识（租约持有者）
+	slots        chan struct{} // 并发执行槽位
+	wake         chan struct{} // 有新运行时唤醒领取
+	stopCh       chan struct{}
+}
+
+// NewWorkflowService 创建工作流编排服务
+func NewWorkflowService(workflowRepo *repository.WorkflowRepository, functionRepo *repository.FunctionRepository, appService *AppService) *WorkflowService {
+	hostname, _ := os.Hostname()
+	s := &WorkflowService{
+		workflowRepo: workflowRepo,
+		functionRepo: functionRepo,
+		appService:   appService,
+		workerID:     fmt.Sprintf("%s-%d-%s", hostname, os.Getpid(), uuid.New().String()[:8]),
+		slots:        make(chan struct{}, workflowMaxRunning),
+		wake:         make(chan struct{}, 1),
+		stopCh:       make(chan struct{}),
+	}
+
+	// 表格数据变更触发
+	appService.OnTableEvent(s.handleTableEvent)
+
+	// 后台执行运行、检查定时触发
+	go s.runWorker()
+	go s.runScheduler()
+
+	return s
+}
+
+// SaveWorkflow 创建或更新工作流（校验不通过时返回所有问题）
+func (s *WorkflowService) SaveWorkflow(ctx context.Context, req *dto.SaveWorkflowReq) (*dto.SaveWorkflowResp, error) {
+	username := contextx.GetRequestUser(ctx)
+
+	if problems := s.validateWorkflow(ctx, username, &req.Definition, req.Triggers); len(problems) > 0 {
+		return nil, fmt.Errorf("工作流校验失败: %s", strings.Join(problems, "；"))
+	}
+	definition, err := json.Marshal(req.Definition)
+	if err != nil {
+		return nil, fmt.Errorf("序列化工作流定义失败: %w", err)
+	}
+	triggers, err := json.Marshal(req.Triggers)
+	if err != nil {
+		return nil, fmt.Errorf("序列化触发器失败: %w", err)
+	}
+
+	if req.ID == 0 {
+		workflow := &model.Workflow{
+			Name:        req.Name,
+			Description: req.Description,
+			Definition:  definition,
+			Triggers:    triggers,
+			Enabled:     req.Enabled,
+		}
+		workflow.CreatedBy = username
+		workflow.UpdatedBy = username
+		if err := s.workflowRepo.CreateWorkflow(workflow); err != nil {
+			return nil, fmt.Errorf("创建工作流失败: %w", err)
+		}
+		logger.Infof(ctx, "[WorkflowService] 创建工作流: id=%d, name=%s, steps=%d", workflow.ID, workflow.Name, len(req.Definition.Steps))
+		return &dto.SaveWorkflowResp{ID: workflow.ID}, nil
+	}
+
+	if _, err := s.getOwnedWorkflow(ctx, req.ID); err != nil {
+		return nil, err
+	}
+	err = s.workflowRepo.UpdateWorkflow(req.ID, map[string]interface{}{
+		"name":        req.Name,
+		"description": req.Description,
+		"definition":  definition,
+		"triggers":    triggers,
+		"enabled":     req.Enabled,
+		"updated_by":  username,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("更新工作流失败: %w", err)
+	}
+	logger.Infof(ctx, "[WorkflowService] 更新工作流: id=%d, name=%s, steps=%d", req.ID, req.Name, len(req.Definition.Steps))
+	return &dto.SaveWorkflowResp{ID: req.ID}, nil
+}
+
+// GetWorkflows 获取当前用户的工作流列表
+func (s *WorkflowService) GetWorkflows(ctx context.Context, req *dto.GetWorkflowsReq) (*dto.GetWorkflowsResp, error) {
+	username := contextx.GetRequestUser(ctx)
+	page, pageSize := normalizePage(req.Page, req.PageSize)
+
+	workflows, total, err := s.workflowRepo.ListWorkflows(username, req.Keyword, page, pageSize)
+	if err != nil {
+		return nil, fmt.Errorf("获取工作流列表失败: %w", err)
+	}
+	infos := make([]dto.WorkflowInfo, 0, len(workflows))
+	for _, workflow := range workflows {
+		infos = append(infos, convertWorkflow(workflow))
+	}
+	return &dto.GetWorkflowsResp{
+		Workflows: infos,
+		Total:     total,
+		Page:      page,
+		PageSize:  pageSize,
+	}, nil
+}
+
+// GetWorkflow 获取工作流详情
+func (s *WorkflowService) GetWorkflow(ctx context.Context, id int64) (*dto.WorkflowInfo, error) {
+	workflow, err := s.getOwnedWorkflow(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	info := convertWorkflow(workflow)
+	return &info, nil
+}
+
+// DeleteWorkflow 删除工作流（运行记录保留）
+func (s *WorkflowService) DeleteWorkflow(ctx context.Context, req *dto.DeleteWorkflowReq) error {
+	if _, err := s.getOwnedWorkflow(ctx, req.ID); err != nil {
+		return err
+	}
+	if err := s.workflowRepo.DeleteWorkflow(req.ID); err != nil {
+		return fmt.Errorf("删除工作流失败: %w", err)
+	}
+	return nil
+}
+
+// RunWorkflow 手动运行工作流（以当前用户身份调用函数）
+func (s *WorkflowService) RunWorkflow(ctx context.Context, req *dto.RunWorkflowReq) (*dto.RunWorkflowResp, error) {
+	workflow, err := s.getOwnedWorkflow(ctx, req.ID)
+	if err != nil {
+		return nil, err
+	}
+	run, err := s.createRun(ctx, workflow, dto.WorkflowTriggerManual, contextx.GetRequestUser(ctx), req.Input)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.RunWorkflowResp{RunID: run.ID}, nil
+}
+
+// CancelRun 取消运行（正在执行的步骤会执行完，之后的步骤不再执行）
+func (s *WorkflowService) CancelRun(ctx context.Context, req *dto.CancelWorkflowRunReq) error {
+	run, err := s.workflowRepo.GetRun(req.RunID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("运行记录不存在")
+		}
+		return fmt.Errorf("获取运行记录失败: %w", err)
+	}
+	if _, err := s.getOwnedWorkflow(ctx, run.WorkflowID); err != nil {
+		return err
+	}
+	ok, err := s.workflowRepo.CancelRun(run.ID, time.Now())
+	if err != nil {
+		return fmt.Errorf("取消运行失败: %w", err)
+	}
+	if !ok {
+		return fmt.Errorf("运行已结束")
+	}
+	return nil
+}
+
+// GetRuns 获取工作流的运行记录（不含步骤）
+func (s *WorkflowService) GetRuns(ctx context.Context, req *dto.GetWorkflowRunsReq) (*dto.GetWorkflowRunsResp, error) {
+	if _, err := s.getOwnedWorkflow(ctx, req.WorkflowID); err != nil {
+		return nil, err
+	}
+	page, pageSize := normalizePage(req.Page, req.PageSize)
+	runs, total, err := s.workflowRepo.ListRuns(req.WorkflowID, page, pageSize)
+	if err != nil {
+		return nil, fmt.Errorf("获取运行记录失败: %w", err)
+	}
+	infos := make([]dto.WorkflowRunInfo, 0, len(runs))
+	for _, run := range runs {
+		infos = append(infos, convertWorkflowRun(run, nil))
+	}
+	return &dto.GetWorkflowRunsResp{
+		Runs:     infos,
+		Total:    total,
+		Page:     page,
+		PageSize: pageSize,
+	}, nil
+}
+
+// GetRun 获取运行详情（包含每个步骤的请求、响应和日志）
+func (s *WorkflowService) GetRun(ctx context.Context, runID int64) (*dto.WorkflowRunInfo, error) {
+	run, err := s.workflowRepo.GetRun(runID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("运行记录不存在")
+		}
+		return nil, fmt.Errorf("获取运行记录失败: %w", err)
+	}
+	if _, err := s.getOwnedWorkflow(ctx, run.WorkflowID); err != nil {
+		return nil, err
+	}
+	stepRuns, err := s.workflowRepo.ListStepRuns([]int64{run.ID})
+	if err != nil {
+		return nil, fmt.Errorf("获取步骤记录失败: %w", err)
+	}
+	info := convertWorkflowRun(run, stepRuns)
+	return &info, nil
+}
+
+// SuggestInputs 推荐步骤输入映射：按字段 code 和类型，从最近的上游步骤输出中为未映射的请求字段查找匹配项
+func (s *WorkflowService) SuggestInputs(ctx context.Context, req *dto.SuggestWorkflowInputsReq) (*dto.SuggestWorkflowInputsResp, error) {
+	def := req.Definition
+	schemas := s.loadStepSchemas(def.Steps)
+	steps := make(map[string]*dto.WorkflowStep, len(def.Steps))
+	for _, step := range def.Steps {
+		if step != nil {
+			steps[step.Key] = step
+		}
+	}
+
+	for _, step := range def.Steps {
+		if step == nil {
+			continue
+		}
+		target, ok := schemas[step.Key]
+		if !ok {
+			continue
+		}
+		mapped := make(map[string]bool, len(step.Inputs))
+		for _, input := range step.Inputs {
+			if input != nil {
+				mapped[input.Field] = true
+			}
+		}
+		upstream := workflowUpstream(steps, step.Key)
+		for _, field := range target.request {
+			if mapped[field.Code] {
+				continue
+			}
+			for _, key := range upstream {
+				source, ok := schemas[key]
+				if !ok || steps[key].Callback != "" {
+					continue
+				}
+				if out := findWorkflowField(source.response, field.Code); out != nil && workflowTypeCompatible(fieldDataType(out), fieldDataType(field)) {
+					step.Inputs = append(step.Inputs, &dto.WorkflowInput{Field: field.Code, From: key + "." + field.Code})
+					break
+				}
+			}
+		}
+	}
+
+	return &dto.SuggestWorkflowInputsResp{
+		Definition: def,
+		Warnings:   s.validateWorkflow(ctx, contextx.GetRequestUser(ctx), &def, nil),
+	}, nil
+}
+
+// ============================================
+// 触发
+// ============================================
+
+// createRun 创建运行记录并唤醒执行
+func (s *WorkflowService) createRun(ctx context.Context, workflow *model.Workflow, triggerType, runAs string, input map[string]interface{}) (*model.WorkflowRun, error) {
+	if input == nil {
+		input = map[string]interface{}{}
+	}
+	inputData, err := json.Marshal(input)
+	if err != nil {
+		return nil, fmt.Errorf("序列化触发数据失败: %w", err)
+	}
+	run := &model.WorkflowRun{
+		WorkflowID:  workflow.ID,
+		Definition:  workflow.Definition,
+		Status:      dto.WorkflowRunPending,
+		TriggerType: triggerType,
+		RunAs:       runAs,
+		Input:       inputData,
+		TraceID:     uuid.New().String(),
+	}
+	run.CreatedBy = runAs
+	if err := s.workflowRepo.CreateRun(run); err != nil {
+		return nil, fmt.Errorf("创建运行记录失败: %w", err)
+	}
+	logger.Infof(ctx, "[WorkflowService] 创建运行: workflow=%d, run=%d, trigger=%s, run_as=%s", workflow.ID, run.ID, triggerType, runAs)
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+	return run, nil
+}
+
+// handleTableEvent 表格数据变更时触发订阅了该事件的工作流
+// 以工作流创建者身份运行（而不是表格操作人，避免操作人借工作流调用自己无权调用的函数），创建者需要有该表格的查看权限
+func (s *WorkflowService) handleTableEvent(ctx context.Context, event *TableEvent) {
+	if !license.GetManager().HasFeature(enterprise.FeatureWorkflow) {
+		return
+	}
+	go func() {
+		ctx := context.Background()
+		workflows, err := s.workflowRepo.ListEnabledWorkflows(event.FullCodePath)
+		if err != nil {
+			logger.Warnf(ctx, "[WorkflowService] 获取表格事件工作流失败: %v", err)
+			return
+		}
+		var body interface{}
+		if len(event.Body) > 0 {
+			_ = json.Unmarshal(event.Body, &body)
+		}
+		for _, workflow := range workflows {
+			if !workflowListensTableEvent(workflow, event) {
+				continue
+			}
+			if err := checkWorkflowPermission(ctx, workflow.CreatedBy, event.FullCodePath, permission.FunctionRead); err != nil {
+				logger.Warnf(ctx, "[WorkflowService] 表格事件未触发工作流: workflow=%d, err=%v", workflow.ID, err)
+				continue
+			}
+			input := map[string]interface{}{
+				"action":         event.Action,
+				"full_code_path": event.FullCodePath,
+				"operator":       event.RequestUser,
+				"body":           body,
+				"result":         event.Result,
+			}
+			if _, err := s.createRun(ctx, workflow, dto.WorkflowTriggerTableEvent, workflow.CreatedBy, input); err != nil {
+				logger.Warnf(ctx, "[WorkflowService] 表格事件触发工作流失败: workflow=%d, err=%v", workflow.ID, err)
+			}
+		}
+	}()
+}
+
+// runScheduler 检查定时触发（每分钟最多触发一次，多实例通过条件更新去重）
+func (s *WorkflowService) runScheduler() {
+	ticker := time.NewTicker(workflowScheduleInterval)
+	defer ticker.Stop()
+	var lastMinute time.Time
+	for {
+		select {
+		case <-s.stopCh:
+			return
+		case now := <-ticker.C:
+			minute := now.Truncate(time.Minute)
+			if !minute.After(lastMinute) {
+				continue
+			}
+			lastMinute = minute
+			s.fireSchedules(minute)
+		}
+	}
+}
+
+func (s *WorkflowService) fireSchedules(minute time.Time) {
+	if !license.GetManager().HasFeature(enterprise.FeatureWorkflow) {
+		return
+	}
+	ctx := context.Background()
+	workflows, err := s.workflowRepo.ListEnabledWorkflows(`"` + dto.WorkflowTriggerSchedule + `"`)
+	if err != nil {
+		logger.Warnf(ctx, "[WorkflowService] 获取定时工作流失败: %v", err)
+		return
+	}
+	for _, workflow := range workflows {
+		if !workflowScheduleMatches(workflow, minute) {
+			continue
+		}
+		ok, err := s.workflowRepo.ClaimSchedule(workflow.ID, minute)
+		if err != nil || !ok {
+			continue
+		}
+		// 定时触发以工作流创建者的身份运行
+		input := map[string]interface{}{"scheduled_at": minute.Format(time.DateTime)}
+		if _, err := s.createRun(ctx, workflow, dto.WorkflowTriggerSchedule, workflow.CreatedBy, input); err != nil {
+			logger.Warnf(ctx, "[WorkflowService] 定时触发工作流失败: workflow=%d, err=%v", workflow.ID, err)
+		}
+	}
+}
+
+// Close 停止后台任务（正在执行的运行租约过期后会被其他实例接管）
+func (s *WorkflowService) Close() error {
+	close(s.stopCh)
+	return nil
+}
+
+// ============================================
+// 辅助函数
+// ============================================
+
+// getOwnedWorkflow 获取当前用户创建的工作流
+func (s *WorkflowService) getOwnedWorkflow(ctx context.Context, id int64) (*model.Workflow, error) {
+	workflow, err := s.workflowRepo.GetWorkflow(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("工作流不存在")
+		}
+		return nil, fmt.Errorf("获取工作流失败: %w", err)
+	}
+	if workflow.CreatedBy != contextx.GetRequestUser(ctx) {
+		return nil, fmt.Errorf("无权操作该工作流")
+	}
+	return workflow, nil
+}
+
+func decodeWorkflowTriggers(data json.RawMessage) []*dto.WorkflowTrigger {
+	var triggers []*dto.WorkflowTrigger
+	if len(data) > 0 {
+		_ = json.Unmarshal(data, &triggers)
+	}
+	return triggers
+}
+
+func workflowListensTableEvent(workflow *model.Workflow, event *TableEvent) bool {
+	for _, trigger := range decodeWorkflowTriggers(workflow.Triggers) {
+		if trigger == nil || trigger.Type != dto.WorkflowTriggerTableEvent || trigger.FullCodePath != event.FullCodePath {
+			continue
+		}
+		if len(trigger.Events) == 0 {
+			return true
+		}
+		for _, e := range trigger.Events {
+			if e == event.Action {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func workflowScheduleMatches(workflow *model.Workflow, minute time.Time) bool {
+	for _, trigger := range decodeWorkflowTriggers(workflow.Triggers) {
+		if trigger == nil || trigger.Type != dto.WorkflowTriggerSchedule {
+			continue
+		}
+		schedule, err := parseCron(trigger.Cron)
+		if err == nil && schedule.Match(minute) {
+			return true
+		}
+	}
+	return false
+}
+
+func convertWorkflow(workflow *model.Workflow) dto.WorkflowInfo {
+	info := dto.WorkflowInfo{
+		ID:          workflow.ID,
+		Name:        workflow.Name,
+		Description: workflow.Description,
+		Triggers:    decodeWorkflowTriggers(workflow.Triggers),
+		Enabled:     workflow.Enabled,
+		CreatedBy:   workflow.CreatedBy,
+		CreatedAt:   time.Time(workflow.CreatedAt).Format(time.DateTime),
+		UpdatedAt:   time.Time(workflow.UpdatedAt).Format(time.DateTime),
+	}
+	if len(workflow.Definition) > 0 {
+		_ = json.Unmarshal(workflow.Definition, &info.Definition)
+	}
+	return info
+}
+
+func convertWorkflowRun(run *model.WorkflowRun, stepRuns []*model.WorkflowStepRun) dto.WorkflowRunInfo {
+	info := dto.WorkflowRunInfo{
+		ID:          run.ID,
+		WorkflowID:  run.WorkflowID,
+		Status:      run.Status,
+		TriggerType: run.TriggerType,
+		RunAs:       run.RunAs,
+		Input:       run.Input,
+		Error:       run.Error,
+		TraceID:     run.TraceID,
+		StartedAt:   formatOptionalTime(run.StartedAt),
+		FinishedAt:  formatOptionalTime(run.FinishedAt),
+		CreatedAt:   time.Time(run.CreatedAt).Format(time.DateTime),
+	}
+	for _, stepRun := range stepRuns {
+		var logs []string
+		if len(stepRun.Logs) > 0 {
+			_ = json.Unmarshal(stepRun.Logs, &logs)
+		}
+		info.Steps = append(info.Steps, dto.WorkflowStepRunInfo{
+			StepKey:      stepRun.StepKey,
+			StepName:     stepRun.StepName,
+			FullCodePath: stepRun.FullCodePath,
+			Status:       stepRun.Status,
+			Attempts:     stepRun.Attempts,
+			Request:      stepRun.Request,
+			Response:     stepRun.Response,
+			Error:        stepRun.Error,
+			Logs:         logs,
+			StartedAt:    formatOptionalTime(stepRun.StartedAt),
+			FinishedAt:   formatOptionalTime(stepRun.FinishedAt),
+		})
+	}
+	return info
+}
@@ -0,0 +1,382 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/ai-agent-os/ai-agent-os/core/app-server/model"
+	"github.com/ai-agent-os/ai-agent-os/dto"
+	"github.com/ai-agent-os/ai-agent-os/enterprise"
+	"github.com/ai-agent-os/ai-agent-os/pkg/license"
+	"github.com/ai-agent-os/ai-agent-os/pkg/permission"
+	"github.com/ai-agent-os/ai-agent-os/sdk/agent-app/widget"
+)
+
+const (
+	workflowTriggerRef    = "trigger" // 触发数据的引用前缀
+	workflowMaxRetries    = 10
+	workflowMaxRetryWait  = 3600
+	workflowTemplateTable = "table"
+)
+
+// workflowConditionOps 支持的条件运算符
+var workflowConditionOps = map[string]bool{
+	dto.WorkflowOpEq: true, dto.WorkflowOpNe: true,
+	dto.WorkflowOpGt: true, dto.WorkflowOpGte: true, dto.WorkflowOpLt: true, dto.WorkflowOpLte: true,
+	dto.WorkflowOpContains: true, dto.WorkflowOpEmpty: true, dto.WorkflowOpNotEmpty: true,
+}
+
+// workflowStepSchema 步骤调用的函数及其请求/响应字段
+type workflowStepSchema struct {
+	function *model.Function
+	request  []*widget.Field
+	response []*widget.Field
+}
+
+// validateWorkflow 校验工作流定义和触发器，返回所有问题（为空表示通过）
+// triggers 为 nil 时只校验定义；username 为工作流的运行身份，需要对每个步骤和表格触发器的函数有权限
+func (s *WorkflowService) validateWorkflow(ctx context.Context, username string, def *dto.WorkflowDefinition, triggers []*dto.WorkflowTrigger) []string {
+	var problems []string
+	addf := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	if len(def.Steps) == 0 {
+		addf("工作流至少需要一个步骤")
+	}
+
+	// 1. 步骤标识
+	steps := make(map[string]*dto.WorkflowStep, len(def.Steps))
+	for i, step := range def.Steps {
+		switch {
+		case step == nil:
+			addf("第 %d 个步骤为空", i+1)
+		case step.Key == "":
+			addf("第 %d 个步骤缺少 key", i+1)
+		case step.Key == workflowTriggerRef || strings.Contains(step.Key, "."):
+			addf("步骤 key 不能是 %s 且不能包含「.」: %s", workflowTriggerRef, step.Key)
+		case steps[step.Key] != nil:
+			addf("步骤 key 重复: %s", step.Key)
+		default:
+			steps[step.Key] = step
+		}
+	}
+
+	// 2. 依赖关系（必须是 DAG）
+	for _, step := range steps {
+		for _, dep := range step.DependsOn {
+			if dep == step.Key {
+				addf("步骤 %s 不能依赖自己", step.Key)
+			} else if steps[dep] == nil {
+				addf("步骤 %s 依赖的步骤 %s 不存在", step.Key, dep)
+			}
+		}
+	}
+	if len(problems) == 0 {
+		if _, err := workflowTopoOrder(def.Steps); err != nil {
+			addf("%s", err.Error())
+		}
+	}
+
+	// 3. 函数、输入映射和条件
+	schemas := s.loadStepSchemas(def.Steps)
+	for _, step := range def.Steps {
+		if step == nil || steps[step.Key] != step {
+			continue
+		}
+		target, ok := schemas[step.Key]
+		if !ok {
+			addf("步骤 %s 的函数 %s 不存在", step.Key, step.FullCodePath)
+			continue
+		}
+		if step.Callback != "" {
+			if !workflowTableEvents[step.Callback] {
+				addf("步骤 %s 不支持的回调: %s", step.Key, step.Callback)
+			} else if target.function.TemplateType != workflowTemplateTable {
+				addf("步骤 %s 的函数不是 Table 函数，不能调用 %s", step.Key, step.Callback)
+			}
+		}
+		if err := checkWorkflowStepPermission(ctx, username, step, target.function); err != nil {
+			addf("步骤 %s: %s", step.Key, err.Error())
+		}
+		if step.MaxRetries < 0 || step.MaxRetries > workflowMaxRetries {
+			addf("步骤 %s 的重试次数需要在 0-%d 之间", step.Key, workflowMaxRetries)
+		}
+		if step.RetryIntervalSeconds < 0 || step.RetryIntervalSeconds > workflowMaxRetryWait {
+			addf("步骤 %s 的重试间隔需要在 0-%d 秒之间", step.Key, workflowMaxRetryWait)
+		}
+
+		upstream := make(map[string]bool)
+		for _, key := range workflowUpstream(steps, step.Key) {
+			upstream[key] = true
+		}
+
+		for _, input := range step.Inputs {
+			if input == nil || input.Field == "" {
+				addf("步骤 %s 的输入映射缺少目标字段", step.Key)
+				continue
+			}
+			targetField := findWorkflowField(target.request, input.Field)
+			if targetField == nil && len(target.request) > 0 && step.Callback == "" {
+				addf("步骤 %s 的函数没有请求字段 %s", step.Key, input.Field)
+				continue
+			}
+			targetType := fieldDataType(targetField)
+
+			if input.From == "" {
+				if input.Value != nil && !matchConfigDataType(targetType, input.Value) {
+					addf("步骤 %s 的字段 %s 固定值类型不匹配，需要 %s", step.Key, input.Field, targetType)
+				}
+				continue
+			}
+			sourceKey, sourcePath, ok := splitWorkflowRef(input.From)
+			if !ok {
+				addf("步骤 %s 的字段 %s 数据来源格式错误: %s", step.Key, input.Field, input.From)
+				continue
+			}
+			if sourceKey == workflowTriggerRef {
+				continue
+			}
+			if !upstream[sourceKey] {
+				addf("步骤 %s 引用的 %s 不是它的上游步骤", step.Key, sourceKey)
+				continue
+			}
+			// 只对直接引用上游响应字段的映射做类型校验（嵌套路径和回调返回值在运行时才能确定）
+			source, ok := schemas[sourceKey]
+			if !ok || steps[sourceKey].Callback != "" || len(source.response) == 0 || strings.Contains(sourcePath, ".") {
+				continue
+			}
+			sourceField := findWorkflowField(source.response, sourcePath)
+			if sourceField == nil {
+				addf("步骤 %s 引用的 %s 没有响应字段 %s", step.Key, sourceKey, sourcePath)
+				continue
+			}
+			if !workflowTypeCompatible(fieldDataType(sourceField), targetType) {
+				addf("步骤 %s 的字段 %s 类型不兼容: %s(%s) -> %s(%s)",
+					step.Key, input.Field, input.From, fieldDataType(sourceField), input.Field, targetType)
+			}
+		}
+
+		for _, condition := range step.Conditions {
+			if condition == nil {
+				continue
+			}
+			if !workflowConditionOps[condition.Op] {
+				addf("步骤 %s 的条件运算符不支持: %s", step.Key, condition.Op)
+			}
+			sourceKey, _, ok := splitWorkflowRef(condition.From)
+			if !ok {
+				addf("步骤 %s 的条件数据来源格式错误: %s", step.Key, condition.From)
+			} else if sourceKey != workflowTriggerRef && !upstream[sourceKey] {
+				addf("步骤 %s 的条件引用的 %s 不是它的上游步骤", step.Key, sourceKey)
+			}
+		}
+	}
+
+	// 4. 触发器
+	for i, trigger := range triggers {
+		if trigger == nil {
+			continue
+		}
+		switch trigger.Type {
+		case dto.WorkflowTriggerManual:
+		case dto.WorkflowTriggerSchedule:
+			if _, err := parseCron(trigger.Cron); err != nil {
+				addf("第 %d 个触发器: %s", i+1, err.Error())
+			}
+		case dto.WorkflowTriggerTableEvent:
+			fn, err := s.functionRepo.GetFunctionByFullCodePath(trigger.FullCodePath)
+			if err != nil {
+				addf("第 %d 个触发器的函数 %s 不存在", i+1, trigger.FullCodePath)
+			} else if fn.TemplateType != workflowTemplateTable {
+				addf("第 %d 个触发器的函数 %s 不是 Table 函数", i+1, trigger.FullCodePath)
+			} else if err := checkWorkflowPermission(ctx, username, trigger.FullCodePath, permission.FunctionRead); err != nil {
+				addf("第 %d 个触发器: %s", i+1, err.Error())
+			}
+			for _, event := range trigger.Events {
+				if !workflowTableEvents[event] {
+					addf("第 %d 个触发器不支持的事件: %s", i+1, event)
+				}
+			}
+		default:
+			addf("第 %d 个触发器类型不支持: %s", i+1, trigger.Type)
+		}
+	}
+
+	return problems
+}
+
+// checkWorkflowStepPermission 检查运行身份是否有权限调用步骤的函数
+// 工作流直接通过 AppService.RequestApp 调用函数，不经过权限中间件，所以在保存和运行时都需要检查
+func checkWorkflowStepPermission(ctx context.Context, username string, step *dto.WorkflowStep, fn *model.Function) error {
+	return checkWorkflowPermission(ctx, username, step.FullCodePath, workflowStepAction(step, fn))
+}
+
+// checkWorkflowPermission 检查用户对函数的权限（社区版不做权限控制）
+func checkWorkflowPermission(ctx context.Context, username, fullCodePath, action string) error {
+	if !license.GetManager().HasFeature(enterprise.FeaturePermission) {
+		return nil
+	}
+	if username == "" {
+		return fmt.Errorf("缺少运行身份，无法校验函数 %s 的权限", fullCodePath)
+	}
+	ok, err := permission.CheckPermissionWithInheritance(ctx, enterprise.GetPermissionService(), username, fullCodePath, action)
+	if err != nil {
+		return fmt.Errorf("校验函数 %s 的权限失败: %w", fullCodePath, err)
+	}
+	if !ok {
+		return fmt.Errorf("%s 没有函数 %s 的 %s 权限", username, fullCodePath, action)
+	}
+	return nil
+}
+
+// workflowStepAction 步骤调用函数所需的权限点（与 HTTP 调用时权限中间件的判定保持一致）
+func workflowStepAction(step *dto.WorkflowStep, fn *model.Function) string {
+	switch step.Callback {
+	case "OnTableAddRow":
+		return permission.FunctionWrite
+	case "OnTableUpdateRow":
+		return permission.FunctionUpdate
+	case "OnTableDeleteRows":
+		return permission.FunctionDelete
+	}
+	switch strings.ToUpper(fn.Method) {
+	case "GET":
+		return permission.FunctionRead
+	case "PUT", "PATCH":
+		return permission.FunctionUpdate
+	case "DELETE":
+		return permission.FunctionDelete
+	default:
+		return permission.FunctionWrite
+	}
+}
+
+// loadStepSchemas 加载每个步骤调用的函数及字段（函数不存在的步骤不在结果中）
+func (s *WorkflowService) loadStepSchemas(steps []*dto.WorkflowStep) map[string]*workflowStepSchema {
+	schemas := make(map[string]*workflowStepSchema, len(steps))
+	for _, step := range steps {
+		if step == nil || step.FullCodePath == "" {
+			continue
+		}
+		fn, err := s.functionRepo.GetFunctionByFullCodePath(step.FullCodePath)
+		if err != nil {
+			continue
+		}
+		schema := &workflowStepSchema{function: fn}
+		if len(fn.Request) > 0 {
+			_ = json.Unmarshal(fn.Request, &schema.request)
+		}
+		if len(fn.Response) > 0 {
+			_ = json.Unmarshal(fn.Response, &schema.response)
+		}
+		schemas[step.Key] = schema
+	}
+	return schemas
+}
+
+// workflowTopoOrder 按依赖关系排序步骤（同一层级保持定义顺序），存在循环依赖时返回错误
+func workflowTopoOrder(steps []*dto.WorkflowStep) ([]*dto.WorkflowStep, error) {
+	indegree := make(map[string]int, len(steps))
+	dependents := make(map[string][]string, len(steps))
+	for _, step := range steps {
+		indegree[step.Key] += 0
+		for _, dep := range step.DependsOn {
+			indegree[step.Key]++
+			dependents[dep] = append(dependents[dep], step.Key)
+		}
+	}
+
+	order := make([]*dto.WorkflowStep, 0, len(steps))
+	done := make(map[string]bool, len(steps))
+	for len(order) < len(steps) {
+		progressed := false
+		for _, step := range steps {
+			if done[step.Key] || indegree[step.Key] > 0 {
+				continue
+			}
+			done[step.Key] = true
+			order = append(order, step)
+			for _, next := range dependents[step.Key] {
+				indegree[next]--
+			}
+			progressed = true
+		}
+		if !progressed {
+			var cyclic []string
+			for _, step := range steps {
+				if !done[step.Key] {
+					cyclic = append(cyclic, step.Key)
+				}
+			}
+			return nil, fmt.Errorf("步骤之间存在循环依赖: %s", strings.Join(cyclic, ", "))
+		}
+	}
+	return order, nil
+}
+
+// workflowUpstream 获取步骤的所有上游步骤（由近及远）
+func workflowUpstream(steps map[string]*dto.WorkflowStep, key string) []string {
+	var result []string
+	seen := map[string]bool{key: true}
+	queue := []string{key}
+	for len(queue) > 0 {
+		current := steps[queue[0]]
+		queue = queue[1:]
+		if current == nil {
+			continue
+		}
+		for _, dep := range current.DependsOn {
+			if seen[dep] {
+				continue
+			}
+			seen[dep] = true
+			result = append(result, dep)
+			queue = append(queue, dep)
+		}
+	}
+	return result
+}
+
+// splitWorkflowRef 拆分数据来源引用：create_customer.id -> (create_customer, id)
+func splitWorkflowRef(ref string) (string, string, bool) {
+	parts := strings.SplitN(ref, ".", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", false
+	}
+	return parts[0], parts[1], true
+}
+
+func findWorkflowField(fields []*widget.Field, code string) *widget.Field {
+	for _, field := range fields {
+		if field != nil && field.Code == code {
+			return field
+		}
+	}
+	return nil
+}
+
+func fieldDataType(field *widget.Field) string {
+	if field == nil || field.Data == nil {
+		return ""
+	}
+	return field.Data.Type
+}
+
+// workflowTypeCompatible 判断上游输出类型能否赋值给下游输入类型（类型未知时不做限制）
+func workflowTypeCompatible(from, to string) bool {
+	if from == "" || to == "" || from == to {
+		return true
+	}
+	switch to {
+	case widget.DataTypeFloat:
+		return from == widget.DataTypeInt
+	case widget.DataTypeFloats:
+		return from == widget.DataTypeInts
+	case widget.DataTypeString:
+		return from == widget.DataTypeInt || from == widget.DataTypeFloat || from == widget.DataTypeBool
+	}
+	return false
+}
@@ -24,16 +24,21 @@ type AgentInfo struct {
 	Timeout         int                `json:"timeout" example:"30"`
 	PluginID        *int64             `json:"plugin_id" example:"1"`                                        // 插件ID（仅 plugin 类型需要）
 	Plugin          *PluginInfo        `json:"plugin,omitempty"`                                            // 预加载的插件信息
+	Pipeline        []AgentPipelineStep `json:"pipeline"`                                                   // 流水线（配置后替代单个插件调用）
 	KnowledgeBaseID     int64              `json:"knowledge_base_id" example:"1"`
 	KnowledgeBase       *KnowledgeBaseInfo `json:"knowledge_base,omitempty"`  // 预加载的知识库信息
 	LLMConfigID         int64              `json:"llm_config_id" example:"1"` // LLM配置ID，如果为0则使用默认LLM
 	LLMConfig           *LLMConfigInfo     `json:"llm_config,omitempty"`      // 预加载的LLM配置信息
+	LLMRouting          *AgentLLMRouting   `json:"llm_routing,omitempty"`     // LLM 路由策略（备用 LLM、选择策略、重试规则）
+	Memory              *AgentMemory       `json:"memory,omitempty"`          // 会话记忆策略（长会话的历史消息压缩）
 	SystemPromptTemplate string            `json:"system_prompt_template" example:"你是一个专业的代码生成助手。以下是相关的知识库内容，请参考这些内容来生成代码：\n{knowledge}"` // System Prompt模板，支持{knowledge}变量
 	Metadata            string             `json:"metadata" example:"{}"`
 	Logo                string             `json:"logo,omitempty" example:"https://example.com/logo.png"` // 智能体 Logo URL（可选）
 	Greeting            string             `json:"greeting,omitempty" example:"欢迎使用本智能体！"` // 开场白内容（可选）
 	GreetingType        string             `json:"greeting_type,omitempty" example:"md"` // 开场白格式类型：text, md, html
 	GenerationCount     int64              `json:"generation_count" example:"100"` // 生成次数统计
+	HubAgentID          int64              `json:"hub_agent_id" example:"0"` // 关联的Hub智能体ID（0 表示未发布）
+	HubVersion          string             `json:"hub_version,omitempty" example:"v1"` // 最近发布到 Hub 的版本
 	Visibility          int                `json:"visibility" example:"0"` // 0: 公开, 1: 私有
 	Admin               string             `json:"admin" example:"user1,user2"` // 管理员列表（逗号分隔）
 	IsAdmin             bool               `json:"is_admin" example:"true"` // 当前用户是否是管理员（前端计算或后端返回）
@@ -84,8 +89,11 @@ type AgentCreateReq struct {
 	Description     string `json:"description" example:"基于Excel文件生成管理系统"`
 	Timeout         int    `json:"timeout" example:"30"`
 	PluginID        *int64 `json:"plugin_id" example:"1"` // 插件ID（仅 plugin 类型需要）
+	Pipeline        []AgentPipelineStep `json:"pipeline"` // 流水线（配置后替代单个插件调用）
 	KnowledgeBaseID     int64  `json:"knowledge_base_id" binding:"required" example:"1"`
 	LLMConfigID         int64  `json:"llm_config_id" example:"1"` // LLM配置ID，如果为0则使用默认LLM
+	LLMRouting          *AgentLLMRouting `json:"llm_routing"` // LLM 路由策略（可选，为空表示只调用主 LLM）
+	Memory              *AgentMemory     `json:"memory"`      // 会话记忆策略（可选，为空表示发送全部历史消息）
 	SystemPromptTemplate string `json:"system_prompt_template" example:"你是一个专业的代码生成助手。以下是相关的知识库内容，请参考这些内容来生成代码：\n{knowledge}"` // System Prompt模板，支持{knowledge}变量
 	Metadata            string `json:"metadata" example:"{}"`
 	Logo                string `json:"logo" example:"https://example.com/logo.png"` // 智能体 Logo URL（可选）
@@ -110,8 +118,11 @@ type AgentUpdateReq struct {
 	Description     string `json:"description" example:"基于Excel文件生成管理系统"`
 	Timeout         int    `json:"timeout" example:"30"`
 	PluginID        *int64 `json:"plugin_id" example:"1"` // 插件ID（仅 plugin 类型需要）
+	Pipeline        []AgentPipelineStep `json:"pipeline"` // 流水线（配置后替代单个插件调用）
 	KnowledgeBaseID     int64  `json:"knowledge_base_id" binding:"required" example:"1"`
 	LLMConfigID         int64  `json:"llm_config_id" example:"1"` // LLM配置ID，如果为0则使用默认LLM
+	LLMRouting          *AgentLLMRouting `json:"llm_routing"` // LLM 路由策略（可选，为空表示只调用主 LLM）
+	Memory              *AgentMemory     `json:"memory"`      // 会话记忆策略（可选，为空表示发送全部历史消息）
 	SystemPromptTemplate string `json:"system_prompt_template" example:"你是一个专业的代码生成助手。以下是相关的知识库内容，请参考这些内容来生成代码：\n{knowledge}"` // System Prompt模板，支持{knowledge}变量
 	Metadata            string `json:"metadata" example:"{}"`
 	Logo                string `json:"logo" example:"https://example.com/logo.png"` // 智能体 Logo URL（可选）
@@ -140,3 +151,93 @@ type AgentEnableReq struct {
 type AgentDisableReq struct {
 	ID int64 `json:"id" binding:"required" example:"1"`
 }
+
+// 智能体流水线步骤类型
+const (
+	AgentPipelineStepPlugin       = "plugin"        // 调用插件
+	AgentPipelineStepKnowledge    = "knowledge"     // 知识库检索（把与输入相关的文档追加到输出）
+	AgentPipelineStepLLM          = "llm"           // 调用 LLM（如根据 Excel 内容推断表结构）
+	AgentPipelineStepCompileCheck = "compile_check" // Go 代码语法检查（一般放在生成之后）
+)
+
+// 智能体流水线步骤阶段
+const (
+	AgentPipelineStageBefore = "before" // 生成代码之前（默认），输出作为生成代码的用户消息
+	AgentPipelineStageAfter  = "after"  // 生成代码之后，输入为生成的代码，输出替换生成的代码
+)
+
+// 智能体流水线步骤执行条件
+const (
+	AgentPipelineWhenHasFiles    = "has_files"    // 用户上传了文件
+	AgentPipelineWhenNoFiles     = "no_files"     // 用户没有上传文件
+	AgentPipelineWhenContains    = "contains"     // 上一步输出包含 Value
+	AgentPipelineWhenNotContains = "not_contains" // 上一步输出不包含 Value
+	AgentPipelineWhenNotEmpty    = "not_empty"    // 上一步输出不为空
+)
+
+// 流水线步骤执行状态
+const (
+	AgentPipelineStatusSucceeded = "succeeded"
+	AgentPipelineStatusFailed    = "failed"
+	AgentPipelineStatusSkipped   = "skipped"
+)
+
+// AgentPipelineStep 智能体流水线步骤
+// 步骤按顺序执行，上一步的输出作为下一步 PluginRunReq.Message，文件始终是用户上传的文件
+type AgentPipelineStep struct {
+	Name            string `json:"name" example:"解析Excel"`
+	Type            string `json:"type" example:"plugin"`                   // plugin/knowledge/llm/compile_check
+	Stage           string `json:"stage,omitempty" example:"before"`        // before/after，默认 before
+	PluginID        int64  `json:"plugin_id,omitempty" example:"1"`         // plugin 步骤：插件ID
+	LLMConfigID     int64  `json:"llm_config_id,omitempty" example:"0"`     // llm 步骤：LLM配置ID，0 表示使用智能体的 LLM
+	Prompt          string `json:"prompt,omitempty" example:"根据以下数据推断表结构"`  // llm 步骤：系统提示词
+	KnowledgeBaseID int64  `json:"knowledge_base_id,omitempty" example:"0"` // knowledge 步骤：知识库ID，0 表示使用智能体的知识库
+	TopK            int    `json:"top_k,omitempty" example:"3"`             // knowledge 步骤：最多返回的文档数，默认 3
+	TimeoutSeconds  int    `json:"timeout_seconds,omitempty" example:"60"`  // 单次执行超时（秒），默认 600
+	MaxRetries      int    `json:"max_retries,omitempty" example:"1"`       // 失败重试次数
+	When            string `json:"when,omitempty" example:"has_files"`      // 执行条件，为空表示总是执行，不满足时跳过（输出沿用上一步）
+	Value           string `json:"value,omitempty" example:""`              // 执行条件的参数（contains/not_contains）
+	ContinueOnError bool   `json:"continue_on_error,omitempty"`             // 失败后继续执行（输出沿用上一步）
+}
+
+// AgentPipelineStepLog 流水线步骤执行记录
+type AgentPipelineStepLog struct {
+	Name       string `json:"name" example:"解析Excel"`
+	Type       string `json:"type" example:"plugin"`
+	Stage      string `json:"stage" example:"before"`
+	Status     string `json:"status" example:"succeeded"` // succeeded/failed/skipped
+	Input      string `json:"input" example:"生成一个工单系统"`
+	Output     string `json:"output" example:"工单标题,问题描述,优先级"`
+	Error      string `json:"error,omitempty" example:""`
+	Attempts   int    `json:"attempts" example:"1"`
+	DurationMs int64  `json:"duration_ms" example:"1200"`
+	StartedAt  string `json:"started_at" example:"2024-01-01 00:00:00"`
+}
+
+// 智能体 LLM 路由策略
+const (
+	AgentLLMRoutingFailover = "failover" // 按顺序：主 LLM 失败后依次尝试备用 LLM（默认）
+	AgentLLMRoutingLatency  = "latency"  // 延迟优先：优先调用近期平均延迟最低的 LLM
+	AgentLLMRoutingCost     = "cost"     // 成本优先：优先调用成本最低的 LLM（LLM 配置的 cost 字段）
+)
+
+// AgentLLMRouting 智能体的 LLM 路由策略
+// 候选 LLM 为主 LLM（智能体绑定的 LLM，未绑定时为默认 LLM）加上备用 LLM，候选之间应该是能力相当的模型。
+// 每个 LLM 配置有独立的熔断器，连续失败后一段时间内会被跳过
+type AgentLLMRouting struct {
+	Strategy             string   `json:"strategy" example:"failover"`                          // failover/latency/cost，默认 failover
+	FallbackLLMConfigIDs []int64  `json:"fallback_llm_config_ids" example:"2,3"`                // 备用 LLM 配置ID（按优先级排列）
+	RetryOn              []string `json:"retry_on,omitempty" example:"rate_limit,server_error"` // 在同一个 LLM 上重试的错误类型：timeout/rate_limit/server_error/network/auth/invalid_request/other，为空时重试 timeout/rate_limit/server_error/network
+	MaxRetries           int      `json:"max_retries" example:"1"`                              // 同一个 LLM 的最大重试次数，用完后切换到下一个 LLM
+	BackoffMs            int      `json:"backoff_ms" example:"1000"`                            // 首次重试前的等待时间（毫秒），之后每次翻倍，默认 1000
+}
+
+// AgentMemory 智能体的会话记忆策略
+// 历史消息超过 token 预算时，较早的对话在本轮生成完成后被总结为会话摘要（滚动更新），只保留最近几轮原文；
+// 每个文件最新生成的代码始终保留在上下文中
+type AgentMemory struct {
+	Enabled            bool  `json:"enabled" example:"true"`             // 是否启用（未启用时发送全部历史消息）
+	MaxHistoryTokens   int   `json:"max_history_tokens" example:"16000"` // 历史消息（包含摘要和代码）的 token 预算，默认 16000
+	KeepRecentTurns    int   `json:"keep_recent_turns" example:"4"`      // 保留原文的最近轮数，默认 4
+	SummaryLLMConfigID int64 `json:"summary_llm_config_id" example:"0"`  // 生成摘要使用的 LLM 配置ID（0 表示使用智能体的 LLM）
+}
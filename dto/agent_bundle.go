@@ -0,0 +1,157 @@
+package dto
+
+// 智能体导出包格式
+// 导出包是一个 zip 文件：manifest.json（清单）、prompt.md（System Prompt 模板）
+// 和 knowledge/ 目录（知识库文档，按文档树组织为 markdown 文件，有子文档的文档对应同名目录）
+const (
+	AgentBundleFormat       = "ai-agent-os.agent-bundle"
+	AgentBundleVersion      = 1 // 导出包格式版本，格式不兼容时递增
+	AgentBundleManifestFile = "manifest.json"
+	AgentBundlePromptFile   = "prompt.md"
+	AgentBundleKnowledgeDir = "knowledge"
+)
+
+// 导入时插件、知识库冲突的处理方式
+const (
+	AgentImportModeMerge     = "merge"     // 合并（默认）：复用已有插件，已有知识库只补充缺少的文档
+	AgentImportModeOverwrite = "overwrite" // 覆盖：用导出包覆盖已有插件的元数据（保留密钥配置）和已有知识库的全部文档
+)
+
+// 导入冲突类型
+const (
+	AgentImportConflictPlugin        = "plugin"         // 插件 Code 已存在
+	AgentImportConflictKnowledgeBase = "knowledge_base" // 知识库名称已存在
+)
+
+// 导入冲突的处理结果
+const (
+	AgentImportActionReuse     = "reuse"     // 直接复用已有资源
+	AgentImportActionMerge     = "merge"     // 合并到已有资源
+	AgentImportActionOverwrite = "overwrite" // 覆盖已有资源
+)
+
+// AgentBundleManifest 智能体导出包清单（manifest.json）
+// 清单中的 ID 都是导出环境的 ID，导入时按插件 Code、知识库名称和 LLM 提供商/模型重新映射
+type AgentBundleManifest struct {
+	Format         string                     `json:"format" example:"ai-agent-os.agent-bundle"`
+	Version        int                        `json:"version" example:"1"`
+	ExportedAt     string                     `json:"exported_at" example:"2024-01-01 00:00:00"`
+	ExportedBy     string                     `json:"exported_by" example:"user1"`
+	Agent          AgentBundleAgent           `json:"agent"`
+	KnowledgeBases []AgentBundleKnowledgeBase `json:"knowledge_bases"` // 智能体和流水线引用的知识库
+	Plugins        []AgentBundlePlugin        `json:"plugins"`         // 智能体和流水线引用的插件（只有元数据，不包含密钥）
+	LLMConfigs     []AgentBundleLLMConfig     `json:"llm_configs"`     // 引用的 LLM 配置（不包含 API Key，导入时匹配已有配置）
+}
+
+// AgentBundleAgent 导出包中的智能体定义
+type AgentBundleAgent struct {
+	ID              int64               `json:"id" example:"1"`
+	Name            string              `json:"name" example:"Excel 转表单"`
+	AgentType       string              `json:"agent_type" example:"plugin"`
+	ChatType        string              `json:"chat_type" example:"function_gen"`
+	Description     string              `json:"description"`
+	Author          string              `json:"author"`
+	Timeout         int                 `json:"timeout" example:"30"`
+	PluginID        int64               `json:"plugin_id,omitempty" example:"1"`
+	KnowledgeBaseID int64               `json:"knowledge_base_id" example:"1"`
+	LLMConfigID     int64               `json:"llm_config_id" example:"1"` // 0 表示使用默认 LLM
+	Pipeline        []AgentPipelineStep `json:"pipeline,omitempty"`
+	LLMRouting      *AgentLLMRouting    `json:"llm_routing,omitempty"`
+	Memory          *AgentMemory        `json:"memory,omitempty"`
+	PromptFile      string              `json:"prompt_file,omitempty" example:"prompt.md"` // System Prompt 模板文件，为空表示使用默认模板
+	Metadata        string              `json:"metadata,omitempty" example:"{}"`
+	Logo            string              `json:"logo,omitempty"`
+	Greeting        string              `json:"greeting,omitempty"`
+	GreetingType    string              `json:"greeting_type,omitempty" example:"md"`
+	Visibility      int                 `json:"visibility" example:"0"`
+}
+
+// AgentBundleKnowledgeBase 导出包中的知识库
+type AgentBundleKnowledgeBase struct {
+	ID          int64                 `json:"id" example:"1"`
+	Name        string                `json:"name" example:"表单组件文档"`
+	Description string                `json:"description"`
+	Dir         string                `json:"dir" example:"knowledge/表单组件文档"` // 文档所在目录
+	Documents   []AgentBundleDocument `json:"documents"`                      // 父文档总是排在子文档之前
+}
+
+// AgentBundleDocument 导出包中的知识库文档
+type AgentBundleDocument struct {
+	ID        int64  `json:"id" example:"2"`
+	ParentID  int64  `json:"parent_id" example:"1"` // 0 表示根目录
+	Title     string `json:"title" example:"输入框"`
+	File      string `json:"file" example:"knowledge/表单组件文档/基础组件/输入框.md"` // 相对导出包根目录的文件路径
+	FileType  string `json:"file_type" example:"md"`
+	SortOrder int    `json:"sort_order" example:"0"`
+}
+
+// AgentBundlePlugin 导出包中的插件元数据
+type AgentBundlePlugin struct {
+	ID          int64  `json:"id" example:"1"`
+	Code        string `json:"code" example:"excel_parser"`
+	Name        string `json:"name" example:"Excel 解析"`
+	Description string `json:"description"`
+	Author      string `json:"author"`
+	Config      string `json:"config,omitempty" example:"{\"timeout\":30}"` // 插件配置（已移除密钥、令牌、密码等字段）
+}
+
+// AgentBundleLLMConfig 导出包中引用的 LLM 配置
+type AgentBundleLLMConfig struct {
+	ID       int64  `json:"id" example:"1"`
+	Name     string `json:"name" example:"DeepSeek"`
+	Provider string `json:"provider" example:"deepseek"`
+	Model    string `json:"model" example:"deepseek-chat"`
+}
+
+// AgentExportReq 导出智能体请求
+type AgentExportReq struct {
+	ID int64 `json:"id" form:"id" binding:"required" example:"1"`
+}
+
+// AgentImportReq 导入智能体请求（multipart 表单，导出包通过 file 字段上传）
+type AgentImportReq struct {
+	Mode   string `form:"mode" example:"merge"`     // merge/overwrite，默认 merge
+	Name   string `form:"name" example:"Excel 转表单"` // 导入后的智能体名称（可选，默认使用导出包中的名称）
+	DryRun bool   `form:"dry_run" example:"false"`  // 只检查冲突和映射关系，不写入数据
+}
+
+// AgentImportConflict 导入冲突
+type AgentImportConflict struct {
+	Type       string `json:"type" example:"plugin"`        // plugin/knowledge_base
+	Key        string `json:"key" example:"excel_parser"`   // 插件 Code 或知识库名称
+	ExistingID int64  `json:"existing_id" example:"3"`      // 已有资源的ID
+	Action     string `json:"action" example:"reuse"`       // reuse/merge/overwrite
+	Message    string `json:"message,omitempty" example:""` // 补充说明
+}
+
+// AgentImportResp 导入智能体响应
+type AgentImportResp struct {
+	AgentID          int64                 `json:"agent_id" example:"10"` // 导入后的智能体ID（dry_run 时为 0）
+	Mode             string                `json:"mode" example:"merge"`
+	DryRun           bool                  `json:"dry_run" example:"false"`
+	Conflicts        []AgentImportConflict `json:"conflicts"`
+	PluginIDs        map[int64]int64       `json:"plugin_ids"`                  // 导出包中的插件ID -> 导入后的插件ID（dry_run 时新建的插件为 0）
+	KnowledgeBaseIDs map[int64]int64       `json:"knowledge_base_ids"`          // 导出包中的知识库ID -> 导入后的知识库ID
+	LLMConfigIDs     map[int64]int64       `json:"llm_config_ids"`              // 导出包中的 LLM 配置ID -> 匹配到的 LLM 配置ID（0 表示使用默认 LLM）
+	DocumentCount    int                   `json:"document_count" example:"12"` // 写入的知识库文档数
+	Warnings         []string              `json:"warnings"`
+}
+
+// AgentPublishToHubReq 发布智能体到 Hub 请求
+// 首次发布创建 Hub 智能体（版本 v1），之后每次发布一个新版本
+type AgentPublishToHubReq struct {
+	ID          int64    `json:"id" binding:"required" example:"1"`
+	Name        string   `json:"name" example:"Excel 转表单"` // Hub 上的名称（可选，默认使用智能体名称）
+	Description string   `json:"description"`              // Hub 上的描述（可选，默认使用智能体描述）
+	Category    string   `json:"category" example:"代码生成"`
+	Tags        []string `json:"tags"`
+}
+
+// AgentPublishToHubResp 发布智能体到 Hub 响应
+type AgentPublishToHubResp struct {
+	HubAgentID  int64  `json:"hub_agent_id" example:"1"`
+	HubAgentURL string `json:"hub_agent_url"`
+	Version     string `json:"version" example:"v2"`
+	OldVersion  string `json:"old_version,omitempty" example:"v1"`
+	FileCount   int    `json:"file_count" example:"13"`
+}
@@ -138,12 +138,50 @@ type ChatMessageInfo struct {
 	Content   string `json:"content" example:"你好"`                     // 消息内容
 	Files     string `json:"files,omitempty" example:"[{\"url\":\"...\",\"remark\":\"...\"}]"` // 文件列表（JSON字符串，可选）
 	User      string `json:"user" example:"beiluo"`                    // 创建用户
+	LLMProvider string `json:"llm_provider,omitempty" example:"glm"`  // 实际调用的 LLM 提供商（仅 assistant 消息）
+	LLMModel    string `json:"llm_model,omitempty" example:"glm-4.6"` // 实际调用的 LLM 模型（仅 assistant 消息）
+	ParentID    int64   `json:"parent_id" example:"0"`                      // 父消息ID（0 表示第一条消息）
+	RecordID    int64   `json:"record_id,omitempty" example:"1"`            // 函数生成记录ID（仅 assistant 消息）
+	Siblings    []int64 `json:"siblings,omitempty" example:"[2,5]"`         // 同一位置的其他版本（包含自己，按创建时间升序；只有一个版本时为空）
+	Applied     bool    `json:"applied,omitempty" example:"true"`           // 该回答的代码是否是最近一次应用到工作空间的版本
 	CreatedAt string `json:"created_at" example:"2006-01-02T15:04:05Z"` // 创建时间
 }
 
 // ChatMessageListResp 获取消息列表响应
 type ChatMessageListResp struct {
-	Messages []ChatMessageInfo `json:"messages"` // 消息列表（按创建时间升序）
+	Messages         []ChatMessageInfo `json:"messages"`                              // 当前分支的消息列表（从第一条消息到分支的最后一条消息）
+	CurrentMessageID int64             `json:"current_message_id" example:"6"`        // 当前分支的最后一条消息ID
+	AppliedRecordID  int64             `json:"applied_record_id,omitempty" example:"3"` // 最近一次应用到工作空间的生成记录ID
+	AppliedMessageID int64             `json:"applied_message_id,omitempty" example:"5"` // 最近一次应用到工作空间的 assistant 消息ID（可能不在当前分支上）
+}
+
+// FunctionGenRegenerateReq 重新生成回答请求（新的回答与原回答并列，成为当前分支）
+type FunctionGenRegenerateReq struct {
+	SessionID     string   `json:"session_id" binding:"required" example:"550e8400-e29b-41d4-a716-446655440000"` // 会话ID
+	MessageID     int64    `json:"message_id" binding:"required" example:"2"`                                    // 要重新生成的 assistant 消息ID
+	Package       string   `json:"package" example:"crm"`                                                        // Package 名称
+	ExistingFiles []string `json:"existing_files" example:"[\"crm_ticket\"]"`                                   // 当前 package 下已存在的文件名（不含 .go 后缀）
+}
+
+// FunctionGenEditReq 编辑用户消息后重新发送请求（新的用户消息与原消息并列，开始一个新分支）
+type FunctionGenEditReq struct {
+	SessionID     string   `json:"session_id" binding:"required" example:"550e8400-e29b-41d4-a716-446655440000"` // 会话ID
+	MessageID     int64    `json:"message_id" binding:"required" example:"1"`                                    // 要编辑的用户消息ID
+	Package       string   `json:"package" example:"crm"`                                                        // Package 名称
+	ExistingFiles []string `json:"existing_files" example:"[\"crm_ticket\"]"`                                   // 当前 package 下已存在的文件名（不含 .go 后缀）
+	Message       Message  `json:"message" binding:"required"`                                                   // 编辑后的消息
+}
+
+// ChatBranchSwitchReq 切换分支请求
+type ChatBranchSwitchReq struct {
+	SessionID string `json:"session_id" binding:"required" example:"550e8400-e29b-41d4-a716-446655440000"` // 会话ID
+	MessageID int64  `json:"message_id" binding:"required" example:"5"`                                    // 切换到的消息ID（当前分支变为该消息下最新的分支）
+}
+
+// FunctionGenApplyReq 把某个分支的代码应用到工作空间请求
+type FunctionGenApplyReq struct {
+	SessionID string `json:"session_id" binding:"required" example:"550e8400-e29b-41d4-a716-446655440000"` // 会话ID
+	MessageID int64  `json:"message_id" binding:"required" example:"5"`                                    // assistant 消息ID（应用它生成的代码）
 }
 
 // FunctionGenCallback 函数生成回调（app-server -> agent-server）
@@ -172,4 +210,39 @@ type FunctionGenStatusResp struct {
 	Duration       int      `json:"duration" example:"30"`                              // 生成耗时（秒）
 	CreatedAt      string   `json:"created_at" example:"2006-01-02T15:04:05Z"`           // 创建时间
 	UpdatedAt      string   `json:"updated_at" example:"2006-01-02T15:04:05Z"`         // 更新时间
+	PipelineSteps  []AgentPipelineStepLog `json:"pipeline_steps,omitempty"` // 流水线各步骤的输入、输出和耗时
+	LLMConfigID    int64                  `json:"llm_config_id,omitempty" example:"1"`   // 实际调用的 LLM 配置ID
+	LLMProvider    string                 `json:"llm_provider,omitempty" example:"glm"`  // 实际调用的 LLM 提供商
+	LLMModel       string                 `json:"llm_model,omitempty" example:"glm-4.6"` // 实际调用的 LLM 模型
+}
+
+// 函数生成流式事件类型（SSE event 字段）
+const (
+	FunctionGenEventStatus     = "status"      // 生成状态变化（记录创建、开始调用 LLM 等）
+	FunctionGenEventPluginStep = "plugin_step" // 插件/流水线步骤执行完成
+	FunctionGenEventThinking   = "thinking"    // 思考过程片段
+	FunctionGenEventContent    = "content"     // 回答内容片段
+	FunctionGenEventCode       = "code"        // 提取出的代码
+	FunctionGenEventResult     = "result"      // app-server 更新工作空间的回调结果（结束事件）
+	FunctionGenEventError      = "error"       // 生成失败（结束事件）
+)
+
+// FunctionGenStreamReq 订阅函数生成流式事件请求
+type FunctionGenStreamReq struct {
+	SessionID   string `json:"session_id" form:"session_id" binding:"required" example:"550e8400-e29b-41d4-a716-446655440000"` // 会话ID
+	LastEventID string `json:"last_event_id" form:"last_event_id" example:"1700000000000000000"`                               // 断线重连时最后收到的事件ID（也可以通过 Last-Event-ID 请求头传递）
+}
+
+// FunctionGenStreamEvent 函数生成流式事件
+type FunctionGenStreamEvent struct {
+	ID        string                `json:"id"`                  // 事件ID（同一会话内递增，用于断线重连）
+	Type      string                `json:"type"`                // 事件类型
+	SessionID string                `json:"session_id"`          // 会话ID
+	RecordID  int64                 `json:"record_id,omitempty"` // 生成记录ID（记录创建之前的插件步骤事件为空）
+	Status    string                `json:"status,omitempty"`    // 生成状态：generating/completed/failed
+	Delta     string                `json:"delta,omitempty"`     // thinking/content 事件的增量文本
+	Step      *AgentPipelineStepLog `json:"step,omitempty"`      // plugin_step 事件的步骤记录
+	Code      string                `json:"code,omitempty"`      // code 事件提取出的代码
+	Result    *FunctionGenCallback  `json:"result,omitempty"`    // result 事件的回调结果
+	Error     string                `json:"error,omitempty"`     // error 事件的错误信息
 }
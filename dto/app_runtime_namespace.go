@@ -1,6 +1,7 @@
 package dto
 
 import (
+	"encoding/json"
 	"fmt"
 	"github.com/ai-agent-os/ai-agent-os/sdk/agent-app/widget"
 	"strings"
@@ -212,6 +213,11 @@ type ApiInfo struct {
 	FullCodePath   string          `json:"full_code_path"`
 	TreeID         int64           `json:"tree_id"` // ServiceTree节点ID，创建tree后赋值，方便后续写快照时入库
 
+	Config        []*widget.Field `json:"config,omitempty"`         // 运行时配置结构
+	ConfigDefault json.RawMessage `json:"config_default,omitempty"` // 运行时配置默认值
+
+	TemplateConfig json.RawMessage `json:"template_config,omitempty"` // 模板配置（看板的分列字段、日历的时间字段等）
+
 	SourceCodeFilePath string        `json:"source_code_file_path"`
 	SourceCode         string        `json:"source_code"`
 	CreateTableModels  []interface{} `json:"-"`
@@ -0,0 +1,43 @@
+package dto
+
+// AppSecretInfo 应用密钥信息
+// 注意：密钥值永远不会以明文返回，只返回元数据
+type AppSecretInfo struct {
+	ID          int64  `json:"id" example:"1"`
+	Name        string `json:"name" example:"OPENAI_API_KEY"`            // 密钥名称（SDK 通过 ctx.Secret("OPENAI_API_KEY") 读取）
+	Description string `json:"description" example:"OpenAI 接口密钥"`        // 描述
+	Value       string `json:"value" example:"******"`                   // 固定返回 ******，不回显明文
+	Version     int    `json:"version" example:"2"`                      // 版本号（每次轮换 +1）
+	UpdatedBy   string `json:"updated_by" example:"beiluo"`              // 最近一次修改人
+	UpdatedAt   string `json:"updated_at" example:"2024-01-01 00:00:00"` // 最近一次修改时间
+	CreatedAt   string `json:"created_at" example:"2024-01-01 00:00:00"` // 创建时间
+}
+
+// AppSecretListResp 获取应用密钥列表响应
+type AppSecretListResp struct {
+	Secrets []AppSecretInfo `json:"secrets"`
+}
+
+// SetAppSecretReq 设置应用密钥请求（不存在则创建，存在则轮换）
+type SetAppSecretReq struct {
+	Name        string `json:"name" binding:"required" example:"OPENAI_API_KEY"` // 密钥名称（字母、数字、下划线，且不能以数字开头）
+	Value       string `json:"value" binding:"required" example:"sk-xxx"`        // 密钥值（加密存储）
+	Description string `json:"description" example:"OpenAI 接口密钥"`                // 描述
+}
+
+// DeleteAppSecretReq 删除应用密钥请求
+type DeleteAppSecretReq struct {
+	Name string `json:"name" form:"name" binding:"required" example:"OPENAI_API_KEY"` // 密钥名称
+}
+
+// GetAppSecretsRuntimeReq 获取应用密钥明文请求（app-runtime/SDK App -> app-server，NATS 内部调用）
+type GetAppSecretsRuntimeReq struct {
+	User  string `json:"user" example:"beiluo"` // 应用所有者
+	App   string `json:"app" example:"demo"`    // 应用名
+	Token string `json:"token"`                 // 应用密钥访问令牌（app-runtime 签发，绑定 User/App）
+}
+
+// GetAppSecretsRuntimeResp 获取应用密钥明文响应
+type GetAppSecretsRuntimeResp struct {
+	Secrets map[string]string `json:"secrets"` // 密钥名称 -> 明文值
+}
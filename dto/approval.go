@@ -0,0 +1,164 @@
+package dto
+
+import "encoding/json"
+
+// 审批模式（节点内多个审批人如何处理）
+const (
+	ApprovalModeSequential = "sequential" // 依次审批：按顺序逐个审批，全部通过后节点通过
+	ApprovalModeParallel   = "parallel"   // 会签：同时审批，全部通过后节点通过
+	ApprovalModeAnyOf      = "any_of"     // 或签：同时审批，任意一人通过即节点通过
+)
+
+// 审批超时处理方式
+const (
+	ApprovalTimeoutEscalate = "escalate" // 升级：转交给升级审批人（默认为审批人的 Leader）
+	ApprovalTimeoutApprove  = "approve"  // 自动通过
+	ApprovalTimeoutReject   = "reject"   // 自动拒绝
+)
+
+// 审批实例状态
+const (
+	ApprovalStatusPending  = "pending"  // 审批中
+	ApprovalStatusApproved = "approved" // 已通过
+	ApprovalStatusRejected = "rejected" // 已拒绝
+	ApprovalStatusCanceled = "canceled" // 已撤回
+)
+
+// 审批操作
+const (
+	ApprovalActionApprove  = "approve"  // 通过
+	ApprovalActionReject   = "reject"   // 拒绝
+	ApprovalActionTransfer = "transfer" // 转交
+	ApprovalActionCancel   = "cancel"   // 撤回（发起人）
+	ApprovalActionStart    = "start"    // 发起
+	ApprovalActionEscalate = "escalate" // 超时升级
+)
+
+// ApprovalNode 审批节点定义
+// 审批人 = Users + 发起人的第 LeaderLevel 级 Leader + Departments 下的所有成员（去重）
+type ApprovalNode struct {
+	Name           string   `json:"name" example:"直属领导审批"`
+	Mode           string   `json:"mode" example:"sequential"`             // 审批模式：sequential/parallel/any_of，默认 sequential
+	Users          []string `json:"users,omitempty" example:"zhangsan"`    // 指定审批人
+	LeaderLevel    int      `json:"leader_level,omitempty" example:"1"`    // 发起人的第 N 级 Leader（1 表示直属 Leader，0 表示不使用）
+	Departments    []string `json:"departments,omitempty" example:"/hr"`   // 部门审批（部门完整路径）
+	TimeoutMinutes int      `json:"timeout_minutes,omitempty" example:"0"` // 超时时间（分钟，0 表示不超时）
+	TimeoutAction  string   `json:"timeout_action,omitempty" example:"escalate"`
+	EscalateTo     []string `json:"escalate_to,omitempty" example:"lisi"` // 超时升级给谁（为空时升级给审批人的 Leader）
+}
+
+// ApprovalProcess 审批流程定义（节点按顺序执行）
+type ApprovalProcess struct {
+	Code  string          `json:"code" example:"expense"`
+	Name  string          `json:"name" example:"报销审批"`
+	Nodes []*ApprovalNode `json:"nodes"`
+}
+
+// StartApprovalReq 发起审批请求（SDK App -> app-server，NATS 内部调用）
+type StartApprovalReq struct {
+	User         string                 `json:"user" example:"luobei"`
+	App          string                 `json:"app" example:"demo"`
+	FullCodePath string                 `json:"full_code_path" example:"/luobei/demo/oa/expense"`
+	RequestUser  string                 `json:"request_user" example:"beiluo"` // 发起人
+	TraceID      string                 `json:"trace_id"`
+	Token        string                 `json:"token"` // 透传前端 token，用于从 hr-server 解析审批人
+	Process      ApprovalProcess        `json:"process"`
+	Title        string                 `json:"title" example:"差旅报销 1200 元"`
+	RowID        int64                  `json:"row_id" example:"1"` // 关联的表格记录ID（Form 函数为 0）
+	Data         map[string]interface{} `json:"data"`               // 业务数据（审批时展示，回调时原样带回）
+}
+
+// StartApprovalResp 发起审批响应
+type StartApprovalResp struct {
+	InstanceID int64    `json:"instance_id" example:"1"`
+	Approvers  []string `json:"approvers"` // 第一个节点的审批人
+}
+
+// ApprovalTaskInfo 审批任务信息
+type ApprovalTaskInfo struct {
+	ID            int64  `json:"id" example:"1"`
+	InstanceID    int64  `json:"instance_id" example:"1"`
+	NodeIndex     int    `json:"node_index" example:"0"`
+	NodeName      string `json:"node_name" example:"直属领导审批"`
+	Assignee      string `json:"assignee" example:"zhangsan"`
+	Status        string `json:"status" example:"pending"` // waiting/pending/approved/rejected/transferred/escalated/canceled
+	Comment       string `json:"comment" example:"同意"`
+	TransferredBy string `json:"transferred_by,omitempty" example:"lisi"` // 由谁转交/升级而来
+	DueAt         string `json:"due_at,omitempty" example:"2024-01-02 00:00:00"`
+	ActedAt       string `json:"acted_at,omitempty" example:"2024-01-01 12:00:00"`
+	CreatedAt     string `json:"created_at" example:"2024-01-01 00:00:00"`
+}
+
+// ApprovalInstanceInfo 审批实例信息
+type ApprovalInstanceInfo struct {
+	ID           int64              `json:"id" example:"1"`
+	App          string             `json:"app" example:"luobei/demo"`
+	FullCodePath string             `json:"full_code_path" example:"/luobei/demo/oa/expense"`
+	ProcessCode  string             `json:"process_code" example:"expense"`
+	ProcessName  string             `json:"process_name" example:"报销审批"`
+	Title        string             `json:"title" example:"差旅报销 1200 元"`
+	RowID        int64              `json:"row_id" example:"1"`
+	Initiator    string             `json:"initiator" example:"beiluo"`
+	Status       string             `json:"status" example:"pending"`
+	CurrentNode  int                `json:"current_node" example:"0"`
+	Nodes        []string           `json:"nodes"` // 节点名称列表
+	Data         json.RawMessage    `json:"data" swaggertype:"object"`
+	CreatedAt    string             `json:"created_at" example:"2024-01-01 00:00:00"`
+	FinishedAt   string             `json:"finished_at,omitempty" example:"2024-01-01 12:00:00"`
+	Tasks        []ApprovalTaskInfo `json:"tasks,omitempty"`
+}
+
+// ApprovalTodoInfo 待办/已办审批
+type ApprovalTodoInfo struct {
+	Task     ApprovalTaskInfo     `json:"task"`
+	Instance ApprovalInstanceInfo `json:"instance"`
+}
+
+// GetApprovalTasksReq 获取我的审批任务请求
+type GetApprovalTasksReq struct {
+	Page     int  `json:"page" form:"page" example:"1"`
+	PageSize int  `json:"page_size" form:"page_size" example:"20"`
+	Done     bool `json:"done" form:"done" example:"false"` // false: 待办，true: 已办
+}
+
+// GetApprovalTasksResp 获取我的审批任务响应
+type GetApprovalTasksResp struct {
+	Tasks    []ApprovalTodoInfo `json:"tasks"`
+	Total    int64              `json:"total" example:"10"`
+	Page     int                `json:"page" example:"1"`
+	PageSize int                `json:"page_size" example:"20"`
+}
+
+// GetInitiatedApprovalsReq 获取我发起的审批请求
+type GetInitiatedApprovalsReq struct {
+	Page     int    `json:"page" form:"page" example:"1"`
+	PageSize int    `json:"page_size" form:"page_size" example:"20"`
+	Status   string `json:"status" form:"status" example:"pending"` // 为空表示全部
+}
+
+// ApprovalInstanceListResp 审批实例列表响应
+type ApprovalInstanceListResp struct {
+	Instances []ApprovalInstanceInfo `json:"instances"`
+	Total     int64                  `json:"total" example:"10"`
+	Page      int                    `json:"page" example:"1"`
+	PageSize  int                    `json:"page_size" example:"20"`
+}
+
+// GetRowApprovalsReq 获取记录的审批历史请求
+type GetRowApprovalsReq struct {
+	RowID int64 `json:"row_id" form:"row_id" binding:"required" example:"1"`
+}
+
+// ApprovalActionReq 审批操作请求
+type ApprovalActionReq struct {
+	TaskID     int64  `json:"task_id" binding:"required" example:"1"`
+	Action     string `json:"action" binding:"required" example:"approve"` // approve/reject/transfer
+	Comment    string `json:"comment" example:"同意"`
+	TransferTo string `json:"transfer_to" example:"lisi"` // 转交给谁（action=transfer 时必填）
+}
+
+// CancelApprovalReq 撤回审批请求（仅发起人）
+type CancelApprovalReq struct {
+	InstanceID int64  `json:"instance_id" binding:"required" example:"1"`
+	Comment    string `json:"comment" example:"信息填写错误，撤回重提"`
+}
@@ -0,0 +1,26 @@
+package dto
+
+import "encoding/json"
+
+// FormDraftInfo 向导表单草稿信息
+type FormDraftInfo struct {
+	Step      string          `json:"step" example:"联系人"`        // 保存草稿时所在的步骤（普通表单为空）
+	Data      json.RawMessage `json:"data" swaggertype:"object"` // 已填写的表单数据
+	UpdatedAt string          `json:"updated_at" example:"2024-01-01 00:00:00"`
+}
+
+// GetFormDraftResp 获取向导表单草稿响应
+type GetFormDraftResp struct {
+	Draft *FormDraftInfo `json:"draft"` // 没有草稿时为 null
+}
+
+// SaveFormDraftReq 保存向导表单草稿请求
+type SaveFormDraftReq struct {
+	Step string          `json:"step" example:"联系人"`                           // 当前所在的步骤
+	Data json.RawMessage `json:"data" binding:"required" swaggertype:"object"` // 已填写的表单数据（JSON 对象）
+}
+
+// SaveFormDraftResp 保存向导表单草稿响应
+type SaveFormDraftResp struct {
+	Draft *FormDraftInfo `json:"draft"`
+}
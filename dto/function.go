@@ -1,5 +1,7 @@
 package dto
 
+import "encoding/json"
+
 // GetFunctionReq 获取函数详情请求
 type GetFunctionReq struct {
 	FunctionID int64 `json:"function_id" binding:"required" example:"1"` // 函数ID
@@ -18,6 +20,7 @@ type GetFunctionResp struct {
 	TemplateType string                 `json:"template_type" example:"default"`                           // 模板类型
 	Request      interface{}            `json:"request"`                                                   // 请求配置（JSON对象）
 	Response     interface{}            `json:"response"`                                                  // 响应配置（JSON对象）
+	TemplateConfig json.RawMessage      `json:"template_config,omitempty"`                                 // 模板配置（看板的分列字段、日历的时间字段等）
 	CreatedAt    string                 `json:"created_at" example:"2024-01-01T00:00:00Z"`                 // 创建时间
 	UpdatedAt    string                 `json:"updated_at" example:"2024-01-01T00:00:00Z"`                 // 更新时间
 	CreatedBy    string                 `json:"created_by" example:"beiluo"`                                // 创建者用户名
@@ -0,0 +1,94 @@
+package dto
+
+import "encoding/json"
+
+// GetFunctionConfigResp 获取函数运行时配置响应
+type GetFunctionConfigResp struct {
+	FullCodePath string          `json:"full_code_path" example:"/luobei/demo/crm/crm_ticket"`       // 函数完整路径
+	Version      int             `json:"version" example:"3"`                                        // 当前配置版本（0 表示未修改过，使用代码中的默认值）
+	Schema       json.RawMessage `json:"schema" swaggertype:"string" example:"[]"`                   // 配置结构（widget 字段列表，前端按表单渲染）
+	Defaults     json.RawMessage `json:"defaults" swaggertype:"string" example:"{\"threshold\":10}"` // 代码中声明的默认值
+	Values       json.RawMessage `json:"values" swaggertype:"string" example:"{\"threshold\":20}"`   // 当前生效的配置值
+	Remark       string          `json:"remark,omitempty" example:"调高阈值"`                            // 当前版本的变更说明
+	UpdatedBy    string          `json:"updated_by,omitempty" example:"beiluo"`                      // 当前版本的修改人
+	UpdatedAt    string          `json:"updated_at,omitempty" example:"2024-01-01 00:00:00"`         // 当前版本的修改时间
+}
+
+// UpdateFunctionConfigReq 更新函数运行时配置请求
+type UpdateFunctionConfigReq struct {
+	Values json.RawMessage `json:"values" binding:"required" swaggertype:"string" example:"{\"threshold\":20}"` // 配置值（未提供的字段沿用当前值）
+	Remark string          `json:"remark" example:"调高阈值"`                                                       // 变更说明
+}
+
+// UpdateFunctionConfigResp 更新函数运行时配置响应
+type UpdateFunctionConfigResp struct {
+	Version int `json:"version" example:"4"` // 新版本号
+}
+
+// FunctionConfigHistoryReq 获取配置历史请求
+type FunctionConfigHistoryReq struct {
+	Page     int `json:"page" form:"page" example:"1"`
+	PageSize int `json:"page_size" form:"page_size" example:"20"`
+}
+
+// FunctionConfigVersionInfo 配置版本信息
+type FunctionConfigVersionInfo struct {
+	Version      int             `json:"version" example:"3"`
+	Values       json.RawMessage `json:"values" swaggertype:"string" example:"{\"threshold\":20}"`
+	Remark       string          `json:"remark" example:"调高阈值"`
+	RollbackFrom int             `json:"rollback_from,omitempty" example:"1"` // 如果是回滚产生的版本，记录回滚到的源版本
+	IsCurrent    bool            `json:"is_current" example:"true"`
+	CreatedBy    string          `json:"created_by" example:"beiluo"`
+	CreatedAt    string          `json:"created_at" example:"2024-01-01 00:00:00"`
+}
+
+// FunctionConfigHistoryResp 获取配置历史响应
+type FunctionConfigHistoryResp struct {
+	Versions []FunctionConfigVersionInfo `json:"versions"`
+	Total    int64                       `json:"total" example:"10"`
+	Page     int                         `json:"page" example:"1"`
+	PageSize int                         `json:"page_size" example:"20"`
+}
+
+// FunctionConfigDiffReq 配置版本对比请求
+type FunctionConfigDiffReq struct {
+	From int `json:"from" form:"from" example:"1"` // 源版本（0 表示代码默认值）
+	To   int `json:"to" form:"to" example:"3"`     // 目标版本（0 表示当前版本）
+}
+
+// FunctionConfigDiffItem 配置差异项
+type FunctionConfigDiffItem struct {
+	Code       string      `json:"code" example:"threshold"`       // 字段 code
+	Name       string      `json:"name" example:"告警阈值"`            // 字段名称
+	ChangeType string      `json:"change_type" example:"modified"` // added/removed/modified
+	Old        interface{} `json:"old"`
+	New        interface{} `json:"new"`
+}
+
+// FunctionConfigDiffResp 配置版本对比响应
+type FunctionConfigDiffResp struct {
+	From    int                      `json:"from" example:"1"`
+	To      int                      `json:"to" example:"3"`
+	Changes []FunctionConfigDiffItem `json:"changes"`
+}
+
+// FunctionConfigRollbackReq 配置回滚请求
+type FunctionConfigRollbackReq struct {
+	Version int    `json:"version" binding:"required" example:"2"` // 回滚到的版本
+	Remark  string `json:"remark" example:"阈值调整导致误报，回滚"`
+}
+
+// GetFunctionConfigRuntimeReq 获取函数运行时配置请求（SDK App -> app-server，NATS 内部调用）
+type GetFunctionConfigRuntimeReq struct {
+	User         string `json:"user" example:"luobei"`
+	App          string `json:"app" example:"demo"`
+	FullCodePath string `json:"full_code_path" example:"/luobei/demo/crm/crm_ticket"`
+	Token        string `json:"token"` // 应用访问令牌（app-runtime 签发，绑定 User/App）
+}
+
+// FunctionConfigRuntime 函数运行时配置（NATS 请求响应、配置变更推送共用）
+type FunctionConfigRuntime struct {
+	FullCodePath string          `json:"full_code_path" example:"/luobei/demo/crm/crm_ticket"`
+	Version      int             `json:"version" example:"3"`
+	Values       json.RawMessage `json:"values" swaggertype:"string" example:"{\"threshold\":20}"` // 为空表示使用代码默认值
+}
@@ -151,3 +151,24 @@ type DirectoryFileDTO struct {
 	// 注意：content 不包含在列表中，需要单独获取
 }
 
+// PublishHubAgentReq 发布智能体到 Hub 请求（内容与智能体导出包一致）
+type PublishHubAgentReq struct {
+	APIKey        string               `json:"api_key"`         // API Key（私有化部署需要）
+	HubAgentID    int64                `json:"hub_agent_id"`    // Hub 智能体 ID（更新时必需）
+	SourceAgentID int64                `json:"source_agent_id"` // 源智能体ID
+	Name          string               `json:"name"`            // 智能体名称
+	Description   string               `json:"description"`     // 智能体描述
+	Category      string               `json:"category"`        // 分类
+	Tags          []string             `json:"tags"`            // 标签
+	Version       string               `json:"version"`         // 版本号（首次发布为 v1）
+	Manifest      *AgentBundleManifest `json:"manifest"`        // 导出包清单
+	Files         []*FileSnapshotInfo  `json:"files"`           // 导出包中的文件（prompt.md 和知识库文档）
+}
+
+// PublishHubAgentResp 发布智能体到 Hub 响应
+type PublishHubAgentResp struct {
+	HubAgentID  int64  `json:"hub_agent_id"`
+	HubAgentURL string `json:"hub_agent_url"`
+	Version     string `json:"version"`    // 发布的版本号
+	FileCount   int    `json:"file_count"` // 包含的文件数量
+}
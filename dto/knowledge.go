@@ -175,3 +175,87 @@ type KnowledgeDocumentSortUpdate struct {
 	Path      string `json:"path" example:"/目录1/文档"`              // 路径
 }
 
+// KnowledgeIngestArchiveReq 从压缩包批量导入文档请求（multipart/form-data，文件字段为 file）
+type KnowledgeIngestArchiveReq struct {
+	KnowledgeBaseID int64  `json:"knowledge_base_id" form:"knowledge_base_id" binding:"required" example:"1"`
+	SubDir          string `json:"sub_dir" form:"sub_dir" example:"docs"`                // 只导入压缩包中的子目录
+	DeleteMissing   bool   `json:"delete_missing" form:"delete_missing" example:"false"` // 删除来源中已不存在的文档（只影响批量导入的文档）
+}
+
+// KnowledgeIngestGitReq 从服务器上的本地 git 仓库批量导入文档请求
+type KnowledgeIngestGitReq struct {
+	KnowledgeBaseID int64  `json:"knowledge_base_id" binding:"required" example:"1"`
+	RepoPath        string `json:"repo_path" binding:"required" example:"/data/repos/docs"` // 仓库路径，必须在配置的 ingest_roots 中
+	Ref             string `json:"ref" example:"main"`                                      // 分支、标签或提交，为空时使用 HEAD
+	SubDir          string `json:"sub_dir" example:"docs"`                                  // 只导入仓库中的子目录
+	DeleteMissing   bool   `json:"delete_missing" example:"false"`                          // 删除来源中已不存在的文档（只影响批量导入的文档）
+}
+
+// KnowledgeIngestResp 批量导入响应（导入在后台执行）
+type KnowledgeIngestResp struct {
+	JobID int64 `json:"job_id" example:"1"`
+}
+
+// KnowledgeIngestJobInfo 批量导入任务信息
+type KnowledgeIngestJobInfo struct {
+	ID              int64  `json:"id" example:"1"`
+	KnowledgeBaseID int64  `json:"knowledge_base_id" example:"1"`
+	SourceType      string `json:"source_type" example:"archive"` // archive/git
+	Source          string `json:"source" example:"docs.zip"`
+	Ref             string `json:"ref" example:"main"`
+	Commit          string `json:"commit" example:"9fceb02d0ae598e95dc970b74767f19372d61af8"`
+	SubDir          string `json:"sub_dir" example:"docs"`
+	DeleteMissing   bool   `json:"delete_missing" example:"false"`
+	Status          string `json:"status" example:"running"` // pending/running/succeeded/failed
+	Total           int    `json:"total" example:"120"`
+	Processed       int    `json:"processed" example:"60"`
+	Created         int    `json:"created" example:"10"`
+	Updated         int    `json:"updated" example:"5"`
+	Unchanged       int    `json:"unchanged" example:"45"`
+	Deleted         int    `json:"deleted" example:"0"`
+	Failed          int    `json:"failed" example:"0"`
+	Skipped         int    `json:"skipped" example:"3"` // 不支持的文件类型
+	ErrorMsg        string `json:"error_msg" example:""`
+	Duration        int    `json:"duration" example:"12"` // 耗时(秒)
+	User            string `json:"user" example:"admin"`
+	CreatedAt       string `json:"created_at" example:"2024-01-01T00:00:00Z"`
+	UpdatedAt       string `json:"updated_at" example:"2024-01-01T00:00:00Z"`
+}
+
+// KnowledgeIngestFileInfo 批量导入的单个文件处理结果
+type KnowledgeIngestFileInfo struct {
+	ID         int64  `json:"id" example:"1"`
+	Path       string `json:"path" example:"guide/install.md"`
+	Status     string `json:"status" example:"created"` // created/updated/unchanged/deleted/failed
+	DocumentID int64  `json:"document_id" example:"12"`
+	FileType   string `json:"file_type" example:"md"`
+	ErrorMsg   string `json:"error_msg" example:""`
+}
+
+// KnowledgeListIngestJobsReq 获取批量导入任务列表请求
+type KnowledgeListIngestJobsReq struct {
+	KnowledgeBaseID int64 `json:"knowledge_base_id" form:"knowledge_base_id" binding:"required" example:"1"`
+	Page            int   `json:"page" form:"page" binding:"required" example:"1"`
+	PageSize        int   `json:"page_size" form:"page_size" binding:"required" example:"10"`
+}
+
+// KnowledgeListIngestJobsResp 获取批量导入任务列表响应
+type KnowledgeListIngestJobsResp struct {
+	Jobs  []KnowledgeIngestJobInfo `json:"jobs"`
+	Total int64                    `json:"total" example:"3"`
+}
+
+// KnowledgeGetIngestJobReq 获取批量导入任务详情请求
+type KnowledgeGetIngestJobReq struct {
+	ID       int64  `json:"id" form:"id" binding:"required" example:"1"`
+	Status   string `json:"status" form:"status" example:"failed"` // 按文件状态过滤，为空表示全部
+	Page     int    `json:"page" form:"page" example:"1"`
+	PageSize int    `json:"page_size" form:"page_size" example:"50"`
+}
+
+// KnowledgeGetIngestJobResp 获取批量导入任务详情响应
+type KnowledgeGetIngestJobResp struct {
+	KnowledgeIngestJobInfo
+	Files      []KnowledgeIngestFileInfo `json:"files"`
+	FilesTotal int64                     `json:"files_total" example:"120"`
+}
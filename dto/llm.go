@@ -18,6 +18,9 @@ type LLMInfo struct {
 	MaxTokens   int    `json:"max_tokens" example:"4000"`
 	ExtraConfig string `json:"extra_config" example:"{}"`
 	UseThinking bool   `json:"use_thinking" example:"false"` // 是否使用思考模式（GLM特有功能）
+	Cost        float64 `json:"cost" example:"0.002"` // 每千 token 成本（智能体按成本路由时使用，0 表示按价格计算）
+	PromptPrice     float64 `json:"prompt_price" example:"0.002"`     // 输入每千 token 价格（用于用量费用统计）
+	CompletionPrice float64 `json:"completion_price" example:"0.008"` // 输出每千 token 价格（用于用量费用统计）
 	IsDefault   bool   `json:"is_default" example:"true"`
 	Visibility  int    `json:"visibility" example:"0"` // 0: 公开, 1: 私有
 	Admin       string `json:"admin" example:"user1,user2"` // 管理员列表（逗号分隔）
@@ -58,6 +61,9 @@ type LLMCreateReq struct {
 	MaxTokens   int     `json:"max_tokens" example:"4000"`
 	ExtraConfig *string `json:"extra_config" example:"{}"`
 	UseThinking bool    `json:"use_thinking" example:"false"` // 是否使用思考模式（GLM特有功能）
+	Cost        float64 `json:"cost" example:"0.002"` // 每千 token 成本（智能体按成本路由时使用，0 表示按价格计算）
+	PromptPrice     float64 `json:"prompt_price" example:"0.002"`     // 输入每千 token 价格（用于用量费用统计）
+	CompletionPrice float64 `json:"completion_price" example:"0.008"` // 输出每千 token 价格（用于用量费用统计）
 	IsDefault   bool    `json:"is_default" example:"false"`
 	Visibility  int     `json:"visibility" example:"0"` // 0: 公开, 1: 私有（默认0）
 	Admin       string  `json:"admin" example:"user1,user2"` // 管理员列表（逗号分隔，默认创建用户）
@@ -80,6 +86,9 @@ type LLMUpdateReq struct {
 	MaxTokens   int    `json:"max_tokens" example:"4000"`
 	ExtraConfig string `json:"extra_config" example:"{}"`
 	UseThinking bool   `json:"use_thinking" example:"false"` // 是否使用思考模式（GLM特有功能）
+	Cost        float64 `json:"cost" example:"0.002"` // 每千 token 成本（智能体按成本路由时使用，0 表示按价格计算）
+	PromptPrice     float64 `json:"prompt_price" example:"0.002"`     // 输入每千 token 价格（用于用量费用统计）
+	CompletionPrice float64 `json:"completion_price" example:"0.008"` // 输出每千 token 价格（用于用量费用统计）
 	IsDefault   bool   `json:"is_default" example:"false"`
 	Visibility  int    `json:"visibility" example:"0"` // 0: 公开, 1: 私有
 	Admin       string `json:"admin" example:"user1,user2"` // 管理员列表（逗号分隔）
@@ -0,0 +1,93 @@
+package dto
+
+// LLMQuotaInfo LLM 用量配额信息
+type LLMQuotaInfo struct {
+	ID          int64   `json:"id" example:"1"`
+	Scope       string  `json:"scope" example:"user"`          // user/department/agent
+	Target      string  `json:"target" example:"beiluo"`       // 用户名/部门完整路径（包含子部门）/智能体ID
+	Period      string  `json:"period" example:"daily"`        // daily/monthly
+	TokenLimit  int64   `json:"token_limit" example:"1000000"` // token 上限（0 表示不限制）
+	CostLimit   float64 `json:"cost_limit" example:"50"`       // 费用上限（0 表示不限制）
+	Enabled     bool    `json:"enabled" example:"true"`
+	Remark      string  `json:"remark" example:"后端组每日额度"`
+	UsedTokens  int64   `json:"used_tokens" example:"12000"` // 当前周期已用 token
+	UsedCost    float64 `json:"used_cost" example:"1.2"`     // 当前周期已用费用
+	PeriodStart string  `json:"period_start" example:"2024-01-01T00:00:00Z"`
+	CreatedBy   string  `json:"created_by" example:"beiluo"`
+	CreatedAt   string  `json:"created_at" example:"2024-01-01T00:00:00Z"`
+	UpdatedAt   string  `json:"updated_at" example:"2024-01-01T00:00:00Z"`
+}
+
+// LLMQuotaListReq 获取配额列表请求
+type LLMQuotaListReq struct {
+	Scope    string `json:"scope" form:"scope"`   // 按范围过滤（可选）
+	Target   string `json:"target" form:"target"` // 按对象过滤（可选）
+	Page     int    `json:"page" form:"page" binding:"required" example:"1"`
+	PageSize int    `json:"page_size" form:"page_size" binding:"required" example:"10"`
+}
+
+// LLMQuotaListResp 获取配额列表响应
+type LLMQuotaListResp struct {
+	Quotas []LLMQuotaInfo `json:"quotas"`
+	Total  int64          `json:"total" example:"10"`
+}
+
+// LLMQuotaCreateReq 创建配额请求
+type LLMQuotaCreateReq struct {
+	Scope      string  `json:"scope" binding:"required" example:"department"`
+	Target     string  `json:"target" binding:"required" example:"/tech/backend"`
+	Period     string  `json:"period" binding:"required" example:"monthly"`
+	TokenLimit int64   `json:"token_limit" example:"10000000"`
+	CostLimit  float64 `json:"cost_limit" example:"500"`
+	Enabled    bool    `json:"enabled" example:"true"`
+	Remark     string  `json:"remark" example:"后端组每月额度"`
+}
+
+// LLMQuotaCreateResp 创建配额响应
+type LLMQuotaCreateResp struct {
+	ID int64 `json:"id" example:"1"`
+}
+
+// LLMQuotaUpdateReq 更新配额请求（范围、对象和周期不可修改）
+type LLMQuotaUpdateReq struct {
+	ID         int64   `json:"id" binding:"required" example:"1"`
+	TokenLimit int64   `json:"token_limit" example:"10000000"`
+	CostLimit  float64 `json:"cost_limit" example:"500"`
+	Enabled    bool    `json:"enabled" example:"true"`
+	Remark     string  `json:"remark" example:"后端组每月额度"`
+}
+
+// LLMQuotaDeleteReq 删除配额请求
+type LLMQuotaDeleteReq struct {
+	ID int64 `json:"id" form:"id" binding:"required" example:"1"`
+}
+
+// LLMUsageReportReq 用量报表请求
+type LLMUsageReportReq struct {
+	GroupBy     string `json:"group_by" form:"group_by" binding:"required" example:"user"` // user/department/agent/model
+	Interval    string `json:"interval" form:"interval" example:"day"`                     // day/month，为空表示整个时间段汇总
+	StartDate   string `json:"start_date" form:"start_date" example:"2024-01-01"`          // 开始日期（包含），默认 30 天前
+	EndDate     string `json:"end_date" form:"end_date" example:"2024-01-31"`              // 结束日期（包含），默认今天
+	User        string `json:"user" form:"user" example:"beiluo"`                          // 按用户过滤（非用量管理员只能查看自己）
+	Department  string `json:"department" form:"department" example:"/tech"`               // 按部门过滤（包含子部门）
+	AgentID     int64  `json:"agent_id" form:"agent_id" example:"1"`                       // 按智能体过滤
+	LLMConfigID int64  `json:"llm_config_id" form:"llm_config_id" example:"1"`             // 按 LLM 配置过滤
+}
+
+// LLMUsageReportRow 用量报表行
+type LLMUsageReportRow struct {
+	Key              string  `json:"key" example:"beiluo"`                  // 分组键：用户名/部门路径/智能体ID/提供商/模型
+	Name             string  `json:"name,omitempty" example:"北落"`           // 分组显示名称（智能体名称）
+	Period           string  `json:"period,omitempty" example:"2024-01-01"` // 时间段（按天为 2006-01-02，按月为 2006-01）
+	Calls            int64   `json:"calls" example:"12"`
+	PromptTokens     int64   `json:"prompt_tokens" example:"10000"`
+	CompletionTokens int64   `json:"completion_tokens" example:"2000"`
+	TotalTokens      int64   `json:"total_tokens" example:"12000"`
+	Cost             float64 `json:"cost" example:"1.2"`
+}
+
+// LLMUsageReportResp 用量报表响应
+type LLMUsageReportResp struct {
+	Rows  []LLMUsageReportRow `json:"rows"`  // 按时间段、用量降序
+	Total LLMUsageReportRow   `json:"total"` // 合计
+}
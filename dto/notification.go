@@ -0,0 +1,90 @@
+package dto
+
+// NotificationInfo 站内通知信息
+type NotificationInfo struct {
+	ID           int64  `json:"id" example:"1"`
+	App          string `json:"app" example:"luobei/demo"`                                 // 发送通知的应用（user/app）
+	FullCodePath string `json:"full_code_path" example:"/luobei/demo/crm/crm_ticket"`      // 发送通知的函数
+	TemplateCode string `json:"template_code" example:"ticket_assigned"`                   // 通知模板编码
+	Title        string `json:"title" example:"工单已分配"`                                     // 标题
+	Content      string `json:"content" example:"工单「打印机故障」已分配给你"`                          // 内容
+	Level        string `json:"level" example:"info"`                                      // 级别：info/success/warning/error
+	Link         string `json:"link,omitempty" example:"/luobei/demo/crm/crm_ticket?id=1"` // 点击跳转链接
+	IsRead       bool   `json:"is_read" example:"false"`
+	ReadAt       string `json:"read_at,omitempty" example:"2024-01-01 00:00:00"`
+	CreatedBy    string `json:"created_by" example:"beiluo"` // 触发通知的用户
+	CreatedAt    string `json:"created_at" example:"2024-01-01 00:00:00"`
+}
+
+// GetNotificationsReq 获取通知列表请求
+type GetNotificationsReq struct {
+	Page       int  `json:"page" form:"page" example:"1"`
+	PageSize   int  `json:"page_size" form:"page_size" example:"20"`
+	UnreadOnly bool `json:"unread_only" form:"unread_only" example:"false"` // 只看未读
+}
+
+// GetNotificationsResp 获取通知列表响应
+type GetNotificationsResp struct {
+	Notifications []NotificationInfo `json:"notifications"`
+	Total         int64              `json:"total" example:"100"`
+	Unread        int64              `json:"unread" example:"3"`
+	Page          int                `json:"page" example:"1"`
+	PageSize      int                `json:"page_size" example:"20"`
+}
+
+// MarkNotificationsReadReq 标记已读请求
+type MarkNotificationsReadReq struct {
+	IDs []int64 `json:"ids" example:"1,2,3"` // 通知ID列表
+	All bool    `json:"all" example:"false"` // 全部标记为已读（忽略 IDs）
+}
+
+// GetUnreadCountResp 获取未读数量响应
+type GetUnreadCountResp struct {
+	Unread int64 `json:"unread" example:"3"`
+}
+
+// NotificationPreferenceInfo 通知偏好设置
+type NotificationPreferenceInfo struct {
+	InAppEnabled   bool   `json:"in_app_enabled" example:"true"`                          // 站内通知
+	EmailEnabled   bool   `json:"email_enabled" example:"false"`                          // 邮件通知
+	WebhookEnabled bool   `json:"webhook_enabled" example:"false"`                        // Webhook 通知
+	WebhookURL     string `json:"webhook_url" example:"https://example.com/hooks/notify"` // Webhook 地址
+	WebhookSecret  string `json:"webhook_secret,omitempty" example:"******"`              // 签名密钥（返回时固定为 ******，更新时为空表示不修改）
+}
+
+// SendNotificationTemplate 通知模板（标题和内容支持 text/template 语法，如 {{.title}}）
+type SendNotificationTemplate struct {
+	Code    string `json:"code" example:"ticket_assigned"`
+	Title   string `json:"title" example:"工单已分配"`
+	Content string `json:"content" example:"工单「{{.title}}」已分配给你"`
+	Level   string `json:"level" example:"info"`
+	Link    string `json:"link" example:"/luobei/demo/crm/crm_ticket?id={{.id}}"`
+}
+
+// SendNotificationReq 发送通知请求（SDK App -> app-server，NATS 内部调用）
+type SendNotificationReq struct {
+	User         string                   `json:"user" example:"luobei"` // 发送通知的应用所有者
+	App          string                   `json:"app" example:"demo"`    // 发送通知的应用
+	FullCodePath string                   `json:"full_code_path" example:"/luobei/demo/crm/crm_ticket"`
+	RequestUser  string                   `json:"request_user" example:"beiluo"` // 触发通知的用户
+	TraceID      string                   `json:"trace_id"`
+	Token        string                   `json:"token"`                               // 透传前端 token，用于查询部门成员、用户邮箱
+	AppToken     string                   `json:"app_token"`                           // 应用访问令牌（app-runtime 签发，绑定 User/App）
+	Users        []string                 `json:"users" example:"zhangsan,lisi"`       // 接收人
+	Departments  []string                 `json:"departments" example:"/tech/backend"` // 接收部门（部门完整路径，部门下所有成员都会收到）
+	Template     SendNotificationTemplate `json:"template"`
+	Data         map[string]interface{}   `json:"data"` // 模板数据
+}
+
+// SendNotificationResp 发送通知响应
+type SendNotificationResp struct {
+	Recipients []string `json:"recipients"` // 实际接收人（展开部门、去重后）
+}
+
+// NotificationWebhookPayload Webhook 推送内容
+// 签名：X-Signature = "sha256=" + hex(HMAC-SHA256(secret, X-Timestamp + "." + body))
+type NotificationWebhookPayload struct {
+	Event        string           `json:"event" example:"notification"`
+	Username     string           `json:"username" example:"zhangsan"` // 接收人
+	Notification NotificationInfo `json:"notification"`
+}
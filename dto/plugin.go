@@ -1,29 +1,32 @@
 package dto
 
+import "encoding/json"
+
 // PluginListReq 获取插件列表请求
 type PluginListReq struct {
 	Enabled  *bool  `json:"enabled" form:"enabled"` // true, false
-	Scope    string `json:"scope" form:"scope"`    // mine: 我的, market: 市场
+	Scope    string `json:"scope" form:"scope"`     // mine: 我的, market: 市场
 	Page     int    `json:"page" form:"page" binding:"required" example:"1"`
 	PageSize int    `json:"page_size" form:"page_size" binding:"required" example:"10"`
 }
 
 // PluginInfo 插件信息
 type PluginInfo struct {
-	ID          int64   `json:"id" example:"1"`
-	Name        string  `json:"name" example:"Excel解析插件"`
-	Code        string  `json:"code" example:"excel_parser"`
-	Description string  `json:"description" example:"解析Excel文件为Markdown表格"`
-	Enabled     bool    `json:"enabled" example:"true"`
-	Subject     string  `json:"subject" example:"plugins.beiluo.1"` // NATS主题，自动生成
-	NatsHost    string  `json:"nats_host" example:"nats://127.0.0.1:4222"` // NATS 服务器地址
-	Config      *string `json:"config" example:"{\"timeout\": 30, \"max_file_size\": 10485760}"` // 插件配置（JSON）
-	User        string  `json:"user" example:"beiluo"` // 创建用户（保留用于向后兼容）
-	Visibility  int     `json:"visibility" example:"0"` // 0: 公开, 1: 私有
-	Admin       string  `json:"admin" example:"user1,user2"` // 管理员列表（逗号分隔）
-	IsAdmin     bool    `json:"is_admin" example:"true"` // 当前用户是否是管理员
-	CreatedAt   string  `json:"created_at" example:"2024-01-01T00:00:00Z"`
-	UpdatedAt   string  `json:"updated_at" example:"2024-01-01T00:00:00Z"`
+	ID          int64                `json:"id" example:"1"`
+	Name        string               `json:"name" example:"Excel解析插件"`
+	Code        string               `json:"code" example:"excel_parser"`
+	Description string               `json:"description" example:"解析Excel文件为Markdown表格"`
+	Enabled     bool                 `json:"enabled" example:"true"`
+	Subject     string               `json:"subject" example:"plugins.beiluo.1"`                              // NATS主题，自动生成
+	NatsHost    string               `json:"nats_host" example:"nats://127.0.0.1:4222"`                       // NATS 服务器地址
+	Config      *string              `json:"config" example:"{\"timeout\": 30, \"max_file_size\": 10485760}"` // 插件配置（JSON）
+	User        string               `json:"user" example:"beiluo"`                                           // 创建用户（保留用于向后兼容）
+	Visibility  int                  `json:"visibility" example:"0"`                                          // 0: 公开, 1: 私有
+	Admin       string               `json:"admin" example:"user1,user2"`                                     // 管理员列表（逗号分隔）
+	IsAdmin     bool                 `json:"is_admin" example:"true"`                                         // 当前用户是否是管理员
+	Runtime     *PluginRuntimeStatus `json:"runtime,omitempty"`                                               // 运行状态（在线实例、延迟）
+	CreatedAt   string               `json:"created_at" example:"2024-01-01T00:00:00Z"`
+	UpdatedAt   string               `json:"updated_at" example:"2024-01-01T00:00:00Z"`
 }
 
 // PluginListResp 获取插件列表响应
@@ -41,8 +44,8 @@ type CreatePluginReq struct {
 	Description string  `json:"description" example:"解析Excel文件为Markdown表格"`
 	Enabled     bool    `json:"enabled" example:"true"`
 	Config      *string `json:"config" example:"{\"timeout\": 30, \"max_file_size\": 10485760}"` // 插件配置（JSON）
-	Visibility  int     `json:"visibility" example:"0"` // 0: 公开, 1: 私有（默认0）
-	Admin       string  `json:"admin" example:"user1,user2"` // 管理员列表（逗号分隔，默认创建用户）
+	Visibility  int     `json:"visibility" example:"0"`                                          // 0: 公开, 1: 私有（默认0）
+	Admin       string  `json:"admin" example:"user1,user2"`                                     // 管理员列表（逗号分隔，默认创建用户）
 }
 
 // UpdatePluginReq 更新插件请求
@@ -52,7 +55,56 @@ type UpdatePluginReq struct {
 	Description string  `json:"description" example:"解析Excel文件为Markdown表格"`
 	Enabled     bool    `json:"enabled" example:"true"`
 	Config      *string `json:"config" example:"{\"timeout\": 30, \"max_file_size\": 10485760}"` // 插件配置（JSON）
-	Visibility  int     `json:"visibility" example:"0"` // 0: 公开, 1: 私有
-	Admin       string  `json:"admin" example:"user1,user2"` // 管理员列表（逗号分隔）
+	Visibility  int     `json:"visibility" example:"0"`                                          // 0: 公开, 1: 私有
+	Admin       string  `json:"admin" example:"user1,user2"`                                     // 管理员列表（逗号分隔）
+}
+
+// 插件实例状态上报类型
+const (
+	PluginPresenceOnline    = "online"    // 上线
+	PluginPresenceHeartbeat = "heartbeat" // 心跳
+	PluginPresenceOffline   = "offline"   // 下线
+)
+
+// PluginPresence 插件实例状态上报（plugin -> agent-server，启动时上线，之后定期心跳，关闭时下线）
+type PluginPresence struct {
+	Type         string          `json:"type" example:"heartbeat"`                     // online/heartbeat/offline
+	Subject      string          `json:"subject" example:"plugins.beiluo.1"`           // 插件主题
+	InstanceID   string          `json:"instance_id" example:"excel-parser-7f9c"`      // 实例ID（每个进程唯一）
+	Hostname     string          `json:"hostname" example:"worker-01"`                 // 主机名
+	Version      string          `json:"version" example:"1.2.0"`                      // 插件版本
+	Capabilities []string        `json:"capabilities" example:"excel,csv"`             // 插件能力
+	InputSchema  json.RawMessage `json:"input_schema,omitempty" swaggertype:"object"`  // 声明的输入结构（JSON Schema）
+	OutputSchema json.RawMessage `json:"output_schema,omitempty" swaggertype:"object"` // 声明的输出结构（JSON Schema）
+	Concurrency  int             `json:"concurrency" example:"4"`                      // 最大并发处理数
+	Inflight     int             `json:"inflight" example:"1"`                         // 正在处理的请求数
+	Interval     int             `json:"interval" example:"10"`                        // 心跳间隔（秒）
 }
 
+// PluginInstanceInfo 插件实例信息
+type PluginInstanceInfo struct {
+	InstanceID      string   `json:"instance_id" example:"excel-parser-7f9c"`
+	Hostname        string   `json:"hostname" example:"worker-01"`
+	Version         string   `json:"version" example:"1.2.0"`
+	Capabilities    []string `json:"capabilities" example:"excel,csv"`
+	Concurrency     int      `json:"concurrency" example:"4"`
+	Inflight        int      `json:"inflight" example:"1"`
+	OnlineAt        string   `json:"online_at" example:"2024-01-01T00:00:00Z"`
+	LastHeartbeatAt string   `json:"last_heartbeat_at" example:"2024-01-01T00:00:00Z"`
+}
+
+// PluginRuntimeStatus 插件运行状态（在线实例和调用延迟）
+type PluginRuntimeStatus struct {
+	Online        bool                 `json:"online" example:"true"`
+	InstanceCount int                  `json:"instance_count" example:"2"`
+	Concurrency   int                  `json:"concurrency" example:"8"`                      // 所有在线实例的并发总和
+	Version       string               `json:"version" example:"1.2.0"`                      // 最近上线实例的版本
+	Capabilities  []string             `json:"capabilities" example:"excel,csv"`             // 最近上线实例声明的能力
+	InputSchema   json.RawMessage      `json:"input_schema,omitempty" swaggertype:"object"`  // 最近上线实例声明的输入结构
+	OutputSchema  json.RawMessage      `json:"output_schema,omitempty" swaggertype:"object"` // 最近上线实例声明的输出结构
+	AvgLatencyMs  int64                `json:"avg_latency_ms" example:"1200"`                // 调用平均延迟（指数滑动平均）
+	LastLatencyMs int64                `json:"last_latency_ms" example:"900"`                // 最近一次调用延迟
+	LastCallAt    string               `json:"last_call_at" example:"2024-01-01T00:00:00Z"`
+	LastError     string               `json:"last_error,omitempty" example:""` // 最近一次调用的错误
+	Instances     []PluginInstanceInfo `json:"instances"`
+}
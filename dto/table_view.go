@@ -0,0 +1,48 @@
+package dto
+
+import "encoding/json"
+
+// TableViewInfo 表格视图信息
+type TableViewInfo struct {
+	ID            int64    `json:"id" example:"1"`
+	Name          string   `json:"name" example:"我的待处理工单"`
+	Scope         string   `json:"scope" example:"private"`                      // 可见范围：private/department/all
+	Department    string   `json:"department,omitempty" example:"/tech/backend"` // 共享的部门（scope=department）
+	Filter        string   `json:"filter" example:"and(eq(status,待处理),eq(assignee,beiluo))"`
+	Sorts         string   `json:"sorts" example:"id:desc"`
+	Columns       []string `json:"columns"`                                           // 显示的列（按顺序），为空表示全部
+	PageSize      int      `json:"page_size" example:"50"`                            // 每页数量（0 表示默认）
+	IsDefault     bool     `json:"is_default" example:"false"`                        // 是否为所有人的默认视图
+	QueryString   string   `json:"query_string" example:"filter=...&sorts=id%3Adesc"` // 打开视图的 URL 查询参数（包含 view=视图ID）
+	InvalidFields []string `json:"invalid_fields,omitempty"`                          // 函数结构变更后引用的已删除字段，不为空时需要修改视图
+	Editable      bool     `json:"editable" example:"true"`                           // 当前用户是否可以修改/删除（创建人）
+	CreatedBy     string   `json:"created_by" example:"beiluo"`                       // 创建人
+	UpdatedAt     string   `json:"updated_at" example:"2024-01-01 00:00:00"`
+}
+
+// ListTableViewsResp 获取表格视图列表响应
+type ListTableViewsResp struct {
+	Views []TableViewInfo `json:"views"`
+}
+
+// SaveTableViewReq 创建或更新表格视图请求
+type SaveTableViewReq struct {
+	ID        int64           `json:"id" example:"0"`                                            // 视图ID（0 表示创建）
+	Name      string          `json:"name" binding:"required" example:"我的待处理工单"`                 // 视图名称
+	Scope     string          `json:"scope" example:"private"`                                   // 可见范围：private（默认）/department/all
+	Filter    json.RawMessage `json:"filter" swaggertype:"string" example:"and(eq(status,待处理))"` // 过滤表达式（紧凑格式字符串或 JSON AST）
+	Sorts     string          `json:"sorts" example:"id:desc"`                                   // 排序
+	Columns   []string        `json:"columns"`                                                   // 显示的列（按顺序），为空表示全部
+	PageSize  int             `json:"page_size" example:"50"`                                    // 每页数量（0 表示默认）
+	IsDefault bool            `json:"is_default" example:"false"`                                // 设置为所有人的默认视图（需要 scope=all）
+}
+
+// SaveTableViewResp 创建或更新表格视图响应
+type SaveTableViewResp struct {
+	View TableViewInfo `json:"view"`
+}
+
+// DeleteTableViewReq 删除表格视图请求
+type DeleteTableViewReq struct {
+	ID int64 `json:"id" binding:"required" example:"1"`
+}
@@ -0,0 +1,205 @@
+package dto
+
+import "encoding/json"
+
+// 工作流触发方式
+const (
+	WorkflowTriggerManual     = "manual"      // 手动触发
+	WorkflowTriggerSchedule   = "schedule"    // 定时触发（cron 表达式）
+	WorkflowTriggerTableEvent = "table_event" // 表格数据变更触发
+)
+
+// 工作流运行状态
+const (
+	WorkflowRunPending   = "pending"   // 等待执行
+	WorkflowRunRunning   = "running"   // 执行中
+	WorkflowRunSucceeded = "succeeded" // 执行成功
+	WorkflowRunFailed    = "failed"    // 执行失败
+	WorkflowRunCanceled  = "canceled"  // 已取消
+)
+
+// 工作流步骤状态
+const (
+	WorkflowStepPending   = "pending"   // 等待执行
+	WorkflowStepRunning   = "running"   // 执行中（重试中）
+	WorkflowStepSucceeded = "succeeded" // 执行成功
+	WorkflowStepFailed    = "failed"    // 执行失败（已重试）
+	WorkflowStepSkipped   = "skipped"   // 条件不满足或上游被跳过
+)
+
+// 工作流条件运算符
+const (
+	WorkflowOpEq       = "eq"
+	WorkflowOpNe       = "ne"
+	WorkflowOpGt       = "gt"
+	WorkflowOpGte      = "gte"
+	WorkflowOpLt       = "lt"
+	WorkflowOpLte      = "lte"
+	WorkflowOpContains = "contains"
+	WorkflowOpEmpty    = "empty"
+	WorkflowOpNotEmpty = "not_empty"
+)
+
+// WorkflowInput 步骤输入映射（把上游输出或触发数据映射到目标函数的请求字段）
+// From 格式：trigger.<字段> 或 <step_key>.<字段>，支持 a.b.0.c 的形式读取嵌套值
+type WorkflowInput struct {
+	Field string      `json:"field" example:"customer_id"`                     // 目标函数的请求字段 code
+	From  string      `json:"from,omitempty" example:"create_customer.id"`     // 数据来源
+	Value interface{} `json:"value,omitempty" swaggertype:"string" example:""` // 固定值（From 为空时使用）
+}
+
+// WorkflowCondition 步骤执行条件（步骤的所有条件都满足才执行，否则跳过，下游也会被跳过）
+type WorkflowCondition struct {
+	From  string      `json:"from" example:"trigger.amount"`
+	Op    string      `json:"op" example:"gt"` // eq/ne/gt/gte/lt/lte/contains/empty/not_empty
+	Value interface{} `json:"value,omitempty" swaggertype:"string" example:"1000"`
+}
+
+// WorkflowStep 工作流步骤（一个函数调用）
+type WorkflowStep struct {
+	Key                  string               `json:"key" example:"create_customer"` // 步骤标识（工作流内唯一，用于引用输出）
+	Name                 string               `json:"name" example:"创建客户"`
+	FullCodePath         string               `json:"full_code_path" example:"/luobei/crm/customer/crm_customer"`
+	Callback             string               `json:"callback,omitempty" example:"OnTableAddRow"` // 调用 Table 函数的回调（如 OnTableAddRow 新增记录），为空时直接调用函数
+	DependsOn            []string             `json:"depends_on,omitempty"`                       // 依赖的步骤（依赖全部完成后才执行）
+	Inputs               []*WorkflowInput     `json:"inputs,omitempty"`
+	Conditions           []*WorkflowCondition `json:"conditions,omitempty"`
+	MaxRetries           int                  `json:"max_retries,omitempty" example:"2"`             // 失败重试次数
+	RetryIntervalSeconds int                  `json:"retry_interval_seconds,omitempty" example:"10"` // 重试间隔（秒）
+	ContinueOnError      bool                 `json:"continue_on_error,omitempty"`                   // 失败后是否继续执行下游
+}
+
+// WorkflowDefinition 工作流定义（步骤组成的有向无环图）
+type WorkflowDefinition struct {
+	Steps []*WorkflowStep `json:"steps"`
+}
+
+// WorkflowTrigger 工作流触发器
+type WorkflowTrigger struct {
+	Type         string   `json:"type" example:"schedule"`                                              // manual/schedule/table_event
+	Cron         string   `json:"cron,omitempty" example:"0 9 * * 1-5"`                                 // 定时触发的 cron 表达式（分 时 日 月 周）
+	FullCodePath string   `json:"full_code_path,omitempty" example:"/luobei/crm/customer/crm_customer"` // 表格数据变更触发的 Table 函数
+	Events       []string `json:"events,omitempty" example:"OnTableAddRow"`                             // 触发的表格事件（OnTableAddRow/OnTableUpdateRow/OnTableDeleteRows），为空表示全部
+}
+
+// WorkflowInfo 工作流信息
+type WorkflowInfo struct {
+	ID          int64              `json:"id" example:"1"`
+	Name        string             `json:"name" example:"新客户跟进"`
+	Description string             `json:"description" example:"新增客户后自动创建跟进工单并通知负责人"`
+	Definition  WorkflowDefinition `json:"definition"`
+	Triggers    []*WorkflowTrigger `json:"triggers"`
+	Enabled     bool               `json:"enabled" example:"true"`
+	CreatedBy   string             `json:"created_by" example:"beiluo"`
+	CreatedAt   string             `json:"created_at" example:"2024-01-01 00:00:00"`
+	UpdatedAt   string             `json:"updated_at" example:"2024-01-01 00:00:00"`
+}
+
+// SaveWorkflowReq 保存工作流请求（ID 为 0 时创建）
+type SaveWorkflowReq struct {
+	ID          int64              `json:"id" example:"0"`
+	Name        string             `json:"name" binding:"required" example:"新客户跟进"`
+	Description string             `json:"description" example:"新增客户后自动创建跟进工单并通知负责人"`
+	Definition  WorkflowDefinition `json:"definition"`
+	Triggers    []*WorkflowTrigger `json:"triggers"`
+	Enabled     bool               `json:"enabled" example:"true"`
+}
+
+// SaveWorkflowResp 保存工作流响应
+type SaveWorkflowResp struct {
+	ID int64 `json:"id" example:"1"`
+}
+
+// GetWorkflowsReq 获取工作流列表请求
+type GetWorkflowsReq struct {
+	Page     int    `json:"page" form:"page" example:"1"`
+	PageSize int    `json:"page_size" form:"page_size" example:"20"`
+	Keyword  string `json:"keyword" form:"keyword" example:"客户"`
+}
+
+// GetWorkflowsResp 获取工作流列表响应
+type GetWorkflowsResp struct {
+	Workflows []WorkflowInfo `json:"workflows"`
+	Total     int64          `json:"total" example:"10"`
+	Page      int            `json:"page" example:"1"`
+	PageSize  int            `json:"page_size" example:"20"`
+}
+
+// DeleteWorkflowReq 删除工作流请求
+type DeleteWorkflowReq struct {
+	ID int64 `json:"id" binding:"required" example:"1"`
+}
+
+// RunWorkflowReq 手动运行工作流请求
+type RunWorkflowReq struct {
+	ID    int64                  `json:"id" binding:"required" example:"1"`
+	Input map[string]interface{} `json:"input"` // 触发数据，步骤中通过 trigger.<字段> 引用
+}
+
+// RunWorkflowResp 手动运行工作流响应
+type RunWorkflowResp struct {
+	RunID int64 `json:"run_id" example:"1"`
+}
+
+// CancelWorkflowRunReq 取消工作流运行请求
+type CancelWorkflowRunReq struct {
+	RunID int64 `json:"run_id" binding:"required" example:"1"`
+}
+
+// GetWorkflowRunsReq 获取工作流运行记录请求
+type GetWorkflowRunsReq struct {
+	WorkflowID int64 `json:"workflow_id" form:"workflow_id" binding:"required" example:"1"`
+	Page       int   `json:"page" form:"page" example:"1"`
+	PageSize   int   `json:"page_size" form:"page_size" example:"20"`
+}
+
+// WorkflowStepRunInfo 步骤运行信息
+type WorkflowStepRunInfo struct {
+	StepKey      string          `json:"step_key" example:"create_customer"`
+	StepName     string          `json:"step_name" example:"创建客户"`
+	FullCodePath string          `json:"full_code_path" example:"/luobei/crm/customer/crm_customer"`
+	Status       string          `json:"status" example:"succeeded"`
+	Attempts     int             `json:"attempts" example:"1"`
+	Request      json.RawMessage `json:"request" swaggertype:"object"`
+	Response     json.RawMessage `json:"response" swaggertype:"object"`
+	Error        string          `json:"error" example:""`
+	Logs         []string        `json:"logs"`
+	StartedAt    string          `json:"started_at" example:"2024-01-01 00:00:00"`
+	FinishedAt   string          `json:"finished_at" example:"2024-01-01 00:00:01"`
+}
+
+// WorkflowRunInfo 工作流运行信息
+type WorkflowRunInfo struct {
+	ID          int64                 `json:"id" example:"1"`
+	WorkflowID  int64                 `json:"workflow_id" example:"1"`
+	Status      string                `json:"status" example:"succeeded"`
+	TriggerType string                `json:"trigger_type" example:"manual"`
+	RunAs       string                `json:"run_as" example:"beiluo"`
+	Input       json.RawMessage       `json:"input" swaggertype:"object"`
+	Error       string                `json:"error" example:""`
+	TraceID     string                `json:"trace_id"`
+	StartedAt   string                `json:"started_at" example:"2024-01-01 00:00:00"`
+	FinishedAt  string                `json:"finished_at" example:"2024-01-01 00:00:05"`
+	CreatedAt   string                `json:"created_at" example:"2024-01-01 00:00:00"`
+	Steps       []WorkflowStepRunInfo `json:"steps,omitempty"`
+}
+
+// GetWorkflowRunsResp 获取工作流运行记录响应
+type GetWorkflowRunsResp struct {
+	Runs     []WorkflowRunInfo `json:"runs"`
+	Total    int64             `json:"total" example:"10"`
+	Page     int               `json:"page" example:"1"`
+	PageSize int               `json:"page_size" example:"20"`
+}
+
+// SuggestWorkflowInputsReq 推荐步骤输入映射请求
+type SuggestWorkflowInputsReq struct {
+	Definition WorkflowDefinition `json:"definition"`
+}
+
+// SuggestWorkflowInputsResp 推荐步骤输入映射响应
+// 按字段 code 和类型在上游步骤的输出中查找匹配项，只补充尚未映射的字段
+type SuggestWorkflowInputsResp struct {
+	Definition WorkflowDefinition `json:"definition"`
+	Warnings   []string           `json:"warnings"` // 校验不通过的地方（如类型不兼容）
+}
@@ -77,3 +77,26 @@ func SearchUsersFuzzy(header *Header, keyword string, limit int) ([]dto.UserInfo
 	return result.Data.Users, nil
 }
 
+
+// GetUsersByDepartment 根据部门完整路径获取部门下的用户（app-server -> hr-server）
+func GetUsersByDepartment(header *Header, departmentFullPath string) ([]dto.UserInfo, error) {
+	// 构建查询参数
+	path := "/hr/api/v1/user/department"
+	params := url.Values{}
+	params.Set("department_full_path", departmentFullPath)
+
+	// 构建完整 URL
+	gatewayURL := serviceconfig.GetGatewayURL()
+	fullURL := fmt.Sprintf("%s%s?%s", gatewayURL, path, params.Encode())
+
+	result, err := callAPIWithURL[dto.GetUsersByDepartmentResp](
+		http.MethodGet,
+		fullURL,
+		header,
+		nil,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return result.Data.Users, nil
+}
@@ -42,6 +42,34 @@ func UpdateDirectoryToHub(header *Header, req *dto.UpdateHubDirectoryReq) (*dto.
 	return &result.Data, nil
 }
 
+// PublishAgentToHub 发布智能体到 Hub（首次发布）
+func PublishAgentToHub(header *Header, req *dto.PublishHubAgentReq) (*dto.PublishHubAgentResp, error) {
+	result, err := callAPI[dto.PublishHubAgentResp](
+		http.MethodPost,
+		"/hub/api/v1/agents/publish",
+		header,
+		req,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &result.Data, nil
+}
+
+// UpdateAgentToHub 发布智能体的新版本到 Hub
+func UpdateAgentToHub(header *Header, req *dto.PublishHubAgentReq) (*dto.PublishHubAgentResp, error) {
+	result, err := callAPI[dto.PublishHubAgentResp](
+		http.MethodPut,
+		"/hub/api/v1/agents/update",
+		header,
+		req,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &result.Data, nil
+}
+
 // GetHubDirectoryList 获取 Hub 目录列表
 func GetHubDirectoryList(header *Header, page, pageSize int, search, category, publisherUsername string) (*dto.HubDirectoryListResp, error) {
 	// 构建查询参数
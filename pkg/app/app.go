@@ -1,5 +1,49 @@
 package app
 
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SecretEnvPrefix 应用密钥注入到容器中的环境变量前缀（如 SECRET_OPENAI_API_KEY）
+const SecretEnvPrefix = "SECRET_"
+
+// SecretTokenEnv 应用密钥访问令牌环境变量（app-runtime 启动容器时注入，SDK 获取密钥时携带）
+const SecretTokenEnv = "APP_SECRET_TOKEN"
+
+// BuildSecretToken 生成应用密钥访问令牌：{过期时间戳}.{HMAC-SHA256(key, user/app/过期时间戳)}
+// key 为 app-runtime 与 app-server 共享的服务密钥，令牌只能在过期前读取对应应用的密钥
+func BuildSecretToken(key []byte, user, app string, expiresAt time.Time) string {
+	expires := strconv.FormatInt(expiresAt.Unix(), 10)
+	return expires + "." + signSecretToken(key, user, app, expires)
+}
+
+// VerifySecretToken 校验应用密钥访问令牌（签名与应用匹配且未过期）
+func VerifySecretToken(key []byte, user, app, token string) bool {
+	if len(key) == 0 || token == "" {
+		return false
+	}
+	expires, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return false
+	}
+	expiresAt, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || time.Now().Unix() > expiresAt {
+		return false
+	}
+	return hmac.Equal([]byte(signSecretToken(key, user, app, expires)), []byte(signature))
+}
+
+func signSecretToken(key []byte, user, app, expires string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(user + "/" + app + "/" + expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
 type App struct {
 }
 
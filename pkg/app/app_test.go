@@ -0,0 +1,51 @@
+package app
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestVerifySecretToken(t *testing.T) {
+	key := []byte("shared-service-key")
+	valid := BuildSecretToken(key, "beiluo", "crm", time.Now().Add(time.Hour))
+
+	cases := []struct {
+		name  string
+		key   []byte
+		user  string
+		app   string
+		token string
+		want  bool
+	}{
+		{"valid", key, "beiluo", "crm", valid, true},
+		{"wrong key", []byte("other-key"), "beiluo", "crm", valid, false},
+		{"empty key", nil, "beiluo", "crm", valid, false},
+		{"other app", key, "beiluo", "hr", valid, false},
+		{"other user", key, "alice", "crm", valid, false},
+		{"expired", key, "beiluo", "crm", BuildSecretToken(key, "beiluo", "crm", time.Now().Add(-time.Second)), false},
+		{"empty token", key, "beiluo", "crm", "", false},
+		{"missing expiry", key, "beiluo", "crm", valid[strings.Index(valid, ".")+1:], false},
+		{"bad expiry", key, "beiluo", "crm", "abc" + valid[strings.Index(valid, "."):], false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := VerifySecretToken(tc.key, tc.user, tc.app, tc.token); got != tc.want {
+				t.Fatalf("VerifySecretToken() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+// TestVerifySecretTokenExtendedExpiry 篡改过期时间延长有效期，签名不再匹配
+func TestVerifySecretTokenExtendedExpiry(t *testing.T) {
+	key := []byte("shared-service-key")
+	token := BuildSecretToken(key, "beiluo", "crm", time.Now().Add(-time.Minute))
+	_, signature, _ := strings.Cut(token, ".")
+	forged := BuildSecretToken(key, "beiluo", "crm", time.Now().Add(time.Hour))
+	expires, _, _ := strings.Cut(forged, ".")
+
+	if VerifySecretToken(key, "beiluo", "crm", expires+"."+signature) {
+		t.Fatal("token with extended expiry should be rejected")
+	}
+}
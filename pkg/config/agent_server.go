@@ -50,14 +50,23 @@ type AgentServerServerConfig struct {
 // AgentConfig 智能体配置
 type AgentConfig struct {
 	Timeout int `mapstructure:"timeout"`
+	// LLM 用量管理员（逗号分隔），可以管理配额、查看所有用户的用量报表
+	UsageAdmins string `mapstructure:"usage_admins"`
+	// 配额查询失败（数据库异常）时是否放行调用，默认 false 拒绝调用
+	QuotaFailOpen bool `mapstructure:"quota_fail_open"`
+	// 知识库批量导入允许读取的本地 git 仓库根目录，为空时不允许从本地路径导入
+	IngestRoots []string `mapstructure:"ingest_roots"`
 	// 注意：NATS 配置已移至全局配置，不再在此处配置
 }
 
 // 便捷访问方法
-func (c *AgentServerConfig) GetPort() int         { return c.Server.Port }
-func (c *AgentServerConfig) GetLogLevel() string  { return c.Server.LogLevel }
-func (c *AgentServerConfig) IsDebug() bool        { return c.Server.Debug }
-func (c *AgentServerConfig) GetAgentTimeout() int { return c.Agent.Timeout }
+func (c *AgentServerConfig) GetPort() int             { return c.Server.Port }
+func (c *AgentServerConfig) GetLogLevel() string      { return c.Server.LogLevel }
+func (c *AgentServerConfig) IsDebug() bool            { return c.Server.Debug }
+func (c *AgentServerConfig) GetAgentTimeout() int     { return c.Agent.Timeout }
+func (c *AgentServerConfig) GetUsageAdmins() string   { return c.Agent.UsageAdmins }
+func (c *AgentServerConfig) IsQuotaFailOpen() bool    { return c.Agent.QuotaFailOpen }
+func (c *AgentServerConfig) GetIngestRoots() []string { return c.Agent.IngestRoots }
 
 // 数据库配置便捷访问方法
 func (c *AgentServerConfig) GetDBLogLevel() string {
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"crypto/sha256"
 	"fmt"
 	"sync"
 )
@@ -37,6 +38,7 @@ type AppServerConfig struct {
 	Timeouts AppServerTimeoutCfg   `mapstructure:"timeouts"`
 	Email    EmailConfig           `mapstructure:"email"`
 	DB       DBConfig              `mapstructure:"db"`
+	Secret   AppSecretConfig       `mapstructure:"secret"`
 	// 注意：NATS、JWT、Control Service 配置已移至全局配置，不再在此处配置
 	// 数据库配置保留在服务配置中，因为微服务后续每个服务一个库
 }
@@ -82,6 +84,11 @@ type EmailVerificationConfig struct {
 type EmailRegisterConfig struct {
 }
 
+// AppSecretConfig 应用密钥配置
+type AppSecretConfig struct {
+	EncryptionKey string `mapstructure:"encryption_key"` // 应用密钥加密密钥（32字节字符串，用于AES-256-GCM加密存储）
+}
+
 // JWTConfig JWT配置
 type JWTConfig struct {
 	Secret             string `mapstructure:"secret"`
@@ -143,6 +150,26 @@ func (c *AppServerConfig) IsDBLogEnabled() bool {
 	return c.DB.LogLevel != "silent"
 }
 
+// HasSecretEncryptionKey 是否显式配置了应用密钥加密密钥
+func (c *AppServerConfig) HasSecretEncryptionKey() bool {
+	return c.Secret.EncryptionKey != ""
+}
+
+// GetSecretEncryptionKey 获取应用密钥加密密钥（AES-256，32字节）
+// 未配置时使用全局 JWT Secret 派生，保证开箱即用；生产环境必须单独配置 secret.encryption_key
+// （否则 JWT Secret 轮换后已加密的密钥将无法解密，且 JWT Secret 泄露即等于密钥泄露）
+func (c *AppServerConfig) GetSecretEncryptionKey() []byte {
+	key := c.Secret.EncryptionKey
+	if key == "" {
+		key = GetGlobalSharedConfig().JWT.Secret
+	}
+	if len(key) == 32 {
+		return []byte(key)
+	}
+	sum := sha256.Sum256([]byte(key))
+	return sum[:]
+}
+
 // GetDB 获取数据库配置
 func (c *AppServerConfig) GetDB() DBConfig {
 	return c.DB
@@ -0,0 +1,400 @@
+package query
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// 聚合函数
+const (
+	AggSum   = "sum"
+	AggAvg   = "avg"
+	AggMin   = "min"
+	AggMax   = "max"
+	AggCount = "count"
+)
+
+// 时间分桶（用于时间戳字段的分组）
+const (
+	BucketDay   = "day"   // 按天，结果形如 2024-01-15
+	BucketWeek  = "week"  // 按周（周一开始），结果为当周周一的日期
+	BucketMonth = "month" // 按月，结果形如 2024-01
+)
+
+const (
+	maxAggregateMetrics   = 16    // 单次请求最多的指标数
+	maxAggregateGroups    = 3     // 最多的分组字段数
+	defaultAggregateLimit = 1000  // 分组结果默认最多返回的行数
+	maxAggregateLimit     = 10000 // 分组结果最多返回的行数
+)
+
+// AggregateMetric 聚合指标
+type AggregateMetric struct {
+	Op    string `json:"op"`              // sum/avg/min/max/count
+	Field string `json:"field"`           // 字段名（数据库列名），count 可以为空或 *，表示统计行数
+	Alias string `json:"alias,omitempty"` // 结果中的名称，默认为 op_field（count(*) 为 count）
+}
+
+// Name 指标在结果中的名称
+func (m AggregateMetric) Name() string {
+	if m.Alias != "" {
+		return m.Alias
+	}
+	if m.Field == "" || m.Field == "*" {
+		return m.Op
+	}
+	return m.Op + "_" + m.Field
+}
+
+// AggregateGroup 分组字段
+type AggregateGroup struct {
+	Field  string `json:"field"`            // 字段名（数据库列名），同时也是结果中的名称
+	Bucket string `json:"bucket,omitempty"` // 时间分桶（day/week/month），只能用于时间戳字段
+}
+
+// AggregateReq 聚合请求
+type AggregateReq struct {
+	Metrics  []AggregateMetric `json:"metrics"`
+	GroupBy  []AggregateGroup  `json:"group_by,omitempty"`
+	Limit    int               `json:"limit,omitempty"`     // 分组结果最多返回的行数，默认 1000
+	TZOffset int               `json:"tz_offset,omitempty"` // 时间分桶使用的时区偏移（分钟），如东八区为 480
+}
+
+// AggregateResult 聚合结果
+// 没有分组时 Rows 只有一行（整个搜索结果的汇总），适合表格底部合计；有分组时按分组字段升序排列，适合图表
+type AggregateResult struct {
+	GroupBy   []string                 `json:"group_by,omitempty"`  // 分组字段名（Rows 中的 key）
+	Metrics   []string                 `json:"metrics"`             // 指标名（Rows 中的 key）
+	Rows      []map[string]interface{} `json:"rows"`                // 聚合结果
+	Truncated bool                     `json:"truncated,omitempty"` // 分组数超过 Limit，结果被截断
+}
+
+// HasAggregate 是否请求了聚合统计
+func (i *SearchFilterPageReq) HasAggregate() bool {
+	return len(i.Aggs) > 0
+}
+
+// GetAggregateReq 解析请求中的聚合参数，未请求聚合时返回 nil
+// aggs 格式：op:field[:alias]，如 sum:price、count:*、avg:score:平均分
+// group_by 格式：field[:bucket]，如 status、created_at:month
+func (i *SearchFilterPageReq) GetAggregateReq() *AggregateReq {
+	if !i.HasAggregate() {
+		return nil
+	}
+
+	req := &AggregateReq{TZOffset: i.TZOffset}
+	for _, agg := range splitParamValues(i.Aggs) {
+		parts := strings.SplitN(agg, ":", 3)
+		metric := AggregateMetric{Op: strings.ToLower(strings.TrimSpace(parts[0]))}
+		if len(parts) > 1 {
+			metric.Field = strings.TrimSpace(parts[1])
+		}
+		if len(parts) > 2 {
+			metric.Alias = strings.TrimSpace(parts[2])
+		}
+		req.Metrics = append(req.Metrics, metric)
+	}
+	for _, group := range splitParamValues(i.GroupBy) {
+		parts := strings.SplitN(group, ":", 2)
+		g := AggregateGroup{Field: strings.TrimSpace(parts[0])}
+		if len(parts) > 1 {
+			g.Bucket = strings.ToLower(strings.TrimSpace(parts[1]))
+		}
+		req.GroupBy = append(req.GroupBy, g)
+	}
+	return req
+}
+
+// splitParamValues 拆分多值参数，兼容 aggs=a&aggs=b 和 aggs=a,b 两种写法
+func splitParamValues(values []string) []string {
+	var result []string
+	for _, value := range values {
+		for _, v := range strings.Split(value, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				result = append(result, v)
+			}
+		}
+	}
+	return result
+}
+
+// Aggregate 在与表格搜索相同的条件（包括关键词检索）下做聚合统计（分页和排序参数不参与聚合）
+//
+// 使用示例：
+//
+//	result, err := query.Aggregate(db, &Order{}, pageInfo, &query.AggregateReq{
+//	    Metrics: []query.AggregateMetric{{Op: query.AggSum, Field: "amount"}},
+//	    GroupBy: []query.AggregateGroup{{Field: "created_at", Bucket: query.BucketMonth}},
+//	})
+//
+// req 为 nil 时使用 pageInfo 中的 aggs/group_by 参数
+func Aggregate(db *gorm.DB, model interface{}, pageInfo *SearchFilterPageReq, req *AggregateReq, configs ...*QueryConfig) (*AggregateResult, error) {
+	if pageInfo == nil {
+		pageInfo = new(SearchFilterPageReq)
+	}
+	if req == nil {
+		if req = pageInfo.GetAggregateReq(); req == nil {
+			return nil, fmt.Errorf("没有指定聚合指标")
+		}
+	}
+
+	dbClone := db.Session(&gorm.Session{})
+	if err := buildWhereConditions(&dbClone, pageInfo, configs...); err != nil {
+		return nil, err
+	}
+	dbClone, _, err := ApplyKeyword(dbClone, model, pageInfo)
+	if err != nil {
+		return nil, err
+	}
+	var config *QueryConfig
+	if len(configs) > 0 {
+		config = mergeConfigs(configs...)
+	}
+	return runAggregate(dbClone, model, req, config)
+}
+
+// runAggregate 在已经应用搜索条件的 db 上执行聚合
+func runAggregate(db *gorm.DB, model interface{}, req *AggregateReq, config *QueryConfig) (*AggregateResult, error) {
+	if len(req.Metrics) == 0 {
+		return nil, fmt.Errorf("没有指定聚合指标")
+	}
+	if len(req.Metrics) > maxAggregateMetrics {
+		return nil, fmt.Errorf("聚合指标不能超过 %d 个", maxAggregateMetrics)
+	}
+	if len(req.GroupBy) > maxAggregateGroups {
+		return nil, fmt.Errorf("分组字段不能超过 %d 个", maxAggregateGroups)
+	}
+
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(model); err != nil {
+		return nil, fmt.Errorf("解析模型失败: %w", err)
+	}
+
+	result := &AggregateResult{}
+	selects := make([]string, 0, len(req.GroupBy)+len(req.Metrics))
+	groups := make([]string, 0, len(req.GroupBy))
+	seen := make(map[string]bool)
+
+	for i, group := range req.GroupBy {
+		field, err := aggregateField(stmt.Schema, group.Field, config)
+		if err != nil {
+			return nil, err
+		}
+		if seen[group.Field] {
+			return nil, fmt.Errorf("分组字段重复: %s", group.Field)
+		}
+		seen[group.Field] = true
+
+		expr := SafeColumnName(field.DBName)
+		if group.Bucket != "" {
+			if expr, err = timeBucketExpr(db.Dialector.Name(), field, group.Bucket, req.TZOffset); err != nil {
+				return nil, err
+			}
+		}
+		alias := fmt.Sprintf("g%d", i)
+		selects = append(selects, expr+" AS "+alias)
+		groups = append(groups, alias)
+		result.GroupBy = append(result.GroupBy, group.Field)
+	}
+
+	for i, metric := range req.Metrics {
+		expr, err := metricExpr(stmt.Schema, metric, config)
+		if err != nil {
+			return nil, err
+		}
+		name := metric.Name()
+		if seen[name] {
+			return nil, fmt.Errorf("聚合结果名称重复: %s", name)
+		}
+		seen[name] = true
+		selects = append(selects, fmt.Sprintf("%s AS m%d", expr, i))
+		result.Metrics = append(result.Metrics, name)
+	}
+
+	limit := req.Limit
+	if limit <= 0 {
+		limit = defaultAggregateLimit
+	}
+	if limit > maxAggregateLimit {
+		limit = maxAggregateLimit
+	}
+
+	query := db.Session(&gorm.Session{}).Model(model).Select(strings.Join(selects, ", "))
+	if len(groups) > 0 {
+		query = query.Group(strings.Join(groups, ", ")).Order(strings.Join(groups, ", ")).Limit(limit + 1)
+	}
+	rows, err := query.Rows()
+	if err != nil {
+		return nil, fmt.Errorf("聚合查询失败: %w", err)
+	}
+	defer rows.Close()
+
+	result.Rows = make([]map[string]interface{}, 0)
+	for rows.Next() {
+		values := make([]interface{}, len(selects))
+		ptrs := make([]interface{}, len(selects))
+		for i := range values {
+			ptrs[i] = &values[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return nil, fmt.Errorf("读取聚合结果失败: %w", err)
+		}
+
+		row := make(map[string]interface{}, len(selects))
+		for i, name := range result.GroupBy {
+			row[name] = normalizeAggregateValue(values[i], false)
+		}
+		for i, name := range result.Metrics {
+			row[name] = normalizeAggregateValue(values[len(result.GroupBy)+i], true)
+		}
+		result.Rows = append(result.Rows, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("读取聚合结果失败: %w", err)
+	}
+
+	if len(result.Rows) > limit {
+		result.Rows = result.Rows[:limit]
+		result.Truncated = true
+	}
+	return result, nil
+}
+
+// aggregateField 校验聚合/分组字段：必须是模型中的字段，并遵守查询配置的黑白名单
+func aggregateField(s *schema.Schema, name string, config *QueryConfig) (*schema.Field, error) {
+	if name == "" || !SafeColumn(name) {
+		return nil, fmt.Errorf("无效的字段名：%s", name)
+	}
+	if config != nil {
+		if _, ok := config.Blacklist[name]; ok {
+			return nil, fmt.Errorf("字段 %s 被禁止查询", name)
+		}
+		if len(config.Fields) > 0 {
+			if _, ok := config.Fields[name]; !ok {
+				return nil, fmt.Errorf("不允许查询字段: %s", name)
+			}
+		}
+	}
+	field := s.LookUpField(name)
+	if field == nil || field.DBName == "" {
+		return nil, fmt.Errorf("字段不存在: %s", name)
+	}
+	return field, nil
+}
+
+// metricExpr 构建聚合指标的 SQL 表达式
+func metricExpr(s *schema.Schema, metric AggregateMetric, config *QueryConfig) (string, error) {
+	switch metric.Op {
+	case AggSum, AggAvg, AggMin, AggMax, AggCount:
+	default:
+		return "", fmt.Errorf("不支持的聚合函数: %s", metric.Op)
+	}
+	if metric.Op == AggCount && (metric.Field == "" || metric.Field == "*") {
+		return "COUNT(*)", nil
+	}
+	field, err := aggregateField(s, metric.Field, config)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s(%s)", strings.ToUpper(metric.Op), SafeColumnName(field.DBName)), nil
+}
+
+// timeBucketExpr 构建时间分桶的 SQL 表达式
+// 整数字段按 Unix 时间戳处理：默认毫秒（timestamp 组件的存储方式），gorm 标签为 autoCreateTime/autoUpdateTime（秒）时按秒
+func timeBucketExpr(dialect string, field *schema.Field, bucket string, tzOffset int) (string, error) {
+	switch bucket {
+	case BucketDay, BucketWeek, BucketMonth:
+	default:
+		return "", fmt.Errorf("不支持的时间分桶: %s", bucket)
+	}
+
+	column := SafeColumnName(field.DBName)
+	var seconds string
+	switch field.DataType {
+	case schema.Time:
+	case schema.Int, schema.Uint:
+		switch timestampUnit(field) {
+		case schema.UnixSecond:
+			seconds = column
+		case schema.UnixNanosecond:
+			seconds = "(" + column + " / 1000000000)"
+		default:
+			seconds = "(" + column + " / 1000)"
+		}
+	default:
+		return "", fmt.Errorf("字段 %s 不是时间戳字段，不能按时间分桶", field.DBName)
+	}
+
+	switch dialect {
+	case "sqlite":
+		var dt string
+		modifier := fmt.Sprintf("'%+d minutes'", tzOffset)
+		if seconds != "" {
+			dt = fmt.Sprintf("datetime(CAST(%s AS INTEGER), 'unixepoch', %s)", seconds, modifier)
+		} else {
+			dt = fmt.Sprintf("datetime(%s, %s)", column, modifier)
+		}
+		switch bucket {
+		case BucketDay:
+			return "strftime('%Y-%m-%d', " + dt + ")", nil
+		case BucketWeek:
+			// weekday 0 跳到本周日（当天是周日则不变），再往前 6 天即为周一
+			return "date(" + dt + ", 'weekday 0', '-6 days')", nil
+		default:
+			return "strftime('%Y-%m', " + dt + ")", nil
+		}
+	case "mysql":
+		var dt string
+		if seconds != "" {
+			// 不使用 FROM_UNIXTIME，避免受会话时区影响
+			dt = fmt.Sprintf("DATE_ADD('1970-01-01 00:00:00', INTERVAL FLOOR(%s) + %d SECOND)", seconds, tzOffset*60)
+		} else {
+			// DATETIME 没有时区信息，按存储的本地时间分桶
+			dt = column
+		}
+		switch bucket {
+		case BucketDay:
+			return "DATE_FORMAT(" + dt + ", '%Y-%m-%d')", nil
+		case BucketWeek:
+			return "DATE_FORMAT(DATE_SUB(" + dt + ", INTERVAL WEEKDAY(" + dt + ") DAY), '%Y-%m-%d')", nil
+		default:
+			return "DATE_FORMAT(" + dt + ", '%Y-%m')", nil
+		}
+	default:
+		return "", fmt.Errorf("数据库 %s 不支持时间分桶", dialect)
+	}
+}
+
+// timestampUnit Unix 时间戳字段的单位
+func timestampUnit(field *schema.Field) schema.TimeType {
+	if field.AutoCreateTime != 0 {
+		return field.AutoCreateTime
+	}
+	if field.AutoUpdateTime != 0 {
+		return field.AutoUpdateTime
+	}
+	return schema.UnixMillisecond
+}
+
+// normalizeAggregateValue 统一不同数据库驱动返回的类型：[]byte 转字符串，指标值转为数字
+func normalizeAggregateValue(v interface{}, numeric bool) interface{} {
+	if b, ok := v.([]byte); ok {
+		v = string(b)
+	}
+	if !numeric {
+		return v
+	}
+	if s, ok := v.(string); ok {
+		if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return n
+		}
+		if f, err := strconv.ParseFloat(s, 64); err == nil {
+			return f
+		}
+	}
+	return v
+}
@@ -0,0 +1,158 @@
+package query
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type aggOrder struct {
+	ID        int64
+	Status    string
+	Amount    int
+	CreatedAt int64 `gorm:"autoCreateTime:milli"`
+	PaidAt    time.Time
+}
+
+func newAggregateTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db := newTestDB(t, &aggOrder{})
+	// 2024-01-29 是周一；22:00 UTC 在东八区已经是第二天
+	base := time.Date(2024, 1, 29, 22, 0, 0, 0, time.UTC)
+	orders := []aggOrder{
+		{ID: 1, Status: "paid", Amount: 10, PaidAt: base},
+		{ID: 2, Status: "paid", Amount: 20, PaidAt: base.Add(24 * time.Hour)},
+		{ID: 3, Status: "new", Amount: 5, PaidAt: base.Add(3 * 24 * time.Hour)},
+		{ID: 4, Status: "paid", Amount: 40, PaidAt: base.Add(7 * 24 * time.Hour)},
+		{ID: 5, Status: "refund", Amount: 7, PaidAt: base.Add(-40 * 24 * time.Hour)},
+	}
+	for i := range orders {
+		orders[i].CreatedAt = orders[i].PaidAt.UnixMilli()
+	}
+	if err := db.Create(&orders).Error; err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+	return db
+}
+
+func TestAggregateTotals(t *testing.T) {
+	db := newAggregateTestDB(t)
+
+	// 合计按整个搜索结果统计，与分页无关
+	req := &SearchFilterPageReq{PageSize: 1, NotEq: []string{"status:refund"}, Aggs: []string{"sum:amount,count:*,avg:amount:平均金额", "max:amount"}}
+	var orders []aggOrder
+	table, err := AutoPaginateTable(context.Background(), db, &aggOrder{}, &orders, req)
+	if err != nil {
+		t.Fatalf("paginate: %v", err)
+	}
+	if len(orders) != 1 || table.Aggregates == nil {
+		t.Fatalf("unexpected table: %+v", table)
+	}
+	want := []map[string]interface{}{{"sum_amount": int64(75), "count": int64(4), "平均金额": 18.75, "max_amount": int64(40)}}
+	if !reflect.DeepEqual(table.Aggregates.Rows, want) {
+		t.Fatalf("rows = %v, want %v", table.Aggregates.Rows, want)
+	}
+	if !reflect.DeepEqual(table.Aggregates.Metrics, []string{"sum_amount", "count", "平均金额", "max_amount"}) {
+		t.Fatalf("metrics = %v", table.Aggregates.Metrics)
+	}
+
+	// 游标分页同样返回聚合结果
+	req.PageMode = PageModeCursor
+	orders = nil
+	table, err = AutoPaginateTable(context.Background(), db, &aggOrder{}, &orders, req)
+	if err != nil || table.Aggregates == nil || table.Aggregates.Rows[0]["sum_amount"] != int64(75) {
+		t.Fatalf("cursor aggregates: %+v, %v", table, err)
+	}
+}
+
+func TestAggregateGroupBy(t *testing.T) {
+	db := newAggregateTestDB(t)
+
+	result, err := Aggregate(db, &aggOrder{}, &SearchFilterPageReq{GroupBy: []string{"status"}}, &AggregateReq{
+		Metrics: []AggregateMetric{{Op: AggSum, Field: "amount"}, {Op: AggCount}},
+		GroupBy: []AggregateGroup{{Field: "status"}},
+	})
+	if err != nil {
+		t.Fatalf("aggregate: %v", err)
+	}
+	want := []map[string]interface{}{
+		{"status": "new", "sum_amount": int64(5), "count": int64(1)},
+		{"status": "paid", "sum_amount": int64(70), "count": int64(3)},
+		{"status": "refund", "sum_amount": int64(7), "count": int64(1)},
+	}
+	if !reflect.DeepEqual(result.Rows, want) {
+		t.Fatalf("rows = %v, want %v", result.Rows, want)
+	}
+
+	result, err = Aggregate(db, &aggOrder{}, &SearchFilterPageReq{Aggs: []string{"count"}, GroupBy: []string{"status"}}, &AggregateReq{
+		Metrics: []AggregateMetric{{Op: AggCount}},
+		GroupBy: []AggregateGroup{{Field: "status"}},
+		Limit:   2,
+	})
+	if err != nil || len(result.Rows) != 2 || !result.Truncated {
+		t.Fatalf("limit: %+v, %v", result, err)
+	}
+}
+
+func TestAggregateTimeBucket(t *testing.T) {
+	db := newAggregateTestDB(t)
+
+	cases := []struct {
+		group    string
+		tzOffset int
+		want     []string
+	}{
+		{"created_at:day", 0, []string{"2023-12-20", "2024-01-29", "2024-01-30", "2024-02-01", "2024-02-05"}},
+		{"created_at:day", 480, []string{"2023-12-21", "2024-01-30", "2024-01-31", "2024-02-02", "2024-02-06"}},
+		{"created_at:week", 0, []string{"2023-12-18", "2024-01-29", "2024-02-05"}},
+		{"created_at:month", 0, []string{"2023-12", "2024-01", "2024-02"}},
+		{"created_at:month", 480, []string{"2023-12", "2024-01", "2024-02"}},
+		{"paid_at:week", 0, []string{"2023-12-18", "2024-01-29", "2024-02-05"}},
+		{"paid_at:day", 480, []string{"2023-12-21", "2024-01-30", "2024-01-31", "2024-02-02", "2024-02-06"}},
+	}
+	for _, c := range cases {
+		req := &SearchFilterPageReq{Aggs: []string{"count:*"}, GroupBy: []string{c.group}, TZOffset: c.tzOffset}
+		result, err := Aggregate(db, &aggOrder{}, req, nil)
+		if err != nil {
+			t.Fatalf("%s: %v", c.group, err)
+		}
+		var got []string
+		for _, row := range result.Rows {
+			got = append(got, row[result.GroupBy[0]].(string))
+		}
+		if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%s tz=%d: got %v, want %v", c.group, c.tzOffset, got, c.want)
+		}
+	}
+}
+
+func TestAggregateValidation(t *testing.T) {
+	db := newAggregateTestDB(t)
+
+	config := NewQueryConfig()
+	config.AllowField("status", "eq")
+	config.AllowField("amount", "gt")
+	config.DenyField("amount")
+
+	for _, req := range []*SearchFilterPageReq{
+		{Aggs: []string{"median:amount"}},
+		{Aggs: []string{"sum:amount); DROP TABLE agg_orders; --"}},
+		{Aggs: []string{"sum:unknown"}},
+		{Aggs: []string{"sum:amount"}},
+		{Aggs: []string{"count"}, GroupBy: []string{"paid_at"}},
+		{Aggs: []string{"count"}, GroupBy: []string{"status:month"}},
+		{Aggs: []string{"count"}, GroupBy: []string{"status:year"}},
+		{Aggs: []string{"count,count"}},
+	} {
+		if _, err := Aggregate(db, &aggOrder{}, req, nil, config); err == nil {
+			t.Errorf("aggs=%v group_by=%v: expected error", req.Aggs, req.GroupBy)
+		}
+	}
+
+	if _, err := Aggregate(db, &aggOrder{}, &SearchFilterPageReq{Aggs: []string{"count"}, GroupBy: []string{"status"}}, nil, config); err != nil {
+		t.Fatalf("allowed group: %v", err)
+	}
+}
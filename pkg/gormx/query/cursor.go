@@ -0,0 +1,358 @@
+package query
+
+import (
+	"context"
+	"crypto/sha1"
+	"database/sql/driver"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// 分页模式
+const (
+	PageModePage   = "page"   // 页码分页（默认）：COUNT(*) + OFFSET
+	PageModeCursor = "cursor" // 游标分页：按排序键定位，不使用 OFFSET
+)
+
+// 游标分页的总数统计方式
+const (
+	CountModeNone   = "none"   // 不统计总数（默认），TotalCount 返回 -1
+	CountModeApprox = "approx" // 最多统计 approxCountLimit 条，超过时 TotalApprox 为 true
+	CountModeExact  = "exact"  // 精确统计（大表较慢）
+)
+
+// approxCountLimit 近似统计时最多数到的行数
+const approxCountLimit = 10000
+
+// IsCursorMode 是否使用游标分页
+func (i *SearchFilterPageReq) IsCursorMode() bool {
+	return i.PageMode == PageModeCursor || i.Cursor != ""
+}
+
+// CursorPageResult 游标分页结果
+type CursorPageResult struct {
+	NextCursor  string // 下一页游标，为空表示没有下一页
+	PrevCursor  string // 上一页游标，为空表示没有上一页
+	TotalCount  int64  // 总数，未统计时为 -1
+	TotalApprox bool   // TotalCount 是否为近似值（实际数量不少于 TotalCount）
+	PageSize    int
+}
+
+// pageCursor 游标内容（base64url 编码的 JSON，对调用方不透明）
+type pageCursor struct {
+	Sign   string        `json:"s"`           // 排序键签名，排序条件变化后游标失效
+	Prev   bool          `json:"p,omitempty"` // 是否向前翻页
+	Values []cursorValue `json:"v"`           // 排序键的值（最后一个是主键）
+}
+
+// cursorValue 游标中的一个值，时间类型单独标记以便还原
+type cursorValue struct {
+	Time  *time.Time  `json:"t,omitempty"`
+	Value interface{} `json:"v,omitempty"`
+}
+
+// sortKey 排序键
+type sortKey struct {
+	Column string
+	Desc   bool
+}
+
+// PaginateCursor 游标分页查询，db 需要已经应用搜索条件（见 ApplySearchConditions）
+// 排序键为 Sorts 中的字段加上主键，保证任意排序组合下顺序稳定；NULL 按 MySQL/SQLite 的规则排在升序的最前面
+func PaginateCursor(db *gorm.DB, model interface{}, dest interface{}, pageInfo *SearchFilterPageReq) (*CursorPageResult, error) {
+	if pageInfo == nil {
+		pageInfo = &SearchFilterPageReq{PageMode: PageModeCursor}
+	}
+	destValue := reflect.ValueOf(dest)
+	if destValue.Kind() != reflect.Ptr || destValue.Elem().Kind() != reflect.Slice {
+		return nil, fmt.Errorf("游标分页的结果必须是切片指针")
+	}
+
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(model); err != nil {
+		return nil, fmt.Errorf("解析模型失败: %w", err)
+	}
+	keys, err := cursorSortKeys(pageInfo.Sorts, stmt.Schema)
+	if err != nil {
+		return nil, err
+	}
+	sign := cursorSign(keys)
+
+	var cursor *pageCursor
+	if pageInfo.Cursor != "" {
+		if cursor, err = decodeCursor(pageInfo.Cursor); err != nil {
+			return nil, err
+		}
+		if cursor.Sign != sign || len(cursor.Values) != len(keys) {
+			return nil, fmt.Errorf("游标与当前排序条件不匹配，请从第一页重新查询")
+		}
+	}
+	backward := cursor != nil && cursor.Prev
+
+	// 向前翻页时反转排序方向查询，再把结果反转回来
+	queryKeys := keys
+	if backward {
+		queryKeys = make([]sortKey, len(keys))
+		for i, key := range keys {
+			queryKeys[i] = sortKey{Column: key.Column, Desc: !key.Desc}
+		}
+	}
+
+	pageSize := pageInfo.GetLimit()
+	result := &CursorPageResult{TotalCount: -1, PageSize: pageSize}
+	if err := countForCursor(db, model, pageInfo.CountMode, result); err != nil {
+		return nil, err
+	}
+
+	query := db.Session(&gorm.Session{})
+	if cursor != nil {
+		sql, args := keysetCondition(queryKeys, cursor.Values)
+		query = query.Where(sql, args...)
+	}
+	orders := make([]string, len(queryKeys))
+	for i, key := range queryKeys {
+		orders[i] = SafeColumnName(key.Column) + " ASC"
+		if key.Desc {
+			orders[i] = SafeColumnName(key.Column) + " DESC"
+		}
+	}
+	if err := query.Order(strings.Join(orders, ", ")).Limit(pageSize + 1).Find(dest).Error; err != nil {
+		return nil, fmt.Errorf("游标分页查询数据失败: %w", err)
+	}
+
+	rows := destValue.Elem()
+	hasMore := rows.Len() > pageSize
+	if hasMore {
+		rows.Set(rows.Slice(0, pageSize))
+	}
+	if backward {
+		swap := reflect.Swapper(rows.Interface())
+		for i, j := 0, rows.Len()-1; i < j; i, j = i+1, j-1 {
+			swap(i, j)
+		}
+	}
+	if rows.Len() == 0 {
+		return result, nil
+	}
+
+	ctx := db.Statement.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	encodeAt := func(index int, prev bool) (string, error) {
+		values, err := rowCursorValues(ctx, stmt.Schema, keys, rows.Index(index))
+		if err != nil {
+			return "", err
+		}
+		return encodeCursor(&pageCursor{Sign: sign, Prev: prev, Values: values})
+	}
+	// 向后翻页：还有更多数据才有下一页，不是第一页才有上一页；向前翻页反之
+	if (!backward && hasMore) || backward {
+		if result.NextCursor, err = encodeAt(rows.Len()-1, false); err != nil {
+			return nil, err
+		}
+	}
+	if (!backward && cursor != nil) || (backward && hasMore) {
+		if result.PrevCursor, err = encodeAt(0, true); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// cursorSortKeys 解析排序条件并追加主键，保证排序唯一
+func cursorSortKeys(sorts string, sch *schema.Schema) ([]sortKey, error) {
+	primaryKey := "id"
+	if sch != nil && sch.PrioritizedPrimaryField != nil {
+		primaryKey = sch.PrioritizedPrimaryField.DBName
+	}
+
+	var keys []sortKey
+	hasPrimaryKey := false
+	if sorts != "" {
+		for _, part := range strings.Split(sorts, ",") {
+			fieldOrder := strings.Split(normalizeSortField(part), ":")
+			if len(fieldOrder) != 2 {
+				return nil, fmt.Errorf("排序字段格式错误：%s，应为 field:order 格式", part)
+			}
+			column := strings.TrimSpace(fieldOrder[0])
+			if !SafeColumn(column) || column == "" {
+				return nil, fmt.Errorf("无效的排序字段名：%s", column)
+			}
+			order := strings.ToLower(strings.TrimSpace(fieldOrder[1]))
+			if order != "asc" && order != "desc" {
+				return nil, fmt.Errorf("无效的排序方向：%s", order)
+			}
+			if sch != nil && sch.LookUpField(column) == nil {
+				return nil, fmt.Errorf("游标分页不支持按 %s 排序：模型中没有该字段", column)
+			}
+			keys = append(keys, sortKey{Column: column, Desc: order == "desc"})
+			if column == primaryKey {
+				hasPrimaryKey = true
+				break // 主键之后的排序字段没有意义
+			}
+		}
+	}
+	if !hasPrimaryKey {
+		keys = append(keys, sortKey{Column: primaryKey})
+	}
+	return keys, nil
+}
+
+// keysetCondition 生成"排在游标之后"的条件：
+// (k1 > v1) OR (k1 = v1 AND k2 > v2) OR ...，降序字段使用 <，并处理 NULL
+func keysetCondition(keys []sortKey, values []cursorValue) (string, []interface{}) {
+	var branches []string
+	var args []interface{}
+	for i := range keys {
+		var parts []string
+		var branchArgs []interface{}
+		for j := 0; j < i; j++ {
+			column := SafeColumnName(keys[j].Column)
+			if value := values[j].raw(); value == nil {
+				parts = append(parts, column+" IS NULL")
+			} else {
+				parts = append(parts, column+" = ?")
+				branchArgs = append(branchArgs, value)
+			}
+		}
+
+		column := SafeColumnName(keys[i].Column)
+		value := values[i].raw()
+		switch {
+		case !keys[i].Desc && value != nil:
+			parts = append(parts, column+" > ?")
+			branchArgs = append(branchArgs, value)
+		case !keys[i].Desc:
+			parts = append(parts, column+" IS NOT NULL")
+		case value != nil:
+			parts = append(parts, "("+column+" < ? OR "+column+" IS NULL)")
+			branchArgs = append(branchArgs, value)
+		default:
+			continue // 降序时 NULL 排在最后，之后没有更多数据
+		}
+		branches = append(branches, "("+strings.Join(parts, " AND ")+")")
+		args = append(args, branchArgs...)
+	}
+	if len(branches) == 0 {
+		return "1 = 0", nil
+	}
+	return "(" + strings.Join(branches, " OR ") + ")", args
+}
+
+// rowCursorValues 读取一行数据的排序键值
+func rowCursorValues(ctx context.Context, sch *schema.Schema, keys []sortKey, row reflect.Value) ([]cursorValue, error) {
+	for row.Kind() == reflect.Ptr {
+		row = row.Elem()
+	}
+	values := make([]cursorValue, len(keys))
+	for i, key := range keys {
+		field := sch.LookUpField(key.Column)
+		if field == nil {
+			return nil, fmt.Errorf("游标分页不支持按 %s 排序：模型中没有该字段", key.Column)
+		}
+		value, zero := field.ValueOf(ctx, row)
+		if valuer, ok := value.(driver.Valuer); ok {
+			v, err := valuer.Value()
+			if err != nil {
+				return nil, fmt.Errorf("读取排序字段 %s 失败: %w", key.Column, err)
+			}
+			value = v
+		}
+		if zero && field.FieldType.Kind() == reflect.Ptr {
+			value = nil
+		}
+		switch v := value.(type) {
+		case time.Time:
+			values[i] = cursorValue{Time: &v}
+		default:
+			values[i] = cursorValue{Value: v}
+		}
+	}
+	return values, nil
+}
+
+func (v cursorValue) raw() interface{} {
+	if v.Time != nil {
+		return *v.Time
+	}
+	if n, ok := v.Value.(json.Number); ok {
+		if i, err := n.Int64(); err == nil {
+			return i
+		}
+		if f, err := n.Float64(); err == nil {
+			return f
+		}
+	}
+	return v.Value
+}
+
+// cursorSign 排序键签名
+func cursorSign(keys []sortKey) string {
+	var sb strings.Builder
+	for _, key := range keys {
+		sb.WriteString(key.Column)
+		if key.Desc {
+			sb.WriteString(":desc,")
+		} else {
+			sb.WriteString(":asc,")
+		}
+	}
+	sum := sha1.Sum([]byte(sb.String()))
+	return hex.EncodeToString(sum[:4])
+}
+
+func encodeCursor(cursor *pageCursor) (string, error) {
+	data, err := json.Marshal(cursor)
+	if err != nil {
+		return "", fmt.Errorf("生成游标失败: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func decodeCursor(s string) (*pageCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("无效的游标")
+	}
+	var cursor pageCursor
+	decoder := json.NewDecoder(strings.NewReader(string(data)))
+	decoder.UseNumber()
+	if err := decoder.Decode(&cursor); err != nil {
+		return nil, fmt.Errorf("无效的游标")
+	}
+	return &cursor, nil
+}
+
+// countForCursor 按统计方式统计总数
+func countForCursor(db *gorm.DB, model interface{}, countMode string, result *CursorPageResult) error {
+	switch countMode {
+	case "", CountModeNone:
+		return nil
+	case CountModeExact:
+		if err := db.Session(&gorm.Session{}).Model(model).Count(&result.TotalCount).Error; err != nil {
+			return fmt.Errorf("分页查询统计总数失败: %w", err)
+		}
+		return nil
+	case CountModeApprox:
+		// 子查询最多取 approxCountLimit+1 行再计数，避免全表扫描
+		limited := db.Session(&gorm.Session{}).Model(model).Select("1").Limit(approxCountLimit + 1)
+		if err := db.Session(&gorm.Session{NewDB: true}).Table("(?) AS t", limited).Count(&result.TotalCount).Error; err != nil {
+			return fmt.Errorf("分页查询统计总数失败: %w", err)
+		}
+		if result.TotalCount > approxCountLimit {
+			result.TotalCount = approxCountLimit
+			result.TotalApprox = true
+		}
+		return nil
+	}
+	return fmt.Errorf("无效的总数统计方式：%s", countMode)
+}
@@ -0,0 +1,140 @@
+package query
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type cursorLog struct {
+	ID        int64
+	Level     *string
+	Score     int
+	CreatedAt time.Time
+}
+
+func newCursorTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db := newTestDB(t, &cursorLog{})
+	levels := []string{"error", "info", "warn"}
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	var logs []cursorLog
+	for i := 1; i <= 23; i++ {
+		log := cursorLog{ID: int64(i), Score: i % 4, CreatedAt: base.Add(time.Duration(i%5) * time.Hour)}
+		if i%3 != 0 {
+			log.Level = &levels[i%len(levels)]
+		}
+		logs = append(logs, log)
+	}
+	if err := db.Create(&logs).Error; err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+	return db
+}
+
+func logIDs(logs []cursorLog) []int64 {
+	ids := make([]int64, len(logs))
+	for i, log := range logs {
+		ids[i] = log.ID
+	}
+	return ids
+}
+
+func TestCursorPagination(t *testing.T) {
+	db := newCursorTestDB(t)
+	for _, sorts := range []string{"", "id:desc", "score:desc,created_at:asc", "level:asc,score:desc", "level:desc", "created_at:desc,id:asc"} {
+		t.Run(sorts, func(t *testing.T) {
+			// 页码分页的结果作为期望值
+			var want []cursorLog
+			order := "id ASC"
+			if sorts != "" {
+				order = (&SearchFilterPageReq{Sorts: sorts}).GetSorts() + ", id ASC"
+			}
+			if err := db.Order(order).Find(&want).Error; err != nil {
+				t.Fatal(err)
+			}
+
+			var pages [][]int64
+			req := &SearchFilterPageReq{PageMode: PageModeCursor, PageSize: 5, Sorts: sorts}
+			var prevCursor string
+			for {
+				var logs []cursorLog
+				result, err := AutoPaginateTable(context.Background(), db, &cursorLog{}, &logs, req)
+				if err != nil {
+					t.Fatalf("AutoPaginateTable() error = %v", err)
+				}
+				if (len(pages) == 0) != (result.PrevCursor == "") {
+					t.Fatalf("page %d prev_cursor = %q", len(pages), result.PrevCursor)
+				}
+				pages = append(pages, logIDs(logs))
+				prevCursor = result.PrevCursor
+				if result.NextCursor == "" {
+					break
+				}
+				req = &SearchFilterPageReq{Cursor: result.NextCursor, PageSize: 5, Sorts: sorts}
+			}
+			var got []int64
+			for _, page := range pages {
+				got = append(got, page...)
+			}
+			if fmt.Sprint(got) != fmt.Sprint(logIDs(want)) {
+				t.Fatalf("forward = %v, want %v", got, logIDs(want))
+			}
+
+			// 从最后一页向前翻到第一页
+			for i := len(pages) - 2; i >= 0; i-- {
+				var logs []cursorLog
+				req := &SearchFilterPageReq{Cursor: prevCursor, PageSize: 5, Sorts: sorts}
+				result, err := AutoPaginateTable(context.Background(), db, &cursorLog{}, &logs, req)
+				if err != nil {
+					t.Fatalf("AutoPaginateTable() error = %v", err)
+				}
+				if fmt.Sprint(logIDs(logs)) != fmt.Sprint(pages[i]) {
+					t.Fatalf("backward page %d = %v, want %v", i, logIDs(logs), pages[i])
+				}
+				if result.NextCursor == "" || (i == 0) != (result.PrevCursor == "") {
+					t.Fatalf("backward page %d cursors = %q, %q", i, result.PrevCursor, result.NextCursor)
+				}
+				prevCursor = result.PrevCursor
+			}
+		})
+	}
+}
+
+func TestCursorPaginationCount(t *testing.T) {
+	db := newCursorTestDB(t)
+	var logs []cursorLog
+	req := &SearchFilterPageReq{PageMode: PageModeCursor, PageSize: 5, Gte: []string{"score:2"}}
+	result, err := AutoPaginateTable(context.Background(), db, &cursorLog{}, &logs, req)
+	if err != nil {
+		t.Fatalf("AutoPaginateTable() error = %v", err)
+	}
+	if result.TotalCount != -1 {
+		t.Fatalf("TotalCount = %d, want -1", result.TotalCount)
+	}
+
+	for _, mode := range []string{CountModeExact, CountModeApprox} {
+		req := &SearchFilterPageReq{PageMode: PageModeCursor, PageSize: 5, Gte: []string{"score:2"}, CountMode: mode}
+		result, err := AutoPaginateTable(context.Background(), db, &cursorLog{}, &logs, req)
+		if err != nil {
+			t.Fatalf("AutoPaginateTable() error = %v", err)
+		}
+		if result.TotalCount != 12 || result.TotalApprox {
+			t.Fatalf("%s TotalCount = %d approx = %v, want 12", mode, result.TotalCount, result.TotalApprox)
+		}
+	}
+
+	first, err := AutoPaginateTable(context.Background(), db, &cursorLog{}, &logs, &SearchFilterPageReq{PageMode: PageModeCursor, PageSize: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := AutoPaginateTable(context.Background(), db, &cursorLog{}, &logs, &SearchFilterPageReq{Cursor: first.NextCursor, Sorts: "score:desc"}); err == nil {
+		t.Fatal("cursor used with different sorts should be rejected")
+	}
+	if _, err := AutoPaginateTable(context.Background(), db, &cursorLog{}, &logs, &SearchFilterPageReq{Cursor: "bad"}); err == nil {
+		t.Fatal("invalid cursor should be rejected")
+	}
+}
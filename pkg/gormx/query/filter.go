@@ -0,0 +1,538 @@
+package query
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// maxFilterDepth 过滤表达式最大嵌套层数
+	maxFilterDepth = 8
+	// maxFilterConditions 过滤表达式最多包含的条件数
+	maxFilterConditions = 64
+)
+
+// FilterNode 嵌套过滤表达式（JSON AST）
+// 分组节点使用 And/Or/Not 之一，叶子节点使用 Field/Op/Value，操作符与扁平参数一致：
+// eq/not_eq/like/not_like/in/not_in/contains/gt/gte/lt/lte
+//
+// 示例：(priority=高 AND created_at>X) OR overdue=true
+//
+//	{"or":[{"and":[{"field":"priority","op":"eq","value":"高"},{"field":"created_at","op":"gt","value":1700000000}]},{"field":"overdue","op":"eq","value":true}]}
+//
+// 对应的紧凑 URL 格式：
+//
+//	or(and(eq(priority,高),gt(created_at,1700000000)),eq(overdue,true))
+type FilterNode struct {
+	And   []*FilterNode `json:"and,omitempty"`
+	Or    []*FilterNode `json:"or,omitempty"`
+	Not   *FilterNode   `json:"not,omitempty"`
+	Field string        `json:"field,omitempty"`
+	Op    string        `json:"op,omitempty"`
+	Value interface{}   `json:"value,omitempty"` // in/not_in/contains 使用数组
+}
+
+// And 创建 AND 分组
+func And(nodes ...*FilterNode) *FilterNode {
+	return &FilterNode{And: nodes}
+}
+
+// Or 创建 OR 分组
+func Or(nodes ...*FilterNode) *FilterNode {
+	return &FilterNode{Or: nodes}
+}
+
+// Not 创建 NOT 分组
+func Not(node *FilterNode) *FilterNode {
+	return &FilterNode{Not: node}
+}
+
+// Cond 创建条件，如 Cond("status", "eq", "待处理")、Cond("id", "in", []int{1, 2})
+func Cond(field, op string, value interface{}) *FilterNode {
+	return &FilterNode{Field: field, Op: op, Value: value}
+}
+
+// filterOperators 过滤表达式支持的操作符 -> 是否取多个值
+var filterOperators = map[string]bool{
+	"eq": false, "not_eq": false, "like": false, "not_like": false,
+	"gt": false, "gte": false, "lt": false, "lte": false,
+	"in": true, "not_in": true, "contains": true,
+}
+
+// FilterParam 过滤表达式参数
+// URL 中使用紧凑格式；JSON 请求体中可以是紧凑格式字符串，也可以直接是 JSON AST 对象
+type FilterParam string
+
+// UnmarshalJSON 同时接受字符串和 JSON 对象
+func (p *FilterParam) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	switch {
+	case len(data) == 0 || string(data) == "null":
+		*p = ""
+	case data[0] == '"':
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		*p = FilterParam(s)
+	case data[0] == '{':
+		var buf bytes.Buffer
+		if err := json.Compact(&buf, data); err != nil {
+			return err
+		}
+		*p = FilterParam(buf.String())
+	default:
+		return fmt.Errorf("filter 必须是字符串或对象")
+	}
+	return nil
+}
+
+// NewFilterParam 把过滤表达式转换为紧凑格式参数
+func NewFilterParam(node *FilterNode) FilterParam {
+	if node == nil {
+		return ""
+	}
+	return FilterParam(node.String())
+}
+
+// Parse 解析过滤表达式，为空时返回 nil
+func (p FilterParam) Parse() (*FilterNode, error) {
+	s := strings.TrimSpace(string(p))
+	if s == "" {
+		return nil, nil
+	}
+	if strings.HasPrefix(s, "{") {
+		var node FilterNode
+		decoder := json.NewDecoder(strings.NewReader(s))
+		decoder.UseNumber()
+		if err := decoder.Decode(&node); err != nil {
+			return nil, fmt.Errorf("过滤表达式格式错误：%w", err)
+		}
+		return &node, nil
+	}
+	return ParseFilter(s)
+}
+
+// WithFilter 追加过滤表达式，与已有的过滤表达式是 AND 关系
+func (r *SearchFilterPageReq) WithFilter(node *FilterNode) (*SearchFilterPageReq, error) {
+	if node == nil {
+		return r, nil
+	}
+	existing, err := r.Filter.Parse()
+	if err != nil {
+		return r, err
+	}
+	if existing != nil {
+		node = And(existing, node)
+	}
+	r.Filter = NewFilterParam(node)
+	return r, nil
+}
+
+// String 转换为紧凑 URL 格式
+func (n *FilterNode) String() string {
+	if n == nil {
+		return ""
+	}
+	var sb strings.Builder
+	n.writeCompact(&sb)
+	return sb.String()
+}
+
+// Fields 表达式引用的字段（去重，按出现顺序）
+func (n *FilterNode) Fields() []string {
+	var fields []string
+	seen := make(map[string]bool)
+	var walk func(node *FilterNode)
+	walk = func(node *FilterNode) {
+		if node == nil {
+			return
+		}
+		for _, child := range node.And {
+			walk(child)
+		}
+		for _, child := range node.Or {
+			walk(child)
+		}
+		walk(node.Not)
+		if node.Field != "" && !seen[node.Field] {
+			seen[node.Field] = true
+			fields = append(fields, node.Field)
+		}
+	}
+	walk(n)
+	return fields
+}
+
+func (n *FilterNode) writeCompact(sb *strings.Builder) {
+	writeGroup := func(name string, nodes []*FilterNode) {
+		sb.WriteString(name + "(")
+		for i, child := range nodes {
+			if i > 0 {
+				sb.WriteString(",")
+			}
+			child.writeCompact(sb)
+		}
+		sb.WriteString(")")
+	}
+	switch {
+	case len(n.And) > 0:
+		writeGroup("and", n.And)
+	case len(n.Or) > 0:
+		writeGroup("or", n.Or)
+	case n.Not != nil:
+		writeGroup("not", []*FilterNode{n.Not})
+	default:
+		sb.WriteString(n.Op + "(" + quoteFilterValue(n.Field))
+		for _, value := range filterValues(n.Value) {
+			sb.WriteString("," + quoteFilterValue(fmt.Sprintf("%v", value)))
+		}
+		sb.WriteString(")")
+	}
+}
+
+// quoteFilterValue 包含特殊字符的值使用单引号包裹，单引号写两次转义
+func quoteFilterValue(s string) string {
+	if s != "" && !strings.ContainsAny(s, "(),' \t\r\n") {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// ParseFilter 解析紧凑格式的过滤表达式
+// 语法：and(表达式,...) / or(表达式,...) / not(表达式) / 操作符(字段,值,...)
+// 值包含逗号、括号、空白或单引号时使用单引号包裹，单引号写两次转义，如 eq(title,'a,b')
+func ParseFilter(s string) (*FilterNode, error) {
+	p := &filterParser{src: s}
+	node, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpaces()
+	if p.pos < len(p.src) {
+		return nil, fmt.Errorf("过滤表达式格式错误：位置 %d 有多余的内容", p.pos)
+	}
+	return node, nil
+}
+
+type filterParser struct {
+	src string
+	pos int
+}
+
+func (p *filterParser) parseExpr() (*FilterNode, error) {
+	name, err := p.parseToken()
+	if err != nil {
+		return nil, err
+	}
+	name = strings.ToLower(name)
+	if err := p.expect('('); err != nil {
+		return nil, err
+	}
+
+	switch name {
+	case "and", "or", "not":
+		var children []*FilterNode
+		for {
+			child, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			children = append(children, child)
+			if !p.consume(',') {
+				break
+			}
+		}
+		if err := p.expect(')'); err != nil {
+			return nil, err
+		}
+		switch name {
+		case "and":
+			return And(children...), nil
+		case "or":
+			return Or(children...), nil
+		}
+		if len(children) != 1 {
+			return nil, fmt.Errorf("过滤表达式格式错误：not 只能包含一个条件")
+		}
+		return Not(children[0]), nil
+	}
+
+	multi, ok := filterOperators[name]
+	if !ok {
+		return nil, fmt.Errorf("过滤表达式格式错误：不支持的操作符 %s", name)
+	}
+	field, err := p.parseToken()
+	if err != nil {
+		return nil, err
+	}
+	var values []string
+	for p.consume(',') {
+		value, err := p.parseToken()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	if err := p.expect(')'); err != nil {
+		return nil, err
+	}
+	if len(values) == 0 {
+		return nil, fmt.Errorf("过滤表达式格式错误：%s(%s) 缺少值", name, field)
+	}
+	if !multi && len(values) > 1 {
+		return nil, fmt.Errorf("过滤表达式格式错误：%s(%s) 只能有一个值", name, field)
+	}
+
+	node := Cond(field, name, nil)
+	if multi {
+		items := make([]interface{}, len(values))
+		for i, value := range values {
+			items[i] = value
+		}
+		node.Value = items
+	} else {
+		node.Value = values[0]
+	}
+	return node, nil
+}
+
+// parseToken 解析名称或值（支持单引号包裹）
+func (p *filterParser) parseToken() (string, error) {
+	p.skipSpaces()
+	if p.pos < len(p.src) && p.src[p.pos] == '\'' {
+		var sb strings.Builder
+		p.pos++
+		for p.pos < len(p.src) {
+			c := p.src[p.pos]
+			p.pos++
+			if c != '\'' {
+				sb.WriteByte(c)
+				continue
+			}
+			if p.pos < len(p.src) && p.src[p.pos] == '\'' {
+				sb.WriteByte('\'')
+				p.pos++
+				continue
+			}
+			return sb.String(), nil
+		}
+		return "", fmt.Errorf("过滤表达式格式错误：引号未闭合")
+	}
+
+	start := p.pos
+	for p.pos < len(p.src) && !strings.ContainsRune("(),'", rune(p.src[p.pos])) {
+		p.pos++
+	}
+	token := strings.TrimSpace(p.src[start:p.pos])
+	if token == "" {
+		return "", fmt.Errorf("过滤表达式格式错误：位置 %d 缺少内容", start)
+	}
+	return token, nil
+}
+
+func (p *filterParser) skipSpaces() {
+	for p.pos < len(p.src) && strings.ContainsRune(" \t\r\n", rune(p.src[p.pos])) {
+		p.pos++
+	}
+}
+
+func (p *filterParser) consume(c byte) bool {
+	p.skipSpaces()
+	if p.pos < len(p.src) && p.src[p.pos] == c {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *filterParser) expect(c byte) error {
+	if !p.consume(c) {
+		return fmt.Errorf("过滤表达式格式错误：位置 %d 应为 %q", p.pos, c)
+	}
+	return nil
+}
+
+// applyFilter 校验过滤表达式并作为一个整体的 WHERE 条件追加到查询（与扁平条件是 AND 关系）
+func applyFilter(db **gorm.DB, filter FilterParam, config *QueryConfig) error {
+	node, err := filter.Parse()
+	if err != nil || node == nil {
+		return err
+	}
+	count := 0
+	sql, args, err := buildFilterSQL(node, config, 1, &count)
+	if err != nil {
+		return err
+	}
+	*db = (*db).Where(sql, args...)
+	return nil
+}
+
+// buildFilterSQL 把过滤表达式编译为参数化 SQL
+func buildFilterSQL(node *FilterNode, config *QueryConfig, depth int, count *int) (string, []interface{}, error) {
+	if node == nil {
+		return "", nil, fmt.Errorf("过滤表达式不能包含空条件")
+	}
+	if depth > maxFilterDepth {
+		return "", nil, fmt.Errorf("过滤表达式嵌套不能超过 %d 层", maxFilterDepth)
+	}
+
+	groups := 0
+	for _, used := range []bool{len(node.And) > 0, len(node.Or) > 0, node.Not != nil, node.Field != "" || node.Op != ""} {
+		if used {
+			groups++
+		}
+	}
+	if groups != 1 {
+		return "", nil, fmt.Errorf("过滤表达式的每个节点只能是 and、or、not 或条件之一")
+	}
+
+	joinGroup := func(nodes []*FilterNode, sep string) (string, []interface{}, error) {
+		parts := make([]string, 0, len(nodes))
+		var args []interface{}
+		for _, child := range nodes {
+			sql, childArgs, err := buildFilterSQL(child, config, depth+1, count)
+			if err != nil {
+				return "", nil, err
+			}
+			parts = append(parts, sql)
+			args = append(args, childArgs...)
+		}
+		return "(" + strings.Join(parts, sep) + ")", args, nil
+	}
+
+	switch {
+	case len(node.And) > 0:
+		return joinGroup(node.And, " AND ")
+	case len(node.Or) > 0:
+		return joinGroup(node.Or, " OR ")
+	case node.Not != nil:
+		sql, args, err := buildFilterSQL(node.Not, config, depth+1, count)
+		if err != nil {
+			return "", nil, err
+		}
+		return "NOT " + sql, args, nil
+	}
+
+	if *count++; *count > maxFilterConditions {
+		return "", nil, fmt.Errorf("过滤表达式不能超过 %d 个条件", maxFilterConditions)
+	}
+	return buildFilterCondition(node, config)
+}
+
+// buildFilterCondition 编译单个条件，字段校验规则与扁平参数一致
+func buildFilterCondition(node *FilterNode, config *QueryConfig) (string, []interface{}, error) {
+	op := strings.ToLower(node.Op)
+	multi, ok := filterOperators[op]
+	if !ok {
+		return "", nil, fmt.Errorf("不支持的操作符：%s", node.Op)
+	}
+	if !SafeColumn(node.Field) || node.Field == "" {
+		return "", nil, fmt.Errorf("无效的字段名：%s", node.Field)
+	}
+	if err := validateField(node.Field, op, config); err != nil {
+		return "", nil, err
+	}
+
+	values := filterValues(node.Value)
+	if len(values) == 0 {
+		return "", nil, fmt.Errorf("字段 %s 的 %s 条件缺少值", node.Field, op)
+	}
+	if !multi && len(values) > 1 {
+		return "", nil, fmt.Errorf("字段 %s 的 %s 条件只能有一个值", node.Field, op)
+	}
+	for i, value := range values {
+		values[i] = normalizeFilterValue(value)
+	}
+
+	column := SafeColumnName(node.Field)
+	switch op {
+	case "like":
+		return "(" + column + ` LIKE ? ESCAPE '\')`, []interface{}{likeContainsPattern(fmt.Sprintf("%v", values[0]))}, nil
+	case "not_like":
+		return "(" + column + ` NOT LIKE ? ESCAPE '\')`, []interface{}{likeContainsPattern(fmt.Sprintf("%v", values[0]))}, nil
+	case "in":
+		return "(" + column + " IN ?)", []interface{}{values}, nil
+	case "not_in":
+		return "(" + column + " NOT IN ?)", []interface{}{values}, nil
+	case "contains":
+		// 与扁平参数一致，使用 instr 精确匹配逗号分隔的值（兼容 SQLite）
+		parts := make([]string, len(values))
+		args := make([]interface{}, len(values))
+		for i, value := range values {
+			parts[i] = "instr(',' || " + column + " || ',', ',' || ? || ',') > 0"
+			args[i] = fmt.Sprintf("%v", value)
+		}
+		return "(" + strings.Join(parts, " OR ") + ")", args, nil
+	}
+
+	symbols := map[string]string{"eq": "=", "not_eq": "!=", "gt": ">", "gte": ">=", "lt": "<", "lte": "<="}
+	return "(" + column + " " + symbols[op] + " ?)", []interface{}{values[0]}, nil
+}
+
+// filterValues 把条件值展开为列表（数组取每个元素，单个值作为一个元素）
+func filterValues(value interface{}) []interface{} {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case []interface{}:
+		return append([]interface{}(nil), v...)
+	case []string:
+		values := make([]interface{}, len(v))
+		for i, s := range v {
+			values[i] = s
+		}
+		return values
+	case []int:
+		values := make([]interface{}, len(v))
+		for i, n := range v {
+			values[i] = n
+		}
+		return values
+	case []int64:
+		values := make([]interface{}, len(v))
+		for i, n := range v {
+			values[i] = n
+		}
+		return values
+	}
+	return []interface{}{value}
+}
+
+// normalizeFilterValue 规范化条件值：字符串按扁平参数的规则尝试转换为数字、布尔值，JSON 数字转换为整数或浮点数
+func normalizeFilterValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return n
+		}
+		if b, err := strconv.ParseBool(v); err == nil {
+			return b
+		}
+		return v
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return n
+		}
+		if f, err := v.Float64(); err == nil {
+			return f
+		}
+		return v.String()
+	case float64:
+		if v == math.Trunc(v) && math.Abs(v) < 1<<53 {
+			return int64(v)
+		}
+	}
+	return value
+}
+
+// likeContainsPattern 包含匹配的 LIKE 模式，转义值中的 % 和 _，避免用户输入被当作通配符（需配合 ESCAPE '\'）
+func likeContainsPattern(term string) string {
+	return "%" + strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(term) + "%"
+}
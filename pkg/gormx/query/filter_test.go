@@ -0,0 +1,166 @@
+package query
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+type filterTask struct {
+	ID       int64
+	Title    string
+	Status   string
+	Assignee string
+	Priority int
+	Tags     string
+}
+
+func newFilterTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db := newTestDB(t, &filterTask{})
+	tasks := []filterTask{
+		{ID: 1, Title: "a,b", Status: "待处理", Assignee: "bob", Priority: 1, Tags: "紧急"},
+		{ID: 2, Title: "x", Status: "已完成", Assignee: "me", Priority: 3, Tags: "重要,紧急"},
+		{ID: 3, Title: "y", Status: "已完成", Assignee: "bob", Priority: 3, Tags: ""},
+		{ID: 4, Title: "z", Status: "进行中", Assignee: "alice", Priority: 2, Tags: "重要"},
+	}
+	if err := db.Create(&tasks).Error; err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+	return db
+}
+
+func filterIDs(t *testing.T, db *gorm.DB, req *SearchFilterPageReq, configs ...*QueryConfig) []int64 {
+	t.Helper()
+	var tasks []filterTask
+	result, err := AutoPaginateTable(context.Background(), db, &filterTask{}, &tasks, req, configs...)
+	if err != nil {
+		t.Fatalf("AutoPaginateTable() error = %v", err)
+	}
+	ids := make([]int64, 0, len(*result.Items))
+	for _, task := range *result.Items {
+		ids = append(ids, task.ID)
+	}
+	return ids
+}
+
+func TestFilterQuery(t *testing.T) {
+	db := newFilterTestDB(t)
+	tests := []struct {
+		name string
+		req  SearchFilterPageReq
+		want string
+	}{
+		{"or", SearchFilterPageReq{Filter: "or(eq(status,待处理),eq(assignee,me))"}, "[1 2]"},
+		{"nested", SearchFilterPageReq{Filter: "or(and(eq(priority,3),eq(assignee,bob)),in(status,进行中,不存在))"}, "[3 4]"},
+		{"not", SearchFilterPageReq{Filter: "not(or(eq(status,已完成),like(title,',')))"}, "[4]"},
+		{"contains", SearchFilterPageReq{Filter: "contains(tags,重要)"}, "[2 4]"},
+		{"like escapes wildcards", SearchFilterPageReq{Filter: "or(like(title,'_'),like(assignee,'%'))"}, "[]"},
+		{"not_like escapes wildcards", SearchFilterPageReq{Filter: "not_like(title,'_')"}, "[1 2 3 4]"},
+		{"quoted", SearchFilterPageReq{Filter: "eq(title,'a,b')"}, "[1]"},
+		{"with flat", SearchFilterPageReq{Eq: []string{"assignee:bob"}, Filter: "or(eq(status,待处理),gt(priority,2))"}, "[1 3]"},
+		{"json ast", SearchFilterPageReq{Filter: `{"or":[{"field":"id","op":"in","value":[1,4]},{"not":{"field":"priority","op":"lt","value":3}}]}`}, "[1 2 3 4]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := tt.req
+			req.Sorts = "id:asc"
+			if got := fmt.Sprint(filterIDs(t, db, &req)); got != tt.want {
+				t.Fatalf("ids = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterValidation(t *testing.T) {
+	db := newFilterTestDB(t)
+	config := NewQueryConfig()
+	config.AllowField("status", "eq")
+	config.AllowField("assignee", "eq")
+
+	for _, filter := range []FilterParam{
+		"or(eq(status,a),like(assignee,b))", // 操作符不在白名单
+		"or(eq(status,a),eq(priority,1))",   // 字段不在白名单
+		"eq(`status`,a)",                    // 不安全的字段名
+		"or(eq(status,a)",                   // 括号未闭合
+		"eq(status,a,b)",                    // 单值操作符传了多个值
+		"drop(status,a)",                    // 不支持的操作符
+		`{"and":[{"field":"status","op":"eq","value":"a"}],"not":{"field":"status","op":"eq","value":"b"}}`, // 一个节点多种类型
+	} {
+		var tasks []filterTask
+		req := &SearchFilterPageReq{Filter: filter}
+		if _, err := AutoPaginateTable(context.Background(), db, &filterTask{}, &tasks, req, config); err == nil {
+			t.Errorf("filter %s should be rejected", filter)
+		}
+	}
+
+	config.DenyField("assignee")
+	var tasks []filterTask
+	if _, err := AutoPaginateTable(context.Background(), db, &filterTask{}, &tasks, &SearchFilterPageReq{Filter: "not(eq(assignee,me))"}, config); err == nil {
+		t.Error("denied field should be rejected inside not()")
+	}
+}
+
+func TestFilterParam(t *testing.T) {
+	node := Or(Cond("status", "eq", "待处理"), And(Cond("title", "like", "a, b's"), Not(Cond("id", "in", []int{1, 2}))))
+	compact := node.String()
+	if want := "or(eq(status,待处理),and(like(title,'a, b''s'),not(in(id,1,2))))"; compact != want {
+		t.Fatalf("String() = %s, want %s", compact, want)
+	}
+	parsed, err := ParseFilter(compact)
+	if err != nil {
+		t.Fatalf("ParseFilter() error = %v", err)
+	}
+	if parsed.String() != compact {
+		t.Fatalf("round trip = %s, want %s", parsed.String(), compact)
+	}
+
+	var req SearchFilterPageReq
+	body := `{"page":1,"filter":{"or":[{"field":"status","op":"eq","value":"待处理"}]}}`
+	if err := json.Unmarshal([]byte(body), &req); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if got, err := req.Filter.Parse(); err != nil || got.String() != "or(eq(status,待处理))" {
+		t.Fatalf("Parse() = %v, %v", got, err)
+	}
+
+	type params struct {
+		Status string      `json:"status" search:"eq"`
+		Filter *FilterNode `json:"filter"`
+	}
+	qs, err := StructToTableParams(&params{Status: "a", Filter: Or(Cond("assignee", "eq", "me"), Cond("overdue", "eq", true))})
+	if err != nil {
+		t.Fatalf("StructToTableParams() error = %v", err)
+	}
+	if want := "eq=status%3Aa&filter=or%28eq%28assignee%2Cme%29%2Ceq%28overdue%2Ctrue%29%29"; qs != want {
+		t.Fatalf("StructToTableParams() = %s, want %s", qs, want)
+	}
+
+	// 保存的视图：过滤表达式、排序、每页数量
+	type view struct {
+		Filter   FilterParam
+		Sorts    string
+		PageSize int
+	}
+	qs, err = StructToTableParams(&view{Filter: "and(eq(status,open),not(eq(assignee,me)))", Sorts: "id:desc", PageSize: 50})
+	if err != nil {
+		t.Fatalf("StructToTableParams() error = %v", err)
+	}
+	if want := "filter=and%28eq%28status%2Copen%29%2Cnot%28eq%28assignee%2Cme%29%29%29&page_size=50&sorts=id%3Adesc"; qs != want {
+		t.Fatalf("StructToTableParams() = %s, want %s", qs, want)
+	}
+}
+
+func TestFilterFields(t *testing.T) {
+	node := And(Cond("status", "eq", "open"), Or(Not(Cond("assignee", "eq", "me")), Cond("status", "eq", "new")))
+	if got := node.Fields(); !reflect.DeepEqual(got, []string{"status", "assignee"}) {
+		t.Fatalf("Fields() = %v", got)
+	}
+	if got := (*FilterNode)(nil).Fields(); len(got) != 0 {
+		t.Fatalf("Fields() = %v", got)
+	}
+}
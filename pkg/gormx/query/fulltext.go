@@ -0,0 +1,323 @@
+package query
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// 全文检索（SQLite FTS5）
+// 模型中带 search:"fulltext" 标签的字段会建立 FTS5 索引表 <表名>_fts（trigram 分词，中文不需要额外分词），
+// 通过触发器与主表同步；SearchFilterPageReq.Keyword 通过索引表检索，按相关度排序并返回命中片段
+
+const (
+	fullTextTag = "fulltext"
+	// fullTextMinTermLen trigram 分词能走索引匹配的最短词长（字符数），更短的词退化为在索引表上 LIKE
+	fullTextMinTermLen = 3
+	// fullTextMaxTerms 关键词最多的词数
+	fullTextMaxTerms = 10
+	// fullTextSnippetTokens 命中片段的长度（trigram 下约等于字符数）
+	fullTextSnippetTokens = 32
+)
+
+// 命中片段中关键词的标记
+const (
+	HighlightStart = "<mark>"
+	HighlightEnd   = "</mark>"
+)
+
+// fullTextIndex 模型的全文检索索引
+type fullTextIndex struct {
+	Table    string
+	FTSTable string
+	PK       *schema.Field
+	Columns  []string
+}
+
+// parseFullTextIndex 解析模型的全文检索字段，没有 search:"fulltext" 字段时返回 nil
+func parseFullTextIndex(db *gorm.DB, model interface{}) (*fullTextIndex, error) {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(model); err != nil {
+		return nil, fmt.Errorf("解析模型失败: %w", err)
+	}
+
+	var columns []string
+	for _, field := range stmt.Schema.Fields {
+		if field.DBName == "" {
+			continue
+		}
+		for _, tag := range strings.Split(field.Tag.Get("search"), ",") {
+			if strings.TrimSpace(tag) == fullTextTag {
+				columns = append(columns, field.DBName)
+				break
+			}
+		}
+	}
+	if len(columns) == 0 {
+		return nil, nil
+	}
+
+	pk := stmt.Schema.PrioritizedPrimaryField
+	if pk == nil || (pk.DataType != schema.Int && pk.DataType != schema.Uint) {
+		return nil, fmt.Errorf("表 %s 的全文检索要求整数主键", stmt.Schema.Table)
+	}
+	if !SafeColumn(stmt.Schema.Table) {
+		return nil, fmt.Errorf("无效的表名：%s", stmt.Schema.Table)
+	}
+	return &fullTextIndex{
+		Table:    stmt.Schema.Table,
+		FTSTable: stmt.Schema.Table + "_fts",
+		PK:       pk,
+		Columns:  columns,
+	}, nil
+}
+
+func (idx *fullTextIndex) createSQL() string {
+	return fmt.Sprintf("CREATE VIRTUAL TABLE %s USING fts5(%s, content='%s', content_rowid='%s', tokenize='trigram')",
+		SafeColumnName(idx.FTSTable), idx.columnList(""), idx.Table, idx.PK.DBName)
+}
+
+// columnList 索引字段列表，prefix 为 new/old 时用于触发器
+func (idx *fullTextIndex) columnList(prefix string) string {
+	columns := make([]string, len(idx.Columns))
+	for i, column := range idx.Columns {
+		columns[i] = SafeColumnName(column)
+		if prefix != "" {
+			columns[i] = prefix + "." + columns[i]
+		}
+	}
+	return strings.Join(columns, ", ")
+}
+
+func (idx *fullTextIndex) triggerNames() []string {
+	return []string{idx.FTSTable + "_ai", idx.FTSTable + "_ad", idx.FTSTable + "_au"}
+}
+
+func (idx *fullTextIndex) triggerSQLs() []string {
+	fts := SafeColumnName(idx.FTSTable)
+	table := SafeColumnName(idx.Table)
+	pk := SafeColumnName(idx.PK.DBName)
+	insert := fmt.Sprintf("INSERT INTO %s(rowid, %s) VALUES (new.%s, %s);", fts, idx.columnList(""), pk, idx.columnList("new"))
+	remove := fmt.Sprintf("INSERT INTO %s(%s, rowid, %s) VALUES ('delete', old.%s, %s);", fts, fts, idx.columnList(""), pk, idx.columnList("old"))
+	names := idx.triggerNames()
+	return []string{
+		fmt.Sprintf("CREATE TRIGGER %s AFTER INSERT ON %s BEGIN %s END", SafeColumnName(names[0]), table, insert),
+		fmt.Sprintf("CREATE TRIGGER %s AFTER DELETE ON %s BEGIN %s END", SafeColumnName(names[1]), table, remove),
+		fmt.Sprintf("CREATE TRIGGER %s AFTER UPDATE ON %s BEGIN %s %s END", SafeColumnName(names[2]), table, remove, insert),
+	}
+}
+
+// EnsureFullTextIndex 为模型中 search:"fulltext" 的字段维护 FTS5 索引表和同步触发器，需要在 AutoMigrate 之后调用
+// 索引字段变化时重建索引表；SQLite 修改列时会重建主表，旧表上的触发器随之删除，这种情况下重新创建触发器并重建索引
+// 非 SQLite 数据库直接返回，关键词检索退化为 LIKE
+func EnsureFullTextIndex(db *gorm.DB, model interface{}) error {
+	if db.Dialector.Name() != "sqlite" {
+		return nil
+	}
+	idx, err := parseFullTextIndex(db, model)
+	if err != nil || idx == nil {
+		return err
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		rebuild := false
+
+		var existing string
+		if err := tx.Raw("SELECT sql FROM sqlite_master WHERE type = 'table' AND name = ?", idx.FTSTable).Scan(&existing).Error; err != nil {
+			return fmt.Errorf("查询全文索引表失败: %w", err)
+		}
+		if existing != idx.createSQL() {
+			for _, name := range idx.triggerNames() {
+				if err := tx.Exec("DROP TRIGGER IF EXISTS " + SafeColumnName(name)).Error; err != nil {
+					return fmt.Errorf("删除全文索引触发器失败: %w", err)
+				}
+			}
+			if err := tx.Exec("DROP TABLE IF EXISTS " + SafeColumnName(idx.FTSTable)).Error; err != nil {
+				return fmt.Errorf("删除全文索引表失败: %w", err)
+			}
+			if err := tx.Exec(idx.createSQL()).Error; err != nil {
+				return fmt.Errorf("创建全文索引表失败: %w", err)
+			}
+			rebuild = true
+		}
+
+		var triggers int64
+		if err := tx.Raw("SELECT count(*) FROM sqlite_master WHERE type = 'trigger' AND tbl_name = ? AND name IN ?", idx.Table, idx.triggerNames()).Scan(&triggers).Error; err != nil {
+			return fmt.Errorf("查询全文索引触发器失败: %w", err)
+		}
+		if int(triggers) != len(idx.triggerNames()) {
+			for _, name := range idx.triggerNames() {
+				if err := tx.Exec("DROP TRIGGER IF EXISTS " + SafeColumnName(name)).Error; err != nil {
+					return fmt.Errorf("删除全文索引触发器失败: %w", err)
+				}
+			}
+			for _, trigger := range idx.triggerSQLs() {
+				if err := tx.Exec(trigger).Error; err != nil {
+					return fmt.Errorf("创建全文索引触发器失败: %w", err)
+				}
+			}
+			rebuild = true
+		}
+
+		if rebuild {
+			fts := SafeColumnName(idx.FTSTable)
+			if err := tx.Exec(fmt.Sprintf("INSERT INTO %s(%s) VALUES ('rebuild')", fts, fts)).Error; err != nil {
+				return fmt.Errorf("重建全文索引失败: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// KeywordSearch 关键词检索，由 ApplyKeyword 创建，nil 表示没有关键词检索
+type KeywordSearch struct {
+	index *fullTextIndex
+	match string // FTS5 MATCH 表达式，所有词都少于 3 个字符时为空
+	fts   bool   // 是否通过 FTS5 索引表检索（否则在主表上 LIKE）
+}
+
+// ApplyKeyword 按 pageInfo.Keyword 做关键词检索
+// 关键词按空白拆分，各个词之间是 AND 关系；模型没有 search:"fulltext" 字段或没有关键词时原样返回 db
+// 有 FTS5 索引表时通过索引表检索（可按 RankOrder 排序、用 Highlights 获取命中片段），否则在全文检索字段上 LIKE
+func ApplyKeyword(db *gorm.DB, model interface{}, pageInfo *SearchFilterPageReq) (*gorm.DB, *KeywordSearch, error) {
+	if pageInfo == nil || strings.TrimSpace(pageInfo.Keyword) == "" {
+		return db, nil, nil
+	}
+	idx, err := parseFullTextIndex(db, model)
+	if err != nil || idx == nil {
+		return db, nil, err
+	}
+
+	terms := strings.Fields(pageInfo.Keyword)
+	if len(terms) > fullTextMaxTerms {
+		return db, nil, fmt.Errorf("关键词不能超过 %d 个", fullTextMaxTerms)
+	}
+
+	search := &KeywordSearch{index: idx}
+	search.fts = db.Dialector.Name() == "sqlite" && db.Session(&gorm.Session{NewDB: true}).Migrator().HasTable(idx.FTSTable)
+	if !search.fts {
+		// 没有索引表（非 SQLite 或尚未迁移）：在主表的全文检索字段上 LIKE
+		for _, term := range terms {
+			sql, args := likeAnyColumn(SafeColumnName(idx.Table)+".", idx.Columns, term)
+			db = db.Where(sql, args...)
+		}
+		return db, search, nil
+	}
+
+	var matches, conds []string
+	var args []interface{}
+	for _, term := range terms {
+		if utf8.RuneCountInString(term) >= fullTextMinTermLen {
+			matches = append(matches, `"`+strings.ReplaceAll(term, `"`, `""`)+`"`)
+			continue
+		}
+		sql, likeArgs := likeAnyColumn("", idx.Columns, term)
+		conds = append(conds, sql)
+		args = append(args, likeArgs...)
+	}
+
+	fts := SafeColumnName(idx.FTSTable)
+	rank := "0"
+	if len(matches) > 0 {
+		search.match = strings.Join(matches, " AND ")
+		rank = "rank"
+		conds = append([]string{fts + " MATCH ?"}, conds...)
+		args = append([]interface{}{search.match}, args...)
+	}
+	join := fmt.Sprintf("JOIN (SELECT rowid AS kw_rowid, %s AS kw_rank FROM %s WHERE %s) AS kw ON kw.kw_rowid = %s.%s",
+		rank, fts, strings.Join(conds, " AND "), SafeColumnName(idx.Table), SafeColumnName(idx.PK.DBName))
+	return db.Joins(join, args...), search, nil
+}
+
+// likeAnyColumn 任意一个字段包含 term
+func likeAnyColumn(prefix string, columns []string, term string) (string, []interface{}) {
+	pattern := likeContainsPattern(term)
+	conds := make([]string, len(columns))
+	args := make([]interface{}, len(columns))
+	for i, column := range columns {
+		conds[i] = prefix + SafeColumnName(column) + ` LIKE ? ESCAPE '\'`
+		args[i] = pattern
+	}
+	return "(" + strings.Join(conds, " OR ") + ")", args
+}
+
+// RankOrder 按相关度排序的 ORDER BY 表达式，不能按相关度排序时返回空字符串
+// 只在没有指定排序时使用，避免覆盖用户选择的排序
+func (s *KeywordSearch) RankOrder() string {
+	if s == nil || !s.fts || s.match == "" {
+		return ""
+	}
+	return "kw.kw_rank"
+}
+
+// Highlights 获取 dest（查询结果的切片指针）中每条记录的命中片段：主键 -> 字段 -> 片段（关键词用 <mark></mark> 标记）
+// 只包含命中的字段；少于 3 个字符的词没有片段
+func (s *KeywordSearch) Highlights(db *gorm.DB, dest interface{}) (map[string]map[string]string, error) {
+	if s == nil || !s.fts || s.match == "" {
+		return nil, nil
+	}
+
+	rows := reflect.Indirect(reflect.ValueOf(dest))
+	if rows.Kind() != reflect.Slice || rows.Len() == 0 {
+		return nil, nil
+	}
+	ids := make([]interface{}, 0, rows.Len())
+	for i := 0; i < rows.Len(); i++ {
+		row := reflect.Indirect(rows.Index(i))
+		if row.Kind() != reflect.Struct {
+			return nil, fmt.Errorf("获取命中片段需要结构体切片")
+		}
+		if id, zero := s.index.PK.ValueOf(db.Statement.Context, row); !zero {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	fts := SafeColumnName(s.index.FTSTable)
+	selects := make([]string, len(s.index.Columns))
+	for i := range s.index.Columns {
+		selects[i] = fmt.Sprintf("snippet(%s, %d, '%s', '%s', '…', %d)", fts, i, HighlightStart, HighlightEnd, fullTextSnippetTokens)
+	}
+	result, err := db.Session(&gorm.Session{NewDB: true}).
+		Raw(fmt.Sprintf("SELECT rowid, %s FROM %s WHERE %s MATCH ? AND rowid IN ?", strings.Join(selects, ", "), fts, fts), s.match, ids).
+		Rows()
+	if err != nil {
+		return nil, fmt.Errorf("查询命中片段失败: %w", err)
+	}
+	defer result.Close()
+
+	highlights := make(map[string]map[string]string)
+	for result.Next() {
+		var rowid int64
+		snippets := make([]sql.NullString, len(s.index.Columns))
+		ptrs := []interface{}{&rowid}
+		for i := range snippets {
+			ptrs = append(ptrs, &snippets[i])
+		}
+		if err := result.Scan(ptrs...); err != nil {
+			return nil, fmt.Errorf("读取命中片段失败: %w", err)
+		}
+		for i, snippet := range snippets {
+			if !strings.Contains(snippet.String, HighlightStart) {
+				continue
+			}
+			key := strconv.FormatInt(rowid, 10)
+			if highlights[key] == nil {
+				highlights[key] = make(map[string]string)
+			}
+			highlights[key][s.index.Columns[i]] = snippet.String
+		}
+	}
+	if err := result.Err(); err != nil {
+		return nil, fmt.Errorf("读取命中片段失败: %w", err)
+	}
+	return highlights, nil
+}
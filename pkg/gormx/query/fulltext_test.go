@@ -0,0 +1,186 @@
+package query
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+type ftsTicket struct {
+	ID      int64
+	Title   string `search:"like,fulltext"`
+	Content string `search:"fulltext"`
+	Status  string
+}
+
+func (ftsTicket) TableName() string {
+	return "fts_tickets"
+}
+
+// ftsTicketV2 同一张表增加了全文检索字段
+type ftsTicketV2 struct {
+	ID      int64
+	Title   string `search:"like,fulltext"`
+	Content string `search:"fulltext"`
+	Status  string
+	Remark  string `search:"fulltext"`
+}
+
+func (ftsTicketV2) TableName() string {
+	return "fts_tickets"
+}
+
+func newFullTextTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db := newTestDB(t, &ftsTicket{})
+	// 建索引前已有的数据由 rebuild 写入索引
+	if err := db.Create(&ftsTicket{ID: 1, Title: "打印机无法连接", Content: "三楼打印机无法连接网络，重启后依旧无法打印", Status: "open"}).Error; err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+	if err := EnsureFullTextIndex(db, &ftsTicket{}); err != nil {
+		t.Fatalf("ensure index: %v", err)
+	}
+	tickets := []ftsTicket{
+		{ID: 2, Title: "申请新的显示器", Content: "显示器出现花屏，申请更换", Status: "open"},
+		{ID: 3, Title: "网络故障", Content: "会议室网络无法连接，打印机无法使用", Status: "closed"},
+		{ID: 4, Title: "VPN 账号申请", Content: "出差需要 VPN，请开通账号", Status: "open"},
+	}
+	if err := db.Create(&tickets).Error; err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+	return db
+}
+
+func keywordIDs(t *testing.T, db *gorm.DB, model interface{}, pageInfo *SearchFilterPageReq) ([]int64, map[string]map[string]string) {
+	t.Helper()
+	var tickets []ftsTicket
+	table, err := AutoPaginateTable(context.Background(), db, model, &tickets, pageInfo)
+	if err != nil {
+		t.Fatalf("keyword %q: %v", pageInfo.Keyword, err)
+	}
+	ids := make([]int64, 0, len(tickets))
+	for _, ticket := range tickets {
+		ids = append(ids, ticket.ID)
+	}
+	if int(table.TotalCount) != len(ids) {
+		t.Fatalf("keyword %q: total %d, got %d rows", pageInfo.Keyword, table.TotalCount, len(ids))
+	}
+	return ids, table.Highlights
+}
+
+func TestFullTextSearch(t *testing.T) {
+	db := newFullTextTestDB(t)
+
+	// 按相关度排序：标题和内容都命中的排在前面
+	ids, highlights := keywordIDs(t, db, &ftsTicket{}, &SearchFilterPageReq{Keyword: "打印机无法"})
+	if !reflect.DeepEqual(ids, []int64{1, 3}) {
+		t.Fatalf("ids = %v", ids)
+	}
+	if highlights["1"]["title"] != "<mark>打印机无法</mark>连接" {
+		t.Fatalf("highlights = %v", highlights)
+	}
+	if _, ok := highlights["3"]["title"]; ok {
+		t.Fatalf("title of 3 should not be highlighted: %v", highlights)
+	}
+
+	// 多个词之间是 AND，短词（少于 3 个字符）退化为 LIKE，和其他条件组合
+	ids, _ = keywordIDs(t, db, &ftsTicket{}, &SearchFilterPageReq{Keyword: "无法连接 网络", Sorts: "id:desc"})
+	if !reflect.DeepEqual(ids, []int64{3, 1}) {
+		t.Fatalf("ids = %v", ids)
+	}
+	ids, _ = keywordIDs(t, db, &ftsTicket{}, &SearchFilterPageReq{Keyword: "申请", Eq: []string{"status:open"}})
+	if !reflect.DeepEqual(ids, []int64{2, 4}) {
+		t.Fatalf("ids = %v", ids)
+	}
+	ids, _ = keywordIDs(t, db, &ftsTicket{}, &SearchFilterPageReq{Keyword: `vpn "`})
+	if len(ids) != 0 {
+		t.Fatalf("ids = %v", ids)
+	}
+	ids, _ = keywordIDs(t, db, &ftsTicket{}, &SearchFilterPageReq{Keyword: "vpn"})
+	if !reflect.DeepEqual(ids, []int64{4}) {
+		t.Fatalf("ids = %v", ids)
+	}
+
+	// 触发器同步更新和删除
+	if err := db.Model(&ftsTicket{}).Where("id = ?", 2).Update("content", "显示器无法连接").Error; err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if err := db.Delete(&ftsTicket{}, 1).Error; err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	ids, _ = keywordIDs(t, db, &ftsTicket{}, &SearchFilterPageReq{Keyword: "无法连接", Sorts: "id:asc"})
+	if !reflect.DeepEqual(ids, []int64{2, 3}) {
+		t.Fatalf("ids = %v", ids)
+	}
+	ids, _ = keywordIDs(t, db, &ftsTicket{}, &SearchFilterPageReq{Keyword: "花屏"})
+	if len(ids) != 0 {
+		t.Fatalf("ids = %v", ids)
+	}
+
+	// 聚合统计使用相同的关键词条件
+	result, err := Aggregate(db, &ftsTicket{}, &SearchFilterPageReq{Keyword: "无法连接", Aggs: []string{"count"}}, nil)
+	if err != nil || result.Rows[0]["count"] != int64(2) {
+		t.Fatalf("aggregate: %+v, %v", result, err)
+	}
+}
+
+func TestFullTextIndexMigration(t *testing.T) {
+	db := newFullTextTestDB(t)
+
+	// 再次调用不重建
+	if err := EnsureFullTextIndex(db, &ftsTicket{}); err != nil {
+		t.Fatalf("ensure index: %v", err)
+	}
+
+	// 增加全文检索字段后重建索引表
+	if err := db.AutoMigrate(&ftsTicketV2{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	if err := db.Model(&ftsTicketV2{}).Where("id = ?", 4).Update("remark", "已转交运维组处理").Error; err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if err := EnsureFullTextIndex(db, &ftsTicketV2{}); err != nil {
+		t.Fatalf("ensure index: %v", err)
+	}
+	ids, _ := keywordIDs(t, db, &ftsTicketV2{}, &SearchFilterPageReq{Keyword: "运维组"})
+	if !reflect.DeepEqual(ids, []int64{4}) {
+		t.Fatalf("ids = %v", ids)
+	}
+
+	// 主表被重建（触发器随旧表删除）后重新创建触发器
+	if err := db.Migrator().DropTable(&ftsTicketV2{}); err != nil {
+		t.Fatalf("drop: %v", err)
+	}
+	if err := db.AutoMigrate(&ftsTicketV2{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	if err := EnsureFullTextIndex(db, &ftsTicketV2{}); err != nil {
+		t.Fatalf("ensure index: %v", err)
+	}
+	if err := db.Create(&ftsTicketV2{ID: 9, Title: "键盘按键失灵", Status: "open"}).Error; err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	ids, _ = keywordIDs(t, db, &ftsTicketV2{}, &SearchFilterPageReq{Keyword: "按键失灵"})
+	if !reflect.DeepEqual(ids, []int64{9}) {
+		t.Fatalf("ids = %v", ids)
+	}
+	ids, _ = keywordIDs(t, db, &ftsTicketV2{}, &SearchFilterPageReq{Keyword: "运维组"})
+	if len(ids) != 0 {
+		t.Fatalf("ids = %v", ids)
+	}
+}
+
+func TestFullTextFallback(t *testing.T) {
+	db := newTestDB(t, &ftsTicket{})
+	if err := db.Create(&[]ftsTicket{{ID: 1, Title: "100%_完成"}, {ID: 2, Title: "100 完成"}}).Error; err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+
+	// 没有索引表时在全文检索字段上 LIKE，通配符按字面匹配
+	ids, highlights := keywordIDs(t, db, &ftsTicket{}, &SearchFilterPageReq{Keyword: "%_"})
+	if !reflect.DeepEqual(ids, []int64{1}) || highlights != nil {
+		t.Fatalf("ids = %v, highlights = %v", ids, highlights)
+	}
+}
@@ -16,6 +16,14 @@ type PaginatedTable[T any] struct {
 	TotalCount  int64 `json:"total_count" runner:"search_cond"`                  // 总数据量
 	TotalPages  int   `json:"total_pages" runner:"search_cond"`                  // 总页数
 	PageSize    int   `json:"page_size" runner:"search_cond"`                    // 每页数量
+	// 游标分页（page_mode=cursor）时返回，页码分页时为空
+	NextCursor  string `json:"next_cursor,omitempty" runner:"search_cond"`  // 下一页游标，为空表示没有下一页
+	PrevCursor  string `json:"prev_cursor,omitempty" runner:"search_cond"`  // 上一页游标，为空表示没有上一页
+	TotalApprox bool   `json:"total_approx,omitempty" runner:"search_cond"` // 总数是否为近似值
+	// 请求了聚合统计（aggs）时返回，按整个搜索结果统计而不是当前页
+	Aggregates *AggregateResult `json:"aggregates,omitempty" runner:"search_cond"`
+	// 关键词全文检索（keyword）命中的片段：主键 -> 字段 -> 片段（关键词用 <mark></mark> 标记）
+	Highlights map[string]map[string]string `json:"highlights,omitempty" runner:"search_cond"`
 }
 
 // SearchFilterPageReq 分页参数结构体
@@ -24,7 +32,12 @@ type SearchFilterPageReq struct {
 	PageSize int    `json:"page_size" form:"page_size"`
 	Sorts    string `json:"sorts" form:"sorts"` //category:asc,price:desc
 
-	Keyword string `json:"keyword" form:"keyword"`
+	// 游标分页（可选）：大表深分页时避免 COUNT(*) 和 OFFSET
+	PageMode  string `json:"page_mode" form:"page_mode"`   // page（默认）/cursor
+	Cursor    string `json:"cursor" form:"cursor"`         // 上一次响应中的 next_cursor/prev_cursor，为空表示第一页
+	CountMode string `json:"count_mode" form:"count_mode"` // 游标分页的总数统计：none（默认，total_count 为 -1）/approx/exact
+
+	Keyword string `json:"keyword" form:"keyword"` // 关键词检索，作用于模型中 search:"fulltext" 的字段（见 ApplyKeyword）
 	// 查询条件
 	Eq       []string `form:"eq" json:"eq"`             // 格式：field:value
 	Like     []string `form:"like" json:"like"`         // 格式：field:value
@@ -38,6 +51,14 @@ type SearchFilterPageReq struct {
 	NotEq   []string `form:"not_eq" json:"not_eq"`     // 格式：field:value
 	NotLike []string `form:"not_like" json:"not_like"` // 格式：field:value
 	NotIn   []string `form:"not_in" json:"not_in"`     // 格式：field:value
+	// 嵌套过滤表达式（支持 AND/OR/NOT 分组），与上面的扁平条件是 AND 关系
+	// 格式：or(eq(status,待处理),eq(assignee,me))，JSON 请求体中也可以直接传 FilterNode 对象
+	Filter FilterParam `form:"filter" json:"filter"`
+
+	// 聚合统计（可选）：在与搜索相同的条件下做 SQL 聚合，结果见 PaginatedTable.Aggregates
+	Aggs     []string `form:"aggs" json:"aggs"`           // 格式：op:field[:alias]，op 为 sum/avg/min/max/count
+	GroupBy  []string `form:"group_by" json:"group_by"`   // 格式：field[:bucket]，bucket 为 day/week/month（时间戳字段）
+	TZOffset int      `form:"tz_offset" json:"tz_offset"` // 时间分桶的时区偏移（分钟），如东八区为 480
 }
 
 // normalizeSortField 标准化排序字段格式
@@ -705,6 +726,49 @@ func AutoPaginateTable[T any](
 		return nil, err
 	}
 
+	// 关键词全文检索
+	dbClone, keyword, err := ApplyKeyword(dbClone, model, pageInfo)
+	if err != nil {
+		return nil, err
+	}
+
+	// 聚合统计（与分页无关，按整个搜索结果统计）
+	var aggregates *AggregateResult
+	if aggReq := pageInfo.GetAggregateReq(); aggReq != nil {
+		var config *QueryConfig
+		if len(configs) > 0 {
+			config = mergeConfigs(configs...)
+		}
+		result, err := runAggregate(dbClone, model, aggReq, config)
+		if err != nil {
+			return nil, err
+		}
+		aggregates = result
+	}
+
+	// 游标分页
+	if pageInfo.IsCursorMode() {
+		cursorResult, err := PaginateCursor(dbClone, model, data, pageInfo)
+		if err != nil {
+			return nil, err
+		}
+		highlights, err := keyword.Highlights(db, data)
+		if err != nil {
+			return nil, err
+		}
+		return &PaginatedTable[T]{
+			Items:       data,
+			TotalCount:  cursorResult.TotalCount,
+			TotalPages:  -1,
+			PageSize:    cursorResult.PageSize,
+			NextCursor:  cursorResult.NextCursor,
+			PrevCursor:  cursorResult.PrevCursor,
+			TotalApprox: cursorResult.TotalApprox,
+			Aggregates:  aggregates,
+			Highlights:  highlights,
+		}, nil
+	}
+
 	// 获取分页大小
 	pageSize := pageInfo.GetLimit()
 	offset := pageInfo.GetOffset()
@@ -715,10 +779,12 @@ func AutoPaginateTable[T any](
 		return nil, fmt.Errorf("分页查询统计总数失败: %w", err)
 	}
 
-	// 应用排序条件
+	// 应用排序条件，没有指定排序时按关键词相关度排序
 	sortStr := pageInfo.GetSorts()
 	if sortStr != "" {
 		dbClone = dbClone.Order(sortStr)
+	} else if rankOrder := keyword.RankOrder(); rankOrder != "" {
+		dbClone = dbClone.Order(rankOrder)
 	}
 
 	// 查询当前页数据
@@ -726,6 +792,11 @@ func AutoPaginateTable[T any](
 		return nil, fmt.Errorf("分页查询数据失败: %w", err)
 	}
 
+	highlights, err := keyword.Highlights(db, data)
+	if err != nil {
+		return nil, err
+	}
+
 	// 计算总页数
 	totalPages := int(totalCount) / pageSize
 	if int(totalCount)%pageSize != 0 {
@@ -738,6 +809,8 @@ func AutoPaginateTable[T any](
 		TotalCount:  totalCount,
 		TotalPages:  totalPages,
 		PageSize:    pageSize,
+		Aggregates:  aggregates,
+		Highlights:  highlights,
 	}, nil
 }
 
@@ -760,6 +833,7 @@ func AutoPaginateTable[T any](
 //   - not_eq: 不等于
 //   - not_like: 否定模糊匹配
 //   - not_in: 否定包含查询
+//   - filter: 嵌套过滤表达式（AND/OR/NOT 分组，见 FilterNode）
 func ApplySearchConditions(db *gorm.DB, pageInfo *SearchFilterPageReq, configs ...*QueryConfig) (*gorm.DB, error) {
 	if pageInfo == nil {
 		return db, nil
@@ -808,6 +882,43 @@ func SimplePaginate(db *gorm.DB, model interface{}, dest interface{}, pageInfo *
 		return nil, fmt.Errorf("应用搜索条件失败: %w", err)
 	}
 
+	// 关键词全文检索
+	dbWithConditions, keyword, err := ApplyKeyword(dbWithConditions, model, pageInfo)
+	if err != nil {
+		return nil, err
+	}
+
+	// 聚合统计
+	var aggregates *AggregateResult
+	if aggReq := pageInfo.GetAggregateReq(); aggReq != nil {
+		if aggregates, err = runAggregate(dbWithConditions, model, aggReq, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	// 游标分页
+	if pageInfo.IsCursorMode() {
+		cursorResult, err := PaginateCursor(dbWithConditions, model, dest, pageInfo)
+		if err != nil {
+			return nil, err
+		}
+		highlights, err := keyword.Highlights(db, dest)
+		if err != nil {
+			return nil, err
+		}
+		return &PaginatedTable[interface{}]{
+			Items:       dest,
+			TotalCount:  cursorResult.TotalCount,
+			TotalPages:  -1,
+			PageSize:    cursorResult.PageSize,
+			NextCursor:  cursorResult.NextCursor,
+			PrevCursor:  cursorResult.PrevCursor,
+			TotalApprox: cursorResult.TotalApprox,
+			Aggregates:  aggregates,
+			Highlights:  highlights,
+		}, nil
+	}
+
 	// 获取分页参数
 	pageSize := pageInfo.GetLimit()
 	offset := pageInfo.GetOffset()
@@ -818,15 +929,22 @@ func SimplePaginate(db *gorm.DB, model interface{}, dest interface{}, pageInfo *
 		return nil, fmt.Errorf("查询总数失败: %w", err)
 	}
 
-	// 应用排序和分页
+	// 应用排序和分页，没有指定排序时按关键词相关度排序
 	if pageInfo.GetSorts() != "" {
 		dbWithConditions = dbWithConditions.Order(pageInfo.GetSorts())
+	} else if rankOrder := keyword.RankOrder(); rankOrder != "" {
+		dbWithConditions = dbWithConditions.Order(rankOrder)
 	}
 
 	if err := dbWithConditions.Offset(offset).Limit(pageSize).Find(dest).Error; err != nil {
 		return nil, fmt.Errorf("分页查询数据失败: %w", err)
 	}
 
+	highlights, err := keyword.Highlights(db, dest)
+	if err != nil {
+		return nil, err
+	}
+
 	// 计算总页数
 	totalPages := int(totalCount) / pageSize
 	if int(totalCount)%pageSize != 0 {
@@ -839,6 +957,8 @@ func SimplePaginate(db *gorm.DB, model interface{}, dest interface{}, pageInfo *
 		TotalCount:  totalCount,
 		TotalPages:  totalPages,
 		PageSize:    pageSize,
+		Aggregates:  aggregates,
+		Highlights:  highlights,
 	}, nil
 }
 
@@ -907,7 +1027,8 @@ func buildWhereConditions(db **gorm.DB, pageInfo *SearchFilterPageReq, configs .
 		return err
 	}
 
-	return nil
+	// 验证并构建嵌套过滤表达式
+	return applyFilter(db, pageInfo.Filter, config)
 }
 
 // buildWhereConditionsWithoutConfig 无配置构建查询条件
@@ -967,7 +1088,8 @@ func buildWhereConditionsWithoutConfig(db **gorm.DB, pageInfo *SearchFilterPageR
 		return err
 	}
 
-	return nil
+	// 构建嵌套过滤表达式
+	return applyFilter(db, pageInfo.Filter, nil)
 }
 
 // mergeConfigs 合并多个配置
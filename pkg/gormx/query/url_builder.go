@@ -4,27 +4,45 @@ import (
 	"fmt"
 	"net/url"
 	"reflect"
+	"strconv"
 	"strings"
 )
 
 // StructToTableParams 将结构体转换为 Table 函数的参数格式
 // 根据 search 标签自动转换
 // 返回 URL 查询字符串，格式：eq=field:value&in=field:value1,value2&sorts=id:desc
+// 没有 search 标签的 Sorts、PageSize 字段作为排序和每页数量
 func StructToTableParams(params interface{}) (string, error) {
 	if params == nil {
 		return "", nil
 	}
 
+	result, err := StructToSearchFilterPageReq(params)
+	if err != nil {
+		return "", err
+	}
+
+	// 转换为 URL 查询字符串
+	return buildSearchParamsURL(result), nil
+}
+
+// StructToSearchFilterPageReq 根据 search 标签把结构体转换为搜索条件
+// 用于表单式的请求结构体（如图表的筛选条件）复用表格搜索的查询逻辑
+func StructToSearchFilterPageReq(params interface{}) (*SearchFilterPageReq, error) {
+	var result SearchFilterPageReq
+	if params == nil {
+		return &result, nil
+	}
+
 	v := reflect.ValueOf(params)
 	if v.Kind() == reflect.Ptr {
 		v = v.Elem()
 	}
 	if v.Kind() != reflect.Struct {
-		return "", fmt.Errorf("参数必须是结构体类型")
+		return nil, fmt.Errorf("参数必须是结构体类型")
 	}
 
 	t := v.Type()
-	var result SearchFilterPageReq
 
 	for i := 0; i < v.NumField(); i++ {
 		field := t.Field(i)
@@ -35,12 +53,26 @@ func StructToTableParams(params interface{}) (string, error) {
 			continue
 		}
 
+		// 过滤表达式字段（*FilterNode / FilterParam），多个字段之间是 AND 关系
+		if node, ok, err := filterFromValue(fieldValue); ok {
+			if err != nil {
+				return nil, fmt.Errorf("字段 %s 的过滤表达式无效: %w", field.Name, err)
+			}
+			if _, err := result.WithFilter(node); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
 		// 获取 search 标签
 		searchTag := field.Tag.Get("search")
 		if searchTag == "" {
-			// 如果没有 search 标签，检查是否是排序字段
-			if field.Name == "Sorts" {
+			// 如果没有 search 标签，检查是否是排序、每页数量字段
+			switch {
+			case field.Name == "Sorts":
 				result.Sorts = fieldValue.String()
+			case field.Name == "PageSize" && fieldValue.CanInt():
+				result.PageSize = int(fieldValue.Int())
 			}
 			continue
 		}
@@ -81,12 +113,14 @@ func StructToTableParams(params interface{}) (string, error) {
 				result.Gt = append(result.Gt, fmt.Sprintf("%s:%v", fieldName, fieldValue.Interface()))
 			case "lt":
 				result.Lt = append(result.Lt, fmt.Sprintf("%s:%v", fieldName, fieldValue.Interface()))
+			case "fulltext":
+				// 全文检索字段的值作为关键词
+				result.Keyword = strings.TrimSpace(result.Keyword + " " + fmt.Sprintf("%v", fieldValue.Interface()))
 			}
 		}
 	}
 
-	// 转换为 URL 查询字符串
-	return buildSearchParamsURL(&result), nil
+	return &result, nil
 }
 
 // buildSearchParamsURL 将 SearchFilterPageReq 转换为 URL 查询字符串
@@ -117,10 +151,42 @@ func buildSearchParamsURL(req *SearchFilterPageReq) string {
 	if req.Sorts != "" {
 		values.Set("sorts", req.Sorts)
 	}
+	if req.PageSize > 0 {
+		values.Set("page_size", strconv.Itoa(req.PageSize))
+	}
+	if req.Keyword != "" {
+		values.Set("keyword", req.Keyword)
+	}
+	if req.Filter != "" {
+		values.Set("filter", string(req.Filter))
+	}
+	if len(req.Aggs) > 0 {
+		values.Set("aggs", strings.Join(req.Aggs, ","))
+	}
+	if len(req.GroupBy) > 0 {
+		values.Set("group_by", strings.Join(req.GroupBy, ","))
+	}
+	if req.TZOffset != 0 {
+		values.Set("tz_offset", strconv.Itoa(req.TZOffset))
+	}
 
 	return values.Encode()
 }
 
+// filterFromValue 字段是过滤表达式类型时返回解析后的表达式
+func filterFromValue(v reflect.Value) (*FilterNode, bool, error) {
+	switch value := v.Interface().(type) {
+	case *FilterNode:
+		return value, true, nil
+	case FilterNode:
+		return &value, true, nil
+	case FilterParam:
+		node, err := value.Parse()
+		return node, true, err
+	}
+	return nil, false, nil
+}
+
 // getJSONTag 获取字段的 json 标签
 func getJSONTag(field reflect.StructField) string {
 	jsonTag := field.Tag.Get("json")
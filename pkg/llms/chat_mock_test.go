@@ -0,0 +1,404 @@
+package llms
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// 真实 API 的测试需要 API Key 和网络，放在 llm_live 构建标签下：go test -tags llm_live ./pkg/llms/
+// 这里用 MockClient（以及模拟提供商接口的 httptest 服务）覆盖同样的场景，默认离线运行
+
+func newScriptedClient(t *testing.T, script *MockScript) *MockClient {
+	t.Helper()
+	client, err := NewLLMClientWithOptions(ProviderMock, "", DefaultClientOptions().WithMock(script))
+	if err != nil {
+		t.Fatalf("NewLLMClientWithOptions() error = %v", err)
+	}
+	return client.(*MockClient)
+}
+
+// collectStream 读完流式通道，返回拼接后的内容、思考过程和最后一个数据块
+func collectStream(t *testing.T, stream <-chan *StreamChunk) (content, thinking string, last *StreamChunk) {
+	t.Helper()
+	var contentBuf, thinkingBuf strings.Builder
+	for chunk := range stream {
+		if chunk == nil {
+			t.Fatal("流式数据块不应该为nil")
+		}
+		if last != nil && last.Done {
+			t.Fatalf("完成片段之后还有数据块: %+v", chunk)
+		}
+		contentBuf.WriteString(chunk.Content)
+		thinkingBuf.WriteString(chunk.Thinking)
+		last = chunk
+	}
+	if last == nil {
+		t.Fatal("流式响应没有任何数据块")
+	}
+	return contentBuf.String(), thinkingBuf.String(), last
+}
+
+// TestMockChatBasic 基本聊天（对应 simple_test、各提供商的 ChatBasic）
+func TestMockChatBasic(t *testing.T) {
+	client := newScriptedClient(t, &MockScript{Responses: []MockResponse{
+		{Content: "你好，我是一个AI助手。"},
+	}})
+	resp, err := client.Chat(context.Background(), &ChatRequest{
+		Messages:    []Message{{Role: "user", Content: "你好，请简单介绍一下你自己"}},
+		MaxTokens:   100,
+		Temperature: 0.7,
+	})
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	if resp.Content != "你好，我是一个AI助手。" || resp.Error != "" {
+		t.Fatalf("resp = %+v", resp)
+	}
+	if resp.Usage == nil || resp.Usage.TotalTokens != resp.Usage.PromptTokens+resp.Usage.CompletionTokens || resp.Usage.CompletionTokens == 0 {
+		t.Fatalf("usage = %+v", resp.Usage)
+	}
+	if client.GetProvider() != string(ProviderMock) {
+		t.Fatalf("provider = %s", client.GetProvider())
+	}
+}
+
+// TestMockChatWithSystemPrompt 系统提示和多轮对话按原样传给提供商（对应 ChatWithSystemMessage）
+func TestMockChatWithSystemPrompt(t *testing.T) {
+	client := newScriptedClient(t, &MockScript{Responses: []MockResponse{
+		{Match: "Go", Content: "Go 是一门编译型语言"},
+		{Content: "默认回答"},
+	}})
+	messages := []Message{
+		{Role: "system", Content: "你是一个专业的编程助手"},
+		{Role: "user", Content: "你好"},
+		{Role: "assistant", Content: "你好！"},
+		{Role: "user", Content: "介绍一下 Go"},
+	}
+	resp, err := client.Chat(context.Background(), &ChatRequest{Messages: messages})
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	if resp.Content != "Go 是一门编译型语言" {
+		t.Fatalf("content = %q", resp.Content)
+	}
+
+	requests := client.Requests()
+	if len(requests) != 1 || len(requests[0].Messages) != len(messages) {
+		t.Fatalf("requests = %+v", requests)
+	}
+	for i, msg := range requests[0].Messages {
+		if msg != messages[i] {
+			t.Fatalf("message[%d] = %+v, want %+v", i, msg, messages[i])
+		}
+	}
+}
+
+// TestMockCodeGeneration 代码生成（对应 CodeGeneration、qwen3_coder）
+func TestMockCodeGeneration(t *testing.T) {
+	code := "```go\npackage main\n\nfunc Add(a, b int) int {\n\treturn a + b\n}\n```"
+	client := newScriptedClient(t, &MockScript{Responses: []MockResponse{{Match: "函数", Content: code}}})
+	resp, err := client.Chat(context.Background(), &ChatRequest{
+		Messages: []Message{{Role: "user", Content: "用Go写一个两数相加的函数"}},
+	})
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	if !strings.Contains(resp.Content, "func Add(a, b int) int") {
+		t.Fatalf("content = %q", resp.Content)
+	}
+}
+
+// TestMockChatStreamAssembly 流式响应按顺序输出内容，最后一个数据块标记完成并带用量（对应 stream_test）
+func TestMockChatStreamAssembly(t *testing.T) {
+	cases := []struct {
+		name   string
+		resp   MockResponse
+		chunks int
+	}{
+		{"content split by chunk size", MockResponse{Content: "流式输出的完整内容"}, 3 + 1},
+		{"explicit chunks", MockResponse{Chunks: []string{"Hello", ", ", "World"}}, 3 + 1},
+		{"thinking before content", MockResponse{Thinking: "先想一想", Content: "回答"}, 2 + 1 + 1},
+		{"empty content", MockResponse{}, 1},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := newScriptedClient(t, &MockScript{ChunkSize: 3, Responses: []MockResponse{tc.resp}})
+			stream, err := client.ChatStream(context.Background(), &ChatRequest{Messages: []Message{{Role: "user", Content: "Test"}}})
+			if err != nil {
+				t.Fatalf("ChatStream() error = %v", err)
+			}
+
+			var chunks []*StreamChunk
+			for chunk := range stream {
+				chunks = append(chunks, chunk)
+			}
+			if len(chunks) != tc.chunks {
+				t.Fatalf("chunks = %d, want %d", len(chunks), tc.chunks)
+			}
+			var content, thinking strings.Builder
+			for _, chunk := range chunks {
+				content.WriteString(chunk.Content)
+				thinking.WriteString(chunk.Thinking)
+			}
+			want := tc.resp.Content
+			if len(tc.resp.Chunks) > 0 {
+				want = strings.Join(tc.resp.Chunks, "")
+			}
+			if content.String() != want || thinking.String() != tc.resp.Thinking {
+				t.Fatalf("content = %q, thinking = %q", content.String(), thinking.String())
+			}
+			last := chunks[len(chunks)-1]
+			if !last.Done || last.Error != "" || last.Usage == nil {
+				t.Fatalf("last chunk = %+v", last)
+			}
+		})
+	}
+}
+
+// TestMockChatStreamError 流式响应中途出错时以错误数据块结束（对应 ChatStreamInvalidAPIKey）
+func TestMockChatStreamError(t *testing.T) {
+	client := newScriptedClient(t, &MockScript{Responses: []MockResponse{
+		{Content: "部分内容", Error: "HTTP请求失败，状态码: 401"},
+	}})
+	stream, err := client.ChatStream(context.Background(), &ChatRequest{Messages: []Message{{Role: "user", Content: "hi"}}})
+	if err != nil {
+		t.Fatalf("ChatStream() error = %v", err)
+	}
+	content, _, last := collectStream(t, stream)
+	if content != "部分内容" || !last.Done || last.Error == "" {
+		t.Fatalf("content = %q, last = %+v", content, last)
+	}
+	if class := ClassifyError(errors.New(last.Error)); class != ErrorClassAuth {
+		t.Fatalf("error class = %s", class)
+	}
+}
+
+// TestMockChatErrorClasses 提供商错误的分类（对应各提供商的 ErrorHandling、InvalidAPIKey）
+func TestMockChatErrorClasses(t *testing.T) {
+	cases := []struct {
+		err  string
+		want string
+	}{
+		{"HTTP请求失败，状态码: 401", ErrorClassAuth},
+		{"HTTP请求失败，状态码: 429", ErrorClassRateLimit},
+		{"HTTP请求失败，状态码: 503", ErrorClassServer},
+		{"HTTP请求失败，状态码: 400", ErrorClassInvalid},
+	}
+	for _, tc := range cases {
+		t.Run(tc.err, func(t *testing.T) {
+			client := newScriptedClient(t, &MockScript{Responses: []MockResponse{{Error: tc.err}}})
+			_, err := client.Chat(context.Background(), &ChatRequest{Messages: []Message{{Role: "user", Content: "hi"}}})
+			if err == nil {
+				t.Fatal("Chat() expected error")
+			}
+			if class := ClassifyError(err); class != tc.want {
+				t.Fatalf("ClassifyError(%v) = %s, want %s", err, class, tc.want)
+			}
+		})
+	}
+}
+
+// TestMockChatStreamTimeout 流式响应超过 context 超时时以错误数据块结束（对应 ChatStreamTimeout）
+func TestMockChatStreamTimeout(t *testing.T) {
+	client := newScriptedClient(t, &MockScript{ChunkSize: 1, Responses: []MockResponse{
+		{Content: "很慢的回答", DelayMs: 200},
+	}})
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	stream, err := client.ChatStream(ctx, &ChatRequest{Messages: []Message{{Role: "user", Content: "hi"}}})
+	if err != nil {
+		t.Fatalf("ChatStream() error = %v", err)
+	}
+	content, _, last := collectStream(t, stream)
+	if !last.Done || last.Error == "" || content == "很慢的回答" {
+		t.Fatalf("content = %q, last = %+v", content, last)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("超时后没有及时结束: %v", elapsed)
+	}
+}
+
+// newGLMStreamServer 模拟 GLM 流式接口：先输出思考过程（reasoning_content），再输出回答内容
+func newGLMStreamServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	events := []string{
+		`{"choices":[{"delta":{"reasoning_content":"先分析需求"}}]}`,
+		`{"choices":[{"delta":{"reasoning_content":"，再写代码"}}]}`,
+		`{"choices":[{"delta":{"content":"package main"}}]}`,
+		`{"choices":[{"delta":{"content":"\n"},"finish_reason":"stop"}],"usage":{"prompt_tokens":3,"completion_tokens":5,"total_tokens":8}}`,
+	}
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer glm-key" {
+			t.Errorf("Authorization = %q", got)
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, event := range events {
+			fmt.Fprintf(w, "data: %s\n\n", event)
+		}
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+}
+
+// TestGLMChatStreamThinking GLM 流式思考过程：默认作为内容输出（保持原有行为），SplitThinking 时单独输出
+func TestGLMChatStreamThinking(t *testing.T) {
+	server := newGLMStreamServer(t)
+	defer server.Close()
+	client := NewGLMClientWithOptions("glm-key", DefaultClientOptions().WithBaseURL(server.URL))
+
+	cases := []struct {
+		name         string
+		split        bool
+		wantContent  string
+		wantThinking string
+	}{
+		{"default merges reasoning into content", false, "先分析需求，再写代码package main\n", ""},
+		{"split thinking", true, "package main\n", "先分析需求，再写代码"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			stream, err := client.ChatStream(context.Background(), &ChatRequest{
+				Messages:      []Message{{Role: "user", Content: "写个函数"}},
+				SplitThinking: tc.split,
+			})
+			if err != nil {
+				t.Fatalf("ChatStream() error = %v", err)
+			}
+			content, thinking, last := collectStream(t, stream)
+			if content != tc.wantContent || thinking != tc.wantThinking {
+				t.Fatalf("content = %q, thinking = %q", content, thinking)
+			}
+			if !last.Done || last.Error != "" || last.Usage == nil || last.Usage.TotalTokens != 8 {
+				t.Fatalf("last chunk = %+v", last)
+			}
+		})
+	}
+}
+
+// TestStreamChunk 测试流式数据块结构
+func TestStreamChunk(t *testing.T) {
+	// 测试内容片段
+	contentChunk := &StreamChunk{
+		Content: "Hello",
+		Done:    false,
+	}
+
+	if contentChunk.Content != "Hello" {
+		t.Error("内容片段内容不正确")
+	}
+
+	if contentChunk.Done {
+		t.Error("内容片段不应该标记为完成")
+	}
+
+	// 测试完成片段
+	doneChunk := &StreamChunk{
+		Content: "",
+		Done:    true,
+		Usage: &Usage{
+			PromptTokens:     10,
+			CompletionTokens: 5,
+			TotalTokens:      15,
+		},
+	}
+
+	if !doneChunk.Done {
+		t.Error("完成片段应该标记为完成")
+	}
+
+	if doneChunk.Usage == nil {
+		t.Error("完成片段应该包含使用统计")
+	}
+
+	// 测试错误片段
+	errorChunk := &StreamChunk{
+		Error: "API错误",
+		Done:  true,
+	}
+
+	if errorChunk.Error == "" {
+		t.Error("错误片段应该包含错误信息")
+	}
+
+	if !errorChunk.Done {
+		t.Error("错误片段应该标记为完成")
+	}
+}
+
+// TestThinkingStreamDetection 测试思考过程内容检测
+func TestThinkingStreamDetection(t *testing.T) {
+	testCases := []struct {
+		name     string
+		content  string
+		expected bool
+	}{
+		{"标准思考标记", "<thinking>这是思考内容</thinking>", true},
+		{"代码块思考", "```thinking\n这是思考内容\n```", true},
+		{"Markdown思考", "**思考过程**\n这是思考内容", true},
+		{"英文思考", "Let me think about this...", true},
+		{"普通内容", "这是普通的回答内容", false},
+		{"空内容", "", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			hasThinking := detectThinkingContent(tc.content)
+			if hasThinking != tc.expected {
+				t.Errorf("检测结果不匹配: 期望 %v, 实际 %v", tc.expected, hasThinking)
+			}
+		})
+	}
+}
+
+// detectThinkingContent 检测内容是否包含思考过程
+func detectThinkingContent(content string) bool {
+	thinkingMarkers := []string{
+		"<thinking>",
+		"</thinking>",
+		"```thinking",
+		"**思考过程**",
+		"Let me think",
+		"思考一下",
+		"让我想想",
+	}
+
+	for _, marker := range thinkingMarkers {
+		if containsString(content, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsString 检查字符串是否包含子字符串
+func containsString(s, substr string) bool {
+	return len(s) >= len(substr) && (s == substr ||
+		(len(s) > len(substr) && (s[:len(substr)] == substr ||
+			s[len(s)-len(substr):] == substr ||
+			indexOf(s, substr) >= 0)))
+}
+
+// indexOf 查找子字符串位置
+func indexOf(s, substr string) int {
+	for i := 0; i <= len(s)-len(substr); i++ {
+		if s[i:i+len(substr)] == substr {
+			return i
+		}
+	}
+	return -1
+}
+
+// minInt 返回两个整数中的较小值
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -1,3 +1,5 @@
+//go:build llm_live
+
 package llms
 
 import (
@@ -10,7 +10,7 @@ func TestEnvironmentVariableFallback(t *testing.T) {
 	// 保存原始环境变量
 	originalGLM := os.Getenv("GLM_API_KEY")
 	originalDeepSeek := os.Getenv("DEEPSEEK_API_KEY")
-	originalQwen := os.Getenv("QWEN_API_KEY")
+	originalQwen := os.Getenv("QIANWEN_API_KEY")
 
 	// 清理环境变量
 	defer func() {
@@ -25,9 +25,9 @@ func TestEnvironmentVariableFallback(t *testing.T) {
 			os.Unsetenv("DEEPSEEK_API_KEY")
 		}
 		if originalQwen != "" {
-			os.Setenv("QWEN_API_KEY", originalQwen)
+			os.Setenv("QIANWEN_API_KEY", originalQwen)
 		} else {
-			os.Unsetenv("QWEN_API_KEY")
+			os.Unsetenv("QIANWEN_API_KEY")
 		}
 	}()
 
@@ -61,7 +61,7 @@ func TestEnvironmentVariableFallback(t *testing.T) {
 	t.Run("Qwen_Environment_Fallback", func(t *testing.T) {
 		// 设置测试环境变量
 		testKey := "test-qwen-key-from-env"
-		os.Setenv("QWEN_API_KEY", testKey)
+		os.Setenv("QIANWEN_API_KEY", testKey)
 
 		// 使用空字符串创建客户端，应该从环境变量获取
 		client := NewQwenClient("")
@@ -17,6 +17,11 @@ const (
 	ProviderClaude     Provider = "claude"
 	ProviderGemini     Provider = "gemini"
 	ProviderGLM        Provider = "glm"
+
+	// ProviderOpenAICompatible 通用 OpenAI 兼容接口（vLLM、Ollama、LM Studio 等自建服务）
+	ProviderOpenAICompatible Provider = "openai_compatible"
+	// ProviderMock 回放脚本的 mock 提供商，用于离线测试
+	ProviderMock Provider = "mock"
 )
 
 // NewLLMClient 创建LLM客户端
@@ -24,7 +29,7 @@ func NewLLMClient(provider Provider, apiKey string) (LLMClient, error) {
 	// 如果API Key为空，尝试从环境变量获取
 	if apiKey == "" {
 		apiKey = getAPIKeyFromEnv(provider)
-		if apiKey == "" {
+		if apiKey == "" && providerRequiresAPIKey(provider) {
 			return nil, fmt.Errorf("未提供API Key且环境变量中未找到 %s 的配置", provider)
 		}
 	}
@@ -37,7 +42,7 @@ func NewLLMClientWithOptions(provider Provider, apiKey string, options *ClientOp
 	// 如果API Key为空，尝试从环境变量获取
 	if apiKey == "" {
 		apiKey = getAPIKeyFromEnv(provider)
-		if apiKey == "" {
+		if apiKey == "" && providerRequiresAPIKey(provider) {
 			return nil, fmt.Errorf("未提供API Key且环境变量中未找到 %s 的配置", provider)
 		}
 	}
@@ -64,6 +69,14 @@ func NewLLMClientWithOptions(provider Provider, apiKey string, options *ClientOp
 		return NewGeminiClientWithOptions(apiKey, options), nil
 	case ProviderGLM:
 		return NewGLMClientWithOptions(apiKey, options), nil
+	case ProviderOpenAICompatible:
+		client, err := NewOpenAICompatibleClientWithOptions(apiKey, options)
+		if err != nil {
+			return nil, err
+		}
+		return client, nil
+	case ProviderMock:
+		return NewMockClientWithOptions(options), nil
 	default:
 		return nil, fmt.Errorf("不支持的提供商: %s", provider)
 	}
@@ -87,11 +100,18 @@ func getAPIKeyFromEnv(provider Provider) string {
 		return os.Getenv("GEMINI_API_KEY")
 	case ProviderGLM:
 		return os.Getenv("GLM_API_KEY")
+	case ProviderOpenAICompatible:
+		return os.Getenv("OPENAI_COMPATIBLE_API_KEY")
 	default:
 		return ""
 	}
 }
 
+// providerRequiresAPIKey 提供商是否必须配置API Key（自建服务通常不需要鉴权，mock 不访问网络）
+func providerRequiresAPIKey(provider Provider) bool {
+	return provider != ProviderOpenAICompatible && provider != ProviderMock
+}
+
 // NewLLMClientFromEnv 从环境变量创建LLM客户端（推荐使用）
 func NewLLMClientFromEnv(provider Provider) (LLMClient, error) {
 	return NewLLMClient(provider, "")
@@ -148,6 +168,8 @@ func GetSupportedProviders() []Provider {
 		ProviderClaude,
 		ProviderGemini,
 		ProviderGLM,
+		ProviderOpenAICompatible,
+		ProviderMock,
 	}
 }
 
@@ -170,6 +192,10 @@ func GetProviderDisplayName(provider Provider) string {
 		return "Gemini"
 	case ProviderGLM:
 		return "GLM"
+	case ProviderOpenAICompatible:
+		return "OpenAI兼容接口"
+	case ProviderMock:
+		return "Mock"
 	default:
 		return string(provider)
 	}
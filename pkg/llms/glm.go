@@ -587,16 +587,27 @@ func (g *GLMClient) ChatStream(ctx context.Context, req *ChatRequest) (<-chan *S
 				if len(streamResp.Choices) > 0 {
 					choice := streamResp.Choices[0]
 
-					// 发送内容片段 - 优先使用reasoning_content（思考过程），其次使用content
-					content := choice.Delta.ReasoningContent
-					if content == "" {
-						content = choice.Delta.Content
-					}
+					if req.SplitThinking {
+						// 思考过程（reasoning_content）和回答内容（content）分开发送
+						if choice.Delta.ReasoningContent != "" || choice.Delta.Content != "" {
+							chunkChan <- &StreamChunk{
+								Content:  choice.Delta.Content,
+								Thinking: choice.Delta.ReasoningContent,
+								Done:     false,
+							}
+						}
+					} else {
+						// 发送内容片段 - 优先使用reasoning_content（思考过程），其次使用content
+						content := choice.Delta.ReasoningContent
+						if content == "" {
+							content = choice.Delta.Content
+						}
 
-					if content != "" {
-						chunkChan <- &StreamChunk{
-							Content: content,
-							Done:    false,
+						if content != "" {
+							chunkChan <- &StreamChunk{
+								Content: content,
+								Done:    false,
+							}
 						}
 					}
 
@@ -15,12 +15,12 @@ func TestGLMClientCreation(t *testing.T) {
 	}
 
 	// 测试默认值
-	if client.GetProvider() != "GLM" {
-		t.Errorf("期望提供商为 GLM，实际为 %s", client.GetProvider())
+	if client.GetProvider() != string(ProviderGLM) {
+		t.Errorf("期望提供商为 %s，实际为 %s", ProviderGLM, client.GetProvider())
 	}
 
-	if client.GetModelName() != "glm-4.5" {
-		t.Errorf("期望默认模型为 glm-4.5，实际为 %s", client.GetModelName())
+	if client.GetModelName() != "glm-4.6" {
+		t.Errorf("期望默认模型为 glm-4.6，实际为 %s", client.GetModelName())
 	}
 
 	// 测试带配置的创建
@@ -86,6 +86,7 @@ func TestGLMThinkingMode(t *testing.T) {
 	client := NewGLMClient("test-api-key")
 
 	// 测试思考模式支持
+	client.SetModel("glm-4.5")
 	if !client.IsThinkingEnabled() {
 		t.Error("GLM-4.5系列应该支持思考模式")
 	}
@@ -130,8 +131,8 @@ func TestGLMFromEnv(t *testing.T) {
 		t.Fatal("期望创建客户端成功，但得到nil")
 	}
 
-	if client.GetProvider() != "GLM" {
-		t.Errorf("期望提供商为 GLM，实际为 %s", client.GetProvider())
+	if client.GetProvider() != string(ProviderGLM) {
+		t.Errorf("期望提供商为 %s，实际为 %s", ProviderGLM, client.GetProvider())
 	}
 }
 
@@ -152,8 +153,8 @@ func TestGLMFactoryIntegration(t *testing.T) {
 		t.Fatal("期望返回GLMClient类型")
 	}
 
-	if glmClient.GetProvider() != "GLM" {
-		t.Errorf("期望提供商为 GLM，实际为 %s", glmClient.GetProvider())
+	if glmClient.GetProvider() != string(ProviderGLM) {
+		t.Errorf("期望提供商为 %s，实际为 %s", ProviderGLM, glmClient.GetProvider())
 	}
 }
 
@@ -272,8 +273,8 @@ func TestGLMClientOptions(t *testing.T) {
 	client := NewGLMClientWithOptions("test-api-key", options)
 
 	// 测试默认选项
-	if client.Options.Timeout != 60*time.Second {
-		t.Errorf("期望默认超时时间为60秒，实际为 %v", client.Options.Timeout)
+	if client.Options.Timeout != 1200*time.Second {
+		t.Errorf("期望默认超时时间为1200秒，实际为 %v", client.Options.Timeout)
 	}
 
 	if client.Options.MaxIdleConns != 10 {
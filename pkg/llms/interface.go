@@ -19,6 +19,12 @@ type ClientOptions struct {
 	// LLM配置
 	Model string `json:"model"` // 模型名称（可选，如果设置则覆盖客户端默认模型）
 
+	// OpenAI 兼容接口配置
+	Headers map[string]string `json:"headers,omitempty"` // 额外请求头（如自建服务的鉴权头）
+
+	// Mock 配置
+	Mock *MockScript `json:"mock,omitempty"` // 回放的脚本（仅 mock 提供商使用）
+
 	// 重试配置
 	MaxRetries int           `json:"max_retries"` // 最大重试次数
 	RetryDelay time.Duration `json:"retry_delay"` // 重试延迟时间
@@ -69,6 +75,18 @@ func (o *ClientOptions) WithModel(model string) *ClientOptions {
 	return o
 }
 
+// WithHeaders 设置额外请求头
+func (o *ClientOptions) WithHeaders(headers map[string]string) *ClientOptions {
+	o.Headers = headers
+	return o
+}
+
+// WithMock 设置 mock 提供商回放的脚本
+func (o *ClientOptions) WithMock(script *MockScript) *ClientOptions {
+	o.Mock = script
+	return o
+}
+
 // Message 对话消息结构
 type Message struct {
 	Role    string `json:"role"`    // system, user, assistant
@@ -83,6 +101,10 @@ type ChatRequest struct {
 	Temperature float64        `json:"temperature"`            // 温度参数（可选）
 	Timeout     *time.Duration `json:"timeout,omitempty"`      // 请求超时时间（可选，覆盖客户端默认超时）
 	UseThinking *bool          `json:"use_thinking,omitempty"` // 是否使用思考模式（可选，GLM特有功能）
+
+	// SplitThinking 流式输出时是否把思考过程单独放在 StreamChunk.Thinking 中
+	// 默认 false 与原有行为一致：思考过程作为 Content 输出（GLM 优先输出 reasoning_content）
+	SplitThinking bool `json:"-"`
 }
 
 // ChatResponse 聊天响应
@@ -101,10 +123,11 @@ type Usage struct {
 
 // StreamChunk 流式响应数据块
 type StreamChunk struct {
-	Content string `json:"content"`         // 流式内容片段
-	Done    bool   `json:"done"`            // 是否完成
-	Error   string `json:"error,omitempty"` // 错误信息（如果有）
-	Usage   *Usage `json:"usage,omitempty"` // 使用统计（完成时提供）
+	Content  string `json:"content"`            // 流式内容片段
+	Thinking string `json:"thinking,omitempty"` // 思考过程片段（支持思考模式的模型，如 GLM）
+	Done     bool   `json:"done"`               // 是否完成
+	Error    string `json:"error,omitempty"`    // 错误信息（如果有）
+	Usage    *Usage `json:"usage,omitempty"`    // 使用统计（完成时提供）
 }
 
 // LLMClient 大模型客户端接口
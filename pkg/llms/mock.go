@@ -0,0 +1,296 @@
+package llms
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const mockDefaultChunkSize = 16
+
+// MockResponse mock 提供商回放的一次响应
+type MockResponse struct {
+	Match    string   `json:"match,omitempty"`    // 最后一条用户消息包含该文本时使用（为空表示按顺序使用）
+	Content  string   `json:"content,omitempty"`  // 回答内容
+	Thinking string   `json:"thinking,omitempty"` // 思考过程（流式回放时在内容之前输出）
+	Chunks   []string `json:"chunks,omitempty"`   // 流式回放的内容分片（为空时按 ChunkSize 切分 Content）
+	Usage    *Usage   `json:"usage,omitempty"`    // 用量（为空时按字符数生成）
+	Error    string   `json:"error,omitempty"`    // 返回的错误（如 "HTTP请求失败，状态码: 503"，用于测试切换和重试）
+	DelayMs  int      `json:"delay_ms,omitempty"` // 流式分片之间的等待时间
+}
+
+// MockScript mock 提供商的回放脚本
+// 按顺序回放 Match 为空的响应，用完后重复最后一条；没有可用响应时回显最后一条用户消息
+type MockScript struct {
+	Responses []MockResponse `json:"responses"`
+	ChunkSize int            `json:"chunk_size,omitempty"` // 流式分片的字符数（默认 16）
+}
+
+// LoadMockScript 从 JSON 文件加载回放脚本（可以是 RecordingClient 录制的结果）
+func LoadMockScript(path string) (*MockScript, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取mock脚本失败: %v", err)
+	}
+	var script MockScript
+	if err := json.Unmarshal(data, &script); err != nil {
+		return nil, fmt.Errorf("解析mock脚本失败: %v", err)
+	}
+	return &script, nil
+}
+
+// Save 保存回放脚本到 JSON 文件
+func (s *MockScript) Save(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化mock脚本失败: %v", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("写入mock脚本失败: %v", err)
+	}
+	return nil
+}
+
+// MockClient 确定性的 mock 客户端，回放脚本中的响应，不访问网络
+type MockClient struct {
+	Model string
+
+	script   *MockScript
+	mu       sync.Mutex
+	next     int            // 下一条按顺序回放的响应
+	requests []*ChatRequest // 收到的请求
+}
+
+// NewMockClientWithOptions 创建 mock 客户端（脚本来自 options.Mock）
+func NewMockClientWithOptions(options *ClientOptions) *MockClient {
+	if options == nil {
+		options = DefaultClientOptions()
+	}
+	script := options.Mock
+	if script == nil {
+		script = &MockScript{}
+	}
+	model := "mock"
+	if options.Model != "" {
+		model = options.Model
+	}
+	return &MockClient{Model: model, script: script}
+}
+
+// GetModelName 获取模型名称
+func (m *MockClient) GetModelName() string {
+	return m.Model
+}
+
+// GetProvider 获取提供商名称
+func (m *MockClient) GetProvider() string {
+	return string(ProviderMock)
+}
+
+// Requests 返回收到的所有请求（用于测试断言）
+func (m *MockClient) Requests() []*ChatRequest {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]*ChatRequest(nil), m.requests...)
+}
+
+// pick 选择本次回放的响应
+func (m *MockClient) pick(req *ChatRequest) MockResponse {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.requests = append(m.requests, req)
+
+	lastUser := ""
+	for i := len(req.Messages) - 1; i >= 0; i-- {
+		if req.Messages[i].Role == "user" {
+			lastUser = req.Messages[i].Content
+			break
+		}
+	}
+
+	var sequential []MockResponse
+	for _, resp := range m.script.Responses {
+		if resp.Match == "" {
+			sequential = append(sequential, resp)
+		} else if strings.Contains(lastUser, resp.Match) {
+			return resp
+		}
+	}
+	if len(sequential) == 0 {
+		return MockResponse{Content: "mock: " + lastUser}
+	}
+	idx := m.next
+	if idx >= len(sequential) {
+		idx = len(sequential) - 1
+	} else {
+		m.next++
+	}
+	return sequential[idx]
+}
+
+// usageOf 响应的用量（脚本未指定时按字符数生成）
+func (m *MockClient) usageOf(req *ChatRequest, resp MockResponse) *Usage {
+	if resp.Usage != nil {
+		return resp.Usage
+	}
+	usage := &Usage{}
+	for _, msg := range req.Messages {
+		usage.PromptTokens += len([]rune(msg.Content))
+	}
+	usage.CompletionTokens = len([]rune(resp.Thinking)) + len([]rune(resp.Content))
+	usage.TotalTokens = usage.PromptTokens + usage.CompletionTokens
+	return usage
+}
+
+// Chat 实现LLMClient接口的Chat方法
+func (m *MockClient) Chat(ctx context.Context, req *ChatRequest) (*ChatResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	resp := m.pick(req)
+	if resp.Error != "" {
+		return nil, errors.New(resp.Error)
+	}
+	content := resp.Content
+	if content == "" {
+		content = strings.Join(resp.Chunks, "")
+	}
+	resp.Content = content
+	return &ChatResponse{Content: content, Usage: m.usageOf(req, resp)}, nil
+}
+
+// ChatStream 实现流式聊天接口：先输出思考过程，再输出内容分片，最后输出用量
+func (m *MockClient) ChatStream(ctx context.Context, req *ChatRequest) (<-chan *StreamChunk, error) {
+	resp := m.pick(req)
+	chunkSize := m.script.ChunkSize
+	if chunkSize <= 0 {
+		chunkSize = mockDefaultChunkSize
+	}
+
+	chunks := make([]*StreamChunk, 0)
+	for _, piece := range splitRunes(resp.Thinking, chunkSize) {
+		chunks = append(chunks, &StreamChunk{Thinking: piece})
+	}
+	pieces := resp.Chunks
+	if len(pieces) == 0 {
+		pieces = splitRunes(resp.Content, chunkSize)
+	} else {
+		resp.Content = strings.Join(pieces, "")
+	}
+	for _, piece := range pieces {
+		chunks = append(chunks, &StreamChunk{Content: piece})
+	}
+	if resp.Error != "" {
+		chunks = append(chunks, &StreamChunk{Error: resp.Error, Done: true})
+	} else {
+		chunks = append(chunks, &StreamChunk{Usage: m.usageOf(req, resp), Done: true})
+	}
+
+	chunkChan := make(chan *StreamChunk, 10)
+	go func() {
+		defer close(chunkChan)
+		for i, chunk := range chunks {
+			if i > 0 && resp.DelayMs > 0 {
+				select {
+				case <-ctx.Done():
+					chunkChan <- &StreamChunk{Error: ctx.Err().Error(), Done: true}
+					return
+				case <-time.After(time.Duration(resp.DelayMs) * time.Millisecond):
+				}
+			}
+			chunkChan <- chunk
+		}
+	}()
+	return chunkChan, nil
+}
+
+// splitRunes 按字符数切分文本
+func splitRunes(text string, size int) []string {
+	runes := []rune(text)
+	pieces := make([]string, 0, (len(runes)+size-1)/size)
+	for start := 0; start < len(runes); start += size {
+		end := start + size
+		if end > len(runes) {
+			end = len(runes)
+		}
+		pieces = append(pieces, string(runes[start:end]))
+	}
+	return pieces
+}
+
+// RecordingClient 包装真实客户端，把每次调用的响应录制为 mock 回放脚本
+type RecordingClient struct {
+	LLMClient
+
+	mu     sync.Mutex
+	script MockScript
+}
+
+// NewRecordingClient 创建录制客户端
+func NewRecordingClient(inner LLMClient) *RecordingClient {
+	return &RecordingClient{LLMClient: inner}
+}
+
+// Script 返回已录制的脚本（可以用 Save 保存，之后用 mock 提供商回放）
+func (r *RecordingClient) Script() *MockScript {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return &MockScript{
+		Responses: append([]MockResponse(nil), r.script.Responses...),
+		ChunkSize: r.script.ChunkSize,
+	}
+}
+
+func (r *RecordingClient) record(resp MockResponse) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.script.Responses = append(r.script.Responses, resp)
+}
+
+// Chat 调用真实客户端并录制响应
+func (r *RecordingClient) Chat(ctx context.Context, req *ChatRequest) (*ChatResponse, error) {
+	resp, err := r.LLMClient.Chat(ctx, req)
+	if err != nil {
+		r.record(MockResponse{Error: err.Error()})
+		return nil, err
+	}
+	r.record(MockResponse{Content: resp.Content, Usage: resp.Usage})
+	return resp, nil
+}
+
+// ChatStream 调用真实客户端并按原样录制流式分片
+func (r *RecordingClient) ChatStream(ctx context.Context, req *ChatRequest) (<-chan *StreamChunk, error) {
+	stream, err := r.LLMClient.ChatStream(ctx, req)
+	if err != nil {
+		r.record(MockResponse{Error: err.Error()})
+		return nil, err
+	}
+
+	out := make(chan *StreamChunk, cap(stream))
+	go func() {
+		defer close(out)
+		var recorded MockResponse
+		var thinking strings.Builder
+		for chunk := range stream {
+			thinking.WriteString(chunk.Thinking)
+			if chunk.Content != "" {
+				recorded.Chunks = append(recorded.Chunks, chunk.Content)
+			}
+			if chunk.Usage != nil {
+				recorded.Usage = chunk.Usage
+			}
+			if chunk.Error != "" {
+				recorded.Error = chunk.Error
+			}
+			out <- chunk
+		}
+		recorded.Thinking = thinking.String()
+		r.record(recorded)
+	}()
+	return out, nil
+}
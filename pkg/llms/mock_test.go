@@ -0,0 +1,119 @@
+package llms
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+func TestMockChatSequence(t *testing.T) {
+	script := &MockScript{Responses: []MockResponse{
+		{Match: "标题", Content: "会话标题"},
+		{Content: "第一次"},
+		{Content: "第二次", Usage: &Usage{PromptTokens: 1, CompletionTokens: 2, TotalTokens: 3}},
+	}}
+	client, err := NewLLMClientWithOptions(ProviderMock, "", DefaultClientOptions().WithMock(script))
+	if err != nil {
+		t.Fatalf("NewLLMClientWithOptions() error = %v", err)
+	}
+
+	ask := func(content string) *ChatResponse {
+		resp, err := client.Chat(context.Background(), &ChatRequest{Messages: []Message{{Role: "user", Content: content}}})
+		if err != nil {
+			t.Fatalf("Chat(%q) error = %v", content, err)
+		}
+		return resp
+	}
+	if got := ask("写个函数").Content; got != "第一次" {
+		t.Fatalf("first = %q", got)
+	}
+	if got := ask("生成一个标题").Content; got != "会话标题" {
+		t.Fatalf("match = %q", got)
+	}
+	if resp := ask("再来"); resp.Content != "第二次" || resp.Usage.TotalTokens != 3 {
+		t.Fatalf("second = %+v", resp)
+	}
+	// 顺序响应用完后重复最后一条
+	if got := ask("还要").Content; got != "第二次" {
+		t.Fatalf("repeat = %q", got)
+	}
+	if n := len(client.(*MockClient).Requests()); n != 4 {
+		t.Fatalf("requests = %d", n)
+	}
+}
+
+func TestMockChatError(t *testing.T) {
+	client := NewMockClientWithOptions(DefaultClientOptions().WithMock(&MockScript{Responses: []MockResponse{
+		{Error: "HTTP请求失败，状态码: 429"},
+		{Content: "ok"},
+	}}))
+	_, err := client.Chat(context.Background(), &ChatRequest{Messages: []Message{{Role: "user", Content: "hi"}}})
+	if ClassifyError(err) != ErrorClassRateLimit {
+		t.Fatalf("Chat() error = %v", err)
+	}
+
+	// 没有脚本时回显用户消息
+	echo := NewMockClientWithOptions(nil)
+	resp, _ := echo.Chat(context.Background(), &ChatRequest{Messages: []Message{{Role: "user", Content: "hi"}}})
+	if resp.Content != "mock: hi" || resp.Usage.PromptTokens != 2 {
+		t.Fatalf("echo = %+v", resp)
+	}
+}
+
+func TestMockChatStream(t *testing.T) {
+	client := NewMockClientWithOptions(DefaultClientOptions().WithMock(&MockScript{
+		ChunkSize: 2,
+		Responses: []MockResponse{{Thinking: "思考中", Content: "package main"}},
+	}))
+	stream, err := client.ChatStream(context.Background(), &ChatRequest{Messages: []Message{{Role: "user", Content: "hi"}}})
+	if err != nil {
+		t.Fatalf("ChatStream() error = %v", err)
+	}
+
+	var chunks []*StreamChunk
+	for chunk := range stream {
+		chunks = append(chunks, chunk)
+	}
+	// 思考过程 2 片、内容 6 片、结束 1 片
+	if len(chunks) != 9 || chunks[0].Thinking != "思考" || chunks[2].Content != "pa" {
+		t.Fatalf("chunks = %d", len(chunks))
+	}
+	last := chunks[len(chunks)-1]
+	if !last.Done || last.Usage == nil || last.Usage.CompletionTokens != 15 {
+		t.Fatalf("last chunk = %+v", last)
+	}
+}
+
+func TestRecordingClientReplay(t *testing.T) {
+	source := NewMockClientWithOptions(DefaultClientOptions().WithMock(&MockScript{Responses: []MockResponse{
+		{Thinking: "嗯", Chunks: []string{"a", "b"}, Usage: &Usage{TotalTokens: 9}},
+	}}))
+	recorder := NewRecordingClient(source)
+	stream, _ := recorder.ChatStream(context.Background(), &ChatRequest{Messages: []Message{{Role: "user", Content: "hi"}}})
+	for range stream {
+	}
+
+	path := filepath.Join(t.TempDir(), "script.json")
+	if err := recorder.Script().Save(path); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	script, err := LoadMockScript(path)
+	if err != nil {
+		t.Fatalf("LoadMockScript() error = %v", err)
+	}
+
+	replay := NewMockClientWithOptions(DefaultClientOptions().WithMock(script))
+	stream, _ = replay.ChatStream(context.Background(), &ChatRequest{Messages: []Message{{Role: "user", Content: "hi"}}})
+	var content, thinking string
+	var usage *Usage
+	for chunk := range stream {
+		content += chunk.Content
+		thinking += chunk.Thinking
+		if chunk.Usage != nil {
+			usage = chunk.Usage
+		}
+	}
+	if content != "ab" || thinking != "嗯" || usage == nil || usage.TotalTokens != 9 {
+		t.Fatalf("replay content %q, thinking %q, usage %+v", content, thinking, usage)
+	}
+}
@@ -0,0 +1,302 @@
+package llms
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/ai-agent-os/ai-agent-os/pkg/logger"
+)
+
+// OpenAICompatibleClient 通用 OpenAI 兼容接口客户端
+// 适用于 vLLM、Ollama、LM Studio 等提供 /v1/chat/completions 接口的自建服务，
+// 地址、模型和请求头全部来自配置，API Key 可以为空（不发送 Authorization 头）
+type OpenAICompatibleClient struct {
+	APIKey  string
+	BaseURL string
+	Options *ClientOptions
+	Model   string
+}
+
+// openAICompatibleRequest OpenAI 兼容接口请求结构
+type openAICompatibleRequest struct {
+	Model         string                 `json:"model"`
+	Messages      []Message              `json:"messages"`
+	MaxTokens     int                    `json:"max_tokens,omitempty"`
+	Temperature   float64                `json:"temperature,omitempty"`
+	Stream        bool                   `json:"stream,omitempty"`
+	StreamOptions map[string]interface{} `json:"stream_options,omitempty"`
+}
+
+// openAICompatibleUsage OpenAI 兼容接口用量
+type openAICompatibleUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+func (u *openAICompatibleUsage) toUsage() *Usage {
+	if u == nil {
+		return nil
+	}
+	return &Usage{
+		PromptTokens:     u.PromptTokens,
+		CompletionTokens: u.CompletionTokens,
+		TotalTokens:      u.TotalTokens,
+	}
+}
+
+// openAICompatibleError OpenAI 兼容接口错误（各实现的 code 类型不统一，按原样保留）
+type openAICompatibleError struct {
+	Message string      `json:"message"`
+	Type    string      `json:"type"`
+	Code    interface{} `json:"code"`
+}
+
+// openAICompatibleResponse OpenAI 兼容接口响应结构
+type openAICompatibleResponse struct {
+	Choices []struct {
+		Message struct {
+			Content          string `json:"content"`
+			ReasoningContent string `json:"reasoning_content"`
+		} `json:"message"`
+	} `json:"choices"`
+	Usage *openAICompatibleUsage `json:"usage,omitempty"`
+	Error *openAICompatibleError `json:"error,omitempty"`
+}
+
+// openAICompatibleStreamResponse OpenAI 兼容接口流式响应结构
+type openAICompatibleStreamResponse struct {
+	Choices []struct {
+		Delta struct {
+			Content          string `json:"content"`
+			ReasoningContent string `json:"reasoning_content"` // vLLM、DeepSeek 等的思考过程
+			Reasoning        string `json:"reasoning"`         // Ollama 等的思考过程
+		} `json:"delta"`
+		FinishReason *string `json:"finish_reason,omitempty"`
+	} `json:"choices"`
+	Usage *openAICompatibleUsage `json:"usage,omitempty"`
+	Error *openAICompatibleError `json:"error,omitempty"`
+}
+
+// NewOpenAICompatibleClientWithOptions 创建 OpenAI 兼容接口客户端
+// BaseURL 可以是服务根地址（如 http://localhost:11434/v1），也可以是完整的 chat/completions 地址
+func NewOpenAICompatibleClientWithOptions(apiKey string, options *ClientOptions) (*OpenAICompatibleClient, error) {
+	if options == nil {
+		options = DefaultClientOptions()
+	}
+	if options.BaseURL == "" {
+		return nil, fmt.Errorf("OpenAI 兼容接口需要配置 BaseURL")
+	}
+	if options.Model == "" {
+		return nil, fmt.Errorf("OpenAI 兼容接口需要配置模型名称")
+	}
+
+	baseURL := strings.TrimRight(options.BaseURL, "/")
+	if !strings.HasSuffix(baseURL, "/chat/completions") {
+		baseURL += "/chat/completions"
+	}
+
+	return &OpenAICompatibleClient{
+		APIKey:  apiKey,
+		BaseURL: baseURL,
+		Options: options,
+		Model:   options.Model,
+	}, nil
+}
+
+// GetModelName 获取模型名称
+func (c *OpenAICompatibleClient) GetModelName() string {
+	return c.Model
+}
+
+// GetProvider 获取提供商名称
+func (c *OpenAICompatibleClient) GetProvider() string {
+	return string(ProviderOpenAICompatible)
+}
+
+// buildRequest 构造 HTTP 请求（设置鉴权头和配置的额外请求头）
+func (c *OpenAICompatibleClient) buildRequest(ctx context.Context, req *ChatRequest, stream bool) (*http.Request, error) {
+	if len(req.Messages) == 0 {
+		return nil, fmt.Errorf("messages 不能为空")
+	}
+
+	apiReq := &openAICompatibleRequest{
+		Model:       c.Model,
+		Messages:    req.Messages,
+		MaxTokens:   req.MaxTokens,
+		Temperature: req.Temperature,
+		Stream:      stream,
+	}
+	if req.Model != "" {
+		apiReq.Model = req.Model
+	}
+	if stream {
+		// 要求在最后一个数据块中返回用量（不支持的服务会忽略）
+		apiReq.StreamOptions = map[string]interface{}{"include_usage": true}
+	}
+
+	jsonData, err := json.Marshal(apiReq)
+	if err != nil {
+		return nil, fmt.Errorf("序列化请求失败: %v", err)
+	}
+	if c.Options.EnableLogging {
+		logger.Infof(ctx, "[OpenAICompatible] 发送请求到: %s, 模型: %s, 请求体长度: %d", c.BaseURL, apiReq.Model, len(jsonData))
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", c.BaseURL, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("创建HTTP请求失败: %v", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if c.APIKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+c.APIKey)
+	}
+	if c.Options.UserAgent != "" {
+		httpReq.Header.Set("User-Agent", c.Options.UserAgent)
+	}
+	for key, value := range c.Options.Headers {
+		httpReq.Header.Set(key, value)
+	}
+	return httpReq, nil
+}
+
+// httpClient 创建 HTTP 客户端（请求级别的超时优先）
+func (c *OpenAICompatibleClient) httpClient(req *ChatRequest) *http.Client {
+	timeout := c.Options.Timeout
+	if req.Timeout != nil && *req.Timeout > 0 {
+		timeout = *req.Timeout
+	}
+	return createHTTPClient(c.Options, timeout)
+}
+
+// Chat 实现LLMClient接口的Chat方法
+func (c *OpenAICompatibleClient) Chat(ctx context.Context, req *ChatRequest) (*ChatResponse, error) {
+	httpReq, err := c.buildRequest(ctx, req, false)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.httpClient(req).Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("HTTP请求失败: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("读取响应失败: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP请求失败，状态码: %d, 响应: %s", resp.StatusCode, string(body))
+	}
+
+	var apiResp openAICompatibleResponse
+	if err := json.Unmarshal(body, &apiResp); err != nil {
+		return nil, fmt.Errorf("解析响应失败: %v", err)
+	}
+	if apiResp.Error != nil {
+		return nil, fmt.Errorf("OpenAI兼容接口错误: %v - %s", apiResp.Error.Code, apiResp.Error.Message)
+	}
+	if len(apiResp.Choices) == 0 {
+		return nil, fmt.Errorf("响应格式错误：没有找到choices")
+	}
+
+	content := apiResp.Choices[0].Message.Content
+	if content == "" {
+		return nil, fmt.Errorf("响应格式错误：content为空")
+	}
+	if c.Options.EnableLogging {
+		logger.Infof(ctx, "[OpenAICompatible] 响应成功 - ContentLength: %d, Usage: %+v", len(content), apiResp.Usage)
+	}
+
+	return &ChatResponse{
+		Content: content,
+		Usage:   apiResp.Usage.toUsage(),
+	}, nil
+}
+
+// ChatStream 实现流式聊天接口（SSE 格式）
+func (c *OpenAICompatibleClient) ChatStream(ctx context.Context, req *ChatRequest) (<-chan *StreamChunk, error) {
+	httpReq, err := c.buildRequest(ctx, req, true)
+	if err != nil {
+		return nil, err
+	}
+
+	chunkChan := make(chan *StreamChunk, 10)
+	go func() {
+		defer close(chunkChan)
+
+		resp, err := c.httpClient(req).Do(httpReq)
+		if err != nil {
+			chunkChan <- &StreamChunk{Error: fmt.Sprintf("HTTP请求失败: %v", err), Done: true}
+			return
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			body, _ := io.ReadAll(resp.Body)
+			chunkChan <- &StreamChunk{
+				Error: fmt.Sprintf("HTTP请求失败，状态码: %d, 响应: %s", resp.StatusCode, string(body)),
+				Done:  true,
+			}
+			return
+		}
+
+		// 用量在 finish_reason 之后的单独数据块中返回，所以读到 [DONE] 才结束
+		var usage *Usage
+		scanner := bufio.NewScanner(resp.Body)
+		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			line := scanner.Text()
+			if !strings.HasPrefix(line, "data:") {
+				continue
+			}
+			data := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+			if data == "[DONE]" {
+				break
+			}
+
+			var streamResp openAICompatibleStreamResponse
+			if err := json.Unmarshal([]byte(data), &streamResp); err != nil {
+				chunkChan <- &StreamChunk{Error: fmt.Sprintf("解析流式响应失败: %v", err), Done: true}
+				return
+			}
+			if streamResp.Error != nil {
+				chunkChan <- &StreamChunk{
+					Error: fmt.Sprintf("OpenAI兼容接口错误: %v - %s", streamResp.Error.Code, streamResp.Error.Message),
+					Done:  true,
+				}
+				return
+			}
+			if streamResp.Usage != nil {
+				usage = streamResp.Usage.toUsage()
+			}
+			if len(streamResp.Choices) == 0 {
+				continue
+			}
+
+			delta := streamResp.Choices[0].Delta
+			thinking := delta.ReasoningContent
+			if thinking == "" {
+				thinking = delta.Reasoning
+			}
+			if thinking != "" || delta.Content != "" {
+				chunkChan <- &StreamChunk{Content: delta.Content, Thinking: thinking}
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			chunkChan <- &StreamChunk{Error: fmt.Sprintf("读取流式响应失败: %v", err), Done: true}
+			return
+		}
+
+		chunkChan <- &StreamChunk{Usage: usage, Done: true}
+	}()
+
+	return chunkChan, nil
+}
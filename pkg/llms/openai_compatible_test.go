@@ -0,0 +1,109 @@
+package llms
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// newOpenAICompatibleServer 模拟 OpenAI 兼容服务，记录收到的请求头和请求体
+func newOpenAICompatibleServer(t *testing.T, headers *http.Header, body *map[string]interface{}) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" {
+			http.NotFound(w, r)
+			return
+		}
+		*headers = r.Header.Clone()
+		if err := json.NewDecoder(r.Body).Decode(body); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		if stream, _ := (*body)["stream"].(bool); stream {
+			w.Header().Set("Content-Type", "text/event-stream")
+			fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{\"reasoning_content\":\"想一想\"}}]}\n\n")
+			fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{\"content\":\"你\"}}]}\n\n")
+			fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{\"content\":\"好\"},\"finish_reason\":\"stop\"}]}\n\n")
+			fmt.Fprint(w, "data: {\"choices\":[],\"usage\":{\"prompt_tokens\":5,\"completion_tokens\":2,\"total_tokens\":7}}\n\n")
+			fmt.Fprint(w, "data: [DONE]\n\n")
+			return
+		}
+		fmt.Fprint(w, `{"choices":[{"message":{"content":"你好"}}],"usage":{"prompt_tokens":5,"completion_tokens":2,"total_tokens":7}}`)
+	}))
+}
+
+func TestOpenAICompatibleChat(t *testing.T) {
+	var headers http.Header
+	var body map[string]interface{}
+	server := newOpenAICompatibleServer(t, &headers, &body)
+	defer server.Close()
+
+	options := DefaultClientOptions().WithBaseURL(server.URL + "/v1/").WithModel("qwen2.5:7b").
+		WithHeaders(map[string]string{"X-Tenant": "dev"})
+	client, err := NewLLMClientWithOptions(ProviderOpenAICompatible, "", options)
+	if err != nil {
+		t.Fatalf("NewLLMClientWithOptions() error = %v", err)
+	}
+
+	resp, err := client.Chat(context.Background(), &ChatRequest{Messages: []Message{{Role: "user", Content: "hi"}}})
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	if resp.Content != "你好" || resp.Usage == nil || resp.Usage.TotalTokens != 7 {
+		t.Fatalf("Chat() = %+v", resp)
+	}
+	if body["model"] != "qwen2.5:7b" || headers.Get("X-Tenant") != "dev" || headers.Get("Authorization") != "" {
+		t.Fatalf("request model %v, headers %v", body["model"], headers)
+	}
+}
+
+func TestOpenAICompatibleChatStream(t *testing.T) {
+	var headers http.Header
+	var body map[string]interface{}
+	server := newOpenAICompatibleServer(t, &headers, &body)
+	defer server.Close()
+
+	client, err := NewOpenAICompatibleClientWithOptions("sk-local", DefaultClientOptions().WithBaseURL(server.URL+"/v1").WithModel("m"))
+	if err != nil {
+		t.Fatalf("NewOpenAICompatibleClientWithOptions() error = %v", err)
+	}
+	stream, err := client.ChatStream(context.Background(), &ChatRequest{Messages: []Message{{Role: "user", Content: "hi"}}})
+	if err != nil {
+		t.Fatalf("ChatStream() error = %v", err)
+	}
+
+	var content, thinking string
+	var last *StreamChunk
+	for chunk := range stream {
+		content += chunk.Content
+		thinking += chunk.Thinking
+		last = chunk
+	}
+	if content != "你好" || thinking != "想一想" {
+		t.Fatalf("content %q, thinking %q", content, thinking)
+	}
+	if !last.Done || last.Error != "" || last.Usage == nil || last.Usage.CompletionTokens != 2 {
+		t.Fatalf("last chunk = %+v", last)
+	}
+	if headers.Get("Authorization") != "Bearer sk-local" {
+		t.Fatalf("Authorization = %q", headers.Get("Authorization"))
+	}
+}
+
+func TestOpenAICompatibleHTTPError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "model is loading", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client, _ := NewOpenAICompatibleClientWithOptions("", DefaultClientOptions().WithBaseURL(server.URL).WithModel("m"))
+	_, err := client.Chat(context.Background(), &ChatRequest{Messages: []Message{{Role: "user", Content: "hi"}}})
+	if ClassifyError(err) != ErrorClassServer {
+		t.Fatalf("Chat() error = %v", err)
+	}
+
+	if _, err := NewOpenAICompatibleClientWithOptions("", DefaultClientOptions().WithModel("m")); err == nil {
+		t.Fatalf("expected error without BaseURL")
+	}
+}
@@ -0,0 +1,453 @@
+package llms
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 错误分类（路由策略根据分类决定是否在同一目标上重试）
+const (
+	ErrorClassTimeout   = "timeout"         // 请求超时
+	ErrorClassRateLimit = "rate_limit"      // 限流（429）
+	ErrorClassServer    = "server_error"    // 服务端错误（5xx）
+	ErrorClassNetwork   = "network"         // 网络错误（连接被拒绝、连接重置、DNS 等）
+	ErrorClassAuth      = "auth"            // 认证失败（401/403、API Key 错误）
+	ErrorClassInvalid   = "invalid_request" // 请求错误（其他 4xx），换目标重试通常也没有意义
+	ErrorClassOther     = "other"
+)
+
+// 路由策略
+const (
+	RouteStrategyFailover = "failover" // 按配置顺序依次尝试
+	RouteStrategyLatency  = "latency"  // 优先选择平均延迟最低的目标（没有统计数据的目标优先，用于探测）
+	RouteStrategyCost     = "cost"     // 优先选择成本最低的目标（未设置成本的排在最后）
+)
+
+// 熔断器状态
+const (
+	CircuitClosed   = "closed"    // 正常
+	CircuitOpen     = "open"      // 熔断中，直接跳过
+	CircuitHalfOpen = "half_open" // 熔断时间已过，允许一个探测请求
+)
+
+const (
+	defaultRouteBackoff         = time.Second
+	defaultCircuitThreshold     = 5
+	defaultCircuitOpenDuration  = 30 * time.Second
+	routeLatencySmoothingFactor = 0.3 // 延迟滑动平均中新样本的权重
+)
+
+// DefaultRetryOn 默认在同一目标上重试的错误分类
+var DefaultRetryOn = []string{ErrorClassTimeout, ErrorClassRateLimit, ErrorClassServer, ErrorClassNetwork}
+
+var statusCodePattern = regexp.MustCompile(`状态码: ?(\d{3})|status(?: code)?:? ?(\d{3})`)
+
+// ClassifyError 对调用错误进行分类
+// 各提供商的错误信息格式不统一（大部分是 "HTTP请求失败，状态码: 429" 这种文本），这里按状态码和关键字识别
+func ClassifyError(err error) string {
+	if err == nil {
+		return ""
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return ErrorClassTimeout
+	}
+	msg := strings.ToLower(err.Error())
+	if m := statusCodePattern.FindStringSubmatch(msg); m != nil {
+		code, _ := strconv.Atoi(m[1] + m[2])
+		switch {
+		case code == 429:
+			return ErrorClassRateLimit
+		case code == 401 || code == 403:
+			return ErrorClassAuth
+		case code == 408 || code == 504:
+			return ErrorClassTimeout
+		case code >= 500:
+			return ErrorClassServer
+		case code >= 400:
+			return ErrorClassInvalid
+		}
+	}
+	switch {
+	case containsAny(msg, "timeout", "deadline exceeded", "超时"):
+		return ErrorClassTimeout
+	case containsAny(msg, "rate limit", "too many requests", "限流", "频率"):
+		return ErrorClassRateLimit
+	case containsAny(msg, "connection refused", "connection reset", "no such host", "broken pipe", "eof", "network is unreachable", "tls"):
+		return ErrorClassNetwork
+	case containsAny(msg, "api key", "unauthorized", "invalid_api_key", "authentication"):
+		return ErrorClassAuth
+	}
+	return ErrorClassOther
+}
+
+func containsAny(s string, subs ...string) bool {
+	for _, sub := range subs {
+		if strings.Contains(s, sub) {
+			return true
+		}
+	}
+	return false
+}
+
+// RoutePolicy 路由策略
+type RoutePolicy struct {
+	Strategy   string        `json:"strategy"`    // 选择策略：failover（默认）、latency、cost
+	RetryOn    []string      `json:"retry_on"`    // 在同一目标上重试的错误分类（为空时使用 DefaultRetryOn）
+	MaxRetries int           `json:"max_retries"` // 同一目标的最大重试次数（不含首次请求），用完后切换到下一个目标
+	Backoff    time.Duration `json:"backoff"`     // 首次重试的等待时间，之后每次翻倍（默认 1 秒）
+}
+
+// retryable 该分类的错误是否在同一目标上重试
+func (p *RoutePolicy) retryable(class string) bool {
+	retryOn := p.RetryOn
+	if len(retryOn) == 0 {
+		retryOn = DefaultRetryOn
+	}
+	for _, c := range retryOn {
+		if c == class {
+			return true
+		}
+	}
+	return false
+}
+
+// backoff 第 attempt 次重试前的等待时间
+func (p *RoutePolicy) backoff(attempt int) time.Duration {
+	base := p.Backoff
+	if base <= 0 {
+		base = defaultRouteBackoff
+	}
+	return base << (attempt - 1)
+}
+
+// RouteTarget 路由目标（一组等价的模型配置之一）
+type RouteTarget struct {
+	Key     string       // 唯一标识，用于熔断和延迟统计（如 LLM 配置ID）
+	Client  LLMClient    // 客户端
+	Request *ChatRequest // 发给该目标的请求（不同目标的模型名、max_tokens 等可能不同）
+	Cost    float64      // 每千 token 成本（<=0 表示未设置）
+}
+
+// RouteAttempt 一次尝试的记录
+type RouteAttempt struct {
+	Key        string        `json:"key"`
+	ErrorClass string        `json:"error_class"`
+	Error      string        `json:"error"`
+	Latency    time.Duration `json:"latency"`
+}
+
+// RouteError 所有目标都调用失败
+type RouteError struct {
+	Attempts []RouteAttempt
+	Cause    error // 调用方取消或超时时为 ctx 的错误，否则为最后一次尝试的错误
+}
+
+func (e *RouteError) Error() string {
+	if len(e.Attempts) == 0 {
+		if e.Cause != nil {
+			return e.Cause.Error()
+		}
+		return "没有可用的模型"
+	}
+	parts := make([]string, 0, len(e.Attempts))
+	for _, a := range e.Attempts {
+		parts = append(parts, fmt.Sprintf("%s(%s): %s", a.Key, a.ErrorClass, a.Error))
+	}
+	return "所有模型均调用失败: " + strings.Join(parts, "; ")
+}
+
+func (e *RouteError) Unwrap() error {
+	return e.Cause
+}
+
+// errCircuitOpen 熔断中的目标被跳过
+var errCircuitOpen = errors.New("熔断中，已跳过")
+
+// Router LLM 路由器
+// 在多个等价的目标之间按策略选择，失败时按错误分类重试或切换到下一个目标；
+// 每个目标有独立的熔断器，连续失败达到阈值后在一段时间内直接跳过，之后放行一个探测请求决定是否恢复。
+// Router 是并发安全的，熔断和延迟统计在同一个 Router 的所有请求之间共享
+type Router struct {
+	FailureThreshold int           // 连续失败多少次后熔断
+	OpenDuration     time.Duration // 熔断持续时间
+
+	mu    sync.Mutex
+	stats map[string]*routeTargetStats
+}
+
+// routeTargetStats 目标的熔断状态和延迟统计
+type routeTargetStats struct {
+	state    string
+	failures int
+	openedAt time.Time
+	probing  bool          // 半开状态下是否已经放行了探测请求
+	latency  time.Duration // 延迟滑动平均（流式请求为首包延迟）
+}
+
+// NewRouter 创建路由器
+func NewRouter() *Router {
+	return &Router{
+		FailureThreshold: defaultCircuitThreshold,
+		OpenDuration:     defaultCircuitOpenDuration,
+		stats:            make(map[string]*routeTargetStats),
+	}
+}
+
+// Chat 按策略调用，返回响应和实际提供服务的目标
+func (r *Router) Chat(ctx context.Context, policy *RoutePolicy, targets []*RouteTarget) (*ChatResponse, *RouteTarget, error) {
+	var resp *ChatResponse
+	target, err := r.route(ctx, policy, targets, func(target *RouteTarget) error {
+		start := time.Now()
+		result, err := target.Client.Chat(ctx, target.Request)
+		if err == nil && result.Error != "" {
+			err = errors.New(result.Error)
+		}
+		if err != nil {
+			return err
+		}
+		resp = result
+		r.recordSuccess(target.Key, time.Since(start))
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return resp, target, nil
+}
+
+// ChatStream 按策略流式调用，返回流式响应通道和实际提供服务的目标
+// 只有在收到第一个数据块之前的失败才会切换目标（已经推送给调用方的内容无法撤回），
+// 之后的错误原样通过数据块返回。调用方必须读完返回的通道
+func (r *Router) ChatStream(ctx context.Context, policy *RoutePolicy, targets []*RouteTarget) (<-chan *StreamChunk, *RouteTarget, error) {
+	var out chan *StreamChunk
+	target, err := r.route(ctx, policy, targets, func(target *RouteTarget) error {
+		start := time.Now()
+		stream, err := target.Client.ChatStream(ctx, target.Request)
+		if err != nil {
+			return err
+		}
+		first, ok := <-stream
+		if !ok {
+			return errors.New("流式响应为空")
+		}
+		if first.Error != "" {
+			go drainStream(stream)
+			return errors.New(first.Error)
+		}
+		firstLatency := time.Since(start)
+
+		out = make(chan *StreamChunk, 100)
+		go func() {
+			defer close(out)
+			var streamErr string
+			out <- first
+			for chunk := range stream {
+				if chunk.Error != "" && streamErr == "" {
+					streamErr = chunk.Error
+				}
+				out <- chunk
+			}
+			// 流结束后才能确定这次调用是否成功
+			if streamErr != "" && ctx.Err() == nil {
+				r.recordFailure(target.Key, ClassifyError(errors.New(streamErr)))
+			} else if streamErr != "" {
+				r.release(target.Key)
+			} else {
+				r.recordSuccess(target.Key, firstLatency)
+			}
+		}()
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return out, target, nil
+}
+
+// route 按策略依次尝试目标
+// call 成功时负责记录成功（流式调用要等流结束），失败时由这里记录
+func (r *Router) route(ctx context.Context, policy *RoutePolicy, targets []*RouteTarget, call func(target *RouteTarget) error) (*RouteTarget, error) {
+	if policy == nil {
+		policy = &RoutePolicy{}
+	}
+	var attempts []RouteAttempt
+	for _, target := range r.order(policy, targets) {
+		for attempt := 0; attempt <= policy.MaxRetries; attempt++ {
+			if attempt > 0 {
+				select {
+				case <-ctx.Done():
+					return nil, &RouteError{Attempts: attempts, Cause: ctx.Err()}
+				case <-time.After(policy.backoff(attempt)):
+				}
+			}
+			if !r.allow(target.Key) {
+				attempts = append(attempts, RouteAttempt{Key: target.Key, ErrorClass: CircuitOpen, Error: errCircuitOpen.Error()})
+				break
+			}
+
+			start := time.Now()
+			err := call(target)
+			if err == nil {
+				return target, nil
+			}
+			if ctx.Err() != nil {
+				// 调用方取消或整体超时，不算目标的失败
+				r.release(target.Key)
+				return nil, &RouteError{Attempts: attempts, Cause: ctx.Err()}
+			}
+
+			class := ClassifyError(err)
+			r.recordFailure(target.Key, class)
+			attempts = append(attempts, RouteAttempt{Key: target.Key, ErrorClass: class, Error: err.Error(), Latency: time.Since(start)})
+			if !policy.retryable(class) {
+				break
+			}
+		}
+	}
+	routeErr := &RouteError{Attempts: attempts}
+	if n := len(attempts); n > 0 && attempts[n-1].ErrorClass != CircuitOpen {
+		routeErr.Cause = errors.New(attempts[n-1].Error)
+	}
+	return nil, routeErr
+}
+
+// order 按策略排序目标（稳定排序，同等条件下保持配置顺序）
+func (r *Router) order(policy *RoutePolicy, targets []*RouteTarget) []*RouteTarget {
+	ordered := append([]*RouteTarget(nil), targets...)
+	switch policy.Strategy {
+	case RouteStrategyLatency:
+		latency := make(map[string]time.Duration, len(ordered))
+		r.mu.Lock()
+		for _, target := range ordered {
+			if stats, ok := r.stats[target.Key]; ok {
+				latency[target.Key] = stats.latency
+			}
+		}
+		r.mu.Unlock()
+		sort.SliceStable(ordered, func(i, j int) bool {
+			return latency[ordered[i].Key] < latency[ordered[j].Key]
+		})
+	case RouteStrategyCost:
+		cost := func(target *RouteTarget) float64 {
+			if target.Cost <= 0 {
+				return math.MaxFloat64
+			}
+			return target.Cost
+		}
+		sort.SliceStable(ordered, func(i, j int) bool {
+			return cost(ordered[i]) < cost(ordered[j])
+		})
+	}
+	return ordered
+}
+
+// CircuitState 获取目标的熔断状态
+func (r *Router) CircuitState(key string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stats, ok := r.stats[key]
+	if !ok {
+		return CircuitClosed
+	}
+	if stats.state == CircuitOpen && time.Since(stats.openedAt) >= r.OpenDuration {
+		return CircuitHalfOpen
+	}
+	return stats.state
+}
+
+// AverageLatency 获取目标的平均延迟（没有统计数据时返回 0）
+func (r *Router) AverageLatency(key string) time.Duration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if stats, ok := r.stats[key]; ok {
+		return stats.latency
+	}
+	return 0
+}
+
+// allow 熔断器是否放行请求
+func (r *Router) allow(key string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stats := r.statsOf(key)
+	switch stats.state {
+	case CircuitOpen:
+		if time.Since(stats.openedAt) < r.OpenDuration {
+			return false
+		}
+		stats.state = CircuitHalfOpen
+		stats.probing = true
+		return true
+	case CircuitHalfOpen:
+		if stats.probing {
+			return false
+		}
+		stats.probing = true
+		return true
+	}
+	return true
+}
+
+// recordSuccess 记录成功：关闭熔断器并更新延迟
+func (r *Router) recordSuccess(key string, latency time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stats := r.statsOf(key)
+	stats.state = CircuitClosed
+	stats.failures = 0
+	stats.probing = false
+	if stats.latency == 0 {
+		stats.latency = latency
+	} else {
+		stats.latency = time.Duration(routeLatencySmoothingFactor*float64(latency) + (1-routeLatencySmoothingFactor)*float64(stats.latency))
+	}
+}
+
+// recordFailure 记录失败：连续失败达到阈值（或半开探测失败）时熔断
+// 请求错误是调用方的问题，不计入熔断
+func (r *Router) recordFailure(key, class string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stats := r.statsOf(key)
+	if class == ErrorClassInvalid {
+		stats.probing = false
+		return
+	}
+	stats.failures++
+	if stats.state == CircuitHalfOpen || stats.failures >= r.FailureThreshold {
+		stats.state = CircuitOpen
+		stats.openedAt = time.Now()
+	}
+	stats.probing = false
+}
+
+// release 放弃本次请求的结果（调用方取消），允许半开状态下重新探测
+func (r *Router) release(key string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.statsOf(key).probing = false
+}
+
+// statsOf 获取目标的统计（不存在时创建，调用方需持有锁）
+func (r *Router) statsOf(key string) *routeTargetStats {
+	stats, ok := r.stats[key]
+	if !ok {
+		stats = &routeTargetStats{state: CircuitClosed}
+		r.stats[key] = stats
+	}
+	return stats
+}
+
+// drainStream 读完通道，避免提供商的 goroutine 阻塞在发送上
+func drainStream(stream <-chan *StreamChunk) {
+	for range stream {
+	}
+}
@@ -0,0 +1,178 @@
+package llms
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// fakeRouteClient 按顺序返回预设错误的客户端（错误用完后返回成功）
+type fakeRouteClient struct {
+	name  string
+	errs  []error
+	calls int
+}
+
+func (c *fakeRouteClient) next() error {
+	c.calls++
+	if len(c.errs) == 0 {
+		return nil
+	}
+	err := c.errs[0]
+	c.errs = c.errs[1:]
+	return err
+}
+
+func (c *fakeRouteClient) Chat(ctx context.Context, req *ChatRequest) (*ChatResponse, error) {
+	if err := c.next(); err != nil {
+		return nil, err
+	}
+	return &ChatResponse{Content: c.name}, nil
+}
+
+func (c *fakeRouteClient) ChatStream(ctx context.Context, req *ChatRequest) (<-chan *StreamChunk, error) {
+	ch := make(chan *StreamChunk, 2)
+	if err := c.next(); err != nil {
+		ch <- &StreamChunk{Error: err.Error(), Done: true}
+	} else {
+		ch <- &StreamChunk{Content: c.name}
+		ch <- &StreamChunk{Done: true}
+	}
+	close(ch)
+	return ch, nil
+}
+
+func (c *fakeRouteClient) GetModelName() string { return c.name }
+func (c *fakeRouteClient) GetProvider() string  { return "fake" }
+
+func routeTargets(clients ...*fakeRouteClient) []*RouteTarget {
+	targets := make([]*RouteTarget, 0, len(clients))
+	for _, c := range clients {
+		targets = append(targets, &RouteTarget{Key: c.name, Client: c, Request: &ChatRequest{}})
+	}
+	return targets
+}
+
+func TestClassifyError(t *testing.T) {
+	cases := map[string]string{
+		"HTTP请求失败，状态码: 429, 响应: {}":           ErrorClassRateLimit,
+		"HTTP请求失败，状态码: 503":                   ErrorClassServer,
+		"HTTP请求失败，状态码: 401, 响应: invalid key":  ErrorClassAuth,
+		"HTTP请求失败，状态码: 400, 响应: bad":          ErrorClassInvalid,
+		"HTTP请求失败: context deadline exceeded": ErrorClassTimeout,
+		"dial tcp: connection refused":        ErrorClassNetwork,
+		"API Key 不能为空":                        ErrorClassAuth,
+		"响应格式错误：没有找到choices":                  ErrorClassOther,
+	}
+	for msg, want := range cases {
+		if got := ClassifyError(errors.New(msg)); got != want {
+			t.Errorf("ClassifyError(%q) = %s, want %s", msg, got, want)
+		}
+	}
+}
+
+func TestRouterFailover(t *testing.T) {
+	primary := &fakeRouteClient{name: "a", errs: []error{errors.New("HTTP请求失败，状态码: 500")}}
+	backup := &fakeRouteClient{name: "b"}
+	router := NewRouter()
+
+	resp, target, err := router.Chat(context.Background(), &RoutePolicy{}, routeTargets(primary, backup))
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	if target.Key != "b" || resp.Content != "b" {
+		t.Fatalf("served by %s, want b", target.Key)
+	}
+}
+
+func TestRouterRetry(t *testing.T) {
+	primary := &fakeRouteClient{name: "a", errs: []error{errors.New("HTTP请求失败，状态码: 429")}}
+	backup := &fakeRouteClient{name: "b"}
+	policy := &RoutePolicy{MaxRetries: 1, Backoff: time.Millisecond}
+
+	_, target, err := NewRouter().Chat(context.Background(), policy, routeTargets(primary, backup))
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	if target.Key != "a" || primary.calls != 2 || backup.calls != 0 {
+		t.Fatalf("served by %s, primary calls %d, backup calls %d", target.Key, primary.calls, backup.calls)
+	}
+
+	// 不在重试列表中的错误直接切换
+	primary = &fakeRouteClient{name: "a", errs: []error{errors.New("HTTP请求失败，状态码: 401")}}
+	_, target, _ = NewRouter().Chat(context.Background(), policy, routeTargets(primary, backup))
+	if target.Key != "b" || primary.calls != 1 {
+		t.Fatalf("served by %s, primary calls %d", target.Key, primary.calls)
+	}
+}
+
+func TestRouterCircuitBreaker(t *testing.T) {
+	router := NewRouter()
+	router.FailureThreshold = 2
+	router.OpenDuration = 20 * time.Millisecond
+	fail := errors.New("HTTP请求失败，状态码: 502")
+	primary := &fakeRouteClient{name: "a", errs: []error{fail, fail, fail}}
+	backup := &fakeRouteClient{name: "b"}
+	targets := routeTargets(primary, backup)
+
+	for i := 0; i < 3; i++ {
+		if _, _, err := router.Chat(context.Background(), &RoutePolicy{}, targets); err != nil {
+			t.Fatalf("Chat() error = %v", err)
+		}
+	}
+	if primary.calls != 2 || router.CircuitState("a") != CircuitOpen {
+		t.Fatalf("primary calls %d, state %s", primary.calls, router.CircuitState("a"))
+	}
+
+	// 熔断时间过后放行一个探测请求，失败则重新熔断
+	time.Sleep(30 * time.Millisecond)
+	router.Chat(context.Background(), &RoutePolicy{}, targets)
+	if primary.calls != 3 || router.CircuitState("a") != CircuitOpen {
+		t.Fatalf("primary calls %d, state %s", primary.calls, router.CircuitState("a"))
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	_, target, _ := router.Chat(context.Background(), &RoutePolicy{}, targets)
+	if target.Key != "a" || router.CircuitState("a") != CircuitClosed {
+		t.Fatalf("served by %s, state %s", target.Key, router.CircuitState("a"))
+	}
+}
+
+func TestRouterCostStrategy(t *testing.T) {
+	targets := routeTargets(&fakeRouteClient{name: "unknown"}, &fakeRouteClient{name: "expensive"}, &fakeRouteClient{name: "cheap"})
+	targets[1].Cost = 0.05
+	targets[2].Cost = 0.001
+
+	_, target, err := NewRouter().Chat(context.Background(), &RoutePolicy{Strategy: RouteStrategyCost}, targets)
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	if target.Key != "cheap" {
+		t.Fatalf("served by %s, want cheap", target.Key)
+	}
+}
+
+func TestRouterChatStream(t *testing.T) {
+	primary := &fakeRouteClient{name: "a", errs: []error{errors.New("HTTP请求失败，状态码: 503")}}
+	backup := &fakeRouteClient{name: "b"}
+
+	stream, target, err := NewRouter().ChatStream(context.Background(), &RoutePolicy{}, routeTargets(primary, backup))
+	if err != nil {
+		t.Fatalf("ChatStream() error = %v", err)
+	}
+	var content string
+	for chunk := range stream {
+		content += chunk.Content
+	}
+	if target.Key != "b" || content != "b" {
+		t.Fatalf("served by %s, content %q", target.Key, content)
+	}
+
+	all := &fakeRouteClient{name: "c", errs: []error{errors.New("HTTP请求失败，状态码: 400")}}
+	_, _, err = NewRouter().ChatStream(context.Background(), &RoutePolicy{}, routeTargets(all))
+	var routeErr *RouteError
+	if !errors.As(err, &routeErr) || len(routeErr.Attempts) != 1 || routeErr.Attempts[0].ErrorClass != ErrorClassInvalid {
+		t.Fatalf("ChatStream() error = %v", err)
+	}
+}
@@ -9,29 +9,29 @@ cd "$(dirname "$0")"
 
 # 测试 DeepSeek
 echo "🔍 测试 DeepSeek API Key..."
-go test -v -run TestDeepSeekChatBasic
+go test -v -tags llm_live -run TestDeepSeekChatBasic
 
 echo ""
 echo "🔍 测试 千问3 Coder API Key..."
-go test -v -run TestQwen3CoderCodeGeneration
+go test -v -tags llm_live -run TestQwen3CoderCodeGeneration
 
 echo ""
 echo "📋 运行所有 DeepSeek 测试..."
-go test -v -run TestDeepSeekAll
+go test -v -tags llm_live -run TestDeepSeekAll
 
 echo ""
 echo "📋 运行所有 千问3 Coder 测试..."
-go test -v -run TestQwen3CoderAll
+go test -v -tags llm_live -run TestQwen3CoderAll
 
 echo ""
 echo "⚡ 运行性能测试..."
-go test -v -bench=BenchmarkDeepSeekChat -run=^$
-go test -v -bench=BenchmarkQwen3CoderChat -run=^$
+go test -v -tags llm_live -bench=BenchmarkDeepSeekChat -run=^$
+go test -v -tags llm_live -bench=BenchmarkQwen3CoderChat -run=^$
 
 echo ""
 echo "🎯 运行集成测试..."
-go test -v -run TestDeepSeekIntegration
-go test -v -run TestQwen3CoderIntegration
+go test -v -tags llm_live -run TestDeepSeekIntegration
+go test -v -tags llm_live -run TestQwen3CoderIntegration
 
 echo ""
 echo "✅ 测试完成！"
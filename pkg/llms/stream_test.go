@@ -1,3 +1,5 @@
+//go:build llm_live
+
 package llms
 
 import (
@@ -200,56 +202,6 @@ func TestChatStream(t *testing.T) {
 	})
 }
 
-// TestStreamChunk 测试流式数据块结构
-func TestStreamChunk(t *testing.T) {
-	// 测试内容片段
-	contentChunk := &StreamChunk{
-		Content: "Hello",
-		Done:    false,
-	}
-
-	if contentChunk.Content != "Hello" {
-		t.Error("内容片段内容不正确")
-	}
-
-	if contentChunk.Done {
-		t.Error("内容片段不应该标记为完成")
-	}
-
-	// 测试完成片段
-	doneChunk := &StreamChunk{
-		Content: "",
-		Done:    true,
-		Usage: &Usage{
-			PromptTokens:     10,
-			CompletionTokens: 5,
-			TotalTokens:      15,
-		},
-	}
-
-	if !doneChunk.Done {
-		t.Error("完成片段应该标记为完成")
-	}
-
-	if doneChunk.Usage == nil {
-		t.Error("完成片段应该包含使用统计")
-	}
-
-	// 测试错误片段
-	errorChunk := &StreamChunk{
-		Error: "API错误",
-		Done:  true,
-	}
-
-	if errorChunk.Error == "" {
-		t.Error("错误片段应该包含错误信息")
-	}
-
-	if !errorChunk.Done {
-		t.Error("错误片段应该标记为完成")
-	}
-}
-
 // TestStreamInterface 测试流式接口实现
 func TestStreamInterface(t *testing.T) {
 	// 测试所有客户端都实现了ChatStream方法
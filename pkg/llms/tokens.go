@@ -0,0 +1,59 @@
+package llms
+
+import (
+	"math"
+	"unicode/utf8"
+)
+
+// tokenRatio 提供商分词器的估算参数
+type tokenRatio struct {
+	asciiPerToken   float64 // 平均多少个 ASCII 字符一个 token
+	otherTokenRatio float64 // 每个非 ASCII 字符（中文等）约多少个 token
+}
+
+// 国产模型的分词器对中文做了优化，一个汉字通常不到一个 token；海外模型一个汉字一般一个 token 以上
+var tokenRatios = map[Provider]tokenRatio{
+	ProviderDeepSeek:   {asciiPerToken: 4, otherTokenRatio: 0.6},
+	ProviderQwen:       {asciiPerToken: 4, otherTokenRatio: 0.7},
+	ProviderQwen3Coder: {asciiPerToken: 4, otherTokenRatio: 0.7},
+	ProviderDouBao:     {asciiPerToken: 4, otherTokenRatio: 0.7},
+	ProviderKimi:       {asciiPerToken: 4, otherTokenRatio: 0.7},
+	ProviderGLM:        {asciiPerToken: 4, otherTokenRatio: 0.7},
+	ProviderClaude:     {asciiPerToken: 3.5, otherTokenRatio: 1.3},
+	ProviderGemini:     {asciiPerToken: 4, otherTokenRatio: 1},
+}
+
+var defaultTokenRatio = tokenRatio{asciiPerToken: 4, otherTokenRatio: 1}
+
+// messageTokenOverhead 每条消息的格式开销（角色、分隔符等）
+const messageTokenOverhead = 4
+
+// CountTokens 估算文本在指定提供商下的 token 数
+// 不依赖各家的分词器，用于上下文预算和用量估算，误差一般在 20% 以内
+func CountTokens(provider Provider, text string) int {
+	if text == "" {
+		return 0
+	}
+	ratio, ok := tokenRatios[provider]
+	if !ok {
+		ratio = defaultTokenRatio
+	}
+
+	ascii := 0
+	for i := 0; i < len(text); i++ {
+		if text[i] < utf8.RuneSelf {
+			ascii++
+		}
+	}
+	other := utf8.RuneCountInString(text) - ascii
+	return int(math.Ceil(float64(ascii)/ratio.asciiPerToken + float64(other)*ratio.otherTokenRatio))
+}
+
+// CountMessagesTokens 估算对话消息的 token 数（包含每条消息的格式开销）
+func CountMessagesTokens(provider Provider, messages []Message) int {
+	total := 0
+	for _, msg := range messages {
+		total += CountTokens(provider, msg.Content) + messageTokenOverhead
+	}
+	return total
+}
@@ -0,0 +1,25 @@
+package llms
+
+import "testing"
+
+func TestCountTokens(t *testing.T) {
+	if got := CountTokens(ProviderDeepSeek, ""); got != 0 {
+		t.Fatalf("empty = %d", got)
+	}
+	if got := CountTokens(ProviderDeepSeek, "package main"); got != 3 {
+		t.Fatalf("ascii = %d, want 3", got)
+	}
+	// 国产模型的中文 token 数少于海外模型
+	text := "生成一个工单管理系统"
+	if deepseek, claude := CountTokens(ProviderDeepSeek, text), CountTokens(ProviderClaude, text); deepseek != 6 || claude != 13 {
+		t.Fatalf("deepseek = %d, claude = %d", deepseek, claude)
+	}
+	if got := CountTokens(Provider("unknown"), "你好"); got != 2 {
+		t.Fatalf("unknown = %d", got)
+	}
+
+	messages := []Message{{Role: "system", Content: "abcd"}, {Role: "user", Content: "你好"}}
+	if got := CountMessagesTokens(ProviderGemini, messages); got != 1+2+2*messageTokenOverhead {
+		t.Fatalf("messages = %d", got)
+	}
+}
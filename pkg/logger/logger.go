@@ -128,8 +128,8 @@ func Init(cfg Config) error {
 		core = zapcore.NewCore(fileEncoder, fileWriter, level)
 	}
 
-	// 创建logger实例
-	logger = zap.New(core, zap.AddCaller(), zap.AddCallerSkip(1))
+	// 创建logger实例（包装脱敏 core，避免密钥等敏感值写入日志）
+	logger = zap.New(newRedactCore(core), zap.AddCaller(), zap.AddCallerSkip(1))
 	sugar = logger.Sugar()
 	initialized = true
 
@@ -0,0 +1,91 @@
+package logger
+
+import (
+	"strings"
+	"sync"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// RedactedPlaceholder 敏感值脱敏后的占位符
+const RedactedPlaceholder = "******"
+
+// minRedactLength 参与脱敏的最小长度，过短的值容易误伤正常日志
+const minRedactLength = 4
+
+var (
+	redactMu     sync.RWMutex
+	redactValues = make(map[string]struct{})
+)
+
+// RegisterSecret 注册需要在日志中脱敏的敏感值（如应用密钥）
+// 注册后，所有日志消息和字符串字段中出现的该值都会被替换为 ******
+func RegisterSecret(values ...string) {
+	redactMu.Lock()
+	defer redactMu.Unlock()
+	for _, v := range values {
+		if len(v) < minRedactLength {
+			continue
+		}
+		redactValues[v] = struct{}{}
+	}
+}
+
+// UnregisterSecret 取消注册敏感值（如密钥轮换后旧值不再需要脱敏）
+func UnregisterSecret(values ...string) {
+	redactMu.Lock()
+	defer redactMu.Unlock()
+	for _, v := range values {
+		delete(redactValues, v)
+	}
+}
+
+// Redact 将字符串中已注册的敏感值替换为占位符
+func Redact(s string) string {
+	redactMu.RLock()
+	defer redactMu.RUnlock()
+	if len(redactValues) == 0 || s == "" {
+		return s
+	}
+	for v := range redactValues {
+		if strings.Contains(s, v) {
+			s = strings.ReplaceAll(s, v, RedactedPlaceholder)
+		}
+	}
+	return s
+}
+
+// redactCore 包装 zapcore.Core，在写入前对消息和字符串字段做脱敏
+type redactCore struct {
+	zapcore.Core
+}
+
+func newRedactCore(core zapcore.Core) zapcore.Core {
+	return &redactCore{Core: core}
+}
+
+func (c *redactCore) With(fields []zapcore.Field) zapcore.Core {
+	return &redactCore{Core: c.Core.With(redactFields(fields))}
+}
+
+func (c *redactCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *redactCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	ent.Message = Redact(ent.Message)
+	return c.Core.Write(ent, redactFields(fields))
+}
+
+// redactFields 对字符串类型字段做脱敏
+func redactFields(fields []zapcore.Field) []zapcore.Field {
+	for i := range fields {
+		if fields[i].Type == zapcore.StringType {
+			fields[i].String = Redact(fields[i].String)
+		}
+	}
+	return fields
+}
@@ -0,0 +1,53 @@
+package logger
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestRedact(t *testing.T) {
+	RegisterSecret("sk-test-123", "abc")
+	t.Cleanup(func() { UnregisterSecret("sk-test-123", "abc") })
+
+	if got := Redact("key=sk-test-123 again sk-test-123"); got != "key=****** again ******" {
+		t.Fatalf("Redact() = %q", got)
+	}
+	// 过短的值不参与脱敏
+	if got := Redact("abc"); got != "abc" {
+		t.Fatalf("short value redacted: %q", got)
+	}
+
+	UnregisterSecret("sk-test-123")
+	if got := Redact("key=sk-test-123"); got != "key=sk-test-123" {
+		t.Fatalf("unregistered value still redacted: %q", got)
+	}
+}
+
+func TestRedactCore(t *testing.T) {
+	RegisterSecret("sk-test-123")
+	t.Cleanup(func() { UnregisterSecret("sk-test-123") })
+
+	var buf bytes.Buffer
+	core := zapcore.NewCore(
+		zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()),
+		zapcore.AddSync(&buf),
+		zapcore.DebugLevel,
+	)
+	log := zap.New(newRedactCore(core)).With(zap.String("token", "sk-test-123"))
+
+	log.Info("calling with sk-test-123", zap.String("header", "Bearer sk-test-123"), zap.Int("n", 1))
+
+	out := buf.String()
+	if strings.Contains(out, "sk-test-123") {
+		t.Fatalf("secret leaked into log: %s", out)
+	}
+	for _, want := range []string{`"msg":"calling with ******"`, `"header":"Bearer ******"`, `"token":"******"`, `"n":1`} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("log output missing %s: %s", want, out)
+		}
+	}
+}
@@ -0,0 +1,283 @@
+package mdconv
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// docxSegment 段落中格式相同的一段文字
+type docxSegment struct {
+	text   string
+	bold   bool
+	italic bool
+}
+
+// docxParagraph 正在解析的段落
+type docxParagraph struct {
+	style    string
+	list     bool
+	level    int
+	segments []docxSegment
+}
+
+// DocxToMarkdown 把 Word（.docx）文档转换为 Markdown
+// 支持标题（标题/Heading 样式）、列表、粗体、斜体和表格，图片等嵌入对象会被忽略
+func DocxToMarkdown(data []byte) (string, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return "", fmt.Errorf("不是有效的 docx 文件: %w", err)
+	}
+	var document, styles *zip.File
+	for _, f := range zr.File {
+		switch f.Name {
+		case "word/document.xml":
+			document = f
+		case "word/styles.xml":
+			styles = f
+		}
+	}
+	if document == nil {
+		return "", fmt.Errorf("不是有效的 docx 文件: 缺少 word/document.xml")
+	}
+
+	styleNames := map[string]string{}
+	if styles != nil {
+		if styleNames, err = readDocxStyles(styles); err != nil {
+			return "", err
+		}
+	}
+
+	rc, err := document.Open()
+	if err != nil {
+		return "", fmt.Errorf("读取 docx 失败: %w", err)
+	}
+	defer rc.Close()
+	return convertDocx(xml.NewDecoder(rc), styleNames)
+}
+
+// readDocxStyles 读取样式ID到样式名称的映射（中文版 Word 的标题样式ID通常是数字）
+func readDocxStyles(f *zip.File) (map[string]string, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, fmt.Errorf("读取 docx 样式失败: %w", err)
+	}
+	defer rc.Close()
+
+	names := map[string]string{}
+	decoder := xml.NewDecoder(rc)
+	styleID := ""
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return names, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("解析 docx 样式失败: %w", err)
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		switch start.Name.Local {
+		case "style":
+			styleID = xmlAttr(start, "styleId")
+		case "name":
+			if styleID != "" {
+				names[styleID] = xmlAttr(start, "val")
+			}
+		}
+	}
+}
+
+func convertDocx(decoder *xml.Decoder, styleNames map[string]string) (string, error) {
+	var sb strings.Builder
+	var para *docxParagraph
+	var run docxSegment
+	inText := false
+	// 表格：rows 为当前表格的行，cell 为当前单元格中的段落
+	var tables [][][]string
+	var row []string
+	var cell []string
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", fmt.Errorf("解析 docx 失败: %w", err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "p":
+				para = &docxParagraph{}
+			case "pStyle":
+				if para != nil {
+					para.style = xmlAttr(t, "val")
+				}
+			case "numPr":
+				if para != nil {
+					para.list = true
+				}
+			case "ilvl":
+				if para != nil {
+					para.level, _ = strconv.Atoi(xmlAttr(t, "val"))
+				}
+			case "r":
+				run = docxSegment{}
+			case "b":
+				run.bold = xmlToggle(t)
+			case "i":
+				run.italic = xmlToggle(t)
+			case "t":
+				inText = true
+			case "tab":
+				run.text += "\t"
+			case "br", "cr":
+				run.text += "\n"
+			case "tbl":
+				tables = append(tables, nil)
+			case "tr":
+				row = nil
+			case "tc":
+				cell = nil
+			}
+		case xml.CharData:
+			if inText {
+				run.text += string(t)
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "t":
+				inText = false
+			case "r":
+				if para != nil && run.text != "" {
+					para.segments = append(para.segments, run)
+				}
+			case "p":
+				if para == nil {
+					continue
+				}
+				if len(tables) > 0 {
+					if text := inline(renderDocxSegments(para.segments)); text != "" {
+						cell = append(cell, text)
+					}
+				} else {
+					sb.WriteString(renderDocxParagraph(para, styleNames))
+				}
+				para = nil
+			case "tc":
+				row = append(row, strings.ReplaceAll(strings.Join(cell, " "), "|", `\|`))
+			case "tr":
+				if len(tables) > 0 {
+					tables[len(tables)-1] = append(tables[len(tables)-1], row)
+				}
+			case "tbl":
+				if len(tables) == 0 {
+					continue
+				}
+				rows := tables[len(tables)-1]
+				tables = tables[:len(tables)-1]
+				if len(tables) > 0 {
+					// 嵌套表格压平为外层单元格中的文本
+					for _, nested := range rows {
+						cell = append(cell, strings.Join(nested, " "))
+					}
+				} else {
+					sb.WriteString(renderMarkdownTable(rows))
+				}
+			}
+		}
+	}
+	return Normalize(sb.String()), nil
+}
+
+// renderDocxParagraph 渲染段落（标题、列表项或普通段落）
+func renderDocxParagraph(para *docxParagraph, styleNames map[string]string) string {
+	text := renderDocxSegments(para.segments)
+	if strings.TrimSpace(text) == "" {
+		return "\n"
+	}
+	if level := docxHeadingLevel(para.style, styleNames); level > 0 {
+		return "\n\n" + strings.Repeat("#", level) + " " + inline(text) + "\n\n"
+	}
+	if para.list {
+		return "\n" + strings.Repeat("  ", para.level) + "- " + inline(text) + "\n"
+	}
+	return "\n\n" + text + "\n\n"
+}
+
+// docxHeadingLevel 根据段落样式判断标题级别，不是标题时返回 0
+func docxHeadingLevel(style string, styleNames map[string]string) int {
+	if style == "" {
+		return 0
+	}
+	for _, name := range []string{styleNames[style], style} {
+		name = strings.ToLower(strings.ReplaceAll(name, " ", ""))
+		if name == "title" {
+			return 1
+		}
+		for _, prefix := range []string{"heading", "标题"} {
+			if !strings.HasPrefix(name, prefix) {
+				continue
+			}
+			if level, err := strconv.Atoi(strings.TrimPrefix(name, prefix)); err == nil && level >= 1 {
+				if level > 6 {
+					level = 6
+				}
+				return level
+			}
+		}
+	}
+	return 0
+}
+
+// renderDocxSegments 合并格式相同的相邻文字后加上强调标记
+func renderDocxSegments(segments []docxSegment) string {
+	var merged []docxSegment
+	for _, segment := range segments {
+		if n := len(merged); n > 0 && merged[n-1].bold == segment.bold && merged[n-1].italic == segment.italic {
+			merged[n-1].text += segment.text
+			continue
+		}
+		merged = append(merged, segment)
+	}
+
+	var sb strings.Builder
+	for _, segment := range merged {
+		text := segment.text
+		if segment.bold {
+			text = wrapInline(text, "**")
+		}
+		if segment.italic {
+			text = wrapInline(text, "*")
+		}
+		sb.WriteString(text)
+	}
+	return sb.String()
+}
+
+func xmlAttr(start xml.StartElement, local string) string {
+	for _, a := range start.Attr {
+		if a.Name.Local == local {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// xmlToggle 解析开关属性（<w:b/> 表示开启，<w:b w:val="0"/> 表示关闭）
+func xmlToggle(start xml.StartElement) bool {
+	switch xmlAttr(start, "val") {
+	case "0", "false", "off":
+		return false
+	}
+	return true
+}
@@ -0,0 +1,296 @@
+package mdconv
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+var (
+	whitespacePattern = regexp.MustCompile(`[ \t\r\n\f]+`)
+	newlinesPattern   = regexp.MustCompile(`\n{2,}`)
+)
+
+// HTMLToMarkdown 把 HTML 转换为 Markdown
+// 支持标题、段落、列表、链接、图片、强调、代码块、引用和表格，脚本、样式等不可见内容会被忽略
+func HTMLToMarkdown(src string) (string, error) {
+	doc, err := html.Parse(strings.NewReader(src))
+	if err != nil {
+		return "", fmt.Errorf("解析 HTML 失败: %w", err)
+	}
+	root := findElement(doc, atom.Body)
+	if root == nil {
+		root = doc
+	}
+	return Normalize(renderChildren(root, 0)), nil
+}
+
+// findElement 深度优先查找第一个指定标签的元素
+func findElement(n *html.Node, a atom.Atom) *html.Node {
+	if n.Type == html.ElementNode && n.DataAtom == a {
+		return n
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if found := findElement(c, a); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// renderChildren 渲染子节点，depth 为当前列表嵌套层级
+func renderChildren(n *html.Node, depth int) string {
+	var sb strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		sb.WriteString(renderNode(c, depth))
+	}
+	return sb.String()
+}
+
+func renderNode(n *html.Node, depth int) string {
+	switch n.Type {
+	case html.TextNode:
+		return whitespacePattern.ReplaceAllString(n.Data, " ")
+	case html.ElementNode:
+	default:
+		return ""
+	}
+
+	switch n.DataAtom {
+	case atom.Script, atom.Style, atom.Head, atom.Noscript, atom.Template, atom.Iframe, atom.Svg, atom.Button, atom.Form:
+		return ""
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		level := int(n.Data[1] - '0')
+		return "\n\n" + strings.Repeat("#", level) + " " + inline(renderChildren(n, depth)) + "\n\n"
+	case atom.Br:
+		return "\n"
+	case atom.Hr:
+		return "\n\n---\n\n"
+	case atom.Strong, atom.B:
+		return wrapInline(renderChildren(n, depth), "**")
+	case atom.Em, atom.I:
+		return wrapInline(renderChildren(n, depth), "*")
+	case atom.Del, atom.S:
+		return wrapInline(renderChildren(n, depth), "~~")
+	case atom.Code, atom.Kbd:
+		text := textContent(n)
+		if strings.TrimSpace(text) == "" {
+			return ""
+		}
+		return "`" + strings.TrimSpace(text) + "`"
+	case atom.Pre:
+		return "\n\n```" + codeLanguage(n) + "\n" + strings.Trim(textContent(n), "\n") + "\n```\n\n"
+	case atom.A:
+		text := inline(renderChildren(n, depth))
+		href := attr(n, "href")
+		if text == "" || href == "" || strings.HasPrefix(href, "javascript:") {
+			return text
+		}
+		return "[" + text + "](" + href + ")"
+	case atom.Img:
+		src := attr(n, "src")
+		if src == "" {
+			return ""
+		}
+		return "![" + attr(n, "alt") + "](" + src + ")"
+	case atom.Ul, atom.Ol:
+		return renderList(n, depth)
+	case atom.Blockquote:
+		content := strings.TrimSpace(Normalize(renderChildren(n, depth)))
+		if content == "" {
+			return ""
+		}
+		lines := strings.Split(content, "\n")
+		for i, line := range lines {
+			lines[i] = strings.TrimRight("> "+line, " ")
+		}
+		return "\n\n" + strings.Join(lines, "\n") + "\n\n"
+	case atom.Table:
+		return renderTable(n)
+	case atom.P, atom.Div, atom.Section, atom.Article, atom.Main, atom.Header, atom.Footer, atom.Nav, atom.Aside,
+		atom.Figure, atom.Figcaption, atom.Details, atom.Summary, atom.Dl, atom.Dt, atom.Dd:
+		return "\n\n" + renderChildren(n, depth) + "\n\n"
+	}
+	return renderChildren(n, depth)
+}
+
+// renderList 渲染有序/无序列表，嵌套列表按层级缩进
+func renderList(n *html.Node, depth int) string {
+	indent := strings.Repeat("  ", depth)
+	var lines []string
+	index := 1
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type != html.ElementNode || c.DataAtom != atom.Li {
+			continue
+		}
+		marker := "-"
+		if n.DataAtom == atom.Ol {
+			marker = fmt.Sprintf("%d.", index)
+			index++
+		}
+		content := strings.TrimSpace(newlinesPattern.ReplaceAllString(Normalize(renderChildren(c, depth+1)), "\n"))
+		lines = append(lines, indent+marker+" "+content)
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+	if depth > 0 {
+		return "\n" + strings.Join(lines, "\n") + "\n"
+	}
+	return "\n\n" + strings.Join(lines, "\n") + "\n\n"
+}
+
+// renderTable 渲染表格，第一行作为表头
+func renderTable(n *html.Node) string {
+	var rows [][]string
+	var collect func(*html.Node)
+	collect = func(node *html.Node) {
+		for c := node.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type != html.ElementNode {
+				continue
+			}
+			switch c.DataAtom {
+			case atom.Thead, atom.Tbody, atom.Tfoot:
+				collect(c)
+			case atom.Tr:
+				var cells []string
+				for cell := c.FirstChild; cell != nil; cell = cell.NextSibling {
+					if cell.Type == html.ElementNode && (cell.DataAtom == atom.Td || cell.DataAtom == atom.Th) {
+						text := inline(strings.ReplaceAll(renderChildren(cell, 0), "\n", " "))
+						cells = append(cells, strings.ReplaceAll(text, "|", `\|`))
+					}
+				}
+				rows = append(rows, cells)
+			}
+		}
+	}
+	collect(n)
+	return renderMarkdownTable(rows)
+}
+
+// renderMarkdownTable 把单元格转换为 Markdown 表格，第一行作为表头
+func renderMarkdownTable(rows [][]string) string {
+	columns := 0
+	for _, row := range rows {
+		if len(row) > columns {
+			columns = len(row)
+		}
+	}
+	if columns == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("\n\n")
+	for i, row := range rows {
+		sb.WriteString("|")
+		for j := 0; j < columns; j++ {
+			cell := ""
+			if j < len(row) {
+				cell = row[j]
+			}
+			sb.WriteString(" " + cell + " |")
+		}
+		sb.WriteString("\n")
+		if i == 0 {
+			sb.WriteString("|" + strings.Repeat(" --- |", columns) + "\n")
+		}
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
+
+// codeLanguage 从 pre 或其中 code 的 class（language-xxx / lang-xxx）中获取代码语言
+func codeLanguage(n *html.Node) string {
+	nodes := []*html.Node{n}
+	if code := findElement(n, atom.Code); code != nil {
+		nodes = append(nodes, code)
+	}
+	for _, node := range nodes {
+		for _, class := range strings.Fields(attr(node, "class")) {
+			for _, prefix := range []string{"language-", "lang-"} {
+				if strings.HasPrefix(class, prefix) {
+					return strings.TrimPrefix(class, prefix)
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// textContent 获取节点的原始文本（保留空白，用于代码）
+func textContent(n *html.Node) string {
+	if n.Type == html.TextNode {
+		return n.Data
+	}
+	if n.Type == html.ElementNode && n.DataAtom == atom.Br {
+		return "\n"
+	}
+	var sb strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		sb.WriteString(textContent(c))
+	}
+	return sb.String()
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return strings.TrimSpace(a.Val)
+		}
+	}
+	return ""
+}
+
+// inline 把内容压缩为单行
+func inline(s string) string {
+	return strings.TrimSpace(whitespacePattern.ReplaceAllString(s, " "))
+}
+
+// wrapInline 给行内内容加上强调标记，标记内侧不能有空白
+func wrapInline(s, mark string) string {
+	trimmed := strings.TrimSpace(s)
+	if trimmed == "" {
+		return s
+	}
+	start := strings.Index(s, trimmed)
+	return s[:start] + mark + trimmed + mark + s[start+len(trimmed):]
+}
+
+// Normalize 规范化 Markdown 空白：去掉行尾空白，合并连续空行（代码块内保持原样）
+func Normalize(md string) string {
+	lines := strings.Split(strings.ReplaceAll(md, "\r\n", "\n"), "\n")
+	result := make([]string, 0, len(lines))
+	inFence := false
+	blank := false
+	for _, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inFence = !inFence
+		} else if inFence {
+			result = append(result, line)
+			continue
+		}
+		line = strings.TrimRight(line, " \t")
+		if strings.TrimSpace(line) == "" {
+			if !blank && len(result) > 0 {
+				result = append(result, "")
+			}
+			blank = true
+			continue
+		}
+		// 块级元素之间的换行会在行首留下一个空格
+		if strings.HasPrefix(line, " ") && !strings.HasPrefix(line, "  ") {
+			line = line[1:]
+		}
+		result = append(result, line)
+		blank = false
+	}
+	md = strings.TrimSpace(strings.Join(result, "\n"))
+	if md == "" {
+		return ""
+	}
+	return md + "\n"
+}
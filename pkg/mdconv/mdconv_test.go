@@ -0,0 +1,68 @@
+package mdconv
+
+import (
+	"archive/zip"
+	"bytes"
+	"testing"
+)
+
+func TestHTMLToMarkdown(t *testing.T) {
+	src := `<html><head><title>x</title><style>p{}</style></head><body>
+<h1>表单组件</h1>
+<p>支持 <strong>必填</strong> 校验，详见 <a href="/docs/form">文档</a>。</p>
+<ul><li>输入框<ul><li>单行</li><li>多行</li></ul></li><li>下拉框</li></ul>
+<pre><code class="language-go">func main() {
+
+}</code></pre>
+<table><tr><th>字段</th><th>说明</th></tr><tr><td>name</td><td>a|b</td></tr></table>
+<script>alert(1)</script>
+</body></html>`
+	got, err := HTMLToMarkdown(src)
+	if err != nil {
+		t.Fatalf("HTMLToMarkdown() error = %v", err)
+	}
+	want := "# 表单组件\n\n" +
+		"支持 **必填** 校验，详见 [文档](/docs/form)。\n\n" +
+		"- 输入框\n  - 单行\n  - 多行\n- 下拉框\n\n" +
+		"```go\nfunc main() {\n\n}\n```\n\n" +
+		"| 字段 | 说明 |\n| --- | --- |\n| name | a\\|b |\n"
+	if got != want {
+		t.Fatalf("HTMLToMarkdown() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestDocxToMarkdown(t *testing.T) {
+	document := `<?xml version="1.0" encoding="UTF-8"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>
+<w:p><w:pPr><w:pStyle w:val="1"/></w:pPr><w:r><w:t>安装</w:t></w:r></w:p>
+<w:p><w:r><w:t xml:space="preserve">执行 </w:t></w:r><w:r><w:rPr><w:b/></w:rPr><w:t>go</w:t></w:r><w:r><w:rPr><w:b/></w:rPr><w:t xml:space="preserve"> install</w:t></w:r></w:p>
+<w:p><w:pPr><w:numPr><w:ilvl w:val="1"/><w:numId w:val="1"/></w:numPr></w:pPr><w:r><w:t>子项</w:t></w:r></w:p>
+<w:tbl><w:tr><w:tc><w:p><w:r><w:t>参数</w:t></w:r></w:p></w:tc><w:tc><w:p><w:r><w:t>默认值</w:t></w:r></w:p></w:tc></w:tr>
+<w:tr><w:tc><w:p><w:r><w:t>timeout</w:t></w:r></w:p></w:tc><w:tc><w:p><w:r><w:t>30</w:t></w:r></w:p></w:tc></w:tr></w:tbl>
+</w:body></w:document>`
+	styles := `<?xml version="1.0" encoding="UTF-8"?>
+<w:styles xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">
+<w:style w:type="paragraph" w:styleId="1"><w:name w:val="heading 1"/></w:style>
+</w:styles>`
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range map[string]string{"word/document.xml": document, "word/styles.xml": styles} {
+		w, _ := zw.Create(name)
+		w.Write([]byte(content))
+	}
+	zw.Close()
+
+	got, err := DocxToMarkdown(buf.Bytes())
+	if err != nil {
+		t.Fatalf("DocxToMarkdown() error = %v", err)
+	}
+	want := "# 安装\n\n执行 **go install**\n\n  - 子项\n\n| 参数 | 默认值 |\n| --- | --- |\n| timeout | 30 |\n"
+	if got != want {
+		t.Fatalf("DocxToMarkdown() =\n%q\nwant\n%q", got, want)
+	}
+
+	if _, err := DocxToMarkdown([]byte("not a zip")); err == nil {
+		t.Fatal("DocxToMarkdown() should fail on invalid file")
+	}
+}
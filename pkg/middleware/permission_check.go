@@ -276,6 +276,36 @@ func CheckChartQuery() gin.HandlerFunc {
 	}
 }
 
+// CheckFunctionConfigRead 检查函数运行时配置查看权限（查看配置、历史、对比）
+func CheckFunctionConfigRead() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !checkPermission(c, permissionconstants.FunctionRead, "无权限查看该函数配置") {
+			return
+		}
+		c.Next()
+	}
+}
+
+// CheckFunctionConfigManage 检查函数运行时配置修改权限（修改、回滚）
+func CheckFunctionConfigManage() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !checkPermission(c, permissionconstants.FunctionManage, "无权限修改该函数配置") {
+			return
+		}
+		c.Next()
+	}
+}
+
+// CheckApprovalRowRead 检查记录审批历史查看权限（与查看函数数据一致）
+func CheckApprovalRowRead() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !checkPermission(c, permissionconstants.FunctionRead, "无权限查看该记录的审批历史") {
+			return
+		}
+		c.Next()
+	}
+}
+
 // CheckAppUpdate 检查应用更新权限
 func CheckAppUpdate() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -355,9 +385,27 @@ func extractFullCodePathFromURL(urlPath string) string {
 	urlPath = strings.TrimPrefix(urlPath, "/table/batch-create")
 	urlPath = strings.TrimPrefix(urlPath, "/table/update")
 	urlPath = strings.TrimPrefix(urlPath, "/table/delete")
+	urlPath = strings.TrimPrefix(urlPath, "/table/children")
+	urlPath = strings.TrimPrefix(urlPath, "/kanban/search")
+	urlPath = strings.TrimPrefix(urlPath, "/kanban/move")
+	urlPath = strings.TrimPrefix(urlPath, "/calendar/search")
+	urlPath = strings.TrimPrefix(urlPath, "/calendar/range")
 	urlPath = strings.TrimPrefix(urlPath, "/form/submit")
+	urlPath = strings.TrimPrefix(urlPath, "/form/step")
+	urlPath = strings.TrimPrefix(urlPath, "/form/draft/get")
+	urlPath = strings.TrimPrefix(urlPath, "/form/draft/save")
+	urlPath = strings.TrimPrefix(urlPath, "/form/draft/delete")
 	urlPath = strings.TrimPrefix(urlPath, "/chart/query")
 	urlPath = strings.TrimPrefix(urlPath, "/callback/on_select_fuzzy")
+	urlPath = strings.TrimPrefix(urlPath, "/config/get")
+	urlPath = strings.TrimPrefix(urlPath, "/config/update")
+	urlPath = strings.TrimPrefix(urlPath, "/config/history")
+	urlPath = strings.TrimPrefix(urlPath, "/config/diff")
+	urlPath = strings.TrimPrefix(urlPath, "/config/rollback")
+	urlPath = strings.TrimPrefix(urlPath, "/approval/row")
+	urlPath = strings.TrimPrefix(urlPath, "/view/list")
+	urlPath = strings.TrimPrefix(urlPath, "/view/save")
+	urlPath = strings.TrimPrefix(urlPath, "/view/delete")
 	urlPath = strings.TrimPrefix(urlPath, "/run")
 	urlPath = strings.TrimPrefix(urlPath, "/callback")
 
@@ -59,11 +59,13 @@ func GetRuntimeStatusSubjectPattern() string {
 // 消息类型常量
 const (
 	// 状态通知消息类型
-	MessageTypeStatusShutdown    = "shutdown"    // 关闭命令
-	MessageTypeStatusDiscovery   = "discovery"   // 服务发现
-	MessageTypeStatusStartup     = "startup"     // 启动通知
-	MessageTypeStatusClose       = "close"       // 关闭通知
-	MessageTypeStatusOnAppUpdate = "onAppUpdate" // 当程序更新时候
+	MessageTypeStatusShutdown     = "shutdown"     // 关闭命令
+	MessageTypeStatusDiscovery    = "discovery"    // 服务发现
+	MessageTypeStatusStartup      = "startup"      // 启动通知
+	MessageTypeStatusClose        = "close"        // 关闭通知
+	MessageTypeStatusOnAppUpdate  = "onAppUpdate"  // 当程序更新时候
+	MessageTypeStatusSecretRotate = "secretRotate" // 应用密钥变更（SDK 清空密钥缓存）
+	MessageTypeStatusConfigUpdate = "configUpdate" // 函数运行时配置变更（SDK 更新配置缓存）
 
 	// Request/Reply 消息类型
 	MessageTypeUpdateCallbackRequest = "update_callback_request" // 更新回调请求
@@ -80,6 +82,36 @@ type Message struct {
 	Timestamp time.Time   `json:"timestamp"`
 }
 
+// GetAppServerSecretRequestSubject 获取应用密钥请求主题（app-runtime/SDK App -> app-server，Request/Reply）
+// 格式：app_server.app_secret.get
+func GetAppServerSecretRequestSubject() string {
+	return "app_server.app_secret.get"
+}
+
+// GetAppServerConfigRequestSubject 获取函数运行时配置请求主题（SDK App -> app-server，Request/Reply）
+// 格式：app_server.function_config.get
+func GetAppServerConfigRequestSubject() string {
+	return "app_server.function_config.get"
+}
+
+// GetAppServerNotificationSendSubject 获取发送通知请求主题（SDK App -> app-server，Request/Reply）
+// 格式：app_server.notification.send
+func GetAppServerNotificationSendSubject() string {
+	return "app_server.notification.send"
+}
+
+// GetAppServerNotificationPushSubject 获取站内信广播主题（app-server 实例之间广播，持有 SSE 连接的实例负责推送）
+// 格式：app_server.notification.push
+func GetAppServerNotificationPushSubject() string {
+	return "app_server.notification.push"
+}
+
+// GetAppServerApprovalStartSubject 获取发起审批请求主题（SDK App -> app-server，Request/Reply）
+// 格式：app_server.approval.start
+func GetAppServerApprovalStartSubject() string {
+	return "app_server.approval.start"
+}
+
 // GetAppRuntime2AppCreateRequestSubject 获取 app_runtime 到 app 创建请求的订阅主题
 func GetAppRuntime2AppCreateRequestSubject() string {
 	return "app_runtime.app.create"
@@ -199,6 +231,27 @@ func GetAgentServerFunctionGenCallbackSubject() string {
 	return "agent_server.function_gen.callback"
 }
 
+// GetAgentServerPluginPresenceSubject 获取插件实例上线/心跳/下线主题（plugin -> agent-server）
+// 格式：agent_server.plugin.presence
+func GetAgentServerPluginPresenceSubject() string {
+	return "agent_server.plugin.presence"
+}
+
+// GetAgentServerPluginDiscoverSubject 获取插件发现主题（agent-server -> plugin，收到后插件立即上报状态）
+// 格式：agent_server.plugin.discover
+func GetAgentServerPluginDiscoverSubject() string {
+	return "agent_server.plugin.discover"
+}
+
+// GetAgentServerFunctionGenStreamSubject 获取函数生成流式事件广播主题（agent-server 实例之间广播，持有 SSE 连接的实例推送给前端）
+// 格式：agent_server.function_gen.stream
+func GetAgentServerFunctionGenStreamSubject() string {
+	return "agent_server.function_gen.stream"
+}
+
+// PluginQueueGroup 插件订阅使用的队列组（同一插件的多个实例分摊请求）
+const PluginQueueGroup = "plugin"
+
 // ==================== Control Service 相关主题 ====================
 
 // GetControlLicenseKeySubject 获取 Control Service License 密钥发布主题（推送模式）
@@ -1,6 +1,7 @@
 package app
 
 import (
+	"encoding/json"
 	"fmt"
 	"strings"
 	"time"
@@ -31,6 +32,12 @@ type ApiInfo struct {
 	App            string          `json:"app"`
 	FullCodePath   string          `json:"full_code_path"`
 
+	// 运行时配置（BaseConfig.Config 声明，可在工作空间中在线修改，无需重新编译）
+	Config        []*widget.Field `json:"config,omitempty"`         // 配置结构
+	ConfigDefault json.RawMessage `json:"config_default,omitempty"` // 配置默认值（代码中声明的值）
+
+	TemplateConfig json.RawMessage `json:"template_config,omitempty"` // 模板配置（看板的分列字段、日历的时间字段等）
+
 	CreateTableModels []interface{} `json:"-"`
 
 	SourceCodeFilePath string `json:"source_code_file_path"`
@@ -117,7 +124,7 @@ func (a *ApiInfo) GetPackageChain() []string {
 }
 
 // IsEqual 比较当前API与另一个API是否相等（排除版本信息）
-// 比较的字段包括：Name, Desc, Tags, CreateTables, Callback, TemplateType, FunctionGroupCode, FunctionGroupName, Request, Response
+// 比较的字段包括：Name, Desc, Tags, CreateTables, Callback, TemplateType, FunctionGroupCode, FunctionGroupName, Request, Response, Config
 func (a *ApiInfo) IsEqual(other *ApiInfo) bool {
 	if other == nil {
 		return false
@@ -135,9 +142,12 @@ func (a *ApiInfo) IsEqual(other *ApiInfo) bool {
 		return false
 	}
 
-	// 比较请求参数和响应参数
+	// 比较请求参数、响应参数、运行时配置和模板配置
 	return jsonx.DeepEqual(a.Request, other.Request) &&
-		jsonx.DeepEqual(a.Response, other.Response)
+		jsonx.DeepEqual(a.Response, other.Response) &&
+		jsonx.DeepEqual(a.Config, other.Config) &&
+		jsonx.DeepEqual(a.ConfigDefault, other.ConfigDefault) &&
+		jsonx.DeepEqual(a.TemplateConfig, other.TemplateConfig)
 }
 
 // equalStrings 比较两个字符串切片是否相等
@@ -89,6 +89,46 @@ func (a *App) addRoute(router string, method string, handleFunc HandleFunc, temp
 			router, existing.Router, existing.Method))
 	}
 
+	// 看板、日历、向导表单注册时校验模板配置
+	if v, ok := templater.(interface{ initTemplate() error }); ok {
+		if err := v.initTemplate(); err != nil {
+			return fmt.Errorf("路由 %s 的模板配置错误: %w", router, err)
+		}
+	}
+
+	// 校验字段的条件表达式（show_if、required_if、readonly_if）和动态默认值
+	if err := initFieldRules(templater); err != nil {
+		return fmt.Errorf("路由 %s 的字段配置错误: %w", router, err)
+	}
+
+	// 声明式聚合图表、看板、日历不需要手写处理函数
+	if handleFunc == nil {
+		switch v := templater.(type) {
+		case *ChartTemplate:
+			if v.Aggregate != nil {
+				handleFunc = v.handleAggregate
+			}
+		case *KanbanTemplate:
+			handleFunc = v.handleList
+		case *CalendarTemplate:
+			handleFunc = v.handleList
+		}
+	}
+
+	// 向导表单提交时重新校验所有步骤
+	if form, ok := templater.(*FormTemplate); ok && form.isWizard() && handleFunc != nil {
+		handleFunc = form.wrapWizardSubmit(handleFunc)
+	}
+
+	// 关联组件字段自动生成模糊搜索回调
+	registerReferenceFuzzy(templater)
+
+	if table, ok := asTableTemplate(templater); ok {
+		if err := table.initChildTables(); err != nil {
+			return fmt.Errorf("路由 %s 的子表声明错误: %w", router, err)
+		}
+	}
+
 	a.routerInfo[key] = &routerInfo{
 		HandleFunc: handleFunc,
 		Router:     router,
@@ -143,6 +183,8 @@ func NewApp() (*App, error) {
 	if err != nil {
 		return nil, err
 	}
+	// 注册容器注入的应用密钥，日志中自动脱敏
+	registerEnvSecrets()
 
 	// 连接 NATS（优先使用环境变量）
 	natsURL := os.Getenv("NATS_URL")
@@ -644,6 +686,10 @@ func (a *App) handleAppStatusMessage(msg *nats.Msg) {
 		a.handleDiscovery(msg) // 发现消息还是用原来的格式
 	case subjects.MessageTypeStatusOnAppUpdate:
 		a.onAppUpdate(msg) // 发现消息还是用原来的格式
+	case subjects.MessageTypeStatusSecretRotate:
+		a.invalidateSecrets()
+	case subjects.MessageTypeStatusConfigUpdate:
+		a.handleConfigUpdate(message)
 	default:
 		logger.Warnf(context.Background(), "Unknown app status message type: %s", message.Type)
 	}
@@ -0,0 +1,81 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ai-agent-os/ai-agent-os/dto"
+	"github.com/ai-agent-os/ai-agent-os/pkg/logger"
+	"github.com/ai-agent-os/ai-agent-os/pkg/msgx"
+	"github.com/ai-agent-os/ai-agent-os/pkg/subjects"
+	"github.com/ai-agent-os/ai-agent-os/sdk/agent-app/env"
+)
+
+// 审批模式
+const (
+	ApprovalModeSequential = dto.ApprovalModeSequential // 依次审批
+	ApprovalModeParallel   = dto.ApprovalModeParallel   // 会签（全部通过）
+	ApprovalModeAnyOf      = dto.ApprovalModeAnyOf      // 或签（任意一人通过）
+)
+
+// 审批超时处理方式
+const (
+	ApprovalTimeoutEscalate = dto.ApprovalTimeoutEscalate // 升级给 EscalateTo 或审批人的 Leader
+	ApprovalTimeoutApprove  = dto.ApprovalTimeoutApprove  // 自动通过
+	ApprovalTimeoutReject   = dto.ApprovalTimeoutReject   // 自动拒绝
+)
+
+// ApprovalProcess 审批流程定义，节点按顺序执行，某个节点被拒绝则整个审批结束
+//
+//	var ExpenseApproval = &app.ApprovalProcess{
+//		Code: "expense",
+//		Name: "报销审批",
+//		Nodes: []*app.ApprovalNode{
+//			{Name: "直属领导", LeaderLevel: 1, TimeoutMinutes: 24 * 60, TimeoutAction: app.ApprovalTimeoutEscalate},
+//			{Name: "财务", Departments: []string{"/finance"}, Mode: app.ApprovalModeAnyOf},
+//		},
+//	}
+type ApprovalProcess = dto.ApprovalProcess
+
+// ApprovalNode 审批节点
+type ApprovalNode = dto.ApprovalNode
+
+// StartApproval 发起审批，返回审批实例ID
+// rowID 为关联的表格记录ID（Form 函数传 0），审批历史会展示在该记录上；
+// 审批结束后会触发当前函数模板上的 OnApprovalFinished 回调，data 会原样带回
+//
+//	instanceID, err := ctx.StartApproval(ExpenseApproval, fmt.Sprintf("%s 的报销申请", ctx.GetRequestUser()), row.ID, map[string]interface{}{
+//		"amount": row.Amount,
+//	})
+func (c *Context) StartApproval(process *ApprovalProcess, title string, rowID int64, data map[string]interface{}) (int64, error) {
+	if process == nil || len(process.Nodes) == 0 {
+		return 0, fmt.Errorf("approval process is empty")
+	}
+	if c.routerInfo == nil {
+		return 0, fmt.Errorf("router info is nil")
+	}
+	if app == nil || app.conn == nil {
+		return 0, fmt.Errorf("app not initialized")
+	}
+
+	req := &dto.StartApprovalReq{
+		User:         env.User,
+		App:          env.App,
+		FullCodePath: fmt.Sprintf("/%s/%s/%s", env.User, env.App, strings.Trim(c.routerInfo.Router, "/")),
+		RequestUser:  c.msg.RequestUser,
+		TraceID:      c.msg.TraceId,
+		Token:        c.token,
+		Process:      *process,
+		Title:        title,
+		RowID:        rowID,
+		Data:         data,
+	}
+
+	var resp dto.StartApprovalResp
+	if _, err := msgx.RequestMsgWithTimeout(c, app.conn, subjects.GetAppServerApprovalStartSubject(), req, &resp, 10*time.Second); err != nil {
+		return 0, fmt.Errorf("start approval failed: %w", err)
+	}
+	logger.Infof(c, "[Approval] 审批已发起: process=%s, instance=%d, approvers=%v", process.Code, resp.InstanceID, resp.Approvers)
+	return resp.InstanceID, nil
+}
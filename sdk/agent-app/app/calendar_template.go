@@ -0,0 +1,151 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/ai-agent-os/ai-agent-os/pkg/gormx/query"
+	"github.com/ai-agent-os/ai-agent-os/sdk/agent-app/callback"
+	"github.com/ai-agent-os/ai-agent-os/sdk/agent-app/response"
+	"github.com/ai-agent-os/ai-agent-os/sdk/agent-app/widget"
+)
+
+// calendarRangeLimit 日历一次最多返回的记录数，超出时返回 truncated
+const calendarRangeLimit = 2000
+
+// CalendarTemplate 日历模板：按开始/结束时间字段把 AutoCrudTable 的记录展示在日历上
+//
+// 日历复用 TableTemplate 的 AutoCrudTable 和增删改回调（新建、编辑、删除日程与表格一致），
+// 前端切换月/周/日视图时通过内置的 OnCalendarRange 回调按时间范围加载日程：
+//
+//	type Meeting struct {
+//	    ID      int    `json:"id" gorm:"primaryKey"`
+//	    Title   string `json:"title" widget:"name:主题;type:input"`
+//	    StartAt int64  `json:"start_at" widget:"name:开始时间;type:timestamp"`
+//	    EndAt   int64  `json:"end_at" widget:"name:结束时间;type:timestamp"`
+//	}
+//
+//	group.GET("meeting_calendar", nil, &app.CalendarTemplate{
+//	    TableTemplate: app.TableTemplate{BaseConfig: app.BaseConfig{Name: "会议日历"}, AutoCrudTable: &Meeting{}},
+//	    StartField:    "start_at",
+//	    EndField:      "end_at",
+//	    TitleField:    "title",
+//	})
+type CalendarTemplate struct {
+	TableTemplate
+
+	StartField string // 开始时间字段（json 标签），必须是 timestamp 组件（毫秒时间戳）
+	EndField   string // 结束时间字段（可选，json 标签），为空或为 0 时表示没有结束时间的单点日程
+	TitleField string // 日程标题字段（可选，json 标签）
+
+	// OnCalendarRange 按时间范围加载日程的回调（可选），不设置时 SDK 直接查询 AutoCrudTable
+	OnCalendarRange OnCalendarRange `json:"-"`
+
+	startColumn string // 开始时间字段的数据库列名
+	endColumn   string // 结束时间字段的数据库列名
+}
+
+func (t *CalendarTemplate) TemplateType() TemplateType {
+	return TemplateTypeCalendar
+}
+
+// initTemplate 注册时校验日历配置
+func (t *CalendarTemplate) initTemplate() error {
+	if t.StartField == "" {
+		return errors.New("日历必须设置 StartField")
+	}
+	for _, field := range []string{t.StartField, t.EndField} {
+		if field == "" {
+			continue
+		}
+		tags, err := t.modelFieldTags(field)
+		if err != nil {
+			return err
+		}
+		if tags.WidgetParsed["type"] != widget.TypeTimestamp {
+			return fmt.Errorf("日历时间字段 %s 必须是 timestamp 组件", field)
+		}
+	}
+	if t.TitleField != "" {
+		if _, err := t.modelFieldTags(t.TitleField); err != nil {
+			return err
+		}
+	}
+
+	var err error
+	if t.startColumn, err = t.modelColumn(t.StartField); err != nil {
+		return err
+	}
+	if t.EndField != "" {
+		if t.endColumn, err = t.modelColumn(t.EndField); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// templateConfig 返回给前端的日历配置
+func (t *CalendarTemplate) templateConfig() interface{} {
+	return map[string]interface{}{
+		"start_field": t.StartField,
+		"end_field":   t.EndField,
+		"title_field": t.TitleField,
+	}
+}
+
+// handleList 日历默认的处理函数：按搜索参数分页查询（列表视图），按开始时间排序
+func (t *CalendarTemplate) handleList(ctx *Context, resp response.Response) error {
+	return t.autoCrudList(ctx, resp, func(pageInfo *query.SearchFilterPageReq) {
+		if pageInfo.Sorts == "" {
+			pageInfo.Sorts = t.startColumn + ":asc"
+		}
+	})
+}
+
+// handleCalendarRange 按时间范围加载日程：返回与 [Start, End) 有交集的记录
+func (t *CalendarTemplate) handleCalendarRange(ctx *Context, req *callback.OnCalendarRangeReq) (*callback.OnCalendarRangeResp, error) {
+	if req.End <= req.Start {
+		return nil, errors.New("结束时间必须大于开始时间")
+	}
+	if t.OnCalendarRange != nil {
+		return t.OnCalendarRange(ctx, req)
+	}
+	if t.AutoCrudTable == nil {
+		return nil, errors.New("没有设置 AutoCrudTable 时必须实现 OnCalendarRange")
+	}
+
+	db := ctx.GetGormDB()
+	if db == nil {
+		return nil, errors.New("获取数据库连接失败")
+	}
+	db, err := query.ApplySearchConditions(db.Model(t.AutoCrudTable), &req.SearchFilterPageReq)
+	if err != nil {
+		return nil, err
+	}
+	db, _, err = query.ApplyKeyword(db, t.AutoCrudTable, &req.SearchFilterPageReq)
+	if err != nil {
+		return nil, err
+	}
+
+	start, end := t.startColumn, t.endColumn
+	if end == "" {
+		db = db.Where(start+" >= ? AND "+start+" < ?", req.Start, req.End)
+	} else {
+		// 有结束时间的日程按区间重叠判断，没有结束时间的按开始时间判断
+		db = db.Where(start+" < ?", req.End).
+			Where("("+end+" > ? OR (("+end+" IS NULL OR "+end+" = 0) AND "+start+" >= ?))", req.Start, req.Start)
+	}
+
+	items := newModelSlice(t.AutoCrudTable)
+	if err := db.Order(start + " asc").Order("id asc").Limit(calendarRangeLimit + 1).Find(items).Error; err != nil {
+		return nil, err
+	}
+
+	resp := &callback.OnCalendarRangeResp{Items: items}
+	if rows := reflect.ValueOf(items).Elem(); rows.Len() > calendarRangeLimit {
+		resp.Items = rows.Slice(0, calendarRangeLimit).Interface()
+		resp.Truncated = true
+	}
+	return resp, nil
+}
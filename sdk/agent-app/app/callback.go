@@ -11,6 +11,11 @@ const (
 	CallbackTypeOnTableCreateInBatches = "OnTableCreateInBatches" // 系统内置批量创建回调
 	CallbackTypeOnPageLoad            = "OnPageLoad"
 	CallbackTypeOnSelectFuzzy         = "OnSelectFuzzy"
+	CallbackTypeOnApprovalFinished    = "OnApprovalFinished" // 审批结束回调
+	CallbackTypeOnTableLoadChildren   = "OnTableLoadChildren" // 系统内置子表加载回调（主从表）
+	CallbackTypeOnKanbanMove          = "OnKanbanMove"        // 看板拖动卡片回调
+	CallbackTypeOnCalendarRange       = "OnCalendarRange"     // 日历按时间范围加载日程回调
+	CallbackTypeOnFormStepValidate    = "OnFormStepValidate"  // 向导表单步骤校验回调
 )
 
 type OnTableAddRow func(ctx *Context, req *callback.OnTableAddRowReq) (*callback.OnTableAddRowResp, error)
@@ -29,3 +34,16 @@ type OnPageLoad func(ctx *Context, req *callback.OnPageLoadReq) (*callback.OnPag
 
 // OnSelectFuzzy 只有select组件才有的，当在select输入框输入关键字时候，如果有这个回调的话，会触发这个回调
 type OnSelectFuzzy func(ctx *Context, req *callback.OnSelectFuzzyReq) (*callback.OnSelectFuzzyResp, error)
+
+// OnApprovalFinished 通过 ctx.StartApproval 发起的审批结束（通过、拒绝、撤回）时触发该回调，用来回写业务状态
+type OnApprovalFinished func(ctx *Context, req *callback.OnApprovalFinishedReq) (*callback.OnApprovalFinishedResp, error)
+
+// OnKanbanMove 看板中拖动卡片到其他列（或调整列内顺序）时触发该回调，不实现时 SDK 直接更新分列字段和排序字段
+type OnKanbanMove func(ctx *Context, req *callback.OnKanbanMoveReq) (*callback.OnKanbanMoveResp, error)
+
+// OnCalendarRange 日历切换视图时按时间范围加载日程，不实现时 SDK 直接按开始/结束时间字段查询
+type OnCalendarRange func(ctx *Context, req *callback.OnCalendarRangeReq) (*callback.OnCalendarRangeResp, error)
+
+// OnFormStepValidate 向导表单进入下一步前触发该回调，用来做服务端校验（如合同编号是否有效）和预填后续步骤的字段
+// 最终提交时 SDK 会按顺序重新执行所有步骤的校验，回调中不要有副作用
+type OnFormStepValidate func(ctx *Context, req *callback.OnFormStepValidateReq) (*callback.OnFormStepValidateResp, error)
@@ -1,9 +1,24 @@
 package app
 
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/ai-agent-os/ai-agent-os/pkg/gormx/query"
+	"github.com/ai-agent-os/ai-agent-os/sdk/agent-app/response"
+	"github.com/ai-agent-os/ai-agent-os/sdk/agent-app/types"
+	"gorm.io/gorm"
+)
+
 type ChartTemplate struct {
 	BaseConfig
 	// 注意：ChartTemplate 不需要回调函数（OnTableAddRow 等）
 	// 因为 BI 图表是只读的，不需要增删改操作
+
+	// Aggregate 声明式聚合图表（可选）
+	// 设置后注册路由时 handleFunc 可以传 nil：请求参数按 Request 结构体的 search 标签转换为搜索条件，
+	// 在 SQL 中聚合后自动生成 types.Chart，不需要手写查询
+	Aggregate *ChartAggregate `json:"-"`
 }
 
 func (t *ChartTemplate) GetBaseConfig() *BaseConfig {
@@ -14,3 +29,181 @@ func (t *ChartTemplate) TemplateType() TemplateType {
 	return TemplateTypeChart
 }
 
+// ChartAggregate 聚合图表配置
+//
+// 示例（按月统计每种状态的订单金额）：
+//
+//	Aggregate: &app.ChartAggregate{
+//	    Model:     &Order{},
+//	    ChartType: "bar",
+//	    Metrics:   []query.AggregateMetric{{Op: query.AggSum, Field: "amount", Alias: "订单金额"}},
+//	    GroupBy:   []query.AggregateGroup{{Field: "created_at", Bucket: query.BucketMonth}, {Field: "status"}},
+//	    TZOffset:  480,
+//	}
+type ChartAggregate struct {
+	Model     interface{} // 数据模型（决定查询的表）
+	ChartType string      // bar/line/area/pie，默认 bar
+	Title     string
+
+	// Metrics 指标，每个指标一个数据系列（饼图只使用第一个指标）
+	Metrics []query.AggregateMetric
+	// GroupBy 第一个分组作为 X 轴（饼图的扇区）；第二个分组（可选，饼图不支持）把每个指标按取值拆成多个系列
+	GroupBy []query.AggregateGroup
+	// Filter 固定的过滤条件（可选），与请求中的搜索条件是 AND 关系
+	Filter *query.FilterNode
+
+	Limit    int // 最多的分组数，默认 1000
+	TZOffset int // 时间分桶的时区偏移（分钟），如东八区为 480
+}
+
+// BuildChart 在 pageInfo 的搜索条件下执行聚合查询并生成图表
+func (a *ChartAggregate) BuildChart(db *gorm.DB, pageInfo *query.SearchFilterPageReq) (*types.Chart, error) {
+	chartType := a.ChartType
+	if chartType == "" {
+		chartType = "bar"
+	}
+	if len(a.GroupBy) > 2 || (chartType == "pie" && len(a.GroupBy) > 1) {
+		return nil, fmt.Errorf("%s 图表不支持 %d 个分组字段", chartType, len(a.GroupBy))
+	}
+	if pageInfo == nil {
+		pageInfo = new(query.SearchFilterPageReq)
+	}
+	if a.Filter != nil {
+		if _, err := pageInfo.WithFilter(a.Filter); err != nil {
+			return nil, err
+		}
+	}
+
+	result, err := query.Aggregate(db, a.Model, pageInfo, &query.AggregateReq{
+		Metrics:  a.Metrics,
+		GroupBy:  a.GroupBy,
+		Limit:    a.Limit,
+		TZOffset: a.TZOffset,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	chart := &types.Chart{
+		ChartType: chartType,
+		Title:     a.Title,
+		Series:    make([]types.ChartSeries, 0),
+	}
+	if result.Truncated {
+		chart.Metadata = map[string]interface{}{"truncated": true}
+	}
+
+	switch {
+	case chartType == "pie":
+		chart.Series = append(chart.Series, aggregatePieSeries(result))
+	case len(result.GroupBy) == 0:
+		// 没有分组：每个指标是 X 轴上的一项
+		series := types.ChartSeries{Name: a.Title, Data: make([]interface{}, 0, len(result.Metrics))}
+		for _, metric := range result.Metrics {
+			chart.XAxis = append(chart.XAxis, metric)
+			if len(result.Rows) > 0 {
+				series.Data = append(series.Data, result.Rows[0][metric])
+			} else {
+				series.Data = append(series.Data, nil)
+			}
+		}
+		chart.Series = append(chart.Series, series)
+	default:
+		chart.XAxis, chart.Series = aggregateAxisSeries(result)
+	}
+	return chart, nil
+}
+
+// aggregatePieSeries 饼图：第一个分组的取值作为扇区，没有分组时每个指标是一个扇区
+func aggregatePieSeries(result *query.AggregateResult) types.ChartSeries {
+	series := types.ChartSeries{Name: result.Metrics[0], Data: make([]interface{}, 0)}
+	if len(result.GroupBy) == 0 {
+		for _, metric := range result.Metrics {
+			var value interface{}
+			if len(result.Rows) > 0 {
+				value = result.Rows[0][metric]
+			}
+			series.Data = append(series.Data, map[string]interface{}{"name": metric, "value": value})
+		}
+		return series
+	}
+	for _, row := range result.Rows {
+		series.Data = append(series.Data, map[string]interface{}{
+			"name":  aggregateLabel(row[result.GroupBy[0]]),
+			"value": row[result.Metrics[0]],
+		})
+	}
+	return series
+}
+
+// aggregateAxisSeries 柱状图/折线图：第一个分组作为 X 轴，第二个分组的每个取值拆成一个系列，缺少的点为 null
+func aggregateAxisSeries(result *query.AggregateResult) ([]string, []types.ChartSeries) {
+	xAxis := make([]string, 0)
+	xIndex := make(map[string]int)
+	for _, row := range result.Rows {
+		x := aggregateLabel(row[result.GroupBy[0]])
+		if _, ok := xIndex[x]; !ok {
+			xIndex[x] = len(xAxis)
+			xAxis = append(xAxis, x)
+		}
+	}
+
+	series := make([]types.ChartSeries, 0)
+	seriesIndex := make(map[string]int)
+	for _, row := range result.Rows {
+		x := xIndex[aggregateLabel(row[result.GroupBy[0]])]
+		for _, metric := range result.Metrics {
+			name := metric
+			if len(result.GroupBy) > 1 {
+				name = aggregateLabel(row[result.GroupBy[1]])
+				if len(result.Metrics) > 1 {
+					name = metric + "-" + name
+				}
+			}
+			i, ok := seriesIndex[name]
+			if !ok {
+				i = len(series)
+				seriesIndex[name] = i
+				series = append(series, types.ChartSeries{Name: name, Data: make([]interface{}, len(xAxis))})
+			}
+			series[i].Data[x] = row[metric]
+		}
+	}
+	return xAxis, series
+}
+
+// aggregateLabel 分组取值转换为图表标签
+func aggregateLabel(v interface{}) string {
+	if v == nil {
+		return ""
+	}
+	return fmt.Sprint(v)
+}
+
+// handleAggregate 声明式聚合图表的处理函数
+func (t *ChartTemplate) handleAggregate(ctx *Context, resp response.Response) error {
+	pageInfo := new(query.SearchFilterPageReq)
+	if t.Request != nil {
+		// 按 Request 结构体的 search 标签转换为搜索条件
+		reqType := reflect.TypeOf(t.Request)
+		if reqType.Kind() == reflect.Ptr {
+			reqType = reqType.Elem()
+		}
+		req := reflect.New(reqType).Interface()
+		if err := ctx.ShouldBind(req); err != nil {
+			return err
+		}
+		var err error
+		if pageInfo, err = query.StructToSearchFilterPageReq(req); err != nil {
+			return err
+		}
+	} else if err := ctx.ShouldBind(pageInfo); err != nil {
+		return err
+	}
+
+	chart, err := t.Aggregate.BuildChart(ctx.GetGormDB(), pageInfo)
+	if err != nil {
+		return err
+	}
+	return resp.Chart(chart).Build()
+}
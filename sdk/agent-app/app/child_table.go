@@ -0,0 +1,477 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/ai-agent-os/ai-agent-os/pkg/gormx/query"
+	"github.com/ai-agent-os/ai-agent-os/pkg/logger"
+	"github.com/ai-agent-os/ai-agent-os/sdk/agent-app/callback"
+	"github.com/ai-agent-os/ai-agent-os/sdk/agent-app/response"
+	"github.com/ai-agent-os/ai-agent-os/sdk/agent-app/widget"
+	"github.com/go-playground/validator/v10"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// ChildTable 主从表的子表（明细表），子表记录通过外键关联主表记录的主键
+//
+// 主表模型中用 []struct 字段声明子表（widget type:table，gorm:"-"，子表数据不保存在主表中）：
+//
+//	type Order struct {
+//	    ID    int          `json:"id" gorm:"primaryKey"`
+//	    Items []*OrderItem `json:"items" gorm:"-" widget:"name:订单明细;type:table"`
+//	}
+//
+//	type OrderItem struct {
+//	    ID       int     `json:"id" gorm:"primaryKey"`
+//	    OrderID  int     `json:"order_id" widget:"-"`
+//	    Name     string  `json:"name" widget:"name:商品;type:input" search:"like" validate:"required"`
+//	    Price    float64 `json:"price" widget:"name:单价;type:float" validate:"gt=0"`
+//	    Quantity int     `json:"quantity" widget:"name:数量;type:number" validate:"min=1"`
+//	}
+//
+//	AutoCrudTable: &Order{},
+//	ChildTables: []*app.ChildTable{{
+//	    Code:       "items",
+//	    Model:      &OrderItem{},
+//	    ForeignKey: "order_id",
+//	    Totals:     []string{"sum:price", "sum:quantity", "count:id"},
+//	    Statistics: map[string]interface{}{"合计(元)": statistics.Sum("单价,*quantity")},
+//	}},
+//
+// SDK 会：
+//   - 在一个事务中执行 OnTableAddRow/OnTableUpdateRow/OnTableDeleteRows 和子表的写入（回调中 ctx.GetGormDB() 返回事务连接），
+//     任何一步失败都会整体回滚；回调中拿不到子表数据，子表由 SDK 按 validate 标签校验后保存
+//   - 新增时按回调返回的 data 中的主键写入子表；更新时请求中带了子表数据则按整个列表同步（新增、更新、删除不在列表中的行）；
+//     删除主表记录时删除对应的子表记录
+//   - 展开主表行时通过内置的 OnTableLoadChildren 回调懒加载子表，支持与表格相同的搜索、排序、分页和聚合统计
+type ChildTable struct {
+	Code       string      // 主表模型中子表字段的 json 标签
+	Model      interface{} // 子表模型（子表模型会自动加入 CreateTables）
+	ForeignKey string      // 子表中保存主表主键的字段（json 标签、列名或 Go 字段名）
+
+	// Totals 加载子表时默认返回的汇总（格式同搜索参数 aggs：op:field[:alias]），按全部子表记录统计，不受分页影响
+	Totals []string
+	// Statistics 子表的统计表达式（statistics 包），随子表数据一起返回给前端展示
+	Statistics map[string]interface{}
+
+	name string // 子表显示名称（主表字段的 widget name）
+}
+
+var (
+	modelSchemaCache = &sync.Map{}
+	modelValidate    = validator.New()
+)
+
+// getChildTable 根据 code 获取子表
+func (t *TableTemplate) getChildTable(code string) (*ChildTable, error) {
+	for _, child := range t.ChildTables {
+		if child.Code == code {
+			return child, nil
+		}
+	}
+	return nil, fmt.Errorf("子表 %s 不存在", code)
+}
+
+// initChildTables 注册时校验子表声明，并把子表模型加入 CreateTables
+func (t *TableTemplate) initChildTables() error {
+	if len(t.ChildTables) == 0 {
+		return nil
+	}
+	if t.AutoCrudTable == nil {
+		return errors.New("声明了 ChildTables 的表格函数必须设置 AutoCrudTable")
+	}
+	parsed, err := widget.ParseModelWithType(t.AutoCrudTable)
+	if err != nil {
+		return fmt.Errorf("解析主表模型失败: %w", err)
+	}
+
+	codes := make(map[string]bool)
+	for _, child := range t.ChildTables {
+		if child.Code == "" || child.Model == nil || child.ForeignKey == "" {
+			return errors.New("子表的 Code、Model、ForeignKey 不能为空")
+		}
+		if codes[child.Code] {
+			return fmt.Errorf("子表 %s 重复声明", child.Code)
+		}
+		codes[child.Code] = true
+
+		for _, tag := range parsed.Tags {
+			if strings.Split(tag.GetCode(), ",")[0] == child.Code {
+				child.name = tag.WidgetParsed["name"]
+				break
+			}
+		}
+		if child.name == "" {
+			child.name = child.Code
+		}
+
+		s, err := schema.Parse(child.Model, modelSchemaCache, schema.NamingStrategy{})
+		if err != nil {
+			return fmt.Errorf("解析子表 %s 模型失败: %w", child.Code, err)
+		}
+		if s.PrioritizedPrimaryField == nil {
+			return fmt.Errorf("子表 %s 没有主键", child.Code)
+		}
+		if lookupSchemaField(s, child.ForeignKey) == nil {
+			return fmt.Errorf("子表 %s 没有外键字段 %s", child.Code, child.ForeignKey)
+		}
+
+		exists := false
+		for _, table := range t.CreateTables {
+			if reflect.TypeOf(table) == reflect.TypeOf(child.Model) {
+				exists = true
+				break
+			}
+		}
+		if !exists {
+			t.CreateTables = append(t.CreateTables, child.Model)
+		}
+	}
+	return nil
+}
+
+// lookupSchemaField 根据 json 标签、列名或 Go 字段名查找模型字段
+func lookupSchemaField(s *schema.Schema, field string) *schema.Field {
+	if f := s.LookUpField(field); f != nil && f.DBName != "" {
+		return f
+	}
+	for _, f := range s.Fields {
+		if f.DBName != "" && strings.Split(f.Tag.Get("json"), ",")[0] == field {
+			return f
+		}
+	}
+	return nil
+}
+
+// runInTx 在事务中执行 fn，fn 中 ctx.GetGormDB() 返回事务连接
+func (ctx *Context) runInTx(fn func() error) error {
+	db := ctx.GetGormDB()
+	if db == nil {
+		return errors.New("获取数据库连接失败")
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		ctx.tx = tx
+		defer func() { ctx.tx = nil }()
+		return fn()
+	})
+}
+
+// takeChildRows 从请求体中取出子表数据（取出后用户回调中不再包含子表字段）
+func (t *TableTemplate) takeChildRows(ctx *Context) (map[*ChildTable][]json.RawMessage, error) {
+	var body map[string]json.RawMessage
+	if err := json.Unmarshal(ctx.body, &body); err != nil {
+		return nil, fmt.Errorf("解析请求数据失败: %w", err)
+	}
+
+	rows := make(map[*ChildTable][]json.RawMessage)
+	for _, child := range t.ChildTables {
+		raw, exists := body[child.Code]
+		if !exists {
+			continue
+		}
+		delete(body, child.Code)
+		var items []json.RawMessage
+		if string(raw) != "null" {
+			if err := json.Unmarshal(raw, &items); err != nil {
+				return nil, fmt.Errorf("子表「%s」数据格式错误: %w", child.name, err)
+			}
+		}
+		rows[child] = items
+	}
+
+	body2, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	ctx.body = body2
+	return rows, nil
+}
+
+// takeChildUpdates 从更新请求中取出子表数据
+func (t *TableTemplate) takeChildUpdates(req *callback.OnTableUpdateRowReq) (map[*ChildTable][]json.RawMessage, error) {
+	rows := make(map[*ChildTable][]json.RawMessage)
+	for _, child := range t.ChildTables {
+		value, exists := req.Updates[child.Code]
+		delete(req.Updates, child.Code)
+		delete(req.BindUpdatesMap, child.Code)
+		delete(req.OldValues, child.Code)
+		if !exists {
+			continue
+		}
+		raw, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		var items []json.RawMessage
+		if string(raw) != "null" {
+			if err := json.Unmarshal(raw, &items); err != nil {
+				return nil, fmt.Errorf("子表「%s」数据格式错误: %w", child.name, err)
+			}
+		}
+		rows[child] = items
+	}
+	return rows, nil
+}
+
+// decodeChildRows 把子表数据解析为子表模型并校验（validate 标签）
+func (child *ChildTable) decodeChildRows(rows []json.RawMessage) ([]reflect.Value, error) {
+	modelType := reflect.TypeOf(child.Model)
+	if modelType.Kind() == reflect.Ptr {
+		modelType = modelType.Elem()
+	}
+
+	values := make([]reflect.Value, 0, len(rows))
+	for i, row := range rows {
+		value := reflect.New(modelType)
+		if err := json.Unmarshal(row, value.Interface()); err != nil {
+			return nil, fmt.Errorf("子表「%s」第 %d 行数据格式错误: %w", child.name, i+1, err)
+		}
+		if err := modelValidate.Struct(value.Interface()); err != nil {
+			return nil, fmt.Errorf("子表「%s」第 %d 行校验失败: %w", child.name, i+1, err)
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+// saveChildRows 同步主表记录的子表数据：有主键的行更新，没有主键的行新增，不在列表中的行删除
+func (child *ChildTable) saveChildRows(ctx *Context, tx *gorm.DB, parentID interface{}, rows []json.RawMessage) error {
+	values, err := child.decodeChildRows(rows)
+	if err != nil {
+		return err
+	}
+	s, err := schema.Parse(child.Model, modelSchemaCache, tx.NamingStrategy)
+	if err != nil {
+		return err
+	}
+	pk := s.PrioritizedPrimaryField
+	fk := lookupSchemaField(s, child.ForeignKey)
+	fkColumn := query.SafeColumnName(fk.DBName)
+	pkColumn := query.SafeColumnName(pk.DBName)
+
+	var keep []interface{}
+	for i, value := range values {
+		if err := fk.Set(ctx, value.Elem(), parentID); err != nil {
+			return fmt.Errorf("子表「%s」第 %d 行设置外键失败: %w", child.name, i+1, err)
+		}
+		id, isZero := pk.ValueOf(ctx, value.Elem())
+		if isZero {
+			if err := tx.Create(value.Interface()).Error; err != nil {
+				return fmt.Errorf("子表「%s」第 %d 行新增失败: %w", child.name, i+1, err)
+			}
+			id, _ = pk.ValueOf(ctx, value.Elem())
+			keep = append(keep, id)
+			continue
+		}
+
+		// 只能修改属于当前主表记录的子表记录
+		var count int64
+		err := tx.Model(child.Model).Where(pkColumn+" = ? AND "+fkColumn+" = ?", id, parentID).Count(&count).Error
+		if err != nil {
+			return fmt.Errorf("查询子表「%s」记录失败: %w", child.name, err)
+		}
+		if count == 0 {
+			return fmt.Errorf("子表「%s」第 %d 行（%v）不属于当前记录", child.name, i+1, id)
+		}
+		if err := tx.Save(value.Interface()).Error; err != nil {
+			return fmt.Errorf("子表「%s」第 %d 行更新失败: %w", child.name, i+1, err)
+		}
+		keep = append(keep, id)
+	}
+
+	del := tx.Where(fkColumn+" = ?", parentID)
+	if len(keep) > 0 {
+		del = del.Where(pkColumn+" NOT IN ?", keep)
+	}
+	if err := del.Delete(child.Model).Error; err != nil {
+		return fmt.Errorf("删除子表「%s」记录失败: %w", child.name, err)
+	}
+	return nil
+}
+
+// parentIDFromData 从 OnTableAddRow 返回的 data 中获取新增记录的主键
+func (t *TableTemplate) parentIDFromData(tx *gorm.DB, data interface{}) (interface{}, error) {
+	s, err := schema.Parse(t.AutoCrudTable, modelSchemaCache, tx.NamingStrategy)
+	if err != nil {
+		return nil, err
+	}
+	pk := s.PrioritizedPrimaryField
+	if pk == nil {
+		return nil, fmt.Errorf("表 %s 没有主键", s.Table)
+	}
+
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	var row map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &row); err != nil {
+		return nil, errors.New("OnTableAddRow 需要在 data 中返回新增的记录，才能保存子表")
+	}
+	key := strings.Split(pk.Tag.Get("json"), ",")[0]
+	if key == "" {
+		key = pk.Name
+	}
+
+	var id json.Number
+	decoder := json.NewDecoder(strings.NewReader(string(row[key])))
+	decoder.UseNumber()
+	if err := decoder.Decode(&id); err != nil || id == "" || id == "0" {
+		var text string
+		if json.Unmarshal(row[key], &text) == nil && text != "" {
+			return text, nil
+		}
+		return nil, fmt.Errorf("OnTableAddRow 返回的 data 中没有主键 %s，无法保存子表", key)
+	}
+	if n, err := id.Int64(); err == nil {
+		return n, nil
+	}
+	return id.String(), nil
+}
+
+// addRowWithChildren 新增主表记录和子表记录（同一个事务）
+func (t *TableTemplate) addRowWithChildren(ctx *Context, req *callback.OnTableAddRowReq) (*callback.OnTableAddRowResp, error) {
+	rows, err := t.takeChildRows(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for child, items := range rows {
+		if _, err := child.decodeChildRows(items); err != nil {
+			return nil, err
+		}
+	}
+
+	var resp *callback.OnTableAddRowResp
+	err = ctx.runInTx(func() error {
+		tx := ctx.tx
+		var err error
+		resp, err = t.OnTableAddRow(ctx, req)
+		if err != nil || len(rows) == 0 {
+			return err
+		}
+		if resp == nil {
+			return errors.New("OnTableAddRow 需要在 data 中返回新增的记录，才能保存子表")
+		}
+		parentID, err := t.parentIDFromData(tx, resp.Data)
+		if err != nil {
+			return err
+		}
+		for child, items := range rows {
+			if err := child.saveChildRows(ctx, tx, parentID, items); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return resp, err
+}
+
+// updateRowWithChildren 更新主表记录并同步子表记录（同一个事务）
+func (t *TableTemplate) updateRowWithChildren(ctx *Context, req *callback.OnTableUpdateRowReq) (*callback.OnTableUpdateRowResp, error) {
+	rows, err := t.takeChildUpdates(req)
+	if err != nil {
+		return nil, err
+	}
+	for child, items := range rows {
+		if _, err := child.decodeChildRows(items); err != nil {
+			return nil, err
+		}
+	}
+
+	var resp *callback.OnTableUpdateRowResp
+	err = ctx.runInTx(func() error {
+		tx := ctx.tx
+		var err error
+		// 只修改了子表时不需要更新主表
+		if len(req.Updates) > 0 || len(rows) == 0 {
+			if resp, err = t.OnTableUpdateRow(ctx, req); err != nil {
+				return err
+			}
+		}
+		for child, items := range rows {
+			if err := child.saveChildRows(ctx, tx, req.GetId(), items); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err == nil && resp == nil {
+		resp = &callback.OnTableUpdateRowResp{}
+	}
+	return resp, err
+}
+
+// deleteRowsWithChildren 删除主表记录和对应的子表记录（同一个事务）
+func (t *TableTemplate) deleteRowsWithChildren(ctx *Context, req *callback.OnTableDeleteRowsReq) (*callback.OnTableDeleteRowsResp, error) {
+	var resp *callback.OnTableDeleteRowsResp
+	err := ctx.runInTx(func() error {
+		tx := ctx.tx
+		var err error
+		if resp, err = t.OnTableDeleteRows(ctx, req); err != nil {
+			return err
+		}
+		if len(req.GetIds()) == 0 {
+			return nil
+		}
+		for _, child := range t.ChildTables {
+			column, err := modelColumn(tx, child.Model, child.ForeignKey)
+			if err != nil {
+				return err
+			}
+			if err := tx.Where(query.SafeColumnName(column)+" IN ?", req.GetIds()).Delete(child.Model).Error; err != nil {
+				return fmt.Errorf("删除子表「%s」记录失败: %w", child.name, err)
+			}
+		}
+		return nil
+	})
+	return resp, err
+}
+
+// handleTableLoadChildren 系统内置的子表加载回调：展开主表行时按外键加载子表记录
+func handleTableLoadChildren(ctx *Context, template *TableTemplate, req *callback.OnTableLoadChildrenReq) (*callback.OnTableLoadChildrenResp, error) {
+	child, err := template.getChildTable(req.Code)
+	if err != nil {
+		return nil, err
+	}
+	db := ctx.GetGormDB()
+	if db == nil {
+		return nil, errors.New("获取数据库连接失败")
+	}
+	column, err := modelColumn(db, child.Model, child.ForeignKey)
+	if err != nil {
+		return nil, err
+	}
+
+	pageInfo := &req.SearchFilterPageReq
+	if !pageInfo.HasAggregate() && len(child.Totals) > 0 {
+		pageInfo.Aggs = child.Totals
+	}
+	if pageInfo.GetSorts() == "" && !pageInfo.IsCursorMode() {
+		s, err := schema.Parse(child.Model, modelSchemaCache, db.NamingStrategy)
+		if err != nil {
+			return nil, err
+		}
+		pageInfo.Sorts = s.PrioritizedPrimaryField.DBName + ":asc"
+	}
+
+	items := newModelSlice(child.Model)
+
+	resp := &response.RunFunctionResp{}
+	where := db.Model(child.Model).Where(query.SafeColumnName(column)+" = ?", req.ParentID)
+	resp.Table(items).AutoSearchFilterPaged(where, child.Model, pageInfo)
+	if err := resp.Build(); err != nil {
+		return nil, err
+	}
+	logger.Infof(ctx, "[handleTableLoadChildren] code=%s parent_id=%v", req.Code, req.ParentID)
+
+	return &callback.OnTableLoadChildrenResp{
+		TableData:  resp.TableData,
+		Statistics: child.Statistics,
+	}, nil
+}
@@ -0,0 +1,120 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ai-agent-os/ai-agent-os/dto"
+	appPkg "github.com/ai-agent-os/ai-agent-os/pkg/app"
+	"github.com/ai-agent-os/ai-agent-os/pkg/logger"
+	"github.com/ai-agent-os/ai-agent-os/pkg/msgx"
+	"github.com/ai-agent-os/ai-agent-os/pkg/subjects"
+	"github.com/ai-agent-os/ai-agent-os/sdk/agent-app/env"
+)
+
+var (
+	configLock  sync.RWMutex
+	configCache = make(map[string]*dto.FunctionConfigRuntime) // full_code_path -> 当前生效的配置
+)
+
+// Config 读取当前函数的运行时配置
+// 配置结构在注册函数时通过 BaseConfig.Config 声明，字段值即为默认值；
+// 在工作空间中修改配置后会推送到运行中的应用，下次读取即为新值，无需重新编译
+//
+//	var cfg CrmTicketConfig
+//	if err := ctx.Config(&cfg); err != nil {
+//		return err
+//	}
+func (c *Context) Config(v interface{}) error {
+	if c.routerInfo == nil {
+		return fmt.Errorf("router info is nil")
+	}
+	base := c.routerInfo.Template.GetBaseConfig()
+	if base == nil || base.Config == nil {
+		return fmt.Errorf("function %s has no config declared", c.routerInfo.Router)
+	}
+
+	// 先填充代码中声明的默认值，再用线上配置覆盖（线上配置中缺失的字段保持默认值）
+	defaults, err := json.Marshal(base.Config)
+	if err != nil {
+		return fmt.Errorf("marshal config default failed: %w", err)
+	}
+	if err := json.Unmarshal(defaults, v); err != nil {
+		return fmt.Errorf("unmarshal config default failed: %w", err)
+	}
+
+	fullCodePath := fmt.Sprintf("/%s/%s/%s", env.User, env.App, strings.Trim(c.routerInfo.Router, "/"))
+	cfg := loadConfig(c, fullCodePath)
+	if cfg == nil || len(cfg.Values) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(cfg.Values, v); err != nil {
+		return fmt.Errorf("unmarshal config values failed: %w", err)
+	}
+	return nil
+}
+
+// loadConfig 加载函数配置（带缓存），获取失败时返回 nil，调用方使用默认值
+func loadConfig(ctx context.Context, fullCodePath string) *dto.FunctionConfigRuntime {
+	configLock.RLock()
+	cfg, ok := configCache[fullCodePath]
+	configLock.RUnlock()
+	if ok {
+		return cfg
+	}
+
+	cfg, err := fetchConfig(ctx, fullCodePath)
+	if err != nil {
+		// 不缓存失败结果，下次读取时重试
+		logger.Warnf(ctx, "[Config] 从 app-server 获取配置失败，使用默认值: path=%s, err=%v", fullCodePath, err)
+		return nil
+	}
+
+	configLock.Lock()
+	// 已经有推送过来的更新版本时，以较新的为准
+	if existing, ok := configCache[fullCodePath]; ok && existing.Version > cfg.Version {
+		cfg = existing
+	} else {
+		configCache[fullCodePath] = cfg
+	}
+	configLock.Unlock()
+	return cfg
+}
+
+// fetchConfig 通过 NATS 向 app-server 获取函数当前配置
+func fetchConfig(ctx context.Context, fullCodePath string) (*dto.FunctionConfigRuntime, error) {
+	if app == nil || app.conn == nil {
+		return nil, fmt.Errorf("app not initialized")
+	}
+	var resp dto.FunctionConfigRuntime
+	req := &dto.GetFunctionConfigRuntimeReq{User: env.User, App: env.App, FullCodePath: fullCodePath, Token: os.Getenv(appPkg.SecretTokenEnv)}
+	if _, err := msgx.RequestMsgWithTimeout(ctx, app.conn, subjects.GetAppServerConfigRequestSubject(), req, &resp, 5*time.Second); err != nil {
+		return nil, err
+	}
+	resp.FullCodePath = fullCodePath
+	return &resp, nil
+}
+
+// handleConfigUpdate 处理 app-server 推送的配置变更，直接更新缓存
+func (a *App) handleConfigUpdate(message subjects.Message) {
+	data, err := json.Marshal(message.Data)
+	if err != nil {
+		logger.Errorf(a, "[Config] 解析配置变更消息失败: %v", err)
+		return
+	}
+	var cfg dto.FunctionConfigRuntime
+	if err := json.Unmarshal(data, &cfg); err != nil || cfg.FullCodePath == "" {
+		logger.Errorf(a, "[Config] 解析配置变更消息失败: data=%s, err=%v", string(data), err)
+		return
+	}
+
+	configLock.Lock()
+	configCache[cfg.FullCodePath] = &cfg
+	configLock.Unlock()
+	logger.Infof(a, "[Config] 函数配置已更新: path=%s, version=%d", cfg.FullCodePath, cfg.Version)
+}
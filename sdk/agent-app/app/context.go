@@ -11,6 +11,7 @@ import (
 	"github.com/ai-agent-os/ai-agent-os/pkg/trace"
 	"github.com/ai-agent-os/ai-agent-os/sdk/agent-app/env"
 	"github.com/go-playground/form/v4"
+	"gorm.io/gorm"
 )
 
 func newCallbackContext(info *routerInfo) *Context {
@@ -56,9 +57,19 @@ type Context struct {
 	urlQuery   string
 	token      string      // ✨ Token（用于调用存储服务等）
 	routerInfo *routerInfo // 当前请求对应的路由信息（包含 PackagePath）
+	tx         *gorm.DB    // 主从表回调执行期间的事务连接（GetGormDB 优先返回）
+	department *string     // 当前用户的部门（动态默认值 $dept 用到时查询并缓存）
 }
 
+// ShouldBind 绑定请求参数，绑定后按 widget 标签执行字段规则（动态默认值、条件显示/必填/只读，见 fieldRules）
 func (c *Context) ShouldBind(req interface{}) error {
+	if err := c.bind(req); err != nil {
+		return err
+	}
+	return c.applyFieldRules(req)
+}
+
+func (c *Context) bind(req interface{}) error {
 	if c.msg == nil {
 		return fmt.Errorf("msg is nil")
 	}
@@ -84,6 +95,13 @@ func (c *Context) ShouldBind(req interface{}) error {
 }
 
 func (c *Context) ShouldBindValidate(req interface{}) error {
+	if err := c.bindValidate(req); err != nil {
+		return err
+	}
+	return c.applyFieldRules(req)
+}
+
+func (c *Context) bindValidate(req interface{}) error {
 	if c.msg == nil {
 		return fmt.Errorf("msg is nil")
 	}
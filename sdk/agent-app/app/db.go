@@ -32,6 +32,11 @@ func (c *Context) GetGormDB() *gorm.DB {
 	// 否则使用默认的数据库名称（兼容旧代码）
 	var dbName string
 
+	// 主从表回调在事务中执行，回调中的读写都使用事务连接
+	if c.tx != nil {
+		return c.tx
+	}
+
 	if c.routerInfo != nil && c.routerInfo.Options != nil {
 		// 根据 PackagePath 构建数据库名称
 		// 例如：/plugins -> plugins.db, /crm/ticket -> crm_ticket.db
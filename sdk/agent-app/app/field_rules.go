@@ -0,0 +1,490 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ai-agent-os/ai-agent-os/pkg/logger"
+	"github.com/ai-agent-os/ai-agent-os/sdk/agent-app/widget"
+	"gorm.io/gorm"
+)
+
+// 字段规则：widget 标签中的 show_if、required_if、readonly_if 和动态默认值（default:$xxx），
+// 前端按表达式控制表单，SDK 在服务端按相同的表达式重新处理，不信任前端提交的数据：
+//
+//	type Invoice struct {
+//	    Type      string `json:"type" widget:"name:抬头类型;type:select;options:个人,企业"`
+//	    TaxNo     string `json:"tax_no" widget:"name:税号;type:input;show_if:type == 企业;required_if:type == 企业"`
+//	    Applicant string `json:"applicant" widget:"name:申请人;type:user;default:$user;readonly_if:type == 个人"`
+//	    Dept      string `json:"dept" widget:"name:部门;type:input;default:$dept"`
+//	    DueAt     int64  `json:"due_at" widget:"name:截止时间;type:timestamp;default:$today+7d"`
+//	}
+//
+// 新增（ShouldBind、AutoCrudTable 新增记录和批量导入）：为空的字段填充动态默认值，清空隐藏字段，
+// 只读字段重置为默认值，显示的字段满足 required_if 时必须填写；
+// 修改（AutoCrudTable 修改记录）：按记录当前的值合并本次修改后求值，丢弃对只读字段的修改，隐藏字段只能清空
+
+// fieldRule 一个顶层字段的条件和动态默认值
+type fieldRule struct {
+	code       string
+	label      string
+	fieldName  string // Go 字段名
+	fieldType  reflect.Type
+	showIf     *widget.Condition
+	requiredIf *widget.Condition
+	readonlyIf *widget.Condition
+
+	defaultValue   string // widget 标签中的 default，只读字段重置时使用
+	dynamicDefault *widget.DynamicDefault
+}
+
+type fieldRules []*fieldRule
+
+type fieldRulesEntry struct {
+	rules fieldRules
+	err   error
+}
+
+// fieldRulesCache 结构体类型 -> *fieldRulesEntry
+var fieldRulesCache sync.Map
+
+// getFieldRules 获取结构体的字段规则（按类型缓存），不是结构体或没有声明规则时返回 nil
+func getFieldRules(model interface{}) (fieldRules, error) {
+	typ := reflect.TypeOf(model)
+	for typ != nil && typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ == nil || typ.Kind() != reflect.Struct {
+		return nil, nil
+	}
+	if v, ok := fieldRulesCache.Load(typ); ok {
+		entry := v.(*fieldRulesEntry)
+		return entry.rules, entry.err
+	}
+	rules, err := parseFieldRules(typ)
+	fieldRulesCache.Store(typ, &fieldRulesEntry{rules: rules, err: err})
+	return rules, err
+}
+
+func parseFieldRules(typ reflect.Type) (fieldRules, error) {
+	parsed, err := widget.ParseModelWithType(reflect.New(typ).Interface())
+	if err != nil {
+		return nil, err
+	}
+
+	codes := make(map[string]bool)
+	var rules fieldRules
+	for _, tag := range parsed.Tags {
+		code := strings.Split(tag.GetCode(), ",")[0]
+		if code == "" || code == "-" {
+			continue
+		}
+		codes[code] = true
+
+		rule := &fieldRule{
+			code:         code,
+			label:        fieldLabel(code, tag),
+			fieldName:    tag.FieldName,
+			fieldType:    tag.Type,
+			defaultValue: tag.WidgetParsed["default"],
+		}
+		for _, c := range []struct {
+			key    string
+			target **widget.Condition
+		}{
+			{"show_if", &rule.showIf},
+			{"required_if", &rule.requiredIf},
+			{"readonly_if", &rule.readonlyIf},
+		} {
+			expr := tag.WidgetParsed[c.key]
+			if expr == "" {
+				continue
+			}
+			if *c.target, err = widget.ParseCondition(expr); err != nil {
+				return nil, fmt.Errorf("字段 %s 的 %s: %w", code, c.key, err)
+			}
+		}
+		if widget.IsDynamicDefault(rule.defaultValue) {
+			if rule.dynamicDefault, err = widget.ParseDynamicDefault(rule.defaultValue); err != nil {
+				return nil, fmt.Errorf("字段 %s 的 default: %w", code, err)
+			}
+			if err := rule.checkDefaultType(); err != nil {
+				return nil, err
+			}
+		}
+		if rule.showIf != nil || rule.requiredIf != nil || rule.readonlyIf != nil || rule.dynamicDefault != nil {
+			rules = append(rules, rule)
+		}
+	}
+
+	// 条件中引用的字段必须存在
+	for _, rule := range rules {
+		for _, cond := range []*widget.Condition{rule.showIf, rule.requiredIf, rule.readonlyIf} {
+			if cond == nil {
+				continue
+			}
+			for _, field := range cond.Fields() {
+				if !codes[field] {
+					return nil, fmt.Errorf("字段 %s 的条件 %q 引用了不存在的字段 %s", rule.code, cond.Expr, field)
+				}
+			}
+		}
+	}
+	return rules, nil
+}
+
+// baseKind 字段类型（指针取元素类型）
+func (r *fieldRule) baseKind() reflect.Kind {
+	typ := r.fieldType
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	return typ.Kind()
+}
+
+// checkDefaultType 时间类默认值只能用于数值（毫秒时间戳）和字符串字段，$user、$dept 只能用于字符串字段
+func (r *fieldRule) checkDefaultType() error {
+	switch kind := r.baseKind(); {
+	case kind == reflect.String:
+		return nil
+	case r.dynamicDefault.IsTime() && isNumberKind(kind):
+		return nil
+	}
+	return fmt.Errorf("字段 %s 的类型 %s 不支持默认值 %s", r.code, r.fieldType, r.defaultValue)
+}
+
+func isNumberKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// visible 字段是否显示
+func (r *fieldRule) visible(values map[string]interface{}) bool {
+	return r.showIf == nil || r.showIf.Eval(values)
+}
+
+// zeroValue 字段的零值（修改记录时清空隐藏字段使用）
+func (r *fieldRule) zeroValue() interface{} {
+	switch kind := r.baseKind(); {
+	case kind == reflect.String:
+		return ""
+	case kind == reflect.Bool:
+		return false
+	case isNumberKind(kind):
+		return 0
+	}
+	return nil
+}
+
+// ruleDefaults 一次请求内计算的默认值（同一个请求中 $now 等只计算一次）
+type ruleDefaults struct {
+	ctx    *Context
+	vars   widget.DefaultVars
+	values map[string]interface{}
+}
+
+func newRuleDefaults(ctx *Context) *ruleDefaults {
+	vars := widget.DefaultVars{Now: time.Now(), User: ctx.GetRequestUser()}
+	vars.Dept = ctx.GetRequestUserDepartment
+	return &ruleDefaults{ctx: ctx, vars: vars, values: make(map[string]interface{})}
+}
+
+// get 字段的默认值：动态默认值按字段类型转换（时间为毫秒时间戳或 2006-01-02 15:04:05），静态默认值按字段类型解析，没有时为 nil
+func (d *ruleDefaults) get(r *fieldRule) interface{} {
+	if v, ok := d.values[r.code]; ok {
+		return v
+	}
+	var value interface{}
+	if r.dynamicDefault != nil {
+		resolved, err := r.dynamicDefault.Resolve(d.vars)
+		if err != nil {
+			// 获取部门等失败时不填充默认值，由必填校验决定是否允许提交
+			logger.Warnf(d.ctx, "[fieldRules] 计算字段 %s 的默认值 %s 失败: %v", r.code, r.defaultValue, err)
+		} else if t, ok := resolved.(time.Time); ok {
+			if r.baseKind() == reflect.String {
+				value = t.Format(time.DateTime)
+			} else {
+				value = t.UnixMilli()
+			}
+		} else if s, _ := resolved.(string); s != "" {
+			value = s
+		}
+	} else if r.defaultValue != "" {
+		switch kind := r.baseKind(); {
+		case kind == reflect.String:
+			value = r.defaultValue
+		case kind == reflect.Bool:
+			if b, err := strconv.ParseBool(r.defaultValue); err == nil {
+				value = b
+			}
+		case isNumberKind(kind):
+			if _, err := strconv.ParseFloat(r.defaultValue, 64); err == nil {
+				value = json.Number(r.defaultValue)
+			}
+		}
+	}
+	d.values[r.code] = value
+	return value
+}
+
+// applyCreate 新增时执行字段规则，values 为 json code -> 值，返回被修改的字段
+func (rules fieldRules) applyCreate(ctx *Context, values map[string]interface{}) (map[string]bool, error) {
+	changed := make(map[string]bool)
+	defaults := newRuleDefaults(ctx)
+
+	for _, r := range rules {
+		if r.dynamicDefault != nil && widget.IsEmptyValue(values[r.code]) {
+			if v := defaults.get(r); v != nil {
+				values[r.code] = v
+				changed[r.code] = true
+			}
+		}
+	}
+
+	// 清空字段可能改变其他字段的条件，重复执行直到没有变化
+	for i := 0; i <= len(rules); i++ {
+		stable := true
+		for _, r := range rules {
+			if !r.visible(values) {
+				if !widget.IsEmptyValue(values[r.code]) {
+					values[r.code] = nil
+					changed[r.code] = true
+					stable = false
+				}
+				continue
+			}
+			if r.readonlyIf != nil && r.readonlyIf.Eval(values) {
+				if v := defaults.get(r); !sameJSONValue(values[r.code], v) {
+					values[r.code] = v
+					changed[r.code] = true
+					stable = false
+				}
+			}
+		}
+		if stable {
+			break
+		}
+	}
+
+	for _, r := range rules {
+		if r.requiredIf != nil && r.visible(values) && r.requiredIf.Eval(values) && widget.IsEmptyValue(values[r.code]) {
+			return nil, fmt.Errorf("%s不能为空", r.label)
+		}
+	}
+	return changed, nil
+}
+
+// applyUpdate 修改记录时执行字段规则，current 为记录当前的值，updates 为本次修改的字段（原地修改）
+func (rules fieldRules) applyUpdate(current, updates map[string]interface{}) error {
+	merged := make(map[string]interface{}, len(current)+len(updates))
+	for k, v := range current {
+		merged[k] = v
+	}
+	for k, v := range updates {
+		merged[k] = v
+	}
+
+	// 修改前或修改后满足只读条件的字段都不能修改
+	for _, r := range rules {
+		if _, ok := updates[r.code]; ok && r.readonlyIf != nil && (r.readonlyIf.Eval(current) || r.readonlyIf.Eval(merged)) {
+			delete(updates, r.code)
+			merged[r.code] = current[r.code]
+		}
+	}
+
+	// 隐藏字段只能清空
+	for i := 0; i <= len(rules); i++ {
+		stable := true
+		for _, r := range rules {
+			if v, ok := updates[r.code]; ok && !r.visible(merged) && !widget.IsEmptyValue(v) {
+				updates[r.code] = r.zeroValue()
+				merged[r.code] = updates[r.code]
+				stable = false
+			}
+		}
+		if stable {
+			break
+		}
+	}
+
+	// 只校验本次修改涉及的条件必填，不因为历史数据阻止修改其他字段
+	for _, r := range rules {
+		if r.requiredIf == nil || !ruleTouched(r, updates) {
+			continue
+		}
+		if r.visible(merged) && r.requiredIf.Eval(merged) && widget.IsEmptyValue(merged[r.code]) {
+			return fmt.Errorf("%s不能为空", r.label)
+		}
+	}
+	return nil
+}
+
+// ruleTouched 本次修改是否涉及字段本身或其条件引用的字段
+func ruleTouched(r *fieldRule, updates map[string]interface{}) bool {
+	if _, ok := updates[r.code]; ok {
+		return true
+	}
+	for _, cond := range []*widget.Condition{r.showIf, r.requiredIf} {
+		if cond == nil {
+			continue
+		}
+		for _, field := range cond.Fields() {
+			if _, ok := updates[field]; ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// sameJSONValue 比较两个值序列化后是否相同（数值可能是 json.Number、float64 或 int64）
+func sameJSONValue(a, b interface{}) bool {
+	ab, err1 := json.Marshal(a)
+	bb, err2 := json.Marshal(b)
+	return err1 == nil && err2 == nil && bytes.Equal(ab, bb)
+}
+
+// toValues 结构体或 JSON 转换为 json code -> 值（数值保留为 json.Number）
+func toValues(v interface{}) (map[string]interface{}, error) {
+	data, ok := v.([]byte)
+	if !ok {
+		var err error
+		if data, err = json.Marshal(v); err != nil {
+			return nil, err
+		}
+	}
+	values := make(map[string]interface{})
+	if len(bytes.TrimSpace(data)) == 0 {
+		return values, nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&values); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// applyFieldRules ShouldBind 绑定后按请求结构体的字段规则处理
+func (c *Context) applyFieldRules(req interface{}) error {
+	rules, err := getFieldRules(req)
+	if err != nil || len(rules) == 0 {
+		return err
+	}
+	rv := reflect.ValueOf(req)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+	rv = rv.Elem()
+
+	values, err := toValues(req)
+	if err != nil {
+		return err
+	}
+	changed, err := rules.applyCreate(c, values)
+	if err != nil {
+		return err
+	}
+	for _, r := range rules {
+		if !changed[r.code] {
+			continue
+		}
+		field := rv.FieldByName(r.fieldName)
+		field.Set(reflect.Zero(field.Type()))
+		if values[r.code] == nil {
+			continue
+		}
+		data, err := json.Marshal(values[r.code])
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(data, field.Addr().Interface()); err != nil {
+			return fmt.Errorf("设置字段 %s 失败: %w", r.code, err)
+		}
+	}
+	return nil
+}
+
+// applyBodyFieldRules AutoCrudTable 新增记录前按模型的字段规则处理请求体
+// 用户在 OnTableAddRow 中 ShouldBind 到其他结构体时，处理后的请求体同样生效
+func (c *Context) applyBodyFieldRules(model interface{}) error {
+	rules, err := getFieldRules(model)
+	if err != nil || len(rules) == 0 {
+		return err
+	}
+	values, err := toValues(c.body)
+	if err != nil {
+		return fmt.Errorf("解析请求体失败: %w", err)
+	}
+	changed, err := rules.applyCreate(c, values)
+	if err != nil || len(changed) == 0 {
+		return err
+	}
+	for code := range changed {
+		if values[code] == nil {
+			delete(values, code)
+		}
+	}
+	c.body, err = json.Marshal(values)
+	return err
+}
+
+// applyUpdateFieldRules AutoCrudTable 修改记录前按模型的字段规则处理 updates，记录当前的值从数据库读取
+func (t *TableTemplate) applyUpdateFieldRules(ctx *Context, id int, updates map[string]interface{}) error {
+	if t.AutoCrudTable == nil || len(updates) == 0 {
+		return nil
+	}
+	rules, err := getFieldRules(t.AutoCrudTable)
+	if err != nil || len(rules) == 0 {
+		return err
+	}
+	db := ctx.GetGormDB()
+	if db == nil {
+		return errors.New("获取数据库连接失败")
+	}
+	modelType := reflect.TypeOf(t.AutoCrudTable)
+	if modelType.Kind() == reflect.Ptr {
+		modelType = modelType.Elem()
+	}
+	row := reflect.New(modelType).Interface()
+	if err := db.Model(t.AutoCrudTable).Where("id = ?", id).Take(row).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("记录 %d 不存在", id)
+		}
+		return err
+	}
+	current, err := toValues(row)
+	if err != nil {
+		return err
+	}
+	return rules.applyUpdate(current, updates)
+}
+
+// initFieldRules 注册时校验 Request 和 AutoCrudTable 的字段规则
+func initFieldRules(templater Templater) error {
+	models := []interface{}{templater.GetBaseConfig().Request}
+	if table, ok := asTableTemplate(templater); ok {
+		models = append(models, table.AutoCrudTable)
+	}
+	for _, model := range models {
+		if model == nil {
+			continue
+		}
+		if _, err := getFieldRules(model); err != nil {
+			return err
+		}
+	}
+	return nil
+}
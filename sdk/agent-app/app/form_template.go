@@ -2,6 +2,13 @@ package app
 
 type FormTemplate struct {
 	BaseConfig
+	OnApprovalFinished OnApprovalFinished
+
+	// OnFormStepValidateMap 向导表单每个步骤的服务端校验（可选），key 为步骤名称（Request 结构体字段的 step 标签）
+	// Request 结构体中声明了 step 标签时表单按步骤填写，见 form_wizard.go
+	OnFormStepValidateMap map[string]OnFormStepValidate `json:"-"`
+
+	steps []*formStep // 向导表单的步骤（注册时根据 step 标签解析）
 }
 
 func (t *FormTemplate) GetBaseConfig() *BaseConfig {
@@ -0,0 +1,290 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/ai-agent-os/ai-agent-os/sdk/agent-app/callback"
+	"github.com/ai-agent-os/ai-agent-os/sdk/agent-app/response"
+	"github.com/ai-agent-os/ai-agent-os/sdk/agent-app/widget"
+	"github.com/go-playground/validator/v10"
+)
+
+// 向导表单：Request 结构体字段上的 step 标签把表单拆成多个步骤，step 标签写在每个步骤的第一个字段上，
+// 后面没有 step 标签的字段属于同一个步骤（第一个 step 标签之前的字段属于第一步）：
+//
+//	type ApplyReq struct {
+//	    ContractNo string `json:"contract_no" widget:"name:合同编号;type:input" validate:"required" step:"合同信息"`
+//	    Company    string `json:"company" widget:"name:公司名称;type:input" validate:"required"`
+//	    Contact    string `json:"contact" widget:"name:联系人;type:input" validate:"required" step:"联系人"`
+//	    Phone      string `json:"phone" widget:"name:电话;type:input"`
+//	}
+//
+//	group.POST("apply", Apply, &app.FormTemplate{
+//	    BaseConfig: app.BaseConfig{Name: "入驻申请", Request: &ApplyReq{}},
+//	    OnFormStepValidateMap: map[string]app.OnFormStepValidate{
+//	        "合同信息": func(ctx *app.Context, req *callback.OnFormStepValidateReq) (*callback.OnFormStepValidateResp, error) {
+//	            var apply ApplyReq
+//	            if err := req.Bind(&apply); err != nil {
+//	                return nil, err
+//	            }
+//	            resp := &callback.OnFormStepValidateResp{}
+//	            contract, ok := findContract(ctx, apply.ContractNo)
+//	            if !ok {
+//	                resp.AddError("contract_no", "合同编号不存在")
+//	                return resp, nil
+//	            }
+//	            resp.SetPrefill("contact", contract.Contact)
+//	            return resp, nil
+//	        },
+//	    },
+//	})
+//
+// 进入下一步前前端调用 OnFormStepValidate：SDK 先按当前步骤字段的 validate 标签校验，通过后再执行该步骤的回调；
+// 最终提交时 SDK 按顺序重新校验所有步骤，全部通过后才执行 handler，草稿由 app-server 保存
+
+// formStep 向导表单的一个步骤
+type formStep struct {
+	Name       string   `json:"name"`
+	Fields     []string `json:"fields"`   // 字段 code（json 标签）
+	Validate   bool     `json:"validate"` // 是否有服务端回调
+	fieldNames []string // Go 字段名（validator 部分校验使用）
+	fieldLabel map[string]string
+}
+
+// initTemplate 注册时根据 Request 结构体的 step 标签解析向导表单的步骤
+func (t *FormTemplate) initTemplate() error {
+	t.steps = nil
+	if t.Request == nil {
+		if len(t.OnFormStepValidateMap) > 0 {
+			return errors.New("设置了 OnFormStepValidateMap 的表单必须设置 Request")
+		}
+		return nil
+	}
+	parsed, err := widget.ParseModelWithType(t.Request)
+	if err != nil {
+		return fmt.Errorf("解析 Request 失败: %w", err)
+	}
+
+	var steps []*formStep
+	var pending []*widget.FieldTags // 第一个 step 标签之前的字段
+	for _, tag := range parsed.Tags {
+		code := strings.Split(tag.GetCode(), ",")[0]
+		if code == "" || code == "-" {
+			continue
+		}
+		if tag.Step != "" {
+			for _, step := range steps {
+				if step.Name == tag.Step {
+					return fmt.Errorf("步骤 %s 的字段必须连续声明", tag.Step)
+				}
+			}
+			steps = append(steps, &formStep{Name: tag.Step, fieldLabel: make(map[string]string)})
+		}
+		if len(steps) == 0 {
+			pending = append(pending, tag)
+			continue
+		}
+		steps[len(steps)-1].addField(code, tag)
+	}
+	if len(steps) == 0 {
+		if len(t.OnFormStepValidateMap) > 0 {
+			return errors.New("设置了 OnFormStepValidateMap 的表单必须在 Request 中声明 step 标签")
+		}
+		return nil
+	}
+	for i := len(pending) - 1; i >= 0; i-- {
+		code := strings.Split(pending[i].GetCode(), ",")[0]
+		steps[0].Fields = append([]string{code}, steps[0].Fields...)
+		steps[0].fieldNames = append([]string{pending[i].FieldName}, steps[0].fieldNames...)
+		steps[0].fieldLabel[code] = fieldLabel(code, pending[i])
+	}
+
+	for name := range t.OnFormStepValidateMap {
+		if formStepIndex(steps, name) < 0 {
+			return fmt.Errorf("OnFormStepValidateMap 中的步骤 %s 不存在", name)
+		}
+	}
+	for _, step := range steps {
+		step.Validate = t.OnFormStepValidateMap[step.Name] != nil
+	}
+	t.steps = steps
+	return nil
+}
+
+func (s *formStep) addField(code string, tag *widget.FieldTags) {
+	s.Fields = append(s.Fields, code)
+	s.fieldNames = append(s.fieldNames, tag.FieldName)
+	s.fieldLabel[code] = fieldLabel(code, tag)
+}
+
+// fieldLabel 字段的显示名称（widget name），没有时使用 code
+func fieldLabel(code string, tag *widget.FieldTags) string {
+	if name := tag.WidgetParsed["name"]; name != "" {
+		return name
+	}
+	return code
+}
+
+// formStepIndex 根据步骤名称获取步骤序号，不存在时返回 -1
+func formStepIndex(steps []*formStep, name string) int {
+	for i, step := range steps {
+		if step.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// isWizard 是否是向导表单
+func (t *FormTemplate) isWizard() bool {
+	return len(t.steps) > 0
+}
+
+// templateConfig 返回给前端的向导表单步骤，普通表单返回 nil
+func (t *FormTemplate) templateConfig() interface{} {
+	if !t.isWizard() {
+		return nil
+	}
+	return map[string]interface{}{"steps": t.steps}
+}
+
+// validateStep 校验向导表单的一个步骤：先按字段的 validate 标签校验，通过后执行该步骤的回调
+func (t *FormTemplate) validateStep(ctx *Context, req *callback.OnFormStepValidateReq) (*callback.OnFormStepValidateResp, error) {
+	index := formStepIndex(t.steps, req.Step)
+	if index < 0 {
+		return nil, fmt.Errorf("步骤 %s 不存在", req.Step)
+	}
+	req.StepIndex = index
+	step := t.steps[index]
+
+	reqType := reflect.TypeOf(t.Request)
+	if reqType.Kind() == reflect.Ptr {
+		reqType = reqType.Elem()
+	}
+	value := reflect.New(reqType).Interface()
+	if err := req.Bind(value); err != nil {
+		return nil, fmt.Errorf("解析表单数据失败: %w", err)
+	}
+
+	resp := &callback.OnFormStepValidateResp{}
+	if err := modelValidate.StructPartial(value, step.fieldNames...); err != nil {
+		var validationErrors validator.ValidationErrors
+		if !errors.As(err, &validationErrors) {
+			return nil, err
+		}
+		for _, fe := range validationErrors {
+			code := step.fieldCode(fe.StructField())
+			resp.AddError(code, validateMessage(step.fieldLabel[code], fe))
+		}
+	}
+
+	if onValidate := t.OnFormStepValidateMap[step.Name]; resp.Passed() && onValidate != nil {
+		userResp, err := onValidate(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		if userResp != nil {
+			resp.Errors, resp.Prefill = userResp.Errors, userResp.Prefill
+		}
+	}
+
+	// 只能预填后续步骤的字段，避免覆盖用户已经填写的数据
+	for code := range resp.Prefill {
+		if t.fieldStep(code) <= index {
+			return nil, fmt.Errorf("步骤 %s 只能预填后续步骤的字段，%s 不是后续步骤的字段", step.Name, code)
+		}
+	}
+	if resp.Passed() && index+1 < len(t.steps) {
+		resp.NextStep = t.steps[index+1].Name
+	}
+	return resp, nil
+}
+
+// fieldCode 根据 Go 字段名获取字段 code
+func (s *formStep) fieldCode(fieldName string) string {
+	for i, name := range s.fieldNames {
+		if name == fieldName {
+			return s.Fields[i]
+		}
+	}
+	return fieldName
+}
+
+// firstError 按字段顺序返回第一个校验错误
+func (s *formStep) firstError(errs map[string]string) string {
+	for _, code := range s.Fields {
+		if msg, ok := errs[code]; ok {
+			return msg
+		}
+	}
+	// 回调中返回了不属于该步骤的字段
+	for code, msg := range errs {
+		return code + ": " + msg
+	}
+	return ""
+}
+
+// fieldStep 字段所在的步骤序号，不存在时返回 -1
+func (t *FormTemplate) fieldStep(code string) int {
+	for i, step := range t.steps {
+		for _, field := range step.Fields {
+			if field == code {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// validateMessage validate 标签校验失败的提示信息
+func validateMessage(label string, fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return fmt.Sprintf("%s不能为空", label)
+	case "min", "gte":
+		return fmt.Sprintf("%s不能小于 %s", label, fe.Param())
+	case "max", "lte":
+		return fmt.Sprintf("%s不能大于 %s", label, fe.Param())
+	case "len":
+		return fmt.Sprintf("%s的长度必须为 %s", label, fe.Param())
+	case "oneof":
+		return fmt.Sprintf("%s必须是 %s 中的一个", label, fe.Param())
+	}
+	if fe.Param() != "" {
+		return fmt.Sprintf("%s校验失败（%s=%s）", label, fe.Tag(), fe.Param())
+	}
+	return fmt.Sprintf("%s校验失败（%s）", label, fe.Tag())
+}
+
+// wrapWizardSubmit 向导表单最终提交时按顺序重新校验所有步骤，全部通过后才执行 handler（防止跳过步骤直接提交）
+func (t *FormTemplate) wrapWizardSubmit(handleFunc HandleFunc) HandleFunc {
+	return func(ctx *Context, resp response.Response) error {
+		reqType := reflect.TypeOf(t.Request)
+		if reqType.Kind() == reflect.Ptr {
+			reqType = reqType.Elem()
+		}
+		value := reflect.New(reqType).Interface()
+		if err := ctx.ShouldBind(value); err != nil {
+			return err
+		}
+		body, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+
+		for _, step := range t.steps {
+			stepResp, err := t.validateStep(ctx, &callback.OnFormStepValidateReq{Step: step.Name, Body: body})
+			if err != nil {
+				return err
+			}
+			if !stepResp.Passed() {
+				return fmt.Errorf("步骤「%s」校验未通过: %s", step.Name, step.firstError(stepResp.Errors))
+			}
+		}
+		return handleFunc(ctx, resp)
+	}
+}
@@ -0,0 +1,201 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ai-agent-os/ai-agent-os/pkg/gormx/query"
+	"github.com/ai-agent-os/ai-agent-os/sdk/agent-app/callback"
+	"github.com/ai-agent-os/ai-agent-os/sdk/agent-app/response"
+	"github.com/ai-agent-os/ai-agent-os/sdk/agent-app/widget"
+)
+
+// kanbanDefaultPageSize 看板默认一次加载的卡片数量（表格默认 20 条，看板需要同时展示多列）
+const kanbanDefaultPageSize = 200
+
+// KanbanTemplate 看板模板：按 select/radio 字段把 AutoCrudTable 的记录分列展示，拖动卡片修改该字段
+//
+// 看板复用 TableTemplate 的 AutoCrudTable 和增删改回调（新建、编辑、删除卡片与表格一致），
+// 注册路由时 handleFunc 可以传 nil，SDK 按搜索参数自动分页查询并返回每列的卡片数量：
+//
+//	type Task struct {
+//	    ID     int    `json:"id" gorm:"primaryKey"`
+//	    Title  string `json:"title" widget:"name:标题;type:input"`
+//	    Status string `json:"status" widget:"name:状态;type:select;options:待处理,进行中,已完成"`
+//	    Sort   int    `json:"sort" widget:"name:排序;type:number" permission:"read"`
+//	}
+//
+//	group.GET("task_board", nil, &app.KanbanTemplate{
+//	    TableTemplate: app.TableTemplate{BaseConfig: app.BaseConfig{Name: "任务看板"}, AutoCrudTable: &Task{}},
+//	    GroupBy:       "status",
+//	    SortField:     "sort",
+//	    TitleField:    "title",
+//	})
+type KanbanTemplate struct {
+	TableTemplate
+
+	GroupBy    string // 分列字段（json 标签），必须是 select 或 radio 组件，选项即看板的列
+	SortField  string // 列内排序字段（可选，json 标签），设置后拖动卡片时 SDK 重新编号目标列
+	TitleField string // 卡片标题字段（可选，json 标签）
+
+	// OnKanbanMove 拖动卡片的回调（可选），不设置时 SDK 直接更新 AutoCrudTable 中的分列字段和排序字段
+	OnKanbanMove OnKanbanMove `json:"-"`
+
+	columns     []string // 看板的列（分列字段的选项）
+	creatable   bool     // 分列字段是否允许新增选项（允许时不校验目标列）
+	groupColumn string   // 分列字段的数据库列名
+	sortColumn  string   // 排序字段的数据库列名
+}
+
+func (t *KanbanTemplate) TemplateType() TemplateType {
+	return TemplateTypeKanban
+}
+
+// initTemplate 注册时校验看板配置，并读取分列字段的选项
+func (t *KanbanTemplate) initTemplate() error {
+	if t.GroupBy == "" {
+		return errors.New("看板必须设置 GroupBy")
+	}
+	tags, err := t.modelFieldTags(t.GroupBy)
+	if err != nil {
+		return err
+	}
+	switch config := widget.NewWidget(tags.WidgetParsed["type"], tags.WidgetParsed).Config().(type) {
+	case *widget.Select:
+		t.columns, t.creatable = config.Options, config.Creatable
+	case *widget.Radio:
+		t.columns = config.Options
+	default:
+		return fmt.Errorf("看板分列字段 %s 必须是 select 或 radio 组件", t.GroupBy)
+	}
+	if !fieldUpdatable(tags) {
+		return fmt.Errorf("看板分列字段 %s 没有修改权限", t.GroupBy)
+	}
+	if t.groupColumn, err = t.modelColumn(t.GroupBy); err != nil {
+		return err
+	}
+	if t.SortField != "" {
+		if t.sortColumn, err = t.modelColumn(t.SortField); err != nil {
+			return err
+		}
+	}
+	for _, field := range []string{t.SortField, t.TitleField} {
+		if field == "" {
+			continue
+		}
+		if _, err := t.modelFieldTags(field); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// templateConfig 返回给前端的看板配置
+func (t *KanbanTemplate) templateConfig() interface{} {
+	return map[string]interface{}{
+		"group_by":    t.GroupBy,
+		"sort_field":  t.SortField,
+		"title_field": t.TitleField,
+		"columns":     t.columns,
+	}
+}
+
+// fieldUpdatable 字段权限为空或包含 update 时允许修改（与表格编辑的权限规则一致）
+func fieldUpdatable(tags *widget.FieldTags) bool {
+	return tags.Permission == "" || strings.Contains(tags.Permission, "update")
+}
+
+// handleList 看板默认的处理函数：按搜索参数分页查询卡片，并按分列字段统计每列的卡片数量
+func (t *KanbanTemplate) handleList(ctx *Context, resp response.Response) error {
+	return t.autoCrudList(ctx, resp, func(pageInfo *query.SearchFilterPageReq) {
+		if pageInfo.PageSize <= 0 {
+			pageInfo.PageSize = kanbanDefaultPageSize
+		}
+		if len(pageInfo.Aggs) == 0 {
+			pageInfo.Aggs = []string{"count:id"}
+			pageInfo.GroupBy = []string{t.groupColumn}
+		}
+		if pageInfo.Sorts == "" && t.sortColumn != "" {
+			pageInfo.Sorts = t.sortColumn + ":asc"
+		}
+	})
+}
+
+// handleKanbanMove 拖动卡片：校验后交给用户回调，没有回调时更新分列字段并重新编号目标列
+func (t *KanbanTemplate) handleKanbanMove(ctx *Context, req *callback.OnKanbanMoveReq) (*callback.OnKanbanMoveResp, error) {
+	if req.GetId() == 0 {
+		return nil, errors.New("id 不能为空")
+	}
+	for field := range req.Updates {
+		if field != t.GroupBy {
+			return nil, fmt.Errorf("拖动卡片只能修改字段 %s", t.GroupBy)
+		}
+	}
+	to, exists := req.Updates[t.GroupBy]
+	if !exists {
+		return nil, fmt.Errorf("缺少字段 %s", t.GroupBy)
+	}
+	if !t.creatable && !containsValue(t.columns, to) {
+		return nil, fmt.Errorf("看板中没有列 %v", to)
+	}
+	req.Field, req.To, req.From = t.GroupBy, to, req.OldValues[t.GroupBy]
+
+	if t.OnKanbanMove != nil {
+		return t.OnKanbanMove(ctx, req)
+	}
+	if t.AutoCrudTable == nil {
+		return nil, errors.New("没有设置 AutoCrudTable 时必须实现 OnKanbanMove")
+	}
+
+	err := ctx.runInTx(func() error {
+		db := ctx.GetGormDB()
+		var count int64
+		if err := db.Model(t.AutoCrudTable).Where("id = ?", req.GetId()).Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			return fmt.Errorf("记录 %d 不存在", req.GetId())
+		}
+		if err := db.Model(t.AutoCrudTable).Where("id = ?", req.GetId()).Update(t.groupColumn, to).Error; err != nil {
+			return err
+		}
+		if t.sortColumn == "" {
+			return nil
+		}
+
+		// 重新编号目标列：把卡片插入到 Index 位置（为空时放在列尾）
+		var ids []int
+		err := db.Model(t.AutoCrudTable).Where(t.groupColumn+" = ? AND id <> ?", to, req.GetId()).
+			Order(t.sortColumn+" asc").Order("id asc").Pluck("id", &ids).Error
+		if err != nil {
+			return err
+		}
+		index := len(ids)
+		if req.Index != nil && *req.Index >= 0 && *req.Index < index {
+			index = *req.Index
+		}
+		ids = append(ids[:index], append([]int{req.GetId()}, ids[index:]...)...)
+		for i, id := range ids {
+			if err := db.Model(t.AutoCrudTable).Where("id = ?", id).Update(t.sortColumn, i).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &callback.OnKanbanMoveResp{}, nil
+}
+
+// containsValue 判断前端传来的值（字符串或数字）是否在选项中
+func containsValue(options []string, value interface{}) bool {
+	s := fmt.Sprint(value)
+	for _, option := range options {
+		if option == s {
+			return true
+		}
+	}
+	return false
+}
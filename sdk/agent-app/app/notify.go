@@ -0,0 +1,104 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ai-agent-os/ai-agent-os/dto"
+	appPkg "github.com/ai-agent-os/ai-agent-os/pkg/app"
+	"github.com/ai-agent-os/ai-agent-os/pkg/logger"
+	"github.com/ai-agent-os/ai-agent-os/pkg/msgx"
+	"github.com/ai-agent-os/ai-agent-os/pkg/subjects"
+	"github.com/ai-agent-os/ai-agent-os/sdk/agent-app/env"
+)
+
+// 通知级别
+const (
+	NotifyLevelInfo    = "info"
+	NotifyLevelSuccess = "success"
+	NotifyLevelWarning = "warning"
+	NotifyLevelError   = "error"
+)
+
+// NotifyTo 通知接收人（用户和部门可以同时指定，发送时会展开部门成员并去重）
+type NotifyTo struct {
+	Users       []string // 用户名
+	Departments []string // 部门完整路径，如 /tech/backend
+}
+
+// NotifyUsers 通知指定用户
+func NotifyUsers(users ...string) NotifyTo {
+	return NotifyTo{Users: users}
+}
+
+// NotifyDepartments 通知指定部门下的所有成员
+func NotifyDepartments(departments ...string) NotifyTo {
+	return NotifyTo{Departments: departments}
+}
+
+// NotifyTemplate 通知模板
+// Title、Content、Link 支持 text/template 语法，渲染数据为 ctx.Notify 传入的 data
+//
+//	var TicketAssigned = &app.NotifyTemplate{
+//		Code:    "ticket_assigned",
+//		Title:   "工单已分配",
+//		Content: "工单「{{.title}}」已分配给你，请及时处理",
+//		Link:    "/luobei/demo/crm/crm_ticket?id={{.id}}",
+//	}
+type NotifyTemplate struct {
+	Code    string // 模板编码（用于统计和筛选）
+	Title   string // 标题
+	Content string // 内容
+	Level   string // 级别：info/success/warning/error，默认 info
+	Link    string // 点击跳转链接
+}
+
+// Notify 发送通知
+// 接收人会按自己的偏好收到站内信、邮件或 Webhook 推送
+//
+//	err := ctx.Notify(app.NotifyUsers(ticket.Handler), TicketAssigned, map[string]interface{}{
+//		"id":    ticket.ID,
+//		"title": ticket.Title,
+//	})
+func (c *Context) Notify(to NotifyTo, tpl *NotifyTemplate, data map[string]interface{}) error {
+	if tpl == nil {
+		return fmt.Errorf("notify template is nil")
+	}
+	if len(to.Users) == 0 && len(to.Departments) == 0 {
+		return fmt.Errorf("notify recipients is empty")
+	}
+	if app == nil || app.conn == nil {
+		return fmt.Errorf("app not initialized")
+	}
+
+	req := &dto.SendNotificationReq{
+		User:        env.User,
+		App:         env.App,
+		RequestUser: c.msg.RequestUser,
+		TraceID:     c.msg.TraceId,
+		Token:       c.token,
+		AppToken:    os.Getenv(appPkg.SecretTokenEnv),
+		Users:       to.Users,
+		Departments: to.Departments,
+		Template: dto.SendNotificationTemplate{
+			Code:    tpl.Code,
+			Title:   tpl.Title,
+			Content: tpl.Content,
+			Level:   tpl.Level,
+			Link:    tpl.Link,
+		},
+		Data: data,
+	}
+	if c.routerInfo != nil {
+		req.FullCodePath = fmt.Sprintf("/%s/%s/%s", env.User, env.App, strings.Trim(c.routerInfo.Router, "/"))
+	}
+
+	var resp dto.SendNotificationResp
+	if _, err := msgx.RequestMsgWithTimeout(c, app.conn, subjects.GetAppServerNotificationSendSubject(), req, &resp, 10*time.Second); err != nil {
+		return fmt.Errorf("send notification failed: %w", err)
+	}
+	logger.Infof(c, "[Notify] 通知已发送: template=%s, recipients=%v", tpl.Code, resp.Recipients)
+	return nil
+}
@@ -9,6 +9,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/ai-agent-os/ai-agent-os/pkg/gormx/query"
 	"github.com/ai-agent-os/ai-agent-os/pkg/logger"
 	"github.com/ai-agent-os/ai-agent-os/pkg/msgx"
 	"github.com/ai-agent-os/ai-agent-os/pkg/subjects"
@@ -310,9 +311,14 @@ func (a *App) getApis() (apis []*ApiInfo, createTables []interface{}, err error)
 		api.TemplateType = string(templateType)
 
 		switch templateType {
-		case TemplateTypeTable:
-			template := info.Template.(*TableTemplate)
+		case TemplateTypeTable, TemplateTypeKanban, TemplateTypeCalendar:
+			// 看板、日历内嵌 TableTemplate，字段和增删改回调与表格一致
+			template, _ := asTableTemplate(info.Template)
 			table := template.AutoCrudTable
+			// 子表字段展开时通过 OnTableLoadChildren 懒加载
+			for _, child := range template.ChildTables {
+				fieldsCallback[child.Code] = append(fieldsCallback[child.Code], CallbackTypeOnTableLoadChildren)
+			}
 			requestFields, responseFields, err := widget.DecodeTable(fieldsCallback, base.Request, table)
 			if err != nil {
 				return nil, nil, err
@@ -332,6 +338,18 @@ func (a *App) getApis() (apis []*ApiInfo, createTables []interface{}, err error)
 			// OnTableCreateInBatches 是系统内置的回调，所有 Table 函数都自动支持
 			// 不需要用户实现，系统会自动处理批量创建
 			callback = append(callback, CallbackTypeOnTableCreateInBatches)
+			if template.OnApprovalFinished != nil {
+				callback = append(callback, CallbackTypeOnApprovalFinished)
+			}
+			if len(template.ChildTables) > 0 {
+				callback = append(callback, CallbackTypeOnTableLoadChildren)
+			}
+			switch templateType {
+			case TemplateTypeKanban:
+				callback = append(callback, CallbackTypeOnKanbanMove)
+			case TemplateTypeCalendar:
+				callback = append(callback, CallbackTypeOnCalendarRange)
+			}
 			if len(callback) > 0 {
 				api.Callback = callback
 			}
@@ -343,6 +361,14 @@ func (a *App) getApis() (apis []*ApiInfo, createTables []interface{}, err error)
 			}
 			api.Request = fields
 			api.Response = responseFields
+			if template, ok := info.Template.(*FormTemplate); ok {
+				if template.OnApprovalFinished != nil {
+					api.Callback = append(api.Callback, CallbackTypeOnApprovalFinished)
+				}
+				if template.isWizard() {
+					api.Callback = append(api.Callback, CallbackTypeOnFormStepValidate)
+				}
+			}
 
 		case TemplateTypeChart:
 			fields, responseFields, err := widget.DecodeForm(fieldsCallback, base.Request, base.Response)
@@ -361,6 +387,28 @@ func (a *App) getApis() (apis []*ApiInfo, createTables []interface{}, err error)
 			api.Response = responseFields
 		}
 
+		// 看板的分列、日历的时间字段、向导表单的步骤等模板配置
+		if v, ok := info.Template.(interface{ templateConfig() interface{} }); ok && v.templateConfig() != nil {
+			templateConfig, err := json.Marshal(v.templateConfig())
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to marshal template config: %w", err)
+			}
+			api.TemplateConfig = templateConfig
+		}
+
+		if base.Config != nil {
+			configFields, _, err := widget.DecodeForm(nil, base.Config, nil)
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to parse config model: %w", err)
+			}
+			configDefault, err := json.Marshal(base.Config)
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to marshal config default: %w", err)
+			}
+			api.Config = configFields
+			api.ConfigDefault = configDefault
+		}
+
 		api.CreateTableModels = base.CreateTables
 		// 提取创建表的名称
 		for _, createTable := range base.CreateTables {
@@ -415,6 +463,12 @@ func (a *App) onAppUpdate(msg *nats.Msg) {
 				a.sendErrorResponse(msg, fmt.Sprintf("Failed to migrate table: %v", err))
 				return
 			}
+			// search:"fulltext" 字段的全文索引（迁移后主表可能被重建，需要重新检查触发器）
+			err = query.EnsureFullTextIndex(db, createTable)
+			if err != nil {
+				a.sendErrorResponse(msg, fmt.Sprintf("Failed to ensure fulltext index: %v", err))
+				return
+			}
 		}
 
 	}
@@ -0,0 +1,309 @@
+package app
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/ai-agent-os/ai-agent-os/pkg/gormx/query"
+	"github.com/ai-agent-os/ai-agent-os/pkg/logger"
+	"github.com/ai-agent-os/ai-agent-os/sdk/agent-app/callback"
+	"github.com/ai-agent-os/ai-agent-os/sdk/agent-app/env"
+	"github.com/ai-agent-os/ai-agent-os/sdk/agent-app/widget"
+	"gorm.io/gorm"
+)
+
+// referenceFuzzyLimit 关联组件模糊搜索最多返回的选项数
+const referenceFuzzyLimit = 50
+
+// referenceField 模型中的关联组件字段
+type referenceField struct {
+	Code      string // 字段 code（json 标签）
+	FieldName string // Go 字段名
+	Nested    bool   // 是否是 table/form 组件中的子字段（子字段不是数据库列）
+	Config    *widget.Reference
+}
+
+// referenceTarget 关联组件的目标表
+type referenceTarget struct {
+	router string
+	model  interface{}
+	db     *gorm.DB
+	value  string // 保存的字段的列名
+	label  string // 显示的字段的列名
+	search []string
+}
+
+// referenceFields 解析模型中的关联组件字段（包括 table/form 组件中的子字段）
+func referenceFields(model interface{}) []*referenceField {
+	if model == nil {
+		return nil
+	}
+	result, err := widget.ParseModelWithType(model)
+	if err != nil {
+		return nil
+	}
+
+	var fields []*referenceField
+	var walk func(tags []*widget.FieldTags, nested bool)
+	walk = func(tags []*widget.FieldTags, nested bool) {
+		for _, tag := range tags {
+			if tag.WidgetParsed["type"] == widget.TypeReference {
+				fields = append(fields, &referenceField{
+					Code:      strings.Split(tag.GetCode(), ",")[0],
+					FieldName: tag.FieldName,
+					Nested:    nested,
+					Config:    widget.NewWidget(widget.TypeReference, tag.WidgetParsed).(*widget.Reference),
+				})
+			}
+			walk(tag.Children, true)
+		}
+	}
+	walk(result.Tags, false)
+	return fields
+}
+
+// registerReferenceFuzzy 为关联组件字段自动生成 OnSelectFuzzy 回调（已有自定义回调的字段除外）
+func registerReferenceFuzzy(templater Templater) {
+	base := templater.GetBaseConfig()
+	models := []interface{}{base.Request}
+	if table, ok := asTableTemplate(templater); ok {
+		models = append(models, table.AutoCrudTable)
+	}
+
+	for _, model := range models {
+		for _, field := range referenceFields(model) {
+			if _, exists := base.OnSelectFuzzyMap[field.Code]; exists {
+				continue
+			}
+			if base.OnSelectFuzzyMap == nil {
+				base.OnSelectFuzzyMap = make(map[string]OnSelectFuzzy)
+			}
+			base.OnSelectFuzzyMap[field.Code] = field.onSelectFuzzy
+		}
+	}
+}
+
+// resolveReferenceTarget 获取关联组件的目标表（目标函数可能在其他 package，使用目标函数所在 package 的数据库）
+func resolveReferenceTarget(config *widget.Reference) (*referenceTarget, error) {
+	if app == nil {
+		return nil, fmt.Errorf("app 未初始化")
+	}
+	if config.Target == "" {
+		return nil, fmt.Errorf("关联组件没有设置 target")
+	}
+	info, err := app.getRoute(config.Target)
+	if err != nil {
+		return nil, fmt.Errorf("关联的函数 %s 不存在: %w", config.Target, err)
+	}
+	table, ok := asTableTemplate(info.Template)
+	if !ok || table.AutoCrudTable == nil {
+		return nil, fmt.Errorf("关联的函数 %s 不是表格函数", config.Target)
+	}
+
+	db, err := routeDB(info)
+	if err != nil {
+		return nil, err
+	}
+	target := &referenceTarget{router: config.Target, model: table.AutoCrudTable, db: db}
+	if target.value, err = modelColumn(db, table.AutoCrudTable, config.Value); err != nil {
+		return nil, err
+	}
+	if target.label, err = modelColumn(db, table.AutoCrudTable, config.Label); err != nil {
+		return nil, err
+	}
+	for _, field := range config.Search {
+		column, err := modelColumn(db, table.AutoCrudTable, field)
+		if err != nil {
+			return nil, err
+		}
+		target.search = append(target.search, column)
+	}
+	return target, nil
+}
+
+// routeDBName 函数所在 package 的数据库名称
+func routeDBName(info *routerInfo) string {
+	if info.Options != nil {
+		return info.Options.GetDBName(env.User, env.App)
+	}
+	return getDBName()
+}
+
+// routeDB 获取函数所在 package 的数据库
+func routeDB(info *routerInfo) (*gorm.DB, error) {
+	dbName := routeDBName(info)
+	db, err := getOrInitDB(dbName)
+	if err != nil {
+		return nil, fmt.Errorf("获取数据库 %s 失败: %w", dbName, err)
+	}
+	return db, nil
+}
+
+// modelColumn 获取模型字段（json 标签、列名或 Go 字段名）对应的列名
+func modelColumn(db *gorm.DB, model interface{}, field string) (string, error) {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(model); err != nil {
+		return "", fmt.Errorf("解析模型失败: %w", err)
+	}
+	if f := stmt.Schema.LookUpField(field); f != nil && f.DBName != "" {
+		return f.DBName, nil
+	}
+	for _, f := range stmt.Schema.Fields {
+		if f.DBName != "" && strings.Split(f.Tag.Get("json"), ",")[0] == field {
+			return f.DBName, nil
+		}
+	}
+	return "", fmt.Errorf("表 %s 没有字段 %s", stmt.Schema.Table, field)
+}
+
+// onSelectFuzzy 关联组件自动生成的 OnSelectFuzzy 回调：按关键词模糊搜索、按值（批量）回显显示的字段
+// 每个选项带有跳转到目标记录的链接（BuildFunctionUrl 生成）
+func (f *referenceField) onSelectFuzzy(ctx *Context, req *callback.OnSelectFuzzyReq) (*callback.OnSelectFuzzyResp, error) {
+	target, err := resolveReferenceTarget(f.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	columns := []string{target.value}
+	if target.label != target.value {
+		columns = append(columns, target.label)
+	}
+	tx := target.db.Model(target.model).Select(columns)
+	switch {
+	case req.IsByValue():
+		tx = tx.Where(query.SafeColumnName(target.value)+" = ?", req.GetValue())
+	case req.IsByValues():
+		tx = tx.Where(query.SafeColumnName(target.value)+" IN ?", req.GetValues())
+	default:
+		if keyword := strings.TrimSpace(req.Keyword()); req.Value != nil && keyword != "" {
+			pattern := "%" + strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(keyword) + "%"
+			conds := make([]string, len(target.search))
+			args := make([]interface{}, len(target.search))
+			for i, column := range target.search {
+				conds[i] = query.SafeColumnName(column) + ` LIKE ? ESCAPE '\'`
+				args[i] = pattern
+			}
+			tx = tx.Where("("+strings.Join(conds, " OR ")+")", args...)
+		}
+		tx = tx.Order(query.SafeColumnName(target.value)).Limit(referenceFuzzyLimit)
+	}
+
+	var rows []map[string]interface{}
+	if err := tx.Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("查询关联的记录失败: %w", err)
+	}
+
+	resp := &callback.OnSelectFuzzyResp{Items: make([]*callback.SelectFuzzyItem, 0, len(rows))}
+	for _, row := range rows {
+		value := row[target.value]
+		label := fmt.Sprintf("%v", row[target.label])
+		item := &callback.SelectFuzzyItem{Value: value, Label: label}
+
+		// 跳转到目标表格并筛选出该记录
+		params := url.Values{}
+		params.Set("eq", fmt.Sprintf("%s:%v", f.Config.Value, value))
+		item.Link, err = ctx.BuildFunctionUrlWithText(target.router+"?"+params.Encode(), nil, label)
+		if err != nil {
+			logger.Warnf(ctx, "构建关联记录链接失败: target=%s, err=%v", target.router, err)
+		}
+		resp.Items = append(resp.Items, item)
+	}
+	return resp, nil
+}
+
+// checkReferences 删除表格记录前检查是否被其他表格的关联组件引用（on_delete:restrict）
+// 被引用时返回错误，不允许删除
+func checkReferences(info *routerInfo, ids []int) error {
+	table, ok := asTableTemplate(info.Template)
+	if !ok || table.AutoCrudTable == nil || len(ids) == 0 || app == nil {
+		return nil
+	}
+
+	checked := make(map[string]bool)
+	for _, ref := range app.routerInfo {
+		refTable, ok := asTableTemplate(ref.Template)
+		if !ok || refTable.AutoCrudTable == nil {
+			continue
+		}
+		for _, field := range referenceFields(refTable.AutoCrudTable) {
+			if field.Nested || field.Config.OnDelete != widget.ReferenceOnDeleteRestrict ||
+				routerKey(field.Config.Target) != routerKey(info.Router) {
+				continue
+			}
+			refDB, err := routeDB(ref)
+			if err != nil {
+				return err
+			}
+			column, err := modelColumn(refDB, refTable.AutoCrudTable, field.FieldName)
+			if err != nil {
+				return err
+			}
+
+			// 多个函数可能使用同一个库中的同一张表，只检查一次
+			stmt := &gorm.Statement{DB: refDB}
+			if err := stmt.Parse(refTable.AutoCrudTable); err != nil {
+				return err
+			}
+			key := fmt.Sprintf("%s|%s|%s", routeDBName(ref), stmt.Schema.Table, column)
+			if checked[key] {
+				continue
+			}
+			checked[key] = true
+
+			target, err := resolveReferenceTarget(field.Config)
+			if err != nil {
+				return err
+			}
+			values, err := referencedValues(target, ids)
+			if err != nil {
+				return err
+			}
+			if len(values) == 0 {
+				continue
+			}
+
+			var count int64
+			err = refDB.Model(refTable.AutoCrudTable).Where(query.SafeColumnName(column)+" IN ?", values).Count(&count).Error
+			if err != nil {
+				return fmt.Errorf("检查关联记录失败: %w", err)
+			}
+			if count > 0 {
+				name := refTable.Name
+				if name == "" {
+					name = ref.Router
+				}
+				return fmt.Errorf("记录被「%s」中的 %d 条数据通过字段 %s 引用，不能删除", name, count, field.Code)
+			}
+		}
+	}
+	return nil
+}
+
+// referencedValues 要删除的记录（主键）中被关联组件保存的字段值
+func referencedValues(target *referenceTarget, ids []int) ([]interface{}, error) {
+	stmt := &gorm.Statement{DB: target.db}
+	if err := stmt.Parse(target.model); err != nil {
+		return nil, err
+	}
+	pk := stmt.Schema.PrioritizedPrimaryField
+	if pk == nil {
+		return nil, fmt.Errorf("表 %s 没有主键", stmt.Schema.Table)
+	}
+	if pk.DBName == target.value {
+		values := make([]interface{}, len(ids))
+		for i, id := range ids {
+			values[i] = id
+		}
+		return values, nil
+	}
+
+	var values []interface{}
+	err := target.db.Model(target.model).
+		Where(query.SafeColumnName(pk.DBName)+" IN ?", ids).
+		Pluck(target.value, &values).Error
+	if err != nil {
+		return nil, fmt.Errorf("查询要删除的记录失败: %w", err)
+	}
+	return values, nil
+}
@@ -236,12 +236,22 @@ func (a *App) CallbackRouter(ctx *Context, resp response.Response) error {
 
 	switch req.Type {
 	case CallbackTypeOnTableAddRow:
-		v, ok := router.Template.(*TableTemplate)
+		v, ok := asTableTemplate(router.Template)
 		if !ok {
 			return errors.New("invalid type of TableTemplate")
 		}
+		// 按 AutoCrudTable 的字段规则填充动态默认值、清空隐藏字段
+		if err := ctx.applyBodyFieldRules(v.AutoCrudTable); err != nil {
+			return err
+		}
 		var onTableReq callback.OnTableAddRowReq
-		onTableResp, err := v.OnTableAddRow(ctx, &onTableReq)
+		var onTableResp *callback.OnTableAddRowResp
+		var err error
+		if len(v.ChildTables) > 0 {
+			onTableResp, err = v.addRowWithChildren(ctx, &onTableReq)
+		} else {
+			onTableResp, err = v.OnTableAddRow(ctx, &onTableReq)
+		}
 		if err != nil {
 			logger.Errorf(ctx, "callback onTableAddRow router:%s call error:%s", req.Type, err.Error())
 			return err
@@ -254,7 +264,7 @@ func (a *App) CallbackRouter(ctx *Context, resp response.Response) error {
 		logger.Infof(ctx, "CallbackRouter onTableAddRow success")
 		return nil
 	case CallbackTypeOnTableUpdateRow:
-		v, ok := router.Template.(*TableTemplate)
+		v, ok := asTableTemplate(router.Template)
 		if !ok {
 			return errors.New("invalid type of TableTemplate")
 		}
@@ -265,13 +275,22 @@ func (a *App) CallbackRouter(ctx *Context, resp response.Response) error {
 		if err != nil {
 			return err
 		}
+		// 按 AutoCrudTable 的字段规则丢弃对只读字段的修改、隐藏字段只能清空
+		if err := v.applyUpdateFieldRules(ctx, onTableReq.GetId(), onTableReq.Updates); err != nil {
+			return err
+		}
 		if onTableReq.BindUpdatesMap == nil {
 			onTableReq.BindUpdatesMap = make(map[string]interface{})
 		}
 		for k, vv := range onTableReq.Updates {
 			onTableReq.BindUpdatesMap[k] = vv
 		}
-		onTableResp, err := v.OnTableUpdateRow(ctx, &onTableReq)
+		var onTableResp *callback.OnTableUpdateRowResp
+		if len(v.ChildTables) > 0 {
+			onTableResp, err = v.updateRowWithChildren(ctx, &onTableReq)
+		} else {
+			onTableResp, err = v.OnTableUpdateRow(ctx, &onTableReq)
+		}
 		if err != nil {
 			return err
 		}
@@ -283,7 +302,7 @@ func (a *App) CallbackRouter(ctx *Context, resp response.Response) error {
 		logger.Infof(ctx, "CallbackRouter OnTableUpdateRows success")
 		return nil
 	case CallbackTypeOnTableDeleteRows:
-		v, ok := router.Template.(*TableTemplate)
+		v, ok := asTableTemplate(router.Template)
 		if !ok {
 			return errors.New("invalid type of TableTemplate")
 		}
@@ -292,7 +311,16 @@ func (a *App) CallbackRouter(ctx *Context, resp response.Response) error {
 		if err != nil {
 			return err
 		}
-		onTableResp, err := v.OnTableDeleteRows(ctx, &onTableReq)
+		// 被其他表格的关联组件引用的记录不允许删除
+		if err := checkReferences(router, onTableReq.GetIds()); err != nil {
+			return err
+		}
+		var onTableResp *callback.OnTableDeleteRowsResp
+		if len(v.ChildTables) > 0 {
+			onTableResp, err = v.deleteRowsWithChildren(ctx, &onTableReq)
+		} else {
+			onTableResp, err = v.OnTableDeleteRows(ctx, &onTableReq)
+		}
 		if err != nil {
 			return err
 		}
@@ -305,7 +333,7 @@ func (a *App) CallbackRouter(ctx *Context, resp response.Response) error {
 		return nil
 	case CallbackTypeOnTableCreateInBatches:
 		// 系统内置批量创建回调，直接批量插入数据库，不触发用户侧的回调
-		v, ok := router.Template.(*TableTemplate)
+		v, ok := asTableTemplate(router.Template)
 		if !ok {
 			return errors.New("invalid type of TableTemplate")
 		}
@@ -330,6 +358,91 @@ func (a *App) CallbackRouter(ctx *Context, resp response.Response) error {
 		}
 		logger.Infof(ctx, "CallbackRouter OnTableCreateInBatches success: success=%d, fail=%d", batchResp.SuccessCount, batchResp.FailCount)
 		return nil
+	case CallbackTypeOnTableLoadChildren:
+		// 系统内置子表加载回调（主从表展开行时懒加载子表）
+		v, ok := asTableTemplate(router.Template)
+		if !ok {
+			return errors.New("invalid type of TableTemplate")
+		}
+		var loadReq callback.OnTableLoadChildrenReq
+		if err := json.Unmarshal(ctx.body, &loadReq); err != nil {
+			return fmt.Errorf("解析子表加载请求失败: %w", err)
+		}
+		loadResp, err := handleTableLoadChildren(ctx, v, &loadReq)
+		if err != nil {
+			logger.Errorf(ctx, "callback OnTableLoadChildren router:%s error:%s", req.Type, err.Error())
+			return err
+		}
+		err = resp.Form(loadResp).Build()
+		if err != nil {
+			logger.Errorf(ctx, "callback OnTableLoadChildren router:%s Build error:%s", req.Type, err.Error())
+			return err
+		}
+		logger.Infof(ctx, "CallbackRouter OnTableLoadChildren success")
+		return nil
+	case CallbackTypeOnKanbanMove:
+		v, ok := router.Template.(*KanbanTemplate)
+		if !ok {
+			return errors.New("invalid type of KanbanTemplate")
+		}
+		var moveReq callback.OnKanbanMoveReq
+		if err := json.Unmarshal(ctx.body, &moveReq); err != nil {
+			return err
+		}
+		moveResp, err := v.handleKanbanMove(ctx, &moveReq)
+		if err != nil {
+			logger.Errorf(ctx, "callback OnKanbanMove router:%s error:%s", req.Type, err.Error())
+			return err
+		}
+		err = resp.Form(moveResp).Build()
+		if err != nil {
+			logger.Errorf(ctx, "callback OnKanbanMove router:%s Build error:%s", req.Type, err.Error())
+			return err
+		}
+		logger.Infof(ctx, "CallbackRouter OnKanbanMove success")
+		return nil
+	case CallbackTypeOnCalendarRange:
+		v, ok := router.Template.(*CalendarTemplate)
+		if !ok {
+			return errors.New("invalid type of CalendarTemplate")
+		}
+		var rangeReq callback.OnCalendarRangeReq
+		if err := json.Unmarshal(ctx.body, &rangeReq); err != nil {
+			return fmt.Errorf("解析日历范围请求失败: %w", err)
+		}
+		rangeResp, err := v.handleCalendarRange(ctx, &rangeReq)
+		if err != nil {
+			logger.Errorf(ctx, "callback OnCalendarRange router:%s error:%s", req.Type, err.Error())
+			return err
+		}
+		err = resp.Form(rangeResp).Build()
+		if err != nil {
+			logger.Errorf(ctx, "callback OnCalendarRange router:%s Build error:%s", req.Type, err.Error())
+			return err
+		}
+		logger.Infof(ctx, "CallbackRouter OnCalendarRange success")
+		return nil
+	case CallbackTypeOnFormStepValidate:
+		v, ok := router.Template.(*FormTemplate)
+		if !ok || !v.isWizard() {
+			return errors.New("该函数不是向导表单")
+		}
+		var stepReq callback.OnFormStepValidateReq
+		if err := json.Unmarshal(ctx.body, &stepReq); err != nil {
+			return fmt.Errorf("解析步骤校验请求失败: %w", err)
+		}
+		stepResp, err := v.validateStep(ctx, &stepReq)
+		if err != nil {
+			logger.Errorf(ctx, "callback OnFormStepValidate router:%s step:%s error:%s", req.Type, stepReq.Step, err.Error())
+			return err
+		}
+		err = resp.Form(stepResp).Build()
+		if err != nil {
+			logger.Errorf(ctx, "callback OnFormStepValidate router:%s Build error:%s", req.Type, err.Error())
+			return err
+		}
+		logger.Infof(ctx, "CallbackRouter OnFormStepValidate success: step=%s, passed=%v", stepReq.Step, stepResp.Passed())
+		return nil
 	case CallbackTypeOnSelectFuzzy:
 		var onCallback callback.OnSelectFuzzyReq
 		base := router.Template.GetBaseConfig()
@@ -352,6 +465,32 @@ func (a *App) CallbackRouter(ctx *Context, resp response.Response) error {
 			return err
 		}
 		logger.Infof(ctx, "CallbackRouter OnSelectFuzzy success")
+	case CallbackTypeOnApprovalFinished:
+		var onFinished OnApprovalFinished
+		if v, ok := router.Template.(*FormTemplate); ok {
+			onFinished = v.OnApprovalFinished
+		} else if v, ok := asTableTemplate(router.Template); ok {
+			onFinished = v.OnApprovalFinished
+		}
+		if onFinished == nil {
+			return errors.New("OnApprovalFinished not implemented")
+		}
+		var onApprovalReq callback.OnApprovalFinishedReq
+		err := json.Unmarshal(ctx.body, &onApprovalReq)
+		if err != nil {
+			return err
+		}
+		onApprovalResp, err := onFinished(ctx, &onApprovalReq)
+		if err != nil {
+			logger.Errorf(ctx, "callback OnApprovalFinished router:%s call error:%s", req.Type, err.Error())
+			return err
+		}
+		err = resp.Form(onApprovalResp).Build()
+		if err != nil {
+			logger.Errorf(ctx, "callback OnApprovalFinished router:%s Build error:%s", req.Type, err.Error())
+			return err
+		}
+		logger.Infof(ctx, "CallbackRouter OnApprovalFinished success: instance=%d, status=%s", onApprovalReq.InstanceID, onApprovalReq.Status)
 	}
 	return nil
 
@@ -385,6 +524,19 @@ func handleTableCreateInBatches(ctx *Context, template *TableTemplate, req *call
 	// 创建切片实例
 	sliceValue := reflect.New(sliceType).Elem()
 	
+	// 按 AutoCrudTable 的字段规则处理每一行（填充动态默认值、清空隐藏字段、校验条件必填）
+	rules, err := getFieldRules(template.AutoCrudTable)
+	if err != nil {
+		return nil, err
+	}
+	if len(rules) > 0 {
+		for i, row := range req.Data {
+			if _, err := rules.applyCreate(ctx, row); err != nil {
+				return nil, fmt.Errorf("第 %d 行: %w", i+1, err)
+			}
+		}
+	}
+
 	// 将 JSON 数据反序列化到切片
 	jsonData, err := json.Marshal(req.Data)
 	if err != nil {
@@ -6,6 +6,9 @@ const (
 	TemplateTypeForm  TemplateType = "form"
 	TemplateTypeTable TemplateType = "table"
 	TemplateTypeChart TemplateType = "chart"
+
+	TemplateTypeKanban   TemplateType = "kanban"   // 看板：按 select 字段分列展示 AutoCrudTable 的记录
+	TemplateTypeCalendar TemplateType = "calendar" // 日历：按开始/结束时间字段展示 AutoCrudTable 的记录
 )
 
 type Templater interface {
@@ -28,6 +31,10 @@ type BaseConfig struct {
 
 	OnSelectFuzzyMap map[string]OnSelectFuzzy `json:"-"`
 	FunctionGroup    FunctionGroup            `json:"function_group"`
+
+	// Config 运行时配置（可选），传入带 widget 标签的结构体，字段值即为默认值
+	// 配置可以在工作空间中在线修改（带版本历史和回滚），handler 中通过 ctx.Config(&cfg) 读取
+	Config interface{} `json:"-"`
 }
 
 type FunctionGroup struct {
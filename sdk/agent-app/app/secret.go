@@ -0,0 +1,107 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ai-agent-os/ai-agent-os/dto"
+	appPkg "github.com/ai-agent-os/ai-agent-os/pkg/app"
+	"github.com/ai-agent-os/ai-agent-os/pkg/logger"
+	"github.com/ai-agent-os/ai-agent-os/pkg/msgx"
+	"github.com/ai-agent-os/ai-agent-os/pkg/subjects"
+	"github.com/ai-agent-os/ai-agent-os/sdk/agent-app/env"
+)
+
+var (
+	secretLock  sync.RWMutex
+	secretCache map[string]string // nil 表示未加载（或密钥变更后已失效）
+)
+
+// Secret 读取应用密钥
+// 密钥在工作空间的「应用密钥」中配置，加密存储；轮换后无需重新编译，下次读取即为新值
+// 优先从 app-server 获取最新值，获取失败时回退到容器启动时注入的 SECRET_{NAME} 环境变量
+//
+//	apiKey := ctx.Secret("OPENAI_API_KEY")
+func (c *Context) Secret(name string) string {
+	values := loadSecrets(c)
+	if value, ok := values[name]; ok {
+		return value
+	}
+	return os.Getenv(appPkg.SecretEnvPrefix + name)
+}
+
+// loadSecrets 加载密钥（带缓存）
+func loadSecrets(ctx context.Context) map[string]string {
+	secretLock.RLock()
+	if secretCache != nil {
+		defer secretLock.RUnlock()
+		return secretCache
+	}
+	secretLock.RUnlock()
+
+	secretLock.Lock()
+	defer secretLock.Unlock()
+	// 双重检查：可能其他协程已经加载
+	if secretCache != nil {
+		return secretCache
+	}
+
+	values, err := fetchSecrets(ctx)
+	if err != nil {
+		logger.Warnf(ctx, "[Secret] 从 app-server 获取密钥失败，使用环境变量: %v", err)
+		values = getEnvSecrets()
+	}
+	for _, value := range values {
+		logger.RegisterSecret(value)
+	}
+	secretCache = values
+	return secretCache
+}
+
+// fetchSecrets 通过 NATS 向 app-server 获取应用密钥
+func fetchSecrets(ctx context.Context) (map[string]string, error) {
+	if app == nil || app.conn == nil {
+		return nil, fmt.Errorf("app not initialized")
+	}
+	var resp dto.GetAppSecretsRuntimeResp
+	req := &dto.GetAppSecretsRuntimeReq{User: env.User, App: env.App, Token: os.Getenv(appPkg.SecretTokenEnv)}
+	if _, err := msgx.RequestMsgWithTimeout(ctx, app.conn, subjects.GetAppServerSecretRequestSubject(), req, &resp, 5*time.Second); err != nil {
+		return nil, err
+	}
+	if resp.Secrets == nil {
+		resp.Secrets = make(map[string]string)
+	}
+	return resp.Secrets, nil
+}
+
+// getEnvSecrets 读取容器启动时注入的 SECRET_{NAME} 环境变量
+func getEnvSecrets() map[string]string {
+	values := make(map[string]string)
+	for _, kv := range os.Environ() {
+		key, value, ok := strings.Cut(kv, "=")
+		if !ok || !strings.HasPrefix(key, appPkg.SecretEnvPrefix) {
+			continue
+		}
+		values[strings.TrimPrefix(key, appPkg.SecretEnvPrefix)] = value
+	}
+	return values
+}
+
+// registerEnvSecrets 启动时注册环境变量中的密钥，确保未调用 ctx.Secret 之前日志中也不会出现明文
+func registerEnvSecrets() {
+	for _, value := range getEnvSecrets() {
+		logger.RegisterSecret(value)
+	}
+}
+
+// invalidateSecrets 密钥变更后清空缓存，下次读取时重新获取
+func (a *App) invalidateSecrets() {
+	secretLock.Lock()
+	secretCache = nil
+	secretLock.Unlock()
+	logger.Infof(a, "[Secret] 应用密钥已变更，缓存已清空")
+}
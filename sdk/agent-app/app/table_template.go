@@ -1,7 +1,14 @@
 package app
 
 import (
+	"fmt"
+	"reflect"
+
+	"github.com/ai-agent-os/ai-agent-os/pkg/gormx/query"
 	"github.com/ai-agent-os/ai-agent-os/sdk/agent-app/callback"
+	"github.com/ai-agent-os/ai-agent-os/sdk/agent-app/response"
+	"github.com/ai-agent-os/ai-agent-os/sdk/agent-app/widget"
+	"gorm.io/gorm/schema"
 )
 
 type TableTemplate struct {
@@ -13,6 +20,10 @@ type TableTemplate struct {
 	// OnTableCreateInBatches 是系统内置的回调，不需要用户实现
 	// 系统会自动通过反射获取 AutoCrudTable 结构，批量插入数据库
 	OnTableCreateInBatches func(ctx *Context, req *callback.OnTableCreateInBatchesReq) (*callback.OnTableCreateInBatchesResp, error) `json:"-"`
+	OnApprovalFinished     OnApprovalFinished
+
+	// ChildTables 主从表的子表（可选），增删改在同一个事务中同步子表，展开行时懒加载子表，见 ChildTable
+	ChildTables []*ChildTable `json:"-"`
 }
 
 func (t *TableTemplate) GetBaseConfig() *BaseConfig {
@@ -22,3 +33,72 @@ func (t *TableTemplate) GetBaseConfig() *BaseConfig {
 func (t *TableTemplate) TemplateType() TemplateType {
 	return TemplateTypeTable
 }
+
+// getTableTemplate 看板、日历等模板内嵌 TableTemplate，共用 AutoCrudTable 和增删改回调
+func (t *TableTemplate) getTableTemplate() *TableTemplate {
+	return t
+}
+
+// asTableTemplate 获取基于表格的模板（表格、看板、日历）中的 TableTemplate
+func asTableTemplate(templater Templater) (*TableTemplate, bool) {
+	if t, ok := templater.(interface{ getTableTemplate() *TableTemplate }); ok {
+		return t.getTableTemplate(), true
+	}
+	return nil, false
+}
+
+// newModelSlice 创建模型的切片指针（*[]*Model），用于查询结果
+func newModelSlice(model interface{}) interface{} {
+	modelType := reflect.TypeOf(model)
+	if modelType.Kind() == reflect.Ptr {
+		modelType = modelType.Elem()
+	}
+	return reflect.New(reflect.SliceOf(reflect.PtrTo(modelType))).Interface()
+}
+
+// modelFieldTags 获取 AutoCrudTable 中指定字段（json 标签）的标签信息
+func (t *TableTemplate) modelFieldTags(code string) (*widget.FieldTags, error) {
+	if t.AutoCrudTable == nil {
+		return nil, fmt.Errorf("没有设置 AutoCrudTable")
+	}
+	parsed, err := widget.ParseModelWithType(t.AutoCrudTable)
+	if err != nil {
+		return nil, fmt.Errorf("解析 AutoCrudTable 失败: %w", err)
+	}
+	for _, tag := range parsed.Tags {
+		if tag.GetCode() == code {
+			return tag, nil
+		}
+	}
+	return nil, fmt.Errorf("AutoCrudTable 中没有字段 %s", code)
+}
+
+// modelColumn 获取 AutoCrudTable 中指定字段（json 标签、列名或 Go 字段名）对应的数据库列名
+func (t *TableTemplate) modelColumn(field string) (string, error) {
+	s, err := schema.Parse(t.AutoCrudTable, modelSchemaCache, schema.NamingStrategy{})
+	if err != nil {
+		return "", fmt.Errorf("解析 AutoCrudTable 失败: %w", err)
+	}
+	f := lookupSchemaField(s, field)
+	if f == nil {
+		return "", fmt.Errorf("AutoCrudTable 中没有数据库字段 %s", field)
+	}
+	return f.DBName, nil
+}
+
+// autoCrudList 按请求中的搜索参数分页查询 AutoCrudTable（看板、日历没有手写处理函数时使用）
+// prepare 可以在查询前调整默认的分页、排序和聚合参数
+func (t *TableTemplate) autoCrudList(ctx *Context, resp response.Response, prepare func(pageInfo *query.SearchFilterPageReq)) error {
+	pageInfo := new(query.SearchFilterPageReq)
+	if err := ctx.ShouldBind(pageInfo); err != nil {
+		return err
+	}
+	db := ctx.GetGormDB()
+	if db == nil {
+		return fmt.Errorf("获取数据库连接失败")
+	}
+	if prepare != nil {
+		prepare(pageInfo)
+	}
+	return resp.Table(newModelSlice(t.AutoCrudTable)).AutoSearchFilterPaged(db.Model(t.AutoCrudTable), t.AutoCrudTable, pageInfo).Build()
+}
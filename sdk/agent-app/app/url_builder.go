@@ -82,8 +82,8 @@ func (ctx *Context) BuildFunctionUrlWithText(
 		} else {
 			// 根据模板类型决定参数格式
 			switch template.TemplateType() {
-			case TemplateTypeTable:
-				// Table 函数：根据 search 标签转换为 search 格式
+			case TemplateTypeTable, TemplateTypeKanban, TemplateTypeCalendar:
+				// Table 函数（以及看板、日历）：根据 search 标签转换为 search 格式
 				// 使用 AutoCrudTable 的 Model（包含 search 标签）
 				newQueryString, err = query.StructToTableParams(params)
 				if err != nil {
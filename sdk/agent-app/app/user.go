@@ -1,5 +1,32 @@
 package app
 
+import (
+	"fmt"
+
+	"github.com/ai-agent-os/ai-agent-os/pkg/apicall"
+)
+
 func (c *Context) GetRequestUser() string {
 	return c.msg.RequestUser
 }
+
+// GetRequestUserDepartment 获取当前用户所在部门的完整路径（如 /tech/backend），同一个请求内只查询一次
+func (c *Context) GetRequestUserDepartment() (string, error) {
+	if c.department != nil {
+		return *c.department, nil
+	}
+	if c.msg.RequestUser == "" {
+		return "", nil
+	}
+	header := &apicall.Header{
+		TraceID:     c.msg.TraceId,
+		RequestUser: c.msg.RequestUser,
+		Token:       c.token,
+	}
+	user, err := apicall.GetUserByUsername(header, c.msg.RequestUser)
+	if err != nil {
+		return "", fmt.Errorf("获取用户 %s 的部门失败: %w", c.msg.RequestUser, err)
+	}
+	c.department = &user.DepartmentFullPath
+	return user.DepartmentFullPath, nil
+}
@@ -1,6 +1,7 @@
 package callback
 
 import (
+	"encoding/json"
 	"fmt"
 	"github.com/ai-agent-os/ai-agent-os/pkg/jsonx"
 	"github.com/ai-agent-os/ai-agent-os/sdk/agent-app/widget"
@@ -82,6 +83,7 @@ type SelectFuzzyItem struct {
 	Label       string                 `json:"label"`
 	Icon        string                 `json:"icon"`
 	DisplayInfo map[string]interface{} `json:"display_info"`
+	Link        string                 `json:"link,omitempty"` // 跳转链接（Link 组件的值格式），关联组件用于跳转到关联的记录
 }
 
 type OnSelectFuzzyResp struct {
@@ -94,3 +96,69 @@ type OnSelectFuzzyResp struct {
 
 	ErrorMsg string `json:"error_msg"`
 }
+
+// OnApprovalFinishedReq 审批结束回调请求（通过、拒绝、撤回都会触发）
+type OnApprovalFinishedReq struct {
+	InstanceID  int64                  `json:"instance_id"`
+	ProcessCode string                 `json:"process_code"`
+	Title       string                 `json:"title"`
+	RowID       int64                  `json:"row_id"`    // 发起审批时关联的记录ID
+	Status      string                 `json:"status"`    // approved/rejected/canceled
+	Initiator   string                 `json:"initiator"` // 发起人
+	Operator    string                 `json:"operator"`  // 最后一个操作人
+	Comment     string                 `json:"comment"`   // 最后一个操作的审批意见
+	Data        map[string]interface{} `json:"data"`      // 发起审批时传入的业务数据
+}
+
+// IsApproved 审批是否通过
+func (r *OnApprovalFinishedReq) IsApproved() bool {
+	return r.Status == "approved"
+}
+
+type OnApprovalFinishedResp struct {
+}
+
+// OnFormStepValidateReq 向导表单进入下一步前的服务端校验请求
+// Body 是当前步骤及之前步骤填写的数据（与最终提交的格式相同），可以用 Bind 绑定到请求结构体
+type OnFormStepValidateReq struct {
+	Step      string          `json:"step"` // 当前步骤名称（step 标签）
+	StepIndex int             `json:"-"`    // 当前步骤序号（从 0 开始），由 SDK 填充
+	Body      json.RawMessage `json:"body"`
+}
+
+// Bind 把已填写的数据绑定到请求结构体
+func (r *OnFormStepValidateReq) Bind(v interface{}) error {
+	if len(r.Body) == 0 {
+		return nil
+	}
+	return json.Unmarshal(r.Body, v)
+}
+
+// OnFormStepValidateResp 向导表单步骤校验结果
+// Errors 不为空时不能进入下一步；Prefill 用来预填后续步骤的字段
+type OnFormStepValidateResp struct {
+	Errors   map[string]string      `json:"errors,omitempty"`    // 字段 code -> 错误信息
+	Prefill  map[string]interface{} `json:"prefill,omitempty"`   // 后续步骤的字段 code -> 预填的值
+	NextStep string                 `json:"next_step,omitempty"` // 校验通过时下一步的名称，最后一步为空（由 SDK 填充）
+}
+
+// AddError 添加字段校验错误
+func (r *OnFormStepValidateResp) AddError(field string, msg string) {
+	if r.Errors == nil {
+		r.Errors = make(map[string]string)
+	}
+	r.Errors[field] = msg
+}
+
+// SetPrefill 预填后续步骤的字段
+func (r *OnFormStepValidateResp) SetPrefill(field string, value interface{}) {
+	if r.Prefill == nil {
+		r.Prefill = make(map[string]interface{})
+	}
+	r.Prefill[field] = value
+}
+
+// Passed 校验是否通过
+func (r *OnFormStepValidateResp) Passed() bool {
+	return len(r.Errors) == 0
+}
@@ -4,7 +4,9 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"github.com/ai-agent-os/ai-agent-os/pkg/gormx/query"
 	"github.com/ai-agent-os/ai-agent-os/pkg/logger"
+	"github.com/ai-agent-os/ai-agent-os/sdk/agent-app/response"
 	"github.com/ai-agent-os/ai-agent-os/sdk/agent-app/widget"
 )
 
@@ -168,3 +170,52 @@ type OnTableCreateBatchError struct {
 	Index int    `json:"index"` // 数据索引（从0开始）
 	Error string `json:"error"` // 错误信息
 }
+
+// OnTableLoadChildrenReq 主从表展开主表行时加载子表记录
+// 搜索、排序、分页和聚合统计参数与表格查询相同
+type OnTableLoadChildrenReq struct {
+	Code     string      `json:"code"`      // 子表 code（主表模型中子表字段的 json 标签）
+	ParentID interface{} `json:"parent_id"` // 主表记录的主键
+	query.SearchFilterPageReq
+}
+
+// OnTableLoadChildrenResp 子表记录（items/paginated/aggregates 同表格查询）和统计表达式
+type OnTableLoadChildrenResp struct {
+	*response.TableData
+	Statistics map[string]interface{} `json:"statistics,omitempty"`
+}
+
+// OnKanbanMoveReq 看板拖动卡片，格式与表格更新相同：{"id": 1, "updates": {"status": "进行中"}, "old_values": {"status": "待处理"}, "index": 0}
+// updates 中只能包含看板的分列字段
+type OnKanbanMoveReq struct {
+	ID        int                    `json:"id"`
+	Updates   map[string]interface{} `json:"updates"`
+	OldValues map[string]interface{} `json:"old_values"`
+	Index     *int                   `json:"index,omitempty"` // 卡片在目标列中的位置（从 0 开始），为空时放在列尾
+
+	// 以下字段由 SDK 根据看板配置填充
+	Field string      `json:"-"` // 分列字段
+	From  interface{} `json:"-"` // 原来所在的列
+	To    interface{} `json:"-"` // 目标列
+}
+
+func (c *OnKanbanMoveReq) GetId() int {
+	return c.ID
+}
+
+type OnKanbanMoveResp struct {
+}
+
+// OnCalendarRangeReq 日历按时间范围加载日程，时间为毫秒时间戳，范围为 [Start, End)
+// 搜索参数与表格查询相同（分页参数不生效）
+type OnCalendarRangeReq struct {
+	Start int64 `json:"start"`
+	End   int64 `json:"end"`
+	query.SearchFilterPageReq
+}
+
+// OnCalendarRangeResp 时间范围内的日程，超出单次返回上限时 truncated 为 true
+type OnCalendarRangeResp struct {
+	Items     interface{} `json:"items"`
+	Truncated bool        `json:"truncated,omitempty"`
+}
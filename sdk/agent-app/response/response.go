@@ -92,6 +92,45 @@ func (t *RunFunctionResp) executeAutoSearchFilterPaged() error {
 		return t.err
 	}
 
+	// 关键词全文检索（模型中 search:"fulltext" 的字段）
+	dbWithConditions, keyword, err := query.ApplyKeyword(dbWithConditions, t.autoPagedModel, t.autoPagedPageInfo)
+	if err != nil {
+		t.err = fmt.Errorf("AutoPaginated.ApplyKeyword failed: %v", err)
+		return t.err
+	}
+
+	// 聚合统计：按整个搜索结果统计（表格底部合计等），不受分页影响
+	if t.autoPagedPageInfo.HasAggregate() {
+		aggregates, err := query.Aggregate(t.autoPagedDB, t.autoPagedModel, t.autoPagedPageInfo, nil)
+		if err != nil {
+			t.err = fmt.Errorf("AutoPaginated.Aggregate failed: %v", err)
+			return t.err
+		}
+		t.TableData.Aggregates = aggregates
+	}
+
+	// 游标分页：不做 COUNT(*) 和 OFFSET
+	if t.autoPagedPageInfo.IsCursorMode() {
+		cursorResult, err := query.PaginateCursor(dbWithConditions, t.autoPagedModel, t.TableData.Items, t.autoPagedPageInfo)
+		if err != nil {
+			t.err = fmt.Errorf("AutoPaginated.PaginateCursor :%+v failed to find records: %v", t.TableData.Items, err)
+			return t.err
+		}
+		if t.TableData.Highlights, err = keyword.Highlights(t.autoPagedDB, t.TableData.Items); err != nil {
+			t.err = fmt.Errorf("AutoPaginated.Highlights failed: %v", err)
+			return t.err
+		}
+		t.TableData.Paginated = &Paginated{
+			TotalCount:  int(cursorResult.TotalCount),
+			TotalPages:  -1,
+			PageSize:    cursorResult.PageSize,
+			NextCursor:  cursorResult.NextCursor,
+			PrevCursor:  cursorResult.PrevCursor,
+			TotalApprox: cursorResult.TotalApprox,
+		}
+		return nil
+	}
+
 	// 获取分页大小
 	pageSize := t.autoPagedPageInfo.GetLimit()
 	offset := t.autoPagedPageInfo.GetOffset()
@@ -103,9 +142,11 @@ func (t *RunFunctionResp) executeAutoSearchFilterPaged() error {
 		return t.err
 	}
 
-	// 应用排序
+	// 应用排序，没有指定排序时按关键词相关度排序
 	if t.autoPagedPageInfo.GetSorts() != "" {
 		dbWithConditions = dbWithConditions.Order(t.autoPagedPageInfo.GetSorts())
+	} else if rankOrder := keyword.RankOrder(); rankOrder != "" {
+		dbWithConditions = dbWithConditions.Order(rankOrder)
 	}
 
 	// 查询当前页数据
@@ -115,6 +156,10 @@ func (t *RunFunctionResp) executeAutoSearchFilterPaged() error {
 		t.err = fmt.Errorf("AutoPaginated.Find :%+v failed to find records: %v", t.TableData.Items, err)
 		return t.err
 	}
+	if t.TableData.Highlights, err = keyword.Highlights(t.autoPagedDB, t.TableData.Items); err != nil {
+		t.err = fmt.Errorf("AutoPaginated.Highlights failed: %v", err)
+		return t.err
+	}
 
 	// 计算总页数
 	totalPages := int(totalCount) / pageSize
@@ -136,6 +181,10 @@ func (t *RunFunctionResp) executeAutoSearchFilterPaged() error {
 type TableData struct {
 	Items     interface{} `json:"items"`
 	Paginated *Paginated  `json:"paginated"`
+	// 请求了聚合统计（aggs/group_by）时返回
+	Aggregates *query.AggregateResult `json:"aggregates,omitempty"`
+	// 关键词全文检索命中的片段：主键 -> 字段 -> 片段（关键词用 <mark></mark> 标记）
+	Highlights map[string]map[string]string `json:"highlights,omitempty"`
 }
 type FormData struct {
 	Data interface{} `json:"data"`
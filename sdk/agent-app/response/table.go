@@ -16,6 +16,11 @@ type Paginated struct {
 	TotalCount  int `json:"total_count"`  // 总数据量
 	TotalPages  int `json:"total_pages"`  // 总页数
 	PageSize    int `json:"page_size"`    // 每页数量
+
+	// 游标分页（page_mode=cursor）时返回，此时 TotalPages 为 -1，未统计总数时 TotalCount 为 -1
+	NextCursor  string `json:"next_cursor,omitempty"`  // 下一页游标，为空表示没有下一页
+	PrevCursor  string `json:"prev_cursor,omitempty"`  // 上一页游标，为空表示没有上一页
+	TotalApprox bool   `json:"total_approx,omitempty"` // 总数是否为近似值
 }
 
 //type table struct {
@@ -0,0 +1,400 @@
+package widget
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Condition 字段条件表达式，用于 widget 标签中的 show_if、required_if、readonly_if，
+// 前端和 SDK 使用相同的语法求值，表达式中的字段使用 json 标签（code）：
+//
+//	show_if:status == 已拒绝              等于（值包含空格或特殊字符时用单/双引号包裹）
+//	show_if:status != 草稿                不等于
+//	required_if:amount > 1000             数值比较：> >= < <=
+//	readonly_if:type in (vip,svip)        属于其中一个
+//	show_if:need_invoice                  值不为空（false、0、空字符串、空数组视为空）
+//	show_if:!need_invoice                 值为空
+//	show_if:type == vip && amount >= 100  且、或、括号：&& || ()
+//
+// 多选字段（数组）使用 == 和 in 时，包含任意一个值即为真；!= 时不包含该值为真
+// 注意：widget 标签使用分号分隔配置项，表达式中不能包含分号
+type Condition struct {
+	Expr   string
+	root   conditionNode
+	fields []string
+}
+
+// ParseCondition 解析条件表达式
+func ParseCondition(expr string) (*Condition, error) {
+	tokens, err := tokenizeCondition(expr)
+	if err != nil {
+		return nil, fmt.Errorf("条件表达式 %q 格式错误: %w", expr, err)
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("条件表达式不能为空")
+	}
+	p := &conditionParser{tokens: tokens}
+	root, err := p.parseOr()
+	if err == nil && p.pos < len(p.tokens) {
+		err = fmt.Errorf("多余的 %q", p.tokens[p.pos].text)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("条件表达式 %q 格式错误: %w", expr, err)
+	}
+	return &Condition{Expr: expr, root: root, fields: p.fields}, nil
+}
+
+// Fields 表达式引用的字段（按出现顺序，不重复）
+func (c *Condition) Fields() []string {
+	return c.fields
+}
+
+// Eval 根据表单的值（json code -> 值）求值，不存在的字段视为空
+func (c *Condition) Eval(values map[string]interface{}) bool {
+	return c.root.eval(values)
+}
+
+type conditionNode interface {
+	eval(values map[string]interface{}) bool
+}
+
+type conditionAnd struct{ left, right conditionNode }
+
+func (n *conditionAnd) eval(values map[string]interface{}) bool {
+	return n.left.eval(values) && n.right.eval(values)
+}
+
+type conditionOr struct{ left, right conditionNode }
+
+func (n *conditionOr) eval(values map[string]interface{}) bool {
+	return n.left.eval(values) || n.right.eval(values)
+}
+
+type conditionNot struct{ node conditionNode }
+
+func (n *conditionNot) eval(values map[string]interface{}) bool {
+	return !n.node.eval(values)
+}
+
+// conditionCompare 字段比较，op 为空时判断字段不为空
+type conditionCompare struct {
+	field  string
+	op     string
+	values []string
+}
+
+func (n *conditionCompare) eval(values map[string]interface{}) bool {
+	actual := values[n.field]
+	switch n.op {
+	case "":
+		return !IsEmptyValue(actual)
+	case "==", "in":
+		return matchAny(actual, n.values)
+	case "!=":
+		return !matchAny(actual, n.values)
+	}
+
+	a, ok := toFloat(actual)
+	if !ok {
+		return false
+	}
+	b, err := strconv.ParseFloat(n.values[0], 64)
+	if err != nil {
+		return false
+	}
+	switch n.op {
+	case ">":
+		return a > b
+	case ">=":
+		return a >= b
+	case "<":
+		return a < b
+	case "<=":
+		return a <= b
+	}
+	return false
+}
+
+// matchAny 值（数组时为任意元素）等于 expected 中的任意一个
+func matchAny(actual interface{}, expected []string) bool {
+	if items, ok := actual.([]interface{}); ok {
+		for _, item := range items {
+			if matchAny(item, expected) {
+				return true
+			}
+		}
+		return false
+	}
+	s := valueString(actual)
+	for _, e := range expected {
+		if s == e {
+			return true
+		}
+		// 数值按大小比较，如 1 和 1.0
+		if a, ok := toFloat(actual); ok {
+			if b, err := strconv.ParseFloat(e, 64); err == nil && a == b {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// valueString 值转换为字符串（nil 为空字符串）
+func valueString(v interface{}) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	case json.Number:
+		return val.String()
+	}
+	return fmt.Sprint(v)
+}
+
+// toFloat 数值或数值字符串转换为 float64
+func toFloat(v interface{}) (float64, bool) {
+	switch val := v.(type) {
+	case nil, bool:
+		return 0, false
+	case float64:
+		return val, true
+	case json.Number:
+		f, err := val.Float64()
+		return f, err == nil
+	}
+	f, err := strconv.ParseFloat(valueString(v), 64)
+	return f, err == nil
+}
+
+// IsEmptyValue 判断表单值是否为空：nil、false、0、空字符串、空数组、空对象
+func IsEmptyValue(v interface{}) bool {
+	switch val := v.(type) {
+	case nil:
+		return true
+	case string:
+		return val == ""
+	case bool:
+		return !val
+	case float64:
+		return val == 0
+	case json.Number:
+		f, err := val.Float64()
+		return err == nil && f == 0
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Map, reflect.Array:
+		return rv.Len() == 0
+	}
+	return rv.IsZero()
+}
+
+type conditionToken struct {
+	text   string
+	quoted bool // 引号包裹的值，不作为运算符
+}
+
+// tokenizeCondition 拆分表达式：运算符、括号、逗号、值（可以用引号包裹）
+func tokenizeCondition(expr string) ([]conditionToken, error) {
+	var tokens []conditionToken
+	runes := []rune(expr)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case r == ' ' || r == '\t':
+			i++
+		case r == '\'' || r == '"':
+			end := i + 1
+			for end < len(runes) && runes[end] != r {
+				end++
+			}
+			if end >= len(runes) {
+				return nil, fmt.Errorf("引号没有闭合")
+			}
+			tokens = append(tokens, conditionToken{text: string(runes[i+1 : end]), quoted: true})
+			i = end + 1
+		case i+1 < len(runes) && isTwoCharOp(string(runes[i:i+2])):
+			tokens = append(tokens, conditionToken{text: string(runes[i : i+2])})
+			i += 2
+		case strings.ContainsRune("()!<>,", r):
+			tokens = append(tokens, conditionToken{text: string(r)})
+			i++
+		case r == '=' || r == '&' || r == '|':
+			return nil, fmt.Errorf("不支持的运算符 %q，请使用 ==、&&、||", string(r))
+		default:
+			end := i
+			for end < len(runes) && !strings.ContainsRune(" \t'\"()!<>,=&|", runes[end]) {
+				end++
+			}
+			tokens = append(tokens, conditionToken{text: string(runes[i:end])})
+			i = end
+		}
+	}
+	return tokens, nil
+}
+
+func isTwoCharOp(s string) bool {
+	switch s {
+	case "==", "!=", ">=", "<=", "&&", "||":
+		return true
+	}
+	return false
+}
+
+// conditionParser 递归下降解析：or -> and -> unary -> primary
+type conditionParser struct {
+	tokens []conditionToken
+	pos    int
+	fields []string
+}
+
+func (p *conditionParser) peek() (conditionToken, bool) {
+	if p.pos >= len(p.tokens) {
+		return conditionToken{}, false
+	}
+	return p.tokens[p.pos], true
+}
+
+// peekOp 下一个 token 是否为指定的运算符（引号包裹的值不是运算符）
+func (p *conditionParser) peekOp(op string) bool {
+	t, ok := p.peek()
+	return ok && !t.quoted && t.text == op
+}
+
+func (p *conditionParser) parseOr() (conditionNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peekOp("||") {
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &conditionOr{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *conditionParser) parseAnd() (conditionNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peekOp("&&") {
+		p.pos++
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &conditionAnd{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *conditionParser) parseUnary() (conditionNode, error) {
+	if p.peekOp("!") {
+		p.pos++
+		node, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &conditionNot{node: node}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *conditionParser) parsePrimary() (conditionNode, error) {
+	if p.peekOp("(") {
+		p.pos++
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.peekOp(")") {
+			return nil, fmt.Errorf("缺少 )")
+		}
+		p.pos++
+		return node, nil
+	}
+
+	field, err := p.parseValue("字段")
+	if err != nil {
+		return nil, err
+	}
+	p.addField(field)
+	node := &conditionCompare{field: field}
+
+	t, ok := p.peek()
+	if !ok || t.quoted {
+		return node, nil
+	}
+	switch t.text {
+	case "==", "!=", ">", ">=", "<", "<=":
+		p.pos++
+		value, err := p.parseValue("值")
+		if err != nil {
+			return nil, err
+		}
+		node.op, node.values = t.text, []string{value}
+		if node.op != "==" && node.op != "!=" {
+			if _, err := strconv.ParseFloat(value, 64); err != nil {
+				return nil, fmt.Errorf("%s 只能和数值比较", node.op)
+			}
+		}
+	case "in":
+		p.pos++
+		if !p.peekOp("(") {
+			return nil, fmt.Errorf("in 后面需要 (")
+		}
+		p.pos++
+		node.op = "in"
+		for {
+			value, err := p.parseValue("值")
+			if err != nil {
+				return nil, err
+			}
+			node.values = append(node.values, value)
+			if p.peekOp(",") {
+				p.pos++
+				continue
+			}
+			if p.peekOp(")") {
+				p.pos++
+				break
+			}
+			return nil, fmt.Errorf("in 的值列表缺少 )")
+		}
+	}
+	return node, nil
+}
+
+// parseValue 读取一个字段名或值
+func (p *conditionParser) parseValue(name string) (string, error) {
+	t, ok := p.peek()
+	if !ok {
+		return "", fmt.Errorf("缺少%s", name)
+	}
+	if !t.quoted && strings.ContainsAny(t.text, "()!<>,=&|") {
+		return "", fmt.Errorf("缺少%s，遇到 %q", name, t.text)
+	}
+	p.pos++
+	return t.text, nil
+}
+
+func (p *conditionParser) addField(field string) {
+	for _, f := range p.fields {
+		if f == field {
+			return
+		}
+	}
+	p.fields = append(p.fields, field)
+}
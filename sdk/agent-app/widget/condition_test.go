@@ -0,0 +1,147 @@
+package widget
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+// 测试条件表达式的解析和求值
+func TestConditionEval(t *testing.T) {
+	values := map[string]interface{}{
+		"status":       "已拒绝",
+		"amount":       float64(1500),
+		"count":        json.Number("3"),
+		"need_invoice": true,
+		"remark":       "",
+		"tags":         []interface{}{"vip", "new"},
+		"title":        "a b",
+	}
+	cases := []struct {
+		expr string
+		want bool
+	}{
+		{"status == 已拒绝", true},
+		{"status != 已拒绝", false},
+		{"amount > 1000", true},
+		{"amount <= 1000", false},
+		{"count >= 3", true},
+		{"count == 3.0", true},
+		{"status in (已通过,已拒绝)", true},
+		{"need_invoice", true},
+		{"!need_invoice", false},
+		{"remark", false},
+		{"missing", false},
+		{"missing == ''", true},
+		{"tags == vip", true},
+		{"tags != vip", false},
+		{"tags in (old,new)", true},
+		{"title == 'a b'", true},
+		{`status == "已拒绝" && amount < 100`, false},
+		{"status == 已通过 || amount > 100", true},
+		{"!(status == 已通过 || remark) && need_invoice", true},
+	}
+	for _, c := range cases {
+		cond, err := ParseCondition(c.expr)
+		if err != nil {
+			t.Errorf("解析 %q 失败: %v", c.expr, err)
+			continue
+		}
+		if got := cond.Eval(values); got != c.want {
+			t.Errorf("%q 求值应为 %v，实际: %v", c.expr, c.want, got)
+		}
+	}
+
+	cond, _ := ParseCondition("type == vip && (amount > 1 || type in (a,b))")
+	if fields := cond.Fields(); len(fields) != 2 || fields[0] != "type" || fields[1] != "amount" {
+		t.Errorf("引用的字段应为 [type amount]，实际: %v", fields)
+	}
+}
+
+func TestConditionParseError(t *testing.T) {
+	for _, expr := range []string{"", "status = 1", "status ==", "amount > abc", "(status", "status == 1 )", "type in a,b", "a & b", "title == 'x"} {
+		if _, err := ParseCondition(expr); err == nil {
+			t.Errorf("%q 应该解析失败", expr)
+		}
+	}
+}
+
+// 测试 show_if、required_if、readonly_if 输出到字段
+func TestConditionTags(t *testing.T) {
+	type conditionTestStruct struct {
+		Type   string `json:"type" widget:"name:类型;type:select;options:个人,企业"`
+		TaxNo  string `json:"tax_no" widget:"name:税号;type:input;show_if:type == 企业;required_if:type == 企业"`
+		Status string `json:"status" widget:"name:状态;type:input;readonly_if:type != 个人"`
+	}
+	result, err := ParseModelWithType(&conditionTestStruct{})
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+	taxNo := ConvertTagsToField(result.Tags[1])
+	if taxNo.ShowIf != "type == 企业" || taxNo.RequiredIf != "type == 企业" {
+		t.Errorf("tax_no 条件错误: show_if=%q required_if=%q", taxNo.ShowIf, taxNo.RequiredIf)
+	}
+	status := ConvertTagsToField(result.Tags[2])
+	if status.ReadonlyIf != "type != 个人" {
+		t.Errorf("status readonly_if 错误: %q", status.ReadonlyIf)
+	}
+}
+
+func TestDynamicDefault(t *testing.T) {
+	now := time.Date(2024, 3, 14, 15, 30, 0, 0, time.Local) // 周四
+	vars := DefaultVars{
+		Now:  now,
+		User: "beiluo",
+		Dept: func() (string, error) { return "/tech/backend", nil },
+	}
+	day := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 0, 0, 0, 0, time.Local) }
+	cases := []struct {
+		expr string
+		want interface{}
+	}{
+		{"$user", "beiluo"},
+		{"$me", "beiluo"},
+		{"$dept", "/tech/backend"},
+		{"$now", now},
+		{"$today", day(2024, 3, 14)},
+		{"$tomorrow", day(2024, 3, 15)},
+		{"$yesterday", day(2024, 3, 13)},
+		{"$today+7d", day(2024, 3, 21)},
+		{"$now-2h", now.Add(-2 * time.Hour)},
+		{"$today+1w", day(2024, 3, 21)},
+		{"$after_30d", now.AddDate(0, 0, 30)},
+		{"$before_1h", now.Add(-time.Hour)},
+		{"$tomorrow_now", now.AddDate(0, 0, 1)},
+		{"$next_week", day(2024, 3, 18)},
+		{"$last_week", day(2024, 3, 4)},
+		{"$next_month", day(2024, 4, 1)},
+		{"$last_year", day(2023, 1, 1)},
+	}
+	for _, c := range cases {
+		d, err := ParseDynamicDefault(c.expr)
+		if err != nil {
+			t.Errorf("解析 %s 失败: %v", c.expr, err)
+			continue
+		}
+		got, err := d.Resolve(vars)
+		if err != nil {
+			t.Errorf("计算 %s 失败: %v", c.expr, err)
+			continue
+		}
+		if want, ok := c.want.(time.Time); ok {
+			if gt, ok := got.(time.Time); !ok || !gt.Equal(want) {
+				t.Errorf("%s 应为 %v，实际: %v", c.expr, want, got)
+			}
+			continue
+		}
+		if got != c.want {
+			t.Errorf("%s 应为 %v，实际: %v", c.expr, c.want, got)
+		}
+	}
+
+	for _, expr := range []string{"now", "$later", "$today+7", "$today+7y"} {
+		if _, err := ParseDynamicDefault(expr); err == nil {
+			t.Errorf("%s 应该解析失败", expr)
+		}
+	}
+}
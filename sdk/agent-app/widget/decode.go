@@ -16,6 +16,7 @@ type FieldTags struct {
 	Validate   string // validate tag value
 	Data       string // data tag value
 	Permission string // permission tag value
+	Step       string // step tag value（向导表单的步骤名称，只对请求结构体的顶层字段生效）
 
 	// 解析后的widget标签
 	WidgetParsed map[string]string
@@ -83,6 +84,7 @@ func ParseModelWithType(model interface{}) (*ParseModelResult, error) {
 			Data:         field.Tag.Get("data"),
 			Callback:     field.Tag.Get("callback"),
 			Permission:   field.Tag.Get("permission"),
+			Step:         field.Tag.Get("step"),
 			WidgetParsed: make(map[string]string),
 			DataParsed:   make(map[string]string),
 			Type:         field.Type, // 保存字段类型
@@ -299,10 +301,19 @@ func ConvertTagsToField(tags *FieldTags) *Field {
 		TablePermission: tags.Permission,
 		Data:            &FieldData{},
 		DependOn:        tags.WidgetParsed["depend_on"], // 从widget标签中获取依赖字段
+		ShowIf:          tags.WidgetParsed["show_if"],
+		RequiredIf:      tags.WidgetParsed["required_if"],
+		ReadonlyIf:      tags.WidgetParsed["readonly_if"],
 	}
 	if tags.Callback != "" {
 		field.Callbacks = strings.Split(tags.Callback, ",")
 	}
+	// 模板中声明的字段级回调（如 OnSelectFuzzyMap、子表的 OnTableLoadChildren）
+	for _, cb := range tags.FieldsCallbackMap[strings.Split(field.Code, ",")[0]] {
+		if !containsString(field.Callbacks, cb) {
+			field.Callbacks = append(field.Callbacks, cb)
+		}
+	}
 
 	// 获取widget类型（必须明确指定，不自动推断）
 	widgetType := tags.WidgetParsed["type"]
@@ -317,6 +328,11 @@ func ConvertTagsToField(tags *FieldTags) *Field {
 		field.Widget.Config = widget.Config()
 	}
 
+	// 关联组件的模糊搜索和回显由 SDK 自动生成的 OnSelectFuzzy 回调处理
+	if widgetType == TypeReference && !containsString(field.Callbacks, CallbackOnSelectFuzzy) {
+		field.Callbacks = append(field.Callbacks, CallbackOnSelectFuzzy)
+	}
+
 	// 根据Go类型推断数据类型，完全基于Go类型，与widget type无关
 	field.Data.Type = inferDataType(tags.Type)
 
@@ -332,6 +348,16 @@ func ConvertTagsToField(tags *FieldTags) *Field {
 	return field
 }
 
+// containsString 切片中是否包含指定字符串
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
 // inferDataType 根据Go类型推断数据类型（完全基于Go类型，与widget type无关）
 func inferDataType(goType reflect.Type) string {
 	// 处理指针类型
@@ -25,7 +25,8 @@ type Demo struct {
 
 	// 框架标签：widget:"type:input;mode:text_area" - 多行文本区域组件
 	// 框架标签：validate:"required,min=10" - 必填字段，至少10字符
-	Description string `json:"description" gorm:"column:description" widget:"name:问题描述;type:text_area" validate:"required,min=10"`
+	// 框架标签：search:"fulltext" - 加入全文检索（SQLite FTS5，支持中文），表格的关键词搜索会检索该字段并返回命中片段
+	Description string `json:"description" gorm:"column:description" widget:"name:问题描述;type:text_area" search:"fulltext" validate:"required,min=10"`
 
 	// 框架标签：widget:"name:优先级;type:select;options:低,中,高;default:中" - 下拉选择组件（默认值为"中"）
 	// 框架标签：validate:"required,oneof=低 中 高" - 必填字段，值必须是选项之一
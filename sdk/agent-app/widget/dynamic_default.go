@@ -0,0 +1,162 @@
+package widget
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DynamicDefault 动态默认值（widget 标签中以 $ 开头的 default），前端展示表单时填充，
+// SDK 在 ShouldBind 和 AutoCrudTable 新增记录时对为空的字段在服务端重新计算：
+//
+//	default:$user / $me        当前登录用户
+//	default:$dept              当前登录用户所在部门的完整路径，如 /tech/backend
+//	default:$now               当前时间
+//	default:$today             今天 00:00:00（$tomorrow、$yesterday 同理）
+//	default:$today+7d          相对时间：now、today、tomorrow、yesterday 加减 m（分钟）、h、d、w
+//
+// 兼容 timestamp 组件已有的写法：$yesterday_now、$tomorrow_now、$after_2h、$before_7d、
+// $next_week、$last_week、$next_month、$last_month、$next_year、$last_year
+type DynamicDefault struct {
+	Expr string
+	kind string // user、dept、time
+	// 时间：基准时间 + 偏移
+	base   string
+	offset time.Duration
+}
+
+// DefaultVars 计算动态默认值时使用的变量
+type DefaultVars struct {
+	Now  time.Time
+	User string
+	// Dept 获取当前用户的部门（需要查询用户信息，只在用到 $dept 时调用）
+	Dept func() (string, error)
+}
+
+var (
+	dynamicOffsetRegexp = regexp.MustCompile(`^(now|today|tomorrow|yesterday)(?:([+-])(\d+)([mhdw]))?$`)
+	dynamicLegacyRegexp = regexp.MustCompile(`^(after|before)_(\d+)([hd])$`)
+)
+
+// IsDynamicDefault 是否为动态默认值（以 $ 开头）
+func IsDynamicDefault(value string) bool {
+	return strings.HasPrefix(value, "$")
+}
+
+// ParseDynamicDefault 解析动态默认值
+func ParseDynamicDefault(expr string) (*DynamicDefault, error) {
+	if !IsDynamicDefault(expr) {
+		return nil, fmt.Errorf("动态默认值必须以 $ 开头: %s", expr)
+	}
+	name := strings.TrimPrefix(expr, "$")
+	d := &DynamicDefault{Expr: expr, kind: "time"}
+
+	switch name {
+	case "user", "me":
+		d.kind = "user"
+		return d, nil
+	case "dept":
+		d.kind = "dept"
+		return d, nil
+	case "yesterday_now":
+		d.base, d.offset = "now", -24*time.Hour
+		return d, nil
+	case "tomorrow_now":
+		d.base, d.offset = "now", 24*time.Hour
+		return d, nil
+	case "next_week", "last_week", "next_month", "last_month", "next_year", "last_year":
+		d.base = name
+		return d, nil
+	}
+
+	if m := dynamicLegacyRegexp.FindStringSubmatch(name); m != nil {
+		n, _ := strconv.Atoi(m[2])
+		d.base, d.offset = "now", time.Duration(n)*unitDuration(m[3])
+		if m[1] == "before" {
+			d.offset = -d.offset
+		}
+		return d, nil
+	}
+	if m := dynamicOffsetRegexp.FindStringSubmatch(name); m != nil {
+		d.base = m[1]
+		if m[2] != "" {
+			n, _ := strconv.Atoi(m[3])
+			d.offset = time.Duration(n) * unitDuration(m[4])
+			if m[2] == "-" {
+				d.offset = -d.offset
+			}
+		}
+		return d, nil
+	}
+	return nil, fmt.Errorf("不支持的动态默认值: %s", expr)
+}
+
+func unitDuration(unit string) time.Duration {
+	switch unit {
+	case "m":
+		return time.Minute
+	case "h":
+		return time.Hour
+	case "w":
+		return 7 * 24 * time.Hour
+	}
+	return 24 * time.Hour
+}
+
+// IsTime 是否为时间类的动态默认值
+func (d *DynamicDefault) IsTime() bool {
+	return d.kind == "time"
+}
+
+// Resolve 计算动态默认值：时间类返回 time.Time，$user、$dept 返回字符串
+func (d *DynamicDefault) Resolve(vars DefaultVars) (interface{}, error) {
+	switch d.kind {
+	case "user":
+		return vars.User, nil
+	case "dept":
+		if vars.Dept == nil {
+			return "", nil
+		}
+		return vars.Dept()
+	}
+
+	now := vars.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	var t time.Time
+	switch d.base {
+	case "now":
+		t = now
+	case "today":
+		t = today
+	case "tomorrow":
+		t = today.AddDate(0, 0, 1)
+	case "yesterday":
+		t = today.AddDate(0, 0, -1)
+	case "next_week", "last_week":
+		// 本周一（周日算作上一周的最后一天）
+		monday := today.AddDate(0, 0, -((int(today.Weekday()) + 6) % 7))
+		if d.base == "next_week" {
+			t = monday.AddDate(0, 0, 7)
+		} else {
+			t = monday.AddDate(0, 0, -7)
+		}
+	case "next_month":
+		t = time.Date(now.Year(), now.Month()+1, 1, 0, 0, 0, 0, now.Location())
+	case "last_month":
+		t = time.Date(now.Year(), now.Month()-1, 1, 0, 0, 0, 0, now.Location())
+	case "next_year":
+		t = time.Date(now.Year()+1, 1, 1, 0, 0, 0, 0, now.Location())
+	case "last_year":
+		t = time.Date(now.Year()-1, 1, 1, 0, 0, 0, 0, now.Location())
+	}
+	// 按天偏移时使用日历日，避免夏令时切换导致的小时偏差
+	if d.offset%(24*time.Hour) == 0 {
+		return t.AddDate(0, 0, int(d.offset/(24*time.Hour))), nil
+	}
+	return t.Add(d.offset), nil
+}
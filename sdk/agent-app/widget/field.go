@@ -16,6 +16,9 @@ type Field struct {
 	TablePermission string   `json:"table_permission,omitempty"` // 表格权限：read,update,create
 	Validation      string   `json:"validation,omitempty"`       // 验证规则，完全照搬 github.com/go-playground/validator/v10
 	DependOn        string   `json:"depend_on,omitempty"`        // 依赖的字段 code，当依赖字段值变化时，该字段会被清空
+	ShowIf          string   `json:"show_if,omitempty"`          // 显示条件（见 Condition），不满足时隐藏并清空，服务端同样不接受隐藏字段的值
+	RequiredIf      string   `json:"required_if,omitempty"`      // 必填条件，满足且字段显示时必须填写
+	ReadonlyIf      string   `json:"readonly_if,omitempty"`      // 只读条件，满足时不能修改（新增时使用默认值）
 }
 
 // FieldData 字段数据类型信息
@@ -0,0 +1,70 @@
+package widget
+
+import "strings"
+
+// CallbackOnSelectFuzzy 关联组件自动生成的回调（与 app.CallbackTypeOnSelectFuzzy 一致）
+const CallbackOnSelectFuzzy = "OnSelectFuzzy"
+
+// 关联记录被删除时的处理方式
+const (
+	ReferenceOnDeleteRestrict = "restrict" // 默认：被引用的记录不允许删除
+	ReferenceOnDeleteIgnore   = "ignore"   // 不检查，允许删除被引用的记录
+)
+
+// Reference 关联组件（外键），关联同一应用中另一个表格函数的记录
+//
+// 使用示例：
+//
+//	CustomerID int `json:"customer_id" widget:"name:客户;type:reference;target:/crm/customer;label:name"`
+//
+// 参数说明：
+//   - target: 目标表格函数的路径（应用内的绝对路径，如 /crm/customer），目标表可以在其他 package 的数据库中
+//   - label: 显示的字段（目标表的列），默认与 value 相同
+//   - value: 保存的字段（目标表的列），默认 id
+//   - search: 模糊搜索的字段（目标表的列，逗号分隔），默认 label
+//   - on_delete: 目标记录被删除时的处理方式，restrict（默认，被引用时不允许删除）/ ignore
+//
+// SDK 会自动生成该字段的 OnSelectFuzzy 回调（模糊搜索、按值回显），返回的选项带有跳转到目标记录的链接；
+// 如果 OnSelectFuzzyMap 中已经有同名字段的回调，使用自定义的回调
+type Reference struct {
+	Target      string   `json:"target"`                // 目标表格函数路径
+	Label       string   `json:"label"`                 // 显示的字段
+	Value       string   `json:"value"`                 // 保存的字段
+	Search      []string `json:"search,omitempty"`      // 模糊搜索的字段
+	OnDelete    string   `json:"on_delete"`             // restrict/ignore
+	Placeholder string   `json:"placeholder,omitempty"` // 占位符文本
+}
+
+func (r *Reference) Config() interface{} {
+	return r
+}
+
+func (r *Reference) Type() string {
+	return TypeReference
+}
+
+func newReference(widgetParsed map[string]string) *Reference {
+	reference := &Reference{
+		Target:      strings.TrimSpace(widgetParsed["target"]),
+		Label:       strings.TrimSpace(widgetParsed["label"]),
+		Value:       strings.TrimSpace(widgetParsed["value"]),
+		OnDelete:    widgetParsed["on_delete"],
+		Placeholder: widgetParsed["placeholder"],
+	}
+	if reference.Value == "" {
+		reference.Value = "id"
+	}
+	if reference.Label == "" {
+		reference.Label = reference.Value
+	}
+	if search, exists := widgetParsed["search"]; exists {
+		reference.Search = parseOptions(search)
+	}
+	if len(reference.Search) == 0 {
+		reference.Search = []string{reference.Label}
+	}
+	if reference.OnDelete != ReferenceOnDeleteIgnore {
+		reference.OnDelete = ReferenceOnDeleteRestrict
+	}
+	return reference
+}
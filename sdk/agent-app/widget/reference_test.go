@@ -0,0 +1,58 @@
+package widget
+
+import "testing"
+
+// 测试 reference 组件的参数解析和自动生成的回调
+type ReferenceTestStruct struct {
+	CustomerID int    `json:"customer_id" widget:"name:客户;type:reference;target:/crm/customer;label:name"`
+	OwnerCode  string `json:"owner_code" widget:"name:负责人;type:reference;target:/hr/employee;value:code;label:name;search:name,phone;on_delete:ignore"`
+}
+
+func TestReferenceConfig(t *testing.T) {
+	result, err := ParseModelWithType(&ReferenceTestStruct{})
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+
+	fields := make(map[string]*Field)
+	for _, tag := range result.Tags {
+		field := ConvertTagsToField(tag)
+		fields[field.Code] = field
+	}
+
+	customer := fields["customer_id"]
+	if customer == nil {
+		t.Fatal("未找到 customer_id 字段")
+	}
+	config, ok := customer.Widget.Config.(*Reference)
+	if !ok {
+		t.Fatal("无法转换配置为 Reference")
+	}
+	if config.Target != "/crm/customer" || config.Value != "id" || config.Label != "name" {
+		t.Errorf("customer_id 配置错误: %+v", config)
+	}
+	if len(config.Search) != 1 || config.Search[0] != "name" {
+		t.Errorf("customer_id search 默认应为 label，实际: %v", config.Search)
+	}
+	if config.OnDelete != ReferenceOnDeleteRestrict {
+		t.Errorf("customer_id on_delete 默认应为 restrict，实际: %s", config.OnDelete)
+	}
+	if !containsString(customer.Callbacks, CallbackOnSelectFuzzy) {
+		t.Errorf("customer_id 应自动带有 OnSelectFuzzy 回调，实际: %v", customer.Callbacks)
+	}
+
+	owner := fields["owner_code"]
+	if owner == nil {
+		t.Fatal("未找到 owner_code 字段")
+	}
+	config = owner.Widget.Config.(*Reference)
+	if config.Value != "code" || config.Label != "name" {
+		t.Errorf("owner_code 配置错误: %+v", config)
+	}
+	if len(config.Search) != 2 || config.Search[0] != "name" || config.Search[1] != "phone" {
+		t.Errorf("owner_code search 错误: %v", config.Search)
+	}
+	if config.OnDelete != ReferenceOnDeleteIgnore {
+		t.Errorf("owner_code on_delete 应为 ignore，实际: %s", config.OnDelete)
+	}
+}
@@ -23,6 +23,7 @@ const (
 	TypeForm        = "form"
 	TypeLink        = "link"
 	TypeProgress    = "progress"
+	TypeReference   = "reference"
 )
 
 // 数据类型
@@ -92,6 +93,8 @@ func NewWidget(widgetType string, widgetParsed map[string]string) Widget {
 		return newLink(widgetParsed)
 	case TypeProgress:
 		return newProgress(widgetParsed)
+	case TypeReference:
+		return newReference(widgetParsed)
 	default:
 		// 默认返回Input组件，确保兜底
 		return newInput(widgetParsed)
@@ -7,21 +7,46 @@ import (
 	"os"
 	"os/signal"
 	"sync"
+	"sync/atomic"
 	"syscall"
+	"time"
 
 	"github.com/ai-agent-os/ai-agent-os/dto"
 	"github.com/ai-agent-os/ai-agent-os/pkg/logger"
 	"github.com/ai-agent-os/ai-agent-os/pkg/msgx"
+	"github.com/ai-agent-os/ai-agent-os/pkg/subjects"
+	"github.com/google/uuid"
 	"github.com/nats-io/nats.go"
 )
 
+// DefaultHeartbeatInterval 默认心跳间隔（agent-server 超过 3 个心跳间隔未收到心跳即认为实例离线）
+const DefaultHeartbeatInterval = 10 * time.Second
+
 // Plugin 插件实例
 type Plugin struct {
 	conn         *nats.Conn
 	sub          *nats.Subscription
+	discoverSub  *nats.Subscription
 	handler      HandlerFunc
 	subject      string // 订阅的主题
 	shutdownOnce sync.Once
+
+	// 上报给 agent-server 的实例信息
+	instanceID        string
+	hostname          string
+	version           string
+	capabilities      []string
+	inputSchema       json.RawMessage
+	outputSchema      json.RawMessage
+	concurrency       int
+	heartbeatInterval time.Duration
+
+	sem      chan struct{} // 并发控制
+	inflight int32         // 正在处理的请求数
+	wg       sync.WaitGroup
+	stopCh   chan struct{}
+	mu       sync.Mutex // 保护 closed，保证 Close 中 wg.Wait 之后不会再有 wg.Add
+	closed   bool
 }
 
 // HandlerFunc 插件处理函数类型
@@ -30,33 +55,129 @@ type Plugin struct {
 // 返回: 插件执行响应和错误
 type HandlerFunc func(ctx *Context, req *dto.PluginRunReq) (*dto.PluginRunResp, error)
 
+// Option 插件选项
+type Option func(p *Plugin) error
+
+// WithVersion 设置插件版本
+func WithVersion(version string) Option {
+	return func(p *Plugin) error {
+		p.version = version
+		return nil
+	}
+}
+
+// WithCapabilities 设置插件能力（如 "excel", "csv"）
+func WithCapabilities(capabilities ...string) Option {
+	return func(p *Plugin) error {
+		p.capabilities = capabilities
+		return nil
+	}
+}
+
+// WithSchema 声明插件的输入/输出结构（JSON Schema，可以是字符串、[]byte 或可序列化的结构体，nil 表示不声明）
+func WithSchema(input, output interface{}) Option {
+	return func(p *Plugin) error {
+		var err error
+		if p.inputSchema, err = toRawJSON(input); err != nil {
+			return fmt.Errorf("输入结构不是合法的 JSON: %w", err)
+		}
+		if p.outputSchema, err = toRawJSON(output); err != nil {
+			return fmt.Errorf("输出结构不是合法的 JSON: %w", err)
+		}
+		return nil
+	}
+}
+
+// WithConcurrency 设置最大并发处理数（默认 1，即串行处理）
+func WithConcurrency(n int) Option {
+	return func(p *Plugin) error {
+		if n < 1 {
+			return fmt.Errorf("并发数必须大于 0")
+		}
+		p.concurrency = n
+		return nil
+	}
+}
+
+// WithHeartbeatInterval 设置心跳间隔（默认 10 秒）
+func WithHeartbeatInterval(interval time.Duration) Option {
+	return func(p *Plugin) error {
+		if interval < time.Second {
+			return fmt.Errorf("心跳间隔不能小于 1 秒")
+		}
+		p.heartbeatInterval = interval
+		return nil
+	}
+}
+
+// WithInstanceID 设置实例ID（默认自动生成，同一插件的多个实例之间必须唯一）
+func WithInstanceID(instanceID string) Option {
+	return func(p *Plugin) error {
+		if instanceID == "" {
+			return fmt.Errorf("实例ID不能为空")
+		}
+		p.instanceID = instanceID
+		return nil
+	}
+}
+
 // NewPlugin 创建插件实例
 // natsURL: NATS 服务器地址（如 "nats://127.0.0.1:4222"）
-// subject: 订阅的主题（如 "agent.function_gen.beiluo.1.run"）
+// subject: 订阅的主题（如 "plugins.beiluo.1"）
 // handler: 处理函数
-func NewPlugin(natsURL, subject string, handler HandlerFunc) (*Plugin, error) {
+// opts: 可选的版本、能力、输入/输出结构、并发数等（会上报给 agent-server）
+//
+// 同一主题可以启动多个实例，请求会在实例之间分摊（NATS 队列组）
+func NewPlugin(natsURL, subject string, handler HandlerFunc, opts ...Option) (*Plugin, error) {
+	hostname, _ := os.Hostname()
+	plugin := &Plugin{
+		handler:           handler,
+		subject:           subject,
+		instanceID:        uuid.NewString(),
+		hostname:          hostname,
+		concurrency:       1,
+		heartbeatInterval: DefaultHeartbeatInterval,
+		stopCh:            make(chan struct{}),
+	}
+	for _, opt := range opts {
+		if err := opt(plugin); err != nil {
+			return nil, err
+		}
+	}
+	plugin.sem = make(chan struct{}, plugin.concurrency)
+
 	// 1. 连接 NATS
 	conn, err := nats.Connect(natsURL)
 	if err != nil {
 		return nil, fmt.Errorf("连接 NATS 失败: %w", err)
 	}
+	plugin.conn = conn
 
-	plugin := &Plugin{
-		conn:    conn,
-		handler: handler,
-		subject: subject,
+	// 2. 订阅主题（队列组：多个实例分摊请求）
+	sub, err := conn.QueueSubscribe(subject, subjects.PluginQueueGroup, plugin.dispatch)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("订阅主题失败: %w", err)
 	}
+	plugin.sub = sub
 
-	// 2. 订阅主题
-	sub, err := conn.Subscribe(subject, plugin.handleMessage)
+	// 3. 订阅发现请求（agent-server 重启后会广播，插件立即上报状态）
+	discoverSub, err := conn.Subscribe(subjects.GetAgentServerPluginDiscoverSubject(), func(msg *nats.Msg) {
+		plugin.announce(dto.PluginPresenceHeartbeat)
+	})
 	if err != nil {
+		sub.Unsubscribe()
 		conn.Close()
-		return nil, fmt.Errorf("订阅主题失败: %w", err)
+		return nil, fmt.Errorf("订阅发现主题失败: %w", err)
 	}
+	plugin.discoverSub = discoverSub
 
-	plugin.sub = sub
+	// 4. 上线并开始心跳
+	plugin.announce(dto.PluginPresenceOnline)
+	go plugin.heartbeat()
 
-	logger.Infof(context.Background(), "[Plugin] 插件初始化成功, Subject: %s", subject)
+	logger.Infof(context.Background(), "[Plugin] 插件初始化成功, Subject: %s, InstanceID: %s, Version: %s, Concurrency: %d",
+		subject, plugin.instanceID, plugin.version, plugin.concurrency)
 
 	return plugin, nil
 }
@@ -75,16 +196,26 @@ func (p *Plugin) Run() error {
 	return p.Close()
 }
 
-// Close 关闭插件
+// Close 关闭插件（先下线并停止接收新请求，再等待正在处理的请求完成）
 func (p *Plugin) Close() error {
 	var err error
 	p.shutdownOnce.Do(func() {
+		close(p.stopCh)
+		p.announce(dto.PluginPresenceOffline)
+		if p.discoverSub != nil {
+			p.discoverSub.Unsubscribe()
+		}
 		if p.sub != nil {
 			if err = p.sub.Unsubscribe(); err != nil {
 				logger.Errorf(context.Background(), "[Plugin] 取消订阅失败: %v", err)
 			}
 		}
+		p.mu.Lock()
+		p.closed = true
+		p.mu.Unlock()
+		p.wg.Wait()
 		if p.conn != nil {
+			p.conn.Flush()
 			p.conn.Close()
 		}
 		logger.Infof(context.Background(), "[Plugin] 插件已关闭")
@@ -92,9 +223,81 @@ func (p *Plugin) Close() error {
 	return err
 }
 
+// heartbeat 定期上报心跳
+func (p *Plugin) heartbeat() {
+	ticker := time.NewTicker(p.heartbeatInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.stopCh:
+			return
+		case <-ticker.C:
+			p.announce(dto.PluginPresenceHeartbeat)
+		}
+	}
+}
+
+// announce 上报实例状态
+func (p *Plugin) announce(presenceType string) {
+	presence := &dto.PluginPresence{
+		Type:         presenceType,
+		Subject:      p.subject,
+		InstanceID:   p.instanceID,
+		Hostname:     p.hostname,
+		Version:      p.version,
+		Capabilities: p.capabilities,
+		InputSchema:  p.inputSchema,
+		OutputSchema: p.outputSchema,
+		Concurrency:  p.concurrency,
+		Inflight:     int(atomic.LoadInt32(&p.inflight)),
+		Interval:     int(p.heartbeatInterval / time.Second),
+	}
+	data, err := json.Marshal(presence)
+	if err != nil {
+		return
+	}
+	if err := p.conn.Publish(subjects.GetAgentServerPluginPresenceSubject(), data); err != nil {
+		logger.Warnf(context.Background(), "[Plugin] 上报状态失败: %v, Type: %s", err, presenceType)
+	}
+}
+
+// dispatch 按并发数分发消息（并发已满时阻塞，消息留在 NATS 客户端缓冲区）
+func (p *Plugin) dispatch(msg *nats.Msg) {
+	select {
+	case <-p.stopCh:
+		msgx.RespFailMsg(msg, fmt.Errorf("插件正在关闭"))
+		return
+	case p.sem <- struct{}{}:
+	}
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		<-p.sem
+		msgx.RespFailMsg(msg, fmt.Errorf("插件正在关闭"))
+		return
+	}
+	p.wg.Add(1)
+	p.mu.Unlock()
+	atomic.AddInt32(&p.inflight, 1)
+	go func() {
+		defer func() {
+			atomic.AddInt32(&p.inflight, -1)
+			p.wg.Done()
+			<-p.sem
+		}()
+		p.handleMessage(msg)
+	}()
+}
+
 // handleMessage 处理接收到的消息
 func (p *Plugin) handleMessage(msg *nats.Msg) {
 	ctx := context.Background()
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Errorf(ctx, "[Plugin] 处理 panic: %v", r)
+			msgx.RespFailMsg(msg, fmt.Errorf("插件处理异常: %v", r))
+		}
+	}()
 
 	// 解析请求
 	var req dto.PluginRunReq
@@ -130,3 +333,23 @@ func (p *Plugin) handleMessage(msg *nats.Msg) {
 	logger.Infof(ctx, "[Plugin] 处理成功, TraceID: %s, DataLength: %d", pluginCtx.TraceID, len(resp.Data))
 }
 
+// toRawJSON 把结构声明转换为 JSON
+func toRawJSON(v interface{}) (json.RawMessage, error) {
+	switch s := v.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		if !json.Valid([]byte(s)) {
+			return nil, fmt.Errorf("JSON 格式错误")
+		}
+		return json.RawMessage(s), nil
+	case []byte:
+		if !json.Valid(s) {
+			return nil, fmt.Errorf("JSON 格式错误")
+		}
+		return json.RawMessage(s), nil
+	case json.RawMessage:
+		return s, nil
+	}
+	return json.Marshal(v)
+}